@@ -22,6 +22,7 @@ import (
 	"context"
 
 	"github.com/a2aproject/a2a-go/a2a"
+	x402types "github.com/x402-foundation/x402/go/types"
 )
 
 // Request describes a business invocation. Services are called once before
@@ -29,18 +30,117 @@ import (
 type Request struct {
 	Prompt          string
 	PaymentVerified bool
+	// SkillID is the agent card skill the request was routed to, or empty
+	// when the client did not name one.
+	SkillID string
+
+	TaskID    a2a.TaskID
+	ContextID string
+	// Message is the user message that started the task, including any
+	// non-text parts and metadata.
+	Message *a2a.Message
+	// Metadata is the metadata the client attached to that message, without
+	// the x402 protocol keys. It is kept with the task, so it is set after the
+	// payment round trip even when the task history is not.
+	Metadata map[string]interface{}
+	// Payer is the address reported by the facilitator. It is empty until the
+	// payment has been verified.
+	Payer string
+	// Requirements is the payment option the client paid with. It is nil
+	// until the payment has been verified.
+	Requirements *x402types.PaymentRequirements
+	// Free is set, together with PaymentVerified, when the service priced the
+	// request at zero and it runs without any payment.
+	Free bool
+	// Subscription is the ID of the subscription that pre-paid the request.
+	// It is set together with PaymentVerified and Payer, and the request is
+	// never quoted.
+	Subscription string
+	// Trusted is the subject of the pre-authorized credential that exempted
+	// the request from payment. It is set together with PaymentVerified, and
+	// the request is never quoted.
+	Trusted string
+	// Round counts the payments made on the task, including the one being
+	// served. It is 1 unless an earlier result asked for an additional
+	// payment.
+	Round int
 }
 
 // Result contains the business output that will be returned with the A2A task.
 type Result struct {
-	Message   string
+	Message string
+	// Summary is the short text put on the completion status message, which
+	// is stored with the task and returned by every GetTask. It defaults to
+	// Message.
+	Summary string
+	// Parts follow the Message text in the "result" artifact, e.g. a FilePart
+	// or DataPart holding the actual output. They are not copied onto the
+	// status message.
+	Parts []a2a.Part
+	// Artifacts are streamed before the final status update. Artifacts that
+	// share an ID are sent as successive chunks of one artifact.
 	Artifacts []*a2a.Artifact
+	// SettleAmount is the atomic amount actually consumed under the "upto"
+	// scheme. It must not exceed the authorized maximum; empty settles the
+	// full amount. It has no effect when settlement runs before execution.
+	SettleAmount string
+	// MeasuredAmount is the atomic amount the work actually cost, for metered
+	// billing under the "upto" scheme. It is raised to the merchant's metered
+	// minimum, though never past the authorized maximum, and takes
+	// precedence over SettleAmount. A measurement above the authorized
+	// maximum fails the task without charging the client.
+	MeasuredAmount string
+	// MeasurementBasis says what MeasuredAmount was computed from, such as
+	// "1532 output tokens". It is recorded in the receipt.
+	MeasurementBasis string
+	// AdditionalPaymentRequired asks for another payment on the same task,
+	// e.g. an upscale offered after the first render. The result is delivered
+	// with the new quote instead of completing the task, and the service is
+	// called again with the next Round once the client pays.
+	AdditionalPaymentRequired *PaymentRequiredError
 }
 
 type BusinessService interface {
 	Execute(ctx context.Context, request Request) (*Result, error)
 }
 
+// Progress is an intermediate update from a long-running paid execution.
+type Progress struct {
+	// Percent is the completion estimate from 0 to 100.
+	Percent int
+	Message string
+}
+
+// ProgressEmitter reports progress to the client. It is safe for concurrent
+// use, and calls made after ExecuteStreaming returns are dropped.
+type ProgressEmitter func(Progress)
+
+// StreamingBusinessService is an optional extension of BusinessService for
+// services that report progress while they work. The orchestrator calls
+// ExecuteStreaming instead of Execute once the request is paid (or free);
+// quoting still goes through Execute.
+type StreamingBusinessService interface {
+	BusinessService
+	ExecuteStreaming(ctx context.Context, request Request, emit ProgressEmitter) (*Result, error)
+}
+
+// PreviewBusinessService is an optional extension of BusinessService for
+// services that show a teaser, such as a low-resolution image or truncated
+// text, alongside the quote. A Preview error never blocks quoting.
+type PreviewBusinessService interface {
+	BusinessService
+	Preview(ctx context.Context, prompt string) ([]a2a.Part, error)
+}
+
+// PromptExtractorBusinessService is an optional extension of BusinessService
+// for requests the orchestrator cannot read a prompt from, such as a FilePart
+// or a DataPart with an unusual shape. ExtractPrompt is only consulted when
+// the message has no text and no value at the orchestrator's prompt pointer.
+type PromptExtractorBusinessService interface {
+	BusinessService
+	ExtractPrompt(ctx context.Context, message *a2a.Message) (string, error)
+}
+
 // PaymentRequiredError is returned by a service when the current request must
 // be paid before execution can continue.
 type PaymentRequiredError struct {
@@ -63,9 +163,22 @@ func (e *PaymentRequiredError) Error() string {
 }
 
 type ServiceRequirements struct {
-	// Price is the payment amount required for the service (as a string, e.g., "1", "0.5")
+	// Price is the payment amount required for the service (as a string, e.g., "1", "0.5").
+	// A price such as "USD 1.50" is fiat-denominated and converted by the
+	// merchant's pricing provider.
 	Price string
 
+	// Currency marks Price as a fiat amount in this ISO 4217 currency (e.g. "USD").
+	Currency string
+
+	// DecimalPricing marks Price as whole tokens of each accepted asset. The
+	// merchant converts it to atomic units with the asset's decimals and
+	// rejects prices more precise than the asset supports.
+	DecimalPricing bool
+
+	// Free skips payment for this request. A Price of "0" has the same effect.
+	Free bool
+
 	// Resource is the resource identifier or URL associated with this service
 	Resource string
 
@@ -75,9 +188,15 @@ type ServiceRequirements struct {
 	// MimeType is the MIME type of the resource (e.g., "application/json", "image/png")
 	MimeType string
 
-	// Scheme is the payment scheme. This implementation currently registers "exact".
+	// Scheme is the payment scheme: "exact", or "upto" on EVM networks for
+	// metered pricing where Price is the ceiling.
 	Scheme string
 
 	// MaxTimeoutSeconds is the maximum time in seconds before payment expires
 	MaxTimeoutSeconds int
+
+	// OutputSchema describes the shape of the result being paid for, usually
+	// as a JSON Schema object. It is sent to clients in the Extra of every
+	// requirement and must encode to a JSON object.
+	OutputSchema any
 }
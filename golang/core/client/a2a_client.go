@@ -18,7 +18,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"mime"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/a2aproject/a2a-go/a2a"
@@ -26,9 +28,37 @@ import (
 	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
 )
 
+const defaultAgentCardTimeout = 10 * time.Second
+
+// connectOptions carries the transport settings shared by agent card discovery
+// and the A2A RPC calls.
+type connectOptions struct {
+	httpClient  *http.Client
+	headers     map[string]string
+	cardTimeout time.Duration
+	clock       Clock
+}
+
 func NewA2AClient(ctx context.Context, merchantURL string) (*a2aclient.Client, error) {
+	conn, err := connectMerchant(ctx, merchantURL, connectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return conn.client, nil
+}
+
+// merchantConnection holds the A2A client together with the discovery results
+// used to build it.
+type merchantConnection struct {
+	client        *a2aclient.Client
+	agentCard     *a2a.AgentCard
+	rpcEndpoint   string
+	extensionURIs []string
+}
+
+func connectMerchant(ctx context.Context, merchantURL string, opts connectOptions) (*merchantConnection, error) {
 	agentCardURL := merchantURL + "/.well-known/agent-card.json"
-	agentCard, err := fetchAgentCard(ctx, agentCardURL)
+	agentCard, err := fetchAgentCard(ctx, agentCardURL, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch AgentCard: %w", err)
 	}
@@ -38,9 +68,14 @@ func NewA2AClient(ctx context.Context, merchantURL string) (*a2aclient.Client, e
 		return nil, fmt.Errorf("merchant does not advertise the required x402 extension: %s", x402pkg.X402ExtensionURI)
 	}
 
-	factory := a2aclient.NewFactory(
-		a2aclient.WithInterceptors(newExtensionHeaderInterceptor(extensionURIs)),
-	)
+	factoryOptions := []a2aclient.FactoryOption{
+		a2aclient.WithInterceptors(
+			newExtensionHeaderInterceptor(extensionURIs),
+			newStaticHeaderInterceptor(opts.headers),
+		),
+	}
+	factoryOptions = append(factoryOptions, a2aclient.WithJSONRPCTransport(withRateLimitDetection(opts.httpClient, opts.clock)))
+	factory := a2aclient.NewFactory(factoryOptions...)
 
 	rpcEndpoint := determineRPCEndpoint(merchantURL, agentCard)
 	client, err := factory.CreateFromEndpoints(ctx, []a2a.AgentInterface{
@@ -53,32 +88,81 @@ func NewA2AClient(ctx context.Context, merchantURL string) (*a2aclient.Client, e
 		return nil, fmt.Errorf("failed to create A2A client from endpoints: %w. Ensure the server is running at %s", err, merchantURL)
 	}
 
-	return client, nil
+	return &merchantConnection{
+		client:        client,
+		agentCard:     agentCard,
+		rpcEndpoint:   rpcEndpoint,
+		extensionURIs: extensionURIs,
+	}, nil
+}
+
+func fetchAgentCard(ctx context.Context, url string, opts connectOptions) (*a2a.AgentCard, error) {
+	var card a2a.AgentCard
+	if err := getJSON(ctx, url, opts, "agent card", &card); err != nil {
+		return nil, err
+	}
+	return &card, nil
 }
 
-func fetchAgentCard(ctx context.Context, url string) (*a2a.AgentCard, error) {
+// getJSON fetches a JSON document from one of the merchant's well-known
+// endpoints into out. what names the document in errors.
+func getJSON(ctx context.Context, url string, opts connectOptions, what string, out any) error {
+	timeout := opts.cardTimeout
+	if timeout <= 0 {
+		timeout = defaultAgentCardTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	for name, value := range opts.headers {
+		req.Header.Set(name, value)
 	}
 
-	httpClient := &http.Client{Timeout: 10 * time.Second}
+	httpClient := opts.httpClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
 	resp, err := httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch agent card: %w", err)
+		return fmt.Errorf("failed to fetch %s: %w", what, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	if err := checkJSONContentType(resp.Header.Get("Content-Type"), what); err != nil {
+		return err
 	}
 
-	var card a2a.AgentCard
-	if err := json.NewDecoder(resp.Body).Decode(&card); err != nil {
-		return nil, fmt.Errorf("failed to decode agent card: %w", err)
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", what, err)
 	}
+	return nil
+}
 
-	return &card, nil
+// checkJSONContentType accepts missing content types for lenient servers but
+// rejects anything that is clearly not JSON, such as a gateway login page.
+func checkJSONContentType(contentType, what string) error {
+	if contentType == "" {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("invalid %s content type %q: %w", what, contentType, err)
+	}
+	if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+		return nil
+	}
+	if mediaType == "text/html" {
+		return fmt.Errorf("%s endpoint returned HTML instead of JSON; the merchant may require authentication", what)
+	}
+	return fmt.Errorf("unexpected %s content type: %s", what, mediaType)
 }
 
 func extractExtensionURIs(agentCard *a2a.AgentCard) []string {
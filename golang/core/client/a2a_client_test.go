@@ -16,8 +16,12 @@ package client
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/a2aproject/a2a-go/a2a"
 	"github.com/a2aproject/a2a-go/a2aclient"
@@ -55,6 +59,93 @@ func TestExtensionHeaderInterceptor(t *testing.T) {
 	}
 }
 
+func TestFetchAgentCard(t *testing.T) {
+	const cardJSON = `{"name":"merchant","url":"http://merchant/rpc"}`
+	tests := []struct {
+		name        string
+		handler     http.HandlerFunc
+		opts        connectOptions
+		wantError   string
+		wantTimeout bool
+	}{
+		{
+			name: "sends accept and auth headers",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != "Bearer token" || r.Header.Get("Accept") != "application/json" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json; charset=utf-8")
+				_, _ = w.Write([]byte(cardJSON))
+			},
+			opts: connectOptions{headers: map[string]string{"Authorization": "Bearer token"}},
+		},
+		{
+			name: "html login page",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html")
+				_, _ = w.Write([]byte("<html>login</html>"))
+			},
+			wantError: "returned HTML instead of JSON",
+		},
+		{
+			name: "unexpected content type",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/plain")
+				_, _ = w.Write([]byte(cardJSON))
+			},
+			wantError: "unexpected agent card content type: text/plain",
+		},
+		{
+			name: "slow server",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-r.Context().Done():
+				case <-time.After(time.Second):
+				}
+			},
+			opts:        connectOptions{cardTimeout: 20 * time.Millisecond},
+			wantTimeout: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+			tt.opts.httpClient = server.Client()
+
+			card, err := fetchAgentCard(context.Background(), server.URL, tt.opts)
+			switch {
+			case tt.wantTimeout:
+				if !errors.Is(err, context.DeadlineExceeded) {
+					t.Fatalf("error = %v, want deadline exceeded", err)
+				}
+			case tt.wantError != "":
+				if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+					t.Fatalf("error = %v, want %q", err, tt.wantError)
+				}
+			default:
+				if err != nil || card.Name != "merchant" {
+					t.Fatalf("card = %#v, error = %v", card, err)
+				}
+			}
+		})
+	}
+}
+
+func TestStaticHeaderInterceptor(t *testing.T) {
+	interceptor := newStaticHeaderInterceptor(map[string]string{"Authorization": "Bearer token"})
+	request := &a2aclient.Request{}
+	if _, err := interceptor.Before(context.Background(), request); err != nil {
+		t.Fatalf("Before() error = %v", err)
+	}
+	values := request.Meta["Authorization"]
+	if len(values) != 1 || values[0] != "Bearer token" {
+		t.Fatalf("auth header = %#v", values)
+	}
+}
+
 func TestSendMessage(t *testing.T) {
 	message := a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "hello"})
 	task := newClientTestTask("task-send", a2a.TaskStateSubmitted, "")
@@ -24,3 +24,27 @@ func (i *extensionHeaderInterceptor) Before(ctx context.Context, req *a2aclient.
 	req.Meta["X-A2A-Extensions"] = i.extensionURIs
 	return ctx, nil
 }
+
+type staticHeaderInterceptor struct {
+	a2aclient.PassthroughInterceptor
+	headers map[string]string
+}
+
+func newStaticHeaderInterceptor(headers map[string]string) *staticHeaderInterceptor {
+	return &staticHeaderInterceptor{
+		headers: headers,
+	}
+}
+
+func (i *staticHeaderInterceptor) Before(ctx context.Context, req *a2aclient.Request) (context.Context, error) {
+	if len(i.headers) == 0 {
+		return ctx, nil
+	}
+	if req.Meta == nil {
+		req.Meta = make(a2aclient.CallMeta)
+	}
+	for name, value := range i.headers {
+		req.Meta[name] = []string{value}
+	}
+	return ctx, nil
+}
@@ -0,0 +1,152 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// AssetInfo describes how to display amounts of a token.
+type AssetInfo struct {
+	Symbol   string
+	Decimals int
+}
+
+// AssetRegistry maps (network, asset address) pairs to display information.
+// Assets registered here take precedence; any other asset is looked up in
+// the x402 asset registry, so the networks' default stablecoins and assets
+// added with x402.RegisterAsset display without registering them again. It
+// is safe for concurrent use.
+type AssetRegistry struct {
+	mu           sync.RWMutex
+	assets       map[string]AssetInfo
+	networkNames map[string]string
+}
+
+// NewAssetRegistry returns a registry that names the built-in networks.
+func NewAssetRegistry() *AssetRegistry {
+	return &AssetRegistry{
+		assets: make(map[string]AssetInfo),
+		networkNames: map[string]string{
+			x402pkg.NetworkBase:          "Base",
+			x402pkg.NetworkBaseSepolia:   "Base Sepolia",
+			x402pkg.NetworkSolanaMainnet: "Solana",
+			x402pkg.NetworkSolanaDevnet:  "Solana Devnet",
+			x402pkg.NetworkSolanaTestnet: "Solana Testnet",
+		},
+	}
+}
+
+// DefaultAssets is the registry used by FormatAmount.
+var DefaultAssets = NewAssetRegistry()
+
+// Register adds or replaces the display information for an asset.
+func (r *AssetRegistry) Register(network string, address string, info AssetInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.assets[assetKey(network, address)] = info
+}
+
+// RegisterNetworkName sets the display name used for network.
+func (r *AssetRegistry) RegisterNetworkName(network string, name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.networkNames[network] = name
+}
+
+// Lookup returns the display information for an asset, registered here or
+// known to the x402 asset registry with a symbol.
+func (r *AssetRegistry) Lookup(network string, address string) (AssetInfo, bool) {
+	r.mu.RLock()
+	info, ok := r.assets[assetKey(network, address)]
+	r.mu.RUnlock()
+	if ok {
+		return info, true
+	}
+	if address == "" {
+		return AssetInfo{}, false
+	}
+	asset, ok := x402pkg.LookupAsset(network, address)
+	if !ok || asset.Symbol == "" {
+		return AssetInfo{}, false
+	}
+	return AssetInfo{Symbol: asset.Symbol, Decimals: asset.Decimals}, true
+}
+
+// FormatAmount renders req as "<decimal> <symbol> on <network name>". Assets
+// that are not registered are shown in raw units with their address.
+func (r *AssetRegistry) FormatAmount(req x402types.PaymentRequirements) (string, error) {
+	amount, ok := new(big.Int).SetString(req.Amount, 10)
+	if !ok {
+		return "", fmt.Errorf("invalid payment amount: %q", req.Amount)
+	}
+
+	r.mu.RLock()
+	networkName, ok := r.networkNames[req.Network]
+	r.mu.RUnlock()
+	if !ok {
+		networkName = req.Network
+	}
+
+	info, ok := r.Lookup(req.Network, req.Asset)
+	if !ok {
+		return fmt.Sprintf("%s units of %s on %s", amount, req.Asset, networkName), nil
+	}
+	return fmt.Sprintf("%s %s on %s", formatDecimal(amount, info.Decimals), info.Symbol, networkName), nil
+}
+
+// FormatAmount formats req using DefaultAssets.
+func FormatAmount(req x402types.PaymentRequirements) (string, error) {
+	return DefaultAssets.FormatAmount(req)
+}
+
+func assetKey(network string, address string) string {
+	if x402pkg.IsEVMNetwork(network) {
+		address = strings.ToLower(address)
+	}
+	return network + "|" + address
+}
+
+// formatDecimal renders an atomic amount with at least two fractional digits.
+func formatDecimal(amount *big.Int, decimals int) string {
+	if decimals <= 0 {
+		return amount.String()
+	}
+	sign := ""
+	if amount.Sign() < 0 {
+		sign = "-"
+		amount = new(big.Int).Neg(amount)
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	whole, fraction := new(big.Int).QuoRem(amount, scale, new(big.Int))
+
+	digits := fraction.String()
+	digits = strings.Repeat("0", decimals-len(digits)) + digits
+	minDigits := 2
+	if decimals < minDigits {
+		minDigits = decimals
+	}
+	trimmed := strings.TrimRight(digits, "0")
+	if len(trimmed) < minDigits {
+		trimmed = digits[:minDigits]
+	}
+	return sign + whole.String() + "." + trimmed
+}
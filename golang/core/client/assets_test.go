@@ -0,0 +1,82 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"testing"
+
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+func TestFormatAmount(t *testing.T) {
+	registry := NewAssetRegistry()
+	registry.Register("eip155:1", "0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE", AssetInfo{Symbol: "ETH", Decimals: 18})
+	registry.RegisterNetworkName("eip155:1", "Ethereum")
+	const weth = "0x4200000000000000000000000000000000000006"
+	if err := x402pkg.RegisterAsset(x402pkg.AssetInfo{Network: x402pkg.NetworkBaseSepolia, Address: weth, Decimals: 18, Symbol: "WETH"}); err != nil {
+		t.Fatalf("RegisterAsset() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		req  x402types.PaymentRequirements
+		want string
+	}{
+		{
+			name: "usdc on base",
+			req:  x402types.PaymentRequirements{Network: x402pkg.NetworkBase, Asset: "0x833589fcd6edb6e08f4c7c32d4f71b54bda02913", Amount: "1500000"},
+			want: "1.50 USDC on Base",
+		},
+		{
+			name: "fractional usdc on solana",
+			req:  x402types.PaymentRequirements{Network: x402pkg.NetworkSolanaDevnet, Asset: "4zMMC9srt5Ri5X14GAgXhaHii3GnPAEERYPJgZJDncDU", Amount: "1234"},
+			want: "0.001234 USDC on Solana Devnet",
+		},
+		{
+			name: "18 decimal asset",
+			req:  x402types.PaymentRequirements{Network: "eip155:1", Asset: "0xeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee", Amount: "2500000000000000000"},
+			want: "2.50 ETH on Ethereum",
+		},
+		{
+			name: "usdc on solana",
+			req:  x402types.PaymentRequirements{Network: x402pkg.NetworkSolanaMainnet, Asset: "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v", Amount: "2000000"},
+			want: "2.00 USDC on Solana",
+		},
+		{
+			name: "asset from the x402 registry",
+			req:  x402types.PaymentRequirements{Network: x402pkg.NetworkBaseSepolia, Asset: weth, Amount: "10000000000000000"},
+			want: "0.01 WETH on Base Sepolia",
+		},
+		{
+			name: "unknown asset",
+			req:  x402types.PaymentRequirements{Network: "eip155:10", Asset: "0xabc", Amount: "42"},
+			want: "42 units of 0xabc on eip155:10",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := registry.FormatAmount(tt.req)
+			if err != nil || got != tt.want {
+				t.Fatalf("FormatAmount() = %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+
+	if _, err := registry.FormatAmount(x402types.PaymentRequirements{Amount: "1.5"}); err == nil {
+		t.Fatal("expected error for non-atomic amount")
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+// ErrBudgetExceeded is returned when a payment would take the client past its
+// budget.
+var ErrBudgetExceeded = errors.New("payment exceeds remaining budget")
+
+// Budget caps the total atomic amount the client may authorize. Amounts are
+// compared in the asset's smallest unit, so a Budget should only be shared by
+// clients paying in the same asset. It is safe for concurrent use.
+type Budget struct {
+	mu    sync.Mutex
+	limit *big.Int
+	spent *big.Int
+}
+
+func NewBudget(limit *big.Int) *Budget {
+	return &Budget{
+		limit: new(big.Int).Set(limit),
+		spent: new(big.Int),
+	}
+}
+
+// Spend reserves amount against the budget.
+func (b *Budget) Spend(amount string) error {
+	value, ok := new(big.Int).SetString(amount, 10)
+	if !ok || value.Sign() < 0 {
+		return fmt.Errorf("invalid payment amount: %s", amount)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	next := new(big.Int).Add(b.spent, value)
+	if next.Cmp(b.limit) > 0 {
+		return fmt.Errorf("%w: requested %s, remaining %s", ErrBudgetExceeded, value, new(big.Int).Sub(b.limit, b.spent))
+	}
+	b.spent = next
+	return nil
+}
+
+// Remaining returns the amount still available.
+func (b *Budget) Remaining() *big.Int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return new(big.Int).Sub(b.limit, b.spent)
+}
+
+func (b *Budget) release(amount string) {
+	value, ok := new(big.Int).SetString(amount, 10)
+	if !ok {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.spent.Sub(b.spent, value)
+	if b.spent.Sign() < 0 {
+		b.spent.SetInt64(0)
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+)
+
+// MerchantCapabilities fetches the networks, schemes and assets the merchant
+// accepts from x402.CapabilitiesPath. Merchants that do not publish them
+// answer with an error.
+func (c *Client) MerchantCapabilities(ctx context.Context) (*types.Capabilities, error) {
+	var capabilities types.Capabilities
+	err := getJSON(ctx, c.merchantURL+x402pkg.CapabilitiesPath, connectOptions{
+		httpClient:  c.httpClient,
+		headers:     c.headers,
+		cardTimeout: c.cardTimeout,
+	}, "capabilities", &capabilities)
+	if err != nil {
+		return nil, err
+	}
+	return &capabilities, nil
+}
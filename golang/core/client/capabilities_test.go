@@ -0,0 +1,73 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+)
+
+func TestMerchantCapabilities(t *testing.T) {
+	want := types.Capabilities{
+		X402Version:  x402pkg.X402Version,
+		ExtensionURI: x402pkg.X402ExtensionURI,
+		Facilitator:  "https://facilitator.example",
+		Networks: []types.NetworkCapability{{
+			Network: x402pkg.NetworkBase,
+			PayTo:   "0x123",
+			Schemes: []string{x402pkg.SchemeExact, x402pkg.SchemeUpto},
+			Assets:  []types.AssetCapability{{Address: "0xusdc", Decimals: 6, Name: "USD Coin"}},
+		}},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != x402pkg.CapabilitiesPath {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("X-Test") != "yes" {
+			t.Errorf("X-Test header = %q, want the client's headers", r.Header.Get("X-Test"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(want)
+	}))
+	defer server.Close()
+
+	c, err := newConfiguredClient([]Option{WithHeaders(map[string]string{"X-Test": "yes"})})
+	if err != nil {
+		t.Fatalf("newConfiguredClient() error = %v", err)
+	}
+	c.merchantURL = server.URL
+
+	got, err := c.MerchantCapabilities(context.Background())
+	if err != nil {
+		t.Fatalf("MerchantCapabilities() error = %v", err)
+	}
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("MerchantCapabilities() = %+v, want %+v", *got, want)
+	}
+
+	c.merchantURL = server.URL + "/missing"
+	if _, err := c.MerchantCapabilities(context.Background()); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("MerchantCapabilities() from a merchant without them error = %v, want a 404", err)
+	}
+}
@@ -16,7 +16,10 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net/http"
 	"time"
 
 	"github.com/a2aproject/a2a-go/a2a"
@@ -37,25 +40,94 @@ type paymentProcessor interface {
 	ProcessPaymentRequired(ctx context.Context, taskID a2a.TaskID, paymentRequired *x402types.PaymentRequired) (*a2a.Message, error)
 }
 
+// ErrInputRequired is returned when the merchant asks for non-payment input and
+// no InputHandler has been configured.
+var ErrInputRequired = errors.New("merchant requires additional input")
+
+// InputHandler answers a merchant prompt for a task paused in input-required
+// without x402 payment metadata. The returned message is sent on the same task.
+type InputHandler func(ctx context.Context, task *a2a.Task) (*a2a.Message, error)
+
 type Client struct {
-	x402Client   paymentProcessor
-	client       taskClient
-	pollInterval time.Duration
+	x402Client    paymentProcessor
+	client        taskClient
+	pollInterval  time.Duration
+	inputHandler  InputHandler
+	clock         Clock
+	lister        taskLister
+	sweepAge      time.Duration
+	httpClient    *http.Client
+	headers       map[string]string
+	cardTimeout   time.Duration
+	smartAccounts []smartAccount
+	budget        *Budget
+	dedupStore    DedupStore
+	maxBackoff    time.Duration
+	hooks         hookRunner
+	logger        *slog.Logger
+	optionErr     error
+	agentCard     *a2a.AgentCard
+	merchantURL   string
 }
 
-func NewClient(merchantURL string, networkKeyPairs []types.NetworkKeyPair) (*Client, error) {
-	a2aClient, err := NewA2AClient(context.Background(), merchantURL)
+func NewClient(merchantURL string, networkKeyPairs []types.NetworkKeyPair, opts ...Option) (*Client, error) {
+	c, err := newConfiguredClient(opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create A2A client: %w", err)
+		return nil, err
 	}
-	x402Client, err := NewX402Client(networkKeyPairs)
+	x402Client, err := newX402Client(networkKeyPairs, c.smartAccounts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create x402 client wrapper: %w", err)
 	}
+	x402Client.budget = c.budget
+
+	c.x402Client = x402Client
+	if err := c.connect(context.Background(), merchantURL); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
 
-	return &Client{
-		x402Client:   x402Client,
-		client:       a2aClient,
+func newConfiguredClient(opts []Option) (*Client, error) {
+	c := &Client{
 		pollInterval: defaultTaskPollInterval,
-	}, nil
+		clock:        RealClock(),
+		sweepAge:     defaultSweepAge,
+		cardTimeout:  defaultAgentCardTimeout,
+		maxBackoff:   defaultMaxBackoff,
+		logger:       slog.New(slog.DiscardHandler),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.hooks.logger = c.logger
+	if c.optionErr != nil {
+		return nil, c.optionErr
+	}
+	return c, nil
+}
+
+func (c *Client) connect(ctx context.Context, merchantURL string) error {
+	conn, err := connectMerchant(ctx, merchantURL, connectOptions{
+		httpClient:  c.httpClient,
+		headers:     c.headers,
+		cardTimeout: c.cardTimeout,
+		clock:       c.clock,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create A2A client: %w", err)
+	}
+	c.client = conn.client
+	c.agentCard = conn.agentCard
+	c.merchantURL = merchantURL
+	c.lister = newJSONRPCTaskLister(conn.rpcEndpoint, c.httpClient, conn.extensionURIs, c.headers)
+	return nil
+}
+
+// Close releases the connection to the merchant.
+func (c *Client) Close() error {
+	if destroyer, ok := c.client.(interface{ Destroy() error }); ok {
+		return destroyer.Destroy()
+	}
+	return nil
 }
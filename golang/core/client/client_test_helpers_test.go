@@ -16,6 +16,7 @@ package client
 
 import (
 	"context"
+	"time"
 
 	"github.com/a2aproject/a2a-go/a2a"
 	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
@@ -79,3 +80,35 @@ func newPaymentRequiredTask(id string) *a2a.Task {
 	})
 	return task
 }
+
+// fakeClock advances instantly on every After call. Once the optional deadline
+// is reached it runs expire and returns a channel that never fires, so the
+// caller observes the cancellation deterministically.
+type fakeClock struct {
+	now      time.Time
+	waits    []time.Duration
+	deadline time.Time
+	expire   func()
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (f *fakeClock) Now() time.Time {
+	return f.now
+}
+
+func (f *fakeClock) After(d time.Duration) <-chan time.Time {
+	f.waits = append(f.waits, d)
+	f.now = f.now.Add(d)
+	if !f.deadline.IsZero() && !f.now.Before(f.deadline) {
+		if f.expire != nil {
+			f.expire()
+		}
+		return make(chan time.Time)
+	}
+	ch := make(chan time.Time, 1)
+	ch <- f.now
+	return ch
+}
@@ -0,0 +1,110 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/a2aproject/a2a-go/a2a"
+)
+
+// DedupStore maps caller-supplied idempotency keys to merchant task IDs so a
+// restarted client resumes a task instead of paying for it twice.
+type DedupStore interface {
+	Get(ctx context.Context, key string) (a2a.TaskID, bool, error)
+	Set(ctx context.Context, key string, taskID a2a.TaskID) error
+}
+
+// FileDedupStore is a DedupStore persisted as a JSON file. Writes replace the
+// file atomically so a crash never leaves a partial mapping behind.
+type FileDedupStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileDedupStore(path string) (*FileDedupStore, error) {
+	if path == "" {
+		return nil, fmt.Errorf("dedup store path is required")
+	}
+	return &FileDedupStore{path: path}, nil
+}
+
+func (s *FileDedupStore) Get(ctx context.Context, key string) (a2a.TaskID, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.load()
+	if err != nil {
+		return "", false, err
+	}
+	taskID, ok := entries[key]
+	return taskID, ok, nil
+}
+
+func (s *FileDedupStore) Set(ctx context.Context, key string, taskID a2a.TaskID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.load()
+	if err != nil {
+		return err
+	}
+	entries[key] = taskID
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to encode dedup store: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write dedup store: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write dedup store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write dedup store: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to write dedup store: %w", err)
+	}
+	return nil
+}
+
+func (s *FileDedupStore) load() (map[string]a2a.TaskID, error) {
+	entries := make(map[string]a2a.TaskID)
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dedup store: %w", err)
+	}
+	if len(data) == 0 {
+		return entries, nil
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode dedup store: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+func TestFileDedupStorePersistsAcrossInstances(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dedup.json")
+	first, err := NewFileDedupStore(path)
+	if err != nil {
+		t.Fatalf("NewFileDedupStore() error = %v", err)
+	}
+	if _, found, err := first.Get(context.Background(), "job-1"); err != nil || found {
+		t.Fatalf("Get() on empty store found = %v, error = %v", found, err)
+	}
+	if err := first.Set(context.Background(), "job-1", "task-1"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	second, _ := NewFileDedupStore(path)
+	taskID, found, err := second.Get(context.Background(), "job-1")
+	if err != nil || !found || taskID != "task-1" {
+		t.Fatalf("Get() = %q, %v, %v", taskID, found, err)
+	}
+}
+
+func TestWaitForCompletionWithKeyResumesAfterCrash(t *testing.T) {
+	store, err := NewFileDedupStore(filepath.Join(t.TempDir(), "dedup.json"))
+	if err != nil {
+		t.Fatalf("NewFileDedupStore() error = %v", err)
+	}
+
+	// The first instance sends the request and crashes while polling.
+	paymentRequired := newPaymentRequiredTask("crash-task")
+	submitted := newClientTestTask("crash-task", a2a.TaskStateWorking, state.PaymentSubmitted)
+	firstA2A := &mockTaskClient{sendMessageFunc: func(_ context.Context, params *a2a.MessageSendParams) (a2a.SendMessageResult, error) {
+		if params.Message.TaskID == "" {
+			return paymentRequired, nil
+		}
+		return submitted, nil
+	}}
+	firstPayments := &mockPaymentProcessor{processFunc: func(ctx context.Context, taskID a2a.TaskID, _ *x402types.PaymentRequired) (*a2a.Message, error) {
+		return a2a.NewMessageForTask(a2a.MessageRoleUser, a2a.TaskInfo{TaskID: taskID}, a2a.TextPart{Text: "paid"}), nil
+	}}
+	ctx, crash := context.WithCancel(context.Background())
+	clock := newFakeClock()
+	clock.deadline = clock.Now()
+	clock.expire = crash
+	first := &Client{client: firstA2A, x402Client: firstPayments, clock: clock, pollInterval: time.Second, dedupStore: store}
+
+	if _, err := first.WaitForCompletionWithKey(ctx, "job-1", "generate"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("first instance error = %v, want context.Canceled", err)
+	}
+	if firstPayments.calls != 1 {
+		t.Fatalf("first instance payments = %d, want 1", firstPayments.calls)
+	}
+
+	// The restarted instance resumes the same task without resending or paying.
+	completed := newClientTestTask("crash-task", a2a.TaskStateCompleted, state.PaymentCompleted)
+	secondA2A := &mockTaskClient{}
+	secondA2A.getTaskFunc = func(_ context.Context, query *a2a.TaskQueryParams) (*a2a.Task, error) {
+		if query.ID != "crash-task" {
+			t.Errorf("GetTask() id = %s", query.ID)
+		}
+		if secondA2A.getCalls == 1 {
+			return submitted, nil
+		}
+		return completed, nil
+	}
+	secondPayments := &mockPaymentProcessor{}
+	second := &Client{client: secondA2A, x402Client: secondPayments, clock: newFakeClock(), pollInterval: time.Second, dedupStore: store}
+
+	task, err := second.WaitForCompletionWithKey(context.Background(), "job-1", "generate")
+	if err != nil || task != completed {
+		t.Fatalf("second instance task = %#v, error = %v", task, err)
+	}
+	if secondA2A.sendCalls != 0 || secondPayments.calls != 0 {
+		t.Fatalf("second instance sends = %d, payments = %d; want none", secondA2A.sendCalls, secondPayments.calls)
+	}
+
+	// A third instance finds the finished task and returns it immediately.
+	thirdA2A := &mockTaskClient{getTaskFunc: func(context.Context, *a2a.TaskQueryParams) (*a2a.Task, error) {
+		return completed, nil
+	}}
+	third := &Client{client: thirdA2A, x402Client: &mockPaymentProcessor{}, clock: newFakeClock(), dedupStore: store}
+	task, err = third.WaitForCompletionWithKey(context.Background(), "job-1", "generate")
+	if err != nil || task != completed || thirdA2A.getCalls != 1 || thirdA2A.sendCalls != 0 {
+		t.Fatalf("third instance task = %#v, error = %v, gets = %d, sends = %d", task, err, thirdA2A.getCalls, thirdA2A.sendCalls)
+	}
+}
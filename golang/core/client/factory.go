@@ -0,0 +1,129 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+)
+
+// ErrFactoryClosed is returned by ClientFor after the factory has been closed.
+var ErrFactoryClosed = errors.New("client factory is closed")
+
+// Factory creates clients for many merchants that share one set of signers, one
+// budget and one http.Client.
+type Factory struct {
+	x402Client *X402Client
+	httpClient *http.Client
+	opts       []Option
+
+	mu      sync.Mutex
+	clients map[string]*Client
+	closed  bool
+}
+
+func NewFactory(networkKeyPairs []types.NetworkKeyPair, opts ...Option) (*Factory, error) {
+	template, err := newConfiguredClient(opts)
+	if err != nil {
+		return nil, err
+	}
+	x402Client, err := newX402Client(networkKeyPairs, template.smartAccounts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create x402 client wrapper: %w", err)
+	}
+	x402Client.budget = template.budget
+
+	httpClient := template.httpClient
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+
+	return &Factory{
+		x402Client: x402Client,
+		httpClient: httpClient,
+		opts:       append(append([]Option(nil), opts...), WithHTTPClient(httpClient)),
+		clients:    make(map[string]*Client),
+	}, nil
+}
+
+// ClientFor returns the client for merchantURL, connecting on first use. The
+// connection is made without holding the factory's lock, so a slow merchant
+// does not hold up clients for the others.
+func (f *Factory) ClientFor(ctx context.Context, merchantURL string) (*Client, error) {
+	key := strings.TrimRight(merchantURL, "/")
+	if c, ok, err := f.cached(key); ok || err != nil {
+		return c, err
+	}
+
+	c, err := newConfiguredClient(f.opts)
+	if err != nil {
+		return nil, err
+	}
+	c.x402Client = f.x402Client
+	if err := c.connect(ctx, key); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		_ = c.Close()
+		return nil, ErrFactoryClosed
+	}
+	// A concurrent call for the same merchant may have connected first.
+	if existing, ok := f.clients[key]; ok {
+		_ = c.Close()
+		return existing, nil
+	}
+	f.clients[key] = c
+	return c, nil
+}
+
+func (f *Factory) cached(key string) (*Client, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return nil, false, ErrFactoryClosed
+	}
+	c, ok := f.clients[key]
+	return c, ok, nil
+}
+
+// Close closes every client created by the factory. Clients returned earlier
+// must not be used afterwards.
+func (f *Factory) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+
+	var errs []error
+	for url, c := range f.clients {
+		if err := c.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close client for %s: %w", url, err))
+		}
+	}
+	f.clients = nil
+	f.httpClient.CloseIdleConnections()
+	return errors.Join(errs...)
+}
@@ -0,0 +1,139 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+)
+
+func newAgentCardServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		card := a2a.AgentCard{
+			Name:               "merchant",
+			URL:                server.URL + "/rpc",
+			PreferredTransport: a2a.TransportProtocolJSONRPC,
+			Capabilities: a2a.AgentCapabilities{Extensions: []a2a.AgentExtension{
+				{URI: x402pkg.X402ExtensionURI},
+			}},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(card)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFactorySharesBudgetAcrossMerchants(t *testing.T) {
+	first := newAgentCardServer(t)
+	second := newAgentCardServer(t)
+	budget := NewBudget(big.NewInt(150))
+
+	factory, err := NewFactory(
+		[]types.NetworkKeyPair{{NetworkName: x402pkg.NetworkBaseSepolia, PrivateKey: testOwnerKey}},
+		WithBudget(budget),
+	)
+	if err != nil {
+		t.Fatalf("NewFactory() error = %v", err)
+	}
+	ctx := context.Background()
+
+	firstClient, err := factory.ClientFor(ctx, first.URL)
+	if err != nil {
+		t.Fatalf("ClientFor(first) error = %v", err)
+	}
+	cached, err := factory.ClientFor(ctx, first.URL+"/")
+	if err != nil || cached != firstClient {
+		t.Fatalf("ClientFor(first) did not reuse the cached client: %v", err)
+	}
+	secondClient, err := factory.ClientFor(ctx, second.URL)
+	if err != nil {
+		t.Fatalf("ClientFor(second) error = %v", err)
+	}
+	if firstClient.x402Client != secondClient.x402Client {
+		t.Fatal("clients must share one x402 client")
+	}
+
+	if _, err := firstClient.x402Client.ProcessPaymentRequired(ctx, "task-1", newBaseSepoliaPaymentRequired()); err != nil {
+		t.Fatalf("first payment error = %v", err)
+	}
+	_, err = secondClient.x402Client.ProcessPaymentRequired(ctx, "task-2", newBaseSepoliaPaymentRequired())
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("second payment error = %v, want ErrBudgetExceeded", err)
+	}
+	if remaining := budget.Remaining(); remaining.Int64() != 50 {
+		t.Fatalf("remaining budget = %s, want 50", remaining)
+	}
+
+	if err := factory.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if _, err := factory.ClientFor(ctx, first.URL); !errors.Is(err, ErrFactoryClosed) {
+		t.Fatalf("ClientFor() after Close error = %v", err)
+	}
+}
+
+func TestFactoryConnectsWithoutBlockingOtherMerchants(t *testing.T) {
+	fast := newAgentCardServer(t)
+	requested := make(chan struct{}, 1)
+	unblock := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case requested <- struct{}{}:
+		default:
+		}
+		<-unblock
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(slow.Close)
+	t.Cleanup(func() { close(unblock) })
+
+	factory, err := NewFactory([]types.NetworkKeyPair{{NetworkName: x402pkg.NetworkBaseSepolia, PrivateKey: testOwnerKey}})
+	if err != nil {
+		t.Fatalf("NewFactory() error = %v", err)
+	}
+	defer factory.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _, _ = factory.ClientFor(ctx, slow.URL) }()
+	<-requested
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := factory.ClientFor(context.Background(), fast.URL)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("ClientFor(fast) error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ClientFor(fast) waited on the slow merchant's connection")
+	}
+}
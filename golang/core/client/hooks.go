@@ -0,0 +1,125 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// Hooks observe the payment lifecycle. Every hook is optional and is called
+// synchronously; a panicking hook is recovered and logged so it cannot break
+// the payment flow. For a single payment the order is OnQuote, OnApproved,
+// OnSubmitted and then one of OnCompleted, OnFailed or OnRejected.
+type Hooks struct {
+	// OnQuote receives the merchant's payment requirements before signing.
+	OnQuote func(ctx context.Context, taskID a2a.TaskID, requirements *x402types.PaymentRequired)
+	// OnApproved receives the signed payload, whose Accepted field holds the
+	// amount that was authorized.
+	OnApproved func(ctx context.Context, taskID a2a.TaskID, payload *x402types.PaymentPayload)
+	// OnSubmitted is called once the merchant has accepted the payment message.
+	OnSubmitted func(ctx context.Context, taskID a2a.TaskID, payload *x402types.PaymentPayload)
+	// OnCompleted receives the settlement receipts.
+	OnCompleted func(ctx context.Context, taskID a2a.TaskID, receipts []*x402core.SettleResponse)
+	// OnFailed is called when the client could not pay or the merchant failed
+	// the payment.
+	OnFailed func(ctx context.Context, taskID a2a.TaskID, err error)
+	// OnRejected is called when the payment was rejected.
+	OnRejected func(ctx context.Context, taskID a2a.TaskID, err error)
+}
+
+// hookRunner calls the registered Hooks, logging recovered panics to the
+// client's logger.
+type hookRunner struct {
+	Hooks
+	logger *slog.Logger
+}
+
+func callHook(ctx context.Context, logger *slog.Logger, name string, task *a2a.Task, fn func()) {
+	defer func() {
+		if r := recover(); r != nil && logger != nil {
+			logger.ErrorContext(ctx, "x402 hook panicked",
+				"task_id", task.ID,
+				"context_id", task.ContextID,
+				"hook", name,
+				"panic", r,
+			)
+		}
+	}()
+	fn()
+}
+
+func (h hookRunner) quote(ctx context.Context, task *a2a.Task, requirements *x402types.PaymentRequired) {
+	if h.OnQuote != nil {
+		callHook(ctx, h.logger, "OnQuote", task, func() { h.OnQuote(ctx, task.ID, requirements) })
+	}
+}
+
+func (h hookRunner) approved(ctx context.Context, task *a2a.Task, payload *x402types.PaymentPayload) {
+	if h.OnApproved != nil {
+		callHook(ctx, h.logger, "OnApproved", task, func() { h.OnApproved(ctx, task.ID, payload) })
+	}
+}
+
+func (h hookRunner) submitted(ctx context.Context, task *a2a.Task, payload *x402types.PaymentPayload) {
+	if h.OnSubmitted != nil {
+		callHook(ctx, h.logger, "OnSubmitted", task, func() { h.OnSubmitted(ctx, task.ID, payload) })
+	}
+}
+
+func (h hookRunner) failed(ctx context.Context, task *a2a.Task, err error) {
+	if h.OnFailed != nil {
+		callHook(ctx, h.logger, "OnFailed", task, func() { h.OnFailed(ctx, task.ID, err) })
+	}
+}
+
+// outcome reports the final payment status of task, returning true when the
+// task carried one.
+func (h hookRunner) outcome(ctx context.Context, task *a2a.Task) bool {
+	status, err := state.ExtractPaymentStatusFromTask(task)
+	if err != nil {
+		return false
+	}
+	switch status {
+	case state.PaymentCompleted:
+		if h.OnCompleted != nil {
+			receipts, _ := state.ExtractPaymentReceipts(task)
+			callHook(ctx, h.logger, "OnCompleted", task, func() { h.OnCompleted(ctx, task.ID, receipts) })
+		}
+	case state.PaymentFailed:
+		h.failed(ctx, task, outcomeError("payment failed", task))
+	case state.PaymentRejected:
+		if h.OnRejected != nil {
+			err := outcomeError("payment rejected", task)
+			callHook(ctx, h.logger, "OnRejected", task, func() { h.OnRejected(ctx, task.ID, err) })
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+func outcomeError(prefix string, task *a2a.Task) error {
+	if msg := extractErrorMessage(task); msg != "" {
+		return fmt.Errorf("%s: %s", prefix, msg)
+	}
+	return fmt.Errorf("%s", prefix)
+}
@@ -0,0 +1,137 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+func newHookRecorder(calls *[]string) Hooks {
+	return Hooks{
+		OnQuote: func(_ context.Context, _ a2a.TaskID, requirements *x402types.PaymentRequired) {
+			*calls = append(*calls, "quote")
+		},
+		OnApproved: func(_ context.Context, _ a2a.TaskID, payload *x402types.PaymentPayload) {
+			*calls = append(*calls, "approved:"+payload.Accepted.Amount)
+		},
+		OnSubmitted: func(context.Context, a2a.TaskID, *x402types.PaymentPayload) {
+			*calls = append(*calls, "submitted")
+		},
+		OnCompleted: func(_ context.Context, _ a2a.TaskID, receipts []*x402core.SettleResponse) {
+			*calls = append(*calls, "completed")
+		},
+		OnFailed: func(_ context.Context, _ a2a.TaskID, err error) {
+			*calls = append(*calls, "failed")
+		},
+		OnRejected: func(context.Context, a2a.TaskID, error) {
+			*calls = append(*calls, "rejected")
+		},
+	}
+}
+
+func newHookTestClient(t *testing.T, submissionResult *a2a.Task, polled *a2a.Task, hooks Hooks) *Client {
+	t.Helper()
+	a2aClient := &mockTaskClient{
+		sendMessageFunc: func(_ context.Context, params *a2a.MessageSendParams) (a2a.SendMessageResult, error) {
+			if params.Message.TaskID == "" {
+				return newPaymentRequiredTask("hooks"), nil
+			}
+			return submissionResult, nil
+		},
+		getTaskFunc: func(context.Context, *a2a.TaskQueryParams) (*a2a.Task, error) {
+			return polled, nil
+		},
+	}
+	payments := &mockPaymentProcessor{processFunc: func(_ context.Context, taskID a2a.TaskID, required *x402types.PaymentRequired) (*a2a.Message, error) {
+		return state.EncodePaymentSubmission(taskID, &x402types.PaymentPayload{
+			X402Version: x402pkg.X402Version,
+			Accepted:    required.Accepts[0],
+			Payload:     map[string]interface{}{"signature": "0x01"},
+		})
+	}}
+	return &Client{client: a2aClient, x402Client: payments, clock: newFakeClock(), pollInterval: time.Second, hooks: hookRunner{Hooks: hooks}}
+}
+
+func TestHooksOrderForSuccessfulPayment(t *testing.T) {
+	var calls []string
+	submitted := newClientTestTask("hooks", a2a.TaskStateWorking, state.PaymentSubmitted)
+	completed := newClientTestTask("hooks", a2a.TaskStateCompleted, "")
+	if err := state.RecordPaymentCompleted(completed, []*x402core.SettleResponse{{Success: true}}, "done"); err != nil {
+		t.Fatalf("RecordPaymentCompleted() error = %v", err)
+	}
+	c := newHookTestClient(t, submitted, completed, newHookRecorder(&calls))
+
+	if _, err := c.WaitForCompletion(context.Background(), "request"); err != nil {
+		t.Fatalf("WaitForCompletion() error = %v", err)
+	}
+	want := []string{"quote", "approved:100", "submitted", "completed"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("hook calls = %v, want %v", calls, want)
+	}
+}
+
+func TestHooksOrderForVerificationFailure(t *testing.T) {
+	var calls []string
+	failed := newClientTestTask("hooks", a2a.TaskStateFailed, "")
+	if err := state.RecordPaymentFailed(failed, x402pkg.ErrorCodeInvalidSignature, "invalid signature", &x402core.SettleResponse{}); err != nil {
+		t.Fatalf("RecordPaymentFailed() error = %v", err)
+	}
+	c := newHookTestClient(t, failed, nil, newHookRecorder(&calls))
+
+	if _, err := c.WaitForCompletion(context.Background(), "request"); err != nil {
+		t.Fatalf("WaitForCompletion() error = %v", err)
+	}
+	want := []string{"quote", "approved:100", "submitted", "failed"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("hook calls = %v, want %v", calls, want)
+	}
+}
+
+func TestHooksRecoverFromPanics(t *testing.T) {
+	var calls []string
+	hooks := newHookRecorder(&calls)
+	hooks.OnQuote = func(context.Context, a2a.TaskID, *x402types.PaymentRequired) {
+		panic("analytics outage")
+	}
+	submitted := newClientTestTask("hooks", a2a.TaskStateWorking, state.PaymentSubmitted)
+	completed := newClientTestTask("hooks", a2a.TaskStateCompleted, state.PaymentCompleted)
+	c := newHookTestClient(t, submitted, completed, hooks)
+	var logs bytes.Buffer
+	c.hooks.logger = slog.New(slog.NewJSONHandler(&logs, nil))
+
+	task, err := c.WaitForCompletion(context.Background(), "request")
+	if err != nil || task != completed {
+		t.Fatalf("task = %#v, error = %v", task, err)
+	}
+	want := []string{"approved:100", "submitted", "completed"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("hook calls = %v, want %v", calls, want)
+	}
+	if !strings.Contains(logs.String(), `"hook":"OnQuote"`) || !strings.Contains(logs.String(), `"panic":"analytics outage"`) {
+		t.Errorf("logs = %s, want the OnQuote panic", logs.String())
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	x402evm "github.com/x402-foundation/x402/go/mechanisms/evm"
+)
+
+// Option configures optional Client behavior.
+type Option func(*Client)
+
+// WithInputHandler sets the callback used when the merchant pauses a task in
+// input-required without any x402 payment metadata.
+func WithInputHandler(handler InputHandler) Option {
+	return func(c *Client) {
+		c.inputHandler = handler
+	}
+}
+
+// WithClock replaces the clock used by the polling loop and time-based checks.
+func WithClock(clock Clock) Option {
+	return func(c *Client) {
+		c.clock = clock
+	}
+}
+
+// WithPollInterval sets how long WaitForCompletion waits between task polls.
+func WithPollInterval(interval time.Duration) Option {
+	return func(c *Client) {
+		c.pollInterval = interval
+	}
+}
+
+// WithSweepAge sets how long a task may wait in payment-required before Sweep
+// rejects it.
+func WithSweepAge(age time.Duration) Option {
+	return func(c *Client) {
+		c.sweepAge = age
+	}
+}
+
+// WithHTTPClient sets the http.Client shared by agent card discovery and all
+// A2A requests to the merchant.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithHeaders adds headers, such as gateway credentials, to every request sent
+// to the merchant including the agent card fetch.
+func WithHeaders(headers map[string]string) Option {
+	return func(c *Client) {
+		c.headers = headers
+	}
+}
+
+// WithAgentCardTimeout bounds how long NewClient waits for the agent card.
+func WithAgentCardTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.cardTimeout = timeout
+	}
+}
+
+// WithSmartAccount pays on network from an ERC-1271 contract wallet at account,
+// with owner producing the signatures the wallet validates.
+func WithSmartAccount(network string, account string, owner x402evm.ClientEvmSigner) Option {
+	return func(c *Client) {
+		signer, err := NewSmartAccountSigner(account, owner)
+		if err != nil {
+			c.optionErr = fmt.Errorf("invalid smart account for network %s: %w", network, err)
+			return
+		}
+		c.smartAccounts = append(c.smartAccounts, smartAccount{network: network, signer: signer})
+	}
+}
+
+// WithBudget caps the total amount the client may authorize. The same Budget can
+// be shared by several clients.
+func WithBudget(budget *Budget) Option {
+	return func(c *Client) {
+		c.budget = budget
+	}
+}
+
+// WithDedupStore sets the store WaitForCompletionWithKey uses to map
+// idempotency keys to merchant tasks.
+func WithDedupStore(store DedupStore) Option {
+	return func(c *Client) {
+		c.dedupStore = store
+	}
+}
+
+// WithMaxBackoff caps how long the polling loop waits when the merchant asks it
+// to back off.
+func WithMaxBackoff(maxBackoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxBackoff = maxBackoff
+	}
+}
+
+// WithHooks registers callbacks for the payment lifecycle.
+func WithHooks(hooks Hooks) Option {
+	return func(c *Client) {
+		c.hooks.Hooks = hooks
+	}
+}
+
+// WithLogger sets the logger that reports hooks recovered from a panic. The
+// default logger discards everything.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		if logger != nil {
+			c.logger = logger
+		}
+	}
+}
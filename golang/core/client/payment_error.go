@@ -0,0 +1,124 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+)
+
+// Errors a PaymentError matches with errors.Is, one per family of x402 error
+// codes. Every PaymentError also matches ErrPaymentFailed.
+var (
+	ErrPaymentFailed       = errors.New("payment failed")
+	ErrInsufficientFunds   = errors.New("insufficient funds")
+	ErrInvalidPayment      = errors.New("payment rejected as invalid")
+	ErrPaymentExpired      = errors.New("payment authorization expired")
+	ErrPaymentMismatch     = errors.New("payment does not match the quote")
+	ErrFacilitatorTimeout  = errors.New("facilitator did not answer in time")
+	ErrSettlementFailed    = errors.New("settlement failed")
+	ErrPayerNotAllowed     = errors.New("payer rejected by merchant policy")
+	ErrAuthorizationVoided = errors.New("payment authorization voided")
+	ErrBusinessFailed      = errors.New("merchant service failed")
+	ErrExtensionRequired   = errors.New("x402 extension required")
+	ErrInvalidRequest      = errors.New("merchant could not route the request")
+	ErrMerchantInternal    = errors.New("merchant internal error")
+	ErrMerchantBusy        = errors.New("merchant at capacity")
+	ErrTooManyAttempts     = errors.New("too many failed payment attempts")
+	ErrUnconfirmed         = errors.New("settlement not confirmed in time")
+)
+
+var errorsByCode = map[string]error{
+	x402pkg.ErrorCodeInsufficientFunds:       ErrInsufficientFunds,
+	x402pkg.ErrorCodeInvalidSignature:        ErrInvalidPayment,
+	x402pkg.ErrorCodeInvalidPayload:          ErrInvalidPayment,
+	x402pkg.ErrorCodeMalformedPayload:        ErrInvalidPayment,
+	x402pkg.ErrorCodeDuplicateNonce:          ErrInvalidPayment,
+	x402pkg.ErrorCodeExpiredPayment:          ErrPaymentExpired,
+	x402pkg.ErrorCodeQuoteExpiredRequote:     ErrPaymentExpired,
+	x402pkg.ErrorCodeQuoteExpired:            ErrPaymentExpired,
+	x402pkg.ErrorCodeNetworkMismatch:         ErrPaymentMismatch,
+	x402pkg.ErrorCodeInvalidAmount:           ErrPaymentMismatch,
+	x402pkg.ErrorCodePayloadMismatch:         ErrPaymentMismatch,
+	x402pkg.ErrorCodeUnsupportedOption:       ErrPaymentMismatch,
+	x402pkg.ErrorCodeTamperedRequirements:    ErrPaymentMismatch,
+	x402pkg.ErrorCodeTaskBindingMismatch:     ErrPaymentMismatch,
+	x402pkg.ErrorCodeAmountMismatch:          ErrPaymentMismatch,
+	x402pkg.ErrorCodeVerifyTimeout:           ErrFacilitatorTimeout,
+	x402pkg.ErrorCodeSettleTimeout:           ErrFacilitatorTimeout,
+	x402pkg.ErrorCodeSettlementFailed:        ErrSettlementFailed,
+	x402pkg.ErrorCodePayerNotAllowed:         ErrPayerNotAllowed,
+	x402pkg.ErrorCodeAuthorizationVoided:     ErrAuthorizationVoided,
+	x402pkg.ErrorCodeBusinessExecutionFailed: ErrBusinessFailed,
+	x402pkg.ErrorCodeBusinessTimeout:         ErrBusinessFailed,
+	x402pkg.ErrorCodeMeteredAmountExceeded:   ErrBusinessFailed,
+	x402pkg.ErrorCodeExtensionRequired:       ErrExtensionRequired,
+	x402pkg.ErrorCodeInvalidRequest:          ErrInvalidRequest,
+	x402pkg.ErrorCodeOrchestratorStuck:       ErrMerchantInternal,
+	x402pkg.ErrorCodeInternal:                ErrMerchantInternal,
+	x402pkg.ErrorCodeMerchantBusy:            ErrMerchantBusy,
+	x402pkg.ErrorCodeCapacityExceeded:        ErrMerchantBusy,
+	x402pkg.ErrorCodeTooManyPaymentAttempts:  ErrTooManyAttempts,
+	x402pkg.ErrorCodeConfirmationTimeout:     ErrUnconfirmed,
+}
+
+// PaymentError describes a task the merchant ended with an x402 error code.
+type PaymentError struct {
+	TaskID a2a.TaskID
+	State  a2a.TaskState
+	Code   string
+	// Message is the merchant's explanation, when it gave one.
+	Message string
+}
+
+func (e *PaymentError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("task %s %s with %s: %s", e.TaskID, e.State, e.Code, e.Message)
+	}
+	return fmt.Sprintf("task %s %s with %s", e.TaskID, e.State, e.Code)
+}
+
+// Is matches ErrPaymentFailed and the error registered for the code.
+func (e *PaymentError) Is(target error) bool {
+	return target == ErrPaymentFailed || (target != nil && target == errorsByCode[e.Code])
+}
+
+// Retryable reports whether paying again against a fresh quote may succeed.
+func (e *PaymentError) Retryable() bool {
+	return x402pkg.Retryable(e.Code)
+}
+
+// PaymentErrorOf returns a *PaymentError when task ended with an x402 error
+// code, and nil otherwise.
+func PaymentErrorOf(task *a2a.Task) error {
+	if task == nil || task.Status.Message == nil || !task.Status.State.Terminal() {
+		return nil
+	}
+	meta := task.Status.Message.Meta()
+	code, _ := meta[x402pkg.MetadataKeyError].(string)
+	if code == "" {
+		return nil
+	}
+	return &PaymentError{
+		TaskID:  task.ID,
+		State:   task.Status.State,
+		Code:    code,
+		Message: state.ExtractMessageText(task.Status.Message),
+	}
+}
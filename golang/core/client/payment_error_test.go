@@ -0,0 +1,80 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+)
+
+func TestEveryErrorCodeMapsToAnError(t *testing.T) {
+	for _, code := range x402pkg.ErrorCodes() {
+		if errorsByCode[code] == nil {
+			t.Errorf("error code %s has no client error", code)
+		}
+	}
+}
+
+func TestPaymentErrorOf(t *testing.T) {
+	failed := newClientTestTask("failed", a2a.TaskStateFailed, state.PaymentFailed)
+	state.SetPaymentError(failed.Status.Message, x402pkg.ErrorCodeInsufficientFunds)
+	expired := newClientTestTask("expired", a2a.TaskStateFailed, state.PaymentFailed)
+	state.SetPaymentError(expired.Status.Message, x402pkg.ErrorCodeExpiredPayment)
+	rejected := newClientTestTask("rejected", a2a.TaskStateRejected, state.PaymentRejected)
+	state.SetPaymentError(rejected.Status.Message, x402pkg.ErrorCodePayerNotAllowed)
+	requoted := newClientTestTask("requoted", a2a.TaskStateInputRequired, state.PaymentRequired)
+	state.SetPaymentError(requoted.Status.Message, x402pkg.ErrorCodeQuoteExpiredRequote)
+
+	tests := []struct {
+		name          string
+		task          *a2a.Task
+		want          error
+		wantRetryable bool
+	}{
+		{name: "completed", task: newClientTestTask("completed", a2a.TaskStateCompleted, state.PaymentCompleted)},
+		{name: "awaiting a fresh quote", task: requoted},
+		{name: "insufficient funds", task: failed, want: ErrInsufficientFunds},
+		{name: "expired", task: expired, want: ErrPaymentExpired, wantRetryable: true},
+		{name: "payer rejected", task: rejected, want: ErrPayerNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := PaymentErrorOf(tt.task)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("PaymentErrorOf() = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.want) || !errors.Is(err, ErrPaymentFailed) {
+				t.Fatalf("PaymentErrorOf() = %v, want it to match %v and ErrPaymentFailed", err, tt.want)
+			}
+			if errors.Is(err, ErrSettlementFailed) {
+				t.Errorf("PaymentErrorOf() = %v matches an unrelated error", err)
+			}
+			var paymentErr *PaymentError
+			if !errors.As(err, &paymentErr) || paymentErr.TaskID != tt.task.ID {
+				t.Fatalf("PaymentErrorOf() = %#v", err)
+			}
+			if paymentErr.Retryable() != tt.wantRetryable {
+				t.Errorf("Retryable() = %v, want %v", paymentErr.Retryable(), tt.wantRetryable)
+			}
+		})
+	}
+}
@@ -75,14 +75,21 @@ func (c *Client) processPaymentState(
 			return task, false, fmt.Errorf("x402 client is required")
 		}
 
+		c.hooks.quote(ctx, task, paymentState.Requirements)
 		paymentMessage, err := c.x402Client.ProcessPaymentRequired(ctx, task.ID, paymentState.Requirements)
 		if err != nil {
-			return task, false, fmt.Errorf("failed to process payment requirements: %w", err)
+			err = fmt.Errorf("failed to process payment requirements: %w", err)
+			c.hooks.failed(ctx, task, err)
+			return task, false, err
 		}
+		payload, _ := state.ExtractPaymentPayload(nil, paymentMessage)
+		c.hooks.approved(ctx, task, payload)
 
 		updatedTask, directMessage, err := SendMessage(ctx, c.client, paymentMessage)
 		if err != nil {
-			return task, false, fmt.Errorf("failed to send payment message: %w", err)
+			err = fmt.Errorf("failed to send payment message: %w", err)
+			c.hooks.failed(ctx, task, err)
+			return task, false, err
 		}
 		if updatedTask == nil {
 			if directMessage != nil {
@@ -90,6 +97,7 @@ func (c *Client) processPaymentState(
 			}
 			return task, true, fmt.Errorf("payment submission returned no task")
 		}
+		c.hooks.submitted(ctx, task, payload)
 		return updatedTask, true, nil
 
 	case state.PaymentCompleted:
@@ -108,6 +116,45 @@ func (c *Client) processPaymentState(
 		return task, false, fmt.Errorf("payment rejected")
 
 	default:
+		if paymentState.Status == "" && task.Status.State == a2a.TaskStateInputRequired {
+			updatedTask, err := c.processInputRequired(ctx, task)
+			return updatedTask, false, err
+		}
 		return task, false, nil
 	}
 }
+
+// processInputRequired answers a non-payment input request through the configured
+// InputHandler, or fails fast when none is configured.
+func (c *Client) processInputRequired(ctx context.Context, task *a2a.Task) (*a2a.Task, error) {
+	if c.inputHandler == nil {
+		if msg := extractErrorMessage(task); msg != "" {
+			return task, fmt.Errorf("%w: %s", ErrInputRequired, msg)
+		}
+		return task, ErrInputRequired
+	}
+
+	reply, err := c.inputHandler(ctx, task)
+	if err != nil {
+		return task, fmt.Errorf("input handler failed: %w", err)
+	}
+	if reply == nil {
+		return task, fmt.Errorf("input handler returned no message")
+	}
+	reply.TaskID = task.ID
+	if reply.ContextID == "" {
+		reply.ContextID = task.ContextID
+	}
+
+	updatedTask, directMessage, err := SendMessage(ctx, c.client, reply)
+	if err != nil {
+		return task, fmt.Errorf("failed to send input message: %w", err)
+	}
+	if updatedTask == nil {
+		if directMessage != nil {
+			return task, fmt.Errorf("input submission returned a direct message instead of a task")
+		}
+		return task, fmt.Errorf("input submission returned no task")
+	}
+	return updatedTask, nil
+}
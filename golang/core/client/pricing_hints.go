@@ -0,0 +1,72 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+)
+
+// SkillPricingHint is an indicative price a merchant advertises for a skill
+// on one network and asset. The quote in the payment-required response is
+// what the client actually pays.
+type SkillPricingHint struct {
+	Network string `json:"network"`
+	Asset   string `json:"asset"`
+	// Price is in whole tokens, such as "0.10", or fiat, such as "USD 1.50".
+	Price  string `json:"price"`
+	Scheme string `json:"scheme"`
+}
+
+// FetchAgentCard fetches the agent card a merchant publishes at its
+// well-known path, so merchants can be compared before connecting.
+func FetchAgentCard(ctx context.Context, merchantURL string) (*a2a.AgentCard, error) {
+	return fetchAgentCard(ctx, merchantURL+"/.well-known/agent-card.json", connectOptions{})
+}
+
+// SkillPricingHints returns the pricing hints the card's x402 extension
+// advertises, keyed by skill ID. A card without hints yields an empty map.
+func SkillPricingHints(card *a2a.AgentCard) (map[string][]SkillPricingHint, error) {
+	hints := make(map[string][]SkillPricingHint)
+	if card == nil {
+		return hints, nil
+	}
+	for _, ext := range card.Capabilities.Extensions {
+		if ext.URI != x402pkg.X402ExtensionURI {
+			continue
+		}
+		raw, ok := ext.Params[x402pkg.ExtensionParamSkillPricing]
+		if !ok {
+			continue
+		}
+		data, err := json.Marshal(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid skill pricing: %w", err)
+		}
+		if err := json.Unmarshal(data, &hints); err != nil {
+			return nil, fmt.Errorf("invalid skill pricing: %w", err)
+		}
+	}
+	return hints, nil
+}
+
+// AgentCard returns the agent card fetched when the client connected.
+func (c *Client) AgentCard() *a2a.AgentCard {
+	return c.agentCard
+}
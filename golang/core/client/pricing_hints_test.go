@@ -0,0 +1,79 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+)
+
+const pricedAgentCard = `{
+	"name": "merchant",
+	"url": "http://localhost:8080/rpc",
+	"preferredTransport": "JSONRPC",
+	"capabilities": {"extensions": [
+		{"uri": "https://example.com/other", "params": {"skillPricing": {"ignored": []}}},
+		{"uri": "` + x402pkg.X402ExtensionURI + `", "required": true, "params": {
+			"pricing": {"summarize": "$0.10"},
+			"skillPricing": {"summarize": [
+				{"network": "eip155:8453", "asset": "0xusdc", "price": "0.10", "scheme": "exact"},
+				{"network": "eip155:84532", "asset": "0xtest", "price": "USD 0.10", "scheme": "upto"}
+			]}
+		}}
+	]},
+	"skills": [{"id": "summarize", "name": "summarize", "description": "", "tags": []}]
+}`
+
+func TestFetchAgentCardSkillPricingHints(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/.well-known/agent-card.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(pricedAgentCard))
+	}))
+	defer server.Close()
+
+	card, err := FetchAgentCard(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("FetchAgentCard() error = %v", err)
+	}
+	hints, err := SkillPricingHints(card)
+	if err != nil {
+		t.Fatalf("SkillPricingHints() error = %v", err)
+	}
+	want := map[string][]SkillPricingHint{
+		"summarize": {
+			{Network: "eip155:8453", Asset: "0xusdc", Price: "0.10", Scheme: "exact"},
+			{Network: "eip155:84532", Asset: "0xtest", Price: "USD 0.10", Scheme: "upto"},
+		},
+	}
+	if !reflect.DeepEqual(hints, want) {
+		t.Errorf("hints = %+v, want %+v", hints, want)
+	}
+}
+
+func TestSkillPricingHintsWithoutHints(t *testing.T) {
+	hints, err := SkillPricingHints(nil)
+	if err != nil || len(hints) != 0 {
+		t.Errorf("SkillPricingHints(nil) = %v, %v; want no hints", hints, err)
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultMaxBackoff = 30 * time.Second
+
+// RateLimitError reports that the merchant asked the client to slow down.
+// RetryAfter is zero when the merchant gave no hint.
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("merchant rate limited the request; retry after %s", e.RetryAfter)
+	}
+	return "merchant rate limited the request"
+}
+
+// rateLimitTransport turns HTTP 429/503 responses and JSON-RPC errors carrying
+// a retryAfter hint into a RateLimitError, which the a2a client otherwise
+// reduces to an opaque status error. Retry-After dates are measured from
+// clock.
+type rateLimitTransport struct {
+	base  http.RoundTripper
+	clock Clock
+}
+
+func withRateLimitDetection(httpClient *http.Client, clock Clock) *http.Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 3 * time.Minute}
+	}
+	base := httpClient.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if _, ok := base.(*rateLimitTransport); ok {
+		return httpClient
+	}
+	wrapped := *httpClient
+	if clock == nil {
+		clock = RealClock()
+	}
+	wrapped.Transport = &rateLimitTransport{base: base, clock: clock}
+	return &wrapped
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode == http.StatusServiceUnavailable && resp.Header.Get("Retry-After") != "") {
+		resp.Body.Close()
+		return nil, &RateLimitError{RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), t.clock.Now())}
+	}
+	if resp.StatusCode != http.StatusOK || strings.Contains(req.Header.Get("Accept"), "text/event-stream") {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if retryAfter, ok := jsonRPCRetryHint(body); ok {
+		return nil, &RateLimitError{RetryAfter: retryAfter}
+	}
+	return resp, nil
+}
+
+// parseRetryAfter accepts both the delay-seconds and HTTP-date forms.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
+// jsonRPCRetryHint recognizes error responses whose data carries a retryAfter
+// value in seconds.
+func jsonRPCRetryHint(body []byte) (time.Duration, bool) {
+	var resp struct {
+		Error *struct {
+			Data map[string]any `json:"data"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil || resp.Error == nil {
+		return 0, false
+	}
+	seconds, ok := resp.Error.Data["retryAfter"].(float64)
+	if !ok {
+		return 0, false
+	}
+	if seconds < 0 {
+		seconds = 0
+	}
+	return time.Duration(seconds * float64(time.Second)), true
+}
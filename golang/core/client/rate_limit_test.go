@@ -0,0 +1,192 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+)
+
+// newRateLimitedMerchant serves an agent card and a JSON-RPC endpoint whose
+// tasks/get responses are produced by pollResponse for each poll.
+func newRateLimitedMerchant(t *testing.T, pollResponse func(poll int, w http.ResponseWriter) bool) *httptest.Server {
+	t.Helper()
+	var mu sync.Mutex
+	polls := 0
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("/.well-known/agent-card.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(a2a.AgentCard{
+			URL:                server.URL + "/rpc",
+			PreferredTransport: a2a.TransportProtocolJSONRPC,
+			Capabilities: a2a.AgentCapabilities{Extensions: []a2a.AgentExtension{
+				{URI: x402pkg.X402ExtensionURI},
+			}},
+		})
+	})
+	mux.HandleFunc("/rpc", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     any    `json:"id"`
+			Method string `json:"method"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		taskState := a2a.TaskStateWorking
+		if req.Method == "tasks/get" {
+			mu.Lock()
+			polls++
+			poll := polls
+			mu.Unlock()
+			if pollResponse(poll, w) {
+				return
+			}
+			taskState = a2a.TaskStateCompleted
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"result":  newClientTestTask("limited", taskState, ""),
+		})
+	})
+	return server
+}
+
+func newRateLimitTestClient(t *testing.T, server *httptest.Server, clock Clock, opts ...Option) *Client {
+	t.Helper()
+	c, err := newConfiguredClient(append([]Option{WithClock(clock), WithPollInterval(time.Second)}, opts...))
+	if err != nil {
+		t.Fatalf("newConfiguredClient() error = %v", err)
+	}
+	if err := c.connect(context.Background(), server.URL); err != nil {
+		t.Fatalf("connect() error = %v", err)
+	}
+	return c
+}
+
+func TestWaitForCompletionBacksOffOnRateLimit(t *testing.T) {
+	server := newRateLimitedMerchant(t, func(poll int, w http.ResponseWriter) bool {
+		if poll > 2 {
+			return false
+		}
+		w.Header().Set("Retry-After", "2")
+		w.WriteHeader(http.StatusTooManyRequests)
+		return true
+	})
+	clock := newFakeClock()
+	c := newRateLimitTestClient(t, server, clock)
+
+	task, err := c.WaitForCompletion(context.Background(), "request")
+	if err != nil {
+		t.Fatalf("WaitForCompletion() error = %v", err)
+	}
+	if task.Status.State != a2a.TaskStateCompleted {
+		t.Fatalf("task state = %s", task.Status.State)
+	}
+	want := []time.Duration{time.Second, 2 * time.Second, 2 * time.Second}
+	if len(clock.waits) != len(want) {
+		t.Fatalf("waits = %v, want %v", clock.waits, want)
+	}
+	for i := range want {
+		if clock.waits[i] != want[i] {
+			t.Fatalf("waits = %v, want %v", clock.waits, want)
+		}
+	}
+}
+
+func TestWaitForCompletionMeasuresRetryAfterDateFromClock(t *testing.T) {
+	server := newRateLimitedMerchant(t, func(poll int, w http.ResponseWriter) bool {
+		if poll > 1 {
+			return false
+		}
+		w.Header().Set("Retry-After", "Wed, 01 Jan 2025 00:00:04 GMT")
+		w.WriteHeader(http.StatusTooManyRequests)
+		return true
+	})
+	clock := newFakeClock()
+	c := newRateLimitTestClient(t, server, clock)
+
+	if _, err := c.WaitForCompletion(context.Background(), "request"); err != nil {
+		t.Fatalf("WaitForCompletion() error = %v", err)
+	}
+	if len(clock.waits) != 2 || clock.waits[1] != 3*time.Second {
+		t.Fatalf("waits = %v, want the 3s left until the Retry-After date", clock.waits)
+	}
+}
+
+func TestWaitForCompletionCapsJSONRPCRetryHint(t *testing.T) {
+	server := newRateLimitedMerchant(t, func(poll int, w http.ResponseWriter) bool {
+		if poll > 1 {
+			return false
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"slow down","data":{"retryAfter":120}}}`))
+		return true
+	})
+	clock := newFakeClock()
+	c := newRateLimitTestClient(t, server, clock, WithMaxBackoff(5*time.Second))
+
+	if _, err := c.WaitForCompletion(context.Background(), "request"); err != nil {
+		t.Fatalf("WaitForCompletion() error = %v", err)
+	}
+	if len(clock.waits) != 2 || clock.waits[1] != 5*time.Second {
+		t.Fatalf("waits = %v, want capped backoff of 5s", clock.waits)
+	}
+}
+
+func TestWaitForCompletionFailsFastOnServerError(t *testing.T) {
+	server := newRateLimitedMerchant(t, func(poll int, w http.ResponseWriter) bool {
+		w.WriteHeader(http.StatusInternalServerError)
+		return true
+	})
+	clock := newFakeClock()
+	c := newRateLimitTestClient(t, server, clock)
+
+	_, err := c.WaitForCompletion(context.Background(), "request")
+	if err == nil || !strings.Contains(err.Error(), "500") {
+		t.Fatalf("error = %v, want HTTP 500 failure", err)
+	}
+	if len(clock.waits) != 1 {
+		t.Fatalf("waits = %v, want no retries", clock.waits)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := map[string]time.Duration{
+		"":                              0,
+		"3":                             3 * time.Second,
+		"-1":                            0,
+		"Wed, 01 Jan 2025 00:00:10 GMT": 10 * time.Second,
+		"soon":                          0,
+	}
+	for value, want := range tests {
+		if got := parseRetryAfter(value, now); got != want {
+			t.Errorf("parseRetryAfter(%q) = %s, want %s", value, got, want)
+		}
+	}
+}
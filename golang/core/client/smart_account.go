@@ -0,0 +1,82 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+
+	x402evm "github.com/x402-foundation/x402/go/mechanisms/evm"
+)
+
+// SmartAccountSigner signs x402 authorizations for an ERC-1271 contract wallet
+// such as a Safe. The wallet address is used as the payer while an owner key
+// signs the SafeMessage wrapping of the EIP-712 digest, so the facilitator
+// validates it through the wallet's isValidSignature.
+type SmartAccountSigner struct {
+	account string
+	owner   x402evm.ClientEvmSigner
+}
+
+func NewSmartAccountSigner(account string, owner x402evm.ClientEvmSigner) (*SmartAccountSigner, error) {
+	if !x402evm.IsValidAddress(account) {
+		return nil, fmt.Errorf("invalid smart account address: %s", account)
+	}
+	if owner == nil {
+		return nil, fmt.Errorf("smart account owner signer is required")
+	}
+	return &SmartAccountSigner{
+		account: x402evm.NormalizeAddress(account),
+		owner:   owner,
+	}, nil
+}
+
+func (s *SmartAccountSigner) Address() string {
+	return s.account
+}
+
+// Owner returns the address of the key that signs on behalf of the account.
+func (s *SmartAccountSigner) Owner() string {
+	return s.owner.Address()
+}
+
+func (s *SmartAccountSigner) SignTypedData(
+	ctx context.Context,
+	domain x402evm.TypedDataDomain,
+	types map[string][]x402evm.TypedDataField,
+	primaryType string,
+	message map[string]interface{},
+) ([]byte, error) {
+	digest, err := x402evm.HashTypedData(domain, types, primaryType, message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash typed data: %w", err)
+	}
+
+	safeDomain := x402evm.TypedDataDomain{
+		ChainID:           domain.ChainID,
+		VerifyingContract: s.account,
+	}
+	safeTypes := map[string][]x402evm.TypedDataField{
+		"SafeMessage": {{Name: "message", Type: "bytes"}},
+	}
+	return s.owner.SignTypedData(ctx, safeDomain, safeTypes, "SafeMessage", map[string]interface{}{
+		"message": digest,
+	})
+}
+
+type smartAccount struct {
+	network string
+	signer  *SmartAccountSigner
+}
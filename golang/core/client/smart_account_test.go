@@ -0,0 +1,147 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402evm "github.com/x402-foundation/x402/go/mechanisms/evm"
+	evmsigners "github.com/x402-foundation/x402/go/signers/evm"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+const (
+	testOwnerKey       = "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+	testSmartAccount   = "0x1111111111111111111111111111111111111111"
+	testBaseSepoliaUSD = "0x036CbD53842c5426634e7929541eC2318f3dCF7e"
+)
+
+type recordingEvmSigner struct {
+	address     string
+	domain      x402evm.TypedDataDomain
+	primaryType string
+	message     map[string]interface{}
+}
+
+func (s *recordingEvmSigner) Address() string {
+	return s.address
+}
+
+func (s *recordingEvmSigner) SignTypedData(
+	ctx context.Context,
+	domain x402evm.TypedDataDomain,
+	types map[string][]x402evm.TypedDataField,
+	primaryType string,
+	message map[string]interface{},
+) ([]byte, error) {
+	s.domain = domain
+	s.primaryType = primaryType
+	s.message = message
+	return make([]byte, 65), nil
+}
+
+func newBaseSepoliaPaymentRequired() *x402types.PaymentRequired {
+	return &x402types.PaymentRequired{
+		X402Version: x402pkg.X402Version,
+		Resource:    &x402types.ResourceInfo{URL: "/resource"},
+		Accepts: []x402types.PaymentRequirements{{
+			Scheme:            "exact",
+			Network:           x402pkg.NetworkBaseSepolia,
+			Asset:             testBaseSepoliaUSD,
+			Amount:            "100",
+			PayTo:             "0x2222222222222222222222222222222222222222",
+			MaxTimeoutSeconds: 60,
+			Extra:             map[string]interface{}{"name": "USDC", "version": "2"},
+		}},
+	}
+}
+
+func TestSmartAccountPayloadUsesContractAddress(t *testing.T) {
+	owner, err := evmsigners.NewClientSignerFromPrivateKey(testOwnerKey)
+	if err != nil {
+		t.Fatalf("NewClientSignerFromPrivateKey() error = %v", err)
+	}
+	signer, err := NewSmartAccountSigner(testSmartAccount, owner)
+	if err != nil {
+		t.Fatalf("NewSmartAccountSigner() error = %v", err)
+	}
+	x402Client, err := newX402Client(nil, []smartAccount{{network: x402pkg.NetworkBaseSepolia, signer: signer}})
+	if err != nil {
+		t.Fatalf("newX402Client() error = %v", err)
+	}
+
+	message, err := x402Client.ProcessPaymentRequired(context.Background(), "task-safe", newBaseSepoliaPaymentRequired())
+	if err != nil {
+		t.Fatalf("ProcessPaymentRequired() error = %v", err)
+	}
+	payload, err := state.ExtractPaymentPayload(nil, message)
+	if err != nil || payload == nil {
+		t.Fatalf("payload = %#v, error = %v", payload, err)
+	}
+	authorization, ok := payload.Payload["authorization"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("authorization = %#v", payload.Payload["authorization"])
+	}
+	from, _ := authorization["from"].(string)
+	if !strings.EqualFold(from, testSmartAccount) {
+		t.Errorf("from = %q, want smart account %s", from, testSmartAccount)
+	}
+	if strings.EqualFold(from, owner.Address()) {
+		t.Errorf("owner key %s must not become the payer", owner.Address())
+	}
+}
+
+func TestSmartAccountSignerWrapsDigestInSafeMessage(t *testing.T) {
+	owner := &recordingEvmSigner{address: "0x3333333333333333333333333333333333333333"}
+	signer, err := NewSmartAccountSigner(testSmartAccount, owner)
+	if err != nil {
+		t.Fatalf("NewSmartAccountSigner() error = %v", err)
+	}
+
+	domain := x402evm.TypedDataDomain{Name: "USDC", Version: "2", VerifyingContract: testBaseSepoliaUSD}
+	domain.ChainID, _ = x402evm.GetEvmChainId(x402pkg.NetworkBaseSepolia)
+	types := map[string][]x402evm.TypedDataField{"Ping": {{Name: "value", Type: "string"}}}
+	message := map[string]interface{}{"value": "hello"}
+	if _, err := signer.SignTypedData(context.Background(), domain, types, "Ping", message); err != nil {
+		t.Fatalf("SignTypedData() error = %v", err)
+	}
+
+	digest, err := x402evm.HashTypedData(domain, types, "Ping", message)
+	if err != nil {
+		t.Fatalf("HashTypedData() error = %v", err)
+	}
+	if owner.primaryType != "SafeMessage" || owner.domain.VerifyingContract != signer.Address() {
+		t.Errorf("owner signed %s for %s", owner.primaryType, owner.domain.VerifyingContract)
+	}
+	if owner.domain.ChainID.Cmp(domain.ChainID) != 0 {
+		t.Errorf("chain id = %v, want %v", owner.domain.ChainID, domain.ChainID)
+	}
+	if wrapped, _ := owner.message["message"].([]byte); !bytes.Equal(wrapped, digest) {
+		t.Errorf("wrapped message = %x, want %x", wrapped, digest)
+	}
+}
+
+func TestWithSmartAccountRejectsInvalidAddress(t *testing.T) {
+	c := &Client{}
+	WithSmartAccount(x402pkg.NetworkBaseSepolia, "not-an-address", &recordingEvmSigner{})(c)
+	if c.optionErr == nil || len(c.smartAccounts) != 0 {
+		t.Fatalf("optionErr = %v, smart accounts = %d", c.optionErr, len(c.smartAccounts))
+	}
+}
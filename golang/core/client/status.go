@@ -0,0 +1,105 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// TaskPaymentStatus summarizes where a task stands in the x402 payment flow.
+type TaskPaymentStatus struct {
+	TaskID        a2a.TaskID
+	State         a2a.TaskState
+	PaymentStatus state.PaymentStatus
+	// Quote lists the payment options offered while payment is required.
+	Quote []x402types.PaymentRequirements
+	// Amount is the first quoted option formatted with FormatAmount.
+	Amount string
+	// OutputSchema is the shape of the result the merchant declared for the
+	// quote, if any. See OutputSchema.
+	OutputSchema map[string]interface{}
+	// ErrorCode is the x402 error code of a failed payment.
+	ErrorCode string
+}
+
+// PaymentStatusOf returns the x402 payment status recorded on task, or an empty
+// status when the task carries no payment metadata.
+func PaymentStatusOf(task *a2a.Task) (state.PaymentStatus, error) {
+	return state.ExtractPaymentStatusFromTask(task)
+}
+
+// DescribeTask builds a TaskPaymentStatus from a task already in hand.
+func DescribeTask(task *a2a.Task) (TaskPaymentStatus, error) {
+	paymentStatus, err := PaymentStatusOf(task)
+	if err != nil {
+		return TaskPaymentStatus{}, err
+	}
+	status := TaskPaymentStatus{
+		TaskID:        task.ID,
+		State:         task.Status.State,
+		PaymentStatus: paymentStatus,
+	}
+
+	switch paymentStatus {
+	case state.PaymentRequired:
+		requirements, err := state.ExtractPaymentRequirements(task)
+		if err != nil {
+			return TaskPaymentStatus{}, fmt.Errorf("failed to extract payment requirements: %w", err)
+		}
+		if requirements != nil && len(requirements.Accepts) > 0 {
+			status.Quote = requirements.Accepts
+			status.Amount, err = FormatAmount(requirements.Accepts[0])
+			if err != nil {
+				return TaskPaymentStatus{}, err
+			}
+			status.OutputSchema = OutputSchema(requirements)
+		}
+	case state.PaymentFailed:
+		if meta := task.Status.Message.Meta(); meta != nil {
+			status.ErrorCode, _ = meta[x402pkg.MetadataKeyError].(string)
+		}
+	}
+	return status, nil
+}
+
+// OutputSchema returns the result schema the merchant declared in a quote,
+// taken from the first option that carries one, or nil when there is none.
+// Agents can validate the delivered result against it.
+func OutputSchema(requirements *x402types.PaymentRequired) map[string]interface{} {
+	if requirements == nil {
+		return nil
+	}
+	for _, option := range requirements.Accepts {
+		if schema, ok := option.Extra[x402pkg.ExtraKeyOutputSchema].(map[string]interface{}); ok {
+			return schema
+		}
+	}
+	return nil
+}
+
+// Status fetches a task from the merchant and summarizes its payment status.
+func (c *Client) Status(ctx context.Context, taskID a2a.TaskID) (TaskPaymentStatus, error) {
+	task, err := c.client.GetTask(ctx, &a2a.TaskQueryParams{ID: taskID})
+	if err != nil {
+		return TaskPaymentStatus{}, fmt.Errorf("failed to get task: %w", err)
+	}
+	return DescribeTask(task)
+}
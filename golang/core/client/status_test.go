@@ -0,0 +1,75 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+)
+
+func TestStatusMapsEveryPaymentStatus(t *testing.T) {
+	failed := newClientTestTask("status", a2a.TaskStateFailed, state.PaymentFailed)
+	state.SetPaymentError(failed.Status.Message, x402pkg.ErrorCodeInsufficientFunds)
+	required := newClientTestTask("status", a2a.TaskStateInputRequired, state.PaymentRequired)
+	if err := state.SetPaymentRequirements(required.Status.Message, newBaseSepoliaPaymentRequired()); err != nil {
+		t.Fatalf("SetPaymentRequirements() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		task      *a2a.Task
+		want      state.PaymentStatus
+		wantQuote bool
+		wantCode  string
+	}{
+		{name: "no payment", task: newClientTestTask("status", a2a.TaskStateWorking, ""), want: ""},
+		{name: "required", task: required, want: state.PaymentRequired, wantQuote: true},
+		{name: "submitted", task: newClientTestTask("status", a2a.TaskStateWorking, state.PaymentSubmitted), want: state.PaymentSubmitted},
+		{name: "verified", task: newClientTestTask("status", a2a.TaskStateWorking, state.PaymentVerified), want: state.PaymentVerified},
+		{name: "rejected", task: newClientTestTask("status", a2a.TaskStateCanceled, state.PaymentRejected), want: state.PaymentRejected},
+		{name: "completed", task: newClientTestTask("status", a2a.TaskStateCompleted, state.PaymentCompleted), want: state.PaymentCompleted},
+		{name: "failed", task: failed, want: state.PaymentFailed, wantCode: x402pkg.ErrorCodeInsufficientFunds},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a2aClient := &mockTaskClient{getTaskFunc: func(context.Context, *a2a.TaskQueryParams) (*a2a.Task, error) {
+				return tt.task, nil
+			}}
+			c := &Client{client: a2aClient}
+
+			got, err := c.Status(context.Background(), tt.task.ID)
+			if err != nil {
+				t.Fatalf("Status() error = %v", err)
+			}
+			if got.TaskID != tt.task.ID || got.State != tt.task.Status.State || got.PaymentStatus != tt.want {
+				t.Fatalf("Status() = %#v", got)
+			}
+			if tt.wantQuote && (len(got.Quote) != 1 || got.Amount != "0.0001 USDC on Base Sepolia") {
+				t.Fatalf("quote = %#v, amount = %q", got.Quote, got.Amount)
+			}
+			if !tt.wantQuote && (got.Quote != nil || got.Amount != "") {
+				t.Fatalf("unexpected quote %#v", got.Quote)
+			}
+			if got.ErrorCode != tt.wantCode {
+				t.Fatalf("error code = %q, want %q", got.ErrorCode, tt.wantCode)
+			}
+		})
+	}
+}
@@ -16,6 +16,7 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -27,6 +28,50 @@ const defaultTaskPollInterval = 500 * time.Millisecond
 
 // WaitForCompletion starts a task by sending a message and waits for it to reach a terminal state.
 func (c *Client) WaitForCompletion(ctx context.Context, messageText string) (*a2a.Task, error) {
+	task, err := c.startTask(ctx, messageText)
+	if err != nil {
+		return nil, err
+	}
+	return c.awaitTask(ctx, task)
+}
+
+// WaitForCompletionWithKey behaves like WaitForCompletion but records the task
+// under idempotencyKey in the configured DedupStore. If the key already maps to
+// a task, that task is resumed instead of sending messageText again, and a task
+// that already finished is returned as is.
+func (c *Client) WaitForCompletionWithKey(ctx context.Context, idempotencyKey string, messageText string) (*a2a.Task, error) {
+	if c.dedupStore == nil || idempotencyKey == "" {
+		return c.WaitForCompletion(ctx, messageText)
+	}
+
+	taskID, found, err := c.dedupStore.Get(ctx, idempotencyKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up idempotency key: %w", err)
+	}
+	if found {
+		task, err := c.client.GetTask(ctx, &a2a.TaskQueryParams{ID: taskID})
+		switch {
+		case err == nil:
+			if task.Status.State.Terminal() {
+				return task, nil
+			}
+			return c.awaitTask(ctx, task)
+		case !errors.Is(err, a2a.ErrTaskNotFound):
+			return nil, fmt.Errorf("failed to get task %s: %w", taskID, err)
+		}
+	}
+
+	task, err := c.startTask(ctx, messageText)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.dedupStore.Set(ctx, idempotencyKey, task.ID); err != nil {
+		return nil, fmt.Errorf("failed to record idempotency key: %w", err)
+	}
+	return c.awaitTask(ctx, task)
+}
+
+func (c *Client) startTask(ctx context.Context, messageText string) (*a2a.Task, error) {
 	message := a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: messageText})
 	task, directMessage, err := SendMessage(ctx, c.client, message)
 	if err != nil {
@@ -38,9 +83,16 @@ func (c *Client) WaitForCompletion(ctx context.Context, messageText string) (*a2
 		}
 		return nil, fmt.Errorf("merchant returned no task")
 	}
+	return task, nil
+}
 
+func (c *Client) awaitTask(ctx context.Context, task *a2a.Task) (*a2a.Task, error) {
 	paymentSubmitted := false
+	outcomeReported := false
 	for {
+		if !outcomeReported {
+			outcomeReported = c.hooks.outcome(ctx, task)
+		}
 		paymentStatus, err := state.ExtractPaymentStatusFromTask(task)
 		if err != nil {
 			return nil, fmt.Errorf("failed to extract payment status: %w", err)
@@ -57,6 +109,9 @@ func (c *Client) WaitForCompletion(ctx context.Context, messageText string) (*a2
 		if submitted {
 			paymentSubmitted = true
 		}
+		if !outcomeReported {
+			outcomeReported = c.hooks.outcome(ctx, task)
+		}
 
 		if task.Status.State.Terminal() {
 			return task, nil
@@ -69,12 +124,41 @@ func (c *Client) WaitForCompletion(ctx context.Context, messageText string) (*a2
 		select {
 		case <-ctx.Done():
 			return nil, ctx.Err()
-		case <-time.After(pollInterval):
+		case <-c.clockOrDefault().After(pollInterval):
 		}
 
-		task, err = c.client.GetTask(ctx, &a2a.TaskQueryParams{ID: task.ID})
+		task, err = c.getTaskWithBackoff(ctx, task.ID, pollInterval)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get task: %w", err)
 		}
 	}
 }
+
+// getTaskWithBackoff retries GetTask while the merchant reports rate limiting,
+// waiting for the hinted duration capped at the configured maximum backoff.
+func (c *Client) getTaskWithBackoff(ctx context.Context, taskID a2a.TaskID, pollInterval time.Duration) (*a2a.Task, error) {
+	maxBackoff := c.maxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxBackoff
+	}
+	for {
+		task, err := c.client.GetTask(ctx, &a2a.TaskQueryParams{ID: taskID})
+		var rateLimited *RateLimitError
+		if !errors.As(err, &rateLimited) {
+			return task, err
+		}
+
+		wait := rateLimited.RetryAfter
+		if wait <= 0 {
+			wait = pollInterval
+		}
+		if wait > maxBackoff {
+			wait = maxBackoff
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-c.clockOrDefault().After(wait):
+		}
+	}
+}
@@ -16,6 +16,7 @@ package client
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"testing"
 	"time"
@@ -109,3 +110,151 @@ func TestWaitForCompletionHonorsCancellation(t *testing.T) {
 		t.Fatalf("error = %v", err)
 	}
 }
+
+func TestWaitForCompletionAnswersInputRequired(t *testing.T) {
+	question := newClientTestTask("input-flow", a2a.TaskStateInputRequired, "")
+	question.Status.Message = a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{Text: "Which size?"})
+	completed := newClientTestTask("input-flow", a2a.TaskStateCompleted, "")
+
+	var reply *a2a.Message
+	a2aClient := &mockTaskClient{}
+	a2aClient.sendMessageFunc = func(_ context.Context, params *a2a.MessageSendParams) (a2a.SendMessageResult, error) {
+		if a2aClient.sendCalls == 1 {
+			return question, nil
+		}
+		reply = params.Message
+		return completed, nil
+	}
+
+	var asked *a2a.Task
+	client := &Client{
+		client:       a2aClient,
+		pollInterval: time.Nanosecond,
+		inputHandler: func(_ context.Context, task *a2a.Task) (*a2a.Message, error) {
+			asked = task
+			return a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "large"}), nil
+		},
+	}
+
+	got, err := client.WaitForCompletion(context.Background(), "request")
+	if err != nil || got != completed {
+		t.Fatalf("task = %#v, error = %v", got, err)
+	}
+	if asked != question {
+		t.Fatalf("input handler received %#v", asked)
+	}
+	if reply == nil || reply.TaskID != question.ID || reply.ContextID != question.ContextID {
+		t.Fatalf("input reply was not sent on the paused task: %#v", reply)
+	}
+	if a2aClient.sendCalls != 2 || a2aClient.getCalls != 0 {
+		t.Fatalf("send calls = %d, get calls = %d", a2aClient.sendCalls, a2aClient.getCalls)
+	}
+}
+
+func TestWaitForCompletionFailsFastOnInputRequired(t *testing.T) {
+	question := newClientTestTask("input-missing", a2a.TaskStateInputRequired, "")
+	question.Status.Message = a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{Text: "Which size?"})
+	a2aClient := &mockTaskClient{sendMessageFunc: func(context.Context, *a2a.MessageSendParams) (a2a.SendMessageResult, error) {
+		return question, nil
+	}}
+	client := &Client{client: a2aClient, pollInterval: time.Hour}
+
+	_, err := client.WaitForCompletion(context.Background(), "request")
+	if !errors.Is(err, ErrInputRequired) || !strings.Contains(err.Error(), "Which size?") {
+		t.Fatalf("error = %v", err)
+	}
+	if a2aClient.getCalls != 0 {
+		t.Fatalf("get calls = %d, want no polling", a2aClient.getCalls)
+	}
+}
+
+func TestWaitForCompletionPollsWithClock(t *testing.T) {
+	working := newClientTestTask("multi-poll", a2a.TaskStateWorking, "")
+	completed := newClientTestTask("multi-poll", a2a.TaskStateCompleted, "")
+	a2aClient := &mockTaskClient{}
+	a2aClient.sendMessageFunc = func(context.Context, *a2a.MessageSendParams) (a2a.SendMessageResult, error) {
+		return working, nil
+	}
+	a2aClient.getTaskFunc = func(context.Context, *a2a.TaskQueryParams) (*a2a.Task, error) {
+		if a2aClient.getCalls < 4 {
+			return working, nil
+		}
+		return completed, nil
+	}
+	clock := newFakeClock()
+	client := &Client{client: a2aClient, pollInterval: time.Minute, clock: clock}
+
+	got, err := client.WaitForCompletion(context.Background(), "request")
+	if err != nil || got != completed {
+		t.Fatalf("task = %#v, error = %v", got, err)
+	}
+	if a2aClient.getCalls != 4 || len(clock.waits) != 4 {
+		t.Fatalf("get calls = %d, waits = %v", a2aClient.getCalls, clock.waits)
+	}
+	for _, wait := range clock.waits {
+		if wait != time.Minute {
+			t.Fatalf("waits = %v, want poll interval between every poll", clock.waits)
+		}
+	}
+}
+
+func TestWaitForCompletionTimesOutOnClockDeadline(t *testing.T) {
+	working := newClientTestTask("timeout", a2a.TaskStateWorking, "")
+	a2aClient := &mockTaskClient{
+		sendMessageFunc: func(context.Context, *a2a.MessageSendParams) (a2a.SendMessageResult, error) {
+			return working, nil
+		},
+		getTaskFunc: func(context.Context, *a2a.TaskQueryParams) (*a2a.Task, error) {
+			return working, nil
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	clock := newFakeClock()
+	clock.deadline = clock.now.Add(10 * time.Second)
+	clock.expire = cancel
+	client := &Client{client: a2aClient, pollInterval: 3 * time.Second, clock: clock}
+
+	_, err := client.WaitForCompletion(ctx, "request")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("error = %v", err)
+	}
+	if a2aClient.getCalls != 3 {
+		t.Fatalf("get calls = %d, want 3 polls before the deadline", a2aClient.getCalls)
+	}
+}
+
+func TestWaitForCompletionPaymentRound(t *testing.T) {
+	required := newPaymentRequiredTask("payment-round")
+	verified := newClientTestTask("payment-round", a2a.TaskStateWorking, state.PaymentVerified)
+	completed := newClientTestTask("payment-round", a2a.TaskStateCompleted, state.PaymentCompleted)
+	processor := &mockPaymentProcessor{processFunc: func(_ context.Context, taskID a2a.TaskID, _ *x402types.PaymentRequired) (*a2a.Message, error) {
+		return a2a.NewMessageForTask(a2a.MessageRoleUser, a2a.TaskInfo{TaskID: taskID}, a2a.TextPart{Text: "payment"}), nil
+	}}
+	a2aClient := &mockTaskClient{}
+	a2aClient.sendMessageFunc = func(context.Context, *a2a.MessageSendParams) (a2a.SendMessageResult, error) {
+		if a2aClient.sendCalls == 1 {
+			return required, nil
+		}
+		return verified, nil
+	}
+	a2aClient.getTaskFunc = func(context.Context, *a2a.TaskQueryParams) (*a2a.Task, error) {
+		if a2aClient.getCalls < 2 {
+			return verified, nil
+		}
+		return completed, nil
+	}
+	clock := newFakeClock()
+	client := &Client{x402Client: processor, client: a2aClient, pollInterval: time.Second, clock: clock}
+
+	got, err := client.WaitForCompletion(context.Background(), "request")
+	if err != nil || got != completed {
+		t.Fatalf("task = %#v, error = %v", got, err)
+	}
+	if processor.calls != 1 || a2aClient.sendCalls != 2 || a2aClient.getCalls != 2 {
+		t.Fatalf("processor calls = %d, send calls = %d, get calls = %d", processor.calls, a2aClient.sendCalls, a2aClient.getCalls)
+	}
+	if got := clock.Now().Sub(newFakeClock().Now()); got != 2*time.Second {
+		t.Fatalf("elapsed fake time = %v, want 2s", got)
+	}
+}
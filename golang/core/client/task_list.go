@@ -0,0 +1,221 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+)
+
+const (
+	defaultSweepAge = time.Hour
+
+	methodTasksList          = "tasks/list"
+	jsonRPCMethodNotFound    = -32601
+	jsonRPCUnsupportedOption = -32004
+)
+
+// ErrNotSupported is returned when the merchant does not implement an optional
+// A2A operation.
+var ErrNotSupported = errors.New("operation not supported by merchant")
+
+type taskLister interface {
+	ListTasks(ctx context.Context, request *a2a.ListTasksRequest) (*a2a.ListTasksResponse, error)
+}
+
+// TaskFilter narrows the tasks returned by Client.ListTasks. Zero fields match
+// everything.
+type TaskFilter struct {
+	ContextID     string
+	State         a2a.TaskState
+	PaymentStatus state.PaymentStatus
+	UpdatedAfter  *time.Time
+}
+
+// PaymentTask is a merchant task annotated with its x402 payment status.
+type PaymentTask struct {
+	Task          *a2a.Task
+	PaymentStatus state.PaymentStatus
+}
+
+// AwaitingPayment reports whether the merchant is waiting for the client to pay.
+func (t PaymentTask) AwaitingPayment() bool {
+	return t.PaymentStatus == state.PaymentRequired && !t.Task.Status.State.Terminal()
+}
+
+// AwaitingMerchant reports whether the client has paid and the merchant has not
+// finished processing the payment.
+func (t PaymentTask) AwaitingMerchant() bool {
+	if t.Task.Status.State.Terminal() {
+		return false
+	}
+	return t.PaymentStatus == state.PaymentSubmitted || t.PaymentStatus == state.PaymentVerified
+}
+
+// ListTasks returns every task the merchant reports for this client, annotated
+// with its payment status. ErrNotSupported is returned when the merchant does not
+// implement task listing.
+func (c *Client) ListTasks(ctx context.Context, filter TaskFilter) ([]PaymentTask, error) {
+	if c.lister == nil {
+		return nil, ErrNotSupported
+	}
+
+	request := &a2a.ListTasksRequest{
+		ContextID:        filter.ContextID,
+		Status:           filter.State,
+		LastUpdatedAfter: filter.UpdatedAfter,
+	}
+	var result []PaymentTask
+	for {
+		response, err := c.lister.ListTasks(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		for _, task := range response.Tasks {
+			if task == nil {
+				continue
+			}
+			status, err := state.ExtractPaymentStatusFromTask(task)
+			if err != nil {
+				return nil, fmt.Errorf("failed to extract payment status for task %s: %w", task.ID, err)
+			}
+			if filter.PaymentStatus != "" && status != filter.PaymentStatus {
+				continue
+			}
+			result = append(result, PaymentTask{Task: task, PaymentStatus: status})
+		}
+		if response.NextPageToken == "" {
+			return result, nil
+		}
+		request.PageToken = response.NextPageToken
+	}
+}
+
+// Sweep rejects every task that has been waiting in payment-required for longer
+// than the configured sweep age and returns the IDs of the rejected tasks.
+func (c *Client) Sweep(ctx context.Context) ([]a2a.TaskID, error) {
+	tasks, err := c.ListTasks(ctx, TaskFilter{
+		State:         a2a.TaskStateInputRequired,
+		PaymentStatus: state.PaymentRequired,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	cutoff := c.clockOrDefault().Now().Add(-c.sweepAge)
+	var rejected []a2a.TaskID
+	for _, candidate := range tasks {
+		timestamp := candidate.Task.Status.Timestamp
+		if timestamp == nil || timestamp.After(cutoff) {
+			continue
+		}
+		message := state.EncodePaymentRejection(candidate.Task.ID, "Payment requirements expired")
+		message.ContextID = candidate.Task.ContextID
+		if _, _, err := SendMessage(ctx, c.client, message); err != nil {
+			return rejected, fmt.Errorf("failed to reject task %s: %w", candidate.Task.ID, err)
+		}
+		rejected = append(rejected, candidate.Task.ID)
+	}
+	return rejected, nil
+}
+
+// jsonRPCTaskLister calls tasks/list directly because the a2a client does not
+// expose it.
+type jsonRPCTaskLister struct {
+	url           string
+	httpClient    *http.Client
+	extensionURIs []string
+	headers       map[string]string
+}
+
+func newJSONRPCTaskLister(
+	url string,
+	httpClient *http.Client,
+	extensionURIs []string,
+	headers map[string]string,
+) *jsonRPCTaskLister {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &jsonRPCTaskLister{url: url, httpClient: httpClient, extensionURIs: extensionURIs, headers: headers}
+}
+
+type jsonRPCListResponse struct {
+	Result *a2a.ListTasksResponse `json:"result,omitempty"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+func (l *jsonRPCTaskLister) ListTasks(ctx context.Context, request *a2a.ListTasksRequest) (*a2a.ListTasksResponse, error) {
+	body, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"method":  methodTasksList,
+		"params":  request,
+		"id":      1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal list request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, l.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create list request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	for name, value := range l.headers {
+		httpReq.Header.Set(name, value)
+	}
+	for _, uri := range l.extensionURIs {
+		httpReq.Header.Add("X-A2A-Extensions", uri)
+	}
+
+	resp, err := l.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusMethodNotAllowed {
+		return nil, ErrNotSupported
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var rpcResp jsonRPCListResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+		return nil, fmt.Errorf("failed to decode list response: %w", err)
+	}
+	if rpcResp.Error != nil {
+		if rpcResp.Error.Code == jsonRPCMethodNotFound || rpcResp.Error.Code == jsonRPCUnsupportedOption {
+			return nil, ErrNotSupported
+		}
+		return nil, fmt.Errorf("failed to list tasks: jsonrpc error %d: %s", rpcResp.Error.Code, rpcResp.Error.Message)
+	}
+	if rpcResp.Result == nil {
+		return &a2a.ListTasksResponse{}, nil
+	}
+	return rpcResp.Result, nil
+}
@@ -0,0 +1,128 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+)
+
+type mockTaskLister struct {
+	pages    []*a2a.ListTasksResponse
+	requests []a2a.ListTasksRequest
+}
+
+func (m *mockTaskLister) ListTasks(ctx context.Context, request *a2a.ListTasksRequest) (*a2a.ListTasksResponse, error) {
+	m.requests = append(m.requests, *request)
+	page := m.pages[0]
+	m.pages = m.pages[1:]
+	return page, nil
+}
+
+func TestListTasksAnnotatesAndFiltersPages(t *testing.T) {
+	required := newPaymentRequiredTask("required")
+	submitted := newClientTestTask("submitted", a2a.TaskStateWorking, state.PaymentSubmitted)
+	plain := newClientTestTask("plain", a2a.TaskStateCompleted, "")
+	lister := &mockTaskLister{pages: []*a2a.ListTasksResponse{
+		{Tasks: []*a2a.Task{required, plain}, NextPageToken: "next"},
+		{Tasks: []*a2a.Task{submitted}},
+	}}
+	client := &Client{lister: lister}
+
+	tasks, err := client.ListTasks(context.Background(), TaskFilter{ContextID: "ctx"})
+	if err != nil {
+		t.Fatalf("ListTasks() error = %v", err)
+	}
+	if len(tasks) != 3 {
+		t.Fatalf("tasks = %d, want 3", len(tasks))
+	}
+	if !tasks[0].AwaitingPayment() || tasks[0].AwaitingMerchant() {
+		t.Errorf("required task annotation = %#v", tasks[0])
+	}
+	if tasks[1].AwaitingPayment() || tasks[1].AwaitingMerchant() {
+		t.Errorf("plain task annotation = %#v", tasks[1])
+	}
+	if !tasks[2].AwaitingMerchant() {
+		t.Errorf("submitted task annotation = %#v", tasks[2])
+	}
+	if len(lister.requests) != 2 || lister.requests[0].ContextID != "ctx" || lister.requests[1].PageToken != "next" {
+		t.Fatalf("requests = %#v", lister.requests)
+	}
+
+	lister.pages = []*a2a.ListTasksResponse{{Tasks: []*a2a.Task{required, submitted, plain}}}
+	tasks, err = client.ListTasks(context.Background(), TaskFilter{PaymentStatus: state.PaymentRequired})
+	if err != nil || len(tasks) != 1 || tasks[0].Task != required {
+		t.Fatalf("filtered tasks = %#v, error = %v", tasks, err)
+	}
+}
+
+func TestListTasksReportsUnsupportedMerchant(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-A2A-Extensions"); got != x402.X402ExtensionURI {
+			t.Errorf("extension header = %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"method not found"}}`))
+	}))
+	defer server.Close()
+
+	client := &Client{lister: newJSONRPCTaskLister(server.URL, server.Client(), []string{x402.X402ExtensionURI}, nil)}
+	if _, err := client.ListTasks(context.Background(), TaskFilter{}); !errors.Is(err, ErrNotSupported) {
+		t.Fatalf("error = %v, want ErrNotSupported", err)
+	}
+}
+
+func TestSweepRejectsStalePaymentRequiredTasks(t *testing.T) {
+	clock := newFakeClock()
+	stale := newPaymentRequiredTask("stale")
+	staleTime := clock.Now().Add(-2 * time.Hour)
+	stale.Status.Timestamp = &staleTime
+	fresh := newPaymentRequiredTask("fresh")
+	freshTime := clock.Now().Add(-time.Minute)
+	fresh.Status.Timestamp = &freshTime
+
+	lister := &mockTaskLister{pages: []*a2a.ListTasksResponse{{Tasks: []*a2a.Task{stale, fresh}}}}
+	var sent []*a2a.Message
+	a2aClient := &mockTaskClient{sendMessageFunc: func(_ context.Context, params *a2a.MessageSendParams) (a2a.SendMessageResult, error) {
+		sent = append(sent, params.Message)
+		return stale, nil
+	}}
+	client := &Client{client: a2aClient, lister: lister, clock: clock, sweepAge: time.Hour}
+
+	rejected, err := client.Sweep(context.Background())
+	if err != nil {
+		t.Fatalf("Sweep() error = %v", err)
+	}
+	if len(rejected) != 1 || rejected[0] != stale.ID {
+		t.Fatalf("rejected = %v, want [%s]", rejected, stale.ID)
+	}
+	if lister.requests[0].Status != a2a.TaskStateInputRequired {
+		t.Errorf("list state filter = %q", lister.requests[0].Status)
+	}
+	if len(sent) != 1 || sent[0].TaskID != stale.ID || sent[0].ContextID != stale.ContextID {
+		t.Fatalf("sent = %#v", sent)
+	}
+	if status, _ := state.ExtractPaymentStatusFromMessage(sent[0]); status != state.PaymentRejected {
+		t.Errorf("rejection status = %q", status)
+	}
+}
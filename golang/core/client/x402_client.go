@@ -32,10 +32,15 @@ import (
 
 type X402Client struct {
 	client *x402.X402Client
+	budget *Budget
 }
 
 func NewX402Client(networkKeyPairs []types.NetworkKeyPair) (*X402Client, error) {
-	if len(networkKeyPairs) == 0 {
+	return newX402Client(networkKeyPairs, nil)
+}
+
+func newX402Client(networkKeyPairs []types.NetworkKeyPair, smartAccounts []smartAccount) (*X402Client, error) {
+	if len(networkKeyPairs) == 0 && len(smartAccounts) == 0 {
 		return nil, fmt.Errorf("at least one network-key pair is required")
 	}
 
@@ -43,7 +48,7 @@ func NewX402Client(networkKeyPairs []types.NetworkKeyPair) (*X402Client, error)
 
 	for _, pair := range networkKeyPairs {
 		switch {
-		case pair.NetworkName == x402pkg.NetworkBase || pair.NetworkName == x402pkg.NetworkBaseSepolia:
+		case isEVMNetwork(pair.NetworkName):
 			evmSigner, err := evmsigners.NewClientSignerFromPrivateKey(pair.PrivateKey)
 			if err != nil {
 				return nil, fmt.Errorf("failed to create EVM signer for network %s: %w", pair.NetworkName, err)
@@ -59,11 +64,21 @@ func NewX402Client(networkKeyPairs []types.NetworkKeyPair) (*X402Client, error)
 			return nil, fmt.Errorf("unsupported network: %s", pair.NetworkName)
 		}
 	}
+	for _, account := range smartAccounts {
+		if !isEVMNetwork(account.network) {
+			return nil, fmt.Errorf("smart accounts are not supported on network: %s", account.network)
+		}
+		client.Register(x402.Network(account.network), evm.NewExactEvmScheme(account.signer, nil))
+	}
 	return &X402Client{
 		client: client,
 	}, nil
 }
 
+func isEVMNetwork(network string) bool {
+	return network == x402pkg.NetworkBase || network == x402pkg.NetworkBaseSepolia
+}
+
 func (c *X402Client) ProcessPaymentRequired(
 	ctx context.Context,
 	taskID a2a.TaskID,
@@ -87,6 +102,15 @@ func (c *X402Client) ProcessPaymentRequired(
 		return nil, fmt.Errorf("no matching payment option found: %w", err)
 	}
 
+	if c.budget != nil {
+		if err := c.budget.Spend(paymentRequirements.Amount); err != nil {
+			if formatted, formatErr := FormatAmount(paymentRequirements); formatErr == nil {
+				return nil, fmt.Errorf("payment of %s not authorized: %w", formatted, err)
+			}
+			return nil, err
+		}
+	}
+
 	payload, err := c.client.CreatePaymentPayload(
 		ctx,
 		paymentRequirements,
@@ -94,6 +118,9 @@ func (c *X402Client) ProcessPaymentRequired(
 		nil,
 	)
 	if err != nil {
+		if c.budget != nil {
+			c.budget.release(paymentRequirements.Amount)
+		}
 		return nil, fmt.Errorf("failed to create payment payload: %w", err)
 	}
 
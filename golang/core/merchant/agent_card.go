@@ -0,0 +1,272 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+)
+
+// AgentCardConfig describes a merchant agent for NewAgentCard.
+type AgentCardConfig struct {
+	Name        string
+	Description string
+	// URL is the JSON-RPC endpoint clients send messages to.
+	URL string
+	// Version is the agent's own version; it defaults to "1.0.0".
+	Version string
+	// Skills must not be empty. A skill without an ID uses its name.
+	Skills []a2a.AgentSkill
+	// NetworkConfigs are the orchestrator's networks, advertised in the x402
+	// extension params so clients can tell up front whether they can pay.
+	NetworkConfigs []types.NetworkConfig
+	// SkillPricing optionally maps skill IDs to a price hint such as "$0.10".
+	// Hints are informational; the quote in the payment-required response is
+	// what the client actually pays.
+	SkillPricing map[string]string
+	// SkillRequirements optionally maps skill IDs to the requirements the
+	// business service quotes for them. Each becomes a list of pricing hints,
+	// one per network and asset in NetworkConfigs, advertised under
+	// x402.ExtensionParamSkillPricing.
+	SkillRequirements map[string]business.ServiceRequirements
+	// InputModes and OutputModes default to text.
+	InputModes  []string
+	OutputModes []string
+}
+
+// NewAgentCard builds an agent card that declares the x402 extension as
+// required, so clients without x402 support fail fast at discovery instead of
+// mid-task.
+func NewAgentCard(cfg AgentCardConfig) (*a2a.AgentCard, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("agent card URL is required")
+	}
+	if len(cfg.Skills) == 0 {
+		return nil, errors.New("agent card needs at least one skill")
+	}
+
+	skills := make([]a2a.AgentSkill, len(cfg.Skills))
+	for i, skill := range cfg.Skills {
+		if skill.ID == "" {
+			skill.ID = skill.Name
+		}
+		if skill.ID == "" {
+			return nil, fmt.Errorf("skill %d has neither an ID nor a name", i)
+		}
+		skills[i] = skill
+	}
+	for skillID := range cfg.SkillPricing {
+		if !hasSkill(skills, skillID) {
+			return nil, fmt.Errorf("pricing hint for unknown skill %q", skillID)
+		}
+	}
+	for skillID := range cfg.SkillRequirements {
+		if !hasSkill(skills, skillID) {
+			return nil, fmt.Errorf("requirements for unknown skill %q", skillID)
+		}
+	}
+	skillPricing, err := skillPricingHints(cfg.NetworkConfigs, cfg.SkillRequirements)
+	if err != nil {
+		return nil, err
+	}
+
+	version := cfg.Version
+	if version == "" {
+		version = "1.0.0"
+	}
+	inputModes := cfg.InputModes
+	if len(inputModes) == 0 {
+		inputModes = []string{"text"}
+	}
+	outputModes := cfg.OutputModes
+	if len(outputModes) == 0 {
+		outputModes = []string{"text"}
+	}
+
+	return &a2a.AgentCard{
+		Name:               cfg.Name,
+		Description:        cfg.Description,
+		URL:                cfg.URL,
+		PreferredTransport: a2a.TransportProtocolJSONRPC,
+		DefaultInputModes:  inputModes,
+		DefaultOutputModes: outputModes,
+		Capabilities: a2a.AgentCapabilities{
+			Extensions: []a2a.AgentExtension{
+				{
+					URI:      x402.X402ExtensionURI,
+					Required: true,
+					Params:   extensionParams(cfg.NetworkConfigs, cfg.SkillPricing, skillPricing),
+				},
+			},
+		},
+		ProtocolVersion: x402.A2AProtocolVersion,
+		Version:         version,
+		Skills:          skills,
+	}, nil
+}
+
+func extensionParams(networkConfigs []types.NetworkConfig, pricing map[string]string, skillPricing map[string]any) map[string]any {
+	params := make(map[string]any)
+	if len(networkConfigs) > 0 {
+		networks := make([]any, 0, len(networkConfigs))
+		for _, networkConfig := range networkConfigs {
+			network := map[string]any{"network": networkConfig.NetworkName}
+			if len(networkConfig.Assets) > 0 {
+				assets := make([]any, 0, len(networkConfig.Assets))
+				for _, asset := range networkConfig.Assets {
+					assets = append(assets, asset.Address)
+				}
+				network["assets"] = assets
+			}
+			networks = append(networks, network)
+		}
+		params["networks"] = networks
+	}
+	if len(pricing) > 0 {
+		hints := make(map[string]any, len(pricing))
+		for skillID, price := range pricing {
+			hints[skillID] = price
+		}
+		params["pricing"] = hints
+	}
+	if len(skillPricing) > 0 {
+		params[x402.ExtensionParamSkillPricing] = skillPricing
+	}
+	if len(params) == 0 {
+		return nil
+	}
+	return params
+}
+
+// skillPricingHints lists, for every skill, the price it is quoted at on each
+// network and asset. Prices stay in the form the business service gave them,
+// so a fiat price reads "USD 1.50" and an asset's fixed price overrides the
+// service price just as it does in a quote.
+func skillPricingHints(networkConfigs []types.NetworkConfig, requirements map[string]business.ServiceRequirements) (map[string]any, error) {
+	if len(requirements) == 0 {
+		return nil, nil
+	}
+	if len(networkConfigs) == 0 {
+		return nil, errors.New("skill requirements need at least one network config")
+	}
+
+	hints := make(map[string]any, len(requirements))
+	for skillID, params := range requirements {
+		scheme := params.Scheme
+		if scheme == "" {
+			scheme = x402.SchemeExact
+		}
+		price := strings.TrimSpace(params.Price)
+		if params.Free || price == "" {
+			price = "0"
+		}
+
+		var skillHints []any
+		for _, networkConfig := range networkConfigs {
+			if err := validateScheme(params.Scheme, networkConfig.NetworkName); err != nil {
+				return nil, fmt.Errorf("skill %q: %w", skillID, err)
+			}
+			assets, err := pricedAssets(networkConfig)
+			if err != nil {
+				return nil, fmt.Errorf("skill %q on network %s: %w", skillID, networkConfig.NetworkName, err)
+			}
+			for _, asset := range assets {
+				assetPrice := price
+				if asset.Price != "" && price != "0" {
+					assetPrice = asset.Price
+				}
+				skillHints = append(skillHints, map[string]any{
+					"network": networkConfig.NetworkName,
+					"asset":   asset.Address,
+					"price":   assetPrice,
+					"scheme":  scheme,
+				})
+			}
+		}
+		hints[skillID] = skillHints
+	}
+	return hints, nil
+}
+
+func hasSkill(skills []a2a.AgentSkill, id string) bool {
+	for _, skill := range skills {
+		if skill.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// AgentCardSource serves an agent card whose pricing hints can change while
+// the merchant is running. It implements a2asrv.AgentCardProducer; serve it
+// with WithHandlerAgentCardSource.
+type AgentCardSource struct {
+	mu   sync.RWMutex
+	cfg  AgentCardConfig
+	card *a2a.AgentCard
+}
+
+// NewAgentCardSource builds the initial card from cfg.
+func NewAgentCardSource(cfg AgentCardConfig) (*AgentCardSource, error) {
+	card, err := NewAgentCard(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &AgentCardSource{cfg: cfg, card: card}, nil
+}
+
+// Card returns the current card.
+func (s *AgentCardSource) Card(ctx context.Context) (*a2a.AgentCard, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.card, nil
+}
+
+// SetSkillRequirements rebuilds the card with new per-skill requirements.
+// If the new card cannot be built, the current one keeps being served.
+func (s *AgentCardSource) SetSkillRequirements(requirements map[string]business.ServiceRequirements) error {
+	return s.update(func(cfg *AgentCardConfig) {
+		cfg.SkillRequirements = requirements
+	})
+}
+
+// SetNetworkConfigs rebuilds the card for a new set of networks, such as
+// after an asset's fixed price changes.
+func (s *AgentCardSource) SetNetworkConfigs(networkConfigs []types.NetworkConfig) error {
+	return s.update(func(cfg *AgentCardConfig) {
+		cfg.NetworkConfigs = networkConfigs
+	})
+}
+
+func (s *AgentCardSource) update(change func(*AgentCardConfig)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cfg := s.cfg
+	change(&cfg)
+	card, err := NewAgentCard(cfg)
+	if err != nil {
+		return err
+	}
+	s.cfg, s.card = cfg, card
+	return nil
+}
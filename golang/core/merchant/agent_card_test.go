@@ -0,0 +1,215 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	evmutils "github.com/x402-foundation/x402/go/mechanisms/evm"
+)
+
+func TestNewAgentCard(t *testing.T) {
+	skills := []a2a.AgentSkill{{ID: "summarize", Name: "Summarize"}, {Name: "translate"}}
+	tests := []struct {
+		name       string
+		cfg        AgentCardConfig
+		wantErr    bool
+		wantParams map[string]any
+	}{
+		{
+			name: "extension with networks and pricing",
+			cfg: AgentCardConfig{
+				Name:   "merchant",
+				URL:    "http://localhost:8080/rpc",
+				Skills: skills,
+				NetworkConfigs: []types.NetworkConfig{
+					{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"},
+					{NetworkName: x402.NetworkBase, PayToAddress: "0x123", Assets: []types.AssetConfig{{Address: "0xusdc"}}},
+				},
+				SkillPricing: map[string]string{"summarize": "$0.10"},
+			},
+			wantParams: map[string]any{
+				"networks": []any{
+					map[string]any{"network": x402.NetworkBaseSepolia},
+					map[string]any{"network": x402.NetworkBase, "assets": []any{"0xusdc"}},
+				},
+				"pricing": map[string]any{"summarize": "$0.10"},
+			},
+		},
+		{
+			name: "extension without params",
+			cfg:  AgentCardConfig{URL: "http://localhost:8080/rpc", Skills: skills},
+		},
+		{
+			name:    "missing URL",
+			cfg:     AgentCardConfig{Skills: skills},
+			wantErr: true,
+		},
+		{
+			name:    "missing skills",
+			cfg:     AgentCardConfig{URL: "http://localhost:8080/rpc"},
+			wantErr: true,
+		},
+		{
+			name: "pricing for unknown skill",
+			cfg: AgentCardConfig{
+				URL:          "http://localhost:8080/rpc",
+				Skills:       skills,
+				SkillPricing: map[string]string{"missing": "$1"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card, err := NewAgentCard(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewAgentCard() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			extensions := card.Capabilities.Extensions
+			if len(extensions) != 1 {
+				t.Fatalf("extensions = %v, want exactly one", extensions)
+			}
+			if extensions[0].URI != x402.X402ExtensionURI || !extensions[0].Required {
+				t.Errorf("extension = %+v, want required %s", extensions[0], x402.X402ExtensionURI)
+			}
+			if !reflect.DeepEqual(extensions[0].Params, tt.wantParams) {
+				t.Errorf("extension params = %v, want %v", extensions[0].Params, tt.wantParams)
+			}
+			if card.ProtocolVersion != x402.A2AProtocolVersion {
+				t.Errorf("ProtocolVersion = %q, want %q", card.ProtocolVersion, x402.A2AProtocolVersion)
+			}
+			if card.Skills[1].ID != "translate" {
+				t.Errorf("skill without ID got ID %q, want its name", card.Skills[1].ID)
+			}
+		})
+	}
+}
+
+func TestNewAgentCardSkillPricingHints(t *testing.T) {
+	usdc, err := evmutils.GetAssetInfo(x402.NetworkBaseSepolia, "")
+	if err != nil {
+		t.Fatalf("GetAssetInfo() error = %v", err)
+	}
+	cfg := AgentCardConfig{
+		URL:    "http://localhost:8080/rpc",
+		Skills: []a2a.AgentSkill{{ID: "summarize"}, {ID: "translate"}},
+		NetworkConfigs: []types.NetworkConfig{
+			{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"},
+			{NetworkName: x402.NetworkBase, PayToAddress: "0x123", Assets: []types.AssetConfig{
+				{Address: "0xusdc", Decimals: 6},
+				{Address: "0xfixed", Decimals: 6, Price: "0.25"},
+			}},
+		},
+		SkillRequirements: map[string]business.ServiceRequirements{
+			"summarize": {Price: "0.10", Scheme: x402.SchemeUpto},
+			"translate": {Free: true},
+		},
+	}
+
+	card, err := NewAgentCard(cfg)
+	if err != nil {
+		t.Fatalf("NewAgentCard() error = %v", err)
+	}
+	got := card.Capabilities.Extensions[0].Params[x402.ExtensionParamSkillPricing]
+	want := map[string]any{
+		"summarize": []any{
+			map[string]any{"network": x402.NetworkBaseSepolia, "asset": usdc.Address, "price": "0.10", "scheme": x402.SchemeUpto},
+			map[string]any{"network": x402.NetworkBase, "asset": "0xusdc", "price": "0.10", "scheme": x402.SchemeUpto},
+			map[string]any{"network": x402.NetworkBase, "asset": "0xfixed", "price": "0.25", "scheme": x402.SchemeUpto},
+		},
+		"translate": []any{
+			map[string]any{"network": x402.NetworkBaseSepolia, "asset": usdc.Address, "price": "0", "scheme": x402.SchemeExact},
+			map[string]any{"network": x402.NetworkBase, "asset": "0xusdc", "price": "0", "scheme": x402.SchemeExact},
+			map[string]any{"network": x402.NetworkBase, "asset": "0xfixed", "price": "0", "scheme": x402.SchemeExact},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("skill pricing = %v, want %v", got, want)
+	}
+
+	for name, bad := range map[string]AgentCardConfig{
+		"unknown skill": {URL: cfg.URL, Skills: cfg.Skills, NetworkConfigs: cfg.NetworkConfigs,
+			SkillRequirements: map[string]business.ServiceRequirements{"missing": {Price: "1"}}},
+		"no networks": {URL: cfg.URL, Skills: cfg.Skills,
+			SkillRequirements: map[string]business.ServiceRequirements{"summarize": {Price: "1"}}},
+		"upto on solana": {URL: cfg.URL, Skills: cfg.Skills,
+			NetworkConfigs:    []types.NetworkConfig{{NetworkName: x402.NetworkSolanaDevnet, PayToAddress: "0x123"}},
+			SkillRequirements: map[string]business.ServiceRequirements{"summarize": {Price: "1", Scheme: x402.SchemeUpto}}},
+	} {
+		if _, err := NewAgentCard(bad); err == nil {
+			t.Errorf("%s: NewAgentCard() succeeded, want an error", name)
+		}
+	}
+}
+
+func TestAgentCardSourceServesRefreshedPricing(t *testing.T) {
+	source, err := NewAgentCardSource(AgentCardConfig{
+		URL:               "http://localhost:8080/rpc",
+		Skills:            []a2a.AgentSkill{{ID: "summarize"}},
+		NetworkConfigs:    []types.NetworkConfig{{NetworkName: x402.NetworkBase, PayToAddress: "0x123", Assets: []types.AssetConfig{{Address: "0xusdc", Decimals: 6}}}},
+		SkillRequirements: map[string]business.ServiceRequirements{"summarize": {Price: "0.10"}},
+	})
+	if err != nil {
+		t.Fatalf("NewAgentCardSource() error = %v", err)
+	}
+	handler := NewHTTPHandler(newHandlerMerchant(t), WithHandlerAgentCardSource(source))
+
+	priceOf := func() any {
+		t.Helper()
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, a2asrv.WellKnownAgentCardPath, nil))
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("GET agent card status = %d, want 200", recorder.Code)
+		}
+		var card a2a.AgentCard
+		if err := json.Unmarshal(recorder.Body.Bytes(), &card); err != nil {
+			t.Fatalf("decode agent card: %v", err)
+		}
+		hints := card.Capabilities.Extensions[0].Params[x402.ExtensionParamSkillPricing].(map[string]any)
+		return hints["summarize"].([]any)[0].(map[string]any)["price"]
+	}
+
+	if got := priceOf(); got != "0.10" {
+		t.Errorf("initial price = %v, want 0.10", got)
+	}
+	if err := source.SetSkillRequirements(map[string]business.ServiceRequirements{"summarize": {Price: "0.20"}}); err != nil {
+		t.Fatalf("SetSkillRequirements() error = %v", err)
+	}
+	if got := priceOf(); got != "0.20" {
+		t.Errorf("refreshed price = %v, want 0.20", got)
+	}
+
+	if err := source.SetSkillRequirements(map[string]business.ServiceRequirements{"missing": {Price: "1"}}); err == nil {
+		t.Fatal("SetSkillRequirements() for an unknown skill succeeded, want an error")
+	}
+	if got := priceOf(); got != "0.20" {
+		t.Errorf("price after a failed refresh = %v, want 0.20", got)
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+// TimeRange selects records in [From, To).
+type TimeRange struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// AssetTotal is the revenue in one asset on one network. Amount is the sum
+// in the asset's base units as a decimal string, so it survives JSON
+// consumers that read numbers as floats.
+type AssetTotal struct {
+	Network  string `json:"network"`
+	Asset    string `json:"asset"`
+	Amount   string `json:"amount"`
+	Receipts int    `json:"receipts"`
+}
+
+// NetworkTotal is the revenue on one network. Amounts in different assets do
+// not add up, so it carries one total per asset.
+type NetworkTotal struct {
+	Network  string       `json:"network"`
+	Receipts int          `json:"receipts"`
+	Assets   []AssetTotal `json:"assets"`
+}
+
+// PayerTotal is what one payer paid, one total per asset.
+type PayerTotal struct {
+	Payer    string       `json:"payer"`
+	Receipts int          `json:"receipts"`
+	Assets   []AssetTotal `json:"assets"`
+}
+
+// FailureCount is how many failures share a key.
+type FailureCount struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+// FailureBreakdown counts failed tasks by error code and by network. Failures
+// from before a payment was submitted have no network and are counted under
+// an empty key.
+type FailureBreakdown struct {
+	Total     int            `json:"total"`
+	ByCode    []FailureCount `json:"byCode"`
+	ByNetwork []FailureCount `json:"byNetwork"`
+}
+
+// Analytics aggregates the receipt and failure stores for admin dashboards.
+// Every result is sorted deterministically and marshals to JSON as is.
+// Revenue is gross: refund records are left out rather than subtracted.
+type Analytics struct {
+	receipts ReceiptStore
+	failures FailureStore
+}
+
+// NewAnalytics reads receipts and failures; either may be nil, in which case
+// the queries over it return nothing.
+func NewAnalytics(receipts ReceiptStore, failures FailureStore) *Analytics {
+	return &Analytics{receipts: receipts, failures: failures}
+}
+
+// TotalsByNetwork returns the revenue per network, sorted by network.
+func (a *Analytics) TotalsByNetwork(ctx context.Context, r TimeRange) ([]NetworkTotal, error) {
+	records, err := a.listReceipts(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	byNetwork := make(map[string][]*ReceiptRecord)
+	for _, record := range records {
+		byNetwork[record.Network] = append(byNetwork[record.Network], record)
+	}
+	totals := make([]NetworkTotal, 0, len(byNetwork))
+	for network, records := range byNetwork {
+		assets, err := sumByAsset(records)
+		if err != nil {
+			return nil, err
+		}
+		totals = append(totals, NetworkTotal{Network: network, Receipts: len(records), Assets: assets})
+	}
+	slices.SortFunc(totals, func(a, b NetworkTotal) int {
+		return strings.Compare(a.Network, b.Network)
+	})
+	return totals, nil
+}
+
+// TotalsByAsset returns the revenue per asset and network, sorted by network
+// then asset.
+func (a *Analytics) TotalsByAsset(ctx context.Context, r TimeRange) ([]AssetTotal, error) {
+	records, err := a.listReceipts(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	return sumByAsset(records)
+}
+
+// TopPayers returns the n payers with the most receipts, ties broken by
+// payer address. Payers are ranked by count because amounts in different
+// assets cannot be compared. Addresses compare case-insensitively and are
+// reported as first seen. Receipts without a payer are left out, and n <= 0
+// returns every payer.
+func (a *Analytics) TopPayers(ctx context.Context, n int, r TimeRange) ([]PayerTotal, error) {
+	records, err := a.listReceipts(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	var order []string
+	byPayer := make(map[string][]*ReceiptRecord)
+	for _, record := range records {
+		if record.Payer == "" {
+			continue
+		}
+		key := strings.ToLower(record.Payer)
+		if _, seen := byPayer[key]; !seen {
+			order = append(order, key)
+		}
+		byPayer[key] = append(byPayer[key], record)
+	}
+	totals := make([]PayerTotal, 0, len(order))
+	for _, key := range order {
+		records := byPayer[key]
+		assets, err := sumByAsset(records)
+		if err != nil {
+			return nil, err
+		}
+		totals = append(totals, PayerTotal{Payer: records[0].Payer, Receipts: len(records), Assets: assets})
+	}
+	slices.SortFunc(totals, func(a, b PayerTotal) int {
+		if c := cmp.Compare(b.Receipts, a.Receipts); c != 0 {
+			return c
+		}
+		return strings.Compare(strings.ToLower(a.Payer), strings.ToLower(b.Payer))
+	})
+	if n > 0 && len(totals) > n {
+		totals = totals[:n]
+	}
+	return totals, nil
+}
+
+// FailureBreakdown counts the failures in r. Counts are sorted by count,
+// largest first, then by key.
+func (a *Analytics) FailureBreakdown(ctx context.Context, r TimeRange) (*FailureBreakdown, error) {
+	breakdown := &FailureBreakdown{ByCode: []FailureCount{}, ByNetwork: []FailureCount{}}
+	if a.failures == nil {
+		return breakdown, nil
+	}
+	records, err := a.failures.ListByTimeRange(ctx, r.From, r.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list failures: %w", err)
+	}
+	byCode := make(map[string]int)
+	byNetwork := make(map[string]int)
+	for _, record := range records {
+		byCode[record.ErrorCode]++
+		byNetwork[record.Network]++
+	}
+	breakdown.Total = len(records)
+	breakdown.ByCode = sortedCounts(byCode)
+	breakdown.ByNetwork = sortedCounts(byNetwork)
+	return breakdown, nil
+}
+
+func (a *Analytics) listReceipts(ctx context.Context, r TimeRange) ([]*ReceiptRecord, error) {
+	if a.receipts == nil {
+		return nil, nil
+	}
+	records, err := a.receipts.ListByTimeRange(ctx, r.From, r.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list receipts: %w", err)
+	}
+	return slices.DeleteFunc(records, func(record *ReceiptRecord) bool {
+		return record.Refund
+	}), nil
+}
+
+// sumByAsset adds up records per network and asset with big integers, since
+// base-unit amounts overflow int64 and lose precision as float64.
+func sumByAsset(records []*ReceiptRecord) ([]AssetTotal, error) {
+	type key struct{ network, asset string }
+	sums := make(map[key]*big.Int)
+	counts := make(map[key]int)
+	for _, record := range records {
+		amount, ok := new(big.Int).SetString(record.Amount, 10)
+		if !ok || amount.Sign() < 0 {
+			return nil, fmt.Errorf("receipt %s for task %s has invalid amount %q", record.Transaction, record.TaskID, record.Amount)
+		}
+		k := key{record.Network, record.Asset}
+		if sums[k] == nil {
+			sums[k] = new(big.Int)
+		}
+		sums[k].Add(sums[k], amount)
+		counts[k]++
+	}
+	totals := make([]AssetTotal, 0, len(sums))
+	for k, sum := range sums {
+		totals = append(totals, AssetTotal{Network: k.network, Asset: k.asset, Amount: sum.String(), Receipts: counts[k]})
+	}
+	slices.SortFunc(totals, func(a, b AssetTotal) int {
+		if c := strings.Compare(a.Network, b.Network); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Asset, b.Asset)
+	})
+	return totals, nil
+}
+
+func sortedCounts(counts map[string]int) []FailureCount {
+	sorted := make([]FailureCount, 0, len(counts))
+	for key, count := range counts {
+		sorted = append(sorted, FailureCount{Key: key, Count: count})
+	}
+	slices.SortFunc(sorted, func(a, b FailureCount) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Key, b.Key)
+	})
+	return sorted
+}
@@ -0,0 +1,245 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+const (
+	usdcBase        = "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"
+	usdcBaseSepolia = "0x036CbD53842c5426634e7929541eC2318f3dCF7e"
+	wethBase        = "0x4200000000000000000000000000000000000006"
+)
+
+// seedAnalytics records 36 receipts across two networks, inside and outside
+// the hour starting at base.
+func seedAnalytics(t *testing.T, base time.Time) (*MemoryReceiptStore, *MemoryFailureStore) {
+	t.Helper()
+	ctx := context.Background()
+	receipts := NewMemoryReceiptStore()
+	payers := []string{"0xAAA", "0xbbb", "0xccc"}
+	for i := 0; i < 30; i++ {
+		record := &ReceiptRecord{
+			TaskID:      a2a.TaskID(fmt.Sprintf("task-%02d", i)),
+			Payer:       payers[i%3],
+			Network:     x402.NetworkBaseSepolia,
+			Asset:       usdcBaseSepolia,
+			Amount:      "1000000",
+			Transaction: fmt.Sprintf("0x%02d", i),
+			SettledAt:   base.Add(time.Duration(i) * time.Minute),
+		}
+		if i%2 == 0 {
+			record.Network = x402.NetworkBase
+			record.Asset = usdcBase
+			record.Amount = "250000"
+		}
+		if i == 0 {
+			record.Payer = "0xaaa"
+		}
+		if err := receipts.Append(ctx, record); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+	// Two WETH receipts whose sum overflows int64.
+	for i, amount := range []string{"9000000000000000000", "9000000000000000001"} {
+		if err := receipts.Append(ctx, &ReceiptRecord{
+			TaskID:      a2a.TaskID(fmt.Sprintf("task-weth-%d", i)),
+			Payer:       "0xccc",
+			Network:     x402.NetworkBase,
+			Asset:       wethBase,
+			Amount:      amount,
+			Transaction: fmt.Sprintf("0xweth%d", i),
+			SettledAt:   base.Add(time.Duration(40+i) * time.Minute),
+		}); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+	// Receipts outside the hour are left out of every total.
+	for i, settledAt := range []time.Time{base.Add(-time.Second), base.Add(time.Hour), base.Add(2 * time.Hour), base.Add(-time.Hour)} {
+		if err := receipts.Append(ctx, &ReceiptRecord{
+			TaskID:      a2a.TaskID(fmt.Sprintf("task-outside-%d", i)),
+			Payer:       "0xddd",
+			Network:     x402.NetworkBase,
+			Asset:       usdcBase,
+			Amount:      "999",
+			Transaction: fmt.Sprintf("0xout%d", i),
+			SettledAt:   settledAt,
+		}); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	failures := NewMemoryFailureStore()
+	for i, record := range []FailureRecord{
+		{ErrorCode: x402.ErrorCodeInvalidSignature, Network: x402.NetworkBase},
+		{ErrorCode: x402.ErrorCodeInvalidSignature, Network: x402.NetworkBaseSepolia},
+		{ErrorCode: x402.ErrorCodeInsufficientFunds, Network: x402.NetworkBase},
+		{ErrorCode: x402.ErrorCodeQuoteExpired},
+		{ErrorCode: x402.ErrorCodeSettlementFailed, Network: x402.NetworkBase},
+	} {
+		record.TaskID = a2a.TaskID(fmt.Sprintf("failed-%d", i))
+		record.FailedAt = base.Add(time.Duration(i) * time.Minute)
+		if err := failures.Append(ctx, &record); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+	if err := failures.Append(ctx, &FailureRecord{TaskID: "failed-late", ErrorCode: x402.ErrorCodeInternal, FailedAt: base.Add(time.Hour)}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	return receipts, failures
+}
+
+// asJSON marshals v, which is how a dashboard sees the aggregates.
+func asJSON(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	return string(data)
+}
+
+func TestAnalytics_Totals(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	receipts, failures := seedAnalytics(t, base)
+	analytics := NewAnalytics(receipts, failures)
+	hour := TimeRange{From: base, To: base.Add(time.Hour)}
+
+	byAsset, err := analytics.TotalsByAsset(ctx, hour)
+	if err != nil {
+		t.Fatalf("TotalsByAsset() error = %v", err)
+	}
+	wantAssets := `[` +
+		`{"network":"eip155:8453","asset":"` + wethBase + `","amount":"18000000000000000001","receipts":2},` +
+		`{"network":"eip155:8453","asset":"` + usdcBase + `","amount":"3750000","receipts":15},` +
+		`{"network":"eip155:84532","asset":"` + usdcBaseSepolia + `","amount":"15000000","receipts":15}]`
+	if got := asJSON(t, byAsset); got != wantAssets {
+		t.Errorf("TotalsByAsset() = %s\nwant %s", got, wantAssets)
+	}
+
+	byNetwork, err := analytics.TotalsByNetwork(ctx, hour)
+	if err != nil {
+		t.Fatalf("TotalsByNetwork() error = %v", err)
+	}
+	if len(byNetwork) != 2 {
+		t.Fatalf("TotalsByNetwork() = %s, want two networks", asJSON(t, byNetwork))
+	}
+	if got := byNetwork[0]; got.Network != x402.NetworkBase || got.Receipts != 17 || len(got.Assets) != 2 {
+		t.Errorf("base total = %s", asJSON(t, got))
+	}
+	if got := byNetwork[1]; got.Network != x402.NetworkBaseSepolia || got.Receipts != 15 || len(got.Assets) != 1 || got.Assets[0].Amount != "15000000" {
+		t.Errorf("base sepolia total = %s", asJSON(t, got))
+	}
+
+	payers, err := analytics.TopPayers(ctx, 2, hour)
+	if err != nil {
+		t.Fatalf("TopPayers() error = %v", err)
+	}
+	wantPayers := `[` +
+		`{"payer":"0xccc","receipts":12,"assets":[` +
+		`{"network":"eip155:8453","asset":"` + wethBase + `","amount":"18000000000000000001","receipts":2},` +
+		`{"network":"eip155:8453","asset":"` + usdcBase + `","amount":"1250000","receipts":5},` +
+		`{"network":"eip155:84532","asset":"` + usdcBaseSepolia + `","amount":"5000000","receipts":5}]},` +
+		`{"payer":"0xaaa","receipts":10,"assets":[` +
+		`{"network":"eip155:8453","asset":"` + usdcBase + `","amount":"1250000","receipts":5},` +
+		`{"network":"eip155:84532","asset":"` + usdcBaseSepolia + `","amount":"5000000","receipts":5}]}]`
+	if got := asJSON(t, payers); got != wantPayers {
+		t.Errorf("TopPayers() = %s\nwant %s", got, wantPayers)
+	}
+	if all, _ := analytics.TopPayers(ctx, 0, hour); len(all) != 3 {
+		t.Errorf("TopPayers(0) returned %d payers, want 3", len(all))
+	}
+
+	breakdown, err := analytics.FailureBreakdown(ctx, hour)
+	if err != nil {
+		t.Fatalf("FailureBreakdown() error = %v", err)
+	}
+	wantBreakdown := `{"total":5,` +
+		`"byCode":[{"key":"INVALID_SIGNATURE","count":2},{"key":"INSUFFICIENT_FUNDS","count":1},{"key":"QUOTE_EXPIRED","count":1},{"key":"SETTLEMENT_FAILED","count":1}],` +
+		`"byNetwork":[{"key":"eip155:8453","count":3},{"key":"","count":1},{"key":"eip155:84532","count":1}]}`
+	if got := asJSON(t, breakdown); got != wantBreakdown {
+		t.Errorf("FailureBreakdown() = %s\nwant %s", got, wantBreakdown)
+	}
+}
+
+func TestAnalytics_InvalidAmount(t *testing.T) {
+	receipts := NewMemoryReceiptStore()
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := receipts.Append(context.Background(), &ReceiptRecord{TaskID: "task-a", Network: x402.NetworkBase, Amount: "1.5", SettledAt: base}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if _, err := NewAnalytics(receipts, nil).TotalsByAsset(context.Background(), TimeRange{From: base, To: base.Add(time.Hour)}); err == nil {
+		t.Error("TotalsByAsset() error = nil, want invalid amount error")
+	}
+}
+
+func TestAnalytics_WithoutStores(t *testing.T) {
+	analytics := NewAnalytics(nil, nil)
+	hour := TimeRange{To: time.Now()}
+	if totals, err := analytics.TotalsByNetwork(context.Background(), hour); err != nil || len(totals) != 0 {
+		t.Errorf("TotalsByNetwork() = %v, %v; want none", totals, err)
+	}
+	breakdown, err := analytics.FailureBreakdown(context.Background(), hour)
+	if err != nil {
+		t.Fatalf("FailureBreakdown() error = %v", err)
+	}
+	if got := asJSON(t, breakdown); got != `{"total":0,"byCode":[],"byNetwork":[]}` {
+		t.Errorf("FailureBreakdown() = %s", got)
+	}
+}
+
+func TestBusinessOrchestrator_RecordsFailures(t *testing.T) {
+	failures := NewMemoryFailureStore()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	orchestrator := newTestOrchestrator(
+		t,
+		&MockResourceServer{
+			VerifyPaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.VerifyResponse, error) {
+				return &x402core.VerifyResponse{IsValid: false, InvalidReason: "invalid_exact_evm_payload_signature"}, nil
+			},
+		},
+		&mockBusinessService{},
+		[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+		WithFailureStore(failures),
+		WithClock(func() time.Time { return now }),
+	)
+	task := quoteTask(t, orchestrator)
+	payOnNetwork(t, orchestrator, task, x402.NetworkBaseSepolia)
+
+	records, err := failures.ListByTimeRange(context.Background(), now, now.Add(time.Second))
+	if err != nil {
+		t.Fatalf("ListByTimeRange() error = %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("recorded %d failures, want 1", len(records))
+	}
+	got := records[0]
+	code := task.Status.Message.Meta()[x402.MetadataKeyError]
+	if got.TaskID != task.ID || got.ErrorCode == "" || got.ErrorCode != code || got.Network != x402.NetworkBaseSepolia || got.State != a2a.TaskStateFailed {
+		t.Errorf("failure record = %+v", got)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// WithRejectUnlistedAssets decides what happens to a built requirement whose
+// asset is not in its network's AllowedAssets. By default it is dropped from
+// the quote and logged; when reject is set the whole quote fails instead.
+// Networks without AllowedAssets are not checked.
+func WithRejectUnlistedAssets(reject bool) Option {
+	return func(o *BusinessOrchestrator) {
+		o.rejectUnlistedAssets = reject
+	}
+}
+
+// unlistedAssetError reports a built requirement for an asset the network
+// does not allow.
+type unlistedAssetError struct {
+	network string
+	asset   string
+}
+
+func (e *unlistedAssetError) Error() string {
+	return fmt.Sprintf("asset %q is not allowed on network %s", e.asset, e.network)
+}
+
+// filterAllowedAssets removes requirements whose asset is not allowed on
+// networkConfig, or fails when unlisted assets are rejected.
+func (o *BusinessOrchestrator) filterAllowedAssets(
+	ctx context.Context,
+	networkConfig types.NetworkConfig,
+	reqs []*x402types.PaymentRequirements,
+) ([]*x402types.PaymentRequirements, error) {
+	if len(networkConfig.AllowedAssets) == 0 {
+		return reqs, nil
+	}
+	allowed := make([]*x402types.PaymentRequirements, 0, len(reqs))
+	for _, req := range reqs {
+		if containsAddress(networkConfig.AllowedAssets, req.Asset) {
+			allowed = append(allowed, req)
+			continue
+		}
+		err := &unlistedAssetError{network: networkConfig.NetworkName, asset: req.Asset}
+		if o.rejectUnlistedAssets {
+			return nil, err
+		}
+		o.logger.WarnContext(ctx, "x402 requirement dropped: asset not allowed",
+			"network", networkConfig.NetworkName,
+			"asset", req.Asset,
+			"scheme", req.Scheme,
+			"amount", req.Amount,
+		)
+	}
+	return allowed, nil
+}
+
+// checkBuiltPayTo confirms that a requirement built by the resource server
+// still pays the configured address. EVM addresses compare
+// case-insensitively.
+func checkBuiltPayTo(req x402types.PaymentRequirements, networkConfig types.NetworkConfig) error {
+	payTo := networkConfig.PayToAddress
+	if req.PayTo == payTo || (x402pkg.IsEVMNetwork(networkConfig.NetworkName) && strings.EqualFold(req.PayTo, payTo)) {
+		return nil
+	}
+	return fmt.Errorf("built requirement pays %q instead of the configured address %q", req.PayTo, payTo)
+}
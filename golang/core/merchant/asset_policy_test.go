@@ -0,0 +1,104 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402pkg "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+func TestBuildPaymentRequirements_AssetPolicy(t *testing.T) {
+	const payTo = "0x1230000000000000000000000000000000000001"
+
+	tests := []struct {
+		name    string
+		allowed []string
+		reject  bool
+		assets  []string
+		// rewrittenPayTo is returned by the resource server instead of the
+		// configured address.
+		rewrittenPayTo string
+		wantAssets     []string
+		wantDropped    bool
+		wantErr        string
+	}{
+		{name: "no allowlist", assets: []string{"0x456", "0xbad"}, wantAssets: []string{"0x456", "0xbad"}},
+		{name: "unlisted asset dropped", allowed: []string{"0x456"}, assets: []string{"0x456", "0xbad"}, wantAssets: []string{"0x456"}, wantDropped: true},
+		{name: "allowlist is case-insensitive", allowed: []string{"0xABC"}, assets: []string{"0xabc"}, wantAssets: []string{"0xabc"}},
+		{name: "unlisted asset rejected", allowed: []string{"0x456"}, reject: true, assets: []string{"0x456", "0xbad"}, wantErr: `asset "0xbad" is not allowed`},
+		{name: "every asset dropped", allowed: []string{"0x456"}, assets: []string{"0xbad"}, wantErr: "no payment requirements left"},
+		{name: "rewritten payTo", assets: []string{"0x456"}, rewrittenPayTo: "0x9990000000000000000000000000000000000009", wantErr: "instead of the configured address"},
+		{name: "payTo case ignored on EVM", assets: []string{"0x456"}, rewrittenPayTo: "0x" + strings.ToUpper(payTo[2:]), wantAssets: []string{"0x456"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			server := &MockResourceServer{
+				BuildPaymentRequirementsFromConfigFunc: func(ctx context.Context, config x402pkg.ResourceConfig) ([]x402types.PaymentRequirements, error) {
+					builtPayTo := config.PayTo
+					if tt.rewrittenPayTo != "" {
+						builtPayTo = tt.rewrittenPayTo
+					}
+					var reqs []x402types.PaymentRequirements
+					for _, asset := range tt.assets {
+						reqs = append(reqs, x402types.PaymentRequirements{Scheme: "exact", Network: string(config.Network), PayTo: builtPayTo, Asset: asset, Amount: "1000"})
+					}
+					return reqs, nil
+				},
+			}
+			orchestrator := newTestOrchestrator(
+				t,
+				server,
+				&mockBusinessService{},
+				[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: payTo, AllowedAssets: tt.allowed}},
+				WithRejectUnlistedAssets(tt.reject),
+				WithLogger(slog.New(slog.NewJSONHandler(&logs, nil))),
+			)
+
+			paymentState, err := orchestrator.buildPaymentRequirements(context.Background(), "", business.NewPaymentRequiredError("pay", business.ServiceRequirements{
+				Price: "1.00", Resource: "/test", Scheme: "exact",
+			}), nil)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("buildPaymentRequirements() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("buildPaymentRequirements() error = %v", err)
+			}
+			var assets []string
+			for _, accepted := range paymentState.Requirements.Accepts {
+				assets = append(assets, accepted.Asset)
+			}
+			if strings.Join(assets, ",") != strings.Join(tt.wantAssets, ",") {
+				t.Errorf("quoted assets = %v, want %v", assets, tt.wantAssets)
+			}
+			if dropped := strings.Contains(logs.String(), `"asset":"0xbad"`); dropped != tt.wantDropped {
+				t.Errorf("dropped asset logged = %v, want %v; logs:\n%s", dropped, tt.wantDropped, logs.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/a2aproject/a2a-go/a2asrv/eventqueue"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// AsyncSettlementConfig configures background settlement.
+type AsyncSettlementConfig struct {
+	// Workers is the number of concurrent settlements. Defaults to 4.
+	Workers int
+	// QueueSize bounds pending settlements. When the queue is full the
+	// payment is settled inline instead. Defaults to 64.
+	QueueSize int
+	// OnSettled is called once per background settlement with the receipt and,
+	// on failure, the settlement error. The task has already completed by then,
+	// so this is the reliable place to deliver receipts, e.g. via push
+	// notifications.
+	OnSettled func(ctx context.Context, taskID a2a.TaskID, receipt *x402core.SettleResponse, err error)
+}
+
+// WithAsyncSettlement completes tasks as soon as the business logic succeeds
+// and settles the payment in a bounded background worker pool. Completed
+// tasks keep the payment-verified status until the receipt arrives. Call
+// Shutdown to drain pending settlements.
+func WithAsyncSettlement(config AsyncSettlementConfig) Option {
+	return func(o *BusinessOrchestrator) {
+		if config.Workers <= 0 {
+			config.Workers = 4
+		}
+		if config.QueueSize <= 0 {
+			config.QueueSize = 64
+		}
+		o.asyncSettlement = &asyncSettler{
+			config: config,
+			jobs:   make(chan *settlementJob, config.QueueSize),
+		}
+	}
+}
+
+type settlementJob struct {
+	ctx            context.Context
+	task           *a2a.Task
+	requestContext *a2asrv.RequestContext
+	queue          eventqueue.Queue
+	paymentState   *state.PaymentState
+	requirement    *x402types.PaymentRequirements
+	metering       *metering
+}
+
+type asyncSettler struct {
+	config AsyncSettlementConfig
+	jobs   chan *settlementJob
+
+	mu      sync.RWMutex
+	closed  bool
+	workers sync.WaitGroup
+}
+
+func (s *asyncSettler) start(o *BusinessOrchestrator) {
+	for range s.config.Workers {
+		s.workers.Add(1)
+		go func() {
+			defer s.workers.Done()
+			for job := range s.jobs {
+				o.settleInBackground(job)
+			}
+		}()
+	}
+}
+
+// enqueue reports false when the pool is full or shut down.
+func (s *asyncSettler) enqueue(job *settlementJob) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return false
+	}
+	select {
+	case s.jobs <- job:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *asyncSettler) shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.jobs)
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("pending settlements not drained: %w", ctx.Err())
+	}
+}
+
+// completeBeforeSettlement hands settlement to the batch queue or the worker
+// pool and completes the task with the business result. It reports false when
+// neither can take the payment and the caller must settle inline.
+func (o *BusinessOrchestrator) completeBeforeSettlement(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	eventQueue eventqueue.Queue,
+	paymentState *state.PaymentState,
+	matchedRequirement *x402types.PaymentRequirements,
+	businessResult *business.Result,
+	meter *metering,
+) (bool, error) {
+	if o.batchSettlement != nil {
+		return o.completeForBatch(ctx, requestContext, task, eventQueue, paymentState, matchedRequirement, businessResult)
+	}
+	job := &settlementJob{
+		ctx:            context.WithoutCancel(ctx),
+		task:           task,
+		requestContext: requestContext,
+		queue:          eventQueue,
+		paymentState:   paymentState,
+		requirement:    matchedRequirement,
+		metering:       meter,
+	}
+	if !o.asyncSettlement.enqueue(job) {
+		return false, nil
+	}
+
+	return true, o.completeUnsettled(ctx, requestContext, task, eventQueue, businessResult, nil)
+}
+
+// completeUnsettled completes the task with the business result while its
+// payment is verified but not yet settled. A placeholder receipt, when given,
+// stands in for the real one.
+func (o *BusinessOrchestrator) completeUnsettled(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	eventQueue eventqueue.Queue,
+	businessResult *business.Result,
+	placeholder *x402core.SettleResponse,
+) error {
+	if err := o.writeArtifacts(ctx, task, eventQueue, businessArtifacts(businessResult.Message, businessResult.Parts, businessResult.Artifacts)); err != nil {
+		return err
+	}
+	responseText := resultSummary(businessResult.Summary, businessResult.Message, "Task completed")
+	payloadHash := state.ExtractPaymentPayloadHash(task)
+	task.Status.Message = a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{Text: responseText})
+	state.SetPaymentStatus(task.Status.Message, state.PaymentVerified)
+	state.SetPaymentPayloadHash(task.Status.Message, payloadHash)
+	if placeholder != nil {
+		if err := state.SetPaymentReceipts(task.Status.Message, []*x402core.SettleResponse{placeholder}); err != nil {
+			return fmt.Errorf("failed to record placeholder receipt: %w", err)
+		}
+	}
+	task.Status.State = a2a.TaskStateCompleted
+
+	event := statusEvent(requestContext, task)
+	return o.writeTerminalEvent(ctx, task, eventQueue, event)
+}
+
+func (o *BusinessOrchestrator) settleInBackground(job *settlementJob) {
+	receipt, err := o.settleWithRetry(job.ctx, job.requestContext, nil, job.paymentState, job.requirement)
+
+	var message *a2a.Message
+	var code string
+	if err != nil {
+		receipt = normalizeFailureReceipt(job.paymentState, receipt, err)
+		message = a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{Text: err.Error()})
+		state.SetPaymentStatus(message, state.PaymentFailed)
+		code = settlementErrorCode(receipt, err)
+		state.SetPaymentError(message, code)
+		if code == x402pkg.ErrorCodeSettleTimeout {
+			state.SetPaymentIndeterminate(message)
+		}
+		o.logFailed(job.ctx, job.task, code, err)
+		o.hooks.failed(job.ctx, job.task, code, err)
+		if compensation := o.compensate(job.ctx, job.task, job.paymentState, code, err); compensation != "" {
+			state.SetCompensation(message, compensation)
+		}
+	} else {
+		job.metering.attach(receipt)
+		o.recordTip(receipt, job.paymentState)
+		o.logSettled(job.ctx, job.task, receipt)
+		o.hooks.settled(job.ctx, job.task, receipt)
+		o.recordReceipt(job.ctx, job.task, job.paymentState.Payer, job.requirement, receipt)
+		message = a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{Text: "Payment settled"})
+		state.SetPaymentStatus(message, state.PaymentCompleted)
+	}
+	if receiptErr := state.SetPaymentReceipts(message, []*x402core.SettleResponse{receipt}); receiptErr != nil && err == nil {
+		err = fmt.Errorf("failed to record settlement receipt: %w", receiptErr)
+	}
+
+	// The execution's queue is usually closed by now; the write is best effort
+	// and OnSettled is the dependable delivery path.
+	event := a2a.NewStatusUpdateEvent(job.requestContext, a2a.TaskStateCompleted, message)
+	_ = job.queue.Write(job.ctx, event)
+
+	// The task completed before settlement started, so the webhook always
+	// follows the completion event.
+	if err != nil {
+		o.notifyWebhook(job.ctx, WebhookPaymentFailed, job.task, []*x402core.SettleResponse{receipt}, code, err)
+	} else {
+		o.notifyWebhook(job.ctx, WebhookPaymentSettled, job.task, []*x402core.SettleResponse{receipt}, "", nil)
+	}
+
+	if o.asyncSettlement.config.OnSettled != nil {
+		o.asyncSettlement.config.OnSettled(job.ctx, job.requestContext.TaskID, receipt, err)
+	}
+}
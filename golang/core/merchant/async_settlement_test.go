@@ -0,0 +1,143 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+func TestBusinessOrchestrator_AsyncSettlement(t *testing.T) {
+	tests := []struct {
+		name       string
+		settleErr  error
+		wantStatus x402state.PaymentStatus
+	}{
+		{name: "settled", wantStatus: x402state.PaymentCompleted},
+		{name: "settlement failure is recorded", settleErr: errors.New("insufficient funds"), wantStatus: x402state.PaymentFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release := make(chan struct{})
+			type settled struct {
+				taskID  a2a.TaskID
+				receipt *x402core.SettleResponse
+				err     error
+			}
+			results := make(chan settled, 1)
+
+			orchestrator := newTestOrchestrator(
+				t,
+				&MockResourceServer{
+					SettlePaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.SettleResponse, error) {
+						<-release
+						if tt.settleErr != nil {
+							return nil, tt.settleErr
+						}
+						return &x402core.SettleResponse{Success: true, Network: x402.NetworkBaseSepolia, Transaction: "0xtx"}, nil
+					},
+				},
+				&mockBusinessService{executeFunc: func(ctx context.Context, request business.Request) (*business.Result, error) {
+					return &business.Result{Message: "result"}, nil
+				}},
+				[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+				WithAsyncSettlement(AsyncSettlementConfig{
+					Workers: 1,
+					OnSettled: func(ctx context.Context, taskID a2a.TaskID, receipt *x402core.SettleResponse, err error) {
+						results <- settled{taskID: taskID, receipt: receipt, err: err}
+					},
+				}),
+			)
+
+			requirements := x402types.PaymentRequirements{Scheme: "exact", Network: x402.NetworkBaseSepolia, PayTo: "0x123", Asset: "0x456"}
+			task := &a2a.Task{
+				ID:        "task-async",
+				ContextID: "context-async",
+				Status:    a2a.TaskStatus{State: a2a.TaskStateWorking, Message: a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{Text: ""})},
+			}
+			x402state.SetPaymentStatus(task.Status.Message, x402state.PaymentVerified)
+			x402state.SetPaymentPayload(task.Status.Message, &x402types.PaymentPayload{X402Version: x402.X402Version, Accepted: requirements})
+			x402state.SetPaymentRequirements(task.Status.Message, &x402types.PaymentRequired{
+				X402Version: x402.X402Version,
+				Accepts:     []x402types.PaymentRequirements{requirements},
+			})
+			x402state.SetOriginalPrompt(task.Status.Message, "test prompt")
+			requestContext := &a2asrv.RequestContext{
+				Message:    a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "continue"}),
+				StoredTask: task,
+				TaskID:     task.ID,
+				ContextID:  task.ContextID,
+			}
+			queue := &mockEventQueue{}
+
+			if err := orchestrator.executeInPlace(context.Background(), requestContext, queue); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if task.Status.State != a2a.TaskStateCompleted {
+				t.Fatalf("task state = %v, want completed before settlement", task.Status.State)
+			}
+			if status, _ := x402state.ExtractPaymentStatus(task); status != x402state.PaymentVerified {
+				t.Errorf("payment status = %v, want %v while settlement is pending", status, x402state.PaymentVerified)
+			}
+			eventsBeforeSettlement := len(queue.events)
+			if eventsBeforeSettlement == 0 {
+				t.Fatal("expected completion event before settlement")
+			}
+			if update, ok := queue.events[eventsBeforeSettlement-1].(*a2a.TaskStatusUpdateEvent); !ok || !update.Final {
+				t.Fatalf("last event before settlement = %#v, want final completion", queue.events[eventsBeforeSettlement-1])
+			}
+
+			close(release)
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			if err := orchestrator.Shutdown(ctx); err != nil {
+				t.Fatalf("Shutdown() error = %v", err)
+			}
+
+			result := <-results
+			if result.taskID != task.ID || result.receipt == nil {
+				t.Fatalf("OnSettled got %#v", result)
+			}
+			if (result.err != nil) != (tt.settleErr != nil) {
+				t.Errorf("OnSettled error = %v, want error %v", result.err, tt.settleErr != nil)
+			}
+			if len(queue.events) != eventsBeforeSettlement+1 {
+				t.Fatalf("events = %d, want receipt event after completion", len(queue.events))
+			}
+			receiptEvent, ok := queue.events[eventsBeforeSettlement].(*a2a.TaskStatusUpdateEvent)
+			if !ok || receiptEvent.Final {
+				t.Fatalf("receipt event = %#v, want non-final status update", queue.events[eventsBeforeSettlement])
+			}
+			if status, _ := x402state.ExtractPaymentStatusFromMessage(receiptEvent.Status.Message); status != tt.wantStatus {
+				t.Errorf("receipt event status = %v, want %v", status, tt.wantStatus)
+			}
+			if _, ok := receiptEvent.Status.Message.Metadata[x402.MetadataKeyReceipts]; !ok {
+				t.Error("receipt event is missing receipts")
+			}
+		})
+	}
+}
@@ -0,0 +1,251 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// Facilitator operations named in audit entries.
+const (
+	AuditOperationVerify = "verify"
+	AuditOperationSettle = "settle"
+)
+
+// Outcomes of an audited facilitator call. A rejected call got an answer
+// that refused the payment; an errored call got no usable answer.
+const (
+	AuditOutcomeSuccess  = "success"
+	AuditOutcomeRejected = "rejected"
+	AuditOutcomeError    = "error"
+)
+
+// AuditEntry records one facilitator call. It carries a hash of the payment
+// payload rather than the payload, which holds the payer's signature.
+type AuditEntry struct {
+	Time        time.Time  `json:"time"`
+	Operation   string     `json:"operation"`
+	TaskID      a2a.TaskID `json:"taskId"`
+	Payer       string     `json:"payer,omitempty"`
+	Network     string     `json:"network"`
+	Asset       string     `json:"asset,omitempty"`
+	Amount      string     `json:"amount,omitempty"`
+	PayloadHash string     `json:"payloadHash"`
+	Outcome     string     `json:"outcome"`
+	// Reason is the facilitator's reason for a rejection, or the error.
+	Reason      string `json:"reason,omitempty"`
+	Transaction string `json:"transaction,omitempty"`
+	LatencyMs   int64  `json:"latencyMs"`
+}
+
+// AuditLogger receives an entry for every VerifyPayment and SettlePayment
+// call the orchestrator makes. Implementations must be safe for concurrent
+// use.
+type AuditLogger interface {
+	Log(ctx context.Context, entry *AuditEntry) error
+}
+
+// WithAuditLogger records every facilitator call in logger. By default a
+// failed audit write is logged and the payment proceeds. With failClosed a
+// verification that could not be audited fails the payment with INTERNAL, so
+// nothing settles without a verify entry; a settlement has already moved
+// funds by the time it is audited, so its audit failures are only logged.
+func WithAuditLogger(logger AuditLogger, failClosed bool) Option {
+	return func(o *BusinessOrchestrator) {
+		o.auditLogger = logger
+		o.auditFailClosed = failClosed
+	}
+}
+
+// auditError reports a verification refused because its audit entry could
+// not be written.
+type auditError struct {
+	err error
+}
+
+func (e *auditError) Error() string {
+	return fmt.Sprintf("facilitator call not audited: %v", e.err)
+}
+
+func (e *auditError) Unwrap() error {
+	return e.err
+}
+
+// newAuditEntry starts the entry for a call made at started with payload
+// against requirement.
+func newAuditEntry(operation string, taskID a2a.TaskID, started time.Time, payload *x402types.PaymentPayload, requirement *x402types.PaymentRequirements) *AuditEntry {
+	entry := &AuditEntry{
+		Time:      started,
+		Operation: operation,
+		TaskID:    taskID,
+		Network:   requirement.Network,
+		Asset:     requirement.Asset,
+		Amount:    requirement.Amount,
+	}
+	entry.PayloadHash, _ = state.PaymentPayloadHash(payload)
+	return entry
+}
+
+// auditVerify records a verification. It returns an auditError when the
+// entry was not written and audits fail closed.
+func (o *BusinessOrchestrator) auditVerify(ctx context.Context, entry *AuditEntry, response *x402core.VerifyResponse, err error, latency time.Duration) error {
+	switch {
+	case err != nil:
+		entry.Outcome, entry.Reason = AuditOutcomeError, err.Error()
+	case response == nil:
+		entry.Outcome, entry.Reason = AuditOutcomeError, "empty verification response"
+	case !response.IsValid:
+		entry.Outcome, entry.Reason = AuditOutcomeRejected, response.InvalidReason
+		entry.Payer = response.Payer
+	default:
+		entry.Outcome = AuditOutcomeSuccess
+		entry.Payer = response.Payer
+	}
+	entry.LatencyMs = latency.Milliseconds()
+	if auditErr := o.writeAudit(ctx, entry); auditErr != nil && o.auditFailClosed {
+		return &auditError{err: auditErr}
+	}
+	return nil
+}
+
+// auditSettle records a settlement. Funds may have moved, so a failed write
+// never changes the outcome.
+func (o *BusinessOrchestrator) auditSettle(ctx context.Context, entry *AuditEntry, payer string, response *x402core.SettleResponse, err error, latency time.Duration) {
+	entry.Payer = payer
+	switch {
+	case err != nil:
+		entry.Outcome, entry.Reason = AuditOutcomeError, err.Error()
+	case response == nil:
+		entry.Outcome, entry.Reason = AuditOutcomeError, "empty settlement response"
+	case !response.Success:
+		entry.Outcome, entry.Reason = AuditOutcomeRejected, response.ErrorReason
+	default:
+		entry.Outcome = AuditOutcomeSuccess
+	}
+	if response != nil {
+		entry.Transaction = response.Transaction
+		if response.Payer != "" {
+			entry.Payer = response.Payer
+		}
+		if response.Amount != "" {
+			entry.Amount = response.Amount
+		}
+	}
+	entry.LatencyMs = latency.Milliseconds()
+	o.writeAudit(ctx, entry)
+}
+
+func (o *BusinessOrchestrator) writeAudit(ctx context.Context, entry *AuditEntry) error {
+	err := o.auditLogger.Log(context.WithoutCancel(ctx), entry)
+	if err != nil {
+		o.logger.ErrorContext(ctx, "x402 facilitator call not audited",
+			"task_id", entry.TaskID,
+			"operation", entry.Operation,
+			"outcome", entry.Outcome,
+			"error", err,
+		)
+	}
+	return err
+}
+
+// FileAuditLogger appends audit entries to a file as JSON lines. Each entry
+// is written with a single append and synced before Log returns. The file is
+// reopened whenever its path no longer names the open file, so it can be
+// rotated by renaming it away.
+type FileAuditLogger struct {
+	path string
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+func NewFileAuditLogger(path string) (*FileAuditLogger, error) {
+	if path == "" {
+		return nil, fmt.Errorf("audit log path is required")
+	}
+	logger := &FileAuditLogger{path: path}
+	if err := logger.open(); err != nil {
+		return nil, err
+	}
+	return logger, nil
+}
+
+func (l *FileAuditLogger) Log(ctx context.Context, entry *AuditEntry) error {
+	if entry == nil {
+		return fmt.Errorf("audit entry is required")
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	data = append(data, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.reopenIfRotated(); err != nil {
+		return err
+	}
+	if _, err := l.file.Write(data); err != nil {
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+	if err := l.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync audit log: %w", err)
+	}
+	return nil
+}
+
+// Close closes the open file. Log reopens it if called again.
+func (l *FileAuditLogger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+func (l *FileAuditLogger) open() error {
+	file, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	l.file = file
+	return nil
+}
+
+func (l *FileAuditLogger) reopenIfRotated() error {
+	if l.file != nil {
+		current, pathErr := os.Stat(l.path)
+		open, fileErr := l.file.Stat()
+		if pathErr == nil && fileErr == nil && os.SameFile(current, open) {
+			return nil
+		}
+		l.file.Close()
+		l.file = nil
+	}
+	return l.open()
+}
@@ -0,0 +1,230 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// recordingAuditLogger keeps every entry and fails with err when it is set.
+type recordingAuditLogger struct {
+	mu      sync.Mutex
+	entries []AuditEntry
+	err     error
+}
+
+func (l *recordingAuditLogger) Log(ctx context.Context, entry *AuditEntry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, *entry)
+	return l.err
+}
+
+func (l *recordingAuditLogger) outcomes() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var outcomes []string
+	for _, entry := range l.entries {
+		outcomes = append(outcomes, entry.Operation+":"+entry.Outcome)
+	}
+	return outcomes
+}
+
+func newAuditedOrchestrator(t *testing.T, server *MockResourceServer, logger AuditLogger, failClosed bool) *BusinessOrchestrator {
+	return newTestOrchestrator(
+		t,
+		server,
+		&mockBusinessService{},
+		[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+		WithAuditLogger(logger, failClosed),
+	)
+}
+
+func TestAuditLogger_OneEntryPerCall(t *testing.T) {
+	tests := []struct {
+		name   string
+		server *MockResourceServer
+		want   []string
+		reason string
+	}{
+		{
+			name:   "verified and settled",
+			server: &MockResourceServer{},
+			want:   []string{"verify:success", "settle:success"},
+		},
+		{
+			name: "verification rejected",
+			server: &MockResourceServer{
+				VerifyPaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.VerifyResponse, error) {
+					return &x402core.VerifyResponse{IsValid: false, InvalidReason: "insufficient_funds", Payer: "0x789"}, nil
+				},
+			},
+			want:   []string{"verify:rejected"},
+			reason: "insufficient_funds",
+		},
+		{
+			name: "verification errored",
+			server: &MockResourceServer{
+				VerifyPaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.VerifyResponse, error) {
+					return nil, errors.New("facilitator unreachable")
+				},
+			},
+			want:   []string{"verify:error"},
+			reason: "facilitator unreachable",
+		},
+		{
+			name: "settlement rejected",
+			server: &MockResourceServer{
+				SettlePaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.SettleResponse, error) {
+					return &x402core.SettleResponse{Success: false, ErrorReason: "nonce_used", Network: x402.NetworkBaseSepolia}, nil
+				},
+			},
+			want:   []string{"verify:success", "settle:rejected"},
+			reason: "nonce_used",
+		},
+		{
+			name: "settlement errored",
+			server: &MockResourceServer{
+				SettlePaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.SettleResponse, error) {
+					return nil, errors.New("connection reset")
+				},
+			},
+			want:   []string{"verify:success", "settle:error"},
+			reason: "connection reset",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &recordingAuditLogger{}
+			orchestrator := newAuditedOrchestrator(t, tt.server, logger, false)
+			task := quoteTask(t, orchestrator)
+			payQuotedTask(t, orchestrator, task)
+
+			if got := logger.outcomes(); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("audit entries = %v, want %v", got, tt.want)
+			}
+			for _, entry := range logger.entries {
+				if entry.TaskID != task.ID || entry.Network != x402.NetworkBaseSepolia || entry.Asset != "0x456" || entry.Time.IsZero() {
+					t.Errorf("entry = %+v", entry)
+				}
+				if len(entry.PayloadHash) != 64 {
+					t.Errorf("payload hash = %q, want a sha256 hex digest", entry.PayloadHash)
+				}
+				if entry.Operation == AuditOperationVerify && entry.Payer != "0x789" && entry.Outcome != AuditOutcomeError {
+					t.Errorf("verify payer = %q, want 0x789", entry.Payer)
+				}
+			}
+			if last := logger.entries[len(logger.entries)-1]; tt.reason != "" && !strings.Contains(last.Reason, tt.reason) {
+				t.Errorf("reason = %q, want %q", last.Reason, tt.reason)
+			}
+		})
+	}
+}
+
+func TestAuditLogger_FailureModes(t *testing.T) {
+	tests := []struct {
+		name       string
+		failClosed bool
+		wantState  a2a.TaskState
+		wantCode   string
+		wantSettle bool
+	}{
+		{name: "fail open", wantState: a2a.TaskStateCompleted, wantSettle: true},
+		{name: "fail closed", failClosed: true, wantState: a2a.TaskStateFailed, wantCode: x402.ErrorCodeInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settled := false
+			logger := &recordingAuditLogger{err: errors.New("disk full")}
+			orchestrator := newAuditedOrchestrator(t, &MockResourceServer{
+				SettlePaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.SettleResponse, error) {
+					settled = true
+					return &x402core.SettleResponse{Success: true, Network: x402.NetworkBaseSepolia}, nil
+				},
+			}, logger, tt.failClosed)
+			task := quoteTask(t, orchestrator)
+			payQuotedTask(t, orchestrator, task)
+
+			if task.Status.State != tt.wantState {
+				t.Fatalf("state = %s, want %s", task.Status.State, tt.wantState)
+			}
+			if code, _ := task.Status.Message.Meta()[x402.MetadataKeyError].(string); code != tt.wantCode {
+				t.Errorf("error code = %q, want %q", code, tt.wantCode)
+			}
+			if settled != tt.wantSettle {
+				t.Errorf("settled = %v, want %v", settled, tt.wantSettle)
+			}
+		})
+	}
+}
+
+func TestFileAuditLogger_Rotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	logger, err := NewFileAuditLogger(path)
+	if err != nil {
+		t.Fatalf("NewFileAuditLogger() error = %v", err)
+	}
+	defer logger.Close()
+	ctx := context.Background()
+	log := func(taskID a2a.TaskID) {
+		t.Helper()
+		if err := logger.Log(ctx, &AuditEntry{TaskID: taskID, Operation: AuditOperationVerify, Outcome: AuditOutcomeSuccess}); err != nil {
+			t.Fatalf("Log() error = %v", err)
+		}
+	}
+
+	log("task-1")
+	log("task-2")
+	rotated := path + ".1"
+	if err := os.Rename(path, rotated); err != nil {
+		t.Fatalf("Rename() error = %v", err)
+	}
+	log("task-3")
+
+	for file, want := range map[string][]a2a.TaskID{rotated: {"task-1", "task-2"}, path: {"task-3"}} {
+		f, err := os.Open(file)
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		var got []a2a.TaskID
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var entry AuditEntry
+			if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+				t.Fatalf("line %q is not an audit entry: %v", scanner.Text(), err)
+			}
+			got = append(got, entry.TaskID)
+		}
+		f.Close()
+		if !slices.Equal(got, want) {
+			t.Errorf("%s holds %v, want %v", filepath.Base(file), got, want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+const defaultClockSkew = 5 * time.Second
+
+// authorizationWindowError reports a payload that is outside its signed
+// validity window, so the facilitator would reject it anyway.
+type authorizationWindowError struct {
+	message string
+	// expired distinguishes a lapsed window from one that has not opened.
+	expired bool
+}
+
+func (e *authorizationWindowError) Error() string {
+	return e.message
+}
+
+// checkAuthorizationWindow rejects payloads whose signed validity window does
+// not contain now, allowing skew in both directions. EIP-3009 payloads carry
+// validAfter/validBefore and Permit2 payloads carry witness.validAfter and
+// deadline. SVM payloads are bounded by their recent blockhash rather than a
+// timestamp, so they and any unrecognized payloads are left to the facilitator.
+func checkAuthorizationWindow(payload *x402types.PaymentPayload, now time.Time, skew time.Duration) error {
+	if payload == nil || payload.Payload == nil {
+		return nil
+	}
+
+	var validAfter, validBefore any
+	if authorization, ok := payload.Payload["authorization"].(map[string]interface{}); ok {
+		validAfter = authorization["validAfter"]
+		validBefore = authorization["validBefore"]
+	} else if permit, ok := payload.Payload["permit2Authorization"].(map[string]interface{}); ok {
+		if witness, ok := permit["witness"].(map[string]interface{}); ok {
+			validAfter = witness["validAfter"]
+		}
+		validBefore = permit["deadline"]
+	} else {
+		return nil
+	}
+
+	if after, ok, err := parseUnixSeconds(validAfter); err != nil {
+		return fmt.Errorf("invalid authorization validAfter: %w", err)
+	} else if ok && now.Add(skew).Before(after) {
+		return &authorizationWindowError{message: fmt.Sprintf("payment authorization is not valid until %s", after.UTC().Format(time.RFC3339))}
+	}
+	if before, ok, err := parseUnixSeconds(validBefore); err != nil {
+		return fmt.Errorf("invalid authorization validBefore: %w", err)
+	} else if ok && !now.Add(-skew).Before(before) {
+		return &authorizationWindowError{message: fmt.Sprintf("payment authorization expired at %s", before.UTC().Format(time.RFC3339)), expired: true}
+	}
+	return nil
+}
+
+func parseUnixSeconds(value any) (time.Time, bool, error) {
+	var seconds int64
+	switch v := value.(type) {
+	case nil:
+		return time.Time{}, false, nil
+	case string:
+		if v == "" {
+			return time.Time{}, false, nil
+		}
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		seconds = parsed
+	case float64:
+		seconds = int64(v)
+	default:
+		return time.Time{}, false, fmt.Errorf("unexpected type %T", value)
+	}
+	return time.Unix(seconds, 0), true, nil
+}
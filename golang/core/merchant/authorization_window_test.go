@@ -0,0 +1,158 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+func TestBusinessOrchestrator_AuthorizationWindow(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	unix := func(offset time.Duration) string {
+		return strconv.FormatInt(now.Add(offset).Unix(), 10)
+	}
+	eip3009 := func(validAfter, validBefore string) map[string]interface{} {
+		return map[string]interface{}{
+			"signature": "0xabc",
+			"authorization": map[string]interface{}{
+				"from":        "0x789",
+				"to":          "0x123",
+				"value":       "100",
+				"validAfter":  validAfter,
+				"validBefore": validBefore,
+				"nonce":       "0xdef",
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		payload       map[string]interface{}
+		wantVerify    bool
+		wantErrorCode string
+	}{
+		{
+			name:       "healthy window",
+			payload:    eip3009(unix(-time.Minute), unix(time.Minute)),
+			wantVerify: true,
+		},
+		{
+			name:          "expired",
+			payload:       eip3009(unix(-time.Hour), unix(-time.Minute)),
+			wantErrorCode: x402.ErrorCodeExpiredPayment,
+		},
+		{
+			name:          "not yet valid",
+			payload:       eip3009(unix(time.Minute), unix(time.Hour)),
+			wantErrorCode: x402.ErrorCodeExpiredPayment,
+		},
+		{
+			name:       "expiry within clock skew",
+			payload:    eip3009(unix(-time.Minute), unix(-2*time.Second)),
+			wantVerify: true,
+		},
+		{
+			name: "expired permit2 deadline",
+			payload: map[string]interface{}{
+				"signature": "0xabc",
+				"permit2Authorization": map[string]interface{}{
+					"deadline": unix(-time.Minute),
+					"witness":  map[string]interface{}{"to": "0x123", "validAfter": unix(-time.Hour)},
+				},
+			},
+			wantErrorCode: x402.ErrorCodeExpiredPayment,
+		},
+		{
+			name:       "svm transaction is left to the facilitator",
+			payload:    map[string]interface{}{"transaction": "AQID"},
+			wantVerify: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifyCalled := false
+			orchestrator := newTestOrchestrator(
+				t,
+				&MockResourceServer{
+					VerifyPaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.VerifyResponse, error) {
+						verifyCalled = true
+						return &x402core.VerifyResponse{IsValid: true, Payer: "0x789"}, nil
+					},
+				},
+				&mockBusinessService{},
+				[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+				WithClock(func() time.Time { return now }),
+				WithClockSkew(5*time.Second),
+				// Expired quotes are re-quoted by default; this test
+				// covers the classification on its own.
+				WithMaxRequotes(0),
+			)
+
+			requirements := x402types.PaymentRequirements{Scheme: "exact", Network: x402.NetworkBaseSepolia, PayTo: "0x123", Asset: "0x456"}
+			paymentState := &x402state.PaymentState{
+				Status: x402state.PaymentSubmitted,
+				Payload: &x402types.PaymentPayload{
+					X402Version: x402.X402Version,
+					Accepted:    requirements,
+					Payload:     tt.payload,
+				},
+				Requirements: &x402types.PaymentRequired{X402Version: x402.X402Version, Accepts: []x402types.PaymentRequirements{requirements}},
+			}
+			task := &a2a.Task{
+				ID:        "task-window",
+				ContextID: "context-window",
+				Status:    a2a.TaskStatus{State: a2a.TaskStateInputRequired},
+			}
+			requestContext := &a2asrv.RequestContext{
+				Message:    a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "pay"}),
+				StoredTask: task,
+				TaskID:     task.ID,
+				ContextID:  task.ContextID,
+			}
+
+			result, err := orchestrator.handlePaymentSubmitted(context.Background(), requestContext, task, &mockEventQueue{}, paymentState)
+			if err != nil {
+				t.Fatalf("handlePaymentSubmitted() error = %v", err)
+			}
+			if verifyCalled != tt.wantVerify {
+				t.Errorf("VerifyPayment called = %v, want %v", verifyCalled, tt.wantVerify)
+			}
+			if tt.wantErrorCode == "" {
+				if result.Status != x402state.PaymentVerified {
+					t.Errorf("payment status = %v, want %v", result.Status, x402state.PaymentVerified)
+				}
+				return
+			}
+			if result.Status != x402state.PaymentFailed {
+				t.Errorf("payment status = %v, want %v", result.Status, x402state.PaymentFailed)
+			}
+			if got := task.Status.Message.Metadata[x402.MetadataKeyError]; got != tt.wantErrorCode {
+				t.Errorf("error code = %v, want %s", got, tt.wantErrorCode)
+			}
+		})
+	}
+}
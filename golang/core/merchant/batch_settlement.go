@@ -0,0 +1,355 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/a2aproject/a2a-go/a2asrv/eventqueue"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// ReceiptExtraSettlementPending marks the placeholder receipt a task carries
+// while its payment waits in the batch settlement queue.
+const ReceiptExtraSettlementPending = "settlementPending"
+
+// BatchSettlementConfig configures batched settlement.
+type BatchSettlementConfig struct {
+	// MaxBatchSize settles the queue as soon as this many payments are
+	// pending. Defaults to 20.
+	MaxBatchSize int
+	// FlushInterval settles whatever is pending at least this often.
+	// Defaults to 30s.
+	FlushInterval time.Duration
+	// Tasks, when set, is the server's task store. Each settled payment's
+	// receipt replaces the placeholder on the completed task there.
+	Tasks a2asrv.TaskStore
+	// OnSettled is called once per payment after its batch settled, with the
+	// receipt and, on failure, the settlement error.
+	OnSettled func(ctx context.Context, taskID a2a.TaskID, receipt *x402core.SettleResponse, err error)
+}
+
+// WithBatchSettlement completes tasks as soon as the business logic succeeds
+// and queues their payments for settlement in batches, for micro-payments
+// that cost more to settle one by one than they are worth. Completed tasks
+// keep the payment-verified status and carry a placeholder receipt until
+// their batch settles.
+//
+// The queue is kept in the PaymentStateStore when it implements
+// PendingSettlementStore, and payments queued before a restart are settled
+// by the next orchestrator. Without such a store the queue is in memory and
+// lost if the process exits. Call Shutdown to settle what is still queued.
+// It takes precedence over asynchronous settlement.
+func WithBatchSettlement(config BatchSettlementConfig) Option {
+	return func(o *BusinessOrchestrator) {
+		if config.MaxBatchSize <= 0 {
+			config.MaxBatchSize = 20
+		}
+		if config.FlushInterval <= 0 {
+			config.FlushInterval = 30 * time.Second
+		}
+		o.batchSettlement = &batchSettler{
+			config: config,
+			full:   make(chan struct{}, 1),
+			stop:   make(chan struct{}),
+			done:   make(chan struct{}),
+		}
+	}
+}
+
+type batchSettler struct {
+	config BatchSettlementConfig
+	store  PendingSettlementStore
+
+	// full is signaled when the queue reaches MaxBatchSize.
+	full chan struct{}
+	stop chan struct{}
+	done chan struct{}
+
+	mu      sync.Mutex
+	closed  bool
+	pending []*PendingSettlement
+}
+
+// start recovers the queue from the store and starts the flush loop.
+func (s *batchSettler) start(o *BusinessOrchestrator) {
+	ctx := context.Background()
+	if store, ok := o.stateStore.(PendingSettlementStore); ok {
+		s.store = store
+	} else {
+		o.logger.WarnContext(ctx, "x402 batch settlement queue is not persisted: payment state store does not hold pending settlements")
+		s.store = NewMemoryPaymentStateStore()
+	}
+	recovered, err := s.store.PendingSettlements(ctx)
+	if err != nil {
+		o.logger.ErrorContext(ctx, "x402 batch settlement queue not recovered", "error", err)
+	}
+	s.pending = recovered
+	if len(s.pending) >= s.config.MaxBatchSize {
+		s.full <- struct{}{}
+	}
+
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(s.config.FlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+			case <-s.full:
+			case <-s.stop:
+				s.flush(o)
+				return
+			}
+			s.flush(o)
+		}
+	}()
+}
+
+// add queues a payment already saved to the store. It reports false once the
+// settler is shut down.
+func (s *batchSettler) add(pending *PendingSettlement) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	s.pending = append(s.pending, pending)
+	if len(s.pending) >= s.config.MaxBatchSize {
+		select {
+		case s.full <- struct{}{}:
+		default:
+		}
+	}
+	return true
+}
+
+// flush settles everything queued, a batch at a time.
+func (s *batchSettler) flush(o *BusinessOrchestrator) {
+	for {
+		s.mu.Lock()
+		n := min(len(s.pending), s.config.MaxBatchSize)
+		batch := s.pending[:n:n]
+		s.pending = s.pending[n:]
+		s.mu.Unlock()
+		if n == 0 {
+			return
+		}
+		for _, pending := range batch {
+			o.settlePending(pending)
+		}
+	}
+}
+
+func (s *batchSettler) shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.stop)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("pending batch settlements not drained: %w", ctx.Err())
+	}
+}
+
+// completeForBatch saves the payment to the settlement queue and completes
+// the task with a placeholder receipt. It reports false when the queue cannot
+// be saved and the caller must settle inline.
+func (o *BusinessOrchestrator) completeForBatch(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	eventQueue eventqueue.Queue,
+	paymentState *state.PaymentState,
+	matchedRequirement *x402types.PaymentRequirements,
+	businessResult *business.Result,
+) (bool, error) {
+	pending := &PendingSettlement{
+		TaskID:      task.ID,
+		ContextID:   task.ContextID,
+		Payer:       paymentState.Payer,
+		Payload:     paymentState.Payload,
+		Requirement: matchedRequirement,
+		QueuedAt:    o.now(),
+	}
+	if err := o.batchSettlement.store.SavePendingSettlement(ctx, pending); err != nil {
+		o.logger.WarnContext(ctx, "x402 batch settlement queue unavailable: settling inline",
+			"task_id", task.ID,
+			"context_id", task.ContextID,
+			"error", err,
+		)
+		return false, nil
+	}
+
+	placeholder := &x402core.SettleResponse{
+		Network: x402core.Network(matchedRequirement.Network),
+		Payer:   paymentState.Payer,
+		Amount:  matchedRequirement.Amount,
+		Extra:   map[string]interface{}{ReceiptExtraSettlementPending: true},
+	}
+	err := o.completeUnsettled(ctx, requestContext, task, eventQueue, businessResult, placeholder)
+	// The completion is written before the payment joins a batch, so the
+	// back-filled receipt always lands on the completed task. A payment that
+	// misses a shut-down settler stays in the store for the next one.
+	if !o.batchSettlement.add(pending) {
+		o.logger.WarnContext(ctx, "x402 batch settlement shut down: payment left queued in the store",
+			"task_id", task.ID,
+			"context_id", task.ContextID,
+		)
+	}
+	return true, err
+}
+
+// settlePending settles one queued payment, back-fills its receipt and
+// removes it from the queue.
+func (o *BusinessOrchestrator) settlePending(pending *PendingSettlement) {
+	ctx := context.Background()
+	requestContext := &a2asrv.RequestContext{TaskID: pending.TaskID, ContextID: pending.ContextID}
+	paymentState := &state.PaymentState{
+		Status:  state.PaymentVerified,
+		Payload: pending.Payload,
+		Payer:   pending.Payer,
+	}
+	receipt, err := o.settleWithRetry(ctx, requestContext, nil, paymentState, pending.Requirement)
+
+	var code string
+	if err != nil {
+		receipt = normalizeFailureReceipt(paymentState, receipt, err)
+		code = settlementErrorCode(receipt, err)
+	}
+	task, backfillErr := o.backfillReceipt(ctx, o.batchSettlement.config.Tasks, pending.TaskID, receipt, code, err)
+	if backfillErr != nil {
+		o.logger.ErrorContext(ctx, "x402 settlement receipt not back-filled",
+			"task_id", pending.TaskID,
+			"context_id", pending.ContextID,
+			"error", backfillErr,
+		)
+	}
+	if task == nil {
+		task = &a2a.Task{
+			ID:        pending.TaskID,
+			ContextID: pending.ContextID,
+			Status:    a2a.TaskStatus{State: a2a.TaskStateCompleted},
+		}
+	}
+
+	if err != nil {
+		o.logFailed(ctx, task, code, err)
+		o.hooks.failed(ctx, task, code, err)
+		o.notifyWebhook(ctx, WebhookPaymentFailed, task, []*x402core.SettleResponse{receipt}, code, err)
+	} else {
+		o.logSettled(ctx, task, receipt)
+		o.hooks.settled(ctx, task, receipt)
+		o.recordReceipt(ctx, task, pending.Payer, pending.Requirement, receipt)
+		o.notifyWebhook(ctx, WebhookPaymentSettled, task, []*x402core.SettleResponse{receipt}, "", nil)
+	}
+	if o.batchSettlement.config.OnSettled != nil {
+		o.batchSettlement.config.OnSettled(ctx, pending.TaskID, receipt, err)
+	}
+
+	if deleteErr := o.batchSettlement.store.DeletePendingSettlement(ctx, pending.TaskID); deleteErr != nil {
+		o.logger.ErrorContext(ctx, "x402 settled payment left in batch queue",
+			"task_id", pending.TaskID,
+			"context_id", pending.ContextID,
+			"error", deleteErr,
+		)
+	}
+}
+
+// placeholderReceipt reports whether receipt stands in for a payment that is
+// queued for batch settlement or left to an external process.
+func placeholderReceipt(receipt *x402core.SettleResponse) bool {
+	if receipt == nil {
+		return false
+	}
+	queued, _ := receipt.Extra[ReceiptExtraSettlementPending].(bool)
+	external, _ := receipt.Extra[ReceiptExtraSettlement].(string)
+	return queued || external == SettlementExternalPending
+}
+
+// backfillReceipt replaces the placeholder receipt on the task in tasks with
+// the settlement outcome. It returns the updated task, or nil when tasks is
+// nil, and fails when the task has no placeholder left to replace.
+func (o *BusinessOrchestrator) backfillReceipt(
+	ctx context.Context,
+	tasks a2asrv.TaskStore,
+	taskID a2a.TaskID,
+	receipt *x402core.SettleResponse,
+	code string,
+	settleErr error,
+) (*a2a.Task, error) {
+	if tasks == nil {
+		return nil, nil
+	}
+	task, version, err := tasks.Get(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load task: %w", err)
+	}
+	receipts, err := state.ExtractPaymentReceipts(task)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read payment receipts: %w", err)
+	}
+	var settled []*x402core.SettleResponse
+	for _, existing := range receipts {
+		if !placeholderReceipt(existing) {
+			settled = append(settled, existing)
+		}
+	}
+	if len(settled) == len(receipts) {
+		return nil, fmt.Errorf("task has no payment awaiting settlement")
+	}
+	settled = append(settled, receipt)
+
+	message := snapshotMessage(task.Status.Message)
+	if message == nil {
+		message = a2a.NewMessage(a2a.MessageRoleAgent)
+	}
+	if message.Metadata != nil {
+		delete(message.Metadata, x402pkg.MetadataKeyReceipts)
+	}
+	if err := state.SetPaymentReceipts(message, settled); err != nil {
+		return nil, fmt.Errorf("failed to record settlement receipt: %w", err)
+	}
+	if settleErr != nil {
+		state.SetPaymentStatus(message, state.PaymentFailed)
+		state.SetPaymentError(message, code)
+		if code == x402pkg.ErrorCodeSettleTimeout {
+			state.SetPaymentIndeterminate(message)
+		}
+	} else {
+		state.SetPaymentStatus(message, state.PaymentCompleted)
+	}
+	task.Status.Message = message
+
+	event := a2a.NewStatusUpdateEvent(task, task.Status.State, message)
+	if _, err := tasks.Save(ctx, task, event, version); err != nil {
+		return nil, fmt.Errorf("failed to save task: %w", err)
+	}
+	return task, nil
+}
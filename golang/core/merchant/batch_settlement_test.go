@@ -0,0 +1,230 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// memoryTaskStore is an in-memory a2a task store. List filters by status and
+// returns every match on one page.
+type memoryTaskStore struct {
+	mu    sync.Mutex
+	tasks map[a2a.TaskID]*a2a.Task
+}
+
+func (s *memoryTaskStore) Save(ctx context.Context, task *a2a.Task, event a2a.Event, prev a2a.TaskVersion) (a2a.TaskVersion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tasks == nil {
+		s.tasks = make(map[a2a.TaskID]*a2a.Task)
+	}
+	stored := *task
+	stored.Status.Message = snapshotMessage(task.Status.Message)
+	s.tasks[task.ID] = &stored
+	return prev + 1, nil
+}
+
+func (s *memoryTaskStore) Get(ctx context.Context, taskID a2a.TaskID) (*a2a.Task, a2a.TaskVersion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	task, ok := s.tasks[taskID]
+	if !ok {
+		return nil, 0, a2a.ErrTaskNotFound
+	}
+	copy := *task
+	copy.Status.Message = snapshotMessage(task.Status.Message)
+	return &copy, 1, nil
+}
+
+func (s *memoryTaskStore) List(ctx context.Context, req *a2a.ListTasksRequest) (*a2a.ListTasksResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	response := &a2a.ListTasksResponse{}
+	for _, task := range s.tasks {
+		if req.Status != "" && task.Status.State != req.Status {
+			continue
+		}
+		copy := *task
+		copy.Status.Message = snapshotMessage(task.Status.Message)
+		response.Tasks = append(response.Tasks, &copy)
+	}
+	return response, nil
+}
+
+// batchHarness records settlements and reports each settled task.
+type batchHarness struct {
+	mu      sync.Mutex
+	settled []string
+	done    chan a2a.TaskID
+}
+
+func newBatchHarness() *batchHarness {
+	return &batchHarness{done: make(chan a2a.TaskID, 16)}
+}
+
+func (h *batchHarness) server() *MockResourceServer {
+	return &MockResourceServer{
+		SettlePaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.SettleResponse, error) {
+			signature, _ := payload.Payload["signature"].(string)
+			h.mu.Lock()
+			h.settled = append(h.settled, signature)
+			h.mu.Unlock()
+			return &x402core.SettleResponse{Success: true, Transaction: "tx-" + signature, Network: x402core.Network(requirements.Network), Payer: "0x789"}, nil
+		},
+	}
+}
+
+func (h *batchHarness) settledCount() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.settled)
+}
+
+func (h *batchHarness) onSettled(ctx context.Context, taskID a2a.TaskID, receipt *x402core.SettleResponse, err error) {
+	h.done <- taskID
+}
+
+func (h *batchHarness) orchestrator(t *testing.T, config BatchSettlementConfig, opts ...Option) *BusinessOrchestrator {
+	config.OnSettled = h.onSettled
+	opts = append(opts, WithBatchSettlement(config))
+	return newTestOrchestrator(
+		t,
+		h.server(),
+		&mockBusinessService{},
+		[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+		opts...,
+	)
+}
+
+func TestBatchSettlement_CompletesWithPlaceholderUntilThreshold(t *testing.T) {
+	harness := newBatchHarness()
+	orchestrator := harness.orchestrator(t, BatchSettlementConfig{MaxBatchSize: 2, FlushInterval: time.Hour})
+	defer shutdown(t, orchestrator)
+
+	first := payTask(t, orchestrator, "task-batch-1")
+
+	if first.Status.State != a2a.TaskStateCompleted {
+		t.Fatalf("task state = %s, want completed before settlement", first.Status.State)
+	}
+	if status, _ := x402state.ExtractPaymentStatus(first); status != x402state.PaymentVerified {
+		t.Errorf("payment status = %q, want %q", status, x402state.PaymentVerified)
+	}
+	receipts, err := x402state.ExtractPaymentReceipts(first)
+	if err != nil || len(receipts) != 1 || receipts[0].Extra[ReceiptExtraSettlementPending] != true {
+		t.Fatalf("receipts = %+v, %v, want one settlement-pending placeholder", receipts, err)
+	}
+	if got := harness.settledCount(); got != 0 {
+		t.Fatalf("settlements after one payment = %d, want 0 below the batch size", got)
+	}
+
+	payTask(t, orchestrator, "task-batch-2")
+
+	settled := map[a2a.TaskID]bool{waitFinished(t, harness.done): true, waitFinished(t, harness.done): true}
+	if !settled["task-batch-1"] || !settled["task-batch-2"] {
+		t.Errorf("settled tasks = %v, want both batch tasks", settled)
+	}
+}
+
+func TestBatchSettlement_FlushesOnInterval(t *testing.T) {
+	harness := newBatchHarness()
+	orchestrator := harness.orchestrator(t, BatchSettlementConfig{MaxBatchSize: 100, FlushInterval: 10 * time.Millisecond})
+	defer shutdown(t, orchestrator)
+
+	payTask(t, orchestrator, "task-batch-timer")
+
+	if got := waitFinished(t, harness.done); got != "task-batch-timer" {
+		t.Errorf("settled task = %s, want task-batch-timer", got)
+	}
+}
+
+func TestBatchSettlement_BackfillsReceipt(t *testing.T) {
+	harness := newBatchHarness()
+	tasks := &memoryTaskStore{}
+	orchestrator := harness.orchestrator(t, BatchSettlementConfig{MaxBatchSize: 10, FlushInterval: time.Hour, Tasks: tasks})
+
+	// The a2a server saves the completed task before the batch settles.
+	task := payTask(t, orchestrator, "task-backfill")
+	if _, err := tasks.Save(context.Background(), task, nil, 0); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	shutdown(t, orchestrator)
+
+	stored, _, err := tasks.Get(context.Background(), "task-backfill")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if status, _ := x402state.ExtractPaymentStatus(stored); status != x402state.PaymentCompleted {
+		t.Errorf("payment status = %q, want %q", status, x402state.PaymentCompleted)
+	}
+	receipts, err := x402state.ExtractPaymentReceipts(stored)
+	if err != nil || len(receipts) != 1 {
+		t.Fatalf("receipts = %+v, %v, want the real receipt only", receipts, err)
+	}
+	if !receipts[0].Success || receipts[0].Transaction != "tx-0xtask-backfill" || receipts[0].Extra[ReceiptExtraSettlementPending] != nil {
+		t.Errorf("receipt = %+v, want settled tx-0xtask-backfill without the placeholder", receipts[0])
+	}
+}
+
+func TestBatchSettlement_RecoversQueueFromStore(t *testing.T) {
+	store := NewMemoryPaymentStateStore()
+	harness := newBatchHarness()
+
+	// The first merchant's settler is gone by the time the payment is
+	// queued, as after a crash, so only the store holds it.
+	crashed := harness.orchestrator(t, BatchSettlementConfig{MaxBatchSize: 10, FlushInterval: time.Hour}, WithPaymentStateStore(store))
+	if err := crashed.batchSettlement.shutdown(context.Background()); err != nil {
+		t.Fatalf("batch settler shutdown() error = %v", err)
+	}
+	payTask(t, crashed, "task-recovered")
+	if pending, _ := store.PendingSettlements(context.Background()); len(pending) != 1 {
+		t.Fatalf("pending settlements = %d, want 1 in the store", len(pending))
+	}
+	if got := harness.settledCount(); got != 0 {
+		t.Fatalf("settlements = %d, want 0 before recovery", got)
+	}
+
+	restarted := harness.orchestrator(t, BatchSettlementConfig{MaxBatchSize: 1, FlushInterval: time.Hour}, WithPaymentStateStore(store))
+	defer shutdown(t, restarted)
+
+	if got := waitFinished(t, harness.done); got != "task-recovered" {
+		t.Errorf("settled task = %s, want task-recovered", got)
+	}
+	if pending, _ := store.PendingSettlements(context.Background()); len(pending) != 0 {
+		t.Errorf("pending settlements = %d after settlement, want 0", len(pending))
+	}
+}
+
+func TestBatchSettlement_ShutdownSettlesQueue(t *testing.T) {
+	harness := newBatchHarness()
+	orchestrator := harness.orchestrator(t, BatchSettlementConfig{MaxBatchSize: 10, FlushInterval: time.Hour})
+
+	payTask(t, orchestrator, "task-drained")
+	shutdown(t, orchestrator)
+
+	if got := harness.settledCount(); got != 1 {
+		t.Errorf("settlements after Shutdown = %d, want 1", got)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+)
+
+// DefaultSettlementBuffer is the part of a requirement's MaxTimeoutSeconds
+// held back from paid business execution so that settlement can still run
+// inside the authorization window.
+const DefaultSettlementBuffer = 10 * time.Second
+
+// WithSettlementBuffer changes how much of the payment window is reserved for
+// settlement under ExecuteThenSettle. A negative buffer disables the business
+// execution deadline entirely.
+func WithSettlementBuffer(buffer time.Duration) Option {
+	return func(o *BusinessOrchestrator) {
+		o.settlementBuffer = buffer
+	}
+}
+
+// businessTimeoutError reports a paid business execution that outlived the
+// payment window. It matches context.DeadlineExceeded.
+type businessTimeoutError struct {
+	timeout time.Duration
+}
+
+func (e *businessTimeoutError) Error() string {
+	return fmt.Sprintf("business execution timed out after %s", e.timeout)
+}
+
+func (e *businessTimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// businessTimeout derives the paid execution budget from the matched
+// requirement. When settlement still has to follow, the settlement buffer is
+// subtracted; a window too short for the buffer is split in half instead.
+// Zero means no deadline.
+func (o *BusinessOrchestrator) businessTimeout(request business.Request) time.Duration {
+	if o.settlementBuffer < 0 || request.Requirements == nil || request.Requirements.MaxTimeoutSeconds <= 0 {
+		return 0
+	}
+	window := time.Duration(request.Requirements.MaxTimeoutSeconds) * time.Second
+	if o.settlementPolicy == SettleThenExecute {
+		return window
+	}
+	if budget := window - o.settlementBuffer; budget > 0 {
+		return budget
+	}
+	return window / 2
+}
+
+// runWithBusinessTimeout runs execute under the paid execution budget. A
+// service that ignores its context is abandoned when the budget runs out, so
+// it cannot hold the task and its verified payment open.
+func runWithBusinessTimeout(
+	ctx context.Context,
+	timeout time.Duration,
+	execute func(ctx context.Context) (*business.Result, error),
+) (*business.Result, error) {
+	if timeout <= 0 {
+		return execute(ctx)
+	}
+	execCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type outcome struct {
+		result *business.Result
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := execute(execCtx)
+		done <- outcome{result: result, err: err}
+	}()
+
+	select {
+	case out := <-done:
+		if out.err != nil && ctx.Err() == nil && execCtx.Err() != nil {
+			return nil, &businessTimeoutError{timeout: timeout}
+		}
+		return out.result, out.err
+	case <-execCtx.Done():
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return nil, &businessTimeoutError{timeout: timeout}
+	}
+}
+
+// businessErrorCode classifies a failed paid execution.
+func businessErrorCode(err error) string {
+	var timeoutErr *businessTimeoutError
+	if errors.As(err, &timeoutErr) {
+		return x402pkg.ErrorCodeBusinessTimeout
+	}
+	if errors.Is(err, errCapacityExceeded) {
+		return x402pkg.ErrorCodeCapacityExceeded
+	}
+	return x402pkg.ErrorCodeBusinessExecutionFailed
+}
@@ -0,0 +1,163 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+func TestBusinessOrchestrator_businessTimeout(t *testing.T) {
+	requirements := func(seconds int) *x402types.PaymentRequirements {
+		return &x402types.PaymentRequirements{MaxTimeoutSeconds: seconds}
+	}
+	tests := []struct {
+		name    string
+		policy  SettlementPolicy
+		buffer  time.Duration
+		request business.Request
+		want    time.Duration
+	}{
+		{name: "buffer held back for settlement", policy: ExecuteThenSettle, buffer: 10 * time.Second, request: business.Request{Requirements: requirements(60)}, want: 50 * time.Second},
+		{name: "settled payments get the whole window", policy: SettleThenExecute, buffer: 10 * time.Second, request: business.Request{Requirements: requirements(60)}, want: 60 * time.Second},
+		{name: "short window is split", policy: ExecuteThenSettle, buffer: 10 * time.Second, request: business.Request{Requirements: requirements(4)}, want: 2 * time.Second},
+		{name: "no window", policy: ExecuteThenSettle, buffer: 10 * time.Second, request: business.Request{Requirements: requirements(0)}},
+		{name: "free request", policy: ExecuteThenSettle, buffer: 10 * time.Second, request: business.Request{Free: true}},
+		{name: "disabled", policy: ExecuteThenSettle, buffer: -1, request: business.Request{Requirements: requirements(60)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &BusinessOrchestrator{settlementPolicy: tt.policy, settlementBuffer: tt.buffer}
+			if got := o.businessTimeout(tt.request); got != tt.want {
+				t.Errorf("businessTimeout() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBusinessOrchestrator_Execute_SlowBusinessTimesOut(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      SettlementPolicy
+		wantSettled bool
+	}{
+		{name: "execute then settle never settles", policy: ExecuteThenSettle},
+		{name: "settle then execute keeps the receipt", policy: SettleThenExecute, wantSettled: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release := make(chan struct{})
+			t.Cleanup(func() { close(release) })
+			settleCalls := 0
+			orchestrator := newTestOrchestrator(
+				t,
+				&MockResourceServer{
+					SettlePaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.SettleResponse, error) {
+						settleCalls++
+						return &x402core.SettleResponse{Success: true, Network: x402.NetworkBaseSepolia, Transaction: "0xtx"}, nil
+					},
+				},
+				// The service ignores its context, as a hung dependency would.
+				&mockBusinessService{executeFunc: func(ctx context.Context, request business.Request) (*business.Result, error) {
+					<-release
+					return &business.Result{Message: "too late"}, nil
+				}},
+				[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+				WithSettlementPolicy(tt.policy),
+				WithSettlementBuffer(900*time.Millisecond),
+			)
+
+			requirements := x402types.PaymentRequirements{
+				Scheme:            "exact",
+				Network:           x402.NetworkBaseSepolia,
+				PayTo:             "0x123",
+				Asset:             "0x456",
+				MaxTimeoutSeconds: 1,
+			}
+			task := &a2a.Task{
+				ID:        "task-slow",
+				ContextID: "context-slow",
+				Status:    a2a.TaskStatus{State: a2a.TaskStateWorking, Message: a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{Text: ""})},
+			}
+			x402state.SetPaymentStatus(task.Status.Message, x402state.PaymentVerified)
+			x402state.SetPaymentPayload(task.Status.Message, &x402types.PaymentPayload{X402Version: x402.X402Version, Accepted: requirements})
+			x402state.SetPaymentRequirements(task.Status.Message, &x402types.PaymentRequired{
+				X402Version: x402.X402Version,
+				Accepts:     []x402types.PaymentRequirements{requirements},
+			})
+			x402state.SetOriginalPrompt(task.Status.Message, "test prompt")
+			requestContext := &a2asrv.RequestContext{
+				Message:    a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "continue"}),
+				StoredTask: task,
+				TaskID:     task.ID,
+				ContextID:  task.ContextID,
+			}
+
+			done := make(chan error, 1)
+			go func() { done <- orchestrator.executeInPlace(context.Background(), requestContext, &mockEventQueue{}) }()
+			select {
+			case err := <-done:
+				if err != nil {
+					t.Fatalf("Execute() error = %v", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("Execute() did not return after the business deadline")
+			}
+
+			if task.Status.State != a2a.TaskStateFailed {
+				t.Fatalf("task state = %v, want failed", task.Status.State)
+			}
+			if got := task.Status.Message.Metadata[x402.MetadataKeyError]; got != x402.ErrorCodeBusinessTimeout {
+				t.Errorf("error code = %v, want %s", got, x402.ErrorCodeBusinessTimeout)
+			}
+			if settled := settleCalls > 0; settled != tt.wantSettled {
+				t.Errorf("settled = %v, want %v", settled, tt.wantSettled)
+			}
+			receipts, err := x402state.ExtractPaymentReceipts(task)
+			if err != nil || len(receipts) != 1 {
+				t.Fatalf("receipts = %#v, error = %v", receipts, err)
+			}
+			if receipts[0].Success != tt.wantSettled {
+				t.Errorf("receipt success = %v, want %v", receipts[0].Success, tt.wantSettled)
+			}
+			if tt.wantSettled && receipts[0].Transaction != "0xtx" {
+				t.Errorf("receipt transaction = %q, want the recorded settlement", receipts[0].Transaction)
+			}
+		})
+	}
+}
+
+func TestRunWithBusinessTimeout_ContextAwareService(t *testing.T) {
+	_, err := runWithBusinessTimeout(context.Background(), 10*time.Millisecond, func(ctx context.Context) (*business.Result, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	var timeoutErr *businessTimeoutError
+	if !errors.As(err, &timeoutErr) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("runWithBusinessTimeout() error = %v, want a business timeout", err)
+	}
+}
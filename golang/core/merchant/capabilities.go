@@ -0,0 +1,106 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402 "github.com/x402-foundation/x402/go"
+)
+
+// Capabilities describes the networks, schemes and assets the orchestrator
+// quotes in. It is derived from the same network configs, default assets and
+// asset allowlists quotes are built from, so an asset listed here is one a
+// quote can name.
+func (o *BusinessOrchestrator) Capabilities() (*types.Capabilities, error) {
+	capabilities := &types.Capabilities{
+		X402Version:  x402pkg.X402Version,
+		ExtensionURI: x402pkg.X402ExtensionURI,
+		Sandbox:      o.sandbox,
+		Networks:     make([]types.NetworkCapability, 0, len(o.networkConfigs)),
+	}
+	if wrapper, ok := o.merchant.(*resourceServerWrapper); ok && !o.sandbox {
+		capabilities.Facilitator = wrapper.facilitatorURL
+	}
+
+	for _, networkConfig := range o.networkConfigs {
+		assets, err := pricedAssets(networkConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve assets for network %s: %w", networkConfig.NetworkName, err)
+		}
+		network := types.NetworkCapability{
+			Network: networkConfig.NetworkName,
+			PayTo:   networkConfig.PayToAddress,
+			Schemes: o.quotedSchemes(networkConfig.NetworkName),
+			Assets:  make([]types.AssetCapability, 0, len(assets)),
+		}
+		for _, asset := range assets {
+			if len(networkConfig.AllowedAssets) > 0 && !containsAddress(networkConfig.AllowedAssets, asset.Address) {
+				continue
+			}
+			network.Assets = append(network.Assets, types.AssetCapability{
+				Address:  asset.Address,
+				Decimals: asset.Decimals,
+				Name:     asset.Name,
+				Price:    asset.Price,
+			})
+		}
+		capabilities.Networks = append(capabilities.Networks, network)
+	}
+	return capabilities, nil
+}
+
+// quotedSchemes lists the schemes the orchestrator can quote on network. A
+// resource server is asked which it registered; a payment server supplied
+// with WithPaymentServer is assumed to handle every scheme validateScheme
+// allows.
+func (o *BusinessOrchestrator) quotedSchemes(network string) []string {
+	wrapper, _ := o.merchant.(*resourceServerWrapper)
+	var schemes []string
+	for _, scheme := range []string{x402pkg.SchemeExact, x402pkg.SchemeUpto} {
+		if validateScheme(scheme, network) != nil {
+			continue
+		}
+		if wrapper != nil && wrapper.server != nil && !wrapper.server.HasRegisteredScheme(x402.Network(network), scheme) {
+			continue
+		}
+		schemes = append(schemes, scheme)
+	}
+	return schemes
+}
+
+// capabilitiesHandler serves the orchestrator's capabilities as JSON. They
+// are rebuilt on every request so they never drift from the quotes.
+func capabilitiesHandler(o *BusinessOrchestrator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		capabilities, err := o.Capabilities()
+		if err != nil {
+			o.logger.ErrorContext(r.Context(), "x402 capabilities unavailable", "error", err)
+			http.Error(w, "capabilities unavailable", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(capabilities)
+	})
+}
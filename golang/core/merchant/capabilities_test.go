@@ -0,0 +1,125 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+func TestCapabilitiesMatchQuote(t *testing.T) {
+	const usdcBase = "0x833589fCD6eDb6E08f4c3C32D4f71b54bdA02913"
+	ctx := context.Background()
+	configs := []types.NetworkConfig{
+		{NetworkName: x402.NetworkBaseSepolia, PayToAddress: evmPayTo},
+		{
+			NetworkName:  x402.NetworkBase,
+			PayToAddress: evmPayTo,
+			Assets: []types.AssetConfig{
+				{Address: usdcBase, Decimals: 6, Name: "USD Coin", Version: "2"},
+				{Address: "0x000000000000000000000000000000000000dEaD", Decimals: 18, Name: "Unlisted", Version: "1"},
+			},
+			AllowedAssets: []string{strings.ToLower(usdcBase)},
+		},
+	}
+	m, err := NewMerchant(ctx, "https://facilitator.invalid", &mockBusinessService{}, configs, WithSandboxMode())
+	if err != nil {
+		t.Fatalf("NewMerchant() error = %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	NewHTTPHandler(m, WithHandlerCapabilities()).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, x402.CapabilitiesPath, nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("GET %s status = %d, want 200", x402.CapabilitiesPath, recorder.Code)
+	}
+	var capabilities types.Capabilities
+	if err := json.Unmarshal(recorder.Body.Bytes(), &capabilities); err != nil {
+		t.Fatalf("decode capabilities: %v", err)
+	}
+	if capabilities.X402Version != x402.X402Version || !capabilities.Sandbox || capabilities.Facilitator != "" {
+		t.Errorf("capabilities = %+v, want x402 v%d in sandbox without a facilitator", capabilities, x402.X402Version)
+	}
+
+	orchestrator := m.orchestrator
+	orchestrator.extensionChecker = newMockExtensionCheckerWithX402()
+	task := quoteRequest(t, orchestrator, "task-capabilities", a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "buy"}))
+	quote, err := x402state.ExtractPaymentRequirements(task)
+	if err != nil || quote == nil {
+		t.Fatalf("ExtractPaymentRequirements() = %v, %v", quote, err)
+	}
+
+	// Every quoted requirement is covered by the capabilities...
+	for _, req := range quote.Accepts {
+		network := findNetworkCapability(capabilities, req.Network)
+		if network == nil {
+			t.Errorf("quoted network %s missing from capabilities", req.Network)
+			continue
+		}
+		if network.PayTo != req.PayTo || !slices.Contains(network.Schemes, req.Scheme) || !hasAssetCapability(network, req.Asset) {
+			t.Errorf("quoted requirement %s/%s/%s not covered by %+v", req.Network, req.Scheme, req.Asset, network)
+		}
+	}
+	// ...and every advertised asset is one a quote names.
+	for _, network := range capabilities.Networks {
+		for _, asset := range network.Assets {
+			quoted := slices.ContainsFunc(quote.Accepts, func(req x402types.PaymentRequirements) bool {
+				return req.Network == network.Network && strings.EqualFold(req.Asset, asset.Address)
+			})
+			if !quoted {
+				t.Errorf("advertised asset %s on %s is not in the quote", asset.Address, network.Network)
+			}
+		}
+	}
+	if base := findNetworkCapability(capabilities, x402.NetworkBase); base == nil || len(base.Assets) != 1 {
+		t.Errorf("base capabilities = %+v, want only the allowed asset", base)
+	}
+}
+
+func TestCapabilitiesHandlerIsOptional(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	NewHTTPHandler(newHandlerMerchant(t)).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, x402.CapabilitiesPath, nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("GET %s without WithHandlerCapabilities status = %d, want 404", x402.CapabilitiesPath, recorder.Code)
+	}
+}
+
+func findNetworkCapability(capabilities types.Capabilities, network string) *types.NetworkCapability {
+	for i := range capabilities.Networks {
+		if capabilities.Networks[i].Network == network {
+			return &capabilities.Networks[i]
+		}
+	}
+	return nil
+}
+
+func hasAssetCapability(network *types.NetworkCapability, address string) bool {
+	for _, asset := range network.Assets {
+		if strings.EqualFold(asset.Address, address) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// SettlementFailure describes a payment that failed to settle after the
+// business logic already ran for it under ExecuteThenSettle, or a held
+// delivery the client disputed.
+type SettlementFailure struct {
+	Task      *a2a.Task
+	Payer     string
+	Payload   *x402types.PaymentPayload
+	ErrorCode string
+	Err       error
+}
+
+// CompensationHandler takes back what was delivered for a payment that then
+// failed to settle: revoking access tokens, expiring the asset, or queueing
+// the task for manual review. It also runs when a client disputes a delivery
+// held by WithEscrowPolicy. It is never called for failures that happen
+// before the business logic runs.
+type CompensationHandler interface {
+	Compensate(ctx context.Context, failure SettlementFailure) error
+}
+
+// CompensationHandlerFunc adapts a function to the CompensationHandler
+// interface.
+type CompensationHandlerFunc func(ctx context.Context, failure SettlementFailure) error
+
+func (f CompensationHandlerFunc) Compensate(ctx context.Context, failure SettlementFailure) error {
+	return f(ctx, failure)
+}
+
+// WithCompensationHandler calls handler when settlement fails after
+// execution. The failed task records whether compensation succeeded.
+func WithCompensationHandler(handler CompensationHandler) Option {
+	return func(o *BusinessOrchestrator) {
+		o.compensationHandler = handler
+	}
+}
+
+// compensate runs the compensation handler and returns the outcome to record,
+// or "" when no handler is configured. A panicking handler counts as failed.
+func (o *BusinessOrchestrator) compensate(
+	ctx context.Context,
+	task *a2a.Task,
+	paymentState *state.PaymentState,
+	errorCode string,
+	cause error,
+) (outcome string) {
+	if o.compensationHandler == nil {
+		return ""
+	}
+	failure := SettlementFailure{
+		Task:      task,
+		Payer:     paymentState.Payer,
+		Payload:   paymentState.Payload,
+		ErrorCode: errorCode,
+		Err:       cause,
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			o.logCompensationFailed(ctx, task, fmt.Errorf("compensation handler panicked: %v", r))
+			outcome = state.CompensationFailed
+		}
+	}()
+	if err := o.compensationHandler.Compensate(ctx, failure); err != nil {
+		o.logCompensationFailed(ctx, task, err)
+		return state.CompensationFailed
+	}
+	return state.CompensationSucceeded
+}
+
+func (o *BusinessOrchestrator) logCompensationFailed(ctx context.Context, task *a2a.Task, err error) {
+	o.logger.ErrorContext(ctx, "x402 compensation failed",
+		"task_id", task.ID,
+		"context_id", task.ContextID,
+		"error", err,
+	)
+}
@@ -0,0 +1,113 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+func newCompensationOrchestrator(t *testing.T, server *MockResourceServer, handler CompensationHandler) *BusinessOrchestrator {
+	return newTestOrchestrator(
+		t,
+		server,
+		&mockBusinessService{},
+		[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+		WithCompensationHandler(handler),
+	)
+}
+
+func refuseSettlement(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.SettleResponse, error) {
+	return &x402core.SettleResponse{Success: false, ErrorReason: "insufficient funds"}, nil
+}
+
+func TestBusinessOrchestrator_Execute_CompensatesSettlementFailureAfterExecution(t *testing.T) {
+	var failures []SettlementFailure
+	orchestrator := newCompensationOrchestrator(t, &MockResourceServer{SettlePaymentFunc: refuseSettlement},
+		CompensationHandlerFunc(func(ctx context.Context, failure SettlementFailure) error {
+			failures = append(failures, failure)
+			return nil
+		}))
+
+	task := payTask(t, orchestrator, "task-compensate")
+
+	if len(failures) != 1 {
+		t.Fatalf("compensation calls = %d, want 1", len(failures))
+	}
+	failure := failures[0]
+	if failure.Task == nil || failure.Task.ID != "task-compensate" {
+		t.Errorf("failure.Task = %+v, want task-compensate", failure.Task)
+	}
+	if failure.Payer != "0x789" {
+		t.Errorf("failure.Payer = %q, want 0x789", failure.Payer)
+	}
+	if failure.Payload == nil || failure.Payload.Payload["signature"] != "0xtask-compensate" {
+		t.Errorf("failure.Payload = %+v, want the submitted payload", failure.Payload)
+	}
+	if failure.ErrorCode != x402.ErrorCodeInsufficientFunds || failure.Err == nil {
+		t.Errorf("failure code/err = %q/%v, want %s with an error", failure.ErrorCode, failure.Err, x402.ErrorCodeInsufficientFunds)
+	}
+	if got := x402state.ExtractCompensation(task); got != x402state.CompensationSucceeded {
+		t.Errorf("ExtractCompensation() = %q, want %q", got, x402state.CompensationSucceeded)
+	}
+	if status, _ := x402state.ExtractPaymentStatus(task); status != x402state.PaymentFailed {
+		t.Errorf("payment status = %q, want %q", status, x402state.PaymentFailed)
+	}
+}
+
+func TestBusinessOrchestrator_Execute_RecordsFailedCompensation(t *testing.T) {
+	orchestrator := newCompensationOrchestrator(t, &MockResourceServer{SettlePaymentFunc: refuseSettlement},
+		CompensationHandlerFunc(func(ctx context.Context, failure SettlementFailure) error {
+			return errors.New("token service unavailable")
+		}))
+
+	task := payTask(t, orchestrator, "task-compensate-fails")
+
+	if got := x402state.ExtractCompensation(task); got != x402state.CompensationFailed {
+		t.Errorf("ExtractCompensation() = %q, want %q", got, x402state.CompensationFailed)
+	}
+}
+
+func TestBusinessOrchestrator_Execute_DoesNotCompensateVerificationFailure(t *testing.T) {
+	called := false
+	orchestrator := newCompensationOrchestrator(t, &MockResourceServer{
+		VerifyPaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.VerifyResponse, error) {
+			return &x402core.VerifyResponse{IsValid: false, InvalidReason: "invalid_exact_evm_payload_signature"}, nil
+		},
+		SettlePaymentFunc: refuseSettlement,
+	}, CompensationHandlerFunc(func(ctx context.Context, failure SettlementFailure) error {
+		called = true
+		return nil
+	}))
+
+	task := payTask(t, orchestrator, "task-verify-fails")
+
+	if called {
+		t.Error("compensation handler called for a verification failure")
+	}
+	if got := x402state.ExtractCompensation(task); got != "" {
+		t.Errorf("ExtractCompensation() = %q, want empty", got)
+	}
+	if status, _ := x402state.ExtractPaymentStatus(task); status != x402state.PaymentFailed {
+		t.Errorf("payment status = %q, want %q", status, x402state.PaymentFailed)
+	}
+}
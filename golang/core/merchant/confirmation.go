@@ -0,0 +1,260 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/a2aproject/a2a-go/a2asrv/eventqueue"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+)
+
+// Defaults for ConfirmationPolicy fields left at zero.
+const (
+	DefaultConfirmationPollInterval = 2 * time.Second
+	DefaultConfirmationTimeout      = 5 * time.Minute
+)
+
+// ConfirmationClient reports how many blocks confirm a settlement
+// transaction: zero while it is unknown or pending, one once it is mined.
+type ConfirmationClient interface {
+	Confirmations(ctx context.Context, network, transaction string) (uint64, error)
+}
+
+// ConfirmationPolicy makes a settled payment wait for a number of block
+// confirmations before its task completes. Networks without a minimum
+// complete as soon as the facilitator reports the settlement.
+type ConfirmationPolicy struct {
+	// MinConfirmations is the number of confirmations required per CAIP-2
+	// network.
+	MinConfirmations map[string]uint64
+	// RPCURLs are JSON-RPC endpoints of EVM networks, used to count
+	// confirmations when Client is nil.
+	RPCURLs map[string]string
+	// Client counts confirmations instead of the RPC endpoints.
+	Client ConfirmationClient
+	// PollInterval is the wait between two checks. Defaults to
+	// DefaultConfirmationPollInterval.
+	PollInterval time.Duration
+	// Timeout bounds the wait for one settlement. Defaults to
+	// DefaultConfirmationTimeout. A settlement still short of its
+	// confirmations then fails the task as indeterminate with
+	// ErrorCodeConfirmationTimeout, keeping the receipt for reconciliation.
+	Timeout time.Duration
+}
+
+// WithConfirmationPolicy makes settlements wait for block confirmations
+// before their task completes. It applies where the task waits for
+// settlement; asynchronous and batched settlements complete the task first
+// and are not held.
+func WithConfirmationPolicy(policy ConfirmationPolicy) Option {
+	return func(o *BusinessOrchestrator) {
+		o.confirmations = policy
+	}
+}
+
+// confirmationTimeoutError reports a settlement that did not reach its
+// required confirmations in time.
+type confirmationTimeoutError struct {
+	transaction string
+	got, want   uint64
+	timeout     time.Duration
+}
+
+func (e *confirmationTimeoutError) Error() string {
+	return fmt.Sprintf("settlement %s had %d of %d confirmations after %s", e.transaction, e.got, e.want, e.timeout)
+}
+
+func (p ConfirmationPolicy) required(network string) uint64 {
+	network = x402pkg.NormalizeNetwork(network)
+	for name, confirmations := range p.MinConfirmations {
+		if x402pkg.NormalizeNetwork(name) == network {
+			return confirmations
+		}
+	}
+	return 0
+}
+
+func (p ConfirmationPolicy) pollInterval() time.Duration {
+	if p.PollInterval <= 0 {
+		return DefaultConfirmationPollInterval
+	}
+	return p.PollInterval
+}
+
+func (p ConfirmationPolicy) timeout() time.Duration {
+	if p.Timeout <= 0 {
+		return DefaultConfirmationTimeout
+	}
+	return p.Timeout
+}
+
+// awaitConfirmations waits until the settlement has the confirmations its
+// network requires, writing a working event each time the count moves. It
+// returns a confirmationTimeoutError when the policy's timeout passes first.
+func (o *BusinessOrchestrator) awaitConfirmations(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	eventQueue eventqueue.Queue,
+	receipt *x402core.SettleResponse,
+) error {
+	policy := o.confirmations
+	want := policy.required(string(receipt.Network))
+	if want == 0 {
+		return nil
+	}
+	client := policy.Client
+	if client == nil {
+		client = o.rpcConfirmations()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, policy.timeout())
+	defer cancel()
+	var got uint64
+	reported := false
+	for {
+		confirmations, err := client.Confirmations(ctx, string(receipt.Network), receipt.Transaction)
+		if err != nil {
+			// A flaky RPC endpoint is retried until the timeout.
+			o.logger.WarnContext(ctx, "x402 confirmation check failed",
+				"task_id", task.ID,
+				"context_id", task.ContextID,
+				"transaction", receipt.Transaction,
+				"error", err,
+			)
+		} else if confirmations >= want {
+			return nil
+		} else if confirmations != got || !reported {
+			got, reported = confirmations, true
+			message := a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{
+				Text: fmt.Sprintf("Payment settled; awaiting confirmations %d/%d", got, want),
+			})
+			state.SetPaymentStatus(message, state.PaymentVerified)
+			if err := state.SetPaymentReceipts(message, []*x402core.SettleResponse{receipt}); err != nil {
+				return fmt.Errorf("failed to record settlement receipt: %w", err)
+			}
+			if eventQueue != nil {
+				if err := o.writeEvent(ctx, task, eventQueue, a2a.NewStatusUpdateEvent(requestContext, a2a.TaskStateWorking, message)); err != nil {
+					return fmt.Errorf("failed to write confirmation event: %w", err)
+				}
+			}
+		}
+
+		timer := time.NewTimer(policy.pollInterval())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return &confirmationTimeoutError{transaction: receipt.Transaction, got: got, want: want, timeout: policy.timeout()}
+		case <-timer.C:
+		}
+	}
+}
+
+// confirmSettlement waits for confirmations and, when they do not arrive,
+// fails the task as indeterminate with the receipt attached. It reports
+// whether the task was failed.
+func (o *BusinessOrchestrator) confirmSettlement(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	eventQueue eventqueue.Queue,
+	paymentState *state.PaymentState,
+	receipt *x402core.SettleResponse,
+) (*state.PaymentState, bool, error) {
+	err := o.awaitConfirmations(ctx, requestContext, task, eventQueue, receipt)
+	var timeoutErr *confirmationTimeoutError
+	if !errors.As(err, &timeoutErr) {
+		return nil, err != nil, err
+	}
+	// The request may be ending too; the outcome must still be recorded.
+	next, err := o.failPayment(context.WithoutCancel(ctx), requestContext, task, eventQueue, paymentState,
+		err, x402pkg.ErrorCodeConfirmationTimeout, receipt)
+	return next, true, err
+}
+
+// rpcConfirmations counts confirmations through the policy's RPC endpoints.
+func (o *BusinessOrchestrator) rpcConfirmations() ConfirmationClient {
+	o.confirmationClientOnce.Do(func() {
+		o.confirmationClient = &rpcConfirmationClient{urls: o.confirmations.RPCURLs, clients: map[string]*ethclient.Client{}}
+	})
+	return o.confirmationClient
+}
+
+// rpcConfirmationClient counts confirmations of EVM transactions over
+// JSON-RPC, dialing each network's endpoint on first use.
+type rpcConfirmationClient struct {
+	urls    map[string]string
+	mu      sync.Mutex
+	clients map[string]*ethclient.Client
+}
+
+func (c *rpcConfirmationClient) Confirmations(ctx context.Context, network, transaction string) (uint64, error) {
+	client, err := c.client(ctx, network)
+	if err != nil {
+		return 0, err
+	}
+	receipt, err := client.TransactionReceipt(ctx, common.HexToHash(transaction))
+	if errors.Is(err, ethereum.NotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read receipt of %s: %w", transaction, err)
+	}
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read block number on %s: %w", network, err)
+	}
+	mined := receipt.BlockNumber.Uint64()
+	if head < mined {
+		return 0, nil
+	}
+	return head - mined + 1, nil
+}
+
+func (c *rpcConfirmationClient) client(ctx context.Context, network string) (*ethclient.Client, error) {
+	network = x402pkg.NormalizeNetwork(network)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if client, ok := c.clients[network]; ok {
+		return client, nil
+	}
+	var url string
+	for name, endpoint := range c.urls {
+		if x402pkg.NormalizeNetwork(name) == network {
+			url = endpoint
+		}
+	}
+	if url == "" || !x402pkg.IsEVMNetwork(network) {
+		return nil, fmt.Errorf("no EVM RPC endpoint configured for %s", network)
+	}
+	client, err := ethclient.DialContext(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial RPC endpoint for %s: %w", network, err)
+	}
+	c.clients[network] = client
+	return client, nil
+}
@@ -0,0 +1,143 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// fakeChain reports one more confirmation on every check, up to limit.
+type fakeChain struct {
+	mu     sync.Mutex
+	checks int
+	limit  uint64
+}
+
+func (c *fakeChain) Confirmations(ctx context.Context, network, transaction string) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	confirmations := min(uint64(c.checks), c.limit)
+	c.checks++
+	return confirmations, nil
+}
+
+func confirmationTexts(events []interface{}) []string {
+	var texts []string
+	for _, event := range events {
+		update, ok := event.(*a2a.TaskStatusUpdateEvent)
+		if !ok || update.Status.State != a2a.TaskStateWorking || update.Status.Message == nil {
+			continue
+		}
+		if text := x402state.ExtractMessageText(update.Status.Message); strings.Contains(text, "awaiting confirmations") {
+			texts = append(texts, text)
+		}
+	}
+	return texts
+}
+
+func newConfirmingOrchestrator(t *testing.T, chain ConfirmationClient, timeout time.Duration) *BusinessOrchestrator {
+	orchestrator := newNetworkMatchingOrchestrator(t, WithConfirmationPolicy(ConfirmationPolicy{
+		MinConfirmations: map[string]uint64{"base-sepolia": 3},
+		Client:           chain,
+		PollInterval:     time.Millisecond,
+		Timeout:          timeout,
+	}))
+	orchestrator.merchant.(*MockResourceServer).SettlePaymentFunc = func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.SettleResponse, error) {
+		return &x402core.SettleResponse{Success: true, Transaction: "0xfeed", Network: x402.NetworkBaseSepolia}, nil
+	}
+	return orchestrator
+}
+
+func TestConfirmationPolicy_WaitsForConfirmations(t *testing.T) {
+	chain := &fakeChain{limit: 10}
+	orchestrator := newConfirmingOrchestrator(t, chain, time.Second)
+	task := quoteTask(t, orchestrator)
+
+	events := payQuotedTask(t, orchestrator, task).events
+
+	if task.Status.State != a2a.TaskStateCompleted {
+		t.Fatalf("state = %s, want %s: %s", task.Status.State, a2a.TaskStateCompleted, x402state.ExtractMessageText(task.Status.Message))
+	}
+	want := []string{
+		"Payment settled; awaiting confirmations 0/3",
+		"Payment settled; awaiting confirmations 1/3",
+		"Payment settled; awaiting confirmations 2/3",
+	}
+	got := confirmationTexts(events)
+	if len(got) != len(want) {
+		t.Fatalf("confirmation events = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("confirmation event %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestConfirmationPolicy_TimeoutIsIndeterminate(t *testing.T) {
+	var settled int
+	chain := &fakeChain{limit: 1}
+	orchestrator := newConfirmingOrchestrator(t, chain, 20*time.Millisecond)
+	orchestrator.hooks.OnSettled = func(ctx context.Context, task *a2a.Task, receipt *x402core.SettleResponse) { settled++ }
+	task := quoteTask(t, orchestrator)
+
+	payQuotedTask(t, orchestrator, task)
+
+	if task.Status.State != a2a.TaskStateFailed {
+		t.Fatalf("state = %s, want %s", task.Status.State, a2a.TaskStateFailed)
+	}
+	metadata := task.Status.Message.Metadata
+	if metadata[x402.MetadataKeyError] != x402.ErrorCodeConfirmationTimeout {
+		t.Errorf("error code = %v, want %s", metadata[x402.MetadataKeyError], x402.ErrorCodeConfirmationTimeout)
+	}
+	if metadata[x402.MetadataKeyIndeterminate] != true {
+		t.Error("unconfirmed settlement not marked indeterminate")
+	}
+	receipts, _ := x402state.ExtractPaymentReceipts(task)
+	if len(receipts) != 1 || receipts[0].Transaction != "0xfeed" {
+		t.Errorf("receipts = %#v, want the unconfirmed settlement", receipts)
+	}
+	if settled != 0 {
+		t.Errorf("OnSettled called %d times for an unconfirmed settlement", settled)
+	}
+}
+
+func TestConfirmationPolicy_ZeroConfirmationsSkipsChain(t *testing.T) {
+	chain := &fakeChain{limit: 10}
+	orchestrator := newNetworkMatchingOrchestrator(t, WithConfirmationPolicy(ConfirmationPolicy{
+		MinConfirmations: map[string]uint64{x402.NetworkBase: 5},
+		Client:           chain,
+	}))
+	task := quoteTask(t, orchestrator)
+
+	events := payQuotedTask(t, orchestrator, task).events
+
+	if task.Status.State != a2a.TaskStateCompleted {
+		t.Fatalf("state = %s, want %s", task.Status.State, a2a.TaskStateCompleted)
+	}
+	if chain.checks != 0 || len(confirmationTexts(events)) != 0 {
+		t.Errorf("chain checked %d times for a network without a minimum", chain.checks)
+	}
+}
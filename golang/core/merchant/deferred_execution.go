@@ -0,0 +1,335 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/a2aproject/a2a-go/a2asrv/eventqueue"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// DeferredExecutionConfig configures running paid work after the request that
+// paid for it has returned.
+type DeferredExecutionConfig struct {
+	// Workers is the number of concurrent executions. Defaults to 4.
+	Workers int
+	// QueueSize bounds executions waiting for a worker. Defaults to 16.
+	QueueSize int
+	// RunInlineWhenFull executes within the paying request, as without
+	// deferred execution, when the pool is full. By default such payments
+	// are refused before settlement with the retryable MERCHANT_BUSY code.
+	RunInlineWhenFull bool
+	// Queues provides the task's event queue once the paying request has
+	// returned; the final status and artifact events are written to it. When
+	// nil they go to the paying request's queue, which is usually closed by
+	// then, and OnFinished is the dependable delivery path.
+	Queues eventqueue.Manager
+	// OnFinished is called once per deferred execution after its final event
+	// was written, with the execution error on failure. Executions stopped by
+	// Cancel are not reported.
+	OnFinished func(ctx context.Context, taskID a2a.TaskID, err error)
+}
+
+// WithDeferredExecution settles verified payments straight away and hands the
+// business execution to a bounded worker pool, for work that takes too long to
+// hold the request open. The task stays working, with its receipt recorded,
+// until a worker completes or fails it. It takes precedence over the
+// settlement policy and asynchronous settlement. Call Shutdown to drain the
+// pool; executions still running when its context expires are canceled and
+// their tasks fail. Queued executions are lost if the process exits.
+func WithDeferredExecution(config DeferredExecutionConfig) Option {
+	return func(o *BusinessOrchestrator) {
+		if config.Workers <= 0 {
+			config.Workers = 4
+		}
+		if config.QueueSize <= 0 {
+			config.QueueSize = 16
+		}
+		stopped, stop := context.WithCancelCause(context.Background())
+		o.deferredExecution = &deferredExecutor{
+			config:  config,
+			slots:   make(chan struct{}, config.Workers+config.QueueSize),
+			jobs:    make(chan *deferredJob, config.Workers+config.QueueSize),
+			running: make(map[a2a.TaskID]*deferredJob),
+			stopped: stopped,
+			stop:    stop,
+		}
+	}
+}
+
+// paymentDeferred is returned by handlePaymentVerified once execution was
+// handed to the pool. It never appears in task metadata.
+const paymentDeferred state.PaymentStatus = "payment-deferred"
+
+var (
+	errDeferredCanceled = errors.New("task canceled")
+	errDeferredShutdown = errors.New("merchant shutting down")
+)
+
+type deferredJob struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	// detach unregisters the job from the pool's shutdown.
+	detach         func() bool
+	task           *a2a.Task
+	requestContext *a2asrv.RequestContext
+	queue          eventqueue.Queue
+	paymentState   *state.PaymentState
+	request        business.Request
+	receipt        *x402core.SettleResponse
+}
+
+type deferredExecutor struct {
+	config DeferredExecutionConfig
+	// slots holds one token per queued or running execution and is claimed
+	// before settlement, so a settled payment always finds room in jobs.
+	slots chan struct{}
+	jobs  chan *deferredJob
+
+	// stopped is canceled when Shutdown gives up waiting.
+	stopped context.Context
+	stop    context.CancelCauseFunc
+
+	mu      sync.RWMutex
+	closed  bool
+	running map[a2a.TaskID]*deferredJob
+	workers sync.WaitGroup
+}
+
+func (e *deferredExecutor) start(o *BusinessOrchestrator) {
+	for range e.config.Workers {
+		e.workers.Add(1)
+		go func() {
+			defer e.workers.Done()
+			for job := range e.jobs {
+				o.executeDeferred(job)
+				<-e.slots
+			}
+		}()
+	}
+}
+
+// reserve claims room for one execution. It reports false when the pool is
+// full or shut down.
+func (e *deferredExecutor) reserve() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closed {
+		return false
+	}
+	select {
+	case e.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (e *deferredExecutor) release() {
+	<-e.slots
+}
+
+// enqueue hands a job to the pool after a successful reserve. The job's
+// context outlives the paying request and is canceled by Cancel or by an
+// expired Shutdown.
+func (e *deferredExecutor) enqueue(ctx context.Context, job *deferredJob) {
+	job.ctx, job.cancel = context.WithCancelCause(context.WithoutCancel(ctx))
+	job.detach = context.AfterFunc(e.stopped, func() { job.cancel(errDeferredShutdown) })
+
+	e.mu.Lock()
+	e.running[job.task.ID] = job
+	e.mu.Unlock()
+	e.jobs <- job
+}
+
+func (e *deferredExecutor) finish(job *deferredJob) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	job.detach()
+	job.cancel(nil)
+	if e.running[job.task.ID] == job {
+		delete(e.running, job.task.ID)
+	}
+}
+
+// cancel stops the task's deferred execution, if it has one, without writing
+// its outcome; Cancel reports the task as canceled instead.
+func (e *deferredExecutor) cancel(taskID a2a.TaskID) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if job, ok := e.running[taskID]; ok {
+		job.cancel(errDeferredCanceled)
+	}
+}
+
+func (e *deferredExecutor) shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	if !e.closed {
+		e.closed = true
+		close(e.jobs)
+	}
+	e.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		e.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		e.stop(errDeferredShutdown)
+		return fmt.Errorf("pending executions not drained: %w", ctx.Err())
+	}
+}
+
+// deferExecution settles the payment and queues the business execution. It
+// reports false when the pool is full and RunInlineWhenFull asks the caller
+// to execute as usual.
+func (o *BusinessOrchestrator) deferExecution(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	eventQueue eventqueue.Queue,
+	paymentState *state.PaymentState,
+	matchedRequirement *x402types.PaymentRequirements,
+	request business.Request,
+) (*state.PaymentState, bool, error) {
+	executor := o.deferredExecution
+	if !executor.reserve() {
+		if executor.config.RunInlineWhenFull {
+			return nil, false, nil
+		}
+		next, err := o.failPayment(ctx, requestContext, task, eventQueue, paymentState,
+			errors.New("merchant is at capacity; no payment was taken, try again later"),
+			x402pkg.ErrorCodeMerchantBusy, nil)
+		return next, true, err
+	}
+
+	if o.settlementAbandoned(ctx, task) {
+		executor.release()
+		next, err := o.voidAuthorization(ctx, requestContext, task, eventQueue, paymentState)
+		return next, true, err
+	}
+	settleResponse, err := o.settleWithRetry(ctx, requestContext, eventQueue, paymentState, matchedRequirement)
+	if err != nil {
+		executor.release()
+		next, err := o.failPayment(ctx, requestContext, task, eventQueue, paymentState,
+			err, settlementErrorCode(settleResponse, err), settleResponse)
+		return next, true, err
+	}
+	if next, failed, err := o.confirmSettlement(ctx, requestContext, task, eventQueue, paymentState, settleResponse); failed {
+		executor.release()
+		return next, true, err
+	}
+	o.logSettled(ctx, task, settleResponse)
+	o.hooks.settled(ctx, task, settleResponse)
+	o.recordReceipt(ctx, task, paymentState.Payer, matchedRequirement, settleResponse)
+
+	payloadHash := state.ExtractPaymentPayloadHash(task)
+	task.Status.State = a2a.TaskStateWorking
+	task.Status.Message = a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{Text: "Payment settled; the job is queued"})
+	state.SetPaymentStatus(task.Status.Message, state.PaymentVerified)
+	state.SetPaymentPayloadHash(task.Status.Message, payloadHash)
+	if err := state.SetPaymentReceipts(task.Status.Message, []*x402core.SettleResponse{settleResponse}); err != nil {
+		executor.release()
+		return nil, true, fmt.Errorf("failed to record settlement receipt: %w", err)
+	}
+	if err := o.writeEvent(ctx, task, eventQueue, statusEvent(requestContext, task)); err != nil {
+		executor.release()
+		return nil, true, fmt.Errorf("failed to write settlement event: %w", err)
+	}
+
+	executor.enqueue(ctx, &deferredJob{
+		task:           task,
+		requestContext: requestContext,
+		queue:          eventQueue,
+		paymentState:   paymentState,
+		request:        request,
+		receipt:        settleResponse,
+	})
+	return &state.PaymentState{Status: paymentDeferred}, true, nil
+}
+
+// executeDeferred runs a queued execution and writes the task's outcome to
+// the task's current event queue.
+func (o *BusinessOrchestrator) executeDeferred(job *deferredJob) {
+	executor := o.deferredExecution
+	defer executor.finish(job)
+
+	queue := job.queue
+	if executor.config.Queues != nil {
+		taskQueue, err := executor.config.Queues.GetOrCreate(job.ctx, job.task.ID)
+		if err != nil {
+			o.logger.ErrorContext(job.ctx, "x402 deferred execution has no event queue",
+				"task_id", job.task.ID,
+				"error", err,
+			)
+		} else {
+			queue = taskQueue
+		}
+	}
+
+	businessResult, err := o.runPaidRequest(job.ctx, job.requestContext, queue, job.request)
+
+	// The outcome is written with a context that is not canceled with the
+	// job, under the task's lock so it cannot interleave with Cancel.
+	ctx := context.WithoutCancel(job.ctx)
+	unlock, _ := o.taskLocks.lock(ctx, job.task.ID)
+	defer unlock()
+	if errors.Is(context.Cause(job.ctx), errDeferredCanceled) {
+		o.logger.InfoContext(ctx, "x402 deferred execution canceled",
+			"task_id", job.task.ID,
+			"context_id", job.task.ContextID,
+		)
+		return
+	}
+	var writeErr error
+	if err != nil {
+		_, writeErr = o.failPayment(ctx, job.requestContext, job.task, queue, job.paymentState,
+			err, businessErrorCode(err), job.receipt)
+	} else {
+		var next *state.PaymentState
+		next, writeErr = o.paidResult(ctx, job.requestContext, job.task, queue, businessResult, job.receipt)
+		if writeErr == nil && next.Status == state.PaymentCompleted {
+			writeErr = o.transitionToCompleted(ctx, job.requestContext, job.task, queue, next)
+		}
+	}
+	if writeErr != nil {
+		o.logger.ErrorContext(ctx, "x402 deferred execution outcome not recorded",
+			"task_id", job.task.ID,
+			"context_id", job.task.ContextID,
+			"error", writeErr,
+		)
+	}
+
+	if executor.config.OnFinished != nil {
+		if err == nil {
+			err = writeErr
+		}
+		executor.config.OnFinished(ctx, job.task.ID, err)
+	}
+}
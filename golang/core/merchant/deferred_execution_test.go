@@ -0,0 +1,445 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/a2aproject/a2a-go/a2asrv/eventqueue"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// taskQueues is an eventqueue.Manager handing out one recording queue per
+// task.
+type taskQueues struct {
+	mu     sync.Mutex
+	queues map[a2a.TaskID]*mockEventQueue
+}
+
+func (m *taskQueues) GetOrCreate(ctx context.Context, taskID a2a.TaskID) (eventqueue.Queue, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.queues == nil {
+		m.queues = make(map[a2a.TaskID]*mockEventQueue)
+	}
+	if _, ok := m.queues[taskID]; !ok {
+		m.queues[taskID] = &mockEventQueue{}
+	}
+	return m.queues[taskID], nil
+}
+
+func (m *taskQueues) Get(ctx context.Context, taskID a2a.TaskID) (eventqueue.Queue, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	queue, ok := m.queues[taskID]
+	return queue, ok
+}
+
+func (m *taskQueues) Destroy(ctx context.Context, taskID a2a.TaskID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.queues, taskID)
+	return nil
+}
+
+// finalState returns the state of the last status update written for taskID.
+func (m *taskQueues) finalState(taskID a2a.TaskID) a2a.TaskState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	queue, ok := m.queues[taskID]
+	if !ok {
+		return ""
+	}
+	var last a2a.TaskState
+	for _, event := range queue.events {
+		if update, ok := event.(*a2a.TaskStatusUpdateEvent); ok {
+			last = update.Status.State
+		}
+	}
+	return last
+}
+
+// slowService quotes like mockBusinessService and holds paid executions of
+// blocked tasks until release is closed or the context ends.
+type slowService struct {
+	release chan struct{}
+	blocked func(taskID a2a.TaskID) bool
+	started chan a2a.TaskID
+}
+
+func (s *slowService) Execute(ctx context.Context, request business.Request) (*business.Result, error) {
+	if !request.PaymentVerified {
+		return (&mockBusinessService{}).Execute(ctx, request)
+	}
+	if s.started != nil {
+		s.started <- request.TaskID
+	}
+	if s.blocked == nil || s.blocked(request.TaskID) {
+		select {
+		case <-s.release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return &business.Result{Message: "rendered " + string(request.TaskID)}, nil
+}
+
+func newDeferredOrchestrator(t *testing.T, service business.BusinessService, settles *atomic.Int32, config DeferredExecutionConfig) *BusinessOrchestrator {
+	return newTestOrchestrator(
+		t,
+		&MockResourceServer{
+			SettlePaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.SettleResponse, error) {
+				settles.Add(1)
+				return &x402core.SettleResponse{Success: true, Transaction: "0xtx", Network: x402.NetworkBaseSepolia}, nil
+			},
+		},
+		service,
+		[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+		WithDeferredExecution(config),
+	)
+}
+
+func waitFinished(t *testing.T, finished <-chan a2a.TaskID) a2a.TaskID {
+	t.Helper()
+	select {
+	case taskID := <-finished:
+		return taskID
+	case <-time.After(5 * time.Second):
+		t.Fatal("deferred execution did not finish")
+		return ""
+	}
+}
+
+func TestBusinessOrchestrator_Execute_DeferredExecution(t *testing.T) {
+	var settles atomic.Int32
+	queues := &taskQueues{}
+	finished := make(chan a2a.TaskID, 1)
+	service := &slowService{release: make(chan struct{})}
+	orchestrator := newDeferredOrchestrator(t, service, &settles, DeferredExecutionConfig{
+		Workers: 1,
+		Queues:  queues,
+		OnFinished: func(ctx context.Context, taskID a2a.TaskID, err error) {
+			if err != nil {
+				t.Errorf("OnFinished() error = %v", err)
+			}
+			finished <- taskID
+		},
+	})
+
+	task := payTask(t, orchestrator, "task-render")
+	if task.Status.State != a2a.TaskStateWorking {
+		t.Fatalf("state after payment = %s, want %s", task.Status.State, a2a.TaskStateWorking)
+	}
+	receipts, err := x402state.ExtractPaymentReceipts(task)
+	if err != nil || len(receipts) != 1 || receipts[0].Transaction != "0xtx" {
+		t.Fatalf("receipts after payment = %+v, %v, want the settlement receipt", receipts, err)
+	}
+	if got := settles.Load(); got != 1 {
+		t.Errorf("settlements = %d, want 1", got)
+	}
+
+	close(service.release)
+	waitFinished(t, finished)
+	if task.Status.State != a2a.TaskStateCompleted {
+		t.Errorf("state after execution = %s, want %s", task.Status.State, a2a.TaskStateCompleted)
+	}
+	if got := queues.finalState(task.ID); got != a2a.TaskStateCompleted {
+		t.Errorf("final event on the task queue = %q, want %s", got, a2a.TaskStateCompleted)
+	}
+	if got := settles.Load(); got != 1 {
+		t.Errorf("settlements after completion = %d, want 1", got)
+	}
+}
+
+func TestBusinessOrchestrator_Execute_DeferredExecutionLeavesStoredTaskUnchanged(t *testing.T) {
+	var settles atomic.Int32
+	queues := &taskQueues{}
+	finished := make(chan a2a.TaskID, 1)
+	service := &slowService{release: make(chan struct{})}
+	orchestrator := newDeferredOrchestrator(t, service, &settles, DeferredExecutionConfig{
+		Workers:    1,
+		Queues:     queues,
+		OnFinished: func(ctx context.Context, taskID a2a.TaskID, err error) { finished <- taskID },
+	})
+	task, submission := quotePayment(t, orchestrator, "task-stored")
+	quoted := task.Status
+
+	err := orchestrator.Execute(context.Background(), &a2asrv.RequestContext{
+		Message:    submission,
+		StoredTask: task,
+		TaskID:     task.ID,
+		ContextID:  task.ContextID,
+	}, &mockEventQueue{})
+	if err != nil {
+		t.Fatalf("paid Execute() error = %v", err)
+	}
+	close(service.release)
+	waitFinished(t, finished)
+
+	// The deferred job outlives Execute; it must finish on its own copy while
+	// a2asrv still holds the stored task.
+	if task.Status != quoted {
+		t.Errorf("stored task status changed to %s, want it left to the task store", task.Status.State)
+	}
+	if got := queues.finalState(task.ID); got != a2a.TaskStateCompleted {
+		t.Errorf("final event on the task queue = %q, want %s", got, a2a.TaskStateCompleted)
+	}
+}
+
+func TestBusinessOrchestrator_Execute_DeferredExecutionPoolFull(t *testing.T) {
+	tests := []struct {
+		name        string
+		inline      bool
+		wantState   a2a.TaskState
+		wantCode    string
+		wantSettles int32
+	}{
+		{name: "rejected before settlement", wantState: a2a.TaskStateFailed, wantCode: x402.ErrorCodeMerchantBusy, wantSettles: 2},
+		{name: "run inline", inline: true, wantState: a2a.TaskStateCompleted, wantSettles: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var settles atomic.Int32
+			service := &slowService{
+				release: make(chan struct{}),
+				blocked: func(taskID a2a.TaskID) bool { return taskID != "task-3" },
+				started: make(chan a2a.TaskID, 3),
+			}
+			orchestrator := newDeferredOrchestrator(t, service, &settles, DeferredExecutionConfig{
+				Workers:           1,
+				QueueSize:         1,
+				RunInlineWhenFull: tt.inline,
+				Queues:            &taskQueues{},
+			})
+
+			running := payTask(t, orchestrator, "task-1")
+			<-service.started
+			queued := payTask(t, orchestrator, "task-2")
+			overflow := payTask(t, orchestrator, "task-3")
+
+			if overflow.Status.State != tt.wantState {
+				t.Fatalf("overflow state = %s, want %s", overflow.Status.State, tt.wantState)
+			}
+			if code, _ := overflow.Status.Message.Metadata[x402.MetadataKeyError].(string); code != tt.wantCode {
+				t.Errorf("overflow error code = %q, want %q", code, tt.wantCode)
+			}
+			if tt.wantCode != "" && !x402.Retryable(tt.wantCode) {
+				t.Errorf("%s is not retryable", tt.wantCode)
+			}
+			if got := settles.Load(); got != tt.wantSettles {
+				t.Errorf("settlements = %d, want %d", got, tt.wantSettles)
+			}
+
+			close(service.release)
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := orchestrator.Shutdown(ctx); err != nil {
+				t.Fatalf("Shutdown() error = %v", err)
+			}
+			for _, task := range []*a2a.Task{running, queued} {
+				if task.Status.State != a2a.TaskStateCompleted {
+					t.Errorf("%s state = %s, want %s", task.ID, task.Status.State, a2a.TaskStateCompleted)
+				}
+			}
+		})
+	}
+}
+
+func TestBusinessOrchestrator_Shutdown_DrainsDeferredExecutions(t *testing.T) {
+	var settles atomic.Int32
+	service := &slowService{release: make(chan struct{})}
+	orchestrator := newDeferredOrchestrator(t, service, &settles, DeferredExecutionConfig{Workers: 1, Queues: &taskQueues{}})
+	first := payTask(t, orchestrator, "task-first")
+	second := payTask(t, orchestrator, "task-second")
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		close(service.release)
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := orchestrator.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	for _, task := range []*a2a.Task{first, second} {
+		if task.Status.State != a2a.TaskStateCompleted {
+			t.Errorf("%s state = %s, want %s", task.ID, task.Status.State, a2a.TaskStateCompleted)
+		}
+	}
+	late := &a2asrv.RequestContext{
+		Message:   a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "render"}),
+		TaskID:    "task-late",
+		ContextID: "context-late",
+	}
+	if err := orchestrator.executeInPlace(context.Background(), late, &mockEventQueue{}); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("Execute() after Shutdown error = %v, want %v", err, ErrShuttingDown)
+	}
+}
+
+func TestBusinessOrchestrator_Shutdown_CancelsUndrainedDeferredExecutions(t *testing.T) {
+	var settles atomic.Int32
+	finished := make(chan a2a.TaskID, 1)
+	var finishErr error
+	service := &slowService{release: make(chan struct{}), started: make(chan a2a.TaskID, 1)}
+	orchestrator := newDeferredOrchestrator(t, service, &settles, DeferredExecutionConfig{
+		Queues: &taskQueues{},
+		OnFinished: func(ctx context.Context, taskID a2a.TaskID, err error) {
+			finishErr = err
+			finished <- taskID
+		},
+	})
+	task := payTask(t, orchestrator, "task-stuck")
+	<-service.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := orchestrator.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown() error = %v, want deadline exceeded", err)
+	}
+	waitFinished(t, finished)
+	if finishErr == nil {
+		t.Error("OnFinished() error = nil, want the canceled execution")
+	}
+	if task.Status.State != a2a.TaskStateFailed {
+		t.Fatalf("state = %s, want %s", task.Status.State, a2a.TaskStateFailed)
+	}
+	receipts, _ := x402state.ExtractPaymentReceipts(task)
+	if len(receipts) == 0 || !receipts[0].Success {
+		t.Errorf("receipts = %+v, want the settled payment kept on the failed task", receipts)
+	}
+}
+
+func TestBusinessOrchestrator_Cancel_StopsDeferredExecution(t *testing.T) {
+	var settles atomic.Int32
+	queues := &taskQueues{}
+	service := &slowService{release: make(chan struct{}), started: make(chan a2a.TaskID, 1)}
+	orchestrator := newDeferredOrchestrator(t, service, &settles, DeferredExecutionConfig{
+		Queues: queues,
+		OnFinished: func(ctx context.Context, taskID a2a.TaskID, err error) {
+			t.Errorf("OnFinished(%s) called for a canceled execution", taskID)
+		},
+	})
+	task := payTask(t, orchestrator, "task-cancel")
+	<-service.started
+
+	stored := *task
+	if err := orchestrator.Cancel(context.Background(), &a2asrv.RequestContext{
+		StoredTask: &stored,
+		TaskID:     task.ID,
+		ContextID:  task.ContextID,
+	}, &mockEventQueue{}); err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+	if stored.Status.State != a2a.TaskStateCanceled {
+		t.Errorf("canceled state = %s, want %s", stored.Status.State, a2a.TaskStateCanceled)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := orchestrator.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if got := queues.finalState(task.ID); got != "" {
+		t.Errorf("deferred execution wrote %s after Cancel", got)
+	}
+}
+
+func TestBusinessOrchestrator_Execute_RefusesRejectDuringDeferredExecution(t *testing.T) {
+	var settles atomic.Int32
+	queues := &taskQueues{}
+	finished := make(chan a2a.TaskID, 1)
+	service := &slowService{release: make(chan struct{}), started: make(chan a2a.TaskID, 1)}
+	orchestrator := newDeferredOrchestrator(t, service, &settles, DeferredExecutionConfig{
+		Queues:     queues,
+		OnFinished: func(ctx context.Context, taskID a2a.TaskID, err error) { finished <- taskID },
+	})
+	task := payTask(t, orchestrator, "task-reject-deferred")
+	<-service.started
+
+	stored := *task
+	rejection := x402state.EncodePaymentRejection(task.ID, "changed my mind")
+	rejection.ContextID = task.ContextID
+	err := orchestrator.executeInPlace(context.Background(), &a2asrv.RequestContext{
+		Message:    rejection,
+		StoredTask: &stored,
+		TaskID:     task.ID,
+		ContextID:  task.ContextID,
+	}, &mockEventQueue{})
+	if !errors.Is(err, a2a.ErrInvalidParams) {
+		t.Fatalf("reject Execute() error = %v, want %v", err, a2a.ErrInvalidParams)
+	}
+	if stored.Status.State != a2a.TaskStateWorking {
+		t.Errorf("state after reject = %s, want %s", stored.Status.State, a2a.TaskStateWorking)
+	}
+
+	close(service.release)
+	waitFinished(t, finished)
+	if got := queues.finalState(task.ID); got != a2a.TaskStateCompleted {
+		t.Errorf("final event on the task queue = %q, want %s", got, a2a.TaskStateCompleted)
+	}
+}
+
+func TestBusinessOrchestrator_Execute_TerminalTransitionStopsDeferredExecution(t *testing.T) {
+	var settles atomic.Int32
+	queues := &taskQueues{}
+	service := &slowService{release: make(chan struct{}), started: make(chan a2a.TaskID, 1)}
+	orchestrator := newDeferredOrchestrator(t, service, &settles, DeferredExecutionConfig{
+		Queues: queues,
+		OnFinished: func(ctx context.Context, taskID a2a.TaskID, err error) {
+			t.Errorf("OnFinished(%s) called for an execution whose task already ended", taskID)
+		},
+	})
+	task := payTask(t, orchestrator, "task-ended")
+	<-service.started
+
+	// The queued status records no payload, so a follow-up message cannot
+	// be matched to a requirement and fails the task.
+	stored := *task
+	followUp := a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "any news?"})
+	followUp.TaskID = task.ID
+	followUp.ContextID = task.ContextID
+	if err := orchestrator.executeInPlace(context.Background(), &a2asrv.RequestContext{
+		Message:    followUp,
+		StoredTask: &stored,
+		TaskID:     task.ID,
+		ContextID:  task.ContextID,
+	}, &mockEventQueue{}); err != nil {
+		t.Fatalf("follow-up Execute() error = %v", err)
+	}
+	if !stored.Status.State.Terminal() {
+		t.Fatalf("state after follow-up = %s, want a terminal state", stored.Status.State)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := orchestrator.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if got := queues.finalState(task.ID); got != "" {
+		t.Errorf("deferred execution wrote %s after the task ended", got)
+	}
+}
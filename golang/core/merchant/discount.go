@@ -0,0 +1,131 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+)
+
+// DiscountPolicy adjusts a quote before payment requirements are built, e.g.
+// for a promo code or account credit the client sent in message metadata.
+// Returning a zero price serves the request for free. An invalid code should
+// return the base requirements with a DiscountInfo.Note rather than an error;
+// errors are treated the same way and never fail the task.
+type DiscountPolicy interface {
+	Apply(ctx context.Context, req *a2asrv.RequestContext, base business.ServiceRequirements) (business.ServiceRequirements, DiscountInfo, error)
+}
+
+// DiscountPolicyFunc adapts a function to the DiscountPolicy interface.
+type DiscountPolicyFunc func(ctx context.Context, req *a2asrv.RequestContext, base business.ServiceRequirements) (business.ServiceRequirements, DiscountInfo, error)
+
+func (f DiscountPolicyFunc) Apply(ctx context.Context, req *a2asrv.RequestContext, base business.ServiceRequirements) (business.ServiceRequirements, DiscountInfo, error) {
+	return f(ctx, req, base)
+}
+
+// DiscountInfo describes the discount applied to one payment option. It is
+// recorded in the requirement's extra and in the task metadata for auditing.
+type DiscountInfo struct {
+	Code        string `json:"code,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Note is shown to the client when no discount applied, e.g. "unknown
+	// promo code".
+	Note string `json:"note,omitempty"`
+	// OriginalPrice and Price are filled in by the orchestrator.
+	OriginalPrice string `json:"originalPrice,omitempty"`
+	Price         string `json:"price,omitempty"`
+}
+
+func (d DiscountInfo) empty() bool {
+	return d.Code == "" && d.Description == "" && d.Note == ""
+}
+
+func (d DiscountInfo) metadata() map[string]interface{} {
+	metadata := map[string]interface{}{}
+	for key, value := range map[string]string{
+		"code":          d.Code,
+		"description":   d.Description,
+		"note":          d.Note,
+		"originalPrice": d.OriginalPrice,
+		"price":         d.Price,
+	} {
+		if value != "" {
+			metadata[key] = value
+		}
+	}
+	return metadata
+}
+
+// WithDiscountPolicy applies discounts to every quote.
+func WithDiscountPolicy(policy DiscountPolicy) Option {
+	return func(o *BusinessOrchestrator) {
+		o.discountPolicy = policy
+	}
+}
+
+// applyDiscounts returns the discounted payment request and the discount
+// applied to each option, in order.
+func (o *BusinessOrchestrator) applyDiscounts(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	paymentRequired *business.PaymentRequiredError,
+) (*business.PaymentRequiredError, []DiscountInfo) {
+	if o.discountPolicy == nil {
+		return paymentRequired, nil
+	}
+
+	discounted := &business.PaymentRequiredError{
+		Message:      paymentRequired.Message,
+		Requirements: make([]business.ServiceRequirements, len(paymentRequired.Requirements)),
+	}
+	discounts := make([]DiscountInfo, len(paymentRequired.Requirements))
+	for i, base := range paymentRequired.Requirements {
+		requirement, info, err := o.discountPolicy.Apply(ctx, requestContext, base)
+		if err != nil {
+			o.logTransition(ctx, slog.LevelWarn, task, "x402 discount not applied", "", slog.String("error", err.Error()))
+			requirement, info = base, DiscountInfo{Note: err.Error()}
+		}
+		if !info.empty() {
+			info.OriginalPrice = base.Price
+			info.Price = requirement.Price
+		}
+		discounted.Requirements[i] = requirement
+		discounts[i] = info
+	}
+	return discounted, discounts
+}
+
+// setDiscountMetadata records applied discounts on the task status message.
+func setDiscountMetadata(message *a2a.Message, discounts []DiscountInfo) {
+	var recorded []interface{}
+	for _, discount := range discounts {
+		if !discount.empty() {
+			recorded = append(recorded, discount.metadata())
+		}
+	}
+	if len(recorded) == 0 {
+		return
+	}
+	if message.Metadata == nil {
+		message.Metadata = make(map[string]interface{})
+	}
+	message.Metadata[x402.MetadataKeyDiscounts] = recorded
+}
@@ -0,0 +1,151 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// promoPolicy knows two codes: HALF takes 50% off and CREDIT covers the full
+// price. Anything else is reported back as an unknown code.
+var promoPolicy = DiscountPolicyFunc(func(ctx context.Context, req *a2asrv.RequestContext, base business.ServiceRequirements) (business.ServiceRequirements, DiscountInfo, error) {
+	code, _ := req.Message.Meta()["promoCode"].(string)
+	switch code {
+	case "":
+		return base, DiscountInfo{}, nil
+	case "HALF":
+		base.Price = "0.50"
+		return base, DiscountInfo{Code: code, Description: "50% off"}, nil
+	case "CREDIT":
+		base.Price = "0"
+		return base, DiscountInfo{Code: code, Description: "account credit"}, nil
+	case "BROKEN":
+		return base, DiscountInfo{}, errors.New("promo service unavailable")
+	default:
+		return base, DiscountInfo{Note: "unknown promo code " + code}, nil
+	}
+})
+
+func TestBusinessOrchestrator_DiscountPolicy(t *testing.T) {
+	tests := []struct {
+		name         string
+		code         string
+		wantState    a2a.TaskState
+		wantAmount   string
+		wantDiscount map[string]interface{}
+	}{
+		{
+			name:       "percent discount",
+			code:       "HALF",
+			wantState:  a2a.TaskStateInputRequired,
+			wantAmount: "500000",
+			wantDiscount: map[string]interface{}{
+				"code": "HALF", "description": "50% off", "originalPrice": "1.00", "price": "0.50",
+			},
+		},
+		{
+			name:      "full credit",
+			code:      "CREDIT",
+			wantState: a2a.TaskStateCompleted,
+			wantDiscount: map[string]interface{}{
+				"code": "CREDIT", "description": "account credit", "originalPrice": "1.00", "price": "0",
+			},
+		},
+		{
+			name:       "invalid code",
+			code:       "BOGUS",
+			wantState:  a2a.TaskStateInputRequired,
+			wantAmount: "1000000",
+			wantDiscount: map[string]interface{}{
+				"note": "unknown promo code BOGUS", "originalPrice": "1.00", "price": "1.00",
+			},
+		},
+		{
+			name:       "policy error",
+			code:       "BROKEN",
+			wantState:  a2a.TaskStateInputRequired,
+			wantAmount: "1000000",
+			wantDiscount: map[string]interface{}{
+				"note": "promo service unavailable", "originalPrice": "1.00", "price": "1.00",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var verified, settled []x402types.PaymentRequirements
+			service := &mockBusinessService{executeFunc: func(ctx context.Context, request business.Request) (*business.Result, error) {
+				if request.PaymentVerified {
+					return &business.Result{Message: "done"}, nil
+				}
+				return nil, business.NewPaymentRequiredError("pay", business.ServiceRequirements{Price: "1.00", Resource: "/promo", Scheme: "exact"})
+			}}
+			orchestrator := newTestOrchestrator(
+				t,
+				assetAwareResourceServer(&verified, &settled),
+				service,
+				[]types.NetworkConfig{{
+					NetworkName:  x402.NetworkBase,
+					PayToAddress: "0x123",
+					Assets:       []types.AssetConfig{{Address: "0xusdc", Decimals: 6}},
+				}},
+				WithDiscountPolicy(promoPolicy),
+			)
+
+			message := a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "buy"})
+			message.Metadata = map[string]interface{}{"promoCode": tt.code}
+			requestContext := &a2asrv.RequestContext{Message: message, TaskID: "task-promo", ContextID: "context-promo"}
+			if err := orchestrator.executeInPlace(context.Background(), requestContext, &mockEventQueue{}); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			task := requestContext.StoredTask
+
+			if task.Status.State != tt.wantState {
+				t.Fatalf("task state = %v, want %v", task.Status.State, tt.wantState)
+			}
+			recorded, _ := task.Status.Message.Metadata[x402.MetadataKeyDiscounts].([]interface{})
+			if len(recorded) != 1 || !maps.Equal(recorded[0].(map[string]interface{}), tt.wantDiscount) {
+				t.Errorf("task discount metadata = %#v, want %#v", recorded, tt.wantDiscount)
+			}
+			if tt.wantAmount == "" {
+				return
+			}
+
+			requirements, err := x402state.ExtractPaymentRequirements(task)
+			if err != nil || len(requirements.Accepts) != 1 {
+				t.Fatalf("accepts = %#v, error = %v", requirements, err)
+			}
+			accepted := requirements.Accepts[0]
+			if accepted.Amount != tt.wantAmount {
+				t.Errorf("amount = %s, want %s", accepted.Amount, tt.wantAmount)
+			}
+			discount, _ := accepted.Extra["discount"].(map[string]interface{})
+			if !maps.Equal(discount, tt.wantDiscount) {
+				t.Errorf("requirement discount = %#v, want %#v", discount, tt.wantDiscount)
+			}
+		})
+	}
+}
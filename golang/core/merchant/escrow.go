@@ -0,0 +1,435 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/a2aproject/a2a-go/a2asrv/eventqueue"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// EscrowPolicy configures holding settlement until the client acknowledges
+// delivery.
+type EscrowPolicy struct {
+	// AckWindow is how long the client has to acknowledge or dispute a
+	// delivery before its payment settles anyway. Defaults to 24h.
+	AckWindow time.Duration
+	// Holds selects the paid requests whose payment is held, e.g. by price.
+	// When nil every paid request is held.
+	Holds func(ctx context.Context, request business.Request) bool
+	// Tasks, when set, is the server's task store. A delivery whose window
+	// closes is settled against the stored task and the outcome saved there.
+	Tasks a2asrv.TaskStore
+	// Queues provides the task's event queue when a window closes; the final
+	// status event is written to it. When nil the outcome only reaches Tasks
+	// and OnSettled.
+	Queues eventqueue.Manager
+	// OnSettled is called once per delivery settled because its window
+	// closed, with the receipt and, on failure, the settlement error.
+	OnSettled func(ctx context.Context, taskID a2a.TaskID, receipt *x402core.SettleResponse, err error)
+}
+
+// WithEscrowPolicy gives clients a dispute window on paid work. The verified
+// payment is not settled when the business logic succeeds: the result is
+// delivered and the task waits, input-required with the delivery-pending-ack
+// status, for the client to answer with x402.delivery.ack. Accepting settles
+// the payment and completes the task with its receipt; disputing voids the
+// authorization, runs the compensation handler and cancels the task. A
+// delivery nobody answers settles when AckWindow closes.
+//
+// Held deliveries are kept in the PaymentStateStore when it implements
+// PendingDeliveryStore, and their windows still close after a restart.
+// Without such a store they are lost if the process exits. It takes
+// precedence over asynchronous and batch settlement; payments settled before
+// execution, under SettleThenExecute or WithDeferredExecution, and results
+// that ask for another payment are never held.
+func WithEscrowPolicy(policy EscrowPolicy) Option {
+	return func(o *BusinessOrchestrator) {
+		if policy.AckWindow <= 0 {
+			policy.AckWindow = 24 * time.Hour
+		}
+		o.escrow = &escrowHolder{
+			policy: policy,
+			held:   make(map[a2a.TaskID]*heldDelivery),
+		}
+	}
+}
+
+var errDeliveryDisputed = errors.New("client disputed the delivery")
+
+type heldDelivery struct {
+	pending *PendingDelivery
+	timer   *time.Timer
+}
+
+type escrowHolder struct {
+	policy EscrowPolicy
+	store  PendingDeliveryStore
+
+	mu       sync.Mutex
+	closed   bool
+	held     map[a2a.TaskID]*heldDelivery
+	releases sync.WaitGroup
+}
+
+// start recovers held deliveries from the store and restarts their windows.
+func (e *escrowHolder) start(o *BusinessOrchestrator) {
+	ctx := context.Background()
+	if store, ok := o.stateStore.(PendingDeliveryStore); ok {
+		e.store = store
+	} else {
+		o.logger.WarnContext(ctx, "x402 held deliveries are not persisted: payment state store does not hold pending deliveries")
+		e.store = NewMemoryPaymentStateStore()
+	}
+	recovered, err := e.store.PendingDeliveries(ctx)
+	if err != nil {
+		o.logger.ErrorContext(ctx, "x402 held deliveries not recovered", "error", err)
+	}
+	for _, pending := range recovered {
+		e.hold(o, pending)
+	}
+}
+
+func (e *escrowHolder) holds(ctx context.Context, request business.Request) bool {
+	return e.policy.Holds == nil || e.policy.Holds(ctx, request)
+}
+
+// hold starts the delivery's window. It reports false once shut down.
+func (e *escrowHolder) hold(o *BusinessOrchestrator, pending *PendingDelivery) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return false
+	}
+	delivery := &heldDelivery{pending: pending}
+	delivery.timer = time.AfterFunc(pending.Deadline.Sub(o.now()), func() {
+		o.settleOnDeadline(pending.TaskID)
+	})
+	e.held[pending.TaskID] = delivery
+	return true
+}
+
+// take stops the task's window and returns its delivery, or false when none is
+// held. Callers hold the task lock, so exactly one of the client's answer and
+// the closing window gets the delivery.
+func (e *escrowHolder) take(taskID a2a.TaskID) (*PendingDelivery, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delivery, ok := e.held[taskID]
+	if !ok {
+		return nil, false
+	}
+	delivery.timer.Stop()
+	delete(e.held, taskID)
+	return delivery.pending, true
+}
+
+// release takes the task's delivery, if held, and removes it from the store.
+func (e *escrowHolder) release(ctx context.Context, o *BusinessOrchestrator, task *a2a.Task) (*PendingDelivery, bool) {
+	pending, ok := e.take(task.ID)
+	if err := e.store.DeletePendingDelivery(ctx, task.ID); err != nil {
+		o.logger.ErrorContext(ctx, "x402 released delivery left in escrow store",
+			"task_id", task.ID,
+			"context_id", task.ContextID,
+			"error", err,
+		)
+	}
+	return pending, ok
+}
+
+func (e *escrowHolder) shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	if !e.closed {
+		e.closed = true
+		for _, delivery := range e.held {
+			delivery.timer.Stop()
+		}
+	}
+	e.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		e.releases.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("held deliveries not released: %w", ctx.Err())
+	}
+}
+
+// holdDelivery delivers the business result and holds the verified payment
+// until the client answers or the window closes. It reports false when the
+// delivery cannot be saved and the caller must settle as usual.
+func (o *BusinessOrchestrator) holdDelivery(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	eventQueue eventqueue.Queue,
+	paymentState *state.PaymentState,
+	matchedRequirement *x402types.PaymentRequirements,
+	businessResult *business.Result,
+) (*state.PaymentState, bool, error) {
+	pending := &PendingDelivery{
+		TaskID:      task.ID,
+		ContextID:   task.ContextID,
+		Payer:       paymentState.Payer,
+		Payload:     paymentState.Payload,
+		Requirement: matchedRequirement,
+		Deadline:    o.now().Add(o.escrow.policy.AckWindow),
+	}
+	if err := o.escrow.store.SavePendingDelivery(ctx, pending); err != nil {
+		o.logger.WarnContext(ctx, "x402 escrow store unavailable: settling without acknowledgement",
+			"task_id", task.ID,
+			"context_id", task.ContextID,
+			"error", err,
+		)
+		return nil, false, nil
+	}
+
+	if err := o.writeArtifacts(ctx, task, eventQueue, businessArtifacts(businessResult.Message, businessResult.Parts, businessResult.Artifacts)); err != nil {
+		return nil, true, err
+	}
+	held := &state.PaymentState{
+		Status:       state.PaymentDeliveryPendingAck,
+		Requirements: paymentState.Requirements,
+		Payload:      paymentState.Payload,
+	}
+	responseText := resultSummary(businessResult.Summary, businessResult.Message, "Task completed")
+	task.Status.State = a2a.TaskStateInputRequired
+	task.Status.Message = a2a.NewMessage(a2a.MessageRoleAgent,
+		a2a.TextPart{Text: responseText + "\n\nAcknowledge the delivery to release payment, or dispute it to void the payment."})
+	if err := state.RecordPaymentVerified(task, held, ""); err != nil {
+		return nil, true, fmt.Errorf("failed to record held delivery: %w", err)
+	}
+	state.SetAckDeadline(task.Status.Message, pending.Deadline)
+	if err := o.savePaymentState(ctx, task, held); err != nil {
+		return nil, true, err
+	}
+	if err := o.writeEvent(ctx, task, eventQueue, statusEvent(requestContext, task)); err != nil {
+		return nil, true, err
+	}
+	if !o.escrow.hold(o, pending) {
+		o.logger.WarnContext(ctx, "x402 escrow shut down: delivery left held in the store",
+			"task_id", task.ID,
+			"context_id", task.ContextID,
+		)
+	}
+	return held, true, nil
+}
+
+// handleDeliveryAck answers a message sent to a held delivery. A payment
+// rejection disputes the delivery, and a message without an acknowledgement
+// leaves the delivery held. A dispute that arrives
+// after the deadline settles like an acknowledgement, and an answer to a
+// delivery no longer held replays the task's status.
+func (o *BusinessOrchestrator) handleDeliveryAck(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	eventQueue eventqueue.Queue,
+	message *a2a.Message,
+	paymentState *state.PaymentState,
+) error {
+	ack := state.ExtractDeliveryAck(message)
+	if status, _ := state.ExtractPaymentStatusFromMessage(message); ack == "" && status == state.PaymentRejected {
+		ack = state.DeliveryDisputed
+	}
+	if ack == "" {
+		return o.writeEvent(ctx, task, eventQueue, statusEvent(requestContext, task))
+	}
+
+	var requirement *x402types.PaymentRequirements
+	if o.escrow != nil {
+		pending, ok := o.escrow.release(ctx, o, task)
+		if !ok {
+			// The window already closed and settled the delivery; the task
+			// the client answered is stale.
+			return o.writeEvent(ctx, task, eventQueue, statusEvent(requestContext, task))
+		}
+		requirement = pending.Requirement
+		paymentState.Payer = pending.Payer
+	} else {
+		// The escrow policy was dropped while the delivery was held; settle
+		// the quoted requirement the payload matches.
+		matched, err := o.findMatchingRequirement(paymentState)
+		if err != nil {
+			_, err = o.failPayment(ctx, requestContext, task, eventQueue, paymentState, err, x402pkg.ErrorCodePayloadMismatch, nil)
+			return err
+		}
+		requirement = matched
+	}
+
+	deadline, ok := state.ExtractAckDeadline(task)
+	if ack == state.DeliveryDisputed && (!ok || !o.now().After(deadline)) {
+		compensation := o.compensate(ctx, task, paymentState, x402pkg.ErrorCodeAuthorizationVoided, errDeliveryDisputed)
+		return o.transitionToDeliveryDisputed(ctx, requestContext, task, eventQueue, paymentState, compensation)
+	}
+	_, _, err := o.settleDelivery(ctx, requestContext, task, eventQueue, paymentState, requirement, "Delivery acknowledged; payment settled")
+	return err
+}
+
+// settleDelivery settles a held payment and completes or fails the task. It
+// returns the receipt and the settlement error apart from the error writing
+// the outcome.
+func (o *BusinessOrchestrator) settleDelivery(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	eventQueue eventqueue.Queue,
+	paymentState *state.PaymentState,
+	requirement *x402types.PaymentRequirements,
+	summary string,
+) (*x402core.SettleResponse, error, error) {
+	receipt, settleErr := o.settleWithRetry(ctx, requestContext, eventQueue, paymentState, requirement)
+	if settleErr != nil {
+		// The delivery was accepted, so the merchant gets a chance to take
+		// it back before the task fails.
+		receipt = normalizeFailureReceipt(paymentState, receipt, settleErr)
+		code := settlementErrorCode(receipt, settleErr)
+		compensation := o.compensate(ctx, task, paymentState, code, settleErr)
+		_, err := o.failPaymentWithCompensation(ctx, requestContext, task, eventQueue, paymentState,
+			settleErr, code, receipt, compensation)
+		return receipt, settleErr, err
+	}
+	o.logSettled(ctx, task, receipt)
+	o.hooks.settled(ctx, task, receipt)
+	o.recordReceipt(ctx, task, paymentState.Payer, requirement, receipt)
+
+	return receipt, nil, o.transitionToCompleted(ctx, requestContext, task, eventQueue, &state.PaymentState{
+		Status:   state.PaymentCompleted,
+		Summary:  summary,
+		Receipts: []*x402core.SettleResponse{receipt},
+	})
+}
+
+// settleOnDeadline settles a delivery whose window closed without an answer.
+func (o *BusinessOrchestrator) settleOnDeadline(taskID a2a.TaskID) {
+	escrow := o.escrow
+	escrow.mu.Lock()
+	if escrow.closed {
+		// Left in the store for the next orchestrator.
+		escrow.mu.Unlock()
+		return
+	}
+	escrow.releases.Add(1)
+	escrow.mu.Unlock()
+	defer escrow.releases.Done()
+
+	ctx := context.Background()
+	unlock, err := o.taskLocks.lock(ctx, taskID)
+	if err != nil {
+		return
+	}
+	defer unlock()
+
+	task, version, err := o.heldTask(ctx, taskID)
+	if err != nil {
+		o.logger.ErrorContext(ctx, "x402 held delivery not loaded",
+			"task_id", taskID,
+			"error", err,
+		)
+		return
+	}
+	pending, ok := escrow.release(ctx, o, task)
+	if !ok {
+		// The client answered while the window was closing.
+		return
+	}
+	if status, _ := state.ExtractPaymentStatus(task); status != state.PaymentDeliveryPendingAck {
+		return
+	}
+
+	var queue eventqueue.Queue = discardQueue{}
+	if escrow.policy.Queues != nil {
+		taskQueue, err := escrow.policy.Queues.GetOrCreate(ctx, taskID)
+		if err != nil {
+			o.logger.ErrorContext(ctx, "x402 held delivery has no event queue",
+				"task_id", taskID,
+				"error", err,
+			)
+		} else {
+			queue = taskQueue
+		}
+	}
+	requestContext := &a2asrv.RequestContext{TaskID: task.ID, ContextID: task.ContextID, StoredTask: task}
+	paymentState := &state.PaymentState{
+		Status:  state.PaymentDeliveryPendingAck,
+		Payload: pending.Payload,
+		Payer:   pending.Payer,
+	}
+	receipt, settleErr, err := o.settleDelivery(ctx, requestContext, task, queue, paymentState, pending.Requirement,
+		"Acknowledgement window closed; payment settled")
+	if err == nil && escrow.policy.Tasks != nil {
+		_, err = escrow.policy.Tasks.Save(ctx, task, statusEvent(requestContext, task), version)
+	}
+	if err != nil {
+		o.logger.ErrorContext(ctx, "x402 held delivery outcome not recorded",
+			"task_id", task.ID,
+			"context_id", task.ContextID,
+			"error", err,
+		)
+	}
+	if escrow.policy.OnSettled != nil {
+		escrow.policy.OnSettled(ctx, taskID, receipt, settleErr)
+	}
+}
+
+// heldTask loads the task whose window closed from the task store. Without
+// one it stands in a task carrying only the held status.
+func (o *BusinessOrchestrator) heldTask(ctx context.Context, taskID a2a.TaskID) (*a2a.Task, a2a.TaskVersion, error) {
+	if tasks := o.escrow.policy.Tasks; tasks != nil {
+		return tasks.Get(ctx, taskID)
+	}
+	o.escrow.mu.Lock()
+	delivery, ok := o.escrow.held[taskID]
+	o.escrow.mu.Unlock()
+	task := &a2a.Task{ID: taskID, Status: a2a.TaskStatus{State: a2a.TaskStateInputRequired}}
+	if ok {
+		task.ContextID = delivery.pending.ContextID
+	}
+	task.Status.Message = a2a.NewMessage(a2a.MessageRoleAgent)
+	state.SetPaymentStatus(task.Status.Message, state.PaymentDeliveryPendingAck)
+	return task, a2a.TaskVersionMissing, nil
+}
+
+// discardQueue stands in for the event queue of a task no request is
+// executing.
+type discardQueue struct{}
+
+func (discardQueue) Write(ctx context.Context, event a2a.Event) error { return nil }
+
+func (discardQueue) WriteVersioned(ctx context.Context, event a2a.Event, version a2a.TaskVersion) error {
+	return nil
+}
+
+func (discardQueue) Read(ctx context.Context) (a2a.Event, a2a.TaskVersion, error) {
+	return nil, a2a.TaskVersionMissing, eventqueue.ErrQueueClosed
+}
+
+func (discardQueue) Close() error { return nil }
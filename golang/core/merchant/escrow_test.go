@@ -0,0 +1,356 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// escrowHarness records settlements and compensations and reports deliveries
+// settled when their window closed.
+type escrowHarness struct {
+	store *MemoryPaymentStateStore
+
+	mu           sync.Mutex
+	settled      []string
+	compensated  []SettlementFailure
+	windowClosed chan a2a.TaskID
+}
+
+func newEscrowHarness() *escrowHarness {
+	return &escrowHarness{store: NewMemoryPaymentStateStore(), windowClosed: make(chan a2a.TaskID, 4)}
+}
+
+func (h *escrowHarness) orchestrator(t *testing.T, policy EscrowPolicy, opts ...Option) *BusinessOrchestrator {
+	policy.OnSettled = func(ctx context.Context, taskID a2a.TaskID, receipt *x402core.SettleResponse, err error) {
+		h.windowClosed <- taskID
+	}
+	server := &MockResourceServer{
+		SettlePaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.SettleResponse, error) {
+			signature, _ := payload.Payload["signature"].(string)
+			h.mu.Lock()
+			h.settled = append(h.settled, signature)
+			h.mu.Unlock()
+			return &x402core.SettleResponse{Success: true, Transaction: "tx-" + signature, Network: x402core.Network(requirements.Network), Payer: "0x789"}, nil
+		},
+	}
+	opts = append([]Option{
+		WithPaymentStateStore(h.store),
+		WithEscrowPolicy(policy),
+		WithCompensationHandler(CompensationHandlerFunc(func(ctx context.Context, failure SettlementFailure) error {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			h.compensated = append(h.compensated, failure)
+			return nil
+		})),
+	}, opts...)
+	return newTestOrchestrator(
+		t,
+		server,
+		&mockBusinessService{},
+		[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+		opts...,
+	)
+}
+
+func (h *escrowHarness) settlements() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.settled...)
+}
+
+func (h *escrowHarness) held(t *testing.T) []*PendingDelivery {
+	t.Helper()
+	held, err := h.store.PendingDeliveries(context.Background())
+	if err != nil {
+		t.Fatalf("PendingDeliveries() error = %v", err)
+	}
+	return held
+}
+
+// answer sends message to the held task.
+func answer(t *testing.T, orchestrator *BusinessOrchestrator, task *a2a.Task, message *a2a.Message) {
+	t.Helper()
+	if err := orchestrator.executeInPlace(context.Background(), &a2asrv.RequestContext{
+		Message:    message,
+		StoredTask: task,
+		TaskID:     task.ID,
+		ContextID:  task.ContextID,
+	}, &mockEventQueue{}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+}
+
+func assertHeld(t *testing.T, task *a2a.Task) {
+	t.Helper()
+	if task.Status.State != a2a.TaskStateInputRequired {
+		t.Errorf("task state = %s, want input-required", task.Status.State)
+	}
+	if status, _ := x402state.ExtractPaymentStatus(task); status != x402state.PaymentDeliveryPendingAck {
+		t.Errorf("payment status = %q, want %q", status, x402state.PaymentDeliveryPendingAck)
+	}
+	if receipts, _ := x402state.ExtractPaymentReceipts(task); len(receipts) != 0 {
+		t.Errorf("receipts = %+v, want none while held", receipts)
+	}
+}
+
+func assertSettledOnce(t *testing.T, task *a2a.Task, harness *escrowHarness) {
+	t.Helper()
+	if task.Status.State != a2a.TaskStateCompleted {
+		t.Errorf("task state = %s, want completed", task.Status.State)
+	}
+	if status, _ := x402state.ExtractPaymentStatus(task); status != x402state.PaymentCompleted {
+		t.Errorf("payment status = %q, want %q", status, x402state.PaymentCompleted)
+	}
+	receipts, _ := x402state.ExtractPaymentReceipts(task)
+	if len(receipts) != 1 || receipts[0].Transaction != "tx-0x"+string(task.ID) {
+		t.Errorf("receipts = %+v, want the settlement of %s", receipts, task.ID)
+	}
+	if settled := harness.settlements(); len(settled) != 1 {
+		t.Errorf("settlements = %v, want exactly one", settled)
+	}
+	if held := harness.held(t); len(held) != 0 {
+		t.Errorf("held deliveries = %+v, want none after settlement", held)
+	}
+}
+
+func TestBusinessOrchestrator_Escrow_AckSettles(t *testing.T) {
+	harness := newEscrowHarness()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	orchestrator := harness.orchestrator(t, EscrowPolicy{AckWindow: time.Hour}, WithClock(func() time.Time { return now }))
+	defer shutdown(t, orchestrator)
+
+	task := payTask(t, orchestrator, "task-ack")
+
+	assertHeld(t, task)
+	if deadline, ok := x402state.ExtractAckDeadline(task); !ok || !deadline.Equal(now.Add(time.Hour)) {
+		t.Errorf("ack deadline = %v, %v, want %v", deadline, ok, now.Add(time.Hour))
+	}
+	if settled := harness.settlements(); len(settled) != 0 {
+		t.Fatalf("settlements = %v, want none before the acknowledgement", settled)
+	}
+	if held := harness.held(t); len(held) != 1 || held[0].TaskID != "task-ack" {
+		t.Fatalf("held deliveries = %+v, want task-ack", held)
+	}
+
+	// Anything but an answer leaves the delivery held.
+	answer(t, orchestrator, task, a2a.NewMessageForTask(a2a.MessageRoleUser, a2a.TaskInfo{TaskID: task.ID}, a2a.TextPart{Text: "thanks"}))
+	assertHeld(t, task)
+
+	answer(t, orchestrator, task, x402state.EncodeDeliveryAck(task.ID, x402state.DeliveryAccepted, ""))
+
+	assertSettledOnce(t, task, harness)
+	if _, found, _ := harness.store.LoadState(context.Background(), task.ID); found {
+		t.Error("payment record kept after the task completed")
+	}
+}
+
+func TestBusinessOrchestrator_Escrow_TimeoutSettles(t *testing.T) {
+	harness := newEscrowHarness()
+	tasks := &memoryTaskStore{}
+	orchestrator := harness.orchestrator(t, EscrowPolicy{AckWindow: 200 * time.Millisecond, Tasks: tasks})
+	defer shutdown(t, orchestrator)
+
+	task := payTask(t, orchestrator, "task-timeout")
+	// The a2a server saves the held task before the window closes.
+	if _, err := tasks.Save(context.Background(), task, nil, 0); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	assertHeld(t, task)
+
+	select {
+	case taskID := <-harness.windowClosed:
+		if taskID != task.ID {
+			t.Fatalf("settled task = %s, want %s", taskID, task.ID)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("held delivery was not settled when its window closed")
+	}
+
+	stored, _, err := tasks.Get(context.Background(), task.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	assertSettledOnce(t, stored, harness)
+
+	// A late acknowledgement against the stale task does not settle again.
+	answer(t, orchestrator, task, x402state.EncodeDeliveryAck(task.ID, x402state.DeliveryAccepted, ""))
+	if settled := harness.settlements(); len(settled) != 1 {
+		t.Errorf("settlements = %v, want exactly one", settled)
+	}
+}
+
+func TestBusinessOrchestrator_Escrow_RecoversHeldDeliveries(t *testing.T) {
+	harness := newEscrowHarness()
+	now := time.Now()
+	first := harness.orchestrator(t, EscrowPolicy{AckWindow: time.Hour}, WithClock(func() time.Time { return now }))
+	task := payTask(t, first, "task-restart")
+	shutdown(t, first)
+	if held := harness.held(t); len(held) != 1 {
+		t.Fatalf("held deliveries = %+v, want the delivery kept across shutdown", held)
+	}
+
+	// The next orchestrator starts after the window closed.
+	tasks := &memoryTaskStore{}
+	if _, err := tasks.Save(context.Background(), task, nil, 0); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	second := harness.orchestrator(t, EscrowPolicy{AckWindow: time.Hour, Tasks: tasks},
+		WithClock(func() time.Time { return now.Add(2 * time.Hour) }))
+	defer shutdown(t, second)
+
+	select {
+	case <-harness.windowClosed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("recovered delivery was not settled")
+	}
+	stored, _, err := tasks.Get(context.Background(), task.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	assertSettledOnce(t, stored, harness)
+}
+
+func TestBusinessOrchestrator_Escrow_DisputeVoids(t *testing.T) {
+	harness := newEscrowHarness()
+	orchestrator := harness.orchestrator(t, EscrowPolicy{AckWindow: time.Hour})
+	defer shutdown(t, orchestrator)
+
+	task := payTask(t, orchestrator, "task-dispute")
+	answer(t, orchestrator, task, x402state.EncodeDeliveryAck(task.ID, x402state.DeliveryDisputed, "Output was truncated"))
+
+	if task.Status.State != a2a.TaskStateCanceled {
+		t.Errorf("task state = %s, want canceled", task.Status.State)
+	}
+	meta := task.Status.Message.Meta()
+	if code, _ := meta[x402.MetadataKeyError].(string); code != x402.ErrorCodeAuthorizationVoided {
+		t.Errorf("error code = %q, want %q", code, x402.ErrorCodeAuthorizationVoided)
+	}
+	if voided, _ := meta[x402.MetadataKeyVoided].(bool); !voided {
+		t.Error("payment not marked voided")
+	}
+	if outcome := x402state.ExtractCompensation(task); outcome != x402state.CompensationSucceeded {
+		t.Errorf("compensation = %q, want %q", outcome, x402state.CompensationSucceeded)
+	}
+	if len(harness.compensated) != 1 || harness.compensated[0].ErrorCode != x402.ErrorCodeAuthorizationVoided {
+		t.Errorf("compensations = %+v, want one for the dispute", harness.compensated)
+	}
+	if settled := harness.settlements(); len(settled) != 0 {
+		t.Errorf("settlements = %v, want none after a dispute", settled)
+	}
+	if held := harness.held(t); len(held) != 0 {
+		t.Errorf("held deliveries = %+v, want none after a dispute", held)
+	}
+}
+
+func TestBusinessOrchestrator_Escrow_RejectDisputes(t *testing.T) {
+	harness := newEscrowHarness()
+	// The task records its deadline in whole seconds; a clock on a second
+	// keeps the rejection inside the short window.
+	now := time.Unix(time.Now().Unix(), 0)
+	orchestrator := harness.orchestrator(t, EscrowPolicy{AckWindow: 100 * time.Millisecond},
+		WithClock(func() time.Time { return now }))
+	defer shutdown(t, orchestrator)
+
+	task := payTask(t, orchestrator, "task-reject")
+	answer(t, orchestrator, task, x402state.EncodePaymentRejection(task.ID, "Not what I asked for"))
+
+	if task.Status.State != a2a.TaskStateCanceled {
+		t.Errorf("task state = %s, want canceled", task.Status.State)
+	}
+	if code, _ := task.Status.Message.Meta()[x402.MetadataKeyError].(string); code != x402.ErrorCodeAuthorizationVoided {
+		t.Errorf("error code = %q, want %q", code, x402.ErrorCodeAuthorizationVoided)
+	}
+	if held := harness.held(t); len(held) != 0 {
+		t.Errorf("held deliveries = %+v, want none after a rejection", held)
+	}
+
+	// The window would have closed by now; the released delivery must not
+	// settle.
+	select {
+	case taskID := <-harness.windowClosed:
+		t.Errorf("window closed and settled %s after the rejection", taskID)
+	case <-time.After(300 * time.Millisecond):
+	}
+	if settled := harness.settlements(); len(settled) != 0 {
+		t.Errorf("settlements = %v, want none after a rejection", settled)
+	}
+}
+
+func TestBusinessOrchestrator_Escrow_DisputeAfterWindowSettles(t *testing.T) {
+	harness := newEscrowHarness()
+	now := time.Now()
+	orchestrator := harness.orchestrator(t, EscrowPolicy{AckWindow: time.Hour}, WithClock(func() time.Time { return now }))
+	defer shutdown(t, orchestrator)
+
+	task := payTask(t, orchestrator, "task-late-dispute")
+	// The dispute lands after the deadline but before the window's timer.
+	now = now.Add(2 * time.Hour)
+	answer(t, orchestrator, task, x402state.EncodeDeliveryAck(task.ID, x402state.DeliveryDisputed, ""))
+
+	assertSettledOnce(t, task, harness)
+	if len(harness.compensated) != 0 {
+		t.Errorf("compensations = %+v, want none", harness.compensated)
+	}
+}
+
+func TestBusinessOrchestrator_Escrow_HoldsSelectedRequests(t *testing.T) {
+	harness := newEscrowHarness()
+	orchestrator := harness.orchestrator(t, EscrowPolicy{
+		Holds: func(ctx context.Context, request business.Request) bool { return false },
+	})
+	defer shutdown(t, orchestrator)
+
+	task := payTask(t, orchestrator, "task-not-held")
+
+	assertSettledOnce(t, task, harness)
+}
+
+func TestBusinessOrchestrator_Escrow_CancelVoidsHeldDelivery(t *testing.T) {
+	harness := newEscrowHarness()
+	orchestrator := harness.orchestrator(t, EscrowPolicy{AckWindow: time.Hour})
+	defer shutdown(t, orchestrator)
+
+	task := payTask(t, orchestrator, "task-cancel")
+	if err := orchestrator.Cancel(context.Background(), &a2asrv.RequestContext{
+		StoredTask: task,
+		TaskID:     task.ID,
+		ContextID:  task.ContextID,
+	}, &mockEventQueue{}); err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+
+	if task.Status.State != a2a.TaskStateCanceled {
+		t.Errorf("task state = %s, want canceled", task.Status.State)
+	}
+	if voided, _ := task.Status.Message.Meta()[x402.MetadataKeyVoided].(bool); !voided {
+		t.Error("payment not marked voided")
+	}
+	if held := harness.held(t); len(held) != 0 {
+		t.Errorf("held deliveries = %+v, want none after cancel", held)
+	}
+}
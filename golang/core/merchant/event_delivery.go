@@ -0,0 +1,122 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv/eventqueue"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+)
+
+// EventWritePolicy controls how failed event queue writes are retried. A
+// closed queue and an ended request context are never retried.
+type EventWritePolicy struct {
+	// MaxAttempts is the total number of writes, including the first.
+	MaxAttempts int
+	// Backoff is the wait between two attempts.
+	Backoff time.Duration
+}
+
+// DefaultEventWritePolicy returns the policy used when none is configured.
+func DefaultEventWritePolicy() EventWritePolicy {
+	return EventWritePolicy{
+		MaxAttempts: 3,
+		Backoff:     50 * time.Millisecond,
+	}
+}
+
+// WithEventWritePolicy overrides the event write retry policy. A policy with
+// MaxAttempts of 1 disables retries.
+func WithEventWritePolicy(policy EventWritePolicy) Option {
+	return func(o *BusinessOrchestrator) {
+		o.eventWrites = policy
+	}
+}
+
+func eventWriteRetryable(err error) bool {
+	return !errors.Is(err, eventqueue.ErrQueueClosed) &&
+		!errors.Is(err, context.Canceled) &&
+		!errors.Is(err, context.DeadlineExceeded)
+}
+
+// writeEvent writes event to queue, retrying transient failures. When the
+// write still fails after the task's payment was verified, the event is
+// handed to OnEventUndelivered so the receipt it carries can be reconciled.
+func (o *BusinessOrchestrator) writeEvent(
+	ctx context.Context,
+	task *a2a.Task,
+	queue eventqueue.Queue,
+	event a2a.Event,
+) (err error) {
+	var taskID a2a.TaskID
+	if task != nil {
+		taskID = task.ID
+	}
+	ctx, span := o.startSpan(ctx, SpanEventWrite, taskID)
+	defer func() { span.End(err) }()
+	attempts := max(o.eventWrites.MaxAttempts, 1)
+	for attempt := 1; ; attempt++ {
+		if err = queue.Write(ctx, event); err == nil {
+			return nil
+		}
+		if attempt >= attempts || !eventWriteRetryable(err) || !waitBackoff(ctx, o.eventWrites.Backoff) {
+			break
+		}
+	}
+
+	if paymentInFlight(task) {
+		o.logger.ErrorContext(ctx, "x402 payment event undelivered",
+			"task_id", task.ID,
+			"context_id", task.ContextID,
+			"error", err,
+		)
+		o.hooks.eventUndelivered(context.WithoutCancel(ctx), task, event, err)
+	}
+	return fmt.Errorf("failed to write event: %w", err)
+}
+
+// waitBackoff waits for delay and reports false if ctx ended first.
+func waitBackoff(ctx context.Context, delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// paymentInFlight reports whether task has reached verification, after which
+// an undelivered event may hide money movement from the client.
+func paymentInFlight(task *a2a.Task) bool {
+	if task == nil {
+		return false
+	}
+	status, err := state.ExtractPaymentStatusFromTask(task)
+	if err != nil {
+		return false
+	}
+	switch status {
+	case state.PaymentVerified, state.PaymentCompleted, state.PaymentFailed:
+		return true
+	}
+	return false
+}
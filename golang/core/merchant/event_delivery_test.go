@@ -0,0 +1,136 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/a2aproject/a2a-go/a2asrv/eventqueue"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+)
+
+// flakyEventQueue fails the next failures writes with err, then accepts them.
+// A negative failures count fails every write.
+type flakyEventQueue struct {
+	mockEventQueue
+	failures int
+	err      error
+	writes   int
+}
+
+func (q *flakyEventQueue) Write(ctx context.Context, event a2a.Event) error {
+	q.writes++
+	if q.failures != 0 {
+		if q.failures > 0 {
+			q.failures--
+		}
+		return q.err
+	}
+	return q.mockEventQueue.Write(ctx, event)
+}
+
+func TestBusinessOrchestrator_writeEvent(t *testing.T) {
+	errTransient := errors.New("queue busy")
+	verified := &a2a.Task{ID: "task-verified", Status: a2a.TaskStatus{State: a2a.TaskStateWorking, Message: a2a.NewMessage(a2a.MessageRoleAgent)}}
+	x402state.SetPaymentStatus(verified.Status.Message, x402state.PaymentVerified)
+	unpaid := &a2a.Task{ID: "task-unpaid", Status: a2a.TaskStatus{State: a2a.TaskStateWorking}}
+
+	tests := []struct {
+		name           string
+		task           *a2a.Task
+		failures       int
+		err            error
+		wantErr        bool
+		wantWrites     int
+		wantDeadLetter bool
+	}{
+		{name: "succeeds after transient failures", task: verified, failures: 2, err: errTransient, wantWrites: 3},
+		{name: "paid event dead-lettered when writes keep failing", task: verified, failures: -1, err: errTransient, wantErr: true, wantWrites: 3, wantDeadLetter: true},
+		{name: "unpaid event is not dead-lettered", task: unpaid, failures: -1, err: errTransient, wantErr: true, wantWrites: 3},
+		{name: "closed queue is not retried", task: verified, failures: -1, err: eventqueue.ErrQueueClosed, wantErr: true, wantWrites: 1, wantDeadLetter: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var deadLetters []a2a.Event
+			o := newTestOrchestrator(t, &MockResourceServer{}, &mockBusinessService{}, nil, WithEventWritePolicy(EventWritePolicy{MaxAttempts: 3, Backoff: time.Millisecond}),
+				WithHooks(Hooks{OnEventUndelivered: func(ctx context.Context, task *a2a.Task, event a2a.Event, err error) {
+					if !errors.Is(err, tt.err) {
+						t.Errorf("dead-letter error = %v, want %v", err, tt.err)
+					}
+					deadLetters = append(deadLetters, event)
+				}}),
+			)
+			queue := &flakyEventQueue{failures: tt.failures, err: tt.err}
+			event := a2a.NewStatusUpdateEvent(tt.task, a2a.TaskStateWorking, nil)
+
+			err := o.writeEvent(context.Background(), tt.task, queue, event)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("writeEvent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if queue.writes != tt.wantWrites {
+				t.Errorf("writes = %d, want %d", queue.writes, tt.wantWrites)
+			}
+			if got := len(deadLetters) == 1 && deadLetters[0] == event; got != tt.wantDeadLetter {
+				t.Errorf("dead-lettered = %v, want %v", deadLetters, tt.wantDeadLetter)
+			}
+		})
+	}
+}
+
+func TestBusinessOrchestrator_Execute_DeadLettersUndeliveredReceipt(t *testing.T) {
+	var deadLetters []*a2a.TaskStatusUpdateEvent
+	orchestrator := newTestOrchestrator(
+		t,
+		&MockResourceServer{},
+		&mockBusinessService{},
+		[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+		WithEventWritePolicy(EventWritePolicy{MaxAttempts: 2, Backoff: time.Millisecond}),
+		WithHooks(Hooks{OnEventUndelivered: func(ctx context.Context, task *a2a.Task, event a2a.Event, err error) {
+			if update, ok := event.(*a2a.TaskStatusUpdateEvent); ok {
+				deadLetters = append(deadLetters, update)
+			}
+		}}),
+	)
+
+	requestContext := &a2asrv.RequestContext{
+		Message:   a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "buy"}),
+		TaskID:    "task-dead-letter",
+		ContextID: "context-dead-letter",
+	}
+	if err := orchestrator.executeInPlace(context.Background(), requestContext, &flakyEventQueue{failures: 1, err: errors.New("queue busy")}); err != nil {
+		t.Fatalf("initial Execute() error = %v", err)
+	}
+	task := requestContext.StoredTask
+	submission := paymentSubmission(t, task)
+
+	err := submitPayment(orchestrator, task, submission, &flakyEventQueue{failures: -1, err: errors.New("queue gone")})
+	if err == nil {
+		t.Fatal("Execute() error = nil, want the undelivered write to surface")
+	}
+	if len(deadLetters) == 0 {
+		t.Fatal("no event was dead-lettered")
+	}
+	status, statusErr := x402state.ExtractPaymentStatusFromMessage(deadLetters[0].Status.Message)
+	if statusErr != nil || status != x402state.PaymentVerified {
+		t.Errorf("dead-lettered payment status = %v, error = %v; want the verification event", status, statusErr)
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultExecutionQueueTimeout is how long a verified payment waits for an
+// execution slot under WithMaxConcurrentExecutions.
+const DefaultExecutionQueueTimeout = 30 * time.Second
+
+// WithMaxConcurrentExecutions caps the business executions that run at once:
+// paid, free, trusted, subscribed and recovered alike. A request that finds
+// every slot taken waits for up to the execution queue timeout and then fails
+// with the retryable CAPACITY_EXCEEDED code. A verified payment claims its
+// slot before settlement under either settlement policy, so a payment that
+// times out is never settled. Deferred executions are bounded by their worker
+// pool instead. A limit of zero or less removes the cap.
+func WithMaxConcurrentExecutions(n int) Option {
+	return func(o *BusinessOrchestrator) {
+		o.maxConcurrentExecutions = n
+	}
+}
+
+// WithExecutionQueueTimeout changes how long a verified payment waits for an
+// execution slot. Zero or less waits for as long as the request does.
+func WithExecutionQueueTimeout(timeout time.Duration) Option {
+	return func(o *BusinessOrchestrator) {
+		o.executionQueueTimeout = timeout
+	}
+}
+
+// errCapacityExceeded reports a paid execution that never got a slot.
+var errCapacityExceeded = errors.New("timed out waiting for an execution slot, try again later")
+
+// acquireExecutionSlot waits for room to run a paid execution. The returned
+// release may be called more than once. Without a limit it returns straight
+// away.
+func (o *BusinessOrchestrator) acquireExecutionSlot(ctx context.Context) (func(), error) {
+	if o.executionSlots == nil {
+		return func() {}, nil
+	}
+	waitCtx := ctx
+	if o.executionQueueTimeout > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, o.executionQueueTimeout)
+		defer cancel()
+	}
+	if err := o.executionSlots.Acquire(waitCtx, 1); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("waiting for an execution slot: %w", ctx.Err())
+		}
+		return nil, errCapacityExceeded
+	}
+	released := false
+	return func() {
+		if !released {
+			released = true
+			o.executionSlots.Release(1)
+		}
+	}, nil
+}
+
+// ExecutionsInFlight returns how many paid business executions are running.
+func (o *BusinessOrchestrator) ExecutionsInFlight() int {
+	return int(o.executionsInFlight.Load())
+}
+
+// trackExecution counts a paid execution as in flight until the returned
+// function is called.
+func (o *BusinessOrchestrator) trackExecution() func() {
+	o.executionsInFlight.Add(1)
+	gauge, _ := o.metrics.(ExecutionGauge)
+	if gauge != nil {
+		gauge.AddExecutionsInFlight(1)
+	}
+	return func() {
+		o.executionsInFlight.Add(-1)
+		if gauge != nil {
+			gauge.AddExecutionsInFlight(-1)
+		}
+	}
+}
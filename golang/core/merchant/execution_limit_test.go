@@ -0,0 +1,203 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// gatedService holds every paid execution until open is closed and records
+// how many ran at once.
+type gatedService struct {
+	open    chan struct{}
+	mu      sync.Mutex
+	running int
+	peak    int
+}
+
+func (s *gatedService) Execute(ctx context.Context, request business.Request) (*business.Result, error) {
+	if !request.PaymentVerified {
+		return (&mockBusinessService{}).Execute(ctx, request)
+	}
+	s.mu.Lock()
+	s.running++
+	s.peak = max(s.peak, s.running)
+	s.mu.Unlock()
+	<-s.open
+	s.mu.Lock()
+	s.running--
+	s.mu.Unlock()
+	return &business.Result{Message: "rendered"}, nil
+}
+
+func waitExecutionsInFlight(t *testing.T, o *BusinessOrchestrator, want int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for o.ExecutionsInFlight() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("ExecutionsInFlight() = %d, want %d", o.ExecutionsInFlight(), want)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestWithMaxConcurrentExecutions(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy SettlementPolicy
+	}{
+		{name: "execute then settle", policy: ExecuteThenSettle},
+		{name: "settle then execute", policy: SettleThenExecute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var settled atomic.Int32
+			service := &gatedService{open: make(chan struct{})}
+			metrics := NewExpvarMetrics()
+			o := newTestOrchestrator(
+				t,
+				&MockResourceServer{
+					SettlePaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.SettleResponse, error) {
+						settled.Add(1)
+						return &x402core.SettleResponse{Success: true, Network: x402.NetworkBaseSepolia, Transaction: "0xtx"}, nil
+					},
+				},
+				service,
+				recoveryNetworks,
+				WithSettlementPolicy(tt.policy),
+				WithMetrics(metrics),
+				WithMaxConcurrentExecutions(2),
+				WithExecutionQueueTimeout(50*time.Millisecond),
+			)
+
+			var tasks []*a2a.Task
+			var wg sync.WaitGroup
+			pay := func(taskID a2a.TaskID) {
+				task, submission := quotePayment(t, o, taskID)
+				tasks = append(tasks, task)
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					err := submitPayment(o, task, submission, &mockEventQueue{})
+					if err != nil {
+						t.Errorf("paid Execute(%s) error = %v", task.ID, err)
+					}
+				}()
+			}
+			pay("task-slot-1")
+			pay("task-slot-2")
+			waitExecutionsInFlight(t, o, 2)
+			if got := metrics.Vars().Get("business_executions_in_flight").String(); got != "2" {
+				t.Errorf("business_executions_in_flight = %s, want 2", got)
+			}
+			settledBefore := settled.Load()
+
+			// Both slots stay taken, so the third payment gives up waiting.
+			waiter, submission := quotePayment(t, o, "task-slot-waiter")
+			err := submitPayment(o, waiter, submission, &mockEventQueue{})
+			if err != nil {
+				t.Fatalf("waiting Execute() error = %v", err)
+			}
+			if waiter.Status.State != a2a.TaskStateFailed || errorCodeOf(waiter) != x402.ErrorCodeCapacityExceeded {
+				t.Errorf("waiter = %s with %q, want failed with %q", waiter.Status.State, errorCodeOf(waiter), x402.ErrorCodeCapacityExceeded)
+			}
+			if !x402.Retryable(errorCodeOf(waiter)) {
+				t.Errorf("%s is not retryable", errorCodeOf(waiter))
+			}
+			if got := settled.Load(); got != settledBefore {
+				t.Errorf("settled %d payments while the waiter timed out, want %d", got, settledBefore)
+			}
+
+			close(service.open)
+			wg.Wait()
+			for _, task := range tasks {
+				if task.Status.State != a2a.TaskStateCompleted {
+					t.Errorf("task %s state = %s, want completed", task.ID, task.Status.State)
+				}
+			}
+			if service.peak != 2 {
+				t.Errorf("peak concurrent executions = %d, want 2", service.peak)
+			}
+			if got := settled.Load(); got != 2 {
+				t.Errorf("settled %d payments, want 2", got)
+			}
+			if o.ExecutionsInFlight() != 0 || metrics.Vars().Get("business_executions_in_flight").String() != "0" {
+				t.Errorf("executions still in flight after all finished")
+			}
+
+			// Freed slots are available to the next payment.
+			next := payTask(t, o, "task-slot-next")
+			if next.Status.State != a2a.TaskStateCompleted {
+				t.Errorf("next task state = %s, want completed", next.Status.State)
+			}
+		})
+	}
+}
+
+func TestWithMaxConcurrentExecutions_TrustedRequestsShareSlots(t *testing.T) {
+	keys := HMACKeySet{"k1": []byte("internal-secret")}
+	credential, err := keys.IssueCredential("k1", "billing-service", time.Time{})
+	if err != nil {
+		t.Fatalf("IssueCredential() error = %v", err)
+	}
+	service := &gatedService{open: make(chan struct{})}
+	o := newTestOrchestrator(
+		t,
+		&MockResourceServer{},
+		service,
+		recoveryNetworks,
+		WithTrustPolicy(CredentialTrustPolicy{Verifier: keys}),
+		WithMaxConcurrentExecutions(1),
+		WithExecutionQueueTimeout(50*time.Millisecond),
+	)
+
+	task, submission := quotePayment(t, o, "task-slot-paid")
+	paid := make(chan error, 1)
+	go func() { paid <- submitPayment(o, task, submission, &mockEventQueue{}) }()
+	waitExecutionsInFlight(t, o, 1)
+
+	// The paid execution holds the only slot, so the trusted one gives up.
+	message := a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "render"})
+	x402state.SetTrustCredential(message, credential)
+	requestContext := &a2asrv.RequestContext{Message: message, TaskID: "task-slot-trusted", ContextID: "context-slot-trusted"}
+	if err := o.executeInPlace(context.Background(), requestContext, &mockEventQueue{}); err != nil {
+		t.Fatalf("trusted Execute() error = %v", err)
+	}
+	trusted := requestContext.StoredTask
+	if trusted.Status.State != a2a.TaskStateFailed || errorCodeOf(trusted) != x402.ErrorCodeCapacityExceeded {
+		t.Errorf("trusted task = %s with %q, want failed with %q", trusted.Status.State, errorCodeOf(trusted), x402.ErrorCodeCapacityExceeded)
+	}
+
+	close(service.open)
+	if err := <-paid; err != nil {
+		t.Fatalf("paid Execute() error = %v", err)
+	}
+	if service.peak != 1 {
+		t.Errorf("peak concurrent executions = %d, want 1", service.peak)
+	}
+}
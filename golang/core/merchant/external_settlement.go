@@ -0,0 +1,241 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/a2aproject/a2a-go/a2asrv/eventqueue"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// ReceiptExtraSettlement marks the placeholder receipt of a payment left to an
+// external settlement process, with the value SettlementExternalPending.
+const (
+	ReceiptExtraSettlement    = "settlement"
+	SettlementExternalPending = "external/pending"
+)
+
+// SettlementMode decides who settles verified payments.
+type SettlementMode int
+
+const (
+	// SettlementInternal settles every payment through the facilitator.
+	SettlementInternal SettlementMode = iota
+	// SettlementExternal verifies payments and delivers, but never settles:
+	// each payment is handed to a SettlementExporter for an external
+	// process, such as a nightly treasury batch, to settle.
+	SettlementExternal
+)
+
+// SettlementConfig configures who settles payments.
+type SettlementConfig struct {
+	Mode SettlementMode
+	// Exporter receives every verified payment in SettlementExternal mode.
+	// It is required in that mode.
+	Exporter SettlementExporter
+	// Tasks, when set, is the server's task store, where
+	// Merchant.RecordExternalSettlement back-fills the real receipt.
+	Tasks a2asrv.TaskStore
+}
+
+// SettlementExporter hands verified payments to an external settlement
+// process. The PendingSettlement carries the full payload and the matched
+// requirement, which is all a facilitator needs to settle it.
+// Implementations must be safe for concurrent use.
+type SettlementExporter interface {
+	ExportSettlement(ctx context.Context, pending *PendingSettlement) error
+}
+
+// SettlementExporterFunc adapts a function to the SettlementExporter
+// interface.
+type SettlementExporterFunc func(ctx context.Context, pending *PendingSettlement) error
+
+func (f SettlementExporterFunc) ExportSettlement(ctx context.Context, pending *PendingSettlement) error {
+	return f(ctx, pending)
+}
+
+// JSONLinesExporter writes each exported payment to W as one line of JSON,
+// e.g. to a file collected by the settlement batch.
+type JSONLinesExporter struct {
+	mu sync.Mutex
+	W  io.Writer
+}
+
+func (e *JSONLinesExporter) ExportSettlement(ctx context.Context, pending *PendingSettlement) error {
+	line, err := json.Marshal(pending)
+	if err != nil {
+		return fmt.Errorf("failed to encode settlement: %w", err)
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, err := e.W.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write settlement: %w", err)
+	}
+	return nil
+}
+
+// WithSettlement selects who settles payments. The default settles through
+// the facilitator. SettlementExternal completes each task after the business
+// logic succeeds, with a placeholder receipt marked SettlementExternalPending,
+// and cannot be combined with settlement before execution, deferred
+// execution, escrow, or asynchronous and batch settlement.
+func WithSettlement(config SettlementConfig) Option {
+	return func(o *BusinessOrchestrator) {
+		o.settlement = config
+	}
+}
+
+// validateSettlement rejects external settlement without an exporter or
+// alongside options that would settle the payment themselves.
+func (o *BusinessOrchestrator) validateSettlement() error {
+	if o.settlement.Mode != SettlementExternal {
+		return nil
+	}
+	if o.settlement.Exporter == nil {
+		return errors.New("external settlement requires a settlement exporter")
+	}
+	var conflicts []string
+	if o.settlementPolicy == SettleThenExecute {
+		conflicts = append(conflicts, "SettleThenExecute")
+	}
+	if o.deferredExecution != nil {
+		conflicts = append(conflicts, "WithDeferredExecution")
+	}
+	if o.escrow != nil {
+		conflicts = append(conflicts, "WithEscrowPolicy")
+	}
+	if o.asyncSettlement != nil {
+		conflicts = append(conflicts, "WithAsyncSettlement")
+	}
+	if o.batchSettlement != nil {
+		conflicts = append(conflicts, "WithBatchSettlement")
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("external settlement cannot be combined with %v", conflicts)
+	}
+	return nil
+}
+
+// exportSettlement hands the payment to the exporter and returns the
+// placeholder receipt that stands in for it.
+func (o *BusinessOrchestrator) exportSettlement(
+	ctx context.Context,
+	task *a2a.Task,
+	paymentState *state.PaymentState,
+	matchedRequirement *x402types.PaymentRequirements,
+) (*x402core.SettleResponse, error) {
+	pending := &PendingSettlement{
+		TaskID:      task.ID,
+		ContextID:   task.ContextID,
+		Payer:       paymentState.Payer,
+		Payload:     paymentState.Payload,
+		Requirement: matchedRequirement,
+		QueuedAt:    o.now(),
+	}
+	if err := o.settlement.Exporter.ExportSettlement(ctx, pending); err != nil {
+		return nil, fmt.Errorf("failed to export payment for external settlement: %w", err)
+	}
+	o.logger.InfoContext(ctx, "x402 payment exported for external settlement",
+		"task_id", task.ID,
+		"context_id", task.ContextID,
+		"payer", paymentState.Payer,
+		"network", matchedRequirement.Network,
+		"amount", matchedRequirement.Amount,
+	)
+	return &x402core.SettleResponse{
+		Network: x402core.Network(matchedRequirement.Network),
+		Payer:   paymentState.Payer,
+		Amount:  matchedRequirement.Amount,
+		Extra:   map[string]interface{}{ReceiptExtraSettlement: SettlementExternalPending},
+	}, nil
+}
+
+// completeExternally exports the payment and completes the task with its
+// placeholder receipt. A result asking for another payment moves on to the
+// next round instead. The client is not charged when the export fails.
+func (o *BusinessOrchestrator) completeExternally(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	eventQueue eventqueue.Queue,
+	paymentState *state.PaymentState,
+	matchedRequirement *x402types.PaymentRequirements,
+	businessResult *business.Result,
+	meter *metering,
+) (*state.PaymentState, error) {
+	placeholder, err := o.exportSettlement(ctx, task, paymentState, matchedRequirement)
+	if err != nil {
+		return o.failPayment(ctx, requestContext, task, eventQueue, paymentState, err, x402pkg.ErrorCodeSettlementFailed, nil)
+	}
+	meter.attach(placeholder)
+	if businessResult.AdditionalPaymentRequired != nil {
+		return o.paidResult(ctx, requestContext, task, eventQueue, businessResult, placeholder)
+	}
+	if err := o.completeUnsettled(ctx, requestContext, task, eventQueue, businessResult, placeholder); err != nil {
+		return nil, fmt.Errorf("failed to complete task before settlement: %w", err)
+	}
+	return &state.PaymentState{Status: state.PaymentVerified}, nil
+}
+
+// RecordExternalSettlement back-fills the receipt of a payment the external
+// process settled, replacing the placeholder on the completed task in the
+// SettlementConfig task store. A receipt that did not succeed marks the
+// payment failed. The receipt is recorded, and hooks and webhooks fire, as
+// for a payment the merchant settled itself.
+func (o *BusinessOrchestrator) RecordExternalSettlement(ctx context.Context, taskID a2a.TaskID, receipt *x402core.SettleResponse) error {
+	if o.settlement.Mode != SettlementExternal {
+		return errors.New("merchant does not settle externally")
+	}
+	if o.settlement.Tasks == nil {
+		return errors.New("external settlement has no task store to record the receipt in")
+	}
+	if receipt == nil {
+		return errors.New("settlement receipt is required")
+	}
+	var code string
+	var settleErr error
+	if !receipt.Success {
+		code = settlementErrorCode(receipt, nil)
+		settleErr = fmt.Errorf("external settlement failed: %s", receipt.ErrorReason)
+	}
+	task, err := o.backfillReceipt(ctx, o.settlement.Tasks, taskID, receipt, code, settleErr)
+	if err != nil {
+		return fmt.Errorf("failed to record external settlement for task %s: %w", taskID, err)
+	}
+
+	if settleErr != nil {
+		o.logFailed(ctx, task, code, settleErr)
+		o.hooks.failed(ctx, task, code, settleErr)
+		o.notifyWebhook(ctx, WebhookPaymentFailed, task, []*x402core.SettleResponse{receipt}, code, settleErr)
+		return nil
+	}
+	o.logSettled(ctx, task, receipt)
+	o.hooks.settled(ctx, task, receipt)
+	o.recordReceipt(ctx, task, "", nil, receipt)
+	o.notifyWebhook(ctx, WebhookPaymentSettled, task, []*x402core.SettleResponse{receipt}, "", nil)
+	return nil
+}
@@ -0,0 +1,215 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// externalHarness fails the test on any settlement and collects exports.
+type externalHarness struct {
+	mu       sync.Mutex
+	exported []*PendingSettlement
+}
+
+func (h *externalHarness) orchestrator(t *testing.T, config SettlementConfig, opts ...Option) *BusinessOrchestrator {
+	if config.Exporter == nil {
+		config.Exporter = SettlementExporterFunc(func(ctx context.Context, pending *PendingSettlement) error {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			h.exported = append(h.exported, pending)
+			return nil
+		})
+	}
+	config.Mode = SettlementExternal
+	return newTestOrchestrator(
+		t,
+		&MockResourceServer{
+			SettlePaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.SettleResponse, error) {
+				t.Errorf("SettlePayment called for %v in external settlement mode", payload.Payload["signature"])
+				return &x402core.SettleResponse{Success: true}, nil
+			},
+		},
+		&mockBusinessService{},
+		[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+		append(opts, WithSettlement(config))...,
+	)
+}
+
+func TestExternalSettlement_ExportsInsteadOfSettling(t *testing.T) {
+	harness := &externalHarness{}
+	orchestrator := harness.orchestrator(t, SettlementConfig{})
+
+	for _, taskID := range []a2a.TaskID{"task-external-1", "task-external-2"} {
+		task := payTask(t, orchestrator, taskID)
+		if task.Status.State != a2a.TaskStateCompleted {
+			t.Fatalf("%s state = %s, want completed", taskID, task.Status.State)
+		}
+		if status, _ := x402state.ExtractPaymentStatus(task); status != x402state.PaymentVerified {
+			t.Errorf("%s payment status = %q, want %q", taskID, status, x402state.PaymentVerified)
+		}
+		receipts, err := x402state.ExtractPaymentReceipts(task)
+		if err != nil || len(receipts) != 1 || receipts[0].Extra[ReceiptExtraSettlement] != SettlementExternalPending {
+			t.Fatalf("%s receipts = %+v, %v, want one external/pending placeholder", taskID, receipts, err)
+		}
+	}
+
+	if len(harness.exported) != 2 {
+		t.Fatalf("exported %d payments, want one per completed task", len(harness.exported))
+	}
+	for i, taskID := range []a2a.TaskID{"task-external-1", "task-external-2"} {
+		pending := harness.exported[i]
+		if pending.TaskID != taskID {
+			t.Errorf("export %d task = %s, want %s", i, pending.TaskID, taskID)
+		}
+		if pending.Payload == nil || pending.Payload.Payload["signature"] != "0x"+string(taskID) {
+			t.Errorf("export %d payload = %+v, want the submitted payload", i, pending.Payload)
+		}
+		if pending.Requirement == nil || pending.Requirement.PayTo != "0x123" || pending.Requirement.Network != x402.NetworkBaseSepolia {
+			t.Errorf("export %d requirement = %+v, want the matched requirement", i, pending.Requirement)
+		}
+	}
+}
+
+func TestExternalSettlement_ExportFailureDoesNotCharge(t *testing.T) {
+	harness := &externalHarness{}
+	orchestrator := harness.orchestrator(t, SettlementConfig{
+		Exporter: SettlementExporterFunc(func(ctx context.Context, pending *PendingSettlement) error {
+			return context.DeadlineExceeded
+		}),
+	})
+
+	task := payTask(t, orchestrator, "task-export-fails")
+	if task.Status.State != a2a.TaskStateFailed {
+		t.Fatalf("task state = %s, want failed", task.Status.State)
+	}
+	if got := task.Status.Message.Metadata[x402.MetadataKeyError]; got != x402.ErrorCodeSettlementFailed {
+		t.Errorf("error code = %v, want %s", got, x402.ErrorCodeSettlementFailed)
+	}
+}
+
+func TestExternalSettlement_RecordBackfillsReceipt(t *testing.T) {
+	harness := &externalHarness{}
+	tasks := &memoryTaskStore{}
+	receiptStore := NewMemoryReceiptStore()
+	m := &Merchant{orchestrator: harness.orchestrator(t, SettlementConfig{Tasks: tasks}, WithReceiptStore(receiptStore))}
+	ctx := context.Background()
+
+	task := payTask(t, m.orchestrator, "task-backfill-external")
+	if _, err := tasks.Save(ctx, task, nil, 0); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	receipt := &x402core.SettleResponse{
+		Success:     true,
+		Transaction: "0xnightly",
+		Network:     x402.NetworkBaseSepolia,
+		Payer:       "0x789",
+		Amount:      harness.exported[0].Requirement.Amount,
+	}
+	if err := m.RecordExternalSettlement(ctx, task.ID, receipt); err != nil {
+		t.Fatalf("RecordExternalSettlement() error = %v", err)
+	}
+
+	stored, _, err := tasks.Get(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if status, _ := x402state.ExtractPaymentStatus(stored); status != x402state.PaymentCompleted {
+		t.Errorf("payment status = %q, want %q", status, x402state.PaymentCompleted)
+	}
+	receipts, err := x402state.ExtractPaymentReceipts(stored)
+	if err != nil || len(receipts) != 1 || receipts[0].Transaction != "0xnightly" || receipts[0].Extra[ReceiptExtraSettlement] != nil {
+		t.Fatalf("receipts = %+v, %v, want the external receipt only", receipts, err)
+	}
+	if records, _ := receiptStore.GetByTask(ctx, task.ID); len(records) != 1 || records[0].Transaction != "0xnightly" {
+		t.Errorf("receipt store records = %+v, want the external receipt", records)
+	}
+
+	if err := m.RecordExternalSettlement(ctx, task.ID, receipt); err == nil {
+		t.Error("second RecordExternalSettlement() succeeded, want an error for a task already settled")
+	}
+}
+
+func TestJSONLinesExporter(t *testing.T) {
+	var buf bytes.Buffer
+	exporter := &JSONLinesExporter{W: &buf}
+	for _, taskID := range []a2a.TaskID{"task-1", "task-2"} {
+		if err := exporter.ExportSettlement(context.Background(), &PendingSettlement{TaskID: taskID}); err != nil {
+			t.Fatalf("ExportSettlement() error = %v", err)
+		}
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("wrote %d lines, want 2: %q", len(lines), buf.String())
+	}
+	var decoded PendingSettlement
+	if err := json.Unmarshal([]byte(lines[1]), &decoded); err != nil || decoded.TaskID != "task-2" {
+		t.Errorf("second line = %q (%v), want task-2", lines[1], err)
+	}
+}
+
+func TestWithSettlement_RejectsInvalidExternalConfig(t *testing.T) {
+	exporter := SettlementExporterFunc(func(ctx context.Context, pending *PendingSettlement) error { return nil })
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr string
+	}{
+		{
+			name:    "no exporter",
+			opts:    []Option{WithSettlement(SettlementConfig{Mode: SettlementExternal})},
+			wantErr: "requires a settlement exporter",
+		},
+		{
+			name: "settles before execution",
+			opts: []Option{
+				WithSettlement(SettlementConfig{Mode: SettlementExternal, Exporter: exporter}),
+				WithSettlementPolicy(SettleThenExecute),
+			},
+			wantErr: "SettleThenExecute",
+		},
+		{
+			name: "batch settlement",
+			opts: []Option{
+				WithSettlement(SettlementConfig{Mode: SettlementExternal, Exporter: exporter}),
+				WithBatchSettlement(BatchSettlementConfig{}),
+			},
+			wantErr: "WithBatchSettlement",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewBusinessOrchestrator(context.Background(), &mockBusinessService{},
+				[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+				append([]Option{WithPaymentServer(&MockResourceServer{})}, tt.opts...)...)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("NewBusinessOrchestrator() error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
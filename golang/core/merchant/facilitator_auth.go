@@ -0,0 +1,295 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	x402http "github.com/x402-foundation/x402/go/http"
+)
+
+// envPrefix marks a FacilitatorOptions value as the name of an environment
+// variable, e.g. "env:CDP_API_KEY_SECRET".
+const envPrefix = "env:"
+
+// cdpJWTLifetime is how long a signed CDP request token stays valid.
+const cdpJWTLifetime = 2 * time.Minute
+
+// FacilitatorOptions configures how the merchant talks to its facilitator.
+// Any string field may be an "env:NAME" reference, resolved when the resource
+// server is created.
+type FacilitatorOptions struct {
+	// APIKeyID and APIKeySecret sign a short-lived CDP JWT for every request,
+	// as required by the Coinbase facilitator. The secret is either an EC
+	// private key in PEM form or a base64 Ed25519 key.
+	APIKeyID     string
+	APIKeySecret string
+	// BearerToken is sent as a static Authorization header.
+	BearerToken string
+	// Headers are added to every facilitator request.
+	Headers map[string]string
+
+	// HTTPClient replaces the SDK's default client, e.g. to set a proxy or
+	// connection pool limits.
+	HTTPClient *http.Client
+	// VerifyTimeout and SettleTimeout bound each facilitator call. Settlement
+	// usually needs the larger budget since it waits for the chain. Zero
+	// selects DefaultVerifyTimeout or DefaultSettleTimeout; a negative value
+	// leaves the call bounded only by the request context.
+	VerifyTimeout time.Duration
+	SettleTimeout time.Duration
+	// PingTimeout bounds each facilitator health check. Zero selects
+	// DefaultPingTimeout; a negative value leaves the check bounded only by
+	// the caller's context.
+	PingTimeout time.Duration
+}
+
+func (f FacilitatorOptions) verifyTimeout() time.Duration {
+	if f.VerifyTimeout == 0 {
+		return DefaultVerifyTimeout
+	}
+	return f.VerifyTimeout
+}
+
+func (f FacilitatorOptions) settleTimeout() time.Duration {
+	if f.SettleTimeout == 0 {
+		return DefaultSettleTimeout
+	}
+	return f.SettleTimeout
+}
+
+func (f FacilitatorOptions) pingTimeout() time.Duration {
+	if f.PingTimeout == 0 {
+		return DefaultPingTimeout
+	}
+	return f.PingTimeout
+}
+
+// WithFacilitatorOptions sets the facilitator authentication used by
+// NewBusinessOrchestrator and NewMerchant.
+func WithFacilitatorOptions(facilitatorOptions FacilitatorOptions) Option {
+	return func(o *BusinessOrchestrator) {
+		o.facilitatorOptions = facilitatorOptions
+	}
+}
+
+// authProvider validates the options and returns nil when no authentication
+// is configured.
+func (f FacilitatorOptions) authProvider(facilitatorURL string) (x402http.AuthProvider, error) {
+	keyID, err := resolveEnv(f.APIKeyID)
+	if err != nil {
+		return nil, fmt.Errorf("api key id: %w", err)
+	}
+	keySecret, err := resolveEnv(f.APIKeySecret)
+	if err != nil {
+		return nil, fmt.Errorf("api key secret: %w", err)
+	}
+	bearerToken, err := resolveEnv(f.BearerToken)
+	if err != nil {
+		return nil, fmt.Errorf("bearer token: %w", err)
+	}
+	headers := make(map[string]string, len(f.Headers))
+	for name, value := range f.Headers {
+		if headers[name], err = resolveEnv(value); err != nil {
+			return nil, fmt.Errorf("header %s: %w", name, err)
+		}
+	}
+
+	if (keyID == "") != (keySecret == "") {
+		return nil, errors.New("api key id and secret must be set together")
+	}
+	if keyID != "" && bearerToken != "" {
+		return nil, errors.New("api key and bearer token are mutually exclusive")
+	}
+	if bearerToken != "" {
+		headers["Authorization"] = "Bearer " + bearerToken
+	}
+
+	provider := &facilitatorAuth{headers: headers}
+	if keyID != "" {
+		signer, err := newCDPSigner(keyID, keySecret, facilitatorURL)
+		if err != nil {
+			return nil, err
+		}
+		provider.cdp = signer
+	}
+	if provider.cdp == nil && len(headers) == 0 {
+		return nil, nil
+	}
+	return provider, nil
+}
+
+func resolveEnv(value string) (string, error) {
+	name, ok := strings.CutPrefix(value, envPrefix)
+	if !ok {
+		return value, nil
+	}
+	resolved := os.Getenv(name)
+	if resolved == "" {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return resolved, nil
+}
+
+type facilitatorAuth struct {
+	headers map[string]string
+	cdp     *cdpSigner
+}
+
+func (a *facilitatorAuth) GetAuthHeaders(ctx context.Context) (x402http.AuthHeaders, error) {
+	endpoints := map[string]map[string]string{}
+	for _, endpoint := range []string{"GET /supported", "POST /verify", "POST /settle"} {
+		headers := make(map[string]string, len(a.headers)+1)
+		for name, value := range a.headers {
+			headers[name] = value
+		}
+		if a.cdp != nil {
+			method, path, _ := strings.Cut(endpoint, " ")
+			token, err := a.cdp.sign(method, path)
+			if err != nil {
+				return x402http.AuthHeaders{}, err
+			}
+			headers["Authorization"] = "Bearer " + token
+		}
+		endpoints[endpoint] = headers
+	}
+	return x402http.AuthHeaders{
+		Supported: endpoints["GET /supported"],
+		Verify:    endpoints["POST /verify"],
+		Settle:    endpoints["POST /settle"],
+	}, nil
+}
+
+// cdpSigner issues the per-request JWTs the CDP API expects.
+type cdpSigner struct {
+	keyID     string
+	key       crypto.Signer
+	algorithm string
+	host      string
+	basePath  string
+}
+
+func newCDPSigner(keyID, secret, facilitatorURL string) (*cdpSigner, error) {
+	parsed, err := url.Parse(facilitatorURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid facilitator URL: %w", err)
+	}
+	signer := &cdpSigner{
+		keyID:    keyID,
+		host:     parsed.Host,
+		basePath: strings.TrimSuffix(parsed.Path, "/"),
+	}
+
+	if block, _ := pem.Decode([]byte(secret)); block != nil {
+		key, err := parseECKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid api key secret: %w", err)
+		}
+		signer.key, signer.algorithm = key, "ES256"
+		return signer, nil
+	}
+	raw, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil || len(raw) != ed25519.PrivateKeySize {
+		return nil, errors.New("invalid api key secret: expected a PEM EC key or a base64 Ed25519 key")
+	}
+	signer.key, signer.algorithm = ed25519.PrivateKey(raw), "EdDSA"
+	return signer, nil
+}
+
+func parseECKey(der []byte) (*ecdsa.PrivateKey, error) {
+	if key, err := x509.ParseECPrivateKey(der); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	ecKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("expected an EC key, got %T", key)
+	}
+	return ecKey, nil
+}
+
+func (s *cdpSigner) sign(method, path string) (string, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	now := time.Now().Unix()
+	header := map[string]any{
+		"alg":   s.algorithm,
+		"kid":   s.keyID,
+		"typ":   "JWT",
+		"nonce": hex.EncodeToString(nonce),
+	}
+	claims := map[string]any{
+		"sub":  s.keyID,
+		"iss":  "cdp",
+		"nbf":  now,
+		"exp":  now + int64(cdpJWTLifetime/time.Second),
+		"uris": []string{fmt.Sprintf("%s %s%s%s", method, s.host, s.basePath, path)},
+	}
+	encodedHeader, err := encodeJWTPart(header)
+	if err != nil {
+		return "", err
+	}
+	encodedClaims, err := encodeJWTPart(claims)
+	if err != nil {
+		return "", err
+	}
+	signingInput := encodedHeader + "." + encodedClaims
+
+	var signature []byte
+	switch key := s.key.(type) {
+	case ed25519.PrivateKey:
+		signature = ed25519.Sign(key, []byte(signingInput))
+	case *ecdsa.PrivateKey:
+		digest := sha256.Sum256([]byte(signingInput))
+		r, sigS, err := ecdsa.Sign(rand.Reader, key, digest[:])
+		if err != nil {
+			return "", fmt.Errorf("failed to sign facilitator token: %w", err)
+		}
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		sigS.FillBytes(signature[32:])
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func encodeJWTPart(value map[string]any) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
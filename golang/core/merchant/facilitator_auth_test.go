@@ -0,0 +1,152 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+)
+
+func newAuthCheckingFacilitator(t *testing.T) (*httptest.Server, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var authHeaders []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		authHeaders = append(authHeaders, r.Header.Get("Authorization"))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"kinds":[{"x402Version":2,"scheme":"exact","network":"` + x402.NetworkBaseSepolia + `"}],"extensions":[],"signers":{}}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), authHeaders...)
+	}
+}
+
+func TestNewResourceServer_FacilitatorAuth(t *testing.T) {
+	t.Setenv("TEST_FACILITATOR_TOKEN", "secret-token")
+
+	server, headers := newAuthCheckingFacilitator(t)
+	_, err := NewResourceServer(context.Background(), server.URL, FacilitatorOptions{
+		BearerToken: "env:TEST_FACILITATOR_TOKEN",
+	})
+	if err != nil {
+		t.Fatalf("NewResourceServer() error = %v", err)
+	}
+	got := headers()
+	if len(got) == 0 {
+		t.Fatal("facilitator received no requests")
+	}
+	for _, header := range got {
+		if header != "Bearer secret-token" {
+			t.Errorf("Authorization = %q, want bearer token from the environment", header)
+		}
+	}
+}
+
+func TestNewResourceServer_CDPJWT(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+
+	server, headers := newAuthCheckingFacilitator(t)
+	_, err = NewResourceServer(context.Background(), server.URL+"/platform/v2/x402", FacilitatorOptions{
+		APIKeyID:     "organizations/org/apiKeys/key",
+		APIKeySecret: secret,
+	})
+	if err != nil {
+		t.Fatalf("NewResourceServer() error = %v", err)
+	}
+	got := headers()
+	if len(got) == 0 {
+		t.Fatal("facilitator received no requests")
+	}
+
+	token, ok := strings.CutPrefix(got[0], "Bearer ")
+	if !ok {
+		t.Fatalf("Authorization = %q, want a bearer JWT", got[0])
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("token has %d parts, want 3", len(parts))
+	}
+	claimsJSON, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	var claims struct {
+		Sub  string   `json:"sub"`
+		Iss  string   `json:"iss"`
+		URIs []string `json:"uris"`
+	}
+	if err := json.Unmarshal(claimsJSON, &claims); err != nil {
+		t.Fatalf("failed to decode claims: %v", err)
+	}
+	wantURI := "GET " + strings.TrimPrefix(server.URL, "http://") + "/platform/v2/x402/supported"
+	if claims.Sub != "organizations/org/apiKeys/key" || claims.Iss != "cdp" || len(claims.URIs) != 1 || claims.URIs[0] != wantURI {
+		t.Errorf("claims = %+v, want sub key id and uri %q", claims, wantURI)
+	}
+
+	signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	r := new(big.Int).SetBytes(signature[:32])
+	s := new(big.Int).SetBytes(signature[32:])
+	if !ecdsa.Verify(&key.PublicKey, digest[:], r, s) {
+		t.Error("JWT signature does not verify with the API key")
+	}
+}
+
+func TestFacilitatorOptions_Misconfiguration(t *testing.T) {
+	tests := []struct {
+		name    string
+		options FacilitatorOptions
+	}{
+		{name: "key without secret", options: FacilitatorOptions{APIKeyID: "key"}},
+		{name: "secret without key", options: FacilitatorOptions{APIKeySecret: "secret"}},
+		{name: "unset environment reference", options: FacilitatorOptions{BearerToken: "env:TEST_FACILITATOR_UNSET"}},
+		{name: "malformed secret", options: FacilitatorOptions{APIKeyID: "key", APIKeySecret: "not-a-key"}},
+		{name: "key and bearer token", options: FacilitatorOptions{APIKeyID: "key", APIKeySecret: "secret", BearerToken: "token"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewResourceServer(context.Background(), "http://127.0.0.1:1", tt.options)
+			if err == nil || !strings.Contains(err.Error(), "invalid facilitator options") {
+				t.Errorf("NewResourceServer() error = %v, want a construction-time options error", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DefaultPingTimeout bounds a facilitator health check, including the one
+// made at startup.
+const DefaultPingTimeout = 5 * time.Second
+
+// facilitatorPinger is implemented by payment servers that can check that
+// their facilitator is reachable.
+type facilitatorPinger interface {
+	PingFacilitator(ctx context.Context) error
+}
+
+// WithStartupPing controls whether NewBusinessOrchestrator and NewMerchant
+// check that the facilitator answers before building the resource server, so
+// a wrong URL or a down facilitator fails startup with the URL and status
+// instead of surfacing on the first payment. It is on by default and has no
+// effect with WithResourceServer or WithPaymentServer.
+func WithStartupPing(enabled bool) Option {
+	return func(o *BusinessOrchestrator) {
+		o.skipStartupPing = !enabled
+	}
+}
+
+// PingFacilitator asks the facilitator for its supported payment kinds, a
+// cheap read that needs no payment, and reports an error naming the
+// facilitator when it does not answer successfully in time.
+func (w *resourceServerWrapper) PingFacilitator(ctx context.Context) error {
+	if w.facilitator == nil {
+		return nil
+	}
+	pingCtx, cancel := withFacilitatorTimeout(ctx, w.pingTimeout)
+	defer cancel()
+	if _, err := w.facilitator.GetSupported(pingCtx); err != nil {
+		err = facilitatorTimeout(ctx, pingCtx, "ping", w.pingTimeout, err)
+		if w.facilitatorURL == "" {
+			return fmt.Errorf("facilitator health check failed: %w", err)
+		}
+		return fmt.Errorf("facilitator %s health check failed: %w", w.facilitatorURL, err)
+	}
+	return nil
+}
+
+// PingFacilitator checks that the facilitator payments go through is
+// reachable and healthy, e.g. for a liveness or readiness endpoint. It reports
+// nil when the orchestrator was given a pre-built resource server or its own
+// payment server, whose facilitator it cannot see.
+func (o *BusinessOrchestrator) PingFacilitator(ctx context.Context) error {
+	pinger, ok := o.merchant.(facilitatorPinger)
+	if !ok {
+		return nil
+	}
+	return pinger.PingFacilitator(ctx)
+}
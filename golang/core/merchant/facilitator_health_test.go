@@ -0,0 +1,95 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google-agentic-commerce/a2a-x402/core/testutil/facilitator"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+)
+
+func TestNewMerchant_StartupPing(t *testing.T) {
+	ctx := context.Background()
+	configs := []types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: evmPayTo}}
+
+	t.Run("healthy", func(t *testing.T) {
+		mock := facilitator.NewMockFacilitator(t, facilitator.Options{})
+
+		m, err := NewMerchant(ctx, mock.URL, &mockBusinessService{}, configs)
+		if err != nil {
+			t.Fatalf("NewMerchant() error = %v", err)
+		}
+		if err := m.PingFacilitator(ctx); err != nil {
+			t.Fatalf("PingFacilitator() error = %v", err)
+		}
+
+		mock.Update(func(o *facilitator.Options) { o.SupportedStatus = http.StatusNotFound })
+		err = m.PingFacilitator(ctx)
+		if err == nil || !strings.Contains(err.Error(), mock.URL) || !strings.Contains(err.Error(), "404") {
+			t.Errorf("PingFacilitator() after outage error = %v, want the URL and status", err)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mock := facilitator.NewMockFacilitator(t, facilitator.Options{SupportedStatus: http.StatusNotFound})
+
+		_, err := NewMerchant(ctx, mock.URL, &mockBusinessService{}, configs)
+		if err == nil || !strings.Contains(err.Error(), mock.URL) || !strings.Contains(err.Error(), "404") {
+			t.Fatalf("NewMerchant() error = %v, want the URL and status", err)
+		}
+		if got := mock.SupportedCalls(); got != 1 {
+			t.Errorf("facilitator requests = %d, want only the ping", got)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		mock := facilitator.NewMockFacilitator(t, facilitator.Options{Latency: time.Minute})
+
+		start := time.Now()
+		_, err := NewMerchant(ctx, mock.URL, &mockBusinessService{}, configs,
+			WithFacilitatorOptions(FacilitatorOptions{PingTimeout: 50 * time.Millisecond}))
+		if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), mock.URL) {
+			t.Fatalf("NewMerchant() error = %v, want a timed-out ping naming the URL", err)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("NewMerchant() took %s, want the ping timeout to bound it", elapsed)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		mock := facilitator.NewMockFacilitator(t, facilitator.Options{})
+
+		if _, err := NewMerchant(ctx, mock.URL, &mockBusinessService{}, configs, WithStartupPing(false)); err != nil {
+			t.Fatalf("NewMerchant() error = %v", err)
+		}
+		if got := mock.SupportedCalls(); got != 1 {
+			t.Errorf("facilitator requests = %d, want only the resource server's", got)
+		}
+	})
+}
+
+func TestBusinessOrchestrator_PingFacilitatorWithoutFacilitator(t *testing.T) {
+	orchestrator := newNetworkMatchingOrchestrator(t)
+	if err := orchestrator.PingFacilitator(context.Background()); err != nil {
+		t.Errorf("PingFacilitator() error = %v, want nil for a payment server without a facilitator", err)
+	}
+}
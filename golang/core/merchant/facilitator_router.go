@@ -0,0 +1,176 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402 "github.com/x402-foundation/x402/go"
+)
+
+// FacilitatorRouter is a facilitator client that sends each payment to the
+// facilitator configured for its network, and the rest to a default one. It
+// advertises only the payment kinds each facilitator supports for the
+// networks routed to it.
+type FacilitatorRouter struct {
+	byNetwork map[string]x402.FacilitatorClient
+	fallback  x402.FacilitatorClient
+}
+
+var _ x402.FacilitatorClient = (*FacilitatorRouter)(nil)
+
+// NewFacilitatorRouter routes payments on the networks in byNetwork to their
+// facilitator and everything else to fallback, which may be nil when every
+// network the merchant quotes on is listed.
+func NewFacilitatorRouter(byNetwork map[string]x402.FacilitatorClient, fallback x402.FacilitatorClient) (*FacilitatorRouter, error) {
+	routes := make(map[string]x402.FacilitatorClient, len(byNetwork))
+	for network, client := range byNetwork {
+		if client == nil {
+			return nil, fmt.Errorf("facilitator for network %s is nil", network)
+		}
+		routes[x402pkg.NormalizeNetwork(network)] = client
+	}
+	if len(routes) == 0 && fallback == nil {
+		return nil, fmt.Errorf("at least one facilitator is required")
+	}
+	return &FacilitatorRouter{byNetwork: routes, fallback: fallback}, nil
+}
+
+// WithNetworkFacilitators verifies and settles payments on the networks in
+// urls through the facilitator at the network's URL, and payments on other
+// networks through the facilitator at WithFacilitatorURL or
+// WithFacilitatorClient. Every facilitator shares the FacilitatorOptions.
+// NewBusinessOrchestrator fails when a configured network is left without a
+// facilitator.
+func WithNetworkFacilitators(urls map[string]string) Option {
+	return func(o *BusinessOrchestrator) {
+		o.networkFacilitators = urls
+	}
+}
+
+// newNetworkFacilitatorRouter builds the router for WithNetworkFacilitators
+// around HTTP clients for each URL.
+func newNetworkFacilitatorRouter(urls map[string]string, fallback x402.FacilitatorClient, facilitatorOptions FacilitatorOptions) (*FacilitatorRouter, error) {
+	clients := make(map[string]x402.FacilitatorClient, len(urls))
+	byURL := make(map[string]x402.FacilitatorClient)
+	for network, url := range urls {
+		client, ok := byURL[url]
+		if !ok {
+			httpClient, err := newHTTPFacilitatorClient(url, facilitatorOptions)
+			if err != nil {
+				return nil, fmt.Errorf("facilitator for network %s: %w", network, err)
+			}
+			client = httpClient
+			byURL[url] = client
+		}
+		clients[network] = client
+	}
+	return NewFacilitatorRouter(clients, fallback)
+}
+
+// checkNetworks reports the networks no facilitator is routed for.
+func (r *FacilitatorRouter) checkNetworks(networks []string) error {
+	var missing []string
+	for _, network := range networks {
+		if _, err := r.facilitatorFor(x402pkg.NormalizeNetwork(network)); err != nil {
+			missing = append(missing, network)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("no facilitator configured for networks %v and no default facilitator", missing)
+	}
+	return nil
+}
+
+func (r *FacilitatorRouter) facilitatorFor(network string) (x402.FacilitatorClient, error) {
+	if client, ok := r.byNetwork[network]; ok {
+		return client, nil
+	}
+	if r.fallback == nil {
+		return nil, fmt.Errorf("no facilitator configured for network %s", network)
+	}
+	return r.fallback, nil
+}
+
+// route picks the facilitator for the network the requirements name.
+func (r *FacilitatorRouter) route(requirementsBytes []byte) (x402.FacilitatorClient, error) {
+	var requirements struct {
+		Network string `json:"network"`
+	}
+	if err := json.Unmarshal(requirementsBytes, &requirements); err != nil {
+		return nil, fmt.Errorf("failed to read payment requirements network: %w", err)
+	}
+	return r.facilitatorFor(x402pkg.NormalizeNetwork(requirements.Network))
+}
+
+func (r *FacilitatorRouter) Verify(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.VerifyResponse, error) {
+	client, err := r.route(requirementsBytes)
+	if err != nil {
+		return nil, err
+	}
+	return client.Verify(ctx, payloadBytes, requirementsBytes)
+}
+
+func (r *FacilitatorRouter) Settle(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error) {
+	client, err := r.route(requirementsBytes)
+	if err != nil {
+		return nil, err
+	}
+	return client.Settle(ctx, payloadBytes, requirementsBytes)
+}
+
+// GetSupported merges the kinds each facilitator supports on the networks
+// routed to it. It fails if any facilitator does not answer.
+func (r *FacilitatorRouter) GetSupported(ctx context.Context) (x402.SupportedResponse, error) {
+	clients := make([]x402.FacilitatorClient, 0, len(r.byNetwork)+1)
+	for _, client := range r.byNetwork {
+		if !slices.Contains(clients, client) {
+			clients = append(clients, client)
+		}
+	}
+	if r.fallback != nil && !slices.Contains(clients, r.fallback) {
+		clients = append(clients, r.fallback)
+	}
+
+	merged := x402.SupportedResponse{Extensions: []string{}, Signers: map[string][]string{}}
+	for _, client := range clients {
+		supported, err := client.GetSupported(ctx)
+		if err != nil {
+			return x402.SupportedResponse{}, err
+		}
+		for _, kind := range supported.Kinds {
+			if routed, err := r.facilitatorFor(x402pkg.NormalizeNetwork(string(kind.Network))); err == nil && routed == client {
+				merged.Kinds = append(merged.Kinds, kind)
+			}
+		}
+		for _, extension := range supported.Extensions {
+			if !slices.Contains(merged.Extensions, extension) {
+				merged.Extensions = append(merged.Extensions, extension)
+			}
+		}
+		for family, signers := range supported.Signers {
+			for _, signer := range signers {
+				if !slices.Contains(merged.Signers[family], signer) {
+					merged.Signers[family] = append(merged.Signers[family], signer)
+				}
+			}
+		}
+	}
+	return merged, nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/testutil/facilitator"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+func TestNewMerchant_NetworkFacilitators(t *testing.T) {
+	ctx := context.Background()
+	baseFacilitator := facilitator.NewMockFacilitator(t, facilitator.Options{})
+	solanaFacilitator := facilitator.NewMockFacilitator(t, facilitator.Options{Kinds: []x402core.SupportedKind{{
+		X402Version: x402.X402Version,
+		Scheme:      x402.SchemeExact,
+		Network:     x402.NetworkSolanaDevnet,
+		Extra:       map[string]interface{}{"feePayer": solanaPayTo},
+	}}})
+	configs := []types.NetworkConfig{
+		{NetworkName: x402.NetworkBaseSepolia, PayToAddress: evmPayTo},
+		{NetworkName: x402.NetworkSolanaDevnet, PayToAddress: solanaPayTo},
+	}
+	m, err := NewMerchant(ctx, "", &mockBusinessService{}, configs, WithNetworkFacilitators(map[string]string{
+		x402.NetworkBaseSepolia:  baseFacilitator.URL,
+		x402.NetworkSolanaDevnet: solanaFacilitator.URL,
+	}))
+	if err != nil {
+		t.Fatalf("NewMerchant() error = %v", err)
+	}
+	orchestrator := m.orchestrator
+	orchestrator.extensionChecker = newMockExtensionCheckerWithX402()
+
+	for _, network := range []string{x402.NetworkBaseSepolia, x402.NetworkSolanaDevnet} {
+		task := quoteRequest(t, orchestrator, a2a.TaskID("task-"+network), a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "buy"}))
+		requirements, err := x402state.ExtractPaymentRequirements(task)
+		if err != nil {
+			t.Fatalf("ExtractPaymentRequirements() error = %v", err)
+		}
+		var accepted *x402types.PaymentRequirements
+		for i := range requirements.Accepts {
+			if requirements.Accepts[i].Network == network {
+				accepted = &requirements.Accepts[i]
+			}
+		}
+		if accepted == nil {
+			t.Fatalf("quote has no requirement on %s: %+v", network, requirements.Accepts)
+		}
+		payQuotedTask(t, orchestrator, task, func(payload *x402types.PaymentPayload) {
+			payload.Accepted = *accepted
+		})
+		if task.Status.State != a2a.TaskStateCompleted {
+			t.Fatalf("%s task state = %v, want completed: %s", network, task.Status.State, x402state.ExtractMessageText(task.Status.Message))
+		}
+	}
+
+	for name, tt := range map[string]struct {
+		mock    *facilitator.MockFacilitator
+		network string
+	}{
+		"base":   {mock: baseFacilitator, network: x402.NetworkBaseSepolia},
+		"solana": {mock: solanaFacilitator, network: x402.NetworkSolanaDevnet},
+	} {
+		verified, settled := tt.mock.Verified(), tt.mock.Settled()
+		if len(verified) != 1 || verified[0].Requirements.Network != tt.network {
+			t.Errorf("%s facilitator verified %+v, want one payment on %s", name, verified, tt.network)
+		}
+		if len(settled) != 1 || settled[0].Requirements.Network != tt.network {
+			t.Errorf("%s facilitator settled %+v, want one payment on %s", name, settled, tt.network)
+		}
+	}
+}
+
+func TestNewMerchant_NetworkFacilitatorsMissingNetwork(t *testing.T) {
+	solanaFacilitator := facilitator.NewMockFacilitator(t, facilitator.Options{})
+	_, err := NewMerchant(context.Background(), "", &mockBusinessService{},
+		[]types.NetworkConfig{
+			{NetworkName: x402.NetworkBaseSepolia, PayToAddress: evmPayTo},
+			{NetworkName: x402.NetworkSolanaDevnet, PayToAddress: solanaPayTo},
+		},
+		WithNetworkFacilitators(map[string]string{x402.NetworkSolanaDevnet: solanaFacilitator.URL}),
+	)
+	if err == nil || !strings.Contains(err.Error(), x402.NetworkBaseSepolia) {
+		t.Fatalf("NewMerchant() error = %v, want one naming %s", err, x402.NetworkBaseSepolia)
+	}
+	if calls := solanaFacilitator.SupportedCalls(); calls != 0 {
+		t.Errorf("facilitator was asked for supported kinds %d times before the configuration was rejected", calls)
+	}
+}
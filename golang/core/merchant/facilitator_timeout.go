@@ -0,0 +1,77 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+)
+
+// Default budgets for facilitator calls. The a2a server's context can be
+// effectively unbounded for non-streaming requests, so without them a hung
+// facilitator would hang the task.
+const (
+	DefaultVerifyTimeout = 15 * time.Second
+	DefaultSettleTimeout = 90 * time.Second
+)
+
+// facilitatorTimeoutError reports a facilitator call that ran past its
+// configured budget. It matches context.DeadlineExceeded, so a timed-out
+// settlement is not retried: it may still land on chain.
+type facilitatorTimeoutError struct {
+	operation string
+	timeout   time.Duration
+	err       error
+}
+
+func (e *facilitatorTimeoutError) Error() string {
+	return fmt.Sprintf("facilitator %s timed out after %s: %v", e.operation, e.timeout, e.err)
+}
+
+// errorCode distinguishes a verification that never answered from a
+// settlement whose outcome is unknown.
+func (e *facilitatorTimeoutError) errorCode() string {
+	if e.operation == "settle" {
+		return x402pkg.ErrorCodeSettleTimeout
+	}
+	return x402pkg.ErrorCodeVerifyTimeout
+}
+
+func (e *facilitatorTimeoutError) Unwrap() []error {
+	return []error{e.err, context.DeadlineExceeded}
+}
+
+func withFacilitatorTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// facilitatorTimeout converts err into a facilitatorTimeoutError when the
+// call's own deadline fired rather than the caller's context ending.
+func facilitatorTimeout(parent, callCtx context.Context, operation string, timeout time.Duration, err error) error {
+	if err == nil || timeout <= 0 || parent.Err() != nil {
+		return err
+	}
+	if !errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+	return &facilitatorTimeoutError{operation: operation, timeout: timeout, err: err}
+}
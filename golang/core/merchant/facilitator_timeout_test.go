@@ -0,0 +1,193 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// newSlowFacilitator answers /supported immediately and stalls on the given
+// endpoint until the request is abandoned or the test ends.
+func newSlowFacilitator(t *testing.T, slowEndpoint string) *httptest.Server {
+	t.Helper()
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/supported"):
+			_, _ = w.Write([]byte(`{"kinds":[{"x402Version":2,"scheme":"exact","network":"` + x402.NetworkBaseSepolia + `"}],"extensions":[],"signers":{}}`))
+		case strings.HasSuffix(r.URL.Path, slowEndpoint):
+			select {
+			case <-r.Context().Done():
+			case <-release:
+			}
+		case strings.HasSuffix(r.URL.Path, "/verify"):
+			_, _ = w.Write([]byte(`{"isValid":true,"payer":"0x789"}`))
+		default:
+			_, _ = w.Write([]byte(`{"success":true,"network":"` + x402.NetworkBaseSepolia + `","transaction":"0xtx"}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+	return server
+}
+
+func TestBusinessOrchestrator_FacilitatorTimeouts(t *testing.T) {
+	tests := []struct {
+		name              string
+		slowEndpoint      string
+		wantCode          string
+		wantIndeterminate bool
+	}{
+		{name: "verify", slowEndpoint: "/verify", wantCode: x402.ErrorCodeVerifyTimeout},
+		{name: "settle", slowEndpoint: "/settle", wantCode: x402.ErrorCodeSettleTimeout, wantIndeterminate: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			facilitator := newSlowFacilitator(t, tt.slowEndpoint)
+			facilitatorOptions := FacilitatorOptions{
+				VerifyTimeout: 50 * time.Millisecond,
+				SettleTimeout: 50 * time.Millisecond,
+			}
+			resourceServer, err := NewResourceServer(context.Background(), facilitator.URL, facilitatorOptions)
+			if err != nil {
+				t.Fatalf("NewResourceServer() error = %v", err)
+			}
+			orchestrator := newTestOrchestrator(
+				t,
+				&resourceServerWrapper{server: resourceServer},
+				&mockBusinessService{},
+				[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x1234567890123456789012345678901234567890"}},
+				WithFacilitatorOptions(facilitatorOptions),
+			)
+
+			task := quoteRequest(t, orchestrator, "task-timeout", a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "buy"}))
+			submission := paymentSubmission(t, task)
+
+			started := time.Now()
+			if err := submitPayment(orchestrator, task, submission, &mockEventQueue{}); err != nil {
+				t.Fatalf("paid Execute() error = %v", err)
+			}
+			if elapsed := time.Since(started); elapsed > 2*time.Second {
+				t.Errorf("Execute() took %v, the facilitator deadline did not fire", elapsed)
+			}
+			if task.Status.State != a2a.TaskStateFailed {
+				t.Fatalf("task state = %v, want failed", task.Status.State)
+			}
+			if got := task.Status.Message.Metadata[x402.MetadataKeyError]; got != tt.wantCode {
+				t.Errorf("error code = %v, want %s", got, tt.wantCode)
+			}
+			if got := task.Status.Message.Metadata[x402.MetadataKeyIndeterminate] == true; got != tt.wantIndeterminate {
+				t.Errorf("indeterminate = %v, want %v", got, tt.wantIndeterminate)
+			}
+		})
+	}
+}
+
+func TestBusinessOrchestrator_FacilitatorTimeoutsWithBlockingResourceServer(t *testing.T) {
+	block := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	tests := []struct {
+		name              string
+		resourceServer    *MockResourceServer
+		wantCode          string
+		wantIndeterminate bool
+	}{
+		{
+			name: "verify",
+			resourceServer: &MockResourceServer{
+				VerifyPaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.VerifyResponse, error) {
+					return nil, block(ctx)
+				},
+			},
+			wantCode: x402.ErrorCodeVerifyTimeout,
+		},
+		{
+			name: "settle",
+			resourceServer: &MockResourceServer{
+				SettlePaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.SettleResponse, error) {
+					return nil, block(ctx)
+				},
+			},
+			wantCode:          x402.ErrorCodeSettleTimeout,
+			wantIndeterminate: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orchestrator := newTestOrchestrator(
+				t,
+				tt.resourceServer,
+				&mockBusinessService{},
+				[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+				WithFacilitatorOptions(FacilitatorOptions{
+					VerifyTimeout: 20 * time.Millisecond,
+					SettleTimeout: 20 * time.Millisecond,
+				}),
+			)
+
+			task := quoteRequest(t, orchestrator, "task-blocking", a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "buy"}))
+			submission := paymentSubmission(t, task)
+
+			if err := submitPayment(orchestrator, task, submission, &mockEventQueue{}); err != nil {
+				t.Fatalf("paid Execute() error = %v", err)
+			}
+			if task.Status.State != a2a.TaskStateFailed {
+				t.Fatalf("task state = %v, want failed", task.Status.State)
+			}
+			if got := task.Status.Message.Metadata[x402.MetadataKeyError]; got != tt.wantCode {
+				t.Errorf("error code = %v, want %s", got, tt.wantCode)
+			}
+			if got := task.Status.Message.Metadata[x402.MetadataKeyIndeterminate] == true; got != tt.wantIndeterminate {
+				t.Errorf("indeterminate = %v, want %v", got, tt.wantIndeterminate)
+			}
+		})
+	}
+}
+
+func TestFacilitatorOptions_DefaultTimeouts(t *testing.T) {
+	var options FacilitatorOptions
+	if got := options.verifyTimeout(); got != DefaultVerifyTimeout {
+		t.Errorf("verifyTimeout() = %v, want %v", got, DefaultVerifyTimeout)
+	}
+	if got := options.settleTimeout(); got != DefaultSettleTimeout {
+		t.Errorf("settleTimeout() = %v, want %v", got, DefaultSettleTimeout)
+	}
+
+	options = FacilitatorOptions{VerifyTimeout: -1, SettleTimeout: time.Second}
+	ctx, cancel := withFacilitatorTimeout(context.Background(), options.verifyTimeout())
+	defer cancel()
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("negative VerifyTimeout set a deadline")
+	}
+	if got := options.settleTimeout(); got != time.Second {
+		t.Errorf("settleTimeout() = %v, want %v", got, time.Second)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+)
+
+// FailureRecord is the accounting copy of one task the merchant ended with an
+// x402 error code.
+type FailureRecord struct {
+	TaskID    a2a.TaskID    `json:"taskId"`
+	ContextID string        `json:"contextId,omitempty"`
+	State     a2a.TaskState `json:"state"`
+	ErrorCode string        `json:"errorCode"`
+	// Network and Asset come from the submitted payment, or Network alone
+	// from the failure receipt. Both are empty when the task failed before a
+	// payment was submitted.
+	Network  string    `json:"network,omitempty"`
+	Asset    string    `json:"asset,omitempty"`
+	FailedAt time.Time `json:"failedAt"`
+}
+
+// FailureStore keeps failed tasks after they are gone. The orchestrator
+// appends a record for every task it ends with an x402 error code.
+// Implementations must be safe for concurrent use.
+type FailureStore interface {
+	Append(ctx context.Context, record *FailureRecord) error
+	// ListByTimeRange returns failures in [from, to) in the order they
+	// happened.
+	ListByTimeRange(ctx context.Context, from, to time.Time) ([]*FailureRecord, error)
+}
+
+// WithFailureStore records every task ended with an x402 error code in store.
+// Merchant.Analytics reads it for failure breakdowns.
+func WithFailureStore(store FailureStore) Option {
+	return func(o *BusinessOrchestrator) {
+		o.failures = store
+	}
+}
+
+// recordFailure appends task to the failure store when it ended with an x402
+// error code. A failed append is logged; the task has already ended.
+func (o *BusinessOrchestrator) recordFailure(ctx context.Context, task *a2a.Task) {
+	if o.failures == nil || task == nil || task.Status.Message == nil {
+		return
+	}
+	code, _ := task.Status.Message.Meta()[x402pkg.MetadataKeyError].(string)
+	if code == "" {
+		return
+	}
+	record := &FailureRecord{
+		TaskID:    task.ID,
+		ContextID: task.ContextID,
+		State:     task.Status.State,
+		ErrorCode: code,
+		FailedAt:  o.now(),
+	}
+	if payload, err := state.ExtractPaymentPayload(task, nil); err == nil && payload != nil {
+		record.Network = payload.Accepted.Network
+		record.Asset = payload.Accepted.Asset
+	} else if receipts, err := state.ExtractPaymentReceipts(task); err == nil && len(receipts) > 0 && receipts[0] != nil {
+		record.Network = string(receipts[0].Network)
+	}
+	if err := o.failures.Append(context.WithoutCancel(ctx), record); err != nil {
+		o.logger.ErrorContext(ctx, "x402 failure not recorded",
+			"task_id", task.ID,
+			"context_id", task.ContextID,
+			"error_code", code,
+			"error", err,
+		)
+	}
+}
+
+// MemoryFailureStore keeps failures in memory. It is meant for tests and
+// single-process deployments.
+type MemoryFailureStore struct {
+	mu      sync.RWMutex
+	records []FailureRecord
+}
+
+func NewMemoryFailureStore() *MemoryFailureStore {
+	return &MemoryFailureStore{}
+}
+
+func (s *MemoryFailureStore) Append(ctx context.Context, record *FailureRecord) error {
+	if record == nil {
+		return fmt.Errorf("failure record is required")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, *record)
+	return nil
+}
+
+func (s *MemoryFailureStore) ListByTimeRange(ctx context.Context, from, to time.Time) ([]*FailureRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var matched []*FailureRecord
+	for _, record := range s.records {
+		if !record.FailedAt.Before(from) && record.FailedAt.Before(to) {
+			matched = append(matched, &record)
+		}
+	}
+	slices.SortStableFunc(matched, func(a, b *FailureRecord) int {
+		if c := a.FailedAt.Compare(b.FailedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(string(a.TaskID), string(b.TaskID))
+	})
+	return matched, nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/a2aproject/a2a-go/a2asrv/eventqueue"
+)
+
+// HandleOnce runs a single Execute pass for msg outside an a2asrv server, for
+// merchants that handle one request per invocation, such as a serverless
+// function. stored is the task msg refers to, loaded by the caller, or nil for
+// a new task. It returns the task as Execute left it and the events to deliver
+// over the caller's own transport; the caller persists the task and hands it
+// back with the next message for it. stored itself is left unchanged.
+//
+// ctx must carry the client's requested extensions, as a transport would set
+// them with a2asrv.WithCallContext, unless the orchestrator was built with
+// WithExtensionChecker. Pair the orchestrator with a durable
+// WithPaymentStateStore so a later invocation on a fresh instance can finish
+// a payment in flight. Options that work in the background after Execute
+// returns, such as WithAsyncSettlement, need a long-lived process: events
+// they write after HandleOnce returns are dropped.
+func HandleOnce(ctx context.Context, o *BusinessOrchestrator, msg *a2a.Message, stored *a2a.Task) (*a2a.Task, []a2a.Event, error) {
+	if msg == nil {
+		return nil, nil, fmt.Errorf("message is required")
+	}
+	requestContext := &a2asrv.RequestContext{
+		Message:    msg,
+		StoredTask: stored,
+		TaskID:     msg.TaskID,
+		ContextID:  msg.ContextID,
+	}
+	if stored != nil {
+		requestContext.TaskID = stored.ID
+		requestContext.ContextID = stored.ContextID
+	}
+	if requestContext.TaskID == "" {
+		requestContext.TaskID = a2a.NewTaskID()
+	}
+	if requestContext.ContextID == "" {
+		requestContext.ContextID = a2a.NewContextID()
+	}
+
+	queue := &collectingQueue{}
+	task, err := o.handle(ctx, requestContext, queue)
+	events := queue.drain()
+	if task == nil {
+		task = stored
+	}
+	return task, events, err
+}
+
+// collectingQueue keeps the events of one Execute pass in memory. It stops
+// taking events once drained.
+type collectingQueue struct {
+	mu     sync.Mutex
+	events []a2a.Event
+	closed bool
+}
+
+var _ eventqueue.Queue = (*collectingQueue)(nil)
+
+func (q *collectingQueue) Write(ctx context.Context, event a2a.Event) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return eventqueue.ErrQueueClosed
+	}
+	q.events = append(q.events, event)
+	return nil
+}
+
+func (q *collectingQueue) WriteVersioned(ctx context.Context, event a2a.Event, version a2a.TaskVersion) error {
+	return q.Write(ctx, event)
+}
+
+func (q *collectingQueue) Read(ctx context.Context) (a2a.Event, a2a.TaskVersion, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.events) == 0 {
+		return nil, 0, eventqueue.ErrQueueClosed
+	}
+	event := q.events[0]
+	q.events = q.events[1:]
+	return event, 0, nil
+}
+
+func (q *collectingQueue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	return nil
+}
+
+// drain closes the queue and returns the events written to it.
+func (q *collectingQueue) drain() []a2a.Event {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	events := q.events
+	q.events = nil
+	return events
+}
@@ -0,0 +1,132 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// lambdaInvocation runs msg on a freshly built orchestrator, as a cold-started
+// function would, and returns the task as the caller's store would keep it.
+func lambdaInvocation(t *testing.T, store PaymentStateStore, server ResourceServer, msg *a2a.Message, stored *a2a.Task) (*a2a.Task, []a2a.Event) {
+	t.Helper()
+	orchestrator := newTestOrchestrator(
+		t,
+		server,
+		&mockBusinessService{},
+		[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+		WithExtensionChecker(DefaultExtensionChecker()),
+		WithPaymentStateStore(store),
+	)
+	ctx, _ := a2asrv.WithCallContext(context.Background(), a2asrv.NewRequestMeta(map[string][]string{
+		a2asrv.ExtensionsMetaKey: {x402.X402ExtensionURI},
+	}))
+	task, events, err := HandleOnce(ctx, orchestrator, msg, stored)
+	if err != nil {
+		t.Fatalf("HandleOnce() error = %v", err)
+	}
+
+	data, err := json.Marshal(task)
+	if err != nil {
+		t.Fatalf("json.Marshal(task) error = %v", err)
+	}
+	var persisted a2a.Task
+	if err := json.Unmarshal(data, &persisted); err != nil {
+		t.Fatalf("json.Unmarshal(task) error = %v", err)
+	}
+	return &persisted, events
+}
+
+func TestHandleOnce_PaymentFlowAcrossInvocations(t *testing.T) {
+	store, err := NewFilePaymentStateStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFilePaymentStateStore() error = %v", err)
+	}
+	var settled int
+	server := &MockResourceServer{
+		SettlePaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.SettleResponse, error) {
+			settled++
+			return &x402core.SettleResponse{Success: true, Network: x402.NetworkBaseSepolia, Transaction: "0xtx"}, nil
+		},
+	}
+
+	// Invocation 1: the request is quoted.
+	task, events := lambdaInvocation(t, store, server, a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "buy"}), nil)
+	if task.ID == "" || task.ContextID == "" {
+		t.Fatalf("task = %+v, want generated IDs", task)
+	}
+	if task.Status.State != a2a.TaskStateInputRequired {
+		t.Fatalf("state after quote = %s, want %s", task.Status.State, a2a.TaskStateInputRequired)
+	}
+	if len(events) < 2 {
+		t.Fatalf("events after quote = %d, want submission and quote", len(events))
+	}
+	if _, found, _ := store.LoadState(context.Background(), task.ID); !found {
+		t.Error("quote was not persisted to the payment state store")
+	}
+
+	// Invocation 2: the client pays against the quote.
+	submission := paymentSubmission(t, task)
+	submission.ContextID = task.ContextID
+	task, events = lambdaInvocation(t, store, server, submission, task)
+	if task.Status.State != a2a.TaskStateCompleted {
+		t.Fatalf("state after payment = %s, want %s", task.Status.State, a2a.TaskStateCompleted)
+	}
+	last, ok := events[len(events)-1].(*a2a.TaskStatusUpdateEvent)
+	if !ok || last.Status.State != a2a.TaskStateCompleted || !last.Final {
+		t.Errorf("last event = %#v, want final completed status", events[len(events)-1])
+	}
+	if _, found, _ := store.LoadState(context.Background(), task.ID); found {
+		t.Error("payment record kept after the task completed")
+	}
+
+	// Invocation 3: the platform redelivers the payment; it is answered from
+	// the stored task without settling again.
+	task, events = lambdaInvocation(t, store, server, submission, task)
+	if task.Status.State != a2a.TaskStateCompleted {
+		t.Errorf("state after redelivery = %s, want %s", task.Status.State, a2a.TaskStateCompleted)
+	}
+	if len(events) != 1 {
+		t.Errorf("events after redelivery = %d, want the replayed status", len(events))
+	}
+	if settled != 1 {
+		t.Errorf("settlements = %d, want 1", settled)
+	}
+}
+
+func TestHandleOnce_RequiresStoredTask(t *testing.T) {
+	orchestrator := newTestOrchestrator(
+		t,
+		&MockResourceServer{},
+		&mockBusinessService{},
+		[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+	)
+	msg := a2a.NewMessageForTask(a2a.MessageRoleUser, a2a.TaskInfo{TaskID: "task-unknown"}, a2a.TextPart{Text: "pay"})
+	if _, _, err := HandleOnce(context.Background(), orchestrator, msg, nil); err == nil {
+		t.Error("HandleOnce() for an unloaded task error = nil, want error")
+	}
+	if _, _, err := HandleOnce(context.Background(), orchestrator, nil, nil); err == nil {
+		t.Error("HandleOnce(nil) error = nil, want error")
+	}
+}
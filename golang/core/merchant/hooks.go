@@ -0,0 +1,119 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// Hooks let merchants run side effects at payment milestones. Every hook is
+// optional and is called synchronously from the orchestrator; a panicking hook
+// is recovered and logged so it cannot corrupt the task. For a paid task the
+// order is OnQuoteIssued, OnPaymentSubmitted, OnPaymentVerified, OnSettled,
+// with OnFailed replacing the remaining steps when the payment fails and
+// OnAuthorizationVoided replacing OnSettled when the task is canceled first.
+type Hooks struct {
+	OnQuoteIssued      func(ctx context.Context, task *a2a.Task, requirements *x402types.PaymentRequired)
+	OnPaymentSubmitted func(ctx context.Context, task *a2a.Task, payload *x402types.PaymentPayload)
+	OnPaymentVerified  func(ctx context.Context, task *a2a.Task, payer string)
+	OnSettled          func(ctx context.Context, task *a2a.Task, receipt *x402core.SettleResponse)
+	// OnFailed receives the x402 error code, which is empty when the task
+	// failed for a reason unrelated to payment.
+	OnFailed func(ctx context.Context, task *a2a.Task, code string, err error)
+	// OnAuthorizationVoided is called when a verified payment is abandoned
+	// before settlement because the task was canceled, so merchants can
+	// release any hold placed against it.
+	OnAuthorizationVoided func(ctx context.Context, task *a2a.Task, payload *x402types.PaymentPayload)
+	// OnEventUndelivered receives an event the queue refused even after
+	// retries, once the task's payment has been verified. The client may not
+	// have seen the receipt it carries, so merchants should keep it for
+	// reconciliation.
+	OnEventUndelivered func(ctx context.Context, task *a2a.Task, event a2a.Event, err error)
+}
+
+// WithHooks registers lifecycle hooks on the orchestrator.
+func WithHooks(hooks Hooks) Option {
+	return func(o *BusinessOrchestrator) {
+		o.hooks.Hooks = hooks
+	}
+}
+
+// hookRunner calls the registered Hooks, logging recovered panics to the
+// orchestrator's logger.
+type hookRunner struct {
+	Hooks
+	logger *slog.Logger
+}
+
+func callHook(ctx context.Context, logger *slog.Logger, name string, task *a2a.Task, fn func()) {
+	defer func() {
+		if r := recover(); r != nil && logger != nil {
+			logger.ErrorContext(ctx, "x402 hook panicked",
+				"task_id", task.ID,
+				"context_id", task.ContextID,
+				"hook", name,
+				"panic", r,
+			)
+		}
+	}()
+	fn()
+}
+
+func (h hookRunner) quoteIssued(ctx context.Context, task *a2a.Task, requirements *x402types.PaymentRequired) {
+	if h.OnQuoteIssued != nil {
+		callHook(ctx, h.logger, "OnQuoteIssued", task, func() { h.OnQuoteIssued(ctx, task, requirements) })
+	}
+}
+
+func (h hookRunner) paymentSubmitted(ctx context.Context, task *a2a.Task, payload *x402types.PaymentPayload) {
+	if h.OnPaymentSubmitted != nil {
+		callHook(ctx, h.logger, "OnPaymentSubmitted", task, func() { h.OnPaymentSubmitted(ctx, task, payload) })
+	}
+}
+
+func (h hookRunner) paymentVerified(ctx context.Context, task *a2a.Task, payer string) {
+	if h.OnPaymentVerified != nil {
+		callHook(ctx, h.logger, "OnPaymentVerified", task, func() { h.OnPaymentVerified(ctx, task, payer) })
+	}
+}
+
+func (h hookRunner) settled(ctx context.Context, task *a2a.Task, receipt *x402core.SettleResponse) {
+	if h.OnSettled != nil {
+		callHook(ctx, h.logger, "OnSettled", task, func() { h.OnSettled(ctx, task, receipt) })
+	}
+}
+
+func (h hookRunner) failed(ctx context.Context, task *a2a.Task, code string, err error) {
+	if h.OnFailed != nil {
+		callHook(ctx, h.logger, "OnFailed", task, func() { h.OnFailed(ctx, task, code, err) })
+	}
+}
+
+func (h hookRunner) authorizationVoided(ctx context.Context, task *a2a.Task, payload *x402types.PaymentPayload) {
+	if h.OnAuthorizationVoided != nil {
+		callHook(ctx, h.logger, "OnAuthorizationVoided", task, func() { h.OnAuthorizationVoided(ctx, task, payload) })
+	}
+}
+
+func (h hookRunner) eventUndelivered(ctx context.Context, task *a2a.Task, event a2a.Event, err error) {
+	if h.OnEventUndelivered != nil {
+		callHook(ctx, h.logger, "OnEventUndelivered", task, func() { h.OnEventUndelivered(ctx, task, event, err) })
+	}
+}
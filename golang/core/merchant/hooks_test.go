@@ -0,0 +1,114 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+func TestBusinessOrchestrator_Hooks(t *testing.T) {
+	tests := []struct {
+		name      string
+		verifyErr error
+		wantCalls []string
+		wantState a2a.TaskState
+	}{
+		{
+			name:      "happy path",
+			wantCalls: []string{"quote", "submitted", "verified:0x789", "settled:0xtx"},
+			wantState: a2a.TaskStateCompleted,
+		},
+		{
+			name:      "verification failure",
+			verifyErr: errors.New("facilitator said no"),
+			wantCalls: []string{"quote", "submitted", "failed:" + x402.ErrorCodeInvalidSignature},
+			wantState: a2a.TaskStateFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			var logs bytes.Buffer
+			hooks := Hooks{
+				OnQuoteIssued: func(ctx context.Context, task *a2a.Task, requirements *x402types.PaymentRequired) {
+					calls = append(calls, "quote")
+					panic("hooks must not break the payment flow")
+				},
+				OnPaymentSubmitted: func(ctx context.Context, task *a2a.Task, payload *x402types.PaymentPayload) {
+					calls = append(calls, "submitted")
+				},
+				OnPaymentVerified: func(ctx context.Context, task *a2a.Task, payer string) {
+					calls = append(calls, "verified:"+payer)
+				},
+				OnSettled: func(ctx context.Context, task *a2a.Task, receipt *x402core.SettleResponse) {
+					calls = append(calls, "settled:"+receipt.Transaction)
+				},
+				OnFailed: func(ctx context.Context, task *a2a.Task, code string, err error) {
+					calls = append(calls, "failed:"+code)
+				},
+			}
+			orchestrator := newTestOrchestrator(
+				t,
+				&MockResourceServer{
+					VerifyPaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.VerifyResponse, error) {
+						if tt.verifyErr != nil {
+							return nil, tt.verifyErr
+						}
+						return &x402core.VerifyResponse{IsValid: true, Payer: "0x789"}, nil
+					},
+					SettlePaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.SettleResponse, error) {
+						return &x402core.SettleResponse{Success: true, Network: x402.NetworkBaseSepolia, Transaction: "0xtx"}, nil
+					},
+				},
+				&mockBusinessService{},
+				[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+				WithHooks(hooks),
+				WithLogger(slog.New(slog.NewJSONHandler(&logs, nil))),
+			)
+
+			task := quoteRequest(t, orchestrator, "task-hooks", a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "buy"}))
+			if task.Status.State != a2a.TaskStateInputRequired {
+				t.Fatalf("task state after panicking hook = %v, want input-required", task.Status.State)
+			}
+			submission := paymentSubmission(t, task)
+			if err := submitPayment(orchestrator, task, submission, &mockEventQueue{}); err != nil {
+				t.Fatalf("paid Execute() error = %v", err)
+			}
+
+			if !slices.Equal(calls, tt.wantCalls) {
+				t.Errorf("hook calls = %v, want %v", calls, tt.wantCalls)
+			}
+			if task.Status.State != tt.wantState {
+				t.Errorf("task state = %v, want %v", task.Status.State, tt.wantState)
+			}
+			if !strings.Contains(logs.String(), `"hook":"OnQuoteIssued"`) || !strings.Contains(logs.String(), `"task_id":"task-hooks"`) {
+				t.Errorf("logs = %s, want the OnQuoteIssued panic for task-hooks", logs.String())
+			}
+		})
+	}
+}
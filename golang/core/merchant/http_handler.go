@@ -0,0 +1,129 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"net/http"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+)
+
+// DefaultRPCPath is where NewHTTPHandler serves JSON-RPC unless
+// WithHandlerRPCPath says otherwise.
+const DefaultRPCPath = "/rpc"
+
+// HandlerOption configures NewHTTPHandler.
+type HandlerOption func(*handlerConfig)
+
+type handlerConfig struct {
+	rpcPath        string
+	agentCard      *a2a.AgentCard
+	cardSource     a2asrv.AgentCardProducer
+	middleware     []func(http.Handler) http.Handler
+	handlerOptions []a2asrv.RequestHandlerOption
+	capabilities   bool
+}
+
+// WithHandlerRPCPath serves JSON-RPC at path instead of DefaultRPCPath.
+func WithHandlerRPCPath(path string) HandlerOption {
+	return func(c *handlerConfig) {
+		c.rpcPath = path
+	}
+}
+
+// WithHandlerAgentCard serves card at a2asrv.WellKnownAgentCardPath.
+func WithHandlerAgentCard(card *a2a.AgentCard) HandlerOption {
+	return func(c *handlerConfig) {
+		c.agentCard = card
+	}
+}
+
+// WithHandlerAgentCardSource serves the card source's current card at
+// a2asrv.WellKnownAgentCardPath, so pricing changes show up on the next
+// fetch. It replaces a card given with WithHandlerAgentCard.
+func WithHandlerAgentCardSource(source a2asrv.AgentCardProducer) HandlerOption {
+	return func(c *handlerConfig) {
+		c.cardSource = source
+	}
+}
+
+// WithHandlerCapabilities serves the merchant's capabilities at
+// x402.CapabilitiesPath, so integrators can check which networks and assets
+// are accepted before starting a task.
+func WithHandlerCapabilities() HandlerOption {
+	return func(c *handlerConfig) {
+		c.capabilities = true
+	}
+}
+
+// WithHandlerMiddleware wraps every route in middleware. The first
+// middleware given is the outermost; all of them run after the request
+// headers are in the call context.
+func WithHandlerMiddleware(middleware ...func(http.Handler) http.Handler) HandlerOption {
+	return func(c *handlerConfig) {
+		c.middleware = append(c.middleware, middleware...)
+	}
+}
+
+// WithRequestHandlerOptions passes options to the a2a request handler, such
+// as a task store. The merchant's push notifications are always wired in.
+func WithRequestHandlerOptions(opts ...a2asrv.RequestHandlerOption) HandlerOption {
+	return func(c *handlerConfig) {
+		c.handlerOptions = append(c.handlerOptions, opts...)
+	}
+}
+
+// NewHTTPHandler serves m over a2a JSON-RPC. It copies the request headers
+// into the a2a call context, which is where the orchestrator looks for the
+// X-A2A-Extensions header; a server that skips this step fails every request
+// for want of the x402 extension.
+func NewHTTPHandler(m *Merchant, opts ...HandlerOption) http.Handler {
+	config := handlerConfig{rpcPath: DefaultRPCPath}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	handlerOptions := append([]a2asrv.RequestHandlerOption{m.PushNotifications()}, config.handlerOptions...)
+	requestHandler := a2asrv.NewHandler(m.Orchestrator(), handlerOptions...)
+
+	mux := http.NewServeMux()
+	mux.Handle(config.rpcPath, a2asrv.NewJSONRPCHandler(requestHandler))
+	switch {
+	case config.cardSource != nil:
+		mux.Handle(a2asrv.WellKnownAgentCardPath, a2asrv.NewAgentCardHandler(config.cardSource))
+	case config.agentCard != nil:
+		mux.Handle(a2asrv.WellKnownAgentCardPath, a2asrv.NewStaticAgentCardHandler(config.agentCard))
+	}
+
+	if config.capabilities {
+		mux.Handle(x402pkg.CapabilitiesPath, capabilitiesHandler(m.orchestrator))
+	}
+
+	var handler http.Handler = mux
+	for i := len(config.middleware) - 1; i >= 0; i-- {
+		handler = config.middleware[i](handler)
+	}
+	return withCallContext(handler)
+}
+
+// withCallContext puts the request headers into the a2a call context.
+func withCallContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, _ := a2asrv.WithCallContext(r.Context(), a2asrv.NewRequestMeta(r.Header))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,123 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+)
+
+const sendMessageRequest = `{"jsonrpc":"2.0","id":1,"method":"message/send","params":{"message":` +
+	`{"kind":"message","messageId":"m1","role":"user","parts":[{"kind":"text","text":"buy"}]}}}`
+
+func newHandlerMerchant(t *testing.T) *Merchant {
+	return &Merchant{orchestrator: newTestOrchestrator(
+		t,
+		&MockResourceServer{},
+		&mockBusinessService{},
+		[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+		WithExtensionChecker(DefaultExtensionChecker()),
+	)}
+}
+
+// postRPC sends a message/send request and decodes the resulting task.
+func postRPC(t *testing.T, handler http.Handler, path string, extensions string) *a2a.Task {
+	t.Helper()
+	request := httptest.NewRequest(http.MethodPost, path, strings.NewReader(sendMessageRequest))
+	request.Header.Set("Content-Type", "application/json")
+	if extensions != "" {
+		request.Header.Set("X-A2A-Extensions", extensions)
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", recorder.Code, recorder.Body)
+	}
+	var response struct {
+		Result *a2a.Task `json:"result"`
+		Error  any       `json:"error"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("response %s does not decode: %v", recorder.Body, err)
+	}
+	if response.Result == nil {
+		t.Fatalf("response %s carries no task", recorder.Body)
+	}
+	return response.Result
+}
+
+func TestNewHTTPHandler_ExtensionHeader(t *testing.T) {
+	handler := NewHTTPHandler(newHandlerMerchant(t))
+
+	quoted := postRPC(t, handler, DefaultRPCPath, x402.X402ExtensionURI)
+	if quoted.Status.State != a2a.TaskStateInputRequired {
+		t.Errorf("with extension header: state = %s, want input-required", quoted.Status.State)
+	}
+
+	// Execute returns the extension error, so the a2a handler fails the task
+	// in place of the quote.
+	if refused := postRPC(t, handler, DefaultRPCPath, ""); refused.Status.State != a2a.TaskStateFailed {
+		t.Errorf("without extension header: state = %s, want failed", refused.Status.State)
+	}
+}
+
+func TestNewHTTPHandler_Options(t *testing.T) {
+	var order []string
+	tag := func(name string) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if _, ok := a2asrv.CallContextFrom(r.Context()); !ok {
+					t.Errorf("middleware %s ran before the call context was set", name)
+				}
+				order = append(order, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+	card := &a2a.AgentCard{Name: "Test Merchant", URL: "http://merchant.test/a2a"}
+	handler := NewHTTPHandler(newHandlerMerchant(t),
+		WithHandlerRPCPath("/a2a"),
+		WithHandlerAgentCard(card),
+		WithHandlerMiddleware(tag("outer"), tag("inner")),
+	)
+
+	if task := postRPC(t, handler, "/a2a", x402.X402ExtensionURI); task.Status.State != a2a.TaskStateInputRequired {
+		t.Errorf("state = %s, want input-required", task.Status.State)
+	}
+	if strings.Join(order, ",") != "outer,inner" {
+		t.Errorf("middleware order = %v, want outer then inner", order)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, a2asrv.WellKnownAgentCardPath, nil))
+	var served a2a.AgentCard
+	if err := json.Unmarshal(recorder.Body.Bytes(), &served); err != nil || served.Name != card.Name {
+		t.Errorf("agent card = %s, %v", recorder.Body, err)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, DefaultRPCPath, strings.NewReader(sendMessageRequest)))
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("default path status = %d, want 404 once the RPC path is moved", recorder.Code)
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// redacted replaces signatures and authorization details in log records.
+const redacted = "[REDACTED]"
+
+// WithLogger makes the orchestrator emit a structured record at each payment
+// transition. Signatures and authorization internals are never logged. The
+// default logger discards everything.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *BusinessOrchestrator) {
+		if logger != nil {
+			o.logger = logger
+		}
+	}
+}
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.DiscardHandler)
+}
+
+func (o *BusinessOrchestrator) logTransition(
+	ctx context.Context,
+	level slog.Level,
+	task *a2a.Task,
+	msg string,
+	status state.PaymentStatus,
+	attrs ...slog.Attr,
+) {
+	if !o.logger.Enabled(ctx, level) {
+		return
+	}
+	base := []slog.Attr{
+		slog.String("task_id", string(task.ID)),
+		slog.String("context_id", task.ContextID),
+	}
+	if status != "" {
+		base = append(base, slog.String("payment_status", status.String()))
+	}
+	o.logger.LogAttrs(ctx, level, msg, append(base, attrs...)...)
+}
+
+func (o *BusinessOrchestrator) logQuoteIssued(ctx context.Context, task *a2a.Task, requirements *x402types.PaymentRequired) {
+	var accepts []map[string]string
+	if requirements != nil {
+		for _, accepted := range requirements.Accepts {
+			accepts = append(accepts, map[string]string{
+				"network": accepted.Network,
+				"asset":   accepted.Asset,
+				"amount":  accepted.Amount,
+			})
+		}
+	}
+	o.logTransition(ctx, slog.LevelInfo, task, "x402 payment required", state.PaymentRequired,
+		slog.Any("accepts", accepts))
+}
+
+func (o *BusinessOrchestrator) logPaymentSubmitted(ctx context.Context, task *a2a.Task, payload *x402types.PaymentPayload) {
+	o.logTransition(ctx, slog.LevelInfo, task, "x402 payment submitted", state.PaymentSubmitted,
+		slog.Any("payload", redactedPayload{payload}))
+}
+
+func (o *BusinessOrchestrator) logPaymentVerified(ctx context.Context, task *a2a.Task, paymentState *state.PaymentState) {
+	o.logTransition(ctx, slog.LevelInfo, task, "x402 payment verified", state.PaymentVerified,
+		slog.String("network", payloadNetwork(paymentState)),
+		slog.String("amount", payloadAmount(paymentState)),
+		slog.String("payer", paymentState.Payer),
+	)
+}
+
+func (o *BusinessOrchestrator) logSettled(ctx context.Context, task *a2a.Task, receipt *x402core.SettleResponse) {
+	if receipt == nil {
+		return
+	}
+	o.logTransition(ctx, slog.LevelInfo, task, "x402 payment settled", state.PaymentCompleted,
+		slog.String("network", string(receipt.Network)),
+		slog.String("transaction", receipt.Transaction),
+	)
+}
+
+func (o *BusinessOrchestrator) logFailed(ctx context.Context, task *a2a.Task, errorCode string, err error) {
+	var status state.PaymentStatus
+	if errorCode != "" {
+		status = state.PaymentFailed
+	}
+	o.logTransition(ctx, slog.LevelWarn, task, "x402 task failed", status,
+		slog.String("error_code", errorCode),
+		slog.String("error", err.Error()),
+	)
+}
+
+func (o *BusinessOrchestrator) logRejected(ctx context.Context, task *a2a.Task) {
+	o.logTransition(ctx, slog.LevelInfo, task, "x402 payment rejected", state.PaymentRejected)
+}
+
+func (o *BusinessOrchestrator) logCanceled(ctx context.Context, task *a2a.Task, settled bool) {
+	status, _ := state.ExtractPaymentStatus(task)
+	o.logTransition(ctx, slog.LevelInfo, task, "x402 task canceled", status, slog.Bool("settled", settled))
+}
+
+func payloadAmount(paymentState *state.PaymentState) string {
+	if paymentState == nil || paymentState.Payload == nil {
+		return ""
+	}
+	return paymentState.Payload.Accepted.Amount
+}
+
+// redactedPayload logs what the client agreed to pay while hiding the signed
+// authorization itself.
+type redactedPayload struct {
+	payload *x402types.PaymentPayload
+}
+
+func (p redactedPayload) LogValue() slog.Value {
+	if p.payload == nil {
+		return slog.Value{}
+	}
+	return slog.GroupValue(
+		slog.String("scheme", p.payload.Accepted.Scheme),
+		slog.String("network", p.payload.Accepted.Network),
+		slog.String("asset", p.payload.Accepted.Asset),
+		slog.String("amount", p.payload.Accepted.Amount),
+		slog.String("pay_to", p.payload.Accepted.PayTo),
+		slog.String("authorization", redacted),
+	)
+}
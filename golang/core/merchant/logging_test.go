@@ -0,0 +1,90 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+func TestBusinessOrchestrator_LogsVerifyFailure(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	orchestrator := newTestOrchestrator(
+		t,
+		&MockResourceServer{
+			VerifyPaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.VerifyResponse, error) {
+				return nil, errors.New("facilitator said no")
+			},
+		},
+		&mockBusinessService{},
+		[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+		WithLogger(logger),
+	)
+
+	task := quoteRequest(t, orchestrator, "task-logging", a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "buy"}))
+	payQuotedTask(t, orchestrator, task, func(payload *x402types.PaymentPayload) {
+		payload.Payload = map[string]interface{}{
+			"signature":     "0xsecretsignature",
+			"authorization": map[string]interface{}{"nonce": "0xsecretnonce"},
+		}
+	})
+
+	output := buf.String()
+	for _, secret := range []string{"0xsecretsignature", "0xsecretnonce"} {
+		if strings.Contains(output, secret) {
+			t.Errorf("log output leaks %q: %s", secret, output)
+		}
+	}
+
+	var messages []string
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("failed to decode log line %q: %v", line, err)
+		}
+		if record["task_id"] != string(task.ID) || record["context_id"] != task.ContextID {
+			t.Errorf("record %v missing task or context ID", record)
+		}
+		messages = append(messages, record["msg"].(string))
+		records = append(records, record)
+	}
+	wantMessages := []string{"x402 payment required", "x402 payment submitted", "x402 task failed"}
+	if !slices.Equal(messages, wantMessages) {
+		t.Fatalf("log messages = %v, want %v", messages, wantMessages)
+	}
+
+	payload := records[1]["payload"].(map[string]any)
+	if payload["network"] != x402.NetworkBaseSepolia || payload["authorization"] != redacted {
+		t.Errorf("submitted payload record = %v", payload)
+	}
+	failed := records[2]
+	if failed["level"] != "WARN" || failed["error_code"] != x402.ErrorCodeInvalidSignature || failed["payment_status"] != "payment-failed" {
+		t.Errorf("failure record = %v", failed)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/a2aproject/a2a-go/a2asrv/eventqueue"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+)
+
+// DefaultMaxPayloadRetries bounds how many undecodable payment payloads a task
+// answers by keeping its quote open before the submission fails instead.
+const DefaultMaxPayloadRetries = 2
+
+// WithMaxPayloadRetries sets how many times a task may receive a payment
+// payload it cannot decode, e.g. one sent as a string instead of an object,
+// while keeping the quote open. Zero fails the first such submission.
+func WithMaxPayloadRetries(retries int) Option {
+	return func(o *BusinessOrchestrator) {
+		o.maxPayloadRetries = max(retries, 0)
+	}
+}
+
+// submissionMalformed reports whether the payment state could not be
+// extracted because of the incoming message alone. The task's own metadata
+// must still decode and the task must be awaiting payment; anything else
+// points at the merchant's stored state.
+func submissionMalformed(task *a2a.Task, message *a2a.Message) bool {
+	if task.Status.State != a2a.TaskStateInputRequired || !hasPaymentMetadata(nil, message) {
+		return false
+	}
+	stored, err := state.ExtractPaymentState(task, nil)
+	if err != nil || stored.Requirements == nil {
+		return false
+	}
+	return stored.Status == state.PaymentRequired || stored.Status == state.PaymentRejected
+}
+
+// rejectMalformedPayload answers an undecodable payment submission with a
+// MALFORMED_PAYMENT_PAYLOAD note while keeping the task in input-required with
+// its original requirements, so the client can resend a correct payload. It
+// reports false once the task has used up its retries and the caller fails
+// the payment as before.
+func (o *BusinessOrchestrator) rejectMalformedPayload(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	eventQueue eventqueue.Queue,
+	cause error,
+) (bool, error) {
+	retries := state.ExtractPayloadRetries(task)
+	if retries >= o.maxPayloadRetries {
+		return false, nil
+	}
+
+	message := a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{
+		Text: fmt.Sprintf("Payment submission could not be read (%v). Please resend the payment payload.", cause),
+	})
+	message.Metadata = maps.Clone(task.Status.Message.Metadata)
+	state.SetPaymentStatus(message, state.PaymentRequired)
+	state.SetPaymentError(message, x402pkg.ErrorCodeMalformedPayload)
+	state.SetPayloadRetries(message, retries+1)
+	recordFailedAttempt(task, message)
+	task.Status.Message = message
+	task.Status.State = a2a.TaskStateInputRequired
+	o.logger.InfoContext(ctx, "x402 malformed payment payload; quote kept open",
+		"task_id", task.ID,
+		"context_id", task.ContextID,
+		"retries", retries+1,
+		"error", cause,
+	)
+
+	return true, o.writeEvent(ctx, task, eventQueue, statusEvent(requestContext, task))
+}
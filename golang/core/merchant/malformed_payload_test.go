@@ -0,0 +1,104 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+)
+
+// sendMalformedPayload submits a payment whose payload is a string, as a
+// buggy client might.
+func sendMalformedPayload(t *testing.T, orchestrator *BusinessOrchestrator, task *a2a.Task) {
+	t.Helper()
+	submission := a2a.NewMessageForTask(a2a.MessageRoleUser, a2a.TaskInfo{TaskID: task.ID}, a2a.TextPart{Text: "Payment authorization provided"})
+	submission.Metadata = map[string]interface{}{
+		x402.MetadataKeyStatus:  x402state.PaymentSubmitted.String(),
+		x402.MetadataKeyPayload: `{"x402Version":2}`,
+	}
+	if err := submitPayment(orchestrator, task, submission, &mockEventQueue{}); err != nil {
+		t.Fatalf("malformed Execute() error = %v", err)
+	}
+}
+
+func TestBusinessOrchestrator_Execute_MalformedPayloadKeepsQuoteOpen(t *testing.T) {
+	orchestrator := newNetworkMatchingOrchestrator(t)
+	task := quoteTask(t, orchestrator)
+	quoted, err := x402state.ExtractPaymentRequirements(task)
+	if err != nil {
+		t.Fatalf("ExtractPaymentRequirements() error = %v", err)
+	}
+
+	sendMalformedPayload(t, orchestrator, task)
+	if task.Status.State != a2a.TaskStateInputRequired {
+		t.Fatalf("state after malformed payload = %s, want %s", task.Status.State, a2a.TaskStateInputRequired)
+	}
+	if status, _ := x402state.ExtractPaymentStatus(task); status != x402state.PaymentRequired {
+		t.Errorf("payment status = %s, want %s", status, x402state.PaymentRequired)
+	}
+	if got := task.Status.Message.Metadata[x402.MetadataKeyError]; got != x402.ErrorCodeMalformedPayload {
+		t.Errorf("error code = %v, want %s", got, x402.ErrorCodeMalformedPayload)
+	}
+	if got := x402state.ExtractPayloadRetries(task); got != 1 {
+		t.Errorf("payload retries = %d, want 1", got)
+	}
+	requirements, err := x402state.ExtractPaymentRequirements(task)
+	if err != nil || requirements == nil || len(requirements.Accepts) != len(quoted.Accepts) || requirements.Accepts[0].Amount != quoted.Accepts[0].Amount {
+		t.Fatalf("requirements after malformed payload = %+v, %v, want the original quote", requirements, err)
+	}
+
+	payOnNetwork(t, orchestrator, task, x402.NetworkBaseSepolia)
+	if task.Status.State != a2a.TaskStateCompleted {
+		t.Fatalf("state after corrected payload = %s, want %s", task.Status.State, a2a.TaskStateCompleted)
+	}
+	if got, ok := task.Status.Message.Metadata[x402.MetadataKeyError]; ok {
+		t.Errorf("completed task error code = %v, want none", got)
+	}
+}
+
+func TestBusinessOrchestrator_Execute_MalformedPayloadRetriesExhausted(t *testing.T) {
+	orchestrator := newNetworkMatchingOrchestrator(t, WithMaxPayloadRetries(1))
+	task := quoteTask(t, orchestrator)
+
+	sendMalformedPayload(t, orchestrator, task)
+	if task.Status.State != a2a.TaskStateInputRequired {
+		t.Fatalf("state after first malformed payload = %s, want %s", task.Status.State, a2a.TaskStateInputRequired)
+	}
+	sendMalformedPayload(t, orchestrator, task)
+	if task.Status.State != a2a.TaskStateFailed {
+		t.Fatalf("state after retries exhausted = %s, want %s", task.Status.State, a2a.TaskStateFailed)
+	}
+	if got := task.Status.Message.Metadata[x402.MetadataKeyError]; got != x402.ErrorCodeInvalidPayload {
+		t.Errorf("error code = %v, want %s", got, x402.ErrorCodeInvalidPayload)
+	}
+}
+
+func TestBusinessOrchestrator_Execute_CorruptStoredPayloadFails(t *testing.T) {
+	orchestrator := newNetworkMatchingOrchestrator(t)
+	task := quoteTask(t, orchestrator)
+	// The merchant's own metadata no longer decodes; a resend cannot fix it.
+	task.Status.Message.Metadata[x402.MetadataKeyPayload] = "corrupt"
+
+	sendMalformedPayload(t, orchestrator, task)
+	if task.Status.State != a2a.TaskStateFailed {
+		t.Fatalf("state with corrupt stored payload = %s, want %s", task.Status.State, a2a.TaskStateFailed)
+	}
+	if got := task.Status.Message.Metadata[x402.MetadataKeyError]; got != x402.ErrorCodeInvalidPayload {
+		t.Errorf("error code = %v, want %s", got, x402.ErrorCodeInvalidPayload)
+	}
+}
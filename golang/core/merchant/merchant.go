@@ -18,9 +18,12 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/a2aproject/a2a-go/a2a"
 	"github.com/a2aproject/a2a-go/a2asrv"
 	"github.com/google-agentic-commerce/a2a-x402/core/business"
 	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
 )
 
 type Merchant struct {
@@ -32,12 +35,19 @@ func NewMerchant(
 	facilitatorURL string,
 	businessService business.BusinessService,
 	networkConfigs []types.NetworkConfig,
+	opts ...Option,
 ) (*Merchant, error) {
-	if len(networkConfigs) == 0 {
-		return nil, fmt.Errorf("no network configurations provided")
+	var settings BusinessOrchestrator
+	for _, opt := range opts {
+		opt(&settings)
+	}
+	networkConfigs, err := normalizeNetworkConfigs(networkConfigs, settings.supportedNetworks(networkConfigs))
+	if err != nil {
+		return nil, err
 	}
 
-	orchestrator, err := NewBusinessOrchestrator(ctx, facilitatorURL, businessService, networkConfigs)
+	opts = append([]Option{WithFacilitatorURL(facilitatorURL)}, opts...)
+	orchestrator, err := NewBusinessOrchestrator(ctx, businessService, networkConfigs, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create business orchestrator: %w", err)
 	}
@@ -50,3 +60,67 @@ func NewMerchant(
 func (m *Merchant) Orchestrator() a2asrv.AgentExecutor {
 	return m.orchestrator
 }
+
+// Receipts returns the store settled payments are recorded in, or nil when
+// the merchant was created without WithReceiptStore.
+func (m *Merchant) Receipts() ReceiptStore {
+	return m.orchestrator.receipts
+}
+
+// Analytics aggregates the merchant's receipt store and failure store. A
+// store the merchant was created without reads as empty.
+func (m *Merchant) Analytics() *Analytics {
+	return NewAnalytics(m.orchestrator.receipts, m.orchestrator.failures)
+}
+
+// PushNotifications wires the merchant's push notifier into an a2a request
+// handler: pass it to a2asrv.NewHandler. Without WithPushNotifier the handler
+// keeps reporting push notifications as unsupported.
+func (m *Merchant) PushNotifications() a2asrv.RequestHandlerOption {
+	if m.orchestrator.push == nil {
+		return a2asrv.WithPushNotifications(nil, nil)
+	}
+	return a2asrv.WithPushNotifications(m.orchestrator.push.notifier.Store, m.orchestrator.push)
+}
+
+// Capabilities describes the networks, schemes and assets the merchant
+// quotes in. See BusinessOrchestrator.Capabilities.
+func (m *Merchant) Capabilities() (*types.Capabilities, error) {
+	return m.orchestrator.Capabilities()
+}
+
+// RecordExternalSettlement back-fills the receipt of a payment settled outside
+// the merchant. See BusinessOrchestrator.RecordExternalSettlement.
+func (m *Merchant) RecordExternalSettlement(ctx context.Context, taskID a2a.TaskID, receipt *x402core.SettleResponse) error {
+	return m.orchestrator.RecordExternalSettlement(ctx, taskID, receipt)
+}
+
+// IssueRefund returns amount of the task's settled payment to its payer. See
+// BusinessOrchestrator.IssueRefund.
+func (m *Merchant) IssueRefund(ctx context.Context, taskID a2a.TaskID, amount string, reason string) (*state.RefundReceipt, error) {
+	return m.orchestrator.IssueRefund(ctx, taskID, amount, reason)
+}
+
+// Recover resumes the payments an earlier process left unfinished. See
+// BusinessOrchestrator.Recover.
+func (m *Merchant) Recover(ctx context.Context) error {
+	return m.orchestrator.Recover(ctx)
+}
+
+// ExecutionsInFlight returns how many paid business executions are running.
+func (m *Merchant) ExecutionsInFlight() int {
+	return m.orchestrator.ExecutionsInFlight()
+}
+
+// PingFacilitator checks that the merchant's facilitator is reachable. See
+// BusinessOrchestrator.PingFacilitator.
+func (m *Merchant) PingFacilitator(ctx context.Context) error {
+	return m.orchestrator.PingFacilitator(ctx)
+}
+
+// Shutdown stops accepting requests, waits for in-flight payments and drains
+// background work such as asynchronous settlements. See
+// BusinessOrchestrator.Shutdown.
+func (m *Merchant) Shutdown(ctx context.Context) error {
+	return m.orchestrator.Shutdown(ctx)
+}
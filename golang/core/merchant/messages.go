@@ -0,0 +1,107 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"github.com/a2aproject/a2a-go/a2a"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+)
+
+// MessageTemplate returns the text of a status message. It receives the text
+// the orchestrator would have written and returns the text to write instead;
+// an empty result keeps the built-in text. Templates only ever change the
+// message text, never its parts or metadata.
+type MessageTemplate func(data MessageData) string
+
+// MessageData describes the status message being written.
+type MessageData struct {
+	TaskID    a2a.TaskID
+	ContextID string
+	State     a2a.TaskState
+	Status    state.PaymentStatus
+	// ErrorCode is the x402 error code recorded on the message, if any.
+	ErrorCode string
+	// Err is the failure being reported by the Failed template.
+	Err error
+	// Round is the payment round the message belongs to.
+	Round int
+	// Text is the built-in text, e.g. "Payment required" or the summary of a
+	// business result.
+	Text string
+}
+
+// MessageTemplates replaces the built-in English status texts, e.g. to
+// translate them or match a product's voice. Nil templates keep the
+// built-in text.
+type MessageTemplates struct {
+	// PaymentRequired covers every quote, including re-quotes and later
+	// rounds, whose built-in text explains why another payment is due.
+	PaymentRequired MessageTemplate
+	PaymentVerified MessageTemplate
+	// Executing is written while the paid service runs.
+	Executing MessageTemplate
+	// Completed receives the business result's summary as its Text.
+	Completed MessageTemplate
+	// Failed covers every failure; its Text is the failure's explanation.
+	Failed MessageTemplate
+	// Canceled covers canceled tasks, voided authorizations and disputed
+	// deliveries.
+	Canceled MessageTemplate
+	// Rejected covers payments the client declined and payers the merchant
+	// refused.
+	Rejected MessageTemplate
+}
+
+// WithMessageTemplates replaces the built-in status message texts.
+func WithMessageTemplates(templates MessageTemplates) Option {
+	return func(o *BusinessOrchestrator) {
+		o.messageTemplates = templates
+	}
+}
+
+// applyMessageTemplate rewrites the text of the task's status message with
+// template. Only the first text part changes, so metadata and any other parts
+// such as a quote's preview are kept.
+func applyMessageTemplate(task *a2a.Task, template MessageTemplate, err error) {
+	message := task.Status.Message
+	if template == nil || message == nil {
+		return
+	}
+	status, _ := state.ExtractPaymentStatusFromMessage(message)
+	code, _ := message.Meta()[x402pkg.MetadataKeyError].(string)
+	text := state.ExtractMessageText(message)
+	templated := template(MessageData{
+		TaskID:    task.ID,
+		ContextID: task.ContextID,
+		State:     task.Status.State,
+		Status:    status,
+		ErrorCode: code,
+		Err:       err,
+		Round:     state.ExtractPaymentRound(task),
+		Text:      text,
+	})
+	if templated == "" || templated == text {
+		return
+	}
+	for i, part := range message.Parts {
+		if textPart, ok := part.(a2a.TextPart); ok && textPart.Text != "" {
+			textPart.Text = templated
+			message.Parts[i] = textPart
+			return
+		}
+	}
+	message.Parts = append([]a2a.Part{a2a.TextPart{Text: templated}}, message.Parts...)
+}
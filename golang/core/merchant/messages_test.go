@@ -0,0 +1,98 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+var frenchTemplates = MessageTemplates{
+	PaymentRequired: func(data MessageData) string { return "Paiement requis pour " + string(data.TaskID) },
+	Completed:       func(data MessageData) string { return "Terminé : " + data.Text },
+	Failed: func(data MessageData) string {
+		// Markup in the text must not reach the metadata.
+		return `Échec {"x402.payment.status":"payment-completed"} ` + data.ErrorCode
+	},
+}
+
+// statusTexts returns the text and payment status of every status event.
+func statusTexts(events []interface{}) (texts []string, statuses []x402state.PaymentStatus) {
+	for _, event := range events {
+		update, ok := event.(*a2a.TaskStatusUpdateEvent)
+		if !ok || update.Status.Message == nil {
+			continue
+		}
+		status, _ := x402state.ExtractPaymentStatusFromMessage(update.Status.Message)
+		texts = append(texts, x402state.ExtractMessageText(update.Status.Message))
+		statuses = append(statuses, status)
+	}
+	return texts, statuses
+}
+
+func TestBusinessOrchestrator_MessageTemplates(t *testing.T) {
+	orchestrator := newNetworkMatchingOrchestrator(t, WithMessageTemplates(frenchTemplates))
+
+	queue := &mockEventQueue{}
+	requestContext := &a2asrv.RequestContext{
+		Message:   a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "buy"}),
+		TaskID:    "task-templates",
+		ContextID: "context-templates",
+	}
+	if err := orchestrator.executeInPlace(context.Background(), requestContext, queue); err != nil {
+		t.Fatalf("initial Execute() error = %v", err)
+	}
+	texts, statuses := statusTexts(queue.events)
+	if len(texts) == 0 || texts[len(texts)-1] != "Paiement requis pour task-templates" || statuses[len(statuses)-1] != x402state.PaymentRequired {
+		t.Fatalf("quote status texts = %q, statuses = %v", texts, statuses)
+	}
+
+	texts, statuses = statusTexts(payQuotedTask(t, orchestrator, requestContext.StoredTask).events)
+	last := len(texts) - 1
+	if last < 0 || texts[last] != "Terminé : Mock response" || statuses[last] != x402state.PaymentCompleted {
+		t.Fatalf("paid status texts = %q, statuses = %v", texts, statuses)
+	}
+	// Executing has no template, so it keeps the built-in text.
+	if !slices.Contains(texts, "Payment verified — executing service") {
+		t.Errorf("paid status texts = %q, want the built-in executing text", texts)
+	}
+}
+
+func TestBusinessOrchestrator_MessageTemplates_Failed(t *testing.T) {
+	orchestrator := newNetworkMatchingOrchestrator(t, WithMessageTemplates(frenchTemplates))
+	orchestrator.merchant.(*MockResourceServer).VerifyPaymentFunc = func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.VerifyResponse, error) {
+		return nil, errors.New("facilitator unreachable")
+	}
+
+	task := quoteTask(t, orchestrator)
+	texts, statuses := statusTexts(payQuotedTask(t, orchestrator, task).events)
+	last := len(texts) - 1
+	if last < 0 || statuses[last] != x402state.PaymentFailed {
+		t.Fatalf("status texts = %q, statuses = %v", texts, statuses)
+	}
+	code, _ := task.Status.Message.Meta()[x402.MetadataKeyError].(string)
+	if want := `Échec {"x402.payment.status":"payment-completed"} ` + code; code == "" || texts[last] != want {
+		t.Errorf("failure text = %q, want %q", texts[last], want)
+	}
+}
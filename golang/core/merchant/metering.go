@@ -0,0 +1,146 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// WithMeteredMinimum sets the least a metered request is charged, in atomic
+// units of the quoted asset, however little usage the business service
+// measured. It only applies to results that report a MeasuredAmount and never
+// raises a charge past what the client authorized.
+func WithMeteredMinimum(amount string) Option {
+	return func(o *BusinessOrchestrator) {
+		o.meteredMinimum = amount
+	}
+}
+
+// parseMeteredMinimum parses the WithMeteredMinimum amount; an empty amount
+// yields nil.
+func parseMeteredMinimum(amount string) (*big.Int, error) {
+	if amount == "" {
+		return nil, nil
+	}
+	minimum, ok := new(big.Int).SetString(amount, 10)
+	if !ok || minimum.Sign() < 0 {
+		return nil, fmt.Errorf("invalid metered minimum %q", amount)
+	}
+	return minimum, nil
+}
+
+// meteringExceededError reports usage measured above the authorized maximum.
+type meteringExceededError struct {
+	measured *big.Int
+	ceiling  *big.Int
+}
+
+func (e *meteringExceededError) Error() string {
+	return fmt.Sprintf("measured amount %s exceeds the authorized maximum %s", e.measured, e.ceiling)
+}
+
+// metering records how a metered settlement amount was reached.
+type metering struct {
+	measured string
+	minimum  string
+	ceiling  string
+	settled  string
+	basis    string
+}
+
+// attach records m in the receipt's Extra under x402.ExtraKeyMetering,
+// copying the map so that one shared with other receipts is left alone.
+// Batched and escrowed settlements, which persist only the requirement,
+// settle the metered amount without the entry.
+func (m *metering) attach(receipt *x402core.SettleResponse) {
+	if m == nil || receipt == nil {
+		return
+	}
+	entry := map[string]interface{}{
+		"measured": m.measured,
+		"ceiling":  m.ceiling,
+		"settled":  m.settled,
+	}
+	if m.minimum != "" {
+		entry["minimum"] = m.minimum
+	}
+	if m.basis != "" {
+		entry["basis"] = m.basis
+	}
+	extra := make(map[string]interface{}, len(receipt.Extra)+1)
+	for key, value := range receipt.Extra {
+		extra[key] = value
+	}
+	extra[x402pkg.ExtraKeyMetering] = entry
+	receipt.Extra = extra
+}
+
+// meteredRequirement returns the requirement to settle for a business result
+// and, for a metered result, how its amount was reached. The measured amount
+// is raised to the metered minimum, never past the authorized maximum, and a
+// measurement above the maximum fails with a *meteringExceededError. Results
+// without a measurement are left to settlementRequirement.
+func (o *BusinessOrchestrator) meteredRequirement(
+	matched *x402types.PaymentRequirements,
+	result *business.Result,
+) (*x402types.PaymentRequirements, *metering, error) {
+	if result == nil || result.MeasuredAmount == "" {
+		requirement, err := settlementRequirement(matched, result)
+		return requirement, nil, err
+	}
+	if matched.Scheme != x402pkg.SchemeUpto {
+		return nil, nil, fmt.Errorf("measured amount %s needs the %q scheme, not %q", result.MeasuredAmount, x402pkg.SchemeUpto, matched.Scheme)
+	}
+
+	measured, ok := new(big.Int).SetString(result.MeasuredAmount, 10)
+	if !ok || measured.Sign() < 0 {
+		return nil, nil, fmt.Errorf("invalid measured amount %q", result.MeasuredAmount)
+	}
+	ceiling, ok := new(big.Int).SetString(matched.Amount, 10)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid authorized amount %q", matched.Amount)
+	}
+	if measured.Cmp(ceiling) > 0 {
+		return nil, nil, &meteringExceededError{measured: measured, ceiling: ceiling}
+	}
+	minimum, err := parseMeteredMinimum(o.meteredMinimum)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	settledAmount := measured
+	if minimum != nil && settledAmount.Cmp(minimum) < 0 {
+		settledAmount = minimum
+		if settledAmount.Cmp(ceiling) > 0 {
+			settledAmount = ceiling
+		}
+	}
+
+	settled := *matched
+	settled.Amount = settledAmount.String()
+	return &settled, &metering{
+		measured: measured.String(),
+		minimum:  o.meteredMinimum,
+		ceiling:  ceiling.String(),
+		settled:  settled.Amount,
+		basis:    result.MeasurementBasis,
+	}, nil
+}
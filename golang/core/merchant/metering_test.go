@@ -0,0 +1,140 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+func TestBusinessOrchestrator_MeteredBilling(t *testing.T) {
+	tests := []struct {
+		name        string
+		measured    string
+		minimum     string
+		wantSettled string
+		wantCode    string
+	}{
+		{name: "under the ceiling", measured: "250000", wantSettled: "250000"},
+		{name: "raised to the minimum", measured: "1000", minimum: "50000", wantSettled: "50000"},
+		{name: "minimum capped at the ceiling", measured: "10", minimum: "2000000", wantSettled: "1000000"},
+		{name: "at the ceiling", measured: "1000000", minimum: "50000", wantSettled: "1000000"},
+		{name: "over the ceiling", measured: "1000001", wantCode: x402.ErrorCodeMeteredAmountExceeded},
+		{name: "not a number", measured: "lots", wantCode: x402.ErrorCodeInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var settled []string
+			orchestrator := newTestOrchestrator(
+				t,
+				&MockResourceServer{
+					BuildPaymentRequirementsFromConfigFunc: func(ctx context.Context, config x402core.ResourceConfig) ([]x402types.PaymentRequirements, error) {
+						return []x402types.PaymentRequirements{{
+							Scheme:  config.Scheme,
+							Network: string(config.Network),
+							PayTo:   config.PayTo,
+							Asset:   "0x456",
+							Amount:  "1000000",
+						}}, nil
+					},
+					SettlePaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.SettleResponse, error) {
+						settled = append(settled, requirements.Amount)
+						return &x402core.SettleResponse{Success: true, Network: x402.NetworkBaseSepolia, Transaction: "0xtx", Amount: requirements.Amount}, nil
+					},
+				},
+				&mockBusinessService{executeFunc: func(ctx context.Context, request business.Request) (*business.Result, error) {
+					if request.PaymentVerified {
+						return &business.Result{
+							Message:          "rendered",
+							SettleAmount:     "999",
+							MeasuredAmount:   tt.measured,
+							MeasurementBasis: "render seconds",
+						}, nil
+					}
+					return nil, business.NewPaymentRequiredError("metered", business.ServiceRequirements{
+						Price:    "1.00",
+						Resource: "/render",
+						Scheme:   x402.SchemeUpto,
+					})
+				}},
+				[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+				WithMeteredMinimum(tt.minimum),
+			)
+
+			task := quoteRequest(t, orchestrator, "task-metered", a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "render"}))
+			submission := paymentSubmission(t, task)
+			if err := submitPayment(orchestrator, task, submission, &mockEventQueue{}); err != nil {
+				t.Fatalf("paid Execute() error = %v", err)
+			}
+
+			if tt.wantCode != "" {
+				if task.Status.State != a2a.TaskStateFailed {
+					t.Fatalf("task state = %v, want %v", task.Status.State, a2a.TaskStateFailed)
+				}
+				if len(settled) != 0 {
+					t.Errorf("settled %v, want the client not charged", settled)
+				}
+				if got := task.Status.Message.Metadata[x402.MetadataKeyError]; got != tt.wantCode {
+					t.Errorf("error code = %v, want %s", got, tt.wantCode)
+				}
+				return
+			}
+
+			if task.Status.State != a2a.TaskStateCompleted {
+				t.Fatalf("task state = %v, want %v", task.Status.State, a2a.TaskStateCompleted)
+			}
+			if len(settled) != 1 || settled[0] != tt.wantSettled {
+				t.Fatalf("settled amounts = %v, want [%s]", settled, tt.wantSettled)
+			}
+			receipts, err := x402state.ExtractPaymentReceipts(task)
+			if err != nil || len(receipts) != 1 {
+				t.Fatalf("ExtractPaymentReceipts() = %v, %v", receipts, err)
+			}
+			entry, _ := receipts[0].Extra[x402.ExtraKeyMetering].(map[string]interface{})
+			want := map[string]interface{}{
+				"measured": tt.measured,
+				"ceiling":  "1000000",
+				"settled":  tt.wantSettled,
+				"basis":    "render seconds",
+			}
+			if tt.minimum != "" {
+				want["minimum"] = tt.minimum
+			}
+			for key, value := range want {
+				if entry[key] != value {
+					t.Errorf("receipt metering[%s] = %v, want %v", key, entry[key], value)
+				}
+			}
+		})
+	}
+}
+
+func TestWithMeteredMinimumRejectsInvalidAmount(t *testing.T) {
+	_, err := NewBusinessOrchestrator(context.Background(), &mockBusinessService{},
+		[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+		WithPaymentServer(&MockResourceServer{}), WithMeteredMinimum("-5"))
+	if err == nil {
+		t.Fatal("NewBusinessOrchestrator() with a negative metered minimum succeeded, want an error")
+	}
+}
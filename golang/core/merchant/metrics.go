@@ -0,0 +1,117 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"expvar"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Metrics receives orchestrator measurements. An empty errorCode means the
+// step succeeded. Implementations must be safe for concurrent use; adapters
+// for Prometheus or OpenTelemetry can be built on this interface without
+// adding dependencies to this package.
+type Metrics interface {
+	QuoteIssued(network string)
+	VerificationCompleted(network, errorCode string, duration time.Duration)
+	SettlementCompleted(network, errorCode string, duration time.Duration)
+	BusinessExecuted(duration time.Duration, err error)
+}
+
+// ExecutionGauge is implemented by Metrics that also track how many paid
+// business executions are running. The orchestrator reports each start and
+// finish as a delta of one.
+type ExecutionGauge interface {
+	AddExecutionsInFlight(delta int)
+}
+
+// WithMetrics instruments the orchestrator. Without it no metrics are kept.
+func WithMetrics(metrics Metrics) Option {
+	return func(o *BusinessOrchestrator) {
+		o.metrics = metrics
+	}
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) QuoteIssued(string)                                  {}
+func (nopMetrics) VerificationCompleted(string, string, time.Duration) {}
+func (nopMetrics) SettlementCompleted(string, string, time.Duration)   {}
+func (nopMetrics) BusinessExecuted(time.Duration, error)               {}
+
+// latencyBuckets are the histogram upper bounds in seconds.
+var latencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// ExpvarMetrics keeps counters and latency histograms in an expvar.Map using
+// Prometheus-style keys, e.g. `settlements_total{network="eip155:8453",code="SETTLEMENT_FAILED"}`.
+type ExpvarMetrics struct {
+	vars *expvar.Map
+}
+
+// NewExpvarMetrics returns metrics stored in a fresh map. Call Publish to
+// expose them on /debug/vars.
+func NewExpvarMetrics() *ExpvarMetrics {
+	return &ExpvarMetrics{vars: new(expvar.Map).Init()}
+}
+
+// Publish registers the metrics under name in the expvar registry. It panics
+// if the name is already taken, like expvar.Publish.
+func (m *ExpvarMetrics) Publish(name string) {
+	expvar.Publish(name, m.vars)
+}
+
+// Vars returns the underlying map.
+func (m *ExpvarMetrics) Vars() *expvar.Map {
+	return m.vars
+}
+
+func (m *ExpvarMetrics) QuoteIssued(network string) {
+	m.vars.Add(fmt.Sprintf("quotes_issued_total{network=%q}", network), 1)
+}
+
+func (m *ExpvarMetrics) VerificationCompleted(network, errorCode string, duration time.Duration) {
+	m.vars.Add(fmt.Sprintf("verifications_total{network=%q,code=%q}", network, errorCode), 1)
+	m.observe("verify_duration_seconds", duration)
+}
+
+func (m *ExpvarMetrics) SettlementCompleted(network, errorCode string, duration time.Duration) {
+	m.vars.Add(fmt.Sprintf("settlements_total{network=%q,code=%q}", network, errorCode), 1)
+	m.observe("settle_duration_seconds", duration)
+}
+
+func (m *ExpvarMetrics) BusinessExecuted(duration time.Duration, err error) {
+	m.observe("business_duration_seconds", duration)
+	if err != nil {
+		m.vars.Add("business_errors_total", 1)
+	}
+}
+
+func (m *ExpvarMetrics) AddExecutionsInFlight(delta int) {
+	m.vars.Add("business_executions_in_flight", int64(delta))
+}
+
+func (m *ExpvarMetrics) observe(name string, duration time.Duration) {
+	seconds := duration.Seconds()
+	for _, bound := range latencyBuckets {
+		if seconds <= bound {
+			m.vars.Add(fmt.Sprintf("%s_bucket{le=%q}", name, strconv.FormatFloat(bound, 'g', -1, 64)), 1)
+		}
+	}
+	m.vars.Add(name+`_bucket{le="+Inf"}`, 1)
+	m.vars.AddFloat(name+"_sum", seconds)
+	m.vars.Add(name+"_count", 1)
+}
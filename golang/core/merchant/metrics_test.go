@@ -0,0 +1,98 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+func TestBusinessOrchestrator_Metrics(t *testing.T) {
+	tests := []struct {
+		name      string
+		settleErr error
+		wantVars  map[string]string
+	}{
+		{
+			name: "settled",
+			wantVars: map[string]string{
+				fmt.Sprintf("quotes_issued_total{network=%q}", x402.NetworkBaseSepolia):           "1",
+				fmt.Sprintf("verifications_total{network=%q,code=\"\"}", x402.NetworkBaseSepolia): "1",
+				fmt.Sprintf("settlements_total{network=%q,code=\"\"}", x402.NetworkBaseSepolia):   "1",
+				"verify_duration_seconds_count":                                                   "1",
+				"settle_duration_seconds_count":                                                   "1",
+				`settle_duration_seconds_bucket{le="+Inf"}`:                                       "1",
+				"business_duration_seconds_count":                                                 "2",
+			},
+		},
+		{
+			name:      "settlement failed",
+			settleErr: errors.New("transaction reverted"),
+			wantVars: map[string]string{
+				fmt.Sprintf("settlements_total{network=%q,code=%q}", x402.NetworkBaseSepolia, x402.ErrorCodeSettlementFailed): "1",
+				"settle_duration_seconds_count": "1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics := NewExpvarMetrics()
+			orchestrator := newTestOrchestrator(
+				t,
+				&MockResourceServer{
+					VerifyPaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.VerifyResponse, error) {
+						return &x402core.VerifyResponse{IsValid: true, Payer: "0x789"}, nil
+					},
+					SettlePaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.SettleResponse, error) {
+						if tt.settleErr != nil {
+							return nil, tt.settleErr
+						}
+						return &x402core.SettleResponse{Success: true, Network: x402.NetworkBaseSepolia, Transaction: "0xtx"}, nil
+					},
+				},
+				&mockBusinessService{},
+				[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+				WithMetrics(metrics),
+				WithSettlementRetry(SettlementRetryPolicy{MaxAttempts: 1}),
+			)
+
+			task := quoteRequest(t, orchestrator, "task-metrics", a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "buy"}))
+			submission := paymentSubmission(t, task)
+			if err := submitPayment(orchestrator, task, submission, &mockEventQueue{}); err != nil {
+				t.Fatalf("paid Execute() error = %v", err)
+			}
+
+			for key, want := range tt.wantVars {
+				got := metrics.Vars().Get(key)
+				if got == nil {
+					t.Errorf("metric %s missing", key)
+					continue
+				}
+				if got.String() != want {
+					t.Errorf("metric %s = %s, want %s", key, got.String(), want)
+				}
+			}
+		})
+	}
+}
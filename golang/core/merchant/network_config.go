@@ -0,0 +1,176 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	svmutils "github.com/x402-foundation/x402/go/mechanisms/svm"
+	"golang.org/x/crypto/sha3"
+)
+
+// normalizeNetworkConfigs checks every config up front so a typo surfaces at
+// startup rather than on the first quote. It returns copies with CAIP-2
+// network names, and reports all problems at once.
+func normalizeNetworkConfigs(configs []types.NetworkConfig, supported []string) ([]types.NetworkConfig, error) {
+	if len(configs) == 0 {
+		return nil, fmt.Errorf("no network configurations provided")
+	}
+
+	var errs []error
+	seen := make(map[string]int, len(configs))
+	normalized := make([]types.NetworkConfig, len(configs))
+	for i, config := range configs {
+		config.NetworkName = x402pkg.NormalizeNetwork(config.NetworkName)
+		config.PayToAddress = strings.TrimSpace(config.PayToAddress)
+		if config.Splits != nil {
+			config.Splits = slices.Clone(config.Splits)
+			for j := range config.Splits {
+				config.Splits[j].Address = strings.TrimSpace(config.Splits[j].Address)
+			}
+		}
+		if config.ResourcePayTo != nil {
+			resourcePayTo := make(map[string]string, len(config.ResourcePayTo))
+			for resource, address := range config.ResourcePayTo {
+				resourcePayTo[resource] = strings.TrimSpace(address)
+			}
+			config.ResourcePayTo = resourcePayTo
+		}
+		if config.AllowedAssets != nil {
+			config.AllowedAssets = slices.Clone(config.AllowedAssets)
+			for j := range config.AllowedAssets {
+				config.AllowedAssets[j] = strings.TrimSpace(config.AllowedAssets[j])
+			}
+		}
+		normalized[i] = config
+
+		network := config.NetworkName
+		if network == "" {
+			errs = append(errs, fmt.Errorf("network config %d: network name is required", i))
+			continue
+		}
+		if !slices.Contains(supported, network) {
+			errs = append(errs, fmt.Errorf("network config %d: unsupported network %q: no scheme server is registered for it", i, configs[i].NetworkName))
+			continue
+		}
+		if first, ok := seen[network]; ok {
+			errs = append(errs, fmt.Errorf("network config %d: duplicate network %s (also config %d)", i, network, first))
+		} else {
+			seen[network] = i
+		}
+		if err := validatePayTo(network, config.PayToAddress); err != nil {
+			errs = append(errs, fmt.Errorf("network config %d (%s): %w", i, network, err))
+		}
+		for _, err := range validateSplits(network, config.Splits) {
+			errs = append(errs, fmt.Errorf("network config %d (%s): %w", i, network, err))
+		}
+		for _, resource := range slices.Sorted(maps.Keys(config.ResourcePayTo)) {
+			if resource == "" {
+				errs = append(errs, fmt.Errorf("network config %d (%s): resource payTo: resource is required", i, network))
+				continue
+			}
+			if err := validatePayTo(network, config.ResourcePayTo[resource]); err != nil {
+				errs = append(errs, fmt.Errorf("network config %d (%s): resource %q: %w", i, network, resource, err))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid network configuration: %w", errors.Join(errs...))
+	}
+	return normalized, nil
+}
+
+func validatePayTo(network, address string) error {
+	if address == "" {
+		return fmt.Errorf("payTo address is required")
+	}
+	switch {
+	case x402pkg.IsEVMNetwork(network):
+		if !isEVMAddress(address) {
+			return fmt.Errorf("payTo %q is not a valid EVM address", address)
+		}
+	case x402pkg.IsSolanaNetwork(network):
+		if !svmutils.ValidateSolanaAddress(address) {
+			return fmt.Errorf("payTo %q is not a valid Solana address", address)
+		}
+	}
+	return nil
+}
+
+// validateSplits checks that every split recipient has a valid address and a
+// positive share, and that the shares add up to the whole payment.
+func validateSplits(network string, splits []types.SplitRecipient) []error {
+	if len(splits) == 0 {
+		return nil
+	}
+	var errs []error
+	total := 0
+	for i, split := range splits {
+		if err := validatePayTo(network, split.Address); err != nil {
+			errs = append(errs, fmt.Errorf("split recipient %d: %w", i, err))
+		}
+		if split.BasisPoints <= 0 {
+			errs = append(errs, fmt.Errorf("split recipient %d: basis points must be positive, got %d", i, split.BasisPoints))
+		}
+		total += split.BasisPoints
+	}
+	if total != splitBasisPoints {
+		errs = append(errs, fmt.Errorf("split basis points sum to %d, want %d", total, splitBasisPoints))
+	}
+	return errs
+}
+
+// isEVMAddress accepts 0x-prefixed 20-byte hex addresses. Mixed-case
+// addresses must carry a valid EIP-55 checksum; all-lower or all-upper
+// addresses are accepted as unchecksummed.
+func isEVMAddress(address string) bool {
+	digits, ok := strings.CutPrefix(address, "0x")
+	if !ok || len(digits) != 40 {
+		return false
+	}
+	if _, err := hex.DecodeString(digits); err != nil {
+		return false
+	}
+	if digits == strings.ToLower(digits) || digits == strings.ToUpper(digits) {
+		return true
+	}
+	return digits == eip55(digits)
+}
+
+func eip55(digits string) string {
+	lower := strings.ToLower(digits)
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write([]byte(lower))
+	sum := hash.Sum(nil)
+
+	checksummed := []byte(lower)
+	for i, c := range checksummed {
+		nibble := sum[i/2]
+		if i%2 == 0 {
+			nibble >>= 4
+		}
+		if c >= 'a' && nibble&0xf >= 8 {
+			checksummed[i] = c - 'a' + 'A'
+		}
+	}
+	return string(checksummed)
+}
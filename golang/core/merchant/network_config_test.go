@@ -0,0 +1,161 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+)
+
+const (
+	evmPayTo    = "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+	solanaPayTo = "9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM"
+)
+
+func TestNormalizeNetworkConfigs(t *testing.T) {
+	tests := []struct {
+		name         string
+		configs      []types.NetworkConfig
+		wantNetworks []string
+		wantErrs     []string
+	}{
+		{
+			name: "valid CAIP-2 configs",
+			configs: []types.NetworkConfig{
+				{NetworkName: x402.NetworkBaseSepolia, PayToAddress: evmPayTo},
+				{NetworkName: x402.NetworkSolanaDevnet, PayToAddress: solanaPayTo},
+			},
+			wantNetworks: []string{x402.NetworkBaseSepolia, x402.NetworkSolanaDevnet},
+		},
+		{
+			name: "legacy aliases are normalized",
+			configs: []types.NetworkConfig{
+				{NetworkName: "base-sepolia", PayToAddress: strings.ToLower(evmPayTo)},
+				{NetworkName: "solana-devnet", PayToAddress: solanaPayTo},
+			},
+			wantNetworks: []string{x402.NetworkBaseSepolia, x402.NetworkSolanaDevnet},
+		},
+		{
+			name:     "empty",
+			wantErrs: []string{"no network configurations provided"},
+		},
+		{
+			name: "bad addresses",
+			configs: []types.NetworkConfig{
+				{NetworkName: x402.NetworkBase, PayToAddress: solanaPayTo},
+				{NetworkName: x402.NetworkSolanaMainnet, PayToAddress: evmPayTo},
+				{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x5AAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"},
+				{NetworkName: x402.NetworkSolanaDevnet},
+			},
+			wantErrs: []string{
+				"config 0 (eip155:8453): payTo",
+				"config 1 (" + x402.NetworkSolanaMainnet + "): payTo",
+				"config 2 (eip155:84532): payTo",
+				"config 3 (" + x402.NetworkSolanaDevnet + "): payTo address is required",
+			},
+		},
+		{
+			name: "valid splits",
+			configs: []types.NetworkConfig{
+				{NetworkName: x402.NetworkBaseSepolia, PayToAddress: evmPayTo, Splits: []types.SplitRecipient{
+					{Address: evmPayTo, BasisPoints: 1000},
+					{Address: " " + strings.ToLower(evmPayTo) + " ", BasisPoints: 9000},
+				}},
+			},
+			wantNetworks: []string{x402.NetworkBaseSepolia},
+		},
+		{
+			name: "bad splits",
+			configs: []types.NetworkConfig{
+				{NetworkName: x402.NetworkBaseSepolia, PayToAddress: evmPayTo, Splits: []types.SplitRecipient{
+					{Address: evmPayTo, BasisPoints: 1000},
+					{Address: evmPayTo, BasisPoints: 8000},
+				}},
+				{NetworkName: x402.NetworkBase, PayToAddress: evmPayTo, Splits: []types.SplitRecipient{
+					{Address: solanaPayTo, BasisPoints: 10000},
+					{Address: evmPayTo, BasisPoints: 0},
+				}},
+			},
+			wantErrs: []string{
+				"config 0 (eip155:84532): split basis points sum to 9000, want 10000",
+				"config 1 (eip155:8453): split recipient 0: payTo",
+				"config 1 (eip155:8453): split recipient 1: basis points must be positive",
+			},
+		},
+		{
+			name: "bad resource payTo",
+			configs: []types.NetworkConfig{
+				{NetworkName: x402.NetworkBaseSepolia, PayToAddress: evmPayTo, ResourcePayTo: map[string]string{
+					"/images": " " + evmPayTo + " ",
+					"/video":  solanaPayTo,
+					"":        evmPayTo,
+				}},
+			},
+			wantErrs: []string{
+				"config 0 (eip155:84532): resource payTo: resource is required",
+				`config 0 (eip155:84532): resource "/video": payTo`,
+			},
+		},
+		{
+			name: "duplicate after normalization",
+			configs: []types.NetworkConfig{
+				{NetworkName: x402.NetworkBaseSepolia, PayToAddress: evmPayTo},
+				{NetworkName: "base-sepolia", PayToAddress: evmPayTo},
+			},
+			wantErrs: []string{"config 1: duplicate network eip155:84532 (also config 0)"},
+		},
+		{
+			name: "unknown networks",
+			configs: []types.NetworkConfig{
+				{NetworkName: "base-seplia", PayToAddress: evmPayTo},
+				{NetworkName: "eip155:10", PayToAddress: evmPayTo},
+				{PayToAddress: evmPayTo},
+			},
+			wantErrs: []string{
+				`config 0: unsupported network "base-seplia"`,
+				`config 1: unsupported network "eip155:10"`,
+				"config 2: network name is required",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeNetworkConfigs(tt.configs, registeredNetworks(DefaultSchemeServers()))
+			if len(tt.wantErrs) > 0 {
+				if err == nil {
+					t.Fatal("normalizeNetworkConfigs() error = nil")
+				}
+				for _, want := range tt.wantErrs {
+					if !strings.Contains(err.Error(), want) {
+						t.Errorf("error %q does not mention %q", err, want)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("normalizeNetworkConfigs() error = %v", err)
+			}
+			for i, config := range got {
+				if config.NetworkName != tt.wantNetworks[i] {
+					t.Errorf("config %d network = %q, want %q", i, config.NetworkName, tt.wantNetworks[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"time"
+
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402 "github.com/x402-foundation/x402/go"
+)
+
+// Option configures optional BusinessOrchestrator behavior.
+type Option func(*BusinessOrchestrator)
+
+// WithFacilitatorURL sets the facilitator NewBusinessOrchestrator verifies and
+// settles payments through. It is required unless WithFacilitatorClient,
+// WithResourceServer or WithPaymentServer is given, or WithNetworkFacilitators
+// covers every configured network.
+func WithFacilitatorURL(url string) Option {
+	return func(o *BusinessOrchestrator) {
+		o.facilitatorURL = url
+	}
+}
+
+// WithPaymentServer verifies and settles through server instead of a resource
+// server built for the facilitator; the facilitator and scheme server options
+// are then ignored. It is mostly useful for tests.
+func WithPaymentServer(server ResourceServer) Option {
+	return func(o *BusinessOrchestrator) {
+		o.merchant = server
+	}
+}
+
+// WithExtensionChecker replaces how the extensions a client activated are
+// read. The default uses the extensions on the a2asrv request context.
+func WithExtensionChecker(checker ExtensionChecker) Option {
+	return func(o *BusinessOrchestrator) {
+		o.extensionChecker = checker
+	}
+}
+
+// WithPaymentStateStore persists payment state at every transition so a
+// restarted merchant can finish payments that were in flight.
+func WithPaymentStateStore(store PaymentStateStore) Option {
+	return func(o *BusinessOrchestrator) {
+		o.stateStore = store
+	}
+}
+
+// SettlementPolicy decides whether payment is settled before or after the
+// business logic runs.
+type SettlementPolicy int
+
+const (
+	// ExecuteThenSettle runs the business logic first and settles only if it
+	// succeeds, so clients are never charged for failed work.
+	ExecuteThenSettle SettlementPolicy = iota
+	// SettleThenExecute settles first, so output is only produced once the
+	// merchant has been paid.
+	SettleThenExecute
+)
+
+// WithSettlementPolicy selects the settlement ordering. The default is
+// ExecuteThenSettle.
+func WithSettlementPolicy(policy SettlementPolicy) Option {
+	return func(o *BusinessOrchestrator) {
+		o.settlementPolicy = policy
+	}
+}
+
+// WithClock replaces the time source used for local payment checks.
+func WithClock(now func() time.Time) Option {
+	return func(o *BusinessOrchestrator) {
+		o.now = now
+	}
+}
+
+// WithClockSkew sets how far the payer's clock may drift from ours before an
+// authorization window is considered closed. The default is five seconds.
+func WithClockSkew(skew time.Duration) Option {
+	return func(o *BusinessOrchestrator) {
+		o.clockSkew = skew
+	}
+}
+
+// WithSkillRouter replaces how the invoked agent card skill is determined. The
+// default reads SkillMetadataKey from the incoming message.
+func WithSkillRouter(router SkillRouter) Option {
+	return func(o *BusinessOrchestrator) {
+		o.skillRouter = router
+	}
+}
+
+// WithSchemeServer registers an additional scheme server, for example to
+// accept payments on another EVM chain the x402 SDK supports. Legacy network
+// names are normalized to CAIP-2.
+func WithSchemeServer(network string, server x402.SchemeNetworkServer) Option {
+	return func(o *BusinessOrchestrator) {
+		o.schemeServers = append(o.schemeServers, SchemeRegistration{
+			Network: x402pkg.NormalizeNetwork(network),
+			Server:  server,
+		})
+	}
+}
+
+// WithoutDefaultNetworks skips the built-in Base and Solana scheme servers so
+// only those added with WithSchemeServer are registered.
+func WithoutDefaultNetworks() Option {
+	return func(o *BusinessOrchestrator) {
+		o.withoutDefaultNetworks = true
+	}
+}
+
+// WithTransactionText appends a "Paid — tx ... on <network>" line to the
+// completion text for each settled payment. The transaction references are
+// always available in the x402.payment.receipts.tx metadata.
+func WithTransactionText() Option {
+	return func(o *BusinessOrchestrator) {
+		o.transactionText = true
+	}
+}
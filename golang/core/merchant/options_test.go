@@ -0,0 +1,221 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402core "github.com/x402-foundation/x402/go"
+)
+
+func TestNewBusinessOrchestrator_Options(t *testing.T) {
+	ctx := context.Background()
+	configs := []types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: evmPayTo}}
+	payments := &MockResourceServer{}
+	checker := newMockExtensionCheckerWithX402()
+	logger := slog.New(slog.DiscardHandler)
+	metrics := NewExpvarMetrics()
+	store := NewMemoryPaymentStateStore()
+	prebuilt, err := NewResourceServerWithFacilitator(ctx, &fakeFacilitator{networks: []string{x402.NetworkBaseSepolia}})
+	if err != nil {
+		t.Fatalf("NewResourceServerWithFacilitator() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		opts  []Option
+		check func(t *testing.T, o *BusinessOrchestrator)
+	}{
+		{
+			name: "facilitator URL",
+			opts: []Option{WithFacilitatorURL(newSupportedFacilitator(t, x402.NetworkBaseSepolia))},
+			check: func(t *testing.T, o *BusinessOrchestrator) {
+				if _, ok := o.merchant.(*resourceServerWrapper); !ok {
+					t.Errorf("merchant = %T, want resource server for the facilitator", o.merchant)
+				}
+			},
+		},
+		{
+			name: "facilitator client",
+			opts: []Option{WithFacilitatorClient(&fakeFacilitator{networks: []string{x402.NetworkBaseSepolia}})},
+			check: func(t *testing.T, o *BusinessOrchestrator) {
+				if _, ok := o.merchant.(*resourceServerWrapper); !ok {
+					t.Errorf("merchant = %T, want resource server for the facilitator client", o.merchant)
+				}
+			},
+		},
+		{
+			name: "resource server",
+			opts: []Option{WithResourceServer(prebuilt)},
+			check: func(t *testing.T, o *BusinessOrchestrator) {
+				if wrapper, ok := o.merchant.(*resourceServerWrapper); !ok || wrapper.server != prebuilt {
+					t.Errorf("merchant = %#v, want the pre-built resource server", o.merchant)
+				}
+			},
+		},
+		{
+			name: "payment server",
+			opts: []Option{WithPaymentServer(payments)},
+			check: func(t *testing.T, o *BusinessOrchestrator) {
+				if o.merchant != payments {
+					t.Errorf("merchant = %#v, want the injected payment server", o.merchant)
+				}
+			},
+		},
+		{
+			name: "extension checker",
+			opts: []Option{WithPaymentServer(payments), WithExtensionChecker(checker)},
+			check: func(t *testing.T, o *BusinessOrchestrator) {
+				if o.extensionChecker != checker {
+					t.Errorf("extensionChecker = %#v, want the injected checker", o.extensionChecker)
+				}
+			},
+		},
+		{
+			name: "settlement policy",
+			opts: []Option{WithPaymentServer(payments), WithSettlementPolicy(SettleThenExecute)},
+			check: func(t *testing.T, o *BusinessOrchestrator) {
+				if o.settlementPolicy != SettleThenExecute {
+					t.Errorf("settlementPolicy = %v, want SettleThenExecute", o.settlementPolicy)
+				}
+			},
+		},
+		{
+			name: "hooks",
+			opts: []Option{WithPaymentServer(payments), WithHooks(Hooks{OnSettled: func(context.Context, *a2a.Task, *x402core.SettleResponse) {}})},
+			check: func(t *testing.T, o *BusinessOrchestrator) {
+				if o.hooks.OnSettled == nil {
+					t.Error("hooks.OnSettled = nil, want the injected hook")
+				}
+			},
+		},
+		{
+			name: "logger",
+			opts: []Option{WithPaymentServer(payments), WithLogger(logger)},
+			check: func(t *testing.T, o *BusinessOrchestrator) {
+				if o.logger != logger {
+					t.Error("logger was not replaced")
+				}
+			},
+		},
+		{
+			name: "metrics",
+			opts: []Option{WithPaymentServer(payments), WithMetrics(metrics)},
+			check: func(t *testing.T, o *BusinessOrchestrator) {
+				if o.metrics != metrics {
+					t.Errorf("metrics = %#v, want the injected metrics", o.metrics)
+				}
+			},
+		},
+		{
+			name: "payment state store",
+			opts: []Option{WithPaymentServer(payments), WithPaymentStateStore(store)},
+			check: func(t *testing.T, o *BusinessOrchestrator) {
+				if o.stateStore != store {
+					t.Errorf("stateStore = %#v, want the injected store", o.stateStore)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o, err := NewBusinessOrchestrator(ctx, &mockBusinessService{}, configs, tt.opts...)
+			if err != nil {
+				t.Fatalf("NewBusinessOrchestrator() error = %v", err)
+			}
+			tt.check(t, o)
+		})
+	}
+}
+
+// orchestratorDefaults is the part of an orchestrator that options configure
+// and that can be compared directly.
+func orchestratorDefaults(o *BusinessOrchestrator) []any {
+	return []any{
+		o.extensionChecker, o.stateStore, o.settlementRetry, o.settlementPolicy, o.asyncSettlement,
+		o.clockSkew, o.skillRouter, o.hooks.OnSettled == nil, o.metrics, o.logger.Handler(), o.pricing,
+		o.discountPolicy, o.payerPolicy, o.transactionText, o.receiptSigner, o.maxPaymentRounds,
+		o.maxRequotes, o.settlementBuffer, o.eventWrites, o.promptRetention, o.promptPointer, o.webhooks,
+	}
+}
+
+func TestNewBusinessOrchestrator_Defaults(t *testing.T) {
+	ctx := context.Background()
+	configs := []types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: evmPayTo}}
+
+	if _, err := NewBusinessOrchestrator(ctx, &mockBusinessService{}, configs); err == nil || !strings.Contains(err.Error(), "facilitatorURL is required") {
+		t.Errorf("NewBusinessOrchestrator() without a facilitator error = %v, want facilitatorURL is required", err)
+	}
+
+	payments := &MockResourceServer{}
+	got, err := NewBusinessOrchestrator(ctx, &mockBusinessService{}, configs, WithPaymentServer(payments))
+	if err != nil {
+		t.Fatalf("NewBusinessOrchestrator() error = %v", err)
+	}
+	want := NewBusinessOrchestratorWithDeps(payments, &mockBusinessService{}, configs, nil)
+	if !reflect.DeepEqual(orchestratorDefaults(got), orchestratorDefaults(want)) {
+		t.Errorf("defaults = %#v, want %#v", orchestratorDefaults(got), orchestratorDefaults(want))
+	}
+	if _, ok := got.extensionChecker.(*defaultExtensionChecker); !ok {
+		t.Errorf("extensionChecker = %T, want the request context checker", got.extensionChecker)
+	}
+}
+
+func TestNewBusinessOrchestratorWithFacilitatorURL(t *testing.T) {
+	configs := []types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: evmPayTo}}
+	o, err := NewBusinessOrchestratorWithFacilitatorURL(context.Background(), newSupportedFacilitator(t, x402.NetworkBaseSepolia), &mockBusinessService{}, configs)
+	if err != nil {
+		t.Fatalf("NewBusinessOrchestratorWithFacilitatorURL() error = %v", err)
+	}
+	if _, ok := o.merchant.(*resourceServerWrapper); !ok {
+		t.Errorf("merchant = %T, want resource server for the facilitator", o.merchant)
+	}
+}
+
+func TestNewBusinessOrchestrator_AppliesOptionsOnce(t *testing.T) {
+	configs := []types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: evmPayTo}}
+	applied := 0
+	counting := func(*BusinessOrchestrator) { applied++ }
+
+	if _, err := NewBusinessOrchestrator(context.Background(), &mockBusinessService{}, configs,
+		WithPaymentServer(&MockResourceServer{}), counting); err != nil {
+		t.Fatalf("NewBusinessOrchestrator() error = %v", err)
+	}
+	if applied != 1 {
+		t.Errorf("option applied %d times, want once", applied)
+	}
+}
+
+func TestNewBusinessOrchestratorWithDeps_LogsInvalidOptions(t *testing.T) {
+	configs := []types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: evmPayTo}}
+	var buf bytes.Buffer
+	o := NewBusinessOrchestratorWithDeps(&MockResourceServer{}, &mockBusinessService{}, configs, nil,
+		WithLogger(slog.New(slog.NewJSONHandler(&buf, nil))),
+		WithPaymentStateStore(NewMemoryPaymentStateStore()), WithRecovery(RecoveryConfig{}))
+	if o == nil {
+		t.Fatal("NewBusinessOrchestratorWithDeps() = nil, want an orchestrator")
+	}
+	if !strings.Contains(buf.String(), "task store") {
+		t.Errorf("logs = %q, want the recovery configuration error", buf.String())
+	}
+}
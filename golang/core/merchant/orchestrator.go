@@ -18,6 +18,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/a2aproject/a2a-go/a2a"
 	"github.com/a2aproject/a2a-go/a2asrv"
@@ -26,52 +30,315 @@ import (
 	"github.com/google-agentic-commerce/a2a-x402/core/types"
 	"github.com/google-agentic-commerce/a2a-x402/core/x402"
 	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	"golang.org/x/sync/semaphore"
 )
 
 type BusinessOrchestrator struct {
-	merchant         ResourceServer
-	businessService  business.BusinessService
-	networkConfigs   []types.NetworkConfig
-	extensionChecker ExtensionChecker
+	merchant          ResourceServer
+	businessService   business.BusinessService
+	networkConfigs    []types.NetworkConfig
+	extensionChecker  ExtensionChecker
+	stateStore        PaymentStateStore
+	settlementRetry   SettlementRetryPolicy
+	settlementPolicy  SettlementPolicy
+	asyncSettlement   *asyncSettler
+	deferredExecution *deferredExecutor
+	now               func() time.Time
+	clockSkew         time.Duration
+	skillRouter       SkillRouter
+	hooks             hookRunner
+	metrics           Metrics
+	logger            *slog.Logger
+	pricing           PricingProvider
+	discountPolicy    DiscountPolicy
+	payerPolicy       PayerPolicy
+
+	facilitatorURL         string
+	facilitatorOptions     FacilitatorOptions
+	facilitatorClient      x402core.FacilitatorClient
+	resourceServer         *x402core.X402ResourceServer
+	schemeServers          []SchemeRegistration
+	withoutDefaultNetworks bool
+	transactionText        bool
+	receiptSigner          ReceiptSigner
+	taskLocks              taskLocks
+	maxPaymentRounds       int
+	maxRequotes            int
+	maxOptionRetries       int
+	settlementBuffer       time.Duration
+	eventWrites            EventWritePolicy
+	promptRetention        PromptRetention
+	promptPointer          string
+	prompts                promptCache
+	webhooks               *webhookOutbox
+	compensationHandler    CompensationHandler
+	splitLedger            SplitLedger
+	batchSettlement        *batchSettler
+	subscriptionPolicy     SubscriptionPolicy
+	subscriptionAudiences  []string
+	claimNonces            claimNonces
+	escrow                 *escrowHolder
+	maxPayloadRetries      int
+	maxPaymentAttempts     int
+	requireTaskBinding     bool
+	receipts               ReceiptStore
+	lifecycle              lifecycle
+	skipStartupPing        bool
+	sandbox                bool
+	confirmations          ConfirmationPolicy
+	confirmationClientOnce sync.Once
+	confirmationClient     ConfirmationClient
+	rejectUnlistedAssets   bool
+	tracer                 Tracer
+	messageTemplates       MessageTemplates
+	quoteJanitor           *quoteJanitor
+	failures               FailureStore
+	auditLogger            AuditLogger
+	auditFailClosed        bool
+	push                   *pushOutbox
+	meteredMinimum         string
+	overpayment            OverpaymentPolicy
+	payTo                  PayToProvider
+	payToFallback          bool
+	trustPolicy            TrustPolicy
+	networkFacilitators    map[string]string
+	settlement             SettlementConfig
+	refunds                *RefundConfig
+	recovery               *RecoveryConfig
+
+	maxConcurrentExecutions int
+	executionQueueTimeout   time.Duration
+	executionSlots          *semaphore.Weighted
+	executionsInFlight      atomic.Int64
 }
 
-// NewBusinessOrchestrator creates a new orchestrator with real dependencies (production use)
+// NewBusinessOrchestrator creates an orchestrator for businessService quoting
+// on networkConfigs. Payments are verified and settled through the facilitator
+// at WithFacilitatorURL unless WithFacilitatorClient, WithResourceServer or
+// WithPaymentServer supplies another; everything else defaults to the
+// behavior described on each option.
 func NewBusinessOrchestrator(
 	ctx context.Context,
-	facilitatorURL string,
 	businessService business.BusinessService,
 	networkConfigs []types.NetworkConfig,
+	opts ...Option,
 ) (*BusinessOrchestrator, error) {
-	resourceServer, err := NewResourceServer(ctx, facilitatorURL)
+	o, err := newBusinessOrchestrator(businessService, networkConfigs, opts...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create x402 resource server: %w", err)
+		return nil, err
+	}
+	merchant := o.merchant
+	if o.sandbox {
+		if merchant, err = newSandboxServer(ctx, o.schemeRegistrations()); err != nil {
+			return nil, err
+		}
+	} else if merchant == nil {
+		resourceServer := o.resourceServer
+		if resourceServer == nil {
+			schemes := o.schemeRegistrations()
+			if len(schemes) == 0 {
+				return nil, fmt.Errorf("no scheme servers registered")
+			}
+			facilitator := o.facilitatorClient
+			facilitatorURL := o.facilitatorURL
+			if facilitator == nil && (facilitatorURL != "" || len(o.networkFacilitators) == 0) {
+				httpFacilitator, err := newHTTPFacilitatorClient(facilitatorURL, o.facilitatorOptions)
+				if err != nil {
+					return nil, fmt.Errorf("failed to create x402 resource server: %w", err)
+				}
+				facilitator = httpFacilitator
+			}
+			if len(o.networkFacilitators) > 0 {
+				router, err := newNetworkFacilitatorRouter(o.networkFacilitators, facilitator, o.facilitatorOptions)
+				if err != nil {
+					return nil, fmt.Errorf("failed to create x402 resource server: %w", err)
+				}
+				// The default URL no longer names every facilitator a
+				// health check reaches.
+				facilitator, facilitatorURL = router, ""
+			}
+			if router, ok := facilitator.(*FacilitatorRouter); ok {
+				networks := make([]string, len(networkConfigs))
+				for i, networkConfig := range networkConfigs {
+					networks[i] = networkConfig.NetworkName
+				}
+				if err := router.checkNetworks(networks); err != nil {
+					return nil, err
+				}
+			}
+			wrapper := &resourceServerWrapper{
+				facilitator:    facilitator,
+				facilitatorURL: facilitatorURL,
+				pingTimeout:    o.facilitatorOptions.pingTimeout(),
+			}
+			if !o.skipStartupPing {
+				if err := wrapper.PingFacilitator(ctx); err != nil {
+					return nil, err
+				}
+			}
+			if wrapper.server, err = NewResourceServerWithFacilitator(ctx, facilitator, schemes...); err != nil {
+				return nil, fmt.Errorf("failed to create x402 resource server: %w", err)
+			}
+			merchant = wrapper
+		} else {
+			merchant = &resourceServerWrapper{server: resourceServer}
+		}
+	}
+
+	o.merchant = merchant
+	o.start()
+	if o.sandbox {
+		o.warnSandbox(ctx)
 	}
+	return o, nil
+}
 
-	merchant := &resourceServerWrapper{server: resourceServer}
+// NewBusinessOrchestratorWithFacilitatorURL creates an orchestrator that
+// settles through the facilitator at facilitatorURL.
+//
+// Deprecated: Use NewBusinessOrchestrator with WithFacilitatorURL.
+func NewBusinessOrchestratorWithFacilitatorURL(
+	ctx context.Context,
+	facilitatorURL string,
+	businessService business.BusinessService,
+	networkConfigs []types.NetworkConfig,
+	opts ...Option,
+) (*BusinessOrchestrator, error) {
+	opts = append([]Option{WithFacilitatorURL(facilitatorURL)}, opts...)
+	return NewBusinessOrchestrator(ctx, businessService, networkConfigs, opts...)
+}
 
-	return &BusinessOrchestrator{
-		merchant:         merchant,
-		businessService:  businessService,
-		networkConfigs:   networkConfigs,
-		extensionChecker: DefaultExtensionChecker(),
-	}, nil
+// schemeRegistrations returns the scheme servers the resource server should
+// register: the defaults unless WithoutDefaultNetworks was given, followed by
+// any added with WithSchemeServer.
+func (o *BusinessOrchestrator) schemeRegistrations() []SchemeRegistration {
+	var schemes []SchemeRegistration
+	if !o.withoutDefaultNetworks {
+		schemes = DefaultSchemeServers()
+	}
+	return append(schemes, o.schemeServers...)
 }
 
-// NewBusinessOrchestratorWithDeps creates a new orchestrator with dependency injection support (for testing)
+// NewBusinessOrchestratorWithDeps creates an orchestrator around an existing
+// ResourceServer and ExtensionChecker. A nil extensionChecker reads the
+// extensions activated on the request context.
+//
+// Deprecated: Use NewBusinessOrchestrator with WithPaymentServer and
+// WithExtensionChecker. Unlike NewBusinessOrchestrator it cannot return an
+// error, so an invalid configuration is only logged.
 func NewBusinessOrchestratorWithDeps(
 	merchant ResourceServer,
 	businessService business.BusinessService,
 	networkConfigs []types.NetworkConfig,
 	extensionChecker ExtensionChecker,
+	opts ...Option,
+) *BusinessOrchestrator {
+	opts = append([]Option{WithPaymentServer(merchant)}, opts...)
+	if extensionChecker != nil {
+		opts = append([]Option{WithExtensionChecker(extensionChecker)}, opts...)
+	}
+	o := configureOrchestrator(businessService, networkConfigs, opts...)
+	if err := o.validate(); err != nil {
+		o.logger.Error("invalid orchestrator options", "error", err)
+	}
+	o.start()
+	return o
+}
+
+// newBusinessOrchestrator applies opts once over the defaults and checks the
+// resulting configuration. The background workers are not running until
+// start is called.
+func newBusinessOrchestrator(
+	businessService business.BusinessService,
+	networkConfigs []types.NetworkConfig,
+	opts ...Option,
+) (*BusinessOrchestrator, error) {
+	o := configureOrchestrator(businessService, networkConfigs, opts...)
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// configureOrchestrator applies opts once over the defaults.
+func configureOrchestrator(
+	businessService business.BusinessService,
+	networkConfigs []types.NetworkConfig,
+	opts ...Option,
 ) *BusinessOrchestrator {
-	if extensionChecker == nil {
-		extensionChecker = DefaultExtensionChecker()
+	o := &BusinessOrchestrator{
+		businessService:    businessService,
+		networkConfigs:     networkConfigs,
+		settlementRetry:    DefaultSettlementRetryPolicy(),
+		now:                time.Now,
+		clockSkew:          defaultClockSkew,
+		skillRouter:        MetadataSkillRouter{},
+		metrics:            nopMetrics{},
+		tracer:             nopTracer{},
+		logger:             discardLogger(),
+		pricing:            StablecoinPricingProvider{},
+		maxPaymentRounds:   DefaultMaxPaymentRounds,
+		maxRequotes:        DefaultMaxRequotes,
+		maxOptionRetries:   DefaultMaxOptionRetries,
+		maxPayloadRetries:  DefaultMaxPayloadRetries,
+		maxPaymentAttempts: DefaultMaxPaymentAttempts,
+		settlementBuffer:   DefaultSettlementBuffer,
+		eventWrites:        DefaultEventWritePolicy(),
+		promptPointer:      DefaultPromptPointer,
+
+		executionQueueTimeout: DefaultExecutionQueueTimeout,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// validate checks that the configured options work together.
+func (o *BusinessOrchestrator) validate() error {
+	if _, err := parseMeteredMinimum(o.meteredMinimum); err != nil {
+		return err
+	}
+	if err := o.validateSettlement(); err != nil {
+		return err
+	}
+	if err := o.validateRefunds(); err != nil {
+		return err
+	}
+	return o.validateRecovery()
+}
+
+// start fills in the defaults that depend on other options and starts the
+// background workers the options configured.
+func (o *BusinessOrchestrator) start() {
+	o.hooks.logger = o.logger
+	if o.maxConcurrentExecutions > 0 {
+		o.executionSlots = semaphore.NewWeighted(int64(o.maxConcurrentExecutions))
+	}
+	if o.extensionChecker == nil {
+		o.extensionChecker = DefaultExtensionChecker()
+	}
+	if o.asyncSettlement != nil {
+		o.asyncSettlement.start(o)
+	}
+	if o.batchSettlement != nil {
+		o.batchSettlement.start(o)
+	}
+	if o.deferredExecution != nil {
+		o.deferredExecution.start(o)
+	}
+	if o.escrow != nil {
+		o.escrow.start(o)
 	}
-	return &BusinessOrchestrator{
-		merchant:         merchant,
-		businessService:  businessService,
-		networkConfigs:   networkConfigs,
-		extensionChecker: extensionChecker,
+	if o.webhooks != nil {
+		o.webhooks.start(o)
+	}
+	if o.quoteJanitor != nil {
+		o.quoteJanitor.start(o)
+	}
+	if o.push != nil {
+		o.push.start(o)
 	}
 }
 
@@ -80,29 +347,120 @@ func (o *BusinessOrchestrator) Execute(
 	requestContext *a2asrv.RequestContext,
 	eventQueue eventqueue.Queue,
 ) error {
-	message := requestContext.Message
+	_, err := o.handle(ctx, requestContext, eventQueue)
+	return err
+}
 
-	task := requestContext.StoredTask
-	if requestContext.Message.TaskID == "" && task == nil {
+// handle runs Execute and returns the task as the execution left it. The
+// stored task in requestContext is never modified: a2asrv keeps processing
+// it on another goroutine, so the execution works on a copy and reports its
+// changes only through events.
+func (o *BusinessOrchestrator) handle(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	eventQueue eventqueue.Queue,
+) (*a2a.Task, error) {
+	var task *a2a.Task
+	if requestContext.StoredTask != nil {
 		var err error
+		task, err = deepCopy(requestContext.StoredTask)
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy stored task: %w", err)
+		}
+	}
+	return o.handleTask(ctx, requestContext, task, eventQueue)
+}
+
+// handleTask runs the execution on task, which it updates in place, or on a
+// new task when task is nil.
+func (o *BusinessOrchestrator) handleTask(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	eventQueue eventqueue.Queue,
+) (*a2a.Task, error) {
+	ctx, span := o.startSpan(ctx, SpanExecute, requestContext.TaskID)
+	task, err := o.execute(ctx, requestContext, task, eventQueue)
+	setSpanErrorCode(span, task)
+	span.End(err)
+	return task, err
+}
+
+func (o *BusinessOrchestrator) execute(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	eventQueue eventqueue.Queue,
+) (*a2a.Task, error) {
+	if !o.lifecycle.enter() {
+		return task, ErrShuttingDown
+	}
+	defer o.lifecycle.exit()
+
+	unlock, err := o.taskLocks.lock(ctx, requestContext.TaskID)
+	if err != nil {
+		return task, err
+	}
+	defer unlock()
+
+	if task == nil && requestContext.Message.TaskID == "" {
 		task, err = o.createTask(ctx, requestContext, eventQueue)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 	if task == nil {
-		return fmt.Errorf("stored task is required for task %s", requestContext.Message.TaskID)
+		return nil, fmt.Errorf("stored task is required for task %s", requestContext.Message.TaskID)
 	}
+	return task, o.executeTask(ctx, requestContext, task, eventQueue)
+}
 
-	if err := o.ensureExtension(ctx, requestContext, task, eventQueue); err != nil {
+// executeTask advances the payment flow of task, the execution's own copy,
+// for the request's message.
+func (o *BusinessOrchestrator) executeTask(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	eventQueue eventqueue.Queue,
+) error {
+	message := requestContext.Message
+
+	extensionCtx, span := o.startSpan(ctx, SpanExtensionCheck, task.ID)
+	err := o.ensureExtension(extensionCtx, requestContext, task, eventQueue)
+	span.End(err)
+	if err != nil {
+		return err
+	}
+	if !task.Status.State.Terminal() {
+		if err := o.restorePaymentState(ctx, task); err != nil {
+			return o.transitionToTaskFailed(ctx, requestContext, task, eventQueue,
+				fmt.Errorf("failed to restore payment state: %w", err), x402.ErrorCodeInternal)
+		}
+	}
+	if handled, err := o.resumeInterruptedSettlement(ctx, requestContext, task, eventQueue); handled {
+		return err
+	}
+	if handled, err := o.handleDuplicateSubmission(ctx, requestContext, task, eventQueue); handled {
 		return err
 	}
-	if task.Status.State == a2a.TaskStateFailed || task.Status.State == a2a.TaskStateCompleted {
+	if task.Status.State.Terminal() {
 		return nil
 	}
 
+	if o.paymentAttemptsExhausted(task, message) {
+		return o.failTooManyAttempts(ctx, requestContext, task, eventQueue)
+	}
+
 	paymentState, err := state.ExtractPaymentState(task, message)
 	if err != nil {
+		// A client that sent an undecodable payload can resend it; metadata
+		// the merchant stored on the task that no longer decodes cannot be
+		// repaired by the client.
+		if submissionMalformed(task, message) {
+			if rejected, rejectErr := o.rejectMalformedPayload(ctx, requestContext, task, eventQueue, err); rejected {
+				return rejectErr
+			}
+		}
 		if hasPaymentMetadata(task, message) {
 			partialState := &state.PaymentState{}
 			partialState.Requirements, _ = state.ExtractPaymentRequirements(task)
@@ -114,85 +472,172 @@ func (o *BusinessOrchestrator) Execute(
 				eventQueue,
 				partialState,
 				fmt.Errorf("failed to extract payment state: %w", err),
-				x402.ErrorCodeInvalidSignature,
+				x402.ErrorCodeInvalidPayload,
 				nil,
 			)
 			return failureErr
 		}
 		return o.transitionToTaskFailed(ctx, requestContext, task, eventQueue,
-			fmt.Errorf("failed to extract payment state: %w", err))
+			fmt.Errorf("failed to extract payment state: %w", err), x402.ErrorCodeInternal)
+	}
+	// A held delivery takes the rejection as a dispute.
+	if status, _ := state.ExtractPaymentStatusFromMessage(message); status == state.PaymentRejected &&
+		paymentState.Status != state.PaymentRejected && paymentState.Status != state.PaymentDeliveryPendingAck {
+		return fmt.Errorf("%w: task %s has no open quote to reject", a2a.ErrInvalidParams, task.ID)
 	}
 
-	for {
-		if task.Status.State == a2a.TaskStateFailed {
-			return nil
-		}
-		if task.Status.State == a2a.TaskStateCompleted {
-			return nil
+	err = o.runStateMachine(ctx, requestContext, task, eventQueue, paymentState,
+		func(paymentState *state.PaymentState) (*state.PaymentState, bool, error) {
+			return o.step(ctx, requestContext, task, eventQueue, message, paymentState)
+		})
+	// A request that ended the task stops its deferred execution so the
+	// worker cannot write an outcome over it.
+	if o.deferredExecution != nil && task.Status.State.Terminal() {
+		o.deferredExecution.cancel(task.ID)
+	}
+	return err
+}
+
+// step runs the handler for the current payment status. It returns the next
+// payment state, or done once the execution has finished.
+func (o *BusinessOrchestrator) step(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	eventQueue eventqueue.Queue,
+	message *a2a.Message,
+	paymentState *state.PaymentState,
+) (*state.PaymentState, bool, error) {
+	switch paymentState.Status {
+	case state.PaymentRequired:
+		if paymentState.Payload != nil {
+			paymentState.Status = state.PaymentSubmitted
+			return o.submittedStep(ctx, requestContext, task, eventQueue, paymentState)
 		}
+		return nil, true, nil
 
-		switch paymentState.Status {
-		case state.PaymentRequired:
-			if paymentState.Payload != nil {
-				paymentState.Status = state.PaymentSubmitted
-				var err error
-				paymentState, err = o.handlePaymentSubmitted(ctx, requestContext, task, eventQueue, paymentState)
-				if err != nil {
-					if task.Status.State == a2a.TaskStateFailed {
-						return nil
-					}
-					return err
-				}
-				continue
-			}
-			return nil
+	case state.PaymentSubmitted:
+		return o.submittedStep(ctx, requestContext, task, eventQueue, paymentState)
 
-		case state.PaymentSubmitted:
-			var err error
-			paymentState, err = o.handlePaymentSubmitted(ctx, requestContext, task, eventQueue, paymentState)
-			if err != nil {
-				if task.Status.State == a2a.TaskStateFailed {
-					return nil
-				}
-				return err
-			}
+	case state.PaymentVerified:
+		next, err := o.handlePaymentVerified(ctx, requestContext, task, eventQueue, paymentState)
+		return next, err != nil || next.Status == paymentDeferred || next.Status == state.PaymentDeliveryPendingAck, err
 
-		case state.PaymentVerified:
-			var err error
-			paymentState, err = o.handlePaymentVerified(ctx, requestContext, task, eventQueue, paymentState)
-			if err != nil {
-				return err
-			}
+	case state.PaymentDeliveryPendingAck:
+		return nil, true, o.handleDeliveryAck(ctx, requestContext, task, eventQueue, message, paymentState)
 
-		case state.PaymentCompleted:
-			return o.transitionToCompleted(ctx, requestContext, task, eventQueue, paymentState)
+	case state.PaymentCompleted:
+		return nil, true, o.transitionToCompleted(ctx, requestContext, task, eventQueue, paymentState)
 
-		default:
-			prompt := state.ExtractMessageText(message)
-			if err := o.transitionToWorking(ctx, requestContext, task, eventQueue); err != nil {
-				return err
-			}
-			businessResult, businessErr := o.businessService.Execute(ctx, business.Request{Prompt: prompt})
-			if businessErr == nil {
-				return o.transitionToBusinessCompleted(ctx, requestContext, task, eventQueue, businessResult)
-			}
+	case state.PaymentRejected:
+		if status, _ := state.ExtractPaymentStatusFromMessage(message); status != state.PaymentRejected {
+			// The merchant rejected an unusable option and the quote is
+			// still open; only the client declining cancels the task.
+			return nil, true, nil
+		}
+		return nil, true, o.transitionToPaymentRejected(ctx, requestContext, task, eventQueue, state.ExtractMessageText(message))
 
-			var paymentRequired *business.PaymentRequiredError
-			if !errors.As(businessErr, &paymentRequired) {
-				return o.transitionToTaskFailed(ctx, requestContext, task, eventQueue,
-					fmt.Errorf("business execution failed: %w", businessErr))
-			}
+	default:
+		prompt, err := o.extractPrompt(ctx, message)
+		if err != nil {
+			return nil, true, o.transitionToTaskFailed(ctx, requestContext, task, eventQueue, err, x402.ErrorCodeInvalidRequest)
+		}
+		skillID, err := o.skillRouter.ResolveSkill(ctx, message)
+		if err != nil {
+			return nil, true, o.transitionToTaskFailed(ctx, requestContext, task, eventQueue,
+				fmt.Errorf("failed to resolve skill: %w", err), x402.ErrorCodeInvalidRequest)
+		}
+		if client := o.trustedClient(ctx, requestContext, task); client != nil {
+			return nil, true, o.executeTrusted(ctx, requestContext, task, eventQueue, message, prompt, skillID, client)
+		}
+		if subscription := o.coveringSubscription(ctx, requestContext, task, message, skillID); subscription != nil {
+			return nil, true, o.executeSubscribed(ctx, requestContext, task, eventQueue, message, prompt, skillID, subscription)
+		}
+		if err := o.transitionToWorking(ctx, requestContext, task, eventQueue); err != nil {
+			return nil, true, err
+		}
+		started := time.Now()
+		businessCtx, span := o.startSpan(ctx, SpanBusinessExecute, task.ID)
+		businessResult, businessErr := o.businessService.Execute(businessCtx, business.Request{
+			Prompt:    prompt,
+			SkillID:   skillID,
+			TaskID:    task.ID,
+			ContextID: task.ContextID,
+			Message:   message,
+			Metadata:  state.RequestMetadata(message),
+			Round:     1,
+		})
+		var paymentRequired *business.PaymentRequiredError
+		if errors.As(businessErr, &paymentRequired) {
+			o.metrics.BusinessExecuted(time.Since(started), nil)
+			span.End(nil)
+		} else {
+			o.metrics.BusinessExecuted(time.Since(started), businessErr)
+			span.End(businessErr)
+		}
+		if businessErr == nil {
+			return nil, true, o.transitionToBusinessCompleted(ctx, requestContext, task, eventQueue, businessResult, nil)
+		}
+
+		if paymentRequired == nil {
+			return nil, true, o.transitionToTaskFailed(ctx, requestContext, task, eventQueue,
+				fmt.Errorf("business execution failed: %w", businessErr), x402.ErrorCodeBusinessExecutionFailed)
+		}
 
-			paymentState, err := o.buildPaymentRequirements(ctx, paymentRequired)
+		paymentRequired, discounts := o.applyDiscounts(ctx, requestContext, task, paymentRequired)
+		if isFree(paymentRequired) {
+			freeResult, err := o.executePaidRequest(ctx, requestContext, eventQueue, business.Request{
+				Prompt:          prompt,
+				PaymentVerified: true,
+				Free:            true,
+				SkillID:         skillID,
+				TaskID:          task.ID,
+				ContextID:       task.ContextID,
+				Message:         message,
+				Metadata:        state.RequestMetadata(message),
+				Round:           1,
+			})
 			if err != nil {
-				return o.transitionToTaskFailed(ctx, requestContext, task, eventQueue,
-					fmt.Errorf("failed to create payment requirements: %w", err))
+				return nil, true, o.transitionToTaskFailed(ctx, requestContext, task, eventQueue, err, businessErrorCode(err))
 			}
-			return o.transitionToPaymentRequired(ctx, requestContext, task, eventQueue, paymentState)
+			return nil, true, o.transitionToBusinessCompleted(ctx, requestContext, task, eventQueue, freeResult, func(message *a2a.Message) {
+				state.SetPaymentStatus(message, state.PaymentNotRequired)
+				setDiscountMetadata(message, discounts)
+			})
+		}
+
+		// The paid execution runs on the stored prompt, so refuse to quote
+		// for a request that has none rather than fail after payment.
+		if prompt == "" {
+			return nil, true, o.transitionToTaskFailed(ctx, requestContext, task, eventQueue,
+				errors.New("request has no prompt: send a text part or a data part with a prompt"), x402.ErrorCodeInvalidRequest)
+		}
+		paymentState, err := o.buildPaymentRequirements(ctx, task.ID, paymentRequired, discounts)
+		if err != nil {
+			return nil, true, o.transitionToTaskFailed(ctx, requestContext, task, eventQueue,
+				fmt.Errorf("failed to create payment requirements: %w", err), x402.ErrorCodeInternal)
 		}
+		return nil, true, o.transitionToPaymentRequired(ctx, requestContext, task, eventQueue, paymentState, prompt, skillID, state.RequestMetadata(message), discounts)
 	}
 }
 
+func (o *BusinessOrchestrator) submittedStep(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	eventQueue eventqueue.Queue,
+	paymentState *state.PaymentState,
+) (*state.PaymentState, bool, error) {
+	next, err := o.handlePaymentSubmitted(ctx, requestContext, task, eventQueue, paymentState)
+	if err != nil {
+		if task.Status.State == a2a.TaskStateFailed {
+			return nil, true, nil
+		}
+		return nil, true, err
+	}
+	return next, false, nil
+}
+
 func hasPaymentMetadata(task *a2a.Task, message *a2a.Message) bool {
 	var taskMessage *a2a.Message
 	if task != nil {
@@ -218,10 +663,31 @@ func (o *BusinessOrchestrator) Cancel(
 	requestContext *a2asrv.RequestContext,
 	queue eventqueue.Queue,
 ) error {
-	message := a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{Text: "Task cancelled"})
-	event := a2a.NewStatusUpdateEvent(requestContext, a2a.TaskStateFailed, message)
-	event.Final = true
-	return queue.Write(ctx, event)
+	o.taskLocks.requestCancel(requestContext.TaskID)
+	if o.deferredExecution != nil {
+		o.deferredExecution.cancel(requestContext.TaskID)
+	}
+	unlock, err := o.taskLocks.lock(ctx, requestContext.TaskID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	task := requestContext.StoredTask
+	if task == nil {
+		message := a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{Text: "Task canceled"})
+		event := a2a.NewStatusUpdateEvent(requestContext, a2a.TaskStateCanceled, message)
+		event.Final = true
+		return o.writeEvent(ctx, nil, queue, event)
+	}
+	if task.Status.State.Terminal() {
+		// Replay the final status instead of overwriting a finished task.
+		return o.writeEvent(ctx, task, queue, statusEvent(requestContext, task))
+	}
+	if o.escrow != nil {
+		o.escrow.release(ctx, o, task)
+	}
+	return o.transitionToCanceled(ctx, requestContext, task, queue)
 }
 
 func (o *BusinessOrchestrator) ensureExtension(
@@ -234,7 +700,7 @@ func (o *BusinessOrchestrator) ensureExtension(
 	if !ok {
 		errorMsg := "x402 extension is required but not active. Client must send X-A2A-Extensions header with value: " + x402.X402ExtensionURI
 		err := fmt.Errorf("%s", errorMsg)
-		if transitionErr := o.transitionToTaskFailed(ctx, requestContext, task, eventQueue, err); transitionErr != nil {
+		if transitionErr := o.transitionToTaskFailed(ctx, requestContext, task, eventQueue, err, x402.ErrorCodeExtensionRequired); transitionErr != nil {
 			return fmt.Errorf("failed to transition to failed state: %w", transitionErr)
 		}
 		return err
@@ -246,7 +712,7 @@ func (o *BusinessOrchestrator) ensureExtension(
 	if !extensions.Requested(x402Extension) {
 		errorMsg := "x402 extension is required but not active. Client must send X-A2A-Extensions header with value: " + x402.X402ExtensionURI
 		err := fmt.Errorf("%s", errorMsg)
-		if transitionErr := o.transitionToTaskFailed(ctx, requestContext, task, eventQueue, err); transitionErr != nil {
+		if transitionErr := o.transitionToTaskFailed(ctx, requestContext, task, eventQueue, err, x402.ErrorCodeExtensionRequired); transitionErr != nil {
 			return fmt.Errorf("failed to transition to failed state: %w", transitionErr)
 		}
 		return err
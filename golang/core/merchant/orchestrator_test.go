@@ -16,11 +16,16 @@ package merchant
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/a2aproject/a2a-go/a2a"
 	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/a2aproject/a2a-go/a2asrv/eventqueue"
 	"github.com/google-agentic-commerce/a2a-x402/core/business"
 	"github.com/google-agentic-commerce/a2a-x402/core/types"
 	"github.com/google-agentic-commerce/a2a-x402/core/x402"
@@ -125,6 +130,124 @@ func newMockExtensionCheckerWithX402() *MockExtensionChecker {
 	}
 }
 
+// newTestOrchestrator creates an orchestrator that verifies and settles
+// through payments and sees the x402 extension activated on every request.
+func newTestOrchestrator(
+	t *testing.T,
+	payments ResourceServer,
+	service business.BusinessService,
+	networks []types.NetworkConfig,
+	opts ...Option,
+) *BusinessOrchestrator {
+	t.Helper()
+	opts = append([]Option{WithPaymentServer(payments), WithExtensionChecker(newMockExtensionCheckerWithX402())}, opts...)
+	o, err := NewBusinessOrchestrator(context.Background(), service, networks, opts...)
+	if err != nil {
+		t.Fatalf("NewBusinessOrchestrator() error = %v", err)
+	}
+	return o
+}
+
+// executeInPlace runs an execution the way tests without a task store observe
+// it: the stored task is updated in place rather than copied, and a new task
+// becomes requestContext.StoredTask.
+func (o *BusinessOrchestrator) executeInPlace(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	eventQueue eventqueue.Queue,
+) error {
+	task, err := o.handleTask(ctx, requestContext, requestContext.StoredTask, eventQueue)
+	if task != nil {
+		requestContext.StoredTask = task
+	}
+	return err
+}
+
+// quoteTask sends a first request for task-option and returns the task it
+// quoted.
+func quoteTask(t *testing.T, orchestrator *BusinessOrchestrator) *a2a.Task {
+	t.Helper()
+	return quoteRequest(t, orchestrator, "task-option", a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "buy"}))
+}
+
+// quoteRequest sends message as the first request for taskID, in context
+// context-<name> for task task-<name>, and returns the task it created.
+func quoteRequest(t *testing.T, orchestrator *BusinessOrchestrator, taskID a2a.TaskID, message *a2a.Message) *a2a.Task {
+	t.Helper()
+	requestContext := &a2asrv.RequestContext{
+		Message:   message,
+		TaskID:    taskID,
+		ContextID: "context-" + strings.TrimPrefix(string(taskID), "task-"),
+	}
+	if err := orchestrator.executeInPlace(context.Background(), requestContext, &mockEventQueue{}); err != nil {
+		t.Fatalf("initial Execute() error = %v", err)
+	}
+	return requestContext.StoredTask
+}
+
+// paymentSubmission returns a message paying task's first quoted option with
+// a payload signed "0x<task ID>". edit may adjust the payload first.
+func paymentSubmission(t *testing.T, task *a2a.Task, edit ...func(*x402types.PaymentPayload)) *a2a.Message {
+	t.Helper()
+	requirements, err := x402state.ExtractPaymentRequirements(task)
+	if err != nil || requirements == nil {
+		t.Fatalf("ExtractPaymentRequirements() = %v, %v", requirements, err)
+	}
+	payload := &x402types.PaymentPayload{
+		X402Version: x402.X402Version,
+		Accepted:    requirements.Accepts[0],
+		Payload:     map[string]interface{}{"signature": "0x" + string(task.ID)},
+	}
+	for _, e := range edit {
+		e(payload)
+	}
+	submission, err := x402state.EncodePaymentSubmission(task.ID, payload)
+	if err != nil {
+		t.Fatalf("EncodePaymentSubmission() error = %v", err)
+	}
+	return submission
+}
+
+// submitPayment sends submission for task, which the execution updates in
+// place, writing its events to queue.
+func submitPayment(orchestrator *BusinessOrchestrator, task *a2a.Task, submission *a2a.Message, queue eventqueue.Queue) error {
+	return orchestrator.executeInPlace(context.Background(), &a2asrv.RequestContext{
+		Message:    submission,
+		StoredTask: task,
+		TaskID:     task.ID,
+		ContextID:  task.ContextID,
+	}, queue)
+}
+
+// quotePayment quotes taskID and returns the quoted task with a payment
+// submission for it.
+func quotePayment(t *testing.T, orchestrator *BusinessOrchestrator, taskID a2a.TaskID) (*a2a.Task, *a2a.Message) {
+	t.Helper()
+	task := quoteRequest(t, orchestrator, taskID, a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "render"}))
+	return task, paymentSubmission(t, task)
+}
+
+// payTask quotes and pays a new task, returning once the paying request does.
+func payTask(t *testing.T, orchestrator *BusinessOrchestrator, taskID a2a.TaskID) *a2a.Task {
+	t.Helper()
+	task, submission := quotePayment(t, orchestrator, taskID)
+	if err := submitPayment(orchestrator, task, submission, &mockEventQueue{}); err != nil {
+		t.Fatalf("paid Execute() error = %v", err)
+	}
+	return task
+}
+
+// payQuotedTask pays task's first quoted option and returns the events the
+// paying request wrote. edit may adjust the payload first.
+func payQuotedTask(t *testing.T, orchestrator *BusinessOrchestrator, task *a2a.Task, edit ...func(*x402types.PaymentPayload)) *mockEventQueue {
+	t.Helper()
+	queue := &mockEventQueue{}
+	if err := submitPayment(orchestrator, task, paymentSubmission(t, task, edit...), queue); err != nil {
+		t.Fatalf("paid Execute() error = %v", err)
+	}
+	return queue
+}
+
 type mockEventQueue struct {
 	events []interface{}
 }
@@ -229,13 +352,14 @@ func TestBusinessOrchestrator_ensureExtension(t *testing.T) {
 			mockQueue.events = nil
 			mockMerchant := &MockResourceServer{}
 
-			orchestrator := NewBusinessOrchestratorWithDeps(
+			orchestrator := newTestOrchestrator(
+				t,
 				mockMerchant,
 				mockService,
 				[]types.NetworkConfig{
 					{NetworkName: "eip155:84532", PayToAddress: "0x123"},
 				},
-				tt.checker,
+				WithExtensionChecker(tt.checker),
 			)
 
 			task := &a2a.Task{
@@ -274,6 +398,7 @@ func TestBusinessOrchestrator_Execute_PaymentFlow(t *testing.T) {
 	paymentRequirements := x402types.PaymentRequirements{
 		Scheme:  "exact",
 		Network: x402.NetworkBaseSepolia,
+		Amount:  "100",
 		PayTo:   "0x123",
 		Asset:   "0x456",
 	}
@@ -334,15 +459,14 @@ func TestBusinessOrchestrator_Execute_PaymentFlow(t *testing.T) {
 	}
 
 	mockQueue := &mockEventQueue{}
-	mockExtensionChecker := newMockExtensionCheckerWithX402()
 
-	orchestrator := NewBusinessOrchestratorWithDeps(
+	orchestrator := newTestOrchestrator(
+		t,
 		mockMerchant,
 		mockService,
 		[]types.NetworkConfig{
 			{NetworkName: "eip155:84532", PayToAddress: "0x123"},
 		},
-		mockExtensionChecker,
 	)
 
 	task := &a2a.Task{
@@ -369,7 +493,7 @@ func TestBusinessOrchestrator_Execute_PaymentFlow(t *testing.T) {
 		ContextID:  "context-456",
 	}
 
-	err := orchestrator.Execute(ctx, requestContext, mockQueue)
+	err := orchestrator.executeInPlace(ctx, requestContext, mockQueue)
 	if err != nil {
 		t.Fatalf("Execute() error = %v", err)
 	}
@@ -404,7 +528,6 @@ func TestBusinessOrchestrator_Execute_PaymentFlow(t *testing.T) {
 }
 
 func TestBusinessOrchestrator_Execute_DynamicPaymentFlow(t *testing.T) {
-	ctx := context.Background()
 	requirement := x402types.PaymentRequirements{
 		Scheme:            "exact",
 		Network:           x402.NetworkBaseSepolia,
@@ -452,46 +575,19 @@ func TestBusinessOrchestrator_Execute_DynamicPaymentFlow(t *testing.T) {
 			return &business.Result{Message: "paid result", Artifacts: []*a2a.Artifact{artifact}}, nil
 		},
 	}
-	mockQueue := &mockEventQueue{}
-	orchestrator := NewBusinessOrchestratorWithDeps(
+	orchestrator := newTestOrchestrator(
+		t,
 		mockMerchant,
 		mockService,
 		[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
-		newMockExtensionCheckerWithX402(),
 	)
 
-	initialMessage := a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "dynamic request"})
-	initialContext := &a2asrv.RequestContext{
-		Message:   initialMessage,
-		TaskID:    "task-dynamic",
-		ContextID: "context-dynamic",
-	}
-	if err := orchestrator.Execute(ctx, initialContext, mockQueue); err != nil {
-		t.Fatalf("initial Execute() error = %v", err)
-	}
-	task := initialContext.StoredTask
+	task := quoteRequest(t, orchestrator, "task-dynamic", a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "dynamic request"}))
 	if task.Status.State != a2a.TaskStateInputRequired {
 		t.Fatalf("initial task state = %v, want input-required", task.Status.State)
 	}
 
-	payload := &x402types.PaymentPayload{
-		X402Version: x402.X402Version,
-		Accepted:    requirement,
-		Payload:     map[string]any{"signature": "0xabc"},
-	}
-	paymentMessage, err := x402state.EncodePaymentSubmission(task.ID, payload)
-	if err != nil {
-		t.Fatalf("EncodePaymentSubmission() error = %v", err)
-	}
-	paymentContext := &a2asrv.RequestContext{
-		Message:    paymentMessage,
-		StoredTask: task,
-		TaskID:     task.ID,
-		ContextID:  task.ContextID,
-	}
-	if err := orchestrator.Execute(ctx, paymentContext, mockQueue); err != nil {
-		t.Fatalf("paid Execute() error = %v", err)
-	}
+	payQuotedTask(t, orchestrator, task)
 
 	if len(calls) != 2 || calls[0].PaymentVerified || !calls[1].PaymentVerified {
 		t.Fatalf("business calls = %#v, want unverified then verified", calls)
@@ -520,6 +616,7 @@ func TestBusinessOrchestrator_handlePaymentSubmitted(t *testing.T) {
 	paymentRequirements := x402types.PaymentRequirements{
 		Scheme:  "exact",
 		Network: x402.NetworkBaseSepolia,
+		Amount:  "100",
 		PayTo:   "0x123",
 		Asset:   "0x456",
 	}
@@ -600,15 +697,14 @@ func TestBusinessOrchestrator_handlePaymentSubmitted(t *testing.T) {
 
 			mockService := &mockBusinessService{}
 			mockQueue := &mockEventQueue{}
-			mockExtensionChecker := newMockExtensionCheckerWithX402()
 
-			orchestrator := NewBusinessOrchestratorWithDeps(
+			orchestrator := newTestOrchestrator(
+				t,
 				mockMerchant,
 				mockService,
 				[]types.NetworkConfig{
 					{NetworkName: "eip155:84532", PayToAddress: "0x123"},
 				},
-				mockExtensionChecker,
 			)
 
 			task := &a2a.Task{
@@ -669,14 +765,14 @@ func TestBusinessOrchestrator_handlePaymentSubmitted(t *testing.T) {
 func TestBusinessOrchestrator_Execute_MalformedPaymentReturnsPaymentFailed(t *testing.T) {
 	ctx := context.Background()
 	serviceCalled := false
-	orchestrator := NewBusinessOrchestratorWithDeps(
+	orchestrator := newTestOrchestrator(
+		t,
 		&MockResourceServer{},
 		&mockBusinessService{executeFunc: func(ctx context.Context, request business.Request) (*business.Result, error) {
 			serviceCalled = true
 			return &business.Result{Message: "unexpected"}, nil
 		}},
 		[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
-		newMockExtensionCheckerWithX402(),
 	)
 
 	task := &a2a.Task{
@@ -714,7 +810,7 @@ func TestBusinessOrchestrator_Execute_MalformedPaymentReturnsPaymentFailed(t *te
 	}
 	mockQueue := &mockEventQueue{}
 
-	if err := orchestrator.Execute(ctx, requestContext, mockQueue); err != nil {
+	if err := orchestrator.executeInPlace(ctx, requestContext, mockQueue); err != nil {
 		t.Fatalf("Execute() error = %v", err)
 	}
 	if serviceCalled {
@@ -724,7 +820,7 @@ func TestBusinessOrchestrator_Execute_MalformedPaymentReturnsPaymentFailed(t *te
 	if err != nil || status != x402state.PaymentFailed {
 		t.Errorf("payment status = %v, error = %v", status, err)
 	}
-	if got := task.Status.Message.Metadata[x402.MetadataKeyError]; got != x402.ErrorCodeInvalidSignature {
+	if got := task.Status.Message.Metadata[x402.MetadataKeyError]; got != x402.ErrorCodeInvalidPayload {
 		t.Errorf("payment error code = %v", got)
 	}
 	receipts, err := x402state.ExtractPaymentReceipts(task)
@@ -736,17 +832,116 @@ func TestBusinessOrchestrator_Execute_MalformedPaymentReturnsPaymentFailed(t *te
 	}
 }
 
+func TestBusinessOrchestrator_Execute_RefusesRejectWithoutOpenQuote(t *testing.T) {
+	orchestrator := newTestOrchestrator(
+		t,
+		&MockResourceServer{},
+		&mockBusinessService{},
+		[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+	)
+
+	task := &a2a.Task{
+		ID:        "task-verified",
+		ContextID: "context-verified",
+		Status: a2a.TaskStatus{
+			State:   a2a.TaskStateWorking,
+			Message: a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{Text: "Payment verified"}),
+		},
+	}
+	x402state.SetPaymentStatus(task.Status.Message, x402state.PaymentVerified)
+
+	message := x402state.EncodePaymentRejection(task.ID, "changed my mind")
+	message.ContextID = task.ContextID
+	requestContext := &a2asrv.RequestContext{
+		Message:    message,
+		StoredTask: task,
+		TaskID:     task.ID,
+		ContextID:  task.ContextID,
+	}
+	mockQueue := &mockEventQueue{}
+
+	err := orchestrator.executeInPlace(context.Background(), requestContext, mockQueue)
+	if !errors.Is(err, a2a.ErrInvalidParams) {
+		t.Fatalf("Execute() error = %v, want %v", err, a2a.ErrInvalidParams)
+	}
+	if len(mockQueue.events) != 0 {
+		t.Errorf("events = %d, want 0", len(mockQueue.events))
+	}
+	if task.Status.State != a2a.TaskStateWorking {
+		t.Errorf("task state = %v, want %v", task.Status.State, a2a.TaskStateWorking)
+	}
+	status, err := x402state.ExtractPaymentStatusFromTask(task)
+	if err != nil || status != x402state.PaymentVerified {
+		t.Errorf("payment status = %v, error = %v", status, err)
+	}
+}
+
+func TestBusinessOrchestrator_Execute_PaymentRejectedCancelsTask(t *testing.T) {
+	ctx := context.Background()
+	serviceCalled := false
+	orchestrator := newTestOrchestrator(
+		t,
+		&MockResourceServer{},
+		&mockBusinessService{executeFunc: func(ctx context.Context, request business.Request) (*business.Result, error) {
+			serviceCalled = true
+			return &business.Result{Message: "unexpected"}, nil
+		}},
+		[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+	)
+
+	task := &a2a.Task{
+		ID:        "task-rejected",
+		ContextID: "context-rejected",
+		Status: a2a.TaskStatus{
+			State:   a2a.TaskStateInputRequired,
+			Message: a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{Text: "Payment required"}),
+		},
+	}
+	x402state.SetPaymentStatus(task.Status.Message, x402state.PaymentRequired)
+
+	message := x402state.EncodePaymentRejection(task.ID, "Payment requirements expired")
+	message.ContextID = task.ContextID
+	requestContext := &a2asrv.RequestContext{
+		Message:    message,
+		StoredTask: task,
+		TaskID:     task.ID,
+		ContextID:  task.ContextID,
+	}
+	mockQueue := &mockEventQueue{}
+
+	if err := orchestrator.executeInPlace(ctx, requestContext, mockQueue); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if serviceCalled {
+		t.Error("business service must not run for rejected payment")
+	}
+	if task.Status.State != a2a.TaskStateCanceled {
+		t.Errorf("task state = %v, want %v", task.Status.State, a2a.TaskStateCanceled)
+	}
+	status, err := x402state.ExtractPaymentStatusFromTask(task)
+	if err != nil || status != x402state.PaymentRejected {
+		t.Errorf("payment status = %v, error = %v", status, err)
+	}
+	if len(mockQueue.events) != 1 {
+		t.Fatalf("events = %d, want 1", len(mockQueue.events))
+	}
+	event, ok := mockQueue.events[0].(*a2a.TaskStatusUpdateEvent)
+	if !ok || !event.Final {
+		t.Errorf("expected final status update, got %#v", mockQueue.events[0])
+	}
+}
+
 func TestBusinessOrchestrator_Execute_MissingSubmittedPayloadReturnsPaymentFailed(t *testing.T) {
 	ctx := context.Background()
 	serviceCalled := false
-	orchestrator := NewBusinessOrchestratorWithDeps(
+	orchestrator := newTestOrchestrator(
+		t,
 		&MockResourceServer{},
 		&mockBusinessService{executeFunc: func(ctx context.Context, request business.Request) (*business.Result, error) {
 			serviceCalled = true
 			return &business.Result{Message: "unexpected"}, nil
 		}},
 		[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
-		newMockExtensionCheckerWithX402(),
 	)
 
 	task := &a2a.Task{
@@ -780,7 +975,7 @@ func TestBusinessOrchestrator_Execute_MissingSubmittedPayloadReturnsPaymentFaile
 		ContextID:  task.ContextID,
 	}
 
-	if err := orchestrator.Execute(ctx, requestContext, &mockEventQueue{}); err != nil {
+	if err := orchestrator.executeInPlace(ctx, requestContext, &mockEventQueue{}); err != nil {
 		t.Fatalf("Execute() error = %v", err)
 	}
 	if serviceCalled {
@@ -801,6 +996,7 @@ func TestBusinessOrchestrator_handlePaymentVerified(t *testing.T) {
 	paymentRequirements := x402types.PaymentRequirements{
 		Scheme:  "exact",
 		Network: x402.NetworkBaseSepolia,
+		Amount:  "100",
 		PayTo:   "0x123",
 		Asset:   "0x456",
 	}
@@ -844,7 +1040,7 @@ func TestBusinessOrchestrator_handlePaymentVerified(t *testing.T) {
 			settleError:    nil,
 			wantErr:        false,
 			wantState:      x402state.PaymentFailed,
-			wantErrorCode:  x402.ErrorCodeSettlementFailed,
+			wantErrorCode:  x402.ErrorCodeBusinessExecutionFailed,
 			businessCalled: true,
 			settleCalled:   false,
 		},
@@ -929,15 +1125,13 @@ func TestBusinessOrchestrator_handlePaymentVerified(t *testing.T) {
 				},
 			}
 
-			mockExtensionChecker := newMockExtensionCheckerWithX402()
-
-			orchestrator := NewBusinessOrchestratorWithDeps(
+			orchestrator := newTestOrchestrator(
+				t,
 				mockMerchant,
 				mockService,
 				[]types.NetworkConfig{
 					{NetworkName: "eip155:84532", PayToAddress: "0x123"},
 				},
-				mockExtensionChecker,
 			)
 
 			task := &a2a.Task{
@@ -1034,15 +1228,14 @@ func TestBusinessOrchestrator_Execute_InitialRequest(t *testing.T) {
 		},
 	}
 	mockQueue := &mockEventQueue{}
-	mockExtensionChecker := newMockExtensionCheckerWithX402()
 
-	orchestrator := NewBusinessOrchestratorWithDeps(
+	orchestrator := newTestOrchestrator(
+		t,
 		mockMerchant,
 		mockService,
 		[]types.NetworkConfig{
 			{NetworkName: "eip155:84532", PayToAddress: "0x123"},
 		},
-		mockExtensionChecker,
 	)
 
 	// Create initial request without payment state
@@ -1053,7 +1246,7 @@ func TestBusinessOrchestrator_Execute_InitialRequest(t *testing.T) {
 		ContextID: "context-456",
 	}
 
-	err := orchestrator.Execute(ctx, requestContext, mockQueue)
+	err := orchestrator.executeInPlace(ctx, requestContext, mockQueue)
 	if err != nil {
 		t.Fatalf("Execute() error = %v", err)
 	}
@@ -1118,11 +1311,11 @@ func TestBusinessOrchestrator_Execute_FreeRequest(t *testing.T) {
 		},
 	}
 	mockQueue := &mockEventQueue{}
-	orchestrator := NewBusinessOrchestratorWithDeps(
+	orchestrator := newTestOrchestrator(
+		t,
 		&MockResourceServer{},
 		mockService,
 		[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
-		newMockExtensionCheckerWithX402(),
 	)
 
 	message := a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "free request"})
@@ -1132,7 +1325,7 @@ func TestBusinessOrchestrator_Execute_FreeRequest(t *testing.T) {
 		ContextID: "context-free",
 	}
 
-	if err := orchestrator.Execute(ctx, requestContext, mockQueue); err != nil {
+	if err := orchestrator.executeInPlace(ctx, requestContext, mockQueue); err != nil {
 		t.Fatalf("Execute() error = %v", err)
 	}
 	if requestContext.StoredTask.Status.State != a2a.TaskStateCompleted {
@@ -1157,3 +1350,1019 @@ func TestBusinessOrchestrator_Execute_FreeRequest(t *testing.T) {
 		t.Errorf("expected artifact event with assigned ID, got %#v", artifactEvent)
 	}
 }
+
+func TestBusinessOrchestrator_SettlementPolicy(t *testing.T) {
+	tests := []struct {
+		name          string
+		policy        SettlementPolicy
+		businessError error
+		wantCalls     []string
+		wantState     a2a.TaskState
+		wantReceipt   bool
+	}{
+		{
+			name:      "execute then settle",
+			policy:    ExecuteThenSettle,
+			wantCalls: []string{"event:Payment verified — executing service", "execute", "settle"},
+			wantState: a2a.TaskStateCompleted,
+		},
+		{
+			name:      "settle then execute",
+			policy:    SettleThenExecute,
+			wantCalls: []string{"settle", "event:Payment settled", "execute"},
+			wantState: a2a.TaskStateCompleted,
+		},
+		{
+			name:          "execute then settle skips settlement on failure",
+			policy:        ExecuteThenSettle,
+			businessError: errors.New("out of stock"),
+			wantCalls:     []string{"event:Payment verified — executing service", "execute"},
+			wantState:     a2a.TaskStateFailed,
+		},
+		{
+			name:          "settle then execute keeps receipt on failure",
+			policy:        SettleThenExecute,
+			businessError: errors.New("out of stock"),
+			wantCalls:     []string{"settle", "event:Payment settled", "execute"},
+			wantState:     a2a.TaskStateFailed,
+			wantReceipt:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			orchestrator := newTestOrchestrator(
+				t,
+				&MockResourceServer{
+					SettlePaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.SettleResponse, error) {
+						calls = append(calls, "settle")
+						return &x402core.SettleResponse{Success: true, Network: x402.NetworkBaseSepolia, Transaction: "0xtx"}, nil
+					},
+				},
+				&mockBusinessService{executeFunc: func(ctx context.Context, request business.Request) (*business.Result, error) {
+					calls = append(calls, "execute")
+					if tt.businessError != nil {
+						return nil, tt.businessError
+					}
+					return &business.Result{Message: "result"}, nil
+				}},
+				[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+				WithSettlementPolicy(tt.policy),
+			)
+
+			requirements := x402types.PaymentRequirements{Scheme: "exact", Network: x402.NetworkBaseSepolia, PayTo: "0x123", Asset: "0x456"}
+			task := &a2a.Task{
+				ID:        "task-policy",
+				ContextID: "context-policy",
+				Status:    a2a.TaskStatus{State: a2a.TaskStateWorking, Message: a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{Text: ""})},
+			}
+			x402state.SetPaymentStatus(task.Status.Message, x402state.PaymentVerified)
+			x402state.SetPaymentPayload(task.Status.Message, &x402types.PaymentPayload{X402Version: x402.X402Version, Accepted: requirements})
+			x402state.SetPaymentRequirements(task.Status.Message, &x402types.PaymentRequired{
+				X402Version: x402.X402Version,
+				Accepts:     []x402types.PaymentRequirements{requirements},
+			})
+			x402state.SetOriginalPrompt(task.Status.Message, "test prompt")
+			requestContext := &a2asrv.RequestContext{
+				Message:    a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "continue"}),
+				StoredTask: task,
+				TaskID:     task.ID,
+				ContextID:  task.ContextID,
+			}
+			queue := &recordingEventQueue{calls: &calls}
+
+			if err := orchestrator.executeInPlace(context.Background(), requestContext, queue); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if !slices.Equal(calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", calls, tt.wantCalls)
+			}
+			if task.Status.State != tt.wantState {
+				t.Errorf("task state = %v, want %v", task.Status.State, tt.wantState)
+			}
+			if tt.wantReceipt {
+				receipts, err := x402state.ExtractPaymentReceipts(task)
+				if err != nil || len(receipts) != 1 || !receipts[0].Success || receipts[0].Transaction != "0xtx" {
+					t.Errorf("receipts = %#v, error = %v; want the successful settlement", receipts, err)
+				}
+			}
+		})
+	}
+}
+
+// recordingEventQueue records working status updates alongside other calls so
+// tests can assert on their relative order.
+type recordingEventQueue struct {
+	mockEventQueue
+	calls *[]string
+}
+
+func (q *recordingEventQueue) Write(ctx context.Context, event a2a.Event) error {
+	if update, ok := event.(*a2a.TaskStatusUpdateEvent); ok && update.Status.State == a2a.TaskStateWorking && update.Status.Message != nil {
+		if text := x402state.ExtractMessageText(update.Status.Message); text != "" {
+			*q.calls = append(*q.calls, "event:"+text)
+		}
+	}
+	return q.mockEventQueue.Write(ctx, event)
+}
+
+func TestBusinessOrchestrator_Execute_DuplicateSubmissionIsIdempotent(t *testing.T) {
+	verifyCalls, settleCalls := 0, 0
+	orchestrator := newTestOrchestrator(
+		t,
+		&MockResourceServer{
+			VerifyPaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.VerifyResponse, error) {
+				verifyCalls++
+				return &x402core.VerifyResponse{IsValid: true, Payer: "0x789"}, nil
+			},
+			SettlePaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.SettleResponse, error) {
+				settleCalls++
+				return &x402core.SettleResponse{Success: true, Network: x402.NetworkBaseSepolia, Transaction: "0xtx"}, nil
+			},
+		},
+		&mockBusinessService{},
+		[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+	)
+
+	task := quoteRequest(t, orchestrator, "task-duplicate", a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "buy"}))
+
+	submission := paymentSubmission(t, task)
+	different := paymentSubmission(t, task, func(payload *x402types.PaymentPayload) {
+		payload.Payload["signature"] = "0xdifferent"
+	})
+	submit := func(submission *a2a.Message) (*mockEventQueue, error) {
+		queue := &mockEventQueue{}
+		return queue, submitPayment(orchestrator, task, submission, queue)
+	}
+
+	if _, err := submit(submission); err != nil {
+		t.Fatalf("first submission error = %v", err)
+	}
+	if task.Status.State != a2a.TaskStateCompleted {
+		t.Fatalf("task state = %v, want completed", task.Status.State)
+	}
+
+	queue, err := submit(submission)
+	if err != nil {
+		t.Fatalf("duplicate submission error = %v", err)
+	}
+	if verifyCalls != 1 || settleCalls != 1 {
+		t.Errorf("verify calls = %d, settle calls = %d; want exactly one each", verifyCalls, settleCalls)
+	}
+	if len(queue.events) != 1 {
+		t.Fatalf("duplicate submission events = %d, want 1", len(queue.events))
+	}
+	event, ok := queue.events[0].(*a2a.TaskStatusUpdateEvent)
+	if !ok || !event.Final || event.Status.State != a2a.TaskStateCompleted {
+		t.Fatalf("duplicate submission event = %#v, want final completed status", queue.events[0])
+	}
+	if _, ok := event.Status.Message.Metadata[x402.MetadataKeyReceipts]; !ok {
+		t.Error("replayed status should carry the recorded receipts")
+	}
+
+	if _, err := submit(different); !errors.Is(err, a2a.ErrInvalidParams) {
+		t.Errorf("different payload error = %v, want %v", err, a2a.ErrInvalidParams)
+	}
+	if verifyCalls != 1 || settleCalls != 1 {
+		t.Errorf("different payload must not be verified or settled: verify = %d, settle = %d", verifyCalls, settleCalls)
+	}
+}
+
+func TestBusinessOrchestrator_Execute_PassesRequestContextToService(t *testing.T) {
+	var paidRequest business.Request
+	orchestrator := newTestOrchestrator(
+		t,
+		&MockResourceServer{
+			VerifyPaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.VerifyResponse, error) {
+				return &x402core.VerifyResponse{IsValid: true, Payer: "0xpayer"}, nil
+			},
+		},
+		&mockBusinessService{executeFunc: func(ctx context.Context, request business.Request) (*business.Result, error) {
+			if !request.PaymentVerified {
+				return nil, business.NewPaymentRequiredError("pay first", business.ServiceRequirements{
+					Price:    "1.00",
+					Resource: "/render",
+					Scheme:   "exact",
+				})
+			}
+			paidRequest = request
+			return &business.Result{Message: "rendered"}, nil
+		}},
+		[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+	)
+
+	initial := a2a.NewMessage(a2a.MessageRoleUser,
+		a2a.TextPart{Text: "render this"},
+		a2a.DataPart{Data: map[string]any{"width": float64(640)}},
+	)
+	task := quoteRequest(t, orchestrator, "task-context", initial)
+	payQuotedTask(t, orchestrator, task)
+
+	if paidRequest.TaskID != task.ID || paidRequest.ContextID != task.ContextID {
+		t.Errorf("request ids = %s/%s, want %s/%s", paidRequest.TaskID, paidRequest.ContextID, task.ID, task.ContextID)
+	}
+	if paidRequest.Payer != "0xpayer" {
+		t.Errorf("payer = %q, want 0xpayer", paidRequest.Payer)
+	}
+	if paidRequest.Requirements == nil || paidRequest.Requirements.Network != x402.NetworkBaseSepolia {
+		t.Errorf("requirements = %#v, want the matched Base Sepolia option", paidRequest.Requirements)
+	}
+	var width any
+	if paidRequest.Message != nil {
+		for _, part := range paidRequest.Message.Parts {
+			if data, ok := part.(a2a.DataPart); ok {
+				width = data.Data["width"]
+			}
+		}
+	}
+	if width != float64(640) {
+		t.Errorf("DataPart width = %v, want 640 from the original message", width)
+	}
+}
+
+func TestBusinessOrchestrator_Execute_FilePartResultStreamsAsArtifact(t *testing.T) {
+	filePart := a2a.FilePart{File: a2a.FileBytes{
+		FileMeta: a2a.FileMeta{Name: "image.png", MimeType: "image/png"},
+		Bytes:    "iVBORw0KGgo=",
+	}}
+	orchestrator := newTestOrchestrator(
+		t,
+		&MockResourceServer{},
+		&mockBusinessService{executeFunc: func(ctx context.Context, request business.Request) (*business.Result, error) {
+			return &business.Result{Message: "Image generated", Parts: []a2a.Part{filePart}}, nil
+		}},
+		[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+	)
+
+	requirements := x402types.PaymentRequirements{Scheme: "exact", Network: x402.NetworkBaseSepolia, PayTo: "0x123", Asset: "0x456"}
+	task := &a2a.Task{
+		ID:        "task-file",
+		ContextID: "context-file",
+		Status:    a2a.TaskStatus{State: a2a.TaskStateWorking, Message: a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{Text: ""})},
+	}
+	x402state.SetPaymentStatus(task.Status.Message, x402state.PaymentVerified)
+	x402state.SetPaymentPayload(task.Status.Message, &x402types.PaymentPayload{X402Version: x402.X402Version, Accepted: requirements})
+	x402state.SetPaymentRequirements(task.Status.Message, &x402types.PaymentRequired{
+		X402Version: x402.X402Version,
+		Accepts:     []x402types.PaymentRequirements{requirements},
+	})
+	x402state.SetOriginalPrompt(task.Status.Message, "draw a cat")
+	queue := &mockEventQueue{}
+
+	err := orchestrator.executeInPlace(context.Background(), &a2asrv.RequestContext{
+		Message:    a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "continue"}),
+		StoredTask: task,
+		TaskID:     task.ID,
+		ContextID:  task.ContextID,
+	}, queue)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	final, ok := queue.events[len(queue.events)-1].(*a2a.TaskStatusUpdateEvent)
+	if !ok || !final.Final || final.Status.State != a2a.TaskStateCompleted {
+		t.Fatalf("last event = %#v, want final completion", queue.events[len(queue.events)-1])
+	}
+	var gotFile *a2a.FilePart
+	for _, event := range queue.events[:len(queue.events)-1] {
+		artifactEvent, ok := event.(*a2a.TaskArtifactUpdateEvent)
+		if !ok || artifactEvent.Artifact.Name != resultArtifactName {
+			continue
+		}
+		if !artifactEvent.LastChunk {
+			t.Error("result artifact is not marked as the last chunk")
+		}
+		for _, part := range artifactEvent.Artifact.Parts {
+			if candidate, ok := part.(a2a.FilePart); ok {
+				gotFile = &candidate
+			}
+		}
+	}
+	if gotFile == nil || gotFile.File != filePart.File {
+		t.Error("no result artifact carrying the FilePart preceded the completion event")
+	}
+
+	message := final.Status.Message
+	if x402state.ExtractMessageText(message) != "Image generated" {
+		t.Errorf("completion text = %q", x402state.ExtractMessageText(message))
+	}
+	encoded, err := json.Marshal(message)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if len(message.Parts) != 1 || strings.Contains(string(encoded), "iVBORw0KGgo=") {
+		t.Errorf("completion message carries the output: %s", encoded)
+	}
+	if _, ok := message.Metadata[x402.MetadataKeyReceipts]; !ok {
+		t.Error("completion message is missing receipts metadata")
+	}
+}
+
+func TestBusinessOrchestrator_Cancel(t *testing.T) {
+	receipt := &x402core.SettleResponse{Success: true, Network: x402.NetworkBaseSepolia, Transaction: "0xtx"}
+	tests := []struct {
+		name        string
+		state       a2a.TaskState
+		status      x402state.PaymentStatus
+		receipts    []*x402core.SettleResponse
+		wantState   a2a.TaskState
+		wantStatus  x402state.PaymentStatus
+		wantVoided  bool
+		wantReceipt bool
+	}{
+		{
+			name:       "before payment",
+			state:      a2a.TaskStateInputRequired,
+			status:     x402state.PaymentRequired,
+			wantState:  a2a.TaskStateCanceled,
+			wantStatus: x402state.PaymentRejected,
+		},
+		{
+			name:       "after verification",
+			state:      a2a.TaskStateWorking,
+			status:     x402state.PaymentVerified,
+			wantState:  a2a.TaskStateCanceled,
+			wantStatus: x402state.PaymentRejected,
+			wantVoided: true,
+		},
+		{
+			name:        "after settlement",
+			state:       a2a.TaskStateWorking,
+			status:      x402state.PaymentVerified,
+			receipts:    []*x402core.SettleResponse{receipt},
+			wantState:   a2a.TaskStateCanceled,
+			wantStatus:  x402state.PaymentCompleted,
+			wantReceipt: true,
+		},
+		{
+			name:        "already completed",
+			state:       a2a.TaskStateCompleted,
+			status:      x402state.PaymentCompleted,
+			receipts:    []*x402core.SettleResponse{receipt},
+			wantState:   a2a.TaskStateCompleted,
+			wantStatus:  x402state.PaymentCompleted,
+			wantReceipt: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{Text: "in progress"})
+			x402state.SetPaymentStatus(message, tt.status)
+			if len(tt.receipts) > 0 {
+				if err := x402state.SetPaymentReceipts(message, tt.receipts); err != nil {
+					t.Fatal(err)
+				}
+			}
+			task := &a2a.Task{
+				ID:        "task-cancel",
+				ContextID: "context-cancel",
+				Status:    a2a.TaskStatus{State: tt.state, Message: message},
+			}
+			orchestrator := newTestOrchestrator(
+				t,
+				&MockResourceServer{},
+				&mockBusinessService{},
+				[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+			)
+
+			queue := &mockEventQueue{}
+			err := orchestrator.Cancel(context.Background(), &a2asrv.RequestContext{
+				StoredTask: task,
+				TaskID:     task.ID,
+				ContextID:  task.ContextID,
+			}, queue)
+			if err != nil {
+				t.Fatalf("Cancel() error = %v", err)
+			}
+			if len(queue.events) != 1 {
+				t.Fatalf("events = %d, want 1", len(queue.events))
+			}
+			event, ok := queue.events[0].(*a2a.TaskStatusUpdateEvent)
+			if !ok || !event.Final || event.Status.State != tt.wantState {
+				t.Fatalf("event = %#v, want final %v", queue.events[0], tt.wantState)
+			}
+
+			if task.Status.State != tt.wantState {
+				t.Errorf("task state = %v, want %v", task.Status.State, tt.wantState)
+			}
+			status, _ := x402state.ExtractPaymentStatusFromMessage(event.Status.Message)
+			if status != tt.wantStatus {
+				t.Errorf("payment status = %v, want %v", status, tt.wantStatus)
+			}
+			if voided := event.Status.Message.Metadata[x402.MetadataKeyVoided] == true; voided != tt.wantVoided {
+				t.Errorf("voided = %v, want %v", voided, tt.wantVoided)
+			}
+			receipts, err := x402state.ExtractPaymentReceipts(task)
+			if err != nil {
+				t.Fatalf("ExtractPaymentReceipts() error = %v", err)
+			}
+			if hasReceipt := len(receipts) == 1 && receipts[0].Transaction == "0xtx"; hasReceipt != tt.wantReceipt {
+				t.Errorf("receipts = %#v, want receipt %v", receipts, tt.wantReceipt)
+			}
+		})
+	}
+}
+
+func TestBusinessOrchestrator_CancelDuringExecutionVoidsAuthorization(t *testing.T) {
+	tests := []struct {
+		name   string
+		cancel func(orchestrator *BusinessOrchestrator, task *a2a.Task, cancelCtx context.CancelFunc) <-chan error
+	}{
+		{
+			name: "Cancel",
+			cancel: func(orchestrator *BusinessOrchestrator, task *a2a.Task, _ context.CancelFunc) <-chan error {
+				done := make(chan error, 1)
+				go func() {
+					done <- orchestrator.Cancel(context.Background(), &a2asrv.RequestContext{
+						StoredTask: task,
+						TaskID:     task.ID,
+						ContextID:  task.ContextID,
+					}, &mockEventQueue{})
+				}()
+				for !orchestrator.taskLocks.cancelRequested(task.ID) {
+					time.Sleep(time.Millisecond)
+				}
+				return done
+			},
+		},
+		{
+			name: "request context",
+			cancel: func(_ *BusinessOrchestrator, _ *a2a.Task, cancelCtx context.CancelFunc) <-chan error {
+				cancelCtx()
+				done := make(chan error, 1)
+				done <- nil
+				return done
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settleCalls := 0
+			var voided *x402types.PaymentPayload
+			started, release := make(chan struct{}), make(chan struct{})
+			orchestrator := newTestOrchestrator(
+				t,
+				&MockResourceServer{
+					SettlePaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.SettleResponse, error) {
+						settleCalls++
+						return &x402core.SettleResponse{Success: true}, nil
+					},
+				},
+				&mockBusinessService{executeFunc: func(ctx context.Context, request business.Request) (*business.Result, error) {
+					if !request.PaymentVerified {
+						return nil, business.NewPaymentRequiredError("pay", business.ServiceRequirements{
+							Price:             "1.00",
+							Resource:          "/slow",
+							Scheme:            "exact",
+							MaxTimeoutSeconds: 60,
+						})
+					}
+					close(started)
+					select {
+					case <-release:
+					case <-ctx.Done():
+						return nil, ctx.Err()
+					}
+					return &business.Result{Message: "done"}, nil
+				}},
+				[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+				WithHooks(Hooks{
+					OnAuthorizationVoided: func(ctx context.Context, task *a2a.Task, payload *x402types.PaymentPayload) {
+						voided = payload
+					},
+				}),
+			)
+
+			task := quoteRequest(t, orchestrator, "task-void", a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "slow"}))
+			submission := paymentSubmission(t, task)
+
+			ctx, cancelCtx := context.WithCancel(context.Background())
+			defer cancelCtx()
+			queue := &mockEventQueue{}
+			executed := make(chan error, 1)
+			go func() {
+				executed <- orchestrator.executeInPlace(ctx, &a2asrv.RequestContext{
+					Message:    submission,
+					StoredTask: task,
+					TaskID:     task.ID,
+					ContextID:  task.ContextID,
+				}, queue)
+			}()
+			<-started
+			canceled := tt.cancel(orchestrator, task, cancelCtx)
+			close(release)
+
+			if err := <-executed; err != nil {
+				t.Fatalf("paid Execute() error = %v", err)
+			}
+			if err := <-canceled; err != nil {
+				t.Fatalf("Cancel() error = %v", err)
+			}
+
+			if settleCalls != 0 {
+				t.Errorf("SettlePayment calls = %d, want 0", settleCalls)
+			}
+			if task.Status.State != a2a.TaskStateCanceled {
+				t.Errorf("task state = %v, want %v", task.Status.State, a2a.TaskStateCanceled)
+			}
+			status, _ := x402state.ExtractPaymentStatus(task)
+			if status != x402state.PaymentRejected {
+				t.Errorf("payment status = %v, want %v", status, x402state.PaymentRejected)
+			}
+			if code := task.Status.Message.Metadata[x402.MetadataKeyError]; code != x402.ErrorCodeAuthorizationVoided {
+				t.Errorf("error code = %v, want %v", code, x402.ErrorCodeAuthorizationVoided)
+			}
+			if voided == nil || voided.Payload["signature"] != "0xtask-void" {
+				t.Errorf("OnAuthorizationVoided payload = %#v, want the submitted payload", voided)
+			}
+			final, ok := queue.events[len(queue.events)-1].(*a2a.TaskStatusUpdateEvent)
+			if !ok || !final.Final || final.Status.State != a2a.TaskStateCanceled {
+				t.Errorf("last event = %#v, want final canceled status", queue.events[len(queue.events)-1])
+			}
+		})
+	}
+}
+
+func TestBusinessOrchestrator_Execute_FreeRequests(t *testing.T) {
+	tests := []struct {
+		name         string
+		requirement  business.ServiceRequirements
+		wantState    a2a.TaskState
+		wantStatus   x402state.PaymentStatus
+		wantRSCalls  int
+		wantFreeCall bool
+	}{
+		{
+			name:         "zero price",
+			requirement:  business.ServiceRequirements{Price: "0", Resource: "/help", Scheme: "exact"},
+			wantState:    a2a.TaskStateCompleted,
+			wantStatus:   x402state.PaymentNotRequired,
+			wantFreeCall: true,
+		},
+		{
+			name:         "free flag",
+			requirement:  business.ServiceRequirements{Price: "$1.00", Resource: "/help", Scheme: "exact", Free: true},
+			wantState:    a2a.TaskStateCompleted,
+			wantStatus:   x402state.PaymentNotRequired,
+			wantFreeCall: true,
+		},
+		{
+			name:        "paid",
+			requirement: business.ServiceRequirements{Price: "$0.01", Resource: "/paid", Scheme: "exact"},
+			wantState:   a2a.TaskStateInputRequired,
+			wantStatus:  x402state.PaymentRequired,
+			wantRSCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rsCalls := 0
+			resourceServer := &MockResourceServer{
+				BuildPaymentRequirementsFromConfigFunc: func(ctx context.Context, config x402pkg.ResourceConfig) ([]x402types.PaymentRequirements, error) {
+					rsCalls++
+					return []x402types.PaymentRequirements{{Scheme: "exact", Network: string(config.Network), PayTo: config.PayTo, Amount: "10000"}}, nil
+				},
+			}
+			var freeCall bool
+			service := &mockBusinessService{executeFunc: func(ctx context.Context, request business.Request) (*business.Result, error) {
+				if request.PaymentVerified {
+					freeCall = request.Free
+					return &business.Result{Message: "usage: ask me anything"}, nil
+				}
+				return nil, business.NewPaymentRequiredError("pay", tt.requirement)
+			}}
+			orchestrator := newTestOrchestrator(
+				t,
+				resourceServer,
+				service,
+				[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+			)
+
+			requestContext := &a2asrv.RequestContext{
+				Message:   a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "help"}),
+				TaskID:    "task-free",
+				ContextID: "context-free",
+			}
+			if err := orchestrator.executeInPlace(context.Background(), requestContext, &mockEventQueue{}); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			task := requestContext.StoredTask
+
+			if task.Status.State != tt.wantState {
+				t.Errorf("task state = %v, want %v", task.Status.State, tt.wantState)
+			}
+			if status, _ := x402state.ExtractPaymentStatus(task); status != tt.wantStatus {
+				t.Errorf("payment status = %v, want %v", status, tt.wantStatus)
+			}
+			if rsCalls != tt.wantRSCalls {
+				t.Errorf("resource server calls = %d, want %d", rsCalls, tt.wantRSCalls)
+			}
+			if freeCall != tt.wantFreeCall {
+				t.Errorf("free execution = %v, want %v", freeCall, tt.wantFreeCall)
+			}
+			if tt.wantFreeCall {
+				if _, ok := task.Status.Message.Metadata[x402.MetadataKeyRequired]; ok {
+					t.Error("free task carries payment requirements")
+				}
+			}
+		})
+	}
+}
+
+func TestBusinessOrchestrator_Execute_PayerPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     PayerPolicy
+		wantState  a2a.TaskState
+		wantSettle int
+	}{
+		{
+			name:       "allowed payer",
+			policy:     StaticPayerPolicy{Allowed: []string{"0xABC"}},
+			wantState:  a2a.TaskStateCompleted,
+			wantSettle: 1,
+		},
+		{
+			name:      "denied payer",
+			policy:    StaticPayerPolicy{Denied: []string{"0xabc"}},
+			wantState: a2a.TaskStateRejected,
+		},
+		{
+			name:      "payer outside allowlist",
+			policy:    StaticPayerPolicy{Allowed: []string{"0xdef"}},
+			wantState: a2a.TaskStateRejected,
+		},
+		{
+			name: "callback",
+			policy: PayerPolicyFunc(func(ctx context.Context, payer string, network string) (bool, string) {
+				return network != x402.NetworkBaseSepolia, "testnet payments are closed"
+			}),
+			wantState: a2a.TaskStateRejected,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settleCalls := 0
+			businessCalls := 0
+			orchestrator := newTestOrchestrator(
+				t,
+				&MockResourceServer{
+					VerifyPaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.VerifyResponse, error) {
+						return &x402core.VerifyResponse{IsValid: true, Payer: "0xabc"}, nil
+					},
+					SettlePaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.SettleResponse, error) {
+						settleCalls++
+						return &x402core.SettleResponse{Success: true, Network: x402.NetworkBaseSepolia, Transaction: "0xtx"}, nil
+					},
+				},
+				&mockBusinessService{executeFunc: func(ctx context.Context, request business.Request) (*business.Result, error) {
+					if request.PaymentVerified {
+						businessCalls++
+						return &business.Result{Message: "done"}, nil
+					}
+					return (&mockBusinessService{}).Execute(ctx, request)
+				}},
+				[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+				WithPayerPolicy(tt.policy),
+			)
+
+			task := quoteRequest(t, orchestrator, "task-payer", a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "buy"}))
+			payQuotedTask(t, orchestrator, task)
+
+			if task.Status.State != tt.wantState {
+				t.Errorf("task state = %v, want %v", task.Status.State, tt.wantState)
+			}
+			if settleCalls != tt.wantSettle || businessCalls != tt.wantSettle {
+				t.Errorf("settle calls = %d, business calls = %d, want %d", settleCalls, businessCalls, tt.wantSettle)
+			}
+			if tt.wantState == a2a.TaskStateRejected {
+				if status, _ := x402state.ExtractPaymentStatus(task); status != x402state.PaymentRejected {
+					t.Errorf("payment status = %v, want %v", status, x402state.PaymentRejected)
+				}
+				if code := task.Status.Message.Metadata[x402.MetadataKeyError]; code != x402.ErrorCodePayerNotAllowed {
+					t.Errorf("error code = %v, want %s", code, x402.ErrorCodePayerNotAllowed)
+				}
+			}
+		})
+	}
+}
+
+func TestBusinessOrchestrator_Execute_LeavesStoredTaskUnchanged(t *testing.T) {
+	orchestrator, err := NewBusinessOrchestrator(context.Background(), &mockBusinessService{},
+		[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+		WithPaymentServer(&MockResourceServer{}),
+		WithExtensionChecker(newMockExtensionCheckerWithX402()),
+	)
+	if err != nil {
+		t.Fatalf("NewBusinessOrchestrator() error = %v", err)
+	}
+	task, submission := quotePayment(t, orchestrator, "task-stored")
+	quoted := task.Status
+
+	queue := &mockEventQueue{}
+	err = orchestrator.Execute(context.Background(), &a2asrv.RequestContext{
+		Message:    submission,
+		StoredTask: task,
+		TaskID:     task.ID,
+		ContextID:  task.ContextID,
+	}, queue)
+	if err != nil {
+		t.Fatalf("paid Execute() error = %v", err)
+	}
+
+	if task.Status != quoted {
+		t.Errorf("stored task status changed to %s, want it left to the task store", task.Status.State)
+	}
+	last, ok := queue.events[len(queue.events)-1].(*a2a.TaskStatusUpdateEvent)
+	if !ok || last.Status.State != a2a.TaskStateCompleted {
+		t.Errorf("last event = %#v, want the completed status", queue.events[len(queue.events)-1])
+	}
+}
+
+func TestBusinessOrchestrator_Execute_WorkingEventBeforeExecution(t *testing.T) {
+	var queue *mockEventQueue
+	var observed int
+	orchestrator := newTestOrchestrator(
+		t,
+		&MockResourceServer{},
+		&mockBusinessService{executeFunc: func(ctx context.Context, request business.Request) (*business.Result, error) {
+			if request.PaymentVerified {
+				observed = len(queue.events)
+				return &business.Result{Message: "done"}, nil
+			}
+			return (&mockBusinessService{}).Execute(ctx, request)
+		}},
+		[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+	)
+
+	task := quoteRequest(t, orchestrator, "task-working", a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "buy"}))
+	submission := paymentSubmission(t, task)
+	queue = &mockEventQueue{}
+	if err := submitPayment(orchestrator, task, submission, queue); err != nil {
+		t.Fatalf("paid Execute() error = %v", err)
+	}
+
+	var got []string
+	for _, event := range queue.events {
+		update, ok := event.(*a2a.TaskStatusUpdateEvent)
+		if !ok {
+			continue
+		}
+		status, _ := x402state.ExtractPaymentStatusFromMessage(update.Status.Message)
+		got = append(got, string(update.Status.State)+"/"+string(status))
+	}
+	want := []string{
+		"working/" + string(x402state.PaymentVerified),
+		"working/" + string(x402state.PaymentVerified),
+		"completed/" + string(x402state.PaymentCompleted),
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+	if observed != 2 {
+		t.Errorf("business service ran after %d events, want it to run after the working event", observed)
+	}
+}
+
+func TestBusinessOrchestrator_transitionToCompleted_Transactions(t *testing.T) {
+	receipts := []*x402core.SettleResponse{
+		{Success: true, Transaction: "0x1234567890abcdef1234567890abcdef", Network: x402.NetworkBaseSepolia},
+		{Success: true, Network: x402.NetworkSolanaDevnet},
+	}
+	tests := []struct {
+		name     string
+		opts     []Option
+		wantText string
+	}{
+		{
+			name:     "metadata only by default",
+			wantText: "done",
+		},
+		{
+			name:     "text line when enabled",
+			opts:     []Option{WithTransactionText()},
+			wantText: "done\n\nPaid — tx 0x12345678…abcdef on " + x402.NetworkBaseSepolia,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orchestrator := newTestOrchestrator(t, &MockResourceServer{}, &mockBusinessService{}, nil, tt.opts...)
+			task := &a2a.Task{ID: "task-tx", ContextID: "context-tx", Status: a2a.TaskStatus{State: a2a.TaskStateWorking}}
+			requestContext := &a2asrv.RequestContext{TaskID: task.ID, ContextID: task.ContextID}
+			result := &x402state.PaymentState{Status: x402state.PaymentCompleted, Message: "done", Receipts: receipts}
+
+			if err := orchestrator.transitionToCompleted(context.Background(), requestContext, task, &mockEventQueue{}, result); err != nil {
+				t.Fatalf("transitionToCompleted() error = %v", err)
+			}
+			if got := x402state.ExtractMessageText(task.Status.Message); got != tt.wantText {
+				t.Errorf("text = %q, want %q", got, tt.wantText)
+			}
+			transactions, _ := task.Status.Message.Metadata[x402.MetadataKeyTransactions].([]interface{})
+			if len(transactions) != 1 {
+				t.Fatalf("transactions = %#v, want the one receipt with a transaction", transactions)
+			}
+			if got := transactions[0].(map[string]interface{})["transaction"]; got != receipts[0].Transaction {
+				t.Errorf("transaction = %v, want %s", got, receipts[0].Transaction)
+			}
+		})
+	}
+}
+
+func TestBusinessOrchestrator_FailuresUseRegisteredErrorCodes(t *testing.T) {
+	quote := func(ctx context.Context, request business.Request) (*business.Result, error) {
+		if request.PaymentVerified {
+			return &business.Result{Message: "done"}, nil
+		}
+		return nil, business.NewPaymentRequiredError("pay", business.ServiceRequirements{Price: "1.00", Resource: "/test", Scheme: "exact"})
+	}
+	noExtension := &MockExtensionChecker{ExtensionsFromFunc: func(ctx context.Context) (*a2asrv.Extensions, bool) { return nil, false }}
+
+	tests := []struct {
+		name     string
+		server   *MockResourceServer
+		execute  func(ctx context.Context, request business.Request) (*business.Result, error)
+		checker  ExtensionChecker
+		options  []Option
+		payload  map[string]interface{}
+		malform  bool
+		wantCode string
+	}{
+		{
+			name:     "extension missing",
+			execute:  quote,
+			checker:  noExtension,
+			wantCode: x402.ErrorCodeExtensionRequired,
+		},
+		{
+			name: "business fails before quoting",
+			execute: func(ctx context.Context, request business.Request) (*business.Result, error) {
+				return nil, errors.New("out of stock")
+			},
+			wantCode: x402.ErrorCodeBusinessExecutionFailed,
+		},
+		{
+			name:    "skill cannot be resolved",
+			execute: quote,
+			options: []Option{WithSkillRouter(SkillRouterFunc(func(ctx context.Context, message *a2a.Message) (string, error) {
+				return "", errors.New("unknown skill")
+			}))},
+			wantCode: x402.ErrorCodeInvalidRequest,
+		},
+		{
+			name: "requirements cannot be built",
+			server: &MockResourceServer{BuildPaymentRequirementsFromConfigFunc: func(ctx context.Context, config x402pkg.ResourceConfig) ([]x402types.PaymentRequirements, error) {
+				return nil, errors.New("no asset")
+			}},
+			execute:  quote,
+			wantCode: x402.ErrorCodeInternal,
+		},
+		{
+			name:     "malformed submission without retries",
+			execute:  quote,
+			options:  []Option{WithMaxPayloadRetries(0)},
+			malform:  true,
+			wantCode: x402.ErrorCodeInvalidPayload,
+		},
+		{
+			name: "verification rejected",
+			server: &MockResourceServer{VerifyPaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.VerifyResponse, error) {
+				return &x402core.VerifyResponse{IsValid: false, InvalidReason: "invalid_exact_evm_payload_signature"}, nil
+			}},
+			execute:  quote,
+			wantCode: x402.ErrorCodeInvalidSignature,
+		},
+		{
+			name: "business fails after payment",
+			execute: func(ctx context.Context, request business.Request) (*business.Result, error) {
+				if request.PaymentVerified {
+					return nil, errors.New("out of stock")
+				}
+				return quote(ctx, request)
+			},
+			wantCode: x402.ErrorCodeBusinessExecutionFailed,
+		},
+		{
+			name: "settlement refused",
+			server: &MockResourceServer{SettlePaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.SettleResponse, error) {
+				return &x402core.SettleResponse{Success: false, ErrorReason: "insufficient funds"}, nil
+			}},
+			execute:  quote,
+			wantCode: x402.ErrorCodeInsufficientFunds,
+		},
+		{
+			name:     "payer rejected by policy",
+			execute:  quote,
+			options:  []Option{WithPayerPolicy(StaticPayerPolicy{Denied: []string{"0x789"}})},
+			wantCode: x402.ErrorCodePayerNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := tt.server
+			if server == nil {
+				server = &MockResourceServer{}
+			}
+			opts := tt.options
+			if tt.checker != nil {
+				opts = append([]Option{WithExtensionChecker(tt.checker)}, opts...)
+			}
+			orchestrator := newTestOrchestrator(
+				t,
+				server,
+				&mockBusinessService{executeFunc: tt.execute},
+				[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+				opts...,
+			)
+
+			queue := &mockEventQueue{}
+			requestContext := &a2asrv.RequestContext{
+				Message:   a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "buy"}),
+				TaskID:    "task-codes",
+				ContextID: "context-codes",
+			}
+			_ = orchestrator.executeInPlace(context.Background(), requestContext, queue)
+			task := requestContext.StoredTask
+			if task.Status.State == a2a.TaskStateInputRequired {
+				submission := paymentSubmission(t, task)
+				if tt.malform {
+					submission.Metadata[x402.MetadataKeyPayload] = "malformed"
+				}
+				_ = submitPayment(orchestrator, task, submission, queue)
+			}
+
+			terminal := 0
+			for _, event := range queue.events {
+				statusEvent, ok := event.(*a2a.TaskStatusUpdateEvent)
+				if !ok || (statusEvent.Status.State != a2a.TaskStateFailed && statusEvent.Status.State != a2a.TaskStateRejected) {
+					continue
+				}
+				terminal++
+				code, _ := statusEvent.Status.Message.Metadata[x402.MetadataKeyError].(string)
+				if !x402.IsErrorCode(code) {
+					t.Errorf("failure event carries unregistered error code %q", code)
+				}
+				if code != tt.wantCode {
+					t.Errorf("error code = %q, want %q", code, tt.wantCode)
+				}
+			}
+			if terminal != 1 {
+				t.Errorf("failure events = %d, want 1", terminal)
+			}
+		})
+	}
+}
+
+func TestBusinessOrchestrator_Execute_RequestMetadata(t *testing.T) {
+	var quoted, paid map[string]interface{}
+	orchestrator := newTestOrchestrator(
+		t,
+		&MockResourceServer{},
+		&mockBusinessService{executeFunc: func(ctx context.Context, request business.Request) (*business.Result, error) {
+			if request.PaymentVerified {
+				paid = request.Metadata
+				return &business.Result{Message: "done"}, nil
+			}
+			quoted = request.Metadata
+			return nil, business.NewPaymentRequiredError("pay", business.ServiceRequirements{Price: "1.00", Resource: "/test", Scheme: "exact"})
+		}},
+		[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+	)
+
+	message := a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "draw a cat"})
+	message.Metadata = map[string]interface{}{
+		"locale":                 "fr",
+		"tenant":                 map[string]interface{}{"id": "acme"},
+		x402.MetadataKeyStatus:   string(x402state.PaymentRequired),
+		x402.MetadataKeyReceipts: []interface{}{},
+	}
+	task := quoteRequest(t, orchestrator, "task-metadata", message)
+
+	// The paid execution must not depend on the history, which a task store
+	// may not keep.
+	task.History = nil
+	payQuotedTask(t, orchestrator, task)
+	if task.Status.State != a2a.TaskStateCompleted {
+		t.Fatalf("task state = %v, want completed", task.Status.State)
+	}
+
+	for name, metadata := range map[string]map[string]interface{}{"quote": quoted, "paid": paid} {
+		if metadata["locale"] != "fr" {
+			t.Errorf("%s execution locale = %v, want fr", name, metadata["locale"])
+		}
+		if tenant, _ := metadata["tenant"].(map[string]interface{}); tenant["id"] != "acme" {
+			t.Errorf("%s execution tenant = %v, want acme", name, metadata["tenant"])
+		}
+		for key := range metadata {
+			if strings.HasPrefix(key, x402.MetadataKeyPrefix) {
+				t.Errorf("%s execution metadata carries protocol key %s", name, key)
+			}
+		}
+	}
+}
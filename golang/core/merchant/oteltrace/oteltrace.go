@@ -0,0 +1,64 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package oteltrace adapts an OpenTelemetry tracer to merchant.Tracer, so the
+// merchant package itself does not depend on OpenTelemetry:
+//
+//	tracer := otel.Tracer("github.com/google-agentic-commerce/a2a-x402")
+//	m, err := merchant.NewMerchant(ctx, url, service, networks,
+//		merchant.WithTracer(oteltrace.New(tracer)))
+//
+// Spans join the trace already in the request context, so wrapping the HTTP
+// handler with OpenTelemetry instrumentation links each payment to the
+// request that carried it.
+package oteltrace
+
+import (
+	"context"
+
+	"github.com/google-agentic-commerce/a2a-x402/core/merchant"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// New returns a merchant.Tracer that starts its spans with tracer.
+func New(tracer trace.Tracer) merchant.Tracer {
+	return otelTracer{tracer: tracer}
+}
+
+type otelTracer struct {
+	tracer trace.Tracer
+}
+
+func (t otelTracer) Start(ctx context.Context, name string) (context.Context, merchant.Span) {
+	ctx, span := t.tracer.Start(ctx, name)
+	return ctx, otelSpan{span: span}
+}
+
+type otelSpan struct {
+	span trace.Span
+}
+
+func (s otelSpan) SetAttribute(key, value string) {
+	s.span.SetAttributes(attribute.String(key, value))
+}
+
+func (s otelSpan) End(err error) {
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
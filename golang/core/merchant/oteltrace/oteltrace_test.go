@@ -0,0 +1,160 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oteltrace_test
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/client"
+	"github.com/google-agentic-commerce/a2a-x402/core/merchant"
+	"github.com/google-agentic-commerce/a2a-x402/core/merchant/oteltrace"
+	"github.com/google-agentic-commerce/a2a-x402/core/testutil"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+const testPrivateKey = "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+
+func pay(t *testing.T, opts testutil.FakeMerchantOptions) (*a2a.Task, *tracetest.InMemoryExporter) {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+	opts.MerchantOptions = append(opts.MerchantOptions, merchant.WithTracer(oteltrace.New(provider.Tracer("test"))))
+
+	fake := testutil.NewFakeMerchant(t, opts)
+	c, err := client.NewClient(
+		fake.URL,
+		[]types.NetworkKeyPair{{NetworkName: x402.NetworkBaseSepolia, PrivateKey: testPrivateKey}},
+		client.WithPollInterval(10*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	task, err := c.WaitForCompletion(ctx, "hello")
+	if err != nil {
+		t.Fatalf("WaitForCompletion() error = %v", err)
+	}
+	return task, exporter
+}
+
+// spanTree renders each trace rooted at an execute span as
+// "root(child,child,...)", children in start order with repeats collapsed.
+func spanTree(spans tracetest.SpanStubs) []string {
+	children := make(map[string][]string)
+	for _, span := range spans {
+		parent := span.Parent.SpanID().String()
+		names := children[parent]
+		if len(names) == 0 || names[len(names)-1] != span.Name {
+			children[parent] = append(names, span.Name)
+		}
+	}
+	var trees []string
+	for _, span := range spans {
+		if span.Name == merchant.SpanExecute {
+			trees = append(trees, span.Name+"("+strings.Join(children[span.SpanContext.SpanID().String()], ",")+")")
+		}
+	}
+	return trees
+}
+
+func attributes(span tracetest.SpanStub) map[attribute.Key]string {
+	attrs := make(map[attribute.Key]string)
+	for _, kv := range span.Attributes {
+		attrs[kv.Key] = kv.Value.Emit()
+	}
+	return attrs
+}
+
+func TestTracer_PaidFlow(t *testing.T) {
+	task, exporter := pay(t, testutil.FakeMerchantOptions{})
+	if task.Status.State != a2a.TaskStateCompleted {
+		t.Fatalf("task state = %s, want completed", task.Status.State)
+	}
+
+	// Spans end before their parents, so the exporter holds children first;
+	// sort by start time to read the tree in order.
+	spans := exporter.GetSpans()
+	ordered := make(tracetest.SpanStubs, len(spans))
+	copy(ordered, spans)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].StartTime.Before(ordered[j].StartTime) })
+
+	want := []string{
+		"x402.execute(x402.event_write,x402.extension_check,x402.event_write,x402.business_execute,x402.quote,x402.event_write)",
+		"x402.execute(x402.extension_check,x402.verify,x402.event_write,x402.business_execute,x402.settle,x402.event_write)",
+	}
+	got := spanTree(ordered)
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("span tree =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	for _, span := range ordered {
+		attrs := attributes(span)
+		if span.Name == merchant.SpanExecute && attrs[merchant.AttributeTaskID] != string(task.ID) {
+			t.Errorf("execute span task ID = %q, want %q", attrs[merchant.AttributeTaskID], task.ID)
+		}
+		if span.Name == merchant.SpanVerify || span.Name == merchant.SpanSettle {
+			if attrs[merchant.AttributeNetwork] != x402.NetworkBaseSepolia || attrs[merchant.AttributeAmount] != "10000" {
+				t.Errorf("%s attributes = %v, want the network and amount paid", span.Name, attrs)
+			}
+		}
+		for key, value := range attrs {
+			if strings.Contains(strings.ToLower(string(key)), "signature") || strings.Contains(value, "0x") && len(value) > 66 {
+				t.Errorf("%s attribute %s = %q looks like a signature", span.Name, key, value)
+			}
+		}
+	}
+}
+
+func TestTracer_FailedVerification(t *testing.T) {
+	task, exporter := pay(t, testutil.FakeMerchantOptions{InvalidReason: "insufficient_funds"})
+	if task.Status.State != a2a.TaskStateFailed {
+		t.Fatalf("task state = %s, want failed", task.Status.State)
+	}
+
+	var verifyCode, executeCode string
+	for _, span := range exporter.GetSpans() {
+		attrs := attributes(span)
+		switch span.Name {
+		case merchant.SpanVerify:
+			verifyCode = attrs[merchant.AttributeErrorCode]
+			if span.Status.Code != codes.Error {
+				t.Errorf("verify span status = %v, want error", span.Status)
+			}
+		case merchant.SpanExecute:
+			if code := attrs[merchant.AttributeErrorCode]; code != "" {
+				executeCode = code
+			}
+		case merchant.SpanSettle:
+			t.Error("settle span recorded after failed verification")
+		}
+	}
+	if verifyCode == "" || executeCode != verifyCode {
+		t.Errorf("verify span error code = %q, execute span error code = %q; want the same code", verifyCode, executeCode)
+	}
+}
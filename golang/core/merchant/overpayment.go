@@ -0,0 +1,153 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// OverpaymentPolicy decides what happens to a payload that pays more than an
+// exact quote. Underpayment is always rejected.
+type OverpaymentPolicy struct {
+	// tolerance is the largest accepted overpayment as a fraction of the
+	// quoted amount; nil rejects every overpayment.
+	tolerance *big.Rat
+}
+
+// RejectOverpayment fails every payload whose amount differs from the exact
+// quote with AMOUNT_MISMATCH. It is the default.
+func RejectOverpayment() OverpaymentPolicy {
+	return OverpaymentPolicy{}
+}
+
+// AcceptOverpaymentUpTo accepts payloads that pay at most percent more than
+// the exact quote. They are verified and settled for the amount signed, and
+// the difference is recorded in the receipt as a tip.
+func AcceptOverpaymentUpTo(percent float64) OverpaymentPolicy {
+	tolerance := new(big.Rat)
+	if percent > 0 {
+		tolerance.SetFloat64(percent / 100)
+	}
+	return OverpaymentPolicy{tolerance: tolerance}
+}
+
+// WithOverpaymentPolicy sets how payloads paying more than an exact quote are
+// treated. The default is RejectOverpayment.
+func WithOverpaymentPolicy(policy OverpaymentPolicy) Option {
+	return func(o *BusinessOrchestrator) {
+		o.overpayment = policy
+	}
+}
+
+// amountMismatchError reports a payload paying a different amount than the
+// exact quote, caught before a facilitator round trip.
+type amountMismatchError struct {
+	expected  string
+	submitted string
+}
+
+func (e *amountMismatchError) Error() string {
+	return fmt.Sprintf("payload pays %s, expected %s", e.submitted, e.expected)
+}
+
+// quoteForAmount returns the exact requirement among accepts that the payload
+// pays on every count but its amount. It returns nil when the payload pays a
+// quote in full or matches no exact quote.
+func quoteForAmount(accepts []x402types.PaymentRequirements, accepted x402types.PaymentRequirements) *x402types.PaymentRequirements {
+	if accepted.Scheme != x402pkg.SchemeExact {
+		return nil
+	}
+	var found *x402types.PaymentRequirements
+	for i := range accepts {
+		quoted := &accepts[i]
+		evm := x402pkg.IsEVMNetwork(quoted.Network)
+		sameAddress := func(a, b string) bool {
+			return a == b || (evm && strings.EqualFold(a, b))
+		}
+		if quoted.Scheme != accepted.Scheme || quoted.Network != accepted.Network ||
+			!sameAddress(quoted.Asset, accepted.Asset) || !sameAddress(quoted.PayTo, accepted.PayTo) {
+			continue
+		}
+		if quoted.Amount == accepted.Amount {
+			return nil
+		}
+		if found == nil {
+			found = quoted
+		}
+	}
+	return found
+}
+
+// overpaidQuote returns the exact requirement the payload overpays within the
+// overpayment policy, and the overpaid amount. An amount that differs from the
+// quote in any other way fails with an *amountMismatchError. Payloads that pay
+// a quote in full, or match no exact quote at all, yield nil and are left to
+// the usual matching.
+func (o *BusinessOrchestrator) overpaidQuote(paymentState *state.PaymentState) (*x402types.PaymentRequirements, *big.Int, error) {
+	if paymentState.Payload == nil || paymentState.Requirements == nil {
+		return nil, nil, nil
+	}
+	accepted := paymentState.Payload.Accepted
+	quoted := quoteForAmount(paymentState.Requirements.Accepts, accepted)
+	if quoted == nil {
+		return nil, nil, nil
+	}
+
+	mismatch := &amountMismatchError{expected: quoted.Amount, submitted: accepted.Amount}
+	expected, ok := new(big.Int).SetString(quoted.Amount, 10)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid quoted amount %q", quoted.Amount)
+	}
+	submitted, ok := new(big.Int).SetString(accepted.Amount, 10)
+	if !ok || submitted.Cmp(expected) < 0 || o.overpayment.tolerance == nil {
+		return nil, nil, mismatch
+	}
+	tip := new(big.Int).Sub(submitted, expected)
+	limit := new(big.Rat).Mul(new(big.Rat).SetInt(expected), o.overpayment.tolerance)
+	if new(big.Rat).SetInt(tip).Cmp(limit) > 0 {
+		return nil, nil, mismatch
+	}
+	return quoted, tip, nil
+}
+
+// recordTip notes an accepted overpayment in the receipt's Extra under
+// x402.ExtraKeyTip, copying the map so that one shared with other receipts is
+// left alone.
+func (o *BusinessOrchestrator) recordTip(receipt *x402core.SettleResponse, paymentState *state.PaymentState) {
+	if receipt == nil {
+		return
+	}
+	quoted, tip, err := o.overpaidQuote(paymentState)
+	if err != nil || quoted == nil {
+		return
+	}
+	extra := make(map[string]interface{}, len(receipt.Extra)+1)
+	for key, value := range receipt.Extra {
+		extra[key] = value
+	}
+	extra[x402pkg.ExtraKeyTip] = map[string]interface{}{
+		"quoted": quoted.Amount,
+		"paid":   paymentState.Payload.Accepted.Amount,
+		"tip":    tip.String(),
+	}
+	receipt.Extra = extra
+}
@@ -0,0 +1,130 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+func TestBusinessOrchestrator_OverpaymentPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []Option
+		submitted   string
+		wantSettled string
+		wantTip     string
+	}{
+		{name: "exact amount", submitted: "1000000", wantSettled: "1000000"},
+		{name: "one wei over rejected by default", submitted: "1000001"},
+		{name: "one wei over rejected explicitly", opts: []Option{WithOverpaymentPolicy(RejectOverpayment())}, submitted: "1000001"},
+		{name: "one wei over within tolerance", opts: []Option{WithOverpaymentPolicy(AcceptOverpaymentUpTo(1))}, submitted: "1000001", wantSettled: "1000001", wantTip: "1"},
+		{name: "five percent over at the tolerance", opts: []Option{WithOverpaymentPolicy(AcceptOverpaymentUpTo(5))}, submitted: "1050000", wantSettled: "1050000", wantTip: "50000"},
+		{name: "five percent over beyond the tolerance", opts: []Option{WithOverpaymentPolicy(AcceptOverpaymentUpTo(1))}, submitted: "1050000"},
+		{name: "one unit under", opts: []Option{WithOverpaymentPolicy(AcceptOverpaymentUpTo(5))}, submitted: "999999"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var verified, settled []string
+			orchestrator := newTestOrchestrator(
+				t,
+				&MockResourceServer{
+					BuildPaymentRequirementsFromConfigFunc: func(ctx context.Context, config x402core.ResourceConfig) ([]x402types.PaymentRequirements, error) {
+						return []x402types.PaymentRequirements{{
+							Scheme:  x402.SchemeExact,
+							Network: string(config.Network),
+							PayTo:   config.PayTo,
+							Asset:   "0x456",
+							Amount:  "1000000",
+						}}, nil
+					},
+					VerifyPaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.VerifyResponse, error) {
+						verified = append(verified, requirements.Amount)
+						return &x402core.VerifyResponse{IsValid: true, Payer: "0x789"}, nil
+					},
+					SettlePaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.SettleResponse, error) {
+						settled = append(settled, requirements.Amount)
+						return &x402core.SettleResponse{Success: true, Network: x402.NetworkBaseSepolia, Transaction: "0xtx", Amount: requirements.Amount}, nil
+					},
+				},
+				&mockBusinessService{executeFunc: func(ctx context.Context, request business.Request) (*business.Result, error) {
+					if request.PaymentVerified {
+						return &business.Result{Message: "done"}, nil
+					}
+					return nil, business.NewPaymentRequiredError("pay", business.ServiceRequirements{
+						Price:    "1.00",
+						Resource: "/item",
+						Scheme:   x402.SchemeExact,
+					})
+				}},
+				[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+				tt.opts...,
+			)
+
+			task := quoteRequest(t, orchestrator, "task-overpayment", a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "buy"}))
+			payQuotedTask(t, orchestrator, task, func(payload *x402types.PaymentPayload) {
+				payload.Accepted.Amount = tt.submitted
+			})
+
+			if tt.wantSettled == "" {
+				if task.Status.State != a2a.TaskStateFailed {
+					t.Fatalf("task state = %v, want %v", task.Status.State, a2a.TaskStateFailed)
+				}
+				if got := task.Status.Message.Metadata[x402.MetadataKeyError]; got != x402.ErrorCodeAmountMismatch {
+					t.Errorf("error code = %v, want %s", got, x402.ErrorCodeAmountMismatch)
+				}
+				text := x402state.ExtractMessageText(task.Status.Message)
+				if !strings.Contains(text, tt.submitted) || !strings.Contains(text, "1000000") {
+					t.Errorf("failure message %q does not name the expected and submitted amounts", text)
+				}
+				if len(verified) != 0 || len(settled) != 0 {
+					t.Errorf("facilitator called (verify %v, settle %v), want the mismatch caught locally", verified, settled)
+				}
+				return
+			}
+
+			if task.Status.State != a2a.TaskStateCompleted {
+				t.Fatalf("task state = %v, want %v: %s", task.Status.State, a2a.TaskStateCompleted, x402state.ExtractMessageText(task.Status.Message))
+			}
+			if len(verified) != 1 || verified[0] != tt.wantSettled || len(settled) != 1 || settled[0] != tt.wantSettled {
+				t.Fatalf("verified %v and settled %v, want [%s] each", verified, settled, tt.wantSettled)
+			}
+			receipts, err := x402state.ExtractPaymentReceipts(task)
+			if err != nil || len(receipts) != 1 {
+				t.Fatalf("ExtractPaymentReceipts() = %v, %v", receipts, err)
+			}
+			tip, hasTip := receipts[0].Extra[x402.ExtraKeyTip].(map[string]interface{})
+			if tt.wantTip == "" {
+				if hasTip {
+					t.Errorf("receipt tip = %v, want none", tip)
+				}
+				return
+			}
+			if tip["tip"] != tt.wantTip || tip["quoted"] != "1000000" || tip["paid"] != tt.submitted {
+				t.Errorf("receipt tip = %v, want %s over 1000000", tip, tt.wantTip)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// PayToProvider derives the address a task's payment is sent to, such as an
+// HD-derived deposit address or a forwarding contract, so incoming funds can
+// be matched to tasks.
+type PayToProvider interface {
+	AddressFor(ctx context.Context, network string, taskID a2a.TaskID) (string, error)
+}
+
+// PayToProviderFunc adapts a function to the PayToProvider interface.
+type PayToProviderFunc func(ctx context.Context, network string, taskID a2a.TaskID) (string, error)
+
+func (f PayToProviderFunc) AddressFor(ctx context.Context, network string, taskID a2a.TaskID) (string, error) {
+	return f(ctx, network, taskID)
+}
+
+// WithPayToProvider quotes every task with an address from provider instead
+// of the network's static PayToAddress and ResourcePayTo. The address is
+// recorded in the quote's metadata and in the receipt store. When the
+// provider fails, the quote falls back to the static addresses if
+// fallbackToStatic is set and fails otherwise.
+func WithPayToProvider(provider PayToProvider, fallbackToStatic bool) Option {
+	return func(o *BusinessOrchestrator) {
+		o.payTo = provider
+		o.payToFallback = fallbackToStatic
+	}
+}
+
+// quoteNetworkConfigs returns the network configs to quote taskID on, with
+// each PayToAddress replaced by the provider's address.
+func (o *BusinessOrchestrator) quoteNetworkConfigs(ctx context.Context, taskID a2a.TaskID) ([]types.NetworkConfig, error) {
+	if o.payTo == nil {
+		return o.networkConfigs, nil
+	}
+	configs := make([]types.NetworkConfig, len(o.networkConfigs))
+	for i, networkConfig := range o.networkConfigs {
+		address, err := o.payTo.AddressFor(ctx, networkConfig.NetworkName, taskID)
+		if err == nil && address == "" {
+			err = fmt.Errorf("provider returned no address")
+		}
+		if err != nil {
+			if !o.payToFallback {
+				return nil, fmt.Errorf("failed to derive pay-to address on network %s: %w", networkConfig.NetworkName, err)
+			}
+			o.logger.WarnContext(ctx, "x402 pay-to address not derived: using the static address",
+				"task_id", taskID,
+				"network", networkConfig.NetworkName,
+				"error", err,
+			)
+			address = networkConfig.PayToAddress
+		}
+		if err == nil {
+			// A derived address replaces the per-resource ones as well.
+			networkConfig.ResourcePayTo = nil
+		}
+		networkConfig.PayToAddress = address
+		configs[i] = networkConfig
+	}
+	return configs, nil
+}
+
+// resourcePayTo returns the address that payments for resource go to on the
+// network: its ResourcePayTo entry, or the network's PayToAddress.
+func resourcePayTo(networkConfig types.NetworkConfig, resource string) string {
+	if address := networkConfig.ResourcePayTo[resource]; address != "" {
+		return address
+	}
+	return networkConfig.PayToAddress
+}
+
+// payToAddresses lists the address each network's requirements pay.
+func payToAddresses(requirements *x402types.PaymentRequired) map[string]string {
+	if requirements == nil {
+		return nil
+	}
+	addresses := make(map[string]string)
+	for _, requirement := range requirements.Accepts {
+		if _, seen := addresses[requirement.Network]; !seen {
+			addresses[requirement.Network] = requirement.PayTo
+		}
+	}
+	return addresses
+}
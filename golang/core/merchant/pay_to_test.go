@@ -0,0 +1,204 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// derivedPayTo is a deterministic fake provider: every task and network gets
+// its own address.
+var derivedPayTo = PayToProviderFunc(func(ctx context.Context, network string, taskID a2a.TaskID) (string, error) {
+	sum := sha256.Sum256([]byte(network + "/" + string(taskID)))
+	return "0x" + hex.EncodeToString(sum[:20]), nil
+})
+
+func newPayToOrchestrator(t *testing.T, receipts ReceiptStore, provider PayToProvider, fallback bool) *BusinessOrchestrator {
+	return newTestOrchestrator(
+		t,
+		&MockResourceServer{
+			BuildPaymentRequirementsFromConfigFunc: func(ctx context.Context, config x402core.ResourceConfig) ([]x402types.PaymentRequirements, error) {
+				return []x402types.PaymentRequirements{{
+					Scheme:  config.Scheme,
+					Network: string(config.Network),
+					PayTo:   config.PayTo,
+					Asset:   "0x456",
+					Amount:  "1000000",
+				}}, nil
+			},
+			VerifyPaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.VerifyResponse, error) {
+				if payload.Accepted.PayTo != requirements.PayTo {
+					return &x402core.VerifyResponse{IsValid: false, InvalidReason: "recipient mismatch"}, nil
+				}
+				return &x402core.VerifyResponse{IsValid: true, Payer: "0xpayer"}, nil
+			},
+			SettlePaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.SettleResponse, error) {
+				return &x402core.SettleResponse{Success: true, Network: x402.NetworkBaseSepolia, Transaction: "0xtx", Payer: "0xpayer"}, nil
+			},
+		},
+		&mockBusinessService{executeFunc: func(ctx context.Context, request business.Request) (*business.Result, error) {
+			if request.PaymentVerified {
+				return &business.Result{Message: "done"}, nil
+			}
+			return nil, business.NewPaymentRequiredError("pay", business.ServiceRequirements{Price: "1.00", Resource: "/thing"})
+		}},
+		[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+		WithPayToProvider(provider, fallback),
+		WithReceiptStore(receipts),
+	)
+}
+
+func TestWithPayToProvider_DerivesAddressPerTask(t *testing.T) {
+	receipts := NewMemoryReceiptStore()
+	orchestrator := newPayToOrchestrator(t, receipts, derivedPayTo, false)
+
+	first := quoteRequest(t, orchestrator, "task-one", a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "buy"}))
+	second := quoteRequest(t, orchestrator, "task-two", a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "buy"}))
+
+	payTo := func(task *a2a.Task) string {
+		requirements, err := x402state.ExtractPaymentRequirements(task)
+		if err != nil {
+			t.Fatalf("ExtractPaymentRequirements() error = %v", err)
+		}
+		return requirements.Accepts[0].PayTo
+	}
+	firstPayTo, secondPayTo := payTo(first), payTo(second)
+	wantFirst, _ := derivedPayTo(context.Background(), x402.NetworkBaseSepolia, "task-one")
+	if firstPayTo != wantFirst {
+		t.Errorf("task-one payTo = %q, want %q", firstPayTo, wantFirst)
+	}
+	if firstPayTo == secondPayTo {
+		t.Errorf("both tasks quoted payTo %q, want different addresses", firstPayTo)
+	}
+	if got := x402state.ExtractPayToAddresses(first)[x402.NetworkBaseSepolia]; got != firstPayTo {
+		t.Errorf("metadata payTo = %q, want %q", got, firstPayTo)
+	}
+
+	payQuotedTask(t, orchestrator, first)
+	if first.Status.State != a2a.TaskStateCompleted {
+		t.Fatalf("task state = %v, want %v", first.Status.State, a2a.TaskStateCompleted)
+	}
+
+	records, err := receipts.GetByTask(context.Background(), first.ID)
+	if err != nil || len(records) != 1 {
+		t.Fatalf("GetByTask() = %v, %v, want one record", records, err)
+	}
+	if records[0].PayTo != firstPayTo {
+		t.Errorf("receipt payTo = %q, want %q", records[0].PayTo, firstPayTo)
+	}
+}
+
+func TestWithPayToProvider_ProviderError(t *testing.T) {
+	failing := PayToProviderFunc(func(ctx context.Context, network string, taskID a2a.TaskID) (string, error) {
+		return "", errors.New("keystore locked")
+	})
+
+	t.Run("falls back to the static address", func(t *testing.T) {
+		task := quoteRequest(t, newPayToOrchestrator(t, NewMemoryReceiptStore(), failing, true), "task-fallback", a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "buy"}))
+		requirements, err := x402state.ExtractPaymentRequirements(task)
+		if err != nil {
+			t.Fatalf("ExtractPaymentRequirements() error = %v", err)
+		}
+		if got := requirements.Accepts[0].PayTo; got != "0x123" {
+			t.Errorf("payTo = %q, want the static 0x123", got)
+		}
+	})
+
+	t.Run("fails the quote", func(t *testing.T) {
+		task := quoteRequest(t, newPayToOrchestrator(t, NewMemoryReceiptStore(), failing, false), "task-fail", a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "buy"}))
+		if task.Status.State != a2a.TaskStateFailed {
+			t.Fatalf("task state = %v, want %v", task.Status.State, a2a.TaskStateFailed)
+		}
+	})
+}
+
+func TestBusinessOrchestrator_ResourcePayTo(t *testing.T) {
+	const (
+		imagesPayTo = "0x1110000000000000000000000000000000000001"
+		videoPayTo  = "0x2220000000000000000000000000000000000002"
+	)
+	tests := []struct {
+		resource  string
+		wantPayTo string
+	}{
+		{resource: "/images", wantPayTo: imagesPayTo},
+		{resource: "/video", wantPayTo: videoPayTo},
+		{resource: "/unmapped", wantPayTo: "0x123"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.resource, func(t *testing.T) {
+			var verified []string
+			orchestrator := newTestOrchestrator(
+				t,
+				&MockResourceServer{
+					BuildPaymentRequirementsFromConfigFunc: func(ctx context.Context, config x402core.ResourceConfig) ([]x402types.PaymentRequirements, error) {
+						return []x402types.PaymentRequirements{{
+							Scheme:  config.Scheme,
+							Network: string(config.Network),
+							PayTo:   config.PayTo,
+							Asset:   "0x456",
+							Amount:  "1000000",
+						}}, nil
+					},
+					VerifyPaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.VerifyResponse, error) {
+						verified = append(verified, requirements.PayTo)
+						return &x402core.VerifyResponse{IsValid: payload.Accepted.PayTo == requirements.PayTo, Payer: "0xpayer"}, nil
+					},
+				},
+				&mockBusinessService{executeFunc: func(ctx context.Context, request business.Request) (*business.Result, error) {
+					if request.PaymentVerified {
+						return &business.Result{Message: "done"}, nil
+					}
+					return nil, business.NewPaymentRequiredError("pay", business.ServiceRequirements{Price: "1.00", Resource: tt.resource})
+				}},
+				[]types.NetworkConfig{{
+					NetworkName:   x402.NetworkBaseSepolia,
+					PayToAddress:  "0x123",
+					ResourcePayTo: map[string]string{"/images": imagesPayTo, "/video": videoPayTo},
+				}},
+			)
+
+			task := quoteRequest(t, orchestrator, "task-resource", a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "buy"}))
+			requirements, err := x402state.ExtractPaymentRequirements(task)
+			if err != nil {
+				t.Fatalf("ExtractPaymentRequirements() error = %v", err)
+			}
+			if got := requirements.Accepts[0].PayTo; got != tt.wantPayTo {
+				t.Fatalf("quoted payTo = %q, want %q", got, tt.wantPayTo)
+			}
+
+			payQuotedTask(t, orchestrator, task)
+			if task.Status.State != a2a.TaskStateCompleted {
+				t.Fatalf("task state = %v, want %v", task.Status.State, a2a.TaskStateCompleted)
+			}
+			if len(verified) != 1 || verified[0] != tt.wantPayTo {
+				t.Errorf("verified against %v, want [%s]", verified, tt.wantPayTo)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"strings"
+)
+
+// PayerPolicy decides whether a verified payer may buy from this merchant. It
+// runs after verification and before any business execution or settlement,
+// so a denied payer is never charged.
+type PayerPolicy interface {
+	Allow(ctx context.Context, payer string, network string) (bool, string)
+}
+
+// PayerPolicyFunc adapts a function to the PayerPolicy interface.
+type PayerPolicyFunc func(ctx context.Context, payer string, network string) (bool, string)
+
+func (f PayerPolicyFunc) Allow(ctx context.Context, payer string, network string) (bool, string) {
+	return f(ctx, payer, network)
+}
+
+// StaticPayerPolicy checks payers against fixed lists. Denied addresses are
+// always refused; when Allowed is non-empty only those addresses may pay.
+// EVM addresses are compared case-insensitively.
+type StaticPayerPolicy struct {
+	Allowed []string
+	Denied  []string
+}
+
+func (p StaticPayerPolicy) Allow(ctx context.Context, payer string, network string) (bool, string) {
+	if containsAddress(p.Denied, payer) {
+		return false, "payer " + payer + " is denied"
+	}
+	if len(p.Allowed) > 0 && !containsAddress(p.Allowed, payer) {
+		return false, "payer " + payer + " is not on the allowlist"
+	}
+	return true, ""
+}
+
+func containsAddress(addresses []string, payer string) bool {
+	for _, address := range addresses {
+		if address == payer || (strings.HasPrefix(address, "0x") && strings.EqualFold(address, payer)) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithPayerPolicy screens payers after verification.
+func WithPayerPolicy(policy PayerPolicy) Option {
+	return func(o *BusinessOrchestrator) {
+		o.payerPolicy = policy
+	}
+}
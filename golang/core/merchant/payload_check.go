@@ -0,0 +1,102 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// payloadMismatchError reports a payload whose accepted block disagrees with
+// the requirement it was matched to, caught before a facilitator round trip.
+type payloadMismatchError struct {
+	field string
+	got   string
+	want  string
+}
+
+func (e *payloadMismatchError) Error() string {
+	return fmt.Sprintf("payload %s %q does not match requirement %q", e.field, e.got, e.want)
+}
+
+// checkPayloadMatchesRequirement compares the payload's accepted scheme,
+// network, asset, payTo and amount with the matched requirement. Addresses
+// compare case-insensitively on EVM networks. Under the upto scheme the
+// authorized amount may be below the quoted maximum.
+func checkPayloadMatchesRequirement(accepted, matched x402types.PaymentRequirements) error {
+	evm := x402pkg.IsEVMNetwork(matched.Network)
+	sameAddress := func(a, b string) bool {
+		if evm {
+			return strings.EqualFold(a, b)
+		}
+		return a == b
+	}
+
+	switch {
+	case accepted.Scheme != matched.Scheme:
+		return &payloadMismatchError{field: "scheme", got: accepted.Scheme, want: matched.Scheme}
+	case accepted.Network != matched.Network:
+		return &payloadMismatchError{field: "network", got: accepted.Network, want: matched.Network}
+	case !sameAddress(accepted.Asset, matched.Asset):
+		return &payloadMismatchError{field: "asset", got: accepted.Asset, want: matched.Asset}
+	case !sameAddress(accepted.PayTo, matched.PayTo):
+		return &payloadMismatchError{field: "payTo", got: accepted.PayTo, want: matched.PayTo}
+	}
+
+	if matched.Scheme != x402pkg.SchemeUpto {
+		if accepted.Amount != matched.Amount {
+			return &payloadMismatchError{field: "amount", got: accepted.Amount, want: matched.Amount}
+		}
+		return nil
+	}
+	amount, ok := new(big.Int).SetString(accepted.Amount, 10)
+	maximum, maxOK := new(big.Int).SetString(matched.Amount, 10)
+	if !ok || !maxOK || amount.Sign() < 0 || amount.Cmp(maximum) > 0 {
+		return &payloadMismatchError{field: "amount", got: accepted.Amount, want: "at most " + matched.Amount}
+	}
+	return nil
+}
+
+// tamperedRequirementsError reports a payment matched to a requirement that
+// was not among those issued with the quote.
+type tamperedRequirementsError struct {
+	requirement x402types.PaymentRequirements
+}
+
+func (e *tamperedRequirementsError) Error() string {
+	return fmt.Sprintf("requirement %s on %s paying %s to %s was not issued with the quote",
+		e.requirement.Scheme, e.requirement.Network, e.requirement.Amount, e.requirement.PayTo)
+}
+
+// checkIssuedRequirement confirms that matched is one of the requirements
+// hashed into issued when the quote went out. Together with
+// checkPayloadMatchesRequirement this holds the payload to the original quote
+// rather than to the requirements stored on the task now. Quotes recorded
+// without hashes are not checked.
+func checkIssuedRequirement(matched x402types.PaymentRequirements, issued []string) error {
+	if issued == nil {
+		return nil
+	}
+	if !slices.Contains(issued, state.RequirementHash(matched)) {
+		return &tamperedRequirementsError{requirement: matched}
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+func TestCheckPayloadMatchesRequirement(t *testing.T) {
+	matched := x402types.PaymentRequirements{
+		Scheme:  x402.SchemeExact,
+		Network: x402.NetworkBaseSepolia,
+		Asset:   "0x036CbD53842c5426634e7929541eC2318f3dCF7e",
+		PayTo:   "0xAbC0000000000000000000000000000000000001",
+		Amount:  "1000000",
+	}
+
+	tests := []struct {
+		name      string
+		mutate    func(accepted, matched *x402types.PaymentRequirements)
+		wantField string
+	}{
+		{name: "matching payload", mutate: func(accepted, matched *x402types.PaymentRequirements) {}},
+		{
+			name: "EVM addresses compare case-insensitively",
+			mutate: func(accepted, matched *x402types.PaymentRequirements) {
+				accepted.PayTo = "0xabc0000000000000000000000000000000000001"
+			},
+		},
+		{name: "scheme", mutate: func(accepted, matched *x402types.PaymentRequirements) { accepted.Scheme = x402.SchemeUpto }, wantField: "scheme"},
+		{name: "network", mutate: func(accepted, matched *x402types.PaymentRequirements) { accepted.Network = x402.NetworkBase }, wantField: "network"},
+		{name: "asset", mutate: func(accepted, matched *x402types.PaymentRequirements) { accepted.Asset = "0x456" }, wantField: "asset"},
+		{name: "payTo", mutate: func(accepted, matched *x402types.PaymentRequirements) { accepted.PayTo = "0x123" }, wantField: "payTo"},
+		{name: "exact amount below quote", mutate: func(accepted, matched *x402types.PaymentRequirements) { accepted.Amount = "999999" }, wantField: "amount"},
+		{
+			name: "upto amount below maximum",
+			mutate: func(accepted, matched *x402types.PaymentRequirements) {
+				accepted.Scheme, matched.Scheme = x402.SchemeUpto, x402.SchemeUpto
+				accepted.Amount = "500000"
+			},
+		},
+		{
+			name: "upto amount above maximum",
+			mutate: func(accepted, matched *x402types.PaymentRequirements) {
+				accepted.Scheme, matched.Scheme = x402.SchemeUpto, x402.SchemeUpto
+				accepted.Amount = "1000001"
+			},
+			wantField: "amount",
+		},
+		{
+			name: "Solana addresses are case-sensitive",
+			mutate: func(accepted, matched *x402types.PaymentRequirements) {
+				accepted.Network, matched.Network = x402.NetworkSolanaDevnet, x402.NetworkSolanaDevnet
+				accepted.PayTo, matched.PayTo = "9wzdxwbbmkg8ztbnmquxvqrayrzzdsgydlvl9zytawwm", solanaPayTo
+			},
+			wantField: "payTo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accepted, want := matched, matched
+			tt.mutate(&accepted, &want)
+			err := checkPayloadMatchesRequirement(accepted, want)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Fatalf("checkPayloadMatchesRequirement() error = %v", err)
+				}
+				return
+			}
+			var mismatch *payloadMismatchError
+			if !errors.As(err, &mismatch) {
+				t.Fatalf("checkPayloadMatchesRequirement() error = %v, want a mismatch", err)
+			}
+			if mismatch.field != tt.wantField {
+				t.Errorf("mismatched field = %q, want %q", mismatch.field, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestBusinessOrchestrator_PayloadMismatchSkipsFacilitator(t *testing.T) {
+	verifyCalled := false
+	orchestrator := newTestOrchestrator(
+		t,
+		&MockResourceServer{
+			VerifyPaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.VerifyResponse, error) {
+				verifyCalled = true
+				return &x402core.VerifyResponse{IsValid: true}, nil
+			},
+		},
+		&mockBusinessService{},
+		[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+	)
+
+	requirements := x402types.PaymentRequirements{Scheme: x402.SchemeExact, Network: x402.NetworkBaseSepolia, PayTo: "0x123", Asset: "0x456", Amount: "100"}
+	accepted := requirements
+	accepted.PayTo = "0x999"
+	task := &a2a.Task{ID: "task-mismatch", ContextID: "context-mismatch", Status: a2a.TaskStatus{State: a2a.TaskStateInputRequired}}
+	requestContext := &a2asrv.RequestContext{StoredTask: task, TaskID: task.ID, ContextID: task.ContextID}
+	paymentState := &x402state.PaymentState{
+		Status:       x402state.PaymentSubmitted,
+		Requirements: &x402types.PaymentRequired{X402Version: x402.X402Version, Accepts: []x402types.PaymentRequirements{requirements}},
+		Payload:      &x402types.PaymentPayload{X402Version: x402.X402Version, Accepted: accepted},
+	}
+
+	result, err := orchestrator.handlePaymentSubmitted(context.Background(), requestContext, task, &mockEventQueue{}, paymentState)
+	if err != nil {
+		t.Fatalf("handlePaymentSubmitted() error = %v", err)
+	}
+	if verifyCalled {
+		t.Error("facilitator verify called for a mismatched payload")
+	}
+	if result.Status != x402state.PaymentFailed {
+		t.Errorf("payment status = %v, want %v", result.Status, x402state.PaymentFailed)
+	}
+	if got := task.Status.Message.Metadata[x402.MetadataKeyError]; got != x402.ErrorCodePayloadMismatch {
+		t.Errorf("error code = %v, want %s", got, x402.ErrorCodePayloadMismatch)
+	}
+}
+
+func TestBusinessOrchestrator_TamperedRequirementsSkipFacilitator(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(*x402types.PaymentRequirements)
+	}{
+		{name: "altered amount", tamper: func(r *x402types.PaymentRequirements) { r.Amount = "1" }},
+		{name: "altered payTo", tamper: func(r *x402types.PaymentRequirements) { r.PayTo = "0x999" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orchestrator := newNetworkMatchingOrchestrator(t)
+			verifyCalled := false
+			orchestrator.merchant.(*MockResourceServer).VerifyPaymentFunc = func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.VerifyResponse, error) {
+				verifyCalled = true
+				return &x402core.VerifyResponse{IsValid: true}, nil
+			}
+			task := quoteTask(t, orchestrator)
+
+			// The stored quote is rewritten after issue and the payload agrees
+			// with the rewritten one.
+			requirements, err := x402state.ExtractPaymentRequirements(task)
+			if err != nil || requirements == nil {
+				t.Fatalf("ExtractPaymentRequirements() = %v, %v", requirements, err)
+			}
+			tt.tamper(&requirements.Accepts[0])
+			if err := x402state.SetPaymentRequirements(task.Status.Message, requirements); err != nil {
+				t.Fatalf("SetPaymentRequirements() error = %v", err)
+			}
+			payOnNetwork(t, orchestrator, task, x402.NetworkBaseSepolia)
+
+			if verifyCalled {
+				t.Error("facilitator verify called for tampered requirements")
+			}
+			if task.Status.State != a2a.TaskStateFailed {
+				t.Errorf("state = %s, want %s", task.Status.State, a2a.TaskStateFailed)
+			}
+			if got := task.Status.Message.Metadata[x402.MetadataKeyError]; got != x402.ErrorCodeTamperedRequirements {
+				t.Errorf("error code = %v, want %s", got, x402.ErrorCodeTamperedRequirements)
+			}
+		})
+	}
+}
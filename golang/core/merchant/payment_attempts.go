@@ -0,0 +1,86 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/a2aproject/a2a-go/a2asrv/eventqueue"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+)
+
+// DefaultMaxPaymentAttempts bounds how many payment submissions a task turns
+// away while keeping its quote open before it refuses any more.
+const DefaultMaxPaymentAttempts = 5
+
+// WithMaxPaymentAttempts caps the failed payment submissions a task accepts
+// across every path that keeps its quote open: payments on an unquoted
+// option, against an expired quote, or with an undecodable payload. Once a
+// task has turned away attempts submissions, the next one fails the task with
+// TOO_MANY_PAYMENT_ATTEMPTS before it reaches the facilitator. Zero removes
+// the cap, leaving only the per-path limits.
+func WithMaxPaymentAttempts(attempts int) Option {
+	return func(o *BusinessOrchestrator) {
+		o.maxPaymentAttempts = max(attempts, 0)
+	}
+}
+
+// isPaymentSubmission reports whether message offers a payment, as opposed to
+// declining the quote or asking a question.
+func isPaymentSubmission(message *a2a.Message) bool {
+	if message == nil {
+		return false
+	}
+	metadata := message.Meta()
+	if _, ok := metadata[x402pkg.MetadataKeyPayload]; ok {
+		return true
+	}
+	status, _ := metadata[x402pkg.MetadataKeyStatus].(string)
+	return status == state.PaymentSubmitted.String()
+}
+
+// paymentAttemptsExhausted reports whether message is another payment for a
+// task that has already turned away the maximum number of attempts.
+func (o *BusinessOrchestrator) paymentAttemptsExhausted(task *a2a.Task, message *a2a.Message) bool {
+	return o.maxPaymentAttempts > 0 &&
+		task.Status.State == a2a.TaskStateInputRequired &&
+		isPaymentSubmission(message) &&
+		state.ExtractFailedAttempts(task) >= o.maxPaymentAttempts
+}
+
+// recordFailedAttempt counts a payment submission the task turned away on
+// message, the task's next status message.
+func recordFailedAttempt(task *a2a.Task, message *a2a.Message) {
+	state.SetFailedAttempts(message, state.ExtractFailedAttempts(task)+1)
+}
+
+// failTooManyAttempts ends a task that refuses further payment submissions.
+func (o *BusinessOrchestrator) failTooManyAttempts(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	eventQueue eventqueue.Queue,
+) error {
+	partialState := &state.PaymentState{}
+	partialState.Requirements, _ = state.ExtractPaymentRequirements(task)
+	_, err := o.failPayment(ctx, requestContext, task, eventQueue, partialState,
+		fmt.Errorf("payment failed %d times; no further attempts are accepted for this task", state.ExtractFailedAttempts(task)),
+		x402pkg.ErrorCodeTooManyPaymentAttempts, nil)
+	return err
+}
@@ -0,0 +1,93 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+func TestBusinessOrchestrator_Execute_TooManyPaymentAttempts(t *testing.T) {
+	const attempts = 3
+	orchestrator := newNetworkMatchingOrchestrator(
+		t,
+		WithMaxPaymentAttempts(attempts),
+		WithMaxOptionRetries(10),
+		WithMaxPayloadRetries(10),
+	)
+	var verified int
+	orchestrator.merchant.(*MockResourceServer).VerifyPaymentFunc = func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.VerifyResponse, error) {
+		verified++
+		return &x402core.VerifyResponse{IsValid: true, Payer: "0xpayer"}, nil
+	}
+	task := quoteTask(t, orchestrator)
+
+	// Failures on different paths count against the same budget.
+	for i := range attempts {
+		if i%2 == 0 {
+			payOnNetwork(t, orchestrator, task, "eip155:1")
+		} else {
+			sendMalformedPayload(t, orchestrator, task)
+		}
+		if task.Status.State != a2a.TaskStateInputRequired {
+			t.Fatalf("state after attempt %d = %s, want %s", i+1, task.Status.State, a2a.TaskStateInputRequired)
+		}
+		if got := x402state.ExtractFailedAttempts(task); got != i+1 {
+			t.Fatalf("failed attempts after attempt %d = %d, want %d", i+1, got, i+1)
+		}
+	}
+
+	// Even a payment that would verify is refused once the budget is spent.
+	payOnNetwork(t, orchestrator, task, x402.NetworkBaseSepolia)
+	if task.Status.State != a2a.TaskStateFailed {
+		t.Fatalf("state after attempt %d = %s, want %s", attempts+1, task.Status.State, a2a.TaskStateFailed)
+	}
+	if got := task.Status.Message.Metadata[x402.MetadataKeyError]; got != x402.ErrorCodeTooManyPaymentAttempts {
+		t.Errorf("error code = %v, want %s", got, x402.ErrorCodeTooManyPaymentAttempts)
+	}
+	if status, _ := x402state.ExtractPaymentStatus(task); status != x402state.PaymentFailed {
+		t.Errorf("payment status = %s, want %s", status, x402state.PaymentFailed)
+	}
+	if verified != 0 {
+		t.Errorf("VerifyPayment() called %d times, want 0", verified)
+	}
+}
+
+func TestBusinessOrchestrator_Execute_PaymentAttemptsUncapped(t *testing.T) {
+	orchestrator := newNetworkMatchingOrchestrator(
+		t,
+		WithMaxPaymentAttempts(0),
+		WithMaxOptionRetries(10),
+	)
+	task := quoteTask(t, orchestrator)
+
+	for range DefaultMaxPaymentAttempts + 1 {
+		payOnNetwork(t, orchestrator, task, "eip155:1")
+	}
+	if task.Status.State != a2a.TaskStateInputRequired {
+		t.Fatalf("state after rejected payments = %s, want %s", task.Status.State, a2a.TaskStateInputRequired)
+	}
+
+	payOnNetwork(t, orchestrator, task, x402.NetworkBaseSepolia)
+	if task.Status.State != a2a.TaskStateCompleted {
+		t.Fatalf("state after supported network = %s, want %s", task.Status.State, a2a.TaskStateCompleted)
+	}
+}
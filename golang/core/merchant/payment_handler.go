@@ -16,8 +16,11 @@ package merchant
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/big"
 	"strings"
+	"time"
 
 	"github.com/a2aproject/a2a-go/a2a"
 	"github.com/a2aproject/a2a-go/a2asrv"
@@ -29,18 +32,45 @@ import (
 	x402types "github.com/x402-foundation/x402/go/types"
 )
 
+// isFree reports whether the service offered a free option, either explicitly
+// or with a zero price, in which case no payment is requested.
+func isFree(paymentRequired *business.PaymentRequiredError) bool {
+	for _, requirement := range paymentRequired.Requirements {
+		if requirement.Free {
+			return true
+		}
+		price := strings.TrimSpace(requirement.Price)
+		if _, amount, ok := fiatPrice(requirement); ok {
+			price = amount
+		}
+		amount, ok := new(big.Rat).SetString(strings.TrimPrefix(price, "$"))
+		if ok && amount.Sign() == 0 {
+			return true
+		}
+	}
+	return false
+}
+
 func (o *BusinessOrchestrator) buildPaymentRequirements(
 	ctx context.Context,
+	taskID a2a.TaskID,
 	paymentRequired *business.PaymentRequiredError,
-) (*state.PaymentState, error) {
+	discounts []DiscountInfo,
+) (paymentState *state.PaymentState, err error) {
+	ctx, span := o.startSpan(ctx, SpanQuote, taskID)
+	defer func() { span.End(err) }()
 	if paymentRequired == nil || len(paymentRequired.Requirements) == 0 {
 		return nil, fmt.Errorf("at least one payment requirement is required")
 	}
+	networkConfigs, err := o.quoteNetworkConfigs(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
 
 	allRequirements := make([]x402types.PaymentRequirements, 0)
 	var resourceInfo *x402types.ResourceInfo
 
-	for _, serviceReq := range paymentRequired.Requirements {
+	for i, serviceReq := range paymentRequired.Requirements {
 		if serviceReq.Resource == "" {
 			return nil, fmt.Errorf("payment resource is required")
 		}
@@ -58,18 +88,41 @@ func (o *BusinessOrchestrator) buildPaymentRequirements(
 			return nil, fmt.Errorf("all payment options must describe the same resource")
 		}
 
-		for _, networkConfig := range o.networkConfigs {
-			reqs, err := BuildPaymentRequirements(ctx, o.merchant, networkConfig, serviceReq)
+		currency, fiatAmount, isFiat := fiatPrice(serviceReq)
+		for _, networkConfig := range networkConfigs {
+			networkConfig.PayToAddress = resourcePayTo(networkConfig, serviceReq.Resource)
+			var reqs []*x402types.PaymentRequirements
+			var err error
+			if isFiat {
+				reqs, err = buildFiatRequirements(ctx, o.merchant, o.pricing, networkConfig, serviceReq, currency, fiatAmount)
+			} else {
+				reqs, err = BuildPaymentRequirements(ctx, o.merchant, networkConfig, serviceReq)
+			}
 			if err != nil {
 				return nil, fmt.Errorf("failed to create payment requirement for network %s: %w", networkConfig.NetworkName, err)
 			}
+			if reqs, err = o.filterAllowedAssets(ctx, networkConfig, reqs); err != nil {
+				return nil, fmt.Errorf("failed to create payment requirement for network %s: %w", networkConfig.NetworkName, err)
+			}
 
 			for _, req := range reqs {
+				if i < len(discounts) && !discounts[i].empty() {
+					extra := make(map[string]interface{}, len(req.Extra)+1)
+					for key, value := range req.Extra {
+						extra[key] = value
+					}
+					extra["discount"] = discounts[i].metadata()
+					req.Extra = extra
+				}
 				allRequirements = append(allRequirements, *req)
 			}
 		}
 	}
 
+	if len(allRequirements) == 0 {
+		return nil, fmt.Errorf("no payment requirements left: every built asset is outside the allowed assets")
+	}
+
 	return &state.PaymentState{
 		Status: state.PaymentRequired,
 		Requirements: &x402types.PaymentRequired{
@@ -95,17 +148,19 @@ func (o *BusinessOrchestrator) findMatchingRequirement(paymentState *state.Payme
 		return nil, fmt.Errorf("unsupported payment payload version: %d", paymentState.Payload.X402Version)
 	}
 
+	// An accepted overpayment is verified and settled for the amount the
+	// payload signed.
+	if quoted, _, err := o.overpaidQuote(paymentState); err == nil && quoted != nil {
+		paid := *quoted
+		paid.Amount = paymentState.Payload.Accepted.Amount
+		return &paid, nil
+	}
 	matchedRequirement := o.merchant.FindMatchingRequirements(
 		paymentState.Requirements.Accepts,
 		*paymentState.Payload,
 	)
 	if matchedRequirement == nil {
-		return nil, fmt.Errorf("no matching payment requirement found for payload (accepted: scheme=%s, network=%s, amount=%s, asset=%s, payTo=%s)",
-			paymentState.Payload.Accepted.Scheme,
-			paymentState.Payload.Accepted.Network,
-			paymentState.Payload.Accepted.Amount,
-			paymentState.Payload.Accepted.Asset,
-			paymentState.Payload.Accepted.PayTo)
+		return nil, &unsupportedOptionError{accepted: paymentState.Payload.Accepted}
 	}
 
 	return matchedRequirement, nil
@@ -113,18 +168,51 @@ func (o *BusinessOrchestrator) findMatchingRequirement(paymentState *state.Payme
 
 func (o *BusinessOrchestrator) verifyPayment(
 	ctx context.Context,
+	task *a2a.Task,
 	paymentState *state.PaymentState,
 ) error {
+	if paymentState.Payload != nil {
+		if err := checkTaskBinding(paymentState.Payload.Accepted, task.ID, o.requireTaskBinding); err != nil {
+			return err
+		}
+	}
+	overpaid, _, err := o.overpaidQuote(paymentState)
+	if err != nil {
+		return err
+	}
 	matchedRequirement, err := o.findMatchingRequirement(paymentState)
 	if err != nil {
 		return fmt.Errorf("failed to find matching requirement: %w", err)
 	}
+	if err := checkPayloadMatchesRequirement(paymentState.Payload.Accepted, *matchedRequirement); err != nil {
+		return err
+	}
+	issued := matchedRequirement
+	if overpaid != nil {
+		issued = overpaid
+	}
+	if err := checkIssuedRequirement(*issued, state.ExtractIssuedRequirements(task)); err != nil {
+		return err
+	}
+	if err := checkAuthorizationWindow(paymentState.Payload, o.now(), o.clockSkew); err != nil {
+		return err
+	}
 
+	verifyCtx, cancel := withFacilitatorTimeout(ctx, o.facilitatorOptions.verifyTimeout())
+	defer cancel()
+	started := o.now()
 	verifyResponse, err := o.merchant.VerifyPayment(
-		ctx,
+		verifyCtx,
 		*paymentState.Payload,
 		*matchedRequirement,
 	)
+	err = facilitatorTimeout(ctx, verifyCtx, "verify", o.facilitatorOptions.verifyTimeout(), err)
+	if o.auditLogger != nil {
+		entry := newAuditEntry(AuditOperationVerify, task.ID, started, paymentState.Payload, matchedRequirement)
+		if auditErr := o.auditVerify(ctx, entry, verifyResponse, err, o.now().Sub(started)); auditErr != nil {
+			return auditErr
+		}
+	}
 	if err != nil {
 		return fmt.Errorf("payment verification failed: %w", err)
 	}
@@ -136,9 +224,42 @@ func (o *BusinessOrchestrator) verifyPayment(
 		return fmt.Errorf("payment verification failed: %s, %s", verifyResponse.InvalidReason, verifyResponse.InvalidMessage)
 	}
 
+	paymentState.Payer = verifyResponse.Payer
 	return nil
 }
 
+// verificationErrorCode returns the x402 error code for a verifyPayment
+// failure.
+func verificationErrorCode(err error) string {
+	var windowErr *authorizationWindowError
+	var timeoutErr *facilitatorTimeoutError
+	var mismatchErr *payloadMismatchError
+	var amountErr *amountMismatchError
+	var optionErr *unsupportedOptionError
+	var tamperedErr *tamperedRequirementsError
+	var bindingErr *taskBindingError
+	var auditErr *auditError
+	switch {
+	case errors.As(err, &optionErr):
+		return x402pkg.ErrorCodeUnsupportedOption
+	case errors.As(err, &mismatchErr):
+		return x402pkg.ErrorCodePayloadMismatch
+	case errors.As(err, &amountErr):
+		return x402pkg.ErrorCodeAmountMismatch
+	case errors.As(err, &tamperedErr):
+		return x402pkg.ErrorCodeTamperedRequirements
+	case errors.As(err, &bindingErr):
+		return x402pkg.ErrorCodeTaskBindingMismatch
+	case errors.As(err, &windowErr):
+		return x402pkg.ErrorCodeExpiredPayment
+	case errors.As(err, &timeoutErr):
+		return timeoutErr.errorCode()
+	case errors.As(err, &auditErr):
+		return x402pkg.ErrorCodeInternal
+	}
+	return x402pkg.ErrorCodeInvalidSignature
+}
+
 func (o *BusinessOrchestrator) handlePaymentSubmitted(
 	ctx context.Context,
 	requestContext *a2asrv.RequestContext,
@@ -154,8 +275,30 @@ func (o *BusinessOrchestrator) handlePaymentSubmitted(
 		return updatedState, nil
 	}
 
-	if err := o.verifyPayment(ctx, paymentState); err != nil {
+	o.logPaymentSubmitted(ctx, task, paymentState.Payload)
+	o.hooks.paymentSubmitted(ctx, task, paymentState.Payload)
+	started := time.Now()
+	verifyCtx, span := o.startSpan(ctx, SpanVerify, task.ID)
+	if paymentState.Payload != nil {
+		setRequirementAttributes(span, paymentState.Payload.Accepted)
+	}
+	if err := o.verifyPayment(verifyCtx, task, paymentState); err != nil {
 		verificationErr := fmt.Errorf("payment verification failed: %w", err)
+		errorCode := verificationErrorCode(err)
+		var optionErr *unsupportedOptionError
+		errors.As(err, &optionErr)
+		o.metrics.VerificationCompleted(payloadNetwork(paymentState), errorCode, time.Since(started))
+		span.SetAttribute(AttributeErrorCode, errorCode)
+		span.End(err)
+		if optionErr != nil {
+			if rejected, rejectErr := o.rejectUnsupportedOption(ctx, requestContext, task, eventQueue, err); rejected {
+				return &state.PaymentState{Status: state.PaymentRequired}, rejectErr
+			}
+		} else if isQuoteExpired(err) {
+			if requoted, requoteErr := o.requote(ctx, requestContext, task, eventQueue, paymentState, err); requoted {
+				return &state.PaymentState{Status: state.PaymentRequired}, requoteErr
+			}
+		}
 		return o.failPayment(
 			ctx,
 			requestContext,
@@ -163,11 +306,24 @@ func (o *BusinessOrchestrator) handlePaymentSubmitted(
 			eventQueue,
 			paymentState,
 			verificationErr,
-			x402pkg.ErrorCodeInvalidSignature,
+			errorCode,
 			nil,
 		)
 	}
 
+	o.metrics.VerificationCompleted(payloadNetwork(paymentState), "", time.Since(started))
+	span.End(nil)
+	if o.payerPolicy != nil {
+		if allowed, reason := o.payerPolicy.Allow(ctx, paymentState.Payer, payloadNetwork(paymentState)); !allowed {
+			if reason == "" {
+				reason = "payer is not allowed"
+			}
+			if err := o.transitionToPayerRejected(ctx, requestContext, task, eventQueue, reason); err != nil {
+				return nil, fmt.Errorf("failed to reject payer: %w", err)
+			}
+			return &state.PaymentState{Status: state.PaymentRejected}, nil
+		}
+	}
 	paymentState.Status = state.PaymentVerified
 	if err := o.transitionToPaymentVerified(ctx, requestContext, task, eventQueue, paymentState); err != nil {
 		return nil, fmt.Errorf("failed to record payment verified state: %w", err)
@@ -177,10 +333,43 @@ func (o *BusinessOrchestrator) handlePaymentSubmitted(
 		Status:       state.PaymentVerified,
 		Requirements: paymentState.Requirements,
 		Payload:      paymentState.Payload,
+		Payer:        paymentState.Payer,
 		Receipts:     paymentState.Receipts,
 	}, nil
 }
 
+// handleDuplicateSubmission answers a payment submission for a task whose
+// payment was already verified. Resending the same payload replays the current
+// status without verifying or settling again; a different payload is refused.
+func (o *BusinessOrchestrator) handleDuplicateSubmission(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	eventQueue eventqueue.Queue,
+) (bool, error) {
+	recordedHash := state.ExtractPaymentPayloadHash(task)
+	if recordedHash == "" {
+		return false, nil
+	}
+	status, err := state.ExtractPaymentStatusFromMessage(requestContext.Message)
+	if err != nil || status != state.PaymentSubmitted {
+		return false, nil
+	}
+	payload, err := state.ExtractPaymentPayload(nil, requestContext.Message)
+	if err != nil || payload == nil {
+		return false, nil
+	}
+	hash, err := state.PaymentPayloadHash(payload)
+	if err != nil {
+		return true, err
+	}
+	if hash != recordedHash {
+		return true, fmt.Errorf("%w: task %s already has a verified payment", a2a.ErrInvalidParams, task.ID)
+	}
+
+	return true, o.writeEvent(ctx, task, eventQueue, statusEvent(requestContext, task))
+}
+
 func (o *BusinessOrchestrator) handlePaymentVerified(
 	ctx context.Context,
 	requestContext *a2asrv.RequestContext,
@@ -190,10 +379,10 @@ func (o *BusinessOrchestrator) handlePaymentVerified(
 ) (*state.PaymentState, error) {
 	matchedRequirement, err := o.findMatchingRequirement(paymentState)
 	if err != nil {
-		return o.failPayment(ctx, requestContext, task, eventQueue, paymentState, err, x402pkg.ErrorCodeInvalidSignature, nil)
+		return o.failPayment(ctx, requestContext, task, eventQueue, paymentState, err, x402pkg.ErrorCodePayloadMismatch, nil)
 	}
 
-	prompt := state.ExtractOriginalPrompt(task)
+	prompt := o.originalPrompt(ctx, task)
 	if prompt == "" {
 		return o.failPayment(
 			ctx,
@@ -201,16 +390,42 @@ func (o *BusinessOrchestrator) handlePaymentVerified(
 			task,
 			eventQueue,
 			paymentState,
-			fmt.Errorf("prompt is required: original prompt not found in task metadata"),
-			x402pkg.ErrorCodeSettlementFailed,
+			fmt.Errorf("prompt is required: original prompt not found for task"),
+			x402pkg.ErrorCodeInternal,
 			nil,
 		)
 	}
 
-	businessResult, err := o.businessService.Execute(ctx, business.Request{
-		Prompt:          prompt,
-		PaymentVerified: true,
-	})
+	request := paidRequest(requestContext, task, paymentState, matchedRequirement, prompt)
+
+	if o.deferredExecution != nil {
+		if next, deferred, err := o.deferExecution(ctx, requestContext, task, eventQueue, paymentState, matchedRequirement, request); deferred {
+			return next, err
+		}
+	}
+	// The slot is claimed before settlement so a payment that never gets
+	// one is not taken.
+	release, err := o.acquireExecutionSlot(ctx)
+	if errors.Is(err, errCapacityExceeded) {
+		return o.failPayment(ctx, requestContext, task, eventQueue, paymentState,
+			fmt.Errorf("%w; no payment was taken", err), x402pkg.ErrorCodeCapacityExceeded, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	if o.settlementPolicy == SettleThenExecute {
+		return o.settleThenExecute(ctx, requestContext, task, eventQueue, paymentState, matchedRequirement, request)
+	}
+
+	if err := o.transitionToExecuting(ctx, requestContext, task, eventQueue); err != nil {
+		return nil, fmt.Errorf("failed to write executing event: %w", err)
+	}
+	businessResult, err := o.runPaidRequest(ctx, requestContext, eventQueue, request)
+	release()
+	if o.settlementAbandoned(ctx, task) {
+		return o.voidAuthorization(ctx, requestContext, task, eventQueue, paymentState)
+	}
 	if err != nil {
 		return o.failPayment(
 			ctx,
@@ -218,25 +433,118 @@ func (o *BusinessOrchestrator) handlePaymentVerified(
 			task,
 			eventQueue,
 			paymentState,
-			fmt.Errorf("business logic execution failed: %w", err),
-			x402pkg.ErrorCodeSettlementFailed,
+			err,
+			businessErrorCode(err),
 			nil,
 		)
 	}
-	if businessResult == nil {
-		return o.failPayment(
+
+	matchedRequirement, meter, err := o.meteredRequirement(matchedRequirement, businessResult)
+	if err != nil {
+		code := x402pkg.ErrorCodeInvalidAmount
+		var exceeded *meteringExceededError
+		if errors.As(err, &exceeded) {
+			code = x402pkg.ErrorCodeMeteredAmountExceeded
+		}
+		return o.failPayment(ctx, requestContext, task, eventQueue, paymentState, err, code, nil)
+	}
+
+	if o.escrow != nil && businessResult.AdditionalPaymentRequired == nil && o.escrow.holds(ctx, request) {
+		if next, held, err := o.holdDelivery(ctx, requestContext, task, eventQueue, paymentState, matchedRequirement, businessResult); held {
+			return next, err
+		}
+	}
+
+	if o.settlement.Mode == SettlementExternal {
+		return o.completeExternally(ctx, requestContext, task, eventQueue, paymentState, matchedRequirement, businessResult, meter)
+	}
+
+	// A result asking for another payment keeps the task open, so it cannot
+	// complete ahead of settlement.
+	if (o.asyncSettlement != nil || o.batchSettlement != nil) && businessResult.AdditionalPaymentRequired == nil {
+		queued, err := o.completeBeforeSettlement(ctx, requestContext, task, eventQueue, paymentState, matchedRequirement, businessResult, meter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to complete task before settlement: %w", err)
+		}
+		if queued {
+			return &state.PaymentState{Status: state.PaymentVerified}, nil
+		}
+	}
+
+	if err := o.markSettling(ctx, task, paymentState); err != nil {
+		return o.failPayment(ctx, requestContext, task, eventQueue, paymentState, err, x402pkg.ErrorCodeInternal, nil)
+	}
+	settleResponse, err := o.settleWithRetry(ctx, requestContext, eventQueue, paymentState, matchedRequirement)
+	if err != nil {
+		// The work has already been done, so the merchant gets a chance to
+		// take it back before the task fails.
+		code := settlementErrorCode(settleResponse, err)
+		compensation := o.compensate(ctx, task, paymentState, code, err)
+		return o.failPaymentWithCompensation(
 			ctx,
 			requestContext,
 			task,
 			eventQueue,
 			paymentState,
-			fmt.Errorf("business logic execution failed: empty result"),
-			x402pkg.ErrorCodeSettlementFailed,
-			nil,
+			err,
+			code,
+			settleResponse,
+			compensation,
 		)
 	}
+	meter.attach(settleResponse)
+	o.recordTip(settleResponse, paymentState)
+	if next, failed, err := o.confirmSettlement(ctx, requestContext, task, eventQueue, paymentState, settleResponse); failed {
+		return next, err
+	}
+	o.logSettled(ctx, task, settleResponse)
+	o.hooks.settled(ctx, task, settleResponse)
+	o.recordReceipt(ctx, task, paymentState.Payer, matchedRequirement, settleResponse)
+
+	return o.paidResult(ctx, requestContext, task, eventQueue, businessResult, settleResponse)
+}
+
+// paidRequest is the business request for the task's verified payment.
+func paidRequest(
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	paymentState *state.PaymentState,
+	matchedRequirement *x402types.PaymentRequirements,
+	prompt string,
+) business.Request {
+	return business.Request{
+		Prompt:          prompt,
+		PaymentVerified: true,
+		SkillID:         state.ExtractSkillID(task),
+		TaskID:          task.ID,
+		ContextID:       task.ContextID,
+		Message:         originalMessage(task, requestContext.Message),
+		Metadata:        requestMetadata(task, requestContext.Message),
+		Payer:           paymentState.Payer,
+		Requirements:    matchedRequirement,
+		Round:           state.ExtractPaymentRound(task),
+	}
+}
 
-	settleResponse, err := o.settlePayment(ctx, paymentState, matchedRequirement)
+// settleThenExecute collects payment before running the business logic. If
+// execution then fails, the task fails with the successful receipt attached so
+// the client can see it was charged.
+func (o *BusinessOrchestrator) settleThenExecute(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	eventQueue eventqueue.Queue,
+	paymentState *state.PaymentState,
+	matchedRequirement *x402types.PaymentRequirements,
+	request business.Request,
+) (*state.PaymentState, error) {
+	if o.settlementAbandoned(ctx, task) {
+		return o.voidAuthorization(ctx, requestContext, task, eventQueue, paymentState)
+	}
+	if err := o.markSettling(ctx, task, paymentState); err != nil {
+		return o.failPayment(ctx, requestContext, task, eventQueue, paymentState, err, x402pkg.ErrorCodeInternal, nil)
+	}
+	settleResponse, err := o.settleWithRetry(ctx, requestContext, eventQueue, paymentState, matchedRequirement)
 	if err != nil {
 		return o.failPayment(
 			ctx,
@@ -249,25 +557,126 @@ func (o *BusinessOrchestrator) handlePaymentVerified(
 			settleResponse,
 		)
 	}
+	if next, failed, err := o.confirmSettlement(ctx, requestContext, task, eventQueue, paymentState, settleResponse); failed {
+		return next, err
+	}
+	o.logSettled(ctx, task, settleResponse)
+	o.hooks.settled(ctx, task, settleResponse)
+	o.recordReceipt(ctx, task, paymentState.Payer, matchedRequirement, settleResponse)
+
+	settled := a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{Text: "Payment settled"})
+	state.SetPaymentStatus(settled, state.PaymentVerified)
+	if err := state.SetPaymentReceipts(settled, []*x402core.SettleResponse{settleResponse}); err != nil {
+		return nil, fmt.Errorf("failed to record settlement receipt: %w", err)
+	}
+	if err := o.writeEvent(ctx, task, eventQueue, a2a.NewStatusUpdateEvent(requestContext, a2a.TaskStateWorking, settled)); err != nil {
+		return nil, fmt.Errorf("failed to write settlement event: %w", err)
+	}
+
+	businessResult, err := o.runPaidRequest(ctx, requestContext, eventQueue, request)
+	if err != nil {
+		return o.failPayment(
+			ctx,
+			requestContext,
+			task,
+			eventQueue,
+			paymentState,
+			err,
+			businessErrorCode(err),
+			settleResponse,
+		)
+	}
 
-	return &state.PaymentState{
-		Status:    state.PaymentCompleted,
-		Message:   businessResult.Message,
-		Receipts:  []*x402core.SettleResponse{settleResponse},
-		Artifacts: businessResult.Artifacts,
-	}, nil
+	return o.paidResult(ctx, requestContext, task, eventQueue, businessResult, settleResponse)
+}
+
+// executePaidRequest runs request once it holds an execution slot, failing
+// with errCapacityExceeded if none frees up in time.
+func (o *BusinessOrchestrator) executePaidRequest(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	eventQueue eventqueue.Queue,
+	request business.Request,
+) (*business.Result, error) {
+	release, err := o.acquireExecutionSlot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return o.runPaidRequest(ctx, requestContext, eventQueue, request)
+}
+
+// runPaidRequest runs request on the business service. The caller holds the
+// execution slot, or runs it on the deferred worker pool.
+func (o *BusinessOrchestrator) runPaidRequest(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	eventQueue eventqueue.Queue,
+	request business.Request,
+) (*business.Result, error) {
+	started := time.Now()
+	ctx, span := o.startSpan(ctx, SpanBusinessExecute, request.TaskID)
+	done := o.trackExecution()
+	businessResult, err := runWithBusinessTimeout(ctx, o.businessTimeout(request), func(ctx context.Context) (*business.Result, error) {
+		if streaming, ok := o.businessService.(business.StreamingBusinessService); ok {
+			emitter := o.newProgressEmitter(ctx, requestContext, eventQueue, request)
+			defer emitter.close()
+			return streaming.ExecuteStreaming(ctx, request, emitter.emit)
+		}
+		return o.businessService.Execute(ctx, request)
+	})
+	done()
+	o.metrics.BusinessExecuted(time.Since(started), err)
+	span.End(err)
+	if err != nil {
+		return nil, fmt.Errorf("business logic execution failed: %w", err)
+	}
+	if businessResult == nil {
+		return nil, fmt.Errorf("business logic execution failed: empty result")
+	}
+	return businessResult, nil
+}
+
+// originalMessage returns the user message that started the task. Paid
+// executions run on the payment submission, so the task history is consulted
+// first.
+func originalMessage(task *a2a.Task, fallback *a2a.Message) *a2a.Message {
+	for _, message := range task.History {
+		if message != nil && message.Role == a2a.MessageRoleUser {
+			return message
+		}
+	}
+	return fallback
+}
+
+// requestMetadata returns the client metadata kept with task, falling back to
+// the metadata on the task's original message.
+func requestMetadata(task *a2a.Task, fallback *a2a.Message) map[string]interface{} {
+	if metadata := state.ExtractRequestMetadata(task); metadata != nil {
+		return metadata
+	}
+	return state.RequestMetadata(originalMessage(task, fallback))
 }
 
 func (o *BusinessOrchestrator) settlePayment(
 	ctx context.Context,
+	taskID a2a.TaskID,
 	paymentState *state.PaymentState,
 	matchedRequirement *x402types.PaymentRequirements,
 ) (*x402core.SettleResponse, error) {
+	settleCtx, cancel := withFacilitatorTimeout(ctx, o.facilitatorOptions.settleTimeout())
+	defer cancel()
+	started := o.now()
 	settleResponse, err := o.merchant.SettlePayment(
-		ctx,
+		settleCtx,
 		*paymentState.Payload,
 		*matchedRequirement,
 	)
+	err = facilitatorTimeout(ctx, settleCtx, "settle", o.facilitatorOptions.settleTimeout(), err)
+	if o.auditLogger != nil {
+		entry := newAuditEntry(AuditOperationSettle, taskID, started, paymentState.Payload, matchedRequirement)
+		o.auditSettle(ctx, entry, paymentState.Payer, settleResponse, err, o.now().Sub(started))
+	}
 	if err != nil {
 		return settleResponse, fmt.Errorf("payment settlement failed: %w", err)
 	}
@@ -282,6 +691,31 @@ func (o *BusinessOrchestrator) settlePayment(
 	return settleResponse, nil
 }
 
+// settlementAbandoned reports whether the task was canceled, through Cancel or
+// the request context, while its payment is verified but not yet settled.
+// The check runs before settlement starts; a settlement already in flight is
+// left to finish and a later Cancel replays its outcome.
+func (o *BusinessOrchestrator) settlementAbandoned(ctx context.Context, task *a2a.Task) bool {
+	return ctx.Err() != nil || o.taskLocks.cancelRequested(task.ID)
+}
+
+// voidAuthorization cancels the task without settling its verified payment.
+// The request context may already be done, so the terminal event is written
+// without its cancellation.
+func (o *BusinessOrchestrator) voidAuthorization(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	eventQueue eventqueue.Queue,
+	paymentState *state.PaymentState,
+) (*state.PaymentState, error) {
+	ctx = context.WithoutCancel(ctx)
+	if err := o.transitionToAuthorizationVoided(ctx, requestContext, task, eventQueue, paymentState); err != nil {
+		return nil, fmt.Errorf("failed to void payment authorization: %w", err)
+	}
+	return &state.PaymentState{Status: state.PaymentRejected}, nil
+}
+
 func (o *BusinessOrchestrator) failPayment(
 	ctx context.Context,
 	requestContext *a2asrv.RequestContext,
@@ -291,9 +725,25 @@ func (o *BusinessOrchestrator) failPayment(
 	err error,
 	errorCode string,
 	receipt *x402core.SettleResponse,
+) (*state.PaymentState, error) {
+	return o.failPaymentWithCompensation(ctx, requestContext, task, eventQueue, paymentState, err, errorCode, receipt, "")
+}
+
+// failPaymentWithCompensation fails the task like failPayment and records the
+// compensation outcome when one ran.
+func (o *BusinessOrchestrator) failPaymentWithCompensation(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	eventQueue eventqueue.Queue,
+	paymentState *state.PaymentState,
+	err error,
+	errorCode string,
+	receipt *x402core.SettleResponse,
+	compensation string,
 ) (*state.PaymentState, error) {
 	receipt = normalizeFailureReceipt(paymentState, receipt, err)
-	if transitionErr := o.transitionToFailed(ctx, requestContext, task, eventQueue, err, errorCode, receipt); transitionErr != nil {
+	if transitionErr := o.transitionToFailed(ctx, requestContext, task, eventQueue, err, errorCode, receipt, compensation); transitionErr != nil {
 		return nil, fmt.Errorf("failed to transition to failed state: %w", transitionErr)
 	}
 
@@ -314,7 +764,7 @@ func normalizeFailureReceipt(
 		receipt = &copy
 	}
 
-	if receipt.ErrorReason == "" && err != nil {
+	if !receipt.Success && receipt.ErrorReason == "" && err != nil {
 		receipt.ErrorReason = err.Error()
 	}
 	if receipt.Network == "" && paymentState != nil && paymentState.Payload != nil {
@@ -326,7 +776,18 @@ func normalizeFailureReceipt(
 	return receipt
 }
 
+func payloadNetwork(paymentState *state.PaymentState) string {
+	if paymentState == nil || paymentState.Payload == nil {
+		return ""
+	}
+	return paymentState.Payload.Accepted.Network
+}
+
 func settlementErrorCode(response *x402core.SettleResponse, err error) string {
+	var timeoutErr *facilitatorTimeoutError
+	if errors.As(err, &timeoutErr) {
+		return timeoutErr.errorCode()
+	}
 	message := ""
 	if response != nil {
 		message = response.ErrorReason + " " + response.ErrorMessage
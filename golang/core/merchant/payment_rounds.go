@@ -0,0 +1,139 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/a2aproject/a2a-go/a2asrv/eventqueue"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+)
+
+// DefaultMaxPaymentRounds bounds how many payments a single task may collect
+// when results keep asking for AdditionalPaymentRequired.
+const DefaultMaxPaymentRounds = 5
+
+// WithMaxPaymentRounds sets how many payments a task may collect. Once the
+// limit is reached, a result asking for another payment completes the task
+// instead. Values below 1 are treated as 1.
+func WithMaxPaymentRounds(rounds int) Option {
+	return func(o *BusinessOrchestrator) {
+		o.maxPaymentRounds = max(rounds, 1)
+	}
+}
+
+// paidResult turns a settled, executed round into the next payment state:
+// completion, or a new quote on the same task when the result asks for an
+// additional payment and the round limit allows it.
+func (o *BusinessOrchestrator) paidResult(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	eventQueue eventqueue.Queue,
+	businessResult *business.Result,
+	settleResponse *x402core.SettleResponse,
+) (*state.PaymentState, error) {
+	completed := &state.PaymentState{
+		Status:    state.PaymentCompleted,
+		Message:   businessResult.Message,
+		Summary:   businessResult.Summary,
+		Receipts:  []*x402core.SettleResponse{settleResponse},
+		Parts:     businessResult.Parts,
+		Artifacts: businessResult.Artifacts,
+	}
+	additional := businessResult.AdditionalPaymentRequired
+	if additional == nil {
+		return completed, nil
+	}
+
+	round := state.ExtractPaymentRound(task)
+	if round >= o.maxPaymentRounds {
+		o.logger.WarnContext(ctx, "x402 additional payment refused: round limit reached",
+			"task_id", task.ID,
+			"context_id", task.ContextID,
+			"round", round,
+		)
+		return completed, nil
+	}
+	additional, discounts := o.applyDiscounts(ctx, requestContext, task, additional)
+	if isFree(additional) {
+		return completed, nil
+	}
+	next, err := o.buildPaymentRequirements(ctx, task.ID, additional, discounts)
+	if err != nil {
+		// The client has paid for this round, so it still gets the result.
+		o.logger.WarnContext(ctx, "x402 additional payment quote failed",
+			"task_id", task.ID,
+			"context_id", task.ContextID,
+			"round", round+1,
+			"error", err,
+		)
+		return completed, nil
+	}
+
+	if err := o.transitionToNextRound(ctx, requestContext, task, eventQueue, businessResult, settleResponse, next, round+1, discounts); err != nil {
+		return nil, err
+	}
+	return &state.PaymentState{Status: state.PaymentRequired}, nil
+}
+
+// transitionToNextRound delivers a round's result and quotes the next payment
+// on the same task. Receipts from every settled round, the original prompt,
+// the skill and the request metadata carry over so the next round runs like the first.
+func (o *BusinessOrchestrator) transitionToNextRound(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	queue eventqueue.Queue,
+	businessResult *business.Result,
+	settleResponse *x402core.SettleResponse,
+	next *state.PaymentState,
+	round int,
+	discounts []DiscountInfo,
+) error {
+	if err := o.writeArtifacts(ctx, task, queue, businessArtifacts(businessResult.Message, businessResult.Parts, businessResult.Artifacts)); err != nil {
+		return err
+	}
+
+	receipts, err := state.ExtractPaymentReceipts(task)
+	if err != nil {
+		return fmt.Errorf("failed to read payment receipts: %w", err)
+	}
+	receipts = append(receipts, settleResponse)
+	originalPrompt := o.originalPrompt(ctx, task)
+	skillID := state.ExtractSkillID(task)
+	metadata := requestMetadata(task, requestContext.Message)
+
+	text := businessResult.AdditionalPaymentRequired.Message
+	if text == "" {
+		text = resultSummary(businessResult.Summary, businessResult.Message, "Additional payment required")
+	}
+	task.Status.Message = a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{Text: text})
+	if err := state.SetPaymentReceipts(task.Status.Message, receipts); err != nil {
+		return fmt.Errorf("failed to record payment receipts: %w", err)
+	}
+	state.SetPaymentRound(task.Status.Message, round)
+
+	if err := o.transitionToPaymentRequired(ctx, requestContext, task, queue, next, originalPrompt, skillID, metadata, discounts); err != nil {
+		return err
+	}
+	o.notifyWebhook(ctx, WebhookPaymentSettled, task, []*x402core.SettleResponse{settleResponse}, "", nil)
+	return nil
+}
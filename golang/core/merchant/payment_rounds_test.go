@@ -0,0 +1,166 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// upscaleService renders on the first paid round and offers an upscale for
+// a second payment.
+type upscaleService struct {
+	rounds  []int
+	prompts []string
+}
+
+func (s *upscaleService) Execute(ctx context.Context, request business.Request) (*business.Result, error) {
+	if !request.PaymentVerified {
+		return nil, business.NewPaymentRequiredError("render costs 1.00", business.ServiceRequirements{
+			Price: "1.00", Resource: "/render", Scheme: "exact", MaxTimeoutSeconds: 60,
+		})
+	}
+	s.rounds = append(s.rounds, request.Round)
+	s.prompts = append(s.prompts, request.Prompt)
+	if request.Round == 1 {
+		return &business.Result{
+			Message: "rendered",
+			AdditionalPaymentRequired: business.NewPaymentRequiredError("upscale costs 2.00", business.ServiceRequirements{
+				Price: "2.00", Resource: "/upscale", Scheme: "exact", MaxTimeoutSeconds: 60,
+			}),
+		}, nil
+	}
+	return &business.Result{Message: "upscaled"}, nil
+}
+
+// payRound pays the task's current round with a payload signed "0x<round>".
+func payRound(t *testing.T, orchestrator *BusinessOrchestrator, task *a2a.Task) {
+	t.Helper()
+	payQuotedTask(t, orchestrator, task, func(payload *x402types.PaymentPayload) {
+		payload.Payload["signature"] = fmt.Sprintf("0x%d", x402state.ExtractPaymentRound(task))
+	})
+}
+
+func TestBusinessOrchestrator_Execute_MultiplePaymentRounds(t *testing.T) {
+	policies := map[string]SettlementPolicy{
+		"execute then settle": ExecuteThenSettle,
+		"settle then execute": SettleThenExecute,
+	}
+	for name, policy := range policies {
+		t.Run(name, func(t *testing.T) {
+			var settled []x402types.PaymentRequirements
+			service := &upscaleService{}
+			orchestrator := newTestOrchestrator(
+				t,
+				&MockResourceServer{
+					BuildPaymentRequirementsFromConfigFunc: func(ctx context.Context, config x402core.ResourceConfig) ([]x402types.PaymentRequirements, error) {
+						return []x402types.PaymentRequirements{{
+							Scheme: config.Scheme, Network: string(config.Network), PayTo: config.PayTo,
+							Asset: "0x456", Amount: fmt.Sprint(config.Price), MaxTimeoutSeconds: config.MaxTimeoutSeconds,
+						}}, nil
+					},
+					SettlePaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.SettleResponse, error) {
+						settled = append(settled, requirements)
+						return &x402core.SettleResponse{Success: true, Network: x402.NetworkBaseSepolia, Transaction: fmt.Sprintf("0xtx%d", len(settled))}, nil
+					},
+				},
+				service,
+				[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+				WithSettlementPolicy(policy),
+			)
+
+			task := quoteRequest(t, orchestrator, "task-rounds", a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "a red fox"}))
+
+			payRound(t, orchestrator, task)
+			if task.Status.State != a2a.TaskStateInputRequired {
+				t.Fatalf("task state after first round = %v, want %v", task.Status.State, a2a.TaskStateInputRequired)
+			}
+			if status, _ := x402state.ExtractPaymentStatus(task); status != x402state.PaymentRequired {
+				t.Errorf("payment status after first round = %v, want %v", status, x402state.PaymentRequired)
+			}
+			if round := x402state.ExtractPaymentRound(task); round != 2 {
+				t.Errorf("round = %d, want 2", round)
+			}
+			if prompt := x402state.ExtractOriginalPrompt(task); prompt != "a red fox" {
+				t.Errorf("original prompt = %q, want %q", prompt, "a red fox")
+			}
+			requirements, _ := x402state.ExtractPaymentRequirements(task)
+			if requirements.Resource == nil || requirements.Resource.URL != "/upscale" {
+				t.Errorf("second quote resource = %+v, want /upscale", requirements.Resource)
+			}
+
+			payRound(t, orchestrator, task)
+			if task.Status.State != a2a.TaskStateCompleted {
+				t.Fatalf("task state = %v, want %v: %s", task.Status.State, a2a.TaskStateCompleted, x402state.ExtractMessageText(task.Status.Message))
+			}
+			receipts, err := x402state.ExtractPaymentReceipts(task)
+			if err != nil {
+				t.Fatalf("ExtractPaymentReceipts() error = %v", err)
+			}
+			var transactions []string
+			for _, receipt := range receipts {
+				transactions = append(transactions, receipt.Transaction)
+			}
+			if want := []string{"0xtx1", "0xtx2"}; !slices.Equal(transactions, want) {
+				t.Errorf("receipts = %v, want %v", transactions, want)
+			}
+			if !slices.Equal(service.rounds, []int{1, 2}) {
+				t.Errorf("business rounds = %v, want [1 2]", service.rounds)
+			}
+			if !slices.Equal(service.prompts, []string{"a red fox", "a red fox"}) {
+				t.Errorf("business prompts = %q", service.prompts)
+			}
+		})
+	}
+}
+
+func TestBusinessOrchestrator_Execute_PaymentRoundLimit(t *testing.T) {
+	settleCalls := 0
+	orchestrator := newTestOrchestrator(
+		t,
+		&MockResourceServer{
+			SettlePaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.SettleResponse, error) {
+				settleCalls++
+				return &x402core.SettleResponse{Success: true, Network: x402.NetworkBaseSepolia, Transaction: "0xtx"}, nil
+			},
+		},
+		&upscaleService{},
+		[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+		WithMaxPaymentRounds(1),
+	)
+
+	task := quoteRequest(t, orchestrator, "task-round-limit", a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "a red fox"}))
+	payRound(t, orchestrator, task)
+
+	if task.Status.State != a2a.TaskStateCompleted {
+		t.Fatalf("task state = %v, want %v", task.Status.State, a2a.TaskStateCompleted)
+	}
+	if text := x402state.ExtractMessageText(task.Status.Message); text != "rendered" {
+		t.Errorf("completion text = %q, want the first round's result", text)
+	}
+	if settleCalls != 1 {
+		t.Errorf("settle calls = %d, want 1", settleCalls)
+	}
+}
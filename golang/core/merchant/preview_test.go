@@ -0,0 +1,106 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+)
+
+type previewService struct {
+	mockBusinessService
+	parts  []a2a.Part
+	err    error
+	prompt string
+}
+
+func (s *previewService) Preview(ctx context.Context, prompt string) ([]a2a.Part, error) {
+	s.prompt = prompt
+	return s.parts, s.err
+}
+
+func TestBusinessOrchestrator_Preview(t *testing.T) {
+	thumbnail := a2a.FilePart{File: a2a.FileURI{URI: "https://example.com/cat-64px.png", FileMeta: a2a.FileMeta{MimeType: "image/png"}}}
+	tests := []struct {
+		name      string
+		service   *previewService
+		wantParts int
+	}{
+		{
+			name:      "preview attached to quote",
+			service:   &previewService{parts: []a2a.Part{thumbnail}},
+			wantParts: 2,
+		},
+		{
+			name:      "preview failure falls back to plain quote",
+			service:   &previewService{parts: []a2a.Part{thumbnail}, err: errors.New("thumbnailer down")},
+			wantParts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orchestrator := newTestOrchestrator(
+				t,
+				&MockResourceServer{},
+				tt.service,
+				[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+			)
+			queue := &mockEventQueue{}
+			requestContext := &a2asrv.RequestContext{
+				Message:   a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "draw a cat"}),
+				TaskID:    "task-preview",
+				ContextID: "context-preview",
+			}
+			if err := orchestrator.executeInPlace(context.Background(), requestContext, queue); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if tt.service.prompt != "draw a cat" {
+				t.Errorf("preview prompt = %q", tt.service.prompt)
+			}
+
+			var quote *a2a.Message
+			for _, event := range queue.events {
+				if update, ok := event.(*a2a.TaskStatusUpdateEvent); ok && update.Status.State == a2a.TaskStateInputRequired {
+					quote = update.Status.Message
+				}
+			}
+			if quote == nil {
+				t.Fatal("no payment-required event written")
+			}
+			if len(quote.Parts) != tt.wantParts {
+				t.Fatalf("quote parts = %#v, want %d parts", quote.Parts, tt.wantParts)
+			}
+			if tt.wantParts == 2 {
+				if _, ok := quote.Parts[1].(a2a.FilePart); !ok {
+					t.Errorf("preview part = %T, want a2a.FilePart", quote.Parts[1])
+				}
+			}
+			if _, ok := quote.Metadata[x402.MetadataKeyRequired]; !ok {
+				t.Error("x402.payment.required missing from quote")
+			}
+			if status, _ := x402state.ExtractPaymentStatusFromMessage(quote); status != x402state.PaymentRequired {
+				t.Errorf("payment status = %v, want %v", status, x402state.PaymentRequired)
+			}
+		})
+	}
+}
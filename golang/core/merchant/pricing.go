@@ -0,0 +1,148 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	evmutils "github.com/x402-foundation/x402/go/mechanisms/evm"
+	svmutils "github.com/x402-foundation/x402/go/mechanisms/svm"
+)
+
+// quoteCacheTTL bounds how long a fiat quote is reused, so a burst of requests
+// does not hit the rate source while prices stay reasonably fresh.
+const quoteCacheTTL = 30 * time.Second
+
+// fiatPricePattern matches prices written as "USD 1.50".
+var fiatPricePattern = regexp.MustCompile(`^([A-Za-z]{3})\s+(\d+(?:\.\d+)?)$`)
+
+// PricingProvider converts a fiat price into the atomic amount of an asset,
+// e.g. "1.50" USD into "1500000" for a six-decimal stablecoin.
+type PricingProvider interface {
+	Quote(ctx context.Context, fiatAmount, currency, network string, asset types.AssetConfig) (string, error)
+}
+
+// PricingProviderFunc adapts a function to the PricingProvider interface.
+type PricingProviderFunc func(ctx context.Context, fiatAmount, currency, network string, asset types.AssetConfig) (string, error)
+
+func (f PricingProviderFunc) Quote(ctx context.Context, fiatAmount, currency, network string, asset types.AssetConfig) (string, error) {
+	return f(ctx, fiatAmount, currency, network, asset)
+}
+
+// StablecoinPricingProvider prices USD one-to-one in whole tokens. It is the
+// default and is only correct for USD stablecoins such as USDC.
+type StablecoinPricingProvider struct{}
+
+func (StablecoinPricingProvider) Quote(ctx context.Context, fiatAmount, currency, network string, asset types.AssetConfig) (string, error) {
+	if !strings.EqualFold(currency, "USD") {
+		return "", fmt.Errorf("stablecoin pricing only supports USD, got %s", currency)
+	}
+	amount, err := evmutils.ParseAmount(fiatAmount, asset.Decimals)
+	if err != nil {
+		return "", err
+	}
+	return amount.String(), nil
+}
+
+// WithPricingProvider sets the provider used for fiat-denominated prices.
+// Quotes are cached briefly per amount, currency and asset.
+func WithPricingProvider(provider PricingProvider) Option {
+	return func(o *BusinessOrchestrator) {
+		o.pricing = newCachingPricingProvider(provider, func() time.Time { return o.now() })
+	}
+}
+
+type quoteKey struct {
+	fiatAmount string
+	currency   string
+	network    string
+	asset      string
+}
+
+type cachedQuote struct {
+	amount  string
+	expires time.Time
+}
+
+type cachingPricingProvider struct {
+	provider PricingProvider
+	now      func() time.Time
+
+	mu     sync.Mutex
+	quotes map[quoteKey]cachedQuote
+}
+
+func newCachingPricingProvider(provider PricingProvider, now func() time.Time) *cachingPricingProvider {
+	return &cachingPricingProvider{provider: provider, now: now, quotes: make(map[quoteKey]cachedQuote)}
+}
+
+func (c *cachingPricingProvider) Quote(ctx context.Context, fiatAmount, currency, network string, asset types.AssetConfig) (string, error) {
+	key := quoteKey{fiatAmount: fiatAmount, currency: strings.ToUpper(currency), network: network, asset: asset.Address}
+	c.mu.Lock()
+	cached, ok := c.quotes[key]
+	c.mu.Unlock()
+	if ok && c.now().Before(cached.expires) {
+		return cached.amount, nil
+	}
+
+	amount, err := c.provider.Quote(ctx, fiatAmount, currency, network, asset)
+	if err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	c.quotes[key] = cachedQuote{amount: amount, expires: c.now().Add(quoteCacheTTL)}
+	c.mu.Unlock()
+	return amount, nil
+}
+
+// fiatPrice reports the currency and amount of a fiat-denominated
+// requirement, either from Currency or from a "USD 1.50" style Price.
+func fiatPrice(params business.ServiceRequirements) (currency, amount string, ok bool) {
+	price := strings.TrimSpace(params.Price)
+	if params.Currency != "" {
+		return strings.ToUpper(params.Currency), strings.TrimPrefix(price, "$"), true
+	}
+	if match := fiatPricePattern.FindStringSubmatch(price); match != nil {
+		return strings.ToUpper(match[1]), match[2], true
+	}
+	return "", "", false
+}
+
+// pricedAssets returns the assets a fiat price is quoted in: the configured
+// ones, or the network's default asset.
+func pricedAssets(networkConfig types.NetworkConfig) ([]types.AssetConfig, error) {
+	if len(networkConfig.Assets) > 0 {
+		return networkConfig.Assets, nil
+	}
+	if strings.HasPrefix(networkConfig.NetworkName, "solana:") {
+		info, err := svmutils.GetAssetInfo(networkConfig.NetworkName, "")
+		if err != nil {
+			return nil, err
+		}
+		return []types.AssetConfig{{Address: info.Address, Decimals: info.Decimals}}, nil
+	}
+	info, err := evmutils.GetAssetInfo(networkConfig.NetworkName, "")
+	if err != nil {
+		return nil, err
+	}
+	return []types.AssetConfig{{Address: info.Address, Decimals: info.Decimals, Name: info.Name, Version: info.Version}}, nil
+}
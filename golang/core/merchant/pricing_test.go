@@ -0,0 +1,173 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+func TestBusinessOrchestrator_FiatPricing(t *testing.T) {
+	// 1 ETH = 2500 USD, quoted into an 18-decimal token.
+	ethProvider := PricingProviderFunc(func(ctx context.Context, fiatAmount, currency, network string, asset types.AssetConfig) (string, error) {
+		if asset.Address == "0xusdc" {
+			return StablecoinPricingProvider{}.Quote(ctx, fiatAmount, currency, network, asset)
+		}
+		if fiatAmount != "1.50" || currency != "USD" {
+			t.Errorf("Quote(%s, %s), want 1.50 USD", fiatAmount, currency)
+		}
+		return "600000000000000", nil
+	})
+
+	tests := []struct {
+		name        string
+		price       business.ServiceRequirements
+		provider    PricingProvider
+		wantAmounts []string
+		wantErr     string
+	}{
+		{
+			name:        "price string with currency",
+			price:       business.ServiceRequirements{Price: "USD 1.50"},
+			provider:    ethProvider,
+			wantAmounts: []string{"1500000", "600000000000000"},
+		},
+		{
+			name:        "structured currency",
+			price:       business.ServiceRequirements{Price: "1.50", Currency: "usd"},
+			provider:    ethProvider,
+			wantAmounts: []string{"1500000", "600000000000000"},
+		},
+		{
+			name:  "provider failure",
+			price: business.ServiceRequirements{Price: "USD 1.50"},
+			provider: PricingProviderFunc(func(ctx context.Context, fiatAmount, currency, network string, asset types.AssetConfig) (string, error) {
+				return "", errors.New("rate source unavailable")
+			}),
+			wantErr: "rate source unavailable",
+		},
+		{
+			name:    "default provider rejects other currencies",
+			price:   business.ServiceRequirements{Price: "EUR 1.50"},
+			wantErr: "only supports USD",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var verified, settled []x402types.PaymentRequirements
+			var opts []Option
+			if tt.provider != nil {
+				opts = append(opts, WithPricingProvider(tt.provider))
+			}
+			service := &mockBusinessService{executeFunc: func(ctx context.Context, request business.Request) (*business.Result, error) {
+				requirements := tt.price
+				requirements.Resource = "/fiat"
+				requirements.Scheme = "exact"
+				return nil, business.NewPaymentRequiredError("pay", requirements)
+			}}
+			orchestrator := newTestOrchestrator(
+				t,
+				assetAwareResourceServer(&verified, &settled),
+				service,
+				[]types.NetworkConfig{{
+					NetworkName:  x402.NetworkBase,
+					PayToAddress: "0x123",
+					Assets: []types.AssetConfig{
+						{Address: "0xusdc", Decimals: 6},
+						{Address: "0xweth", Decimals: 18},
+					},
+				}},
+				opts...,
+			)
+
+			requestContext := &a2asrv.RequestContext{
+				Message:   a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "buy"}),
+				TaskID:    "task-fiat",
+				ContextID: "context-fiat",
+			}
+			if err := orchestrator.executeInPlace(context.Background(), requestContext, &mockEventQueue{}); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			task := requestContext.StoredTask
+
+			if tt.wantErr != "" {
+				if task.Status.State != a2a.TaskStateFailed {
+					t.Fatalf("task state = %v, want failed", task.Status.State)
+				}
+				if text := x402state.ExtractMessageText(task.Status.Message); !strings.Contains(text, tt.wantErr) {
+					t.Errorf("failure message = %q, want it to mention %q", text, tt.wantErr)
+				}
+				return
+			}
+
+			requirements, err := x402state.ExtractPaymentRequirements(task)
+			if err != nil {
+				t.Fatalf("ExtractPaymentRequirements() error = %v", err)
+			}
+			if len(requirements.Accepts) != len(tt.wantAmounts) {
+				t.Fatalf("accepts = %d, want %d", len(requirements.Accepts), len(tt.wantAmounts))
+			}
+			for i, accepted := range requirements.Accepts {
+				if accepted.Amount != tt.wantAmounts[i] {
+					t.Errorf("accept %d amount = %s, want %s", i, accepted.Amount, tt.wantAmounts[i])
+				}
+				quote, _ := accepted.Extra["fiatQuote"].(map[string]interface{})
+				if quote["currency"] != "USD" || quote["fiatAmount"] != "1.50" || quote["amount"] != tt.wantAmounts[i] {
+					t.Errorf("accept %d fiat quote = %#v", i, quote)
+				}
+			}
+		})
+	}
+}
+
+func TestCachingPricingProvider(t *testing.T) {
+	calls := 0
+	provider := PricingProviderFunc(func(ctx context.Context, fiatAmount, currency, network string, asset types.AssetConfig) (string, error) {
+		calls++
+		return "100", nil
+	})
+	now := time.Unix(1700000000, 0)
+	cache := newCachingPricingProvider(provider, func() time.Time { return now })
+	asset := types.AssetConfig{Address: "0xusdc", Decimals: 6}
+
+	for range 3 {
+		if _, err := cache.Quote(context.Background(), "1", "USD", x402.NetworkBase, asset); err != nil {
+			t.Fatalf("Quote() error = %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("provider calls = %d, want 1 within the cache TTL", calls)
+	}
+
+	now = now.Add(quoteCacheTTL)
+	if _, err := cache.Quote(context.Background(), "1", "USD", x402.NetworkBase, asset); err != nil {
+		t.Fatalf("Quote() error = %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("provider calls = %d, want a fresh quote after the TTL", calls)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"sync"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/a2aproject/a2a-go/a2asrv/eventqueue"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+)
+
+// progressEmitter turns business progress into working status updates. Once
+// closed it drops further emissions, so a service goroutine that outlives
+// ExecuteStreaming cannot write after the terminal event.
+type progressEmitter struct {
+	ctx            context.Context
+	orchestrator   *BusinessOrchestrator
+	requestContext *a2asrv.RequestContext
+	queue          eventqueue.Queue
+	status         state.PaymentStatus
+
+	mu     sync.Mutex
+	closed bool
+}
+
+func (o *BusinessOrchestrator) newProgressEmitter(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	queue eventqueue.Queue,
+	request business.Request,
+) *progressEmitter {
+	status := state.PaymentVerified
+	if request.Free {
+		status = state.PaymentNotRequired
+	}
+	return &progressEmitter{
+		ctx:            ctx,
+		orchestrator:   o,
+		requestContext: requestContext,
+		queue:          queue,
+		status:         status,
+	}
+}
+
+func (e *progressEmitter) emit(progress business.Progress) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed || e.queue == nil || e.ctx.Err() != nil {
+		return
+	}
+
+	percent := min(max(progress.Percent, 0), 100)
+	message := a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{Text: progress.Message})
+	state.SetPaymentStatus(message, e.status)
+	message.Metadata[x402pkg.MetadataKeyProgress] = percent
+
+	event := a2a.NewStatusUpdateEvent(e.requestContext, a2a.TaskStateWorking, message)
+	if err := e.queue.Write(e.ctx, event); err != nil {
+		e.orchestrator.logger.WarnContext(e.ctx, "x402 progress update dropped",
+			"task_id", e.requestContext.TaskID,
+			"error", err,
+		)
+	}
+}
+
+func (e *progressEmitter) close() {
+	e.mu.Lock()
+	e.closed = true
+	e.mu.Unlock()
+}
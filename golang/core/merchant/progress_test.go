@@ -0,0 +1,117 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+)
+
+// streamingService reports two progress steps during the paid execution and
+// keeps its emitter so the test can call it after the task has finished.
+type streamingService struct {
+	mockBusinessService
+	err  error
+	emit business.ProgressEmitter
+}
+
+func (s *streamingService) ExecuteStreaming(ctx context.Context, request business.Request, emit business.ProgressEmitter) (*business.Result, error) {
+	s.emit = emit
+	emit(business.Progress{Percent: 10, Message: "rendering"})
+	emit(business.Progress{Percent: 90, Message: "upscaling"})
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &business.Result{Message: "image ready"}, nil
+}
+
+func TestBusinessOrchestrator_StreamingProgress(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want []string
+	}{
+		{
+			name: "completes after progress",
+			want: []string{"working 10%", "working 90%", "completed"},
+		},
+		{
+			name: "fails after progress",
+			err:  errors.New("gpu lost"),
+			want: []string{"working 10%", "working 90%", "failed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &streamingService{err: tt.err}
+			orchestrator := newTestOrchestrator(
+				t,
+				&MockResourceServer{},
+				service,
+				[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+			)
+
+			requestContext := &a2asrv.RequestContext{
+				Message:   a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "draw a cat"}),
+				TaskID:    "task-stream",
+				ContextID: "context-stream",
+			}
+			if err := orchestrator.executeInPlace(context.Background(), requestContext, &mockEventQueue{}); err != nil {
+				t.Fatalf("initial Execute() error = %v", err)
+			}
+			if service.emit != nil {
+				t.Fatal("ExecuteStreaming called before payment")
+			}
+
+			task := requestContext.StoredTask
+			submission := paymentSubmission(t, task)
+			queue := &mockEventQueue{}
+			if err := submitPayment(orchestrator, task, submission, queue); err != nil {
+				t.Fatalf("paid Execute() error = %v", err)
+			}
+
+			// A late emission from a lingering goroutine must be dropped.
+			service.emit(business.Progress{Percent: 100, Message: "too late"})
+
+			var got []string
+			for _, event := range queue.events {
+				update, ok := event.(*a2a.TaskStatusUpdateEvent)
+				if !ok {
+					continue
+				}
+				if percent, ok := update.Status.Message.Metadata[x402.MetadataKeyProgress]; ok {
+					got = append(got, fmt.Sprintf("working %v%%", percent))
+					continue
+				}
+				if update.Status.State.Terminal() {
+					got = append(got, string(update.Status.State))
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("events = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
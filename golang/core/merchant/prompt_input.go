@@ -0,0 +1,119 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+)
+
+// DefaultPromptPointer locates the prompt in a DataPart such as
+// {"prompt": "...", "size": "1024"}.
+const DefaultPromptPointer = "/prompt"
+
+// WithPromptPointer sets the JSON pointer (RFC 6901) used to read the prompt
+// from a DataPart when the message has no text. An empty pointer selects the
+// whole DataPart, which is then used as JSON.
+func WithPromptPointer(pointer string) Option {
+	return func(o *BusinessOrchestrator) {
+		o.promptPointer = pointer
+	}
+}
+
+// extractPrompt returns the canonical input of message: its text, else the
+// value at the prompt pointer of the first DataPart that has one, else what
+// the business service extracts. An empty prompt means none was found.
+func (o *BusinessOrchestrator) extractPrompt(ctx context.Context, message *a2a.Message) (string, error) {
+	if text := state.ExtractMessageText(message); text != "" {
+		return text, nil
+	}
+	if message != nil {
+		for _, part := range message.Parts {
+			data, ok := part.(a2a.DataPart)
+			if !ok {
+				continue
+			}
+			value, found := resolvePointer(data.Data, o.promptPointer)
+			if !found {
+				continue
+			}
+			if prompt := promptValue(value); prompt != "" {
+				return prompt, nil
+			}
+		}
+	}
+	if extractor, ok := o.businessService.(business.PromptExtractorBusinessService); ok && message != nil {
+		prompt, err := extractor.ExtractPrompt(ctx, message)
+		if err != nil {
+			return "", fmt.Errorf("failed to extract prompt: %w", err)
+		}
+		return prompt, nil
+	}
+	return "", nil
+}
+
+// promptValue renders a DataPart value as a prompt. Strings are used as is
+// and anything else as JSON.
+func promptValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
+
+// resolvePointer evaluates an RFC 6901 JSON pointer against document.
+func resolvePointer(document map[string]any, pointer string) (any, bool) {
+	if pointer == "" {
+		return document, true
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, false
+	}
+	var current any = document
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch node := current.(type) {
+		case map[string]any:
+			value, ok := node[token]
+			if !ok {
+				return nil, false
+			}
+			current = value
+		case []any:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, false
+			}
+			current = node[index]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
@@ -0,0 +1,178 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+)
+
+// fileNameExtractor reads the prompt from the name of an attached file.
+type fileNameExtractor struct {
+	*mockBusinessService
+}
+
+func (fileNameExtractor) ExtractPrompt(ctx context.Context, message *a2a.Message) (string, error) {
+	for _, part := range message.Parts {
+		if file, ok := part.(a2a.FilePart); ok {
+			if uri, ok := file.File.(a2a.FileURI); ok {
+				return "describe " + uri.Name, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+func TestBusinessOrchestrator_Execute_ExtractsPrompt(t *testing.T) {
+	file := a2a.FilePart{File: a2a.FileURI{FileMeta: a2a.FileMeta{Name: "scan.png"}, URI: "https://example.com/scan.png"}}
+
+	tests := []struct {
+		name       string
+		parts      []a2a.Part
+		options    []Option
+		extractor  bool
+		wantPrompt string
+		wantState  a2a.TaskState
+	}{
+		{
+			name:       "text only",
+			parts:      []a2a.Part{a2a.TextPart{Text: "a red fox"}},
+			wantPrompt: "a red fox",
+			wantState:  a2a.TaskStateInputRequired,
+		},
+		{
+			name:       "data only",
+			parts:      []a2a.Part{a2a.DataPart{Data: map[string]any{"prompt": "a red fox", "size": "1024"}}},
+			wantPrompt: "a red fox",
+			wantState:  a2a.TaskStateInputRequired,
+		},
+		{
+			name: "mixed prefers text",
+			parts: []a2a.Part{
+				a2a.DataPart{Data: map[string]any{"prompt": "from data"}},
+				a2a.TextPart{Text: "from text"},
+			},
+			wantPrompt: "from text",
+			wantState:  a2a.TaskStateInputRequired,
+		},
+		{
+			name:       "configured pointer",
+			parts:      []a2a.Part{a2a.DataPart{Data: map[string]any{"job": map[string]any{"steps": []any{"render a red fox"}}}}},
+			options:    []Option{WithPromptPointer("/job/steps/0")},
+			wantPrompt: "render a red fox",
+			wantState:  a2a.TaskStateInputRequired,
+		},
+		{
+			name:       "file through the service extractor",
+			parts:      []a2a.Part{file},
+			extractor:  true,
+			wantPrompt: "describe scan.png",
+			wantState:  a2a.TaskStateInputRequired,
+		},
+		{
+			name:      "genuinely empty",
+			parts:     []a2a.Part{a2a.DataPart{Data: map[string]any{"size": "1024"}}},
+			wantState: a2a.TaskStateFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var quotedPrompt string
+			service := &mockBusinessService{}
+			service.executeFunc = func(ctx context.Context, request business.Request) (*business.Result, error) {
+				quotedPrompt = request.Prompt
+				return nil, business.NewPaymentRequiredError("pay", business.ServiceRequirements{Price: "1.00", Resource: "/test", Scheme: "exact"})
+			}
+			var businessService business.BusinessService = service
+			if tt.extractor {
+				businessService = fileNameExtractor{mockBusinessService: service}
+			}
+			orchestrator := newTestOrchestrator(
+				t,
+				&MockResourceServer{},
+				businessService,
+				[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+				tt.options...,
+			)
+
+			requestContext := &a2asrv.RequestContext{
+				Message:   a2a.NewMessage(a2a.MessageRoleUser, tt.parts...),
+				TaskID:    "task-input",
+				ContextID: "context-input",
+			}
+			if err := orchestrator.executeInPlace(context.Background(), requestContext, &mockEventQueue{}); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			task := requestContext.StoredTask
+
+			if task.Status.State != tt.wantState {
+				t.Fatalf("task state = %v, want %v", task.Status.State, tt.wantState)
+			}
+			if quotedPrompt != tt.wantPrompt {
+				t.Errorf("quoted prompt = %q, want %q", quotedPrompt, tt.wantPrompt)
+			}
+			if got := x402state.ExtractOriginalPrompt(task); got != tt.wantPrompt {
+				t.Errorf("original prompt = %q, want %q", got, tt.wantPrompt)
+			}
+			if tt.wantState == a2a.TaskStateFailed {
+				if got := task.Status.Message.Metadata[x402.MetadataKeyError]; got != x402.ErrorCodeInvalidRequest {
+					t.Errorf("error code = %v, want %s", got, x402.ErrorCodeInvalidRequest)
+				}
+				if requirements, _ := x402state.ExtractPaymentRequirements(task); requirements != nil {
+					t.Error("a request without a prompt was quoted")
+				}
+			}
+		})
+	}
+}
+
+func TestResolvePointer(t *testing.T) {
+	document := map[string]any{
+		"prompt": "fox",
+		"a/b":    "slash",
+		"m~n":    "tilde",
+		"list":   []any{"zero", map[string]any{"k": 1.0}},
+	}
+	tests := []struct {
+		pointer string
+		want    any
+		found   bool
+	}{
+		{pointer: "/prompt", want: "fox", found: true},
+		{pointer: "/a~1b", want: "slash", found: true},
+		{pointer: "/m~0n", want: "tilde", found: true},
+		{pointer: "/list/1/k", want: 1.0, found: true},
+		{pointer: "/list/2"},
+		{pointer: "/missing"},
+		{pointer: "prompt"},
+	}
+	for _, tt := range tests {
+		got, found := resolvePointer(document, tt.pointer)
+		if found != tt.found || (found && got != tt.want) {
+			t.Errorf("resolvePointer(%q) = %v, %v; want %v, %v", tt.pointer, got, found, tt.want, tt.found)
+		}
+	}
+	if got, _ := resolvePointer(document, ""); promptValue(got) == "" {
+		t.Error("empty pointer should select the whole document")
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"crypto/rand"
+	"sync"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+)
+
+// PromptRetention controls how much of the user's original prompt is written
+// into task metadata, which reaches every status event and any task store.
+//
+// Under PromptHashed and PromptNone the prompt for paid execution comes from
+// an in-memory per-task cache, falling back to the task's message history.
+// The cache does not survive a restart, so a merchant that restarts while a
+// quote is outstanding can only recover the prompt from history; a task store
+// that drops history loses it, and the paid execution fails.
+type PromptRetention int
+
+const (
+	// PromptPlaintext stores the prompt text. It is the default.
+	PromptPlaintext PromptRetention = iota
+	// PromptHashed stores a salted SHA-256 digest and the prompt length.
+	PromptHashed
+	// PromptNone stores nothing about the prompt.
+	PromptNone
+)
+
+// WithPromptRetention selects how the original prompt is retained.
+func WithPromptRetention(retention PromptRetention) Option {
+	return func(o *BusinessOrchestrator) {
+		o.promptRetention = retention
+	}
+}
+
+// promptCache holds prompts that are not retained in task metadata until
+// their task reaches a terminal state.
+type promptCache struct {
+	mu      sync.Mutex
+	prompts map[a2a.TaskID]string
+}
+
+func (c *promptCache) store(taskID a2a.TaskID, prompt string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.prompts == nil {
+		c.prompts = make(map[a2a.TaskID]string)
+	}
+	c.prompts[taskID] = prompt
+}
+
+func (c *promptCache) load(taskID a2a.TaskID) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	prompt, ok := c.prompts[taskID]
+	return prompt, ok
+}
+
+func (c *promptCache) forget(taskID a2a.TaskID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.prompts, taskID)
+}
+
+// retainPrompt records prompt on the task's status message according to the
+// configured retention.
+func (o *BusinessOrchestrator) retainPrompt(task *a2a.Task, prompt string) {
+	if prompt == "" {
+		return
+	}
+	switch o.promptRetention {
+	case PromptHashed:
+		salt := make([]byte, 16)
+		_, _ = rand.Read(salt)
+		state.SetOriginalPromptDigest(task.Status.Message, prompt, salt)
+	case PromptNone:
+	default:
+		state.SetOriginalPrompt(task.Status.Message, prompt)
+		return
+	}
+	o.prompts.store(task.ID, prompt)
+}
+
+// originalPrompt returns the prompt that started task, wherever it was kept.
+func (o *BusinessOrchestrator) originalPrompt(ctx context.Context, task *a2a.Task) string {
+	if prompt := state.ExtractOriginalPrompt(task); prompt != "" {
+		return prompt
+	}
+	if prompt, ok := o.prompts.load(task.ID); ok {
+		return prompt
+	}
+	if message := originalMessage(task, nil); message != nil {
+		prompt, _ := o.extractPrompt(ctx, message)
+		return prompt
+	}
+	return ""
+}
@@ -0,0 +1,123 @@
+package merchant
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+)
+
+func TestBusinessOrchestrator_PromptRetention(t *testing.T) {
+	const prompt = "diagnose my rash"
+
+	tests := []struct {
+		name      string
+		retention PromptRetention
+		// restart pays on a fresh orchestrator whose cache is empty.
+		restart     bool
+		dropHistory bool
+		wantPrompt  bool
+		wantDigest  bool
+		wantState   a2a.TaskState
+	}{
+		{name: "plaintext", retention: PromptPlaintext, wantPrompt: true, wantState: a2a.TaskStateCompleted},
+		{name: "hashed", retention: PromptHashed, wantDigest: true, wantState: a2a.TaskStateCompleted},
+		{name: "none", retention: PromptNone, wantState: a2a.TaskStateCompleted},
+		{name: "none after restart reads history", retention: PromptNone, restart: true, wantState: a2a.TaskStateCompleted},
+		{name: "none after restart without history", retention: PromptNone, restart: true, dropHistory: true, wantState: a2a.TaskStateFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var paidPrompt string
+			newOrchestrator := func() *BusinessOrchestrator {
+				return newTestOrchestrator(
+					t,
+					&MockResourceServer{},
+					&mockBusinessService{executeFunc: func(ctx context.Context, request business.Request) (*business.Result, error) {
+						if request.PaymentVerified {
+							paidPrompt = request.Prompt
+							return &business.Result{Message: "done"}, nil
+						}
+						return nil, business.NewPaymentRequiredError("pay", business.ServiceRequirements{Price: "1.00", Resource: "/test", Scheme: "exact"})
+					}},
+					[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+					WithPromptRetention(tt.retention),
+				)
+			}
+			orchestrator := newOrchestrator()
+
+			queue := &mockEventQueue{}
+			requestContext := &a2asrv.RequestContext{
+				Message:   a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: prompt}),
+				TaskID:    "task-prompt",
+				ContextID: "context-prompt",
+			}
+			if err := orchestrator.executeInPlace(context.Background(), requestContext, queue); err != nil {
+				t.Fatalf("initial Execute() error = %v", err)
+			}
+			task := requestContext.StoredTask
+
+			for _, event := range queue.events {
+				update, ok := event.(*a2a.TaskStatusUpdateEvent)
+				if !ok || update.Status.Message == nil {
+					continue
+				}
+				for key, value := range update.Status.Message.Metadata {
+					if text, ok := value.(string); ok && strings.Contains(text, prompt) && !tt.wantPrompt {
+						t.Errorf("status event metadata %s carries the prompt", key)
+					}
+				}
+			}
+			if got := x402state.ExtractOriginalPrompt(task); (got == prompt) != tt.wantPrompt {
+				t.Errorf("stored prompt = %q, want retained = %v", got, tt.wantPrompt)
+			}
+			digest, length := x402state.ExtractOriginalPromptDigest(task)
+			if tt.wantDigest {
+				salt, _, _ := strings.Cut(digest, ":")
+				if salt == "" || !strings.HasPrefix(x402state.PromptDigest(prompt, mustDecodeHex(t, salt)), digest) || length != len(prompt) {
+					t.Errorf("digest = %q, length = %d; want a salted digest of the prompt", digest, length)
+				}
+			} else if digest != "" || length != 0 {
+				t.Errorf("digest = %q, length = %d; want none", digest, length)
+			}
+
+			if tt.restart {
+				orchestrator = newOrchestrator()
+			}
+			if tt.dropHistory {
+				task.History = nil
+			}
+			submission := paymentSubmission(t, task)
+			if err := submitPayment(orchestrator, task, submission, &mockEventQueue{}); err != nil {
+				t.Fatalf("paid Execute() error = %v", err)
+			}
+
+			if task.Status.State != tt.wantState {
+				t.Fatalf("task state = %v, want %v", task.Status.State, tt.wantState)
+			}
+			if tt.wantState == a2a.TaskStateCompleted && paidPrompt != prompt {
+				t.Errorf("paid execution prompt = %q, want %q", paidPrompt, prompt)
+			}
+			if _, cached := orchestrator.prompts.load(task.ID); cached {
+				t.Error("prompt cache still holds the finished task")
+			}
+		})
+	}
+}
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("DecodeString(%q) error = %v", s, err)
+	}
+	return b
+}
@@ -0,0 +1,275 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/a2aproject/a2a-go/a2asrv/push"
+)
+
+// PushTokenHeader carries the token a client registered with its push
+// config, so the receiver can tell its callbacks from forged ones.
+const PushTokenHeader = "X-A2A-Notification-Token"
+
+// PushNotifier calls back the URLs clients register as a2a push-notification
+// configs when their tasks change state.
+type PushNotifier struct {
+	// Store keeps the registered configs. Defaults to an in-memory store.
+	Store a2asrv.PushConfigStore
+	// Secret, when set, signs every callback body into the
+	// WebhookSignatureHeader, as webhooks are signed.
+	Secret []byte
+	// IncludeInterim also calls back on non-final status updates, such as
+	// payment verified or settlement pending. By default only updates that
+	// end an execution are sent: payment required, and terminal states.
+	IncludeInterim bool
+	// Client sends the requests. Defaults to a client with a 10s timeout.
+	Client *http.Client
+	// MaxAttempts is the total number of deliveries per callback, including
+	// the first. Defaults to 5.
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry; it doubles on each
+	// retry up to one minute. Defaults to 1s.
+	InitialBackoff time.Duration
+	// QueueSize bounds the in-memory outbox. Defaults to 256.
+	QueueSize int
+	// DeadLetter receives every callback that was not delivered: rejected by
+	// the receiver, out of attempts, or dropped from a full outbox. Undelivered
+	// callbacks are always logged.
+	DeadLetter func(ctx context.Context, letter *PushDeadLetter)
+}
+
+// PushDeadLetter is a callback that was not delivered. Body is the task
+// document that would have been posted.
+type PushDeadLetter struct {
+	TaskID   a2a.TaskID      `json:"taskId"`
+	State    a2a.TaskState   `json:"state"`
+	Config   *a2a.PushConfig `json:"config"`
+	Body     []byte          `json:"body"`
+	Attempts int             `json:"attempts"`
+	Error    string          `json:"error"`
+	FailedAt time.Time       `json:"failedAt"`
+}
+
+// WithPushNotifier delivers a2a push notifications for the merchant's
+// tasks. Pass Merchant.PushNotifications to a2asrv.NewHandler so the handler
+// stores the configs clients register and hands each status update to the
+// notifier. Callbacks post the task as JSON from an in-memory outbox, so a
+// slow or failing receiver never delays or fails a payment; callbacks still
+// queued are lost on restart. Call Shutdown to drain the outbox.
+func WithPushNotifier(notifier PushNotifier) Option {
+	return func(o *BusinessOrchestrator) {
+		if notifier.Store == nil {
+			notifier.Store = push.NewInMemoryStore()
+		}
+		if notifier.Client == nil {
+			notifier.Client = &http.Client{Timeout: 10 * time.Second}
+		}
+		if notifier.MaxAttempts <= 0 {
+			notifier.MaxAttempts = 5
+		}
+		if notifier.InitialBackoff <= 0 {
+			notifier.InitialBackoff = time.Second
+		}
+		if notifier.QueueSize <= 0 {
+			notifier.QueueSize = 256
+		}
+		o.push = &pushOutbox{
+			notifier:  notifier,
+			callbacks: make(chan *pushCallback, notifier.QueueSize),
+			done:      make(chan struct{}),
+		}
+	}
+}
+
+// pushCallback is one callback waiting in the outbox. The task is encoded
+// when it is queued, so later changes to it do not leak into the callback.
+type pushCallback struct {
+	taskID a2a.TaskID
+	state  a2a.TaskState
+	config a2a.PushConfig
+	body   []byte
+}
+
+// pushOutbox is the a2asrv.PushSender handed to the request handler.
+type pushOutbox struct {
+	notifier  PushNotifier
+	o         *BusinessOrchestrator
+	callbacks chan *pushCallback
+	done      chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+func (p *pushOutbox) start(o *BusinessOrchestrator) {
+	p.o = o
+	go func() {
+		defer close(p.done)
+		for callback := range p.callbacks {
+			if attempts, err := p.deliver(callback); err != nil {
+				p.deadLetter(callback, attempts, err)
+			}
+		}
+	}()
+}
+
+// SendPush queues a callback for task and never fails: the a2a handler would
+// otherwise fail the task over an unreachable receiver.
+func (p *pushOutbox) SendPush(ctx context.Context, config *a2a.PushConfig, task *a2a.Task) error {
+	if config == nil || task == nil || !p.wanted(task.Status.State) {
+		return nil
+	}
+	callback := &pushCallback{taskID: task.ID, state: task.Status.State, config: *config}
+	body, err := json.Marshal(task)
+	if err != nil {
+		p.deadLetter(callback, 0, fmt.Errorf("failed to encode task: %w", err))
+		return nil
+	}
+	callback.body = body
+	if !p.enqueue(callback) {
+		p.deadLetter(callback, 0, fmt.Errorf("push outbox full or shut down"))
+	}
+	return nil
+}
+
+// wanted reports whether an update to state is called back. The handler
+// asks for every update; interim ones are skipped unless configured.
+func (p *pushOutbox) wanted(state a2a.TaskState) bool {
+	if p.notifier.IncludeInterim || state.Terminal() {
+		return true
+	}
+	return state == a2a.TaskStateInputRequired || state == a2a.TaskStateAuthRequired
+}
+
+// enqueue reports false when the outbox is full or shut down.
+func (p *pushOutbox) enqueue(callback *pushCallback) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return false
+	}
+	select {
+	case p.callbacks <- callback:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *pushOutbox) shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.callbacks)
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("pending push notifications not delivered: %w", ctx.Err())
+	}
+}
+
+// deliver posts callback until the receiver accepts it, rejects it outright
+// with a 4xx other than 429, or the attempts run out. It returns the number
+// of attempts made.
+func (p *pushOutbox) deliver(callback *pushCallback) (int, error) {
+	backoff := p.notifier.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		retry, err := p.post(callback)
+		if err == nil {
+			return attempt, nil
+		}
+		if !retry || attempt >= p.notifier.MaxAttempts {
+			return attempt, fmt.Errorf("push delivery failed after %d attempts: %w", attempt, err)
+		}
+		time.Sleep(backoff)
+		backoff = min(backoff*2, time.Minute)
+	}
+}
+
+func (p *pushOutbox) post(callback *pushCallback) (bool, error) {
+	request, err := http.NewRequest(http.MethodPost, callback.config.URL, bytes.NewReader(callback.body))
+	if err != nil {
+		return false, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if callback.config.Token != "" {
+		request.Header.Set(PushTokenHeader, callback.config.Token)
+	}
+	if auth := callback.config.Auth; auth != nil && auth.Credentials != "" {
+		for _, scheme := range auth.Schemes {
+			if strings.EqualFold(scheme, "bearer") {
+				request.Header.Set("Authorization", "Bearer "+auth.Credentials)
+				break
+			}
+			if strings.EqualFold(scheme, "basic") {
+				request.Header.Set("Authorization", "Basic "+auth.Credentials)
+				break
+			}
+		}
+	}
+	if len(p.notifier.Secret) > 0 {
+		request.Header.Set(WebhookSignatureHeader, SignWebhook(callback.body, p.notifier.Secret))
+	}
+
+	response, err := p.notifier.Client.Do(request)
+	if err != nil {
+		return true, err
+	}
+	response.Body.Close()
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return false, nil
+	}
+	retry := response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= 500
+	return retry, fmt.Errorf("push receiver returned %s", response.Status)
+}
+
+func (p *pushOutbox) deadLetter(callback *pushCallback, attempts int, err error) {
+	ctx := context.Background()
+	p.o.logger.ErrorContext(ctx, "x402 push notification undelivered",
+		"task_id", callback.taskID,
+		"state", callback.state,
+		"push_config_id", callback.config.ID,
+		"attempts", attempts,
+		"error", err,
+	)
+	if p.notifier.DeadLetter == nil {
+		return
+	}
+	config := callback.config
+	p.notifier.DeadLetter(ctx, &PushDeadLetter{
+		TaskID:   callback.taskID,
+		State:    callback.state,
+		Config:   &config,
+		Body:     callback.body,
+		Attempts: attempts,
+		Error:    err.Error(),
+		FailedAt: p.o.now(),
+	})
+}
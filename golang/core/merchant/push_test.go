@@ -0,0 +1,179 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+)
+
+// pushReceiver records the callbacks posted to it.
+type pushReceiver struct {
+	mu     sync.Mutex
+	states []a2a.TaskState
+	status int
+	server *httptest.Server
+}
+
+func newPushReceiver(t *testing.T, secret []byte, status int) *pushReceiver {
+	r := &pushReceiver{status: status}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		if req.Header.Get(PushTokenHeader) != "client-token" {
+			t.Errorf("token header = %q", req.Header.Get(PushTokenHeader))
+		}
+		signature := req.Header.Get(WebhookSignatureHeader)
+		if len(secret) == 0 && signature != "" {
+			t.Errorf("unsigned notifier sent signature %q", signature)
+		} else if len(secret) > 0 && !VerifyWebhookSignature(body, signature, secret) {
+			t.Error("callback signature does not verify")
+		}
+		var task a2a.Task
+		if err := json.Unmarshal(body, &task); err != nil {
+			t.Errorf("callback body is not a task: %v", err)
+		}
+		r.mu.Lock()
+		r.states = append(r.states, task.Status.State)
+		r.mu.Unlock()
+		w.WriteHeader(r.status)
+	}))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+func (r *pushReceiver) received() []a2a.TaskState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.states)
+}
+
+// sendWithPush quotes and pays through an a2a request handler, registering a
+// push config with the first message.
+func sendWithPush(t *testing.T, merchant *Merchant, url string) *a2a.Task {
+	t.Helper()
+	ctx := context.Background()
+	handler := a2asrv.NewHandler(merchant.Orchestrator(), merchant.PushNotifications())
+	result, err := handler.OnSendMessage(ctx, &a2a.MessageSendParams{
+		Message: a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "buy"}),
+		Config: &a2a.MessageSendConfig{
+			PushConfig: &a2a.PushConfig{URL: url, Token: "client-token"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("OnSendMessage() error = %v", err)
+	}
+	quoted, ok := result.(*a2a.Task)
+	if !ok || quoted.Status.State != a2a.TaskStateInputRequired {
+		t.Fatalf("first result = %#v, want an input-required task", result)
+	}
+	submission := paymentSubmission(t, quoted)
+	submission.ContextID = quoted.ContextID
+	result, err = handler.OnSendMessage(ctx, &a2a.MessageSendParams{Message: submission})
+	if err != nil {
+		t.Fatalf("paid OnSendMessage() error = %v", err)
+	}
+	paid, ok := result.(*a2a.Task)
+	if !ok {
+		t.Fatalf("paid result = %#v, want a task", result)
+	}
+	return paid
+}
+
+func TestPushNotifier_CallsBackOnPaymentRequiredAndCompleted(t *testing.T) {
+	secret := []byte("push-secret")
+	receiver := newPushReceiver(t, secret, http.StatusOK)
+	merchant := &Merchant{orchestrator: newNetworkMatchingOrchestrator(
+		t,
+		WithPushNotifier(PushNotifier{Secret: secret}),
+	)}
+
+	task := sendWithPush(t, merchant, receiver.server.URL)
+	if task.Status.State != a2a.TaskStateCompleted {
+		t.Fatalf("state = %s, want completed", task.Status.State)
+	}
+	if err := merchant.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	want := []a2a.TaskState{a2a.TaskStateInputRequired, a2a.TaskStateCompleted}
+	if got := receiver.received(); !slices.Equal(got, want) {
+		t.Errorf("callbacks = %v, want %v", got, want)
+	}
+}
+
+func TestPushNotifier_IncludeInterim(t *testing.T) {
+	receiver := newPushReceiver(t, nil, http.StatusOK)
+	merchant := &Merchant{orchestrator: newNetworkMatchingOrchestrator(
+		t,
+		WithPushNotifier(PushNotifier{IncludeInterim: true}),
+	)}
+
+	sendWithPush(t, merchant, receiver.server.URL)
+	if err := merchant.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	got := receiver.received()
+	if !slices.Contains(got, a2a.TaskStateWorking) || got[len(got)-1] != a2a.TaskStateCompleted {
+		t.Errorf("callbacks = %v, want interim working updates before completed", got)
+	}
+}
+
+func TestPushNotifier_DeadLettersWithoutFailingPayment(t *testing.T) {
+	receiver := newPushReceiver(t, nil, http.StatusServiceUnavailable)
+	var mu sync.Mutex
+	var letters []*PushDeadLetter
+	merchant := &Merchant{orchestrator: newNetworkMatchingOrchestrator(
+		t,
+		WithPushNotifier(PushNotifier{
+			MaxAttempts:    2,
+			InitialBackoff: time.Millisecond,
+			DeadLetter: func(ctx context.Context, letter *PushDeadLetter) {
+				mu.Lock()
+				defer mu.Unlock()
+				letters = append(letters, letter)
+			},
+		}),
+	)}
+
+	task := sendWithPush(t, merchant, receiver.server.URL)
+	if task.Status.State != a2a.TaskStateCompleted {
+		t.Fatalf("state = %s, want completed despite the failing receiver", task.Status.State)
+	}
+	if err := merchant.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if got := len(receiver.received()); got != 4 {
+		t.Errorf("receiver saw %d attempts, want 2 per callback", got)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(letters) != 2 {
+		t.Fatalf("dead letters = %d, want 2", len(letters))
+	}
+	for _, letter := range letters {
+		if letter.Attempts != 2 || letter.TaskID != task.ID || letter.Config.URL != receiver.server.URL || len(letter.Body) == 0 {
+			t.Errorf("dead letter = %+v", letter)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/a2aproject/a2a-go/a2asrv/eventqueue"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+)
+
+// Defaults for QuoteJanitorPolicy.
+const (
+	DefaultQuoteSlack         = time.Minute
+	DefaultQuoteSweepInterval = time.Minute
+)
+
+// QuoteJanitorPolicy configures the cleanup of quotes nobody pays.
+type QuoteJanitorPolicy struct {
+	// Tasks is the server's task store, searched for tasks waiting on a
+	// payment. The janitor does not run without it.
+	Tasks a2asrv.TaskStore
+	// Queues provides the event queue of a task whose quote expires. When the
+	// task has an open queue the final status event is written to it.
+	Queues eventqueue.Manager
+	// TTL is how long a quote may go unpaid. When zero, a quote lives for
+	// the longest MaxTimeoutSeconds among its requirements plus Slack.
+	TTL time.Duration
+	// Slack is added to MaxTimeoutSeconds when TTL is zero. Defaults to
+	// DefaultQuoteSlack.
+	Slack time.Duration
+	// Interval is how often the task store is swept. Defaults to
+	// DefaultQuoteSweepInterval.
+	Interval time.Duration
+}
+
+// WithQuoteJanitor cancels tasks whose quote goes unpaid, so abandoned quotes
+// do not pile up in the task store with their prompts and requirements. The
+// store is swept every Interval; a task still input-required with an unpaid
+// quote older than its TTL is canceled with QUOTE_EXPIRED, saved back to the
+// store, and its payment state and cached prompt are dropped. Tasks with a
+// submitted payment are never touched. Shutdown stops the janitor.
+func WithQuoteJanitor(policy QuoteJanitorPolicy) Option {
+	return func(o *BusinessOrchestrator) {
+		if policy.Slack <= 0 {
+			policy.Slack = DefaultQuoteSlack
+		}
+		if policy.Interval <= 0 {
+			policy.Interval = DefaultQuoteSweepInterval
+		}
+		o.quoteJanitor = &quoteJanitor{
+			policy: policy,
+			stop:   make(chan struct{}),
+			done:   make(chan struct{}),
+		}
+	}
+}
+
+type quoteJanitor struct {
+	policy QuoteJanitorPolicy
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func (j *quoteJanitor) start(o *BusinessOrchestrator) {
+	if j.policy.Tasks == nil {
+		o.logger.ErrorContext(context.Background(), "x402 quote janitor not started: no task store")
+		close(j.done)
+		return
+	}
+	go func() {
+		defer close(j.done)
+		ticker := time.NewTicker(j.policy.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-j.stop:
+				return
+			case <-ticker.C:
+				o.sweepExpiredQuotes(context.Background())
+			}
+		}
+	}()
+}
+
+// shutdown stops the janitor and waits for a sweep in progress.
+func (j *quoteJanitor) shutdown(ctx context.Context) error {
+	j.stopOnce.Do(func() { close(j.stop) })
+	select {
+	case <-j.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("quote janitor not stopped: %w", ctx.Err())
+	}
+}
+
+// ttl returns how long task's quote may go unpaid.
+func (j *quoteJanitor) ttl(task *a2a.Task) time.Duration {
+	if j.policy.TTL > 0 {
+		return j.policy.TTL
+	}
+	var longest int
+	if requirements, err := state.ExtractPaymentRequirements(task); err == nil && requirements != nil {
+		for _, accepted := range requirements.Accepts {
+			longest = max(longest, accepted.MaxTimeoutSeconds)
+		}
+	}
+	return time.Duration(longest)*time.Second + j.policy.Slack
+}
+
+// quoteExpired reports whether task is waiting on a quote, with no payment
+// submitted, that has outlived its TTL.
+func (o *BusinessOrchestrator) quoteExpired(task *a2a.Task) bool {
+	if task == nil || task.Status.State != a2a.TaskStateInputRequired || task.Status.Message == nil {
+		return false
+	}
+	if status, _ := state.ExtractPaymentStatus(task); status != state.PaymentRequired {
+		return false
+	}
+	if _, submitted := task.Status.Message.Meta()[x402pkg.MetadataKeyPayload]; submitted {
+		return false
+	}
+	quotedAt, ok := state.ExtractQuotedAt(task)
+	if !ok {
+		if task.Status.Timestamp == nil {
+			return false
+		}
+		quotedAt = *task.Status.Timestamp
+	}
+	return !o.now().Before(quotedAt.Add(o.quoteJanitor.ttl(task)))
+}
+
+// sweepExpiredQuotes cancels every task whose quote has expired and returns
+// how many it canceled.
+func (o *BusinessOrchestrator) sweepExpiredQuotes(ctx context.Context) int {
+	tasks := o.quoteJanitor.policy.Tasks
+	request := &a2a.ListTasksRequest{Status: a2a.TaskStateInputRequired, PageSize: 100}
+	// The whole store is listed before anything changes, so canceling tasks
+	// does not shift the pages still to be read.
+	var expired []a2a.TaskID
+	for {
+		response, err := tasks.List(ctx, request)
+		if err != nil {
+			o.logger.ErrorContext(ctx, "x402 quote janitor could not list tasks", "error", err)
+			break
+		}
+		for _, task := range response.Tasks {
+			if o.quoteExpired(task) {
+				expired = append(expired, task.ID)
+			}
+		}
+		if response.NextPageToken == "" {
+			break
+		}
+		request.PageToken = response.NextPageToken
+	}
+
+	canceled := 0
+	for _, taskID := range expired {
+		if o.expireQuote(ctx, taskID) {
+			canceled++
+		}
+	}
+	return canceled
+}
+
+// expireQuote cancels taskID if its quote is still expired once the task is
+// locked, so a payment submitted meanwhile wins.
+func (o *BusinessOrchestrator) expireQuote(ctx context.Context, taskID a2a.TaskID) bool {
+	unlock, err := o.taskLocks.lock(ctx, taskID)
+	if err != nil {
+		return false
+	}
+	defer unlock()
+
+	tasks := o.quoteJanitor.policy.Tasks
+	task, version, err := tasks.Get(ctx, taskID)
+	if err != nil {
+		o.logger.ErrorContext(ctx, "x402 expired quote not loaded",
+			"task_id", taskID,
+			"error", err,
+		)
+		return false
+	}
+	if !o.quoteExpired(task) {
+		return false
+	}
+
+	task.Status.State = a2a.TaskStateCanceled
+	if err := state.RecordPaymentCanceled(task, state.PaymentRequired, nil, "Quote expired without payment"); err != nil {
+		return false
+	}
+	state.SetPaymentError(task.Status.Message, x402pkg.ErrorCodeQuoteExpired)
+	applyMessageTemplate(task, o.messageTemplates.Canceled, nil)
+
+	requestContext := &a2asrv.RequestContext{TaskID: task.ID, ContextID: task.ContextID, StoredTask: task}
+	event := statusEvent(requestContext, task)
+	if _, err := tasks.Save(ctx, task, event, version); err != nil {
+		o.logger.ErrorContext(ctx, "x402 expired quote not canceled",
+			"task_id", task.ID,
+			"context_id", task.ContextID,
+			"error", err,
+		)
+		return false
+	}
+	o.logger.InfoContext(ctx, "x402 quote expired; task canceled",
+		"task_id", task.ID,
+		"context_id", task.ContextID,
+	)
+	o.recordFailure(ctx, task)
+
+	if queues := o.quoteJanitor.policy.Queues; queues != nil {
+		if queue, ok := queues.Get(ctx, task.ID); ok {
+			if err := o.writeEvent(ctx, task, queue, event); err != nil {
+				o.logger.WarnContext(ctx, "x402 expired quote event not delivered",
+					"task_id", task.ID,
+					"context_id", task.ContextID,
+					"error", err,
+				)
+			}
+		}
+	}
+	o.prompts.forget(task.ID)
+	if err := o.deletePaymentState(ctx, task); err != nil {
+		o.logger.ErrorContext(ctx, "x402 expired quote left in payment state store",
+			"task_id", task.ID,
+			"context_id", task.ContextID,
+			"error", err,
+		)
+	}
+	return true
+}
@@ -0,0 +1,165 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv/eventqueue"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+)
+
+// queueManager hands out one fixed queue for every task.
+type queueManager struct {
+	queue eventqueue.Queue
+}
+
+func (m *queueManager) GetOrCreate(ctx context.Context, taskID a2a.TaskID) (eventqueue.Queue, error) {
+	return m.queue, nil
+}
+
+func (m *queueManager) Get(ctx context.Context, taskID a2a.TaskID) (eventqueue.Queue, bool) {
+	return m.queue, true
+}
+
+func (m *queueManager) Destroy(ctx context.Context, taskID a2a.TaskID) error {
+	return nil
+}
+
+// janitorHarness quotes a task into an in-memory task store and sweeps it
+// with a movable clock.
+type janitorHarness struct {
+	now          time.Time
+	tasks        *memoryTaskStore
+	states       *MemoryPaymentStateStore
+	queue        *mockEventQueue
+	orchestrator *BusinessOrchestrator
+}
+
+func newJanitorHarness(t *testing.T, policy QuoteJanitorPolicy) *janitorHarness {
+	t.Helper()
+	h := &janitorHarness{
+		now:    time.Unix(1_700_000_000, 0),
+		tasks:  &memoryTaskStore{},
+		states: NewMemoryPaymentStateStore(),
+		queue:  &mockEventQueue{},
+	}
+	policy.Tasks = h.tasks
+	policy.Queues = &queueManager{queue: h.queue}
+	policy.Interval = time.Hour
+	h.orchestrator = newNetworkMatchingOrchestrator(
+		t,
+		WithClock(func() time.Time { return h.now }),
+		WithPaymentStateStore(h.states),
+		WithQuoteJanitor(policy),
+	)
+	t.Cleanup(func() { h.orchestrator.Shutdown(context.Background()) })
+
+	task := quoteTask(t, h.orchestrator)
+	if _, err := h.tasks.Save(context.Background(), task, nil, 0); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	return h
+}
+
+func (h *janitorHarness) stored(t *testing.T) *a2a.Task {
+	t.Helper()
+	task, _, err := h.tasks.Get(context.Background(), "task-option")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	return task
+}
+
+func TestQuoteJanitor_CancelsExpiredQuote(t *testing.T) {
+	h := newJanitorHarness(t, QuoteJanitorPolicy{})
+	h.now = h.now.Add(DefaultQuoteSlack)
+
+	if canceled := h.orchestrator.sweepExpiredQuotes(context.Background()); canceled != 1 {
+		t.Fatalf("sweepExpiredQuotes() = %d, want 1", canceled)
+	}
+	task := h.stored(t)
+	if task.Status.State != a2a.TaskStateCanceled {
+		t.Fatalf("state = %s, want canceled", task.Status.State)
+	}
+	if code := task.Status.Message.Meta()[x402.MetadataKeyError]; code != x402.ErrorCodeQuoteExpired {
+		t.Errorf("error code = %v, want %s", code, x402.ErrorCodeQuoteExpired)
+	}
+	if requirements, _ := x402state.ExtractPaymentRequirements(task); requirements != nil {
+		t.Error("canceled task still carries the quoted requirements")
+	}
+	if _, ok, err := h.states.LoadState(context.Background(), task.ID); err != nil || ok {
+		t.Errorf("LoadState() = %v, %v; want the record purged", ok, err)
+	}
+	if len(h.queue.events) != 1 {
+		t.Fatalf("queue got %d events, want 1", len(h.queue.events))
+	}
+	event, ok := h.queue.events[0].(*a2a.TaskStatusUpdateEvent)
+	if !ok || !event.Final || event.Status.State != a2a.TaskStateCanceled {
+		t.Errorf("queue event = %#v, want a final canceled status", h.queue.events[0])
+	}
+
+	if canceled := h.orchestrator.sweepExpiredQuotes(context.Background()); canceled != 0 {
+		t.Errorf("second sweep canceled %d tasks, want 0", canceled)
+	}
+}
+
+func TestQuoteJanitor_KeepsFreshQuote(t *testing.T) {
+	h := newJanitorHarness(t, QuoteJanitorPolicy{TTL: 10 * time.Minute})
+	h.now = h.now.Add(5 * time.Minute)
+
+	if canceled := h.orchestrator.sweepExpiredQuotes(context.Background()); canceled != 0 {
+		t.Fatalf("sweepExpiredQuotes() = %d, want 0", canceled)
+	}
+	if state := h.stored(t).Status.State; state != a2a.TaskStateInputRequired {
+		t.Errorf("state = %s, want input-required", state)
+	}
+	if _, ok, _ := h.states.LoadState(context.Background(), "task-option"); !ok {
+		t.Error("payment state of a fresh quote was purged")
+	}
+}
+
+func TestQuoteJanitor_SkipsSubmittedPayment(t *testing.T) {
+	h := newJanitorHarness(t, QuoteJanitorPolicy{})
+	task := h.stored(t)
+	task.Status.Message.Metadata[x402.MetadataKeyPayload] = map[string]interface{}{"x402Version": 2}
+	if _, err := h.tasks.Save(context.Background(), task, nil, 1); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	h.now = h.now.Add(time.Hour)
+
+	if canceled := h.orchestrator.sweepExpiredQuotes(context.Background()); canceled != 0 {
+		t.Fatalf("sweepExpiredQuotes() = %d, want 0", canceled)
+	}
+	if state := h.stored(t).Status.State; state != a2a.TaskStateInputRequired {
+		t.Errorf("state = %s, want input-required", state)
+	}
+}
+
+func TestQuoteJanitor_StoppedByShutdown(t *testing.T) {
+	h := newJanitorHarness(t, QuoteJanitorPolicy{})
+	if err := h.orchestrator.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	select {
+	case <-h.orchestrator.quoteJanitor.done:
+	default:
+		t.Error("janitor still running after Shutdown")
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+)
+
+// ReceiptSigner signs receipt digests so clients can prove a settlement was
+// acknowledged by this merchant for their task. Address identifies the key.
+type ReceiptSigner interface {
+	Address() string
+	Sign(digest []byte) ([]byte, error)
+}
+
+// WithReceiptSigner attaches a state.SignedReceipt for every settled receipt
+// when a task completes. Clients check them with state.VerifySignedReceipt.
+func WithReceiptSigner(signer ReceiptSigner) Option {
+	return func(o *BusinessOrchestrator) {
+		o.receiptSigner = signer
+	}
+}
+
+type evmReceiptSigner struct {
+	key     *ecdsa.PrivateKey
+	address string
+}
+
+// NewEVMReceiptSigner signs with a secp256k1 key given in hex, producing
+// 65-byte [R || S || V] signatures that state.VerifySignedReceipt recovers.
+func NewEVMReceiptSigner(privateKeyHex string) (ReceiptSigner, error) {
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(privateKeyHex, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid receipt signing key: %w", err)
+	}
+	return &evmReceiptSigner{key: key, address: crypto.PubkeyToAddress(key.PublicKey).Hex()}, nil
+}
+
+func (s *evmReceiptSigner) Address() string {
+	return s.address
+}
+
+func (s *evmReceiptSigner) Sign(digest []byte) ([]byte, error) {
+	return crypto.Sign(digest, s.key)
+}
+
+// signReceipts records merchant signatures for the settled receipts. The
+// payment has already been collected, so a signing failure is logged rather
+// than failing the task.
+func (o *BusinessOrchestrator) signReceipts(
+	ctx context.Context,
+	task *a2a.Task,
+	payloadHash string,
+	receipts []*x402core.SettleResponse,
+) {
+	if o.receiptSigner == nil {
+		return
+	}
+	var signed []*state.SignedReceipt
+	for _, receipt := range receipts {
+		if receipt == nil || !receipt.Success {
+			continue
+		}
+		signedReceipt := &state.SignedReceipt{
+			TaskID:      string(task.ID),
+			PayloadHash: payloadHash,
+			Receipt:     receipt,
+			Timestamp:   o.now().Unix(),
+			Signer:      o.receiptSigner.Address(),
+		}
+		digest, err := state.ReceiptDigest(signedReceipt)
+		if err == nil {
+			var signature []byte
+			signature, err = o.receiptSigner.Sign(digest)
+			signedReceipt.Signature = "0x" + hex.EncodeToString(signature)
+		}
+		if err != nil {
+			o.logger.WarnContext(ctx, "x402 receipt signing failed",
+				"task_id", task.ID,
+				"error", err,
+			)
+			continue
+		}
+		signed = append(signed, signedReceipt)
+	}
+	if err := state.SetSignedReceipts(task.Status.Message, signed); err != nil {
+		o.logger.WarnContext(ctx, "x402 receipt signing failed", "task_id", task.ID, "error", err)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+)
+
+func TestBusinessOrchestrator_transitionToCompleted_SignedReceipts(t *testing.T) {
+	signer, err := NewEVMReceiptSigner("0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	if err != nil {
+		t.Fatalf("NewEVMReceiptSigner() error = %v", err)
+	}
+	now := time.Unix(1760000000, 0)
+	orchestrator := newTestOrchestrator(t, &MockResourceServer{}, &mockBusinessService{}, nil, WithReceiptSigner(signer), WithClock(func() time.Time { return now }))
+
+	message := a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{Text: "Payment verified"})
+	x402state.SetPaymentPayloadHash(message, "payload-hash")
+	task := &a2a.Task{ID: "task-signed", ContextID: "context-signed", Status: a2a.TaskStatus{State: a2a.TaskStateWorking, Message: message}}
+	requestContext := &a2asrv.RequestContext{TaskID: task.ID, ContextID: task.ContextID}
+	receipt := &x402core.SettleResponse{Success: true, Transaction: "0xtx", Network: x402.NetworkBaseSepolia}
+
+	err = orchestrator.transitionToCompleted(context.Background(), requestContext, task, &mockEventQueue{}, &x402state.PaymentState{
+		Status:   x402state.PaymentCompleted,
+		Message:  "done",
+		Receipts: []*x402core.SettleResponse{receipt},
+	})
+	if err != nil {
+		t.Fatalf("transitionToCompleted() error = %v", err)
+	}
+
+	signed, err := x402state.ExtractSignedReceipts(task)
+	if err != nil || len(signed) != 1 {
+		t.Fatalf("ExtractSignedReceipts() = %v, %v", signed, err)
+	}
+	if signed[0].TaskID != "task-signed" || signed[0].PayloadHash != "payload-hash" || signed[0].Timestamp != now.Unix() {
+		t.Errorf("signed receipt = %+v", signed[0])
+	}
+	if err := x402state.VerifySignedReceipt(signed[0], signer.Address()); err != nil {
+		t.Errorf("VerifySignedReceipt() error = %v", err)
+	}
+}
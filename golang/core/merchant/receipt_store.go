@@ -0,0 +1,193 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// ReceiptRecord is the accounting copy of one settled payment. A task paid in
+// several rounds has one record per round.
+type ReceiptRecord struct {
+	TaskID    a2a.TaskID `json:"taskId"`
+	ContextID string     `json:"contextId,omitempty"`
+	Payer     string     `json:"payer,omitempty"`
+	Network   string     `json:"network"`
+	Asset     string     `json:"asset,omitempty"`
+	// PayTo is the address the payment was sent to, which differs per task
+	// under WithPayToProvider.
+	PayTo       string `json:"payTo,omitempty"`
+	Amount      string `json:"amount"`
+	Transaction string `json:"transaction"`
+	// Refund marks money returned to the payer by IssueRefund rather than a
+	// payment; Amount is the amount refunded.
+	Refund bool `json:"refund,omitempty"`
+	// Reason is why a refund was issued.
+	Reason string `json:"reason,omitempty"`
+	// SettledAt is when the merchant received the receipt.
+	SettledAt time.Time `json:"settledAt"`
+	// RecordedAt is when the store took the record; stores fill it in when it
+	// is zero.
+	RecordedAt time.Time `json:"recordedAt"`
+}
+
+// ReceiptStore keeps settled payments after their tasks are gone. The
+// orchestrator appends a record for every successful settlement, whether it
+// happened inline, in the background, in a batch or when escrow released it,
+// and one for every refund.
+// Implementations must be safe for concurrent use.
+type ReceiptStore interface {
+	Append(ctx context.Context, record *ReceiptRecord) error
+	// ListByPayer returns the payer's receipts in settlement order. Payer
+	// addresses compare case-insensitively.
+	ListByPayer(ctx context.Context, payer string) ([]*ReceiptRecord, error)
+	// ListByTimeRange returns receipts settled in [from, to) in settlement
+	// order.
+	ListByTimeRange(ctx context.Context, from, to time.Time) ([]*ReceiptRecord, error)
+	// GetByTask returns the task's receipts in settlement order, or none.
+	GetByTask(ctx context.Context, taskID a2a.TaskID) ([]*ReceiptRecord, error)
+}
+
+// WithReceiptStore records every settled payment in store. Merchant.Receipts
+// exposes it for admin endpoints.
+func WithReceiptStore(store ReceiptStore) Option {
+	return func(o *BusinessOrchestrator) {
+		o.receipts = store
+	}
+}
+
+// recordReceipt appends a settled payment to the receipt store. The payment
+// has settled by the time it is called, so a failed append is logged rather
+// than failing the task.
+func (o *BusinessOrchestrator) recordReceipt(
+	ctx context.Context,
+	task *a2a.Task,
+	payer string,
+	requirement *x402types.PaymentRequirements,
+	receipt *x402core.SettleResponse,
+) {
+	if o.receipts == nil || receipt == nil || !receipt.Success {
+		return
+	}
+	record := &ReceiptRecord{
+		TaskID:      task.ID,
+		ContextID:   task.ContextID,
+		Payer:       receipt.Payer,
+		Network:     string(receipt.Network),
+		Amount:      receipt.Amount,
+		Transaction: receipt.Transaction,
+		SettledAt:   o.now(),
+	}
+	if record.Payer == "" {
+		record.Payer = payer
+	}
+	if requirement != nil {
+		record.Asset = requirement.Asset
+		record.PayTo = requirement.PayTo
+		if record.Network == "" {
+			record.Network = requirement.Network
+		}
+		if record.Amount == "" {
+			record.Amount = requirement.Amount
+		}
+	}
+	if err := o.receipts.Append(context.WithoutCancel(ctx), record); err != nil {
+		o.logger.ErrorContext(ctx, "x402 receipt not recorded",
+			"task_id", task.ID,
+			"context_id", task.ContextID,
+			"transaction", receipt.Transaction,
+			"error", err,
+		)
+	}
+}
+
+// MemoryReceiptStore keeps receipts in memory. It is meant for tests and
+// single-process deployments that export receipts elsewhere.
+type MemoryReceiptStore struct {
+	mu      sync.RWMutex
+	records []ReceiptRecord
+}
+
+func NewMemoryReceiptStore() *MemoryReceiptStore {
+	return &MemoryReceiptStore{}
+}
+
+func (s *MemoryReceiptStore) Append(ctx context.Context, record *ReceiptRecord) error {
+	if record == nil {
+		return fmt.Errorf("receipt record is required")
+	}
+	stored := *record
+	if stored.RecordedAt.IsZero() {
+		stored.RecordedAt = time.Now()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, stored)
+	return nil
+}
+
+func (s *MemoryReceiptStore) ListByPayer(ctx context.Context, payer string) ([]*ReceiptRecord, error) {
+	return s.list(func(record *ReceiptRecord) bool {
+		return strings.EqualFold(record.Payer, payer)
+	}), nil
+}
+
+func (s *MemoryReceiptStore) ListByTimeRange(ctx context.Context, from, to time.Time) ([]*ReceiptRecord, error) {
+	return s.list(func(record *ReceiptRecord) bool {
+		return !record.SettledAt.Before(from) && record.SettledAt.Before(to)
+	}), nil
+}
+
+func (s *MemoryReceiptStore) GetByTask(ctx context.Context, taskID a2a.TaskID) ([]*ReceiptRecord, error) {
+	return s.list(func(record *ReceiptRecord) bool {
+		return record.TaskID == taskID
+	}), nil
+}
+
+func (s *MemoryReceiptStore) list(match func(*ReceiptRecord) bool) []*ReceiptRecord {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var matched []*ReceiptRecord
+	for _, record := range s.records {
+		if match(&record) {
+			matched = append(matched, &record)
+		}
+	}
+	SortReceipts(matched)
+	return matched
+}
+
+// SortReceipts orders receipts by settlement time, then by task ID and
+// transaction, the order every ReceiptStore query returns.
+func SortReceipts(records []*ReceiptRecord) {
+	slices.SortStableFunc(records, func(a, b *ReceiptRecord) int {
+		if c := a.SettledAt.Compare(b.SettledAt); c != 0 {
+			return c
+		}
+		if c := strings.Compare(string(a.TaskID), string(b.TaskID)); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Transaction, b.Transaction)
+	})
+}
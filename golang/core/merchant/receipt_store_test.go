@@ -0,0 +1,212 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+func TestMemoryReceiptStore_Queries(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryReceiptStore()
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, record := range []*ReceiptRecord{
+		{TaskID: "task-b", Payer: "0xAbC", Network: x402.NetworkBaseSepolia, Amount: "200", Transaction: "0x2", SettledAt: base.Add(2 * time.Hour)},
+		{TaskID: "task-a", Payer: "0xabc", Network: x402.NetworkBaseSepolia, Amount: "100", Transaction: "0x1", SettledAt: base},
+		{TaskID: "task-c", Payer: "0xdef", Network: x402.NetworkBase, Amount: "300", Transaction: "0x3", SettledAt: base.Add(time.Hour)},
+		{TaskID: "task-a", Payer: "0xabc", Network: x402.NetworkBaseSepolia, Amount: "50", Transaction: "0x4", SettledAt: base.Add(3 * time.Hour)},
+	} {
+		if err := store.Append(ctx, record); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+	if err := store.Append(ctx, nil); err == nil {
+		t.Error("Append(nil) error = nil, want error")
+	}
+
+	transactions := func(records []*ReceiptRecord) string {
+		var txs []string
+		for _, record := range records {
+			txs = append(txs, record.Transaction)
+		}
+		return fmt.Sprint(txs)
+	}
+	tests := []struct {
+		name  string
+		query func() ([]*ReceiptRecord, error)
+		want  string
+	}{
+		{
+			name:  "by payer ignoring case",
+			query: func() ([]*ReceiptRecord, error) { return store.ListByPayer(ctx, "0xABC") },
+			want:  "[0x1 0x2 0x4]",
+		},
+		{
+			name:  "by unknown payer",
+			query: func() ([]*ReceiptRecord, error) { return store.ListByPayer(ctx, "0x999") },
+			want:  "[]",
+		},
+		{
+			name: "by time range excluding the end",
+			query: func() ([]*ReceiptRecord, error) {
+				return store.ListByTimeRange(ctx, base.Add(time.Hour), base.Add(3*time.Hour))
+			},
+			want: "[0x3 0x2]",
+		},
+		{
+			name:  "by task with several rounds",
+			query: func() ([]*ReceiptRecord, error) { return store.GetByTask(ctx, "task-a") },
+			want:  "[0x1 0x4]",
+		},
+		{
+			name:  "by unknown task",
+			query: func() ([]*ReceiptRecord, error) { return store.GetByTask(ctx, "task-missing") },
+			want:  "[]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := tt.query()
+			if err != nil {
+				t.Fatalf("query error = %v", err)
+			}
+			if got := transactions(records); got != tt.want {
+				t.Errorf("transactions = %s, want %s", got, tt.want)
+			}
+			for _, record := range records {
+				if record.RecordedAt.IsZero() {
+					t.Errorf("receipt %s has no RecordedAt", record.Transaction)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryReceiptStore_ConcurrentAppends(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryReceiptStore()
+	const writers, perWriter = 8, 50
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	var wg sync.WaitGroup
+	for w := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range perWriter {
+				record := &ReceiptRecord{
+					TaskID:      a2a.TaskID(fmt.Sprintf("task-%d-%d", w, i)),
+					Payer:       fmt.Sprintf("0x%d", w),
+					Transaction: fmt.Sprintf("0x%d%d", w, i),
+					SettledAt:   base.Add(time.Duration(i) * time.Second),
+				}
+				if err := store.Append(ctx, record); err != nil {
+					t.Errorf("Append() error = %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	all, err := store.ListByTimeRange(ctx, base, base.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("ListByTimeRange() error = %v", err)
+	}
+	if len(all) != writers*perWriter {
+		t.Fatalf("receipts = %d, want %d", len(all), writers*perWriter)
+	}
+	for i := 1; i < len(all); i++ {
+		if all[i].SettledAt.Before(all[i-1].SettledAt) {
+			t.Fatalf("receipts out of order at %d", i)
+		}
+	}
+	if byPayer, _ := store.ListByPayer(ctx, "0x3"); len(byPayer) != perWriter {
+		t.Errorf("receipts for one payer = %d, want %d", len(byPayer), perWriter)
+	}
+}
+
+func TestBusinessOrchestrator_RecordsSettledReceipts(t *testing.T) {
+	settledAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		opts       []Option
+		settleFail bool
+		want       int
+	}{
+		{name: "inline settlement", want: 1},
+		{name: "background settlement", opts: []Option{WithAsyncSettlement(AsyncSettlementConfig{})}, want: 1},
+		{name: "failed settlement", settleFail: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receipts := NewMemoryReceiptStore()
+			server := &MockResourceServer{
+				SettlePaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.SettleResponse, error) {
+					if tt.settleFail {
+						return &x402core.SettleResponse{Success: false, ErrorReason: "insufficient_funds", Network: x402.NetworkBaseSepolia}, nil
+					}
+					return &x402core.SettleResponse{Success: true, Network: x402.NetworkBaseSepolia, Transaction: "0xtx", Amount: "100"}, nil
+				},
+			}
+			orchestrator := newTestOrchestrator(
+				t,
+				server,
+				&mockBusinessService{},
+				[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+				append([]Option{WithReceiptStore(receipts), WithClock(func() time.Time { return settledAt })}, tt.opts...)...,
+			)
+			task := payTask(t, orchestrator, "task-receipt")
+			shutdown(t, orchestrator)
+
+			records, err := receipts.GetByTask(context.Background(), task.ID)
+			if err != nil {
+				t.Fatalf("GetByTask() error = %v", err)
+			}
+			if len(records) != tt.want {
+				t.Fatalf("receipts = %+v, want %d", records, tt.want)
+			}
+			if tt.want == 0 {
+				return
+			}
+			want := ReceiptRecord{
+				TaskID:      task.ID,
+				ContextID:   task.ContextID,
+				Payer:       "0x789",
+				Network:     x402.NetworkBaseSepolia,
+				Asset:       "0x456",
+				PayTo:       "0x123",
+				Amount:      "100",
+				Transaction: "0xtx",
+				SettledAt:   settledAt,
+			}
+			got := *records[0]
+			got.RecordedAt = time.Time{}
+			if got != want {
+				t.Errorf("receipt = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,314 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/a2aproject/a2a-go/a2asrv/eventqueue"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+)
+
+// RecoveryConfig configures Recover.
+type RecoveryConfig struct {
+	// Tasks is the server's task store. Recover loads every unfinished task
+	// from it and saves the task back, with its last catch-up event, once
+	// the payment has moved on. It is required.
+	Tasks a2asrv.TaskStore
+}
+
+// WithRecovery lets Recover resume the payments an earlier process left
+// verified but unfinished. It requires a WithPaymentStateStore store that
+// implements PaymentStateLister.
+func WithRecovery(config RecoveryConfig) Option {
+	return func(o *BusinessOrchestrator) {
+		o.recovery = &config
+	}
+}
+
+// validateRecovery rejects a recovery configuration that could not find or
+// update the unfinished tasks.
+func (o *BusinessOrchestrator) validateRecovery() error {
+	if o.recovery == nil {
+		return nil
+	}
+	if o.recovery.Tasks == nil {
+		return errors.New("recovery requires a task store")
+	}
+	if _, ok := o.stateStore.(PaymentStateLister); !ok {
+		return errors.New("recovery requires a payment state store that implements PaymentStateLister")
+	}
+	return nil
+}
+
+// Recover resumes every verified payment in the payment state store whose
+// task an earlier process left unfinished. Call it at startup, before the
+// server takes requests. Each payment carries on according to how far it
+// got:
+//
+//   - A payment whose settlement had started is reconciled against the
+//     receipt store instead of being settled again. A receipt recorded since
+//     the settlement started completes the task with that receipt; without
+//     one the outcome is unknown, and the task fails with SETTLE_TIMEOUT and
+//     is marked indeterminate for manual reconciliation.
+//   - A payment not yet settled is verified again, since its authorization
+//     may have expired or been spent while the merchant was down, and then
+//     executed and settled under the settlement policy.
+//
+// A request for a task whose settlement was interrupted is reconciled the
+// same way when it arrives, with or without Recover. Recover returns the
+// errors of the tasks it could not resume, joined.
+func (o *BusinessOrchestrator) Recover(ctx context.Context) error {
+	if o.recovery == nil {
+		return errors.New("recovery is not configured")
+	}
+	lister, ok := o.stateStore.(PaymentStateLister)
+	if !ok {
+		return errors.New("payment state store cannot list records")
+	}
+	records, err := lister.PaymentStates(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list payment records: %w", err)
+	}
+	taskIDs := make([]a2a.TaskID, 0, len(records))
+	for taskID, record := range records {
+		if record.Status == state.PaymentVerified {
+			taskIDs = append(taskIDs, taskID)
+		}
+	}
+	slices.Sort(taskIDs)
+
+	var errs []error
+	for _, taskID := range taskIDs {
+		if err := o.recoverTask(ctx, taskID, records[taskID]); err != nil {
+			o.logger.ErrorContext(ctx, "x402 payment not recovered",
+				"task_id", taskID,
+				"error", err,
+			)
+			errs = append(errs, fmt.Errorf("task %s: %w", taskID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (o *BusinessOrchestrator) recoverTask(ctx context.Context, taskID a2a.TaskID, record *PaymentRecord) error {
+	if !o.lifecycle.enter() {
+		return ErrShuttingDown
+	}
+	defer o.lifecycle.exit()
+	unlock, err := o.taskLocks.lock(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	tasks := o.recovery.Tasks
+	task, version, err := tasks.Get(ctx, taskID)
+	if err != nil {
+		return fmt.Errorf("failed to load task: %w", err)
+	}
+	if task.Status.State.Terminal() {
+		// The record outlived its task; the task's own metadata says how
+		// the payment ended.
+		return nil
+	}
+	if err := o.restorePaymentState(ctx, task); err != nil {
+		return fmt.Errorf("failed to restore payment state: %w", err)
+	}
+
+	o.logger.InfoContext(ctx, "x402 resuming interrupted payment",
+		"task_id", task.ID,
+		"context_id", task.ContextID,
+		"settlement_started", !record.SettlingSince.IsZero(),
+	)
+	requestContext := &a2asrv.RequestContext{
+		TaskID:     task.ID,
+		ContextID:  task.ContextID,
+		StoredTask: task,
+	}
+	queue := &collectingQueue{}
+	if settlementInterrupted(record) {
+		err = o.reconcileSettlement(ctx, requestContext, task, queue, record)
+	} else {
+		err = o.resumeVerified(ctx, requestContext, task, queue)
+	}
+	events := queue.drain()
+	if len(events) > 0 {
+		if _, saveErr := tasks.Save(ctx, task, events[len(events)-1], version); saveErr != nil {
+			return errors.Join(err, fmt.Errorf("failed to save task: %w", saveErr))
+		}
+	}
+	return err
+}
+
+// settlementInterrupted reports whether record belongs to a settlement that
+// started but whose outcome the merchant never saw.
+func settlementInterrupted(record *PaymentRecord) bool {
+	return record.Status == state.PaymentVerified && (record.Indeterminate || !record.SettlingSince.IsZero())
+}
+
+// resumeInterruptedSettlement reconciles a request for a task whose settlement
+// an earlier process started but never finished. It reports false for every
+// other task.
+func (o *BusinessOrchestrator) resumeInterruptedSettlement(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	eventQueue eventqueue.Queue,
+) (bool, error) {
+	if o.stateStore == nil || task.Status.State.Terminal() {
+		return false, nil
+	}
+	if status, err := state.ExtractPaymentStatus(task); err != nil || status != state.PaymentVerified {
+		return false, nil
+	}
+	record, found, err := o.stateStore.LoadState(ctx, task.ID)
+	if err != nil || !found || !settlementInterrupted(record) {
+		return false, nil
+	}
+	return true, o.reconcileSettlement(ctx, requestContext, task, eventQueue, record)
+}
+
+// resumeVerified verifies the task's payment again and carries on from
+// payment-verified.
+func (o *BusinessOrchestrator) resumeVerified(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	eventQueue eventqueue.Queue,
+) error {
+	paymentState, err := state.ExtractPaymentState(task, nil)
+	if err != nil {
+		return o.transitionToTaskFailed(ctx, requestContext, task, eventQueue,
+			fmt.Errorf("failed to extract payment state: %w", err), x402pkg.ErrorCodeInternal)
+	}
+	if err := o.verifyPayment(ctx, task, paymentState); err != nil {
+		_, err := o.failPayment(ctx, requestContext, task, eventQueue, paymentState,
+			fmt.Errorf("payment verification failed: %w", err), verificationErrorCode(err), nil)
+		return err
+	}
+	return o.runStateMachine(ctx, requestContext, task, eventQueue, paymentState,
+		func(paymentState *state.PaymentState) (*state.PaymentState, bool, error) {
+			return o.step(ctx, requestContext, task, eventQueue, nil, paymentState)
+		})
+}
+
+// reconcileSettlement completes the task with the receipt of its interrupted
+// settlement, or fails it as indeterminate when no receipt was recorded.
+func (o *BusinessOrchestrator) reconcileSettlement(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	eventQueue eventqueue.Queue,
+	record *PaymentRecord,
+) error {
+	paymentState, err := state.ExtractPaymentState(task, nil)
+	if err != nil {
+		return o.transitionToTaskFailed(ctx, requestContext, task, eventQueue,
+			fmt.Errorf("failed to extract payment state: %w", err), x402pkg.ErrorCodeInternal)
+	}
+	receipt, err := o.recordedSettlement(ctx, task.ID, record)
+	if err != nil {
+		return err
+	}
+	if receipt == nil {
+		o.logger.ErrorContext(ctx, "x402 settlement interrupted with unknown outcome; reconcile manually",
+			"task_id", task.ID,
+			"context_id", task.ContextID,
+			"network", payloadNetwork(paymentState),
+		)
+		_, err := o.failPayment(ctx, requestContext, task, eventQueue, paymentState,
+			errors.New("settlement was interrupted and its outcome is unknown"), x402pkg.ErrorCodeSettleTimeout, nil)
+		return err
+	}
+
+	reconciled := false
+	return o.runStateMachine(ctx, requestContext, task, eventQueue, paymentState,
+		func(paymentState *state.PaymentState) (*state.PaymentState, bool, error) {
+			if reconciled {
+				return o.step(ctx, requestContext, task, eventQueue, nil, paymentState)
+			}
+			reconciled = true
+			next, err := o.completeSettled(ctx, requestContext, task, eventQueue, paymentState, receipt)
+			return next, err != nil, err
+		})
+}
+
+// recordedSettlement returns the receipt the receipt store holds for the
+// settlement record describes, or nil when it holds none. A settlement
+// recorded before the interrupted one started belongs to an earlier round.
+func (o *BusinessOrchestrator) recordedSettlement(ctx context.Context, taskID a2a.TaskID, record *PaymentRecord) (*x402core.SettleResponse, error) {
+	if o.receipts == nil {
+		return nil, nil
+	}
+	records, err := o.receipts.GetByTask(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read receipts: %w", err)
+	}
+	for _, settled := range slices.Backward(records) {
+		if settled.Refund || settled.SettledAt.Before(record.SettlingSince) {
+			continue
+		}
+		return &x402core.SettleResponse{
+			Success:     true,
+			Payer:       settled.Payer,
+			Transaction: settled.Transaction,
+			Network:     x402core.Network(settled.Network),
+			Amount:      settled.Amount,
+		}, nil
+	}
+	return nil, nil
+}
+
+// completeSettled runs the business logic for a payment that has already
+// settled and completes the task with receipt. The result of an earlier run,
+// if there was one, was lost with the process.
+func (o *BusinessOrchestrator) completeSettled(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	eventQueue eventqueue.Queue,
+	paymentState *state.PaymentState,
+	receipt *x402core.SettleResponse,
+) (*state.PaymentState, error) {
+	matchedRequirement, err := o.findMatchingRequirement(paymentState)
+	if err != nil {
+		return o.failPayment(ctx, requestContext, task, eventQueue, paymentState, err, x402pkg.ErrorCodePayloadMismatch, receipt)
+	}
+	prompt := o.originalPrompt(ctx, task)
+	if prompt == "" {
+		return o.failPayment(ctx, requestContext, task, eventQueue, paymentState,
+			errors.New("prompt is required: original prompt not found for task"), x402pkg.ErrorCodeInternal, receipt)
+	}
+	if receipt.Payer != "" {
+		paymentState.Payer = receipt.Payer
+	}
+	if err := o.transitionToExecuting(ctx, requestContext, task, eventQueue); err != nil {
+		return nil, fmt.Errorf("failed to write executing event: %w", err)
+	}
+	businessResult, err := o.executePaidRequest(ctx, requestContext, eventQueue,
+		paidRequest(requestContext, task, paymentState, matchedRequirement, prompt))
+	if err != nil {
+		return o.failPayment(ctx, requestContext, task, eventQueue, paymentState, err, businessErrorCode(err), receipt)
+	}
+	return o.paidResult(ctx, requestContext, task, eventQueue, businessResult, receipt)
+}
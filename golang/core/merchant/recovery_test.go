@@ -0,0 +1,328 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+var recoveryNetworks = []types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}}
+
+// interruptPayment pays for taskID on a merchant whose process dies after
+// verification: inside the business logic, or inside settlement when
+// duringSettlement is set. It returns the task as it was when the merchant
+// died.
+func interruptPayment(t *testing.T, taskID a2a.TaskID, duringSettlement bool, opts ...Option) *a2a.Task {
+	t.Helper()
+	died := func() {
+		// Unwinds the execution without letting it finish, as a crash
+		// would.
+		runtime.Goexit()
+	}
+	before := newTestOrchestrator(
+		t,
+		&MockResourceServer{
+			SettlePaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.SettleResponse, error) {
+				if duringSettlement {
+					died()
+				}
+				return &x402core.SettleResponse{Success: true, Network: x402.NetworkBaseSepolia, Transaction: "0xbefore"}, nil
+			},
+		},
+		&mockBusinessService{executeFunc: func(ctx context.Context, request business.Request) (*business.Result, error) {
+			if request.PaymentVerified && !duringSettlement {
+				died()
+			}
+			return (&mockBusinessService{}).Execute(ctx, request)
+		}},
+		recoveryNetworks,
+		opts...,
+	)
+
+	task, submission := quotePayment(t, before, taskID)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = submitPayment(before, task, submission, &mockEventQueue{})
+		t.Error("paid Execute() returned, want the merchant to die mid-payment")
+	}()
+	<-done
+	if task.Status.State.Terminal() {
+		t.Fatalf("task state = %s after the merchant died, want it unfinished", task.Status.State)
+	}
+	return task
+}
+
+// countingServer counts the facilitator calls a restarted merchant makes.
+type countingServer struct {
+	verified atomic.Int32
+	settled  atomic.Int32
+	invalid  bool
+}
+
+func (c *countingServer) server() *MockResourceServer {
+	return &MockResourceServer{
+		VerifyPaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.VerifyResponse, error) {
+			c.verified.Add(1)
+			if c.invalid {
+				return &x402core.VerifyResponse{IsValid: false, InvalidReason: "nonce_already_used"}, nil
+			}
+			return &x402core.VerifyResponse{IsValid: true, Payer: "0x789"}, nil
+		},
+		SettlePaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.SettleResponse, error) {
+			c.settled.Add(1)
+			return &x402core.SettleResponse{Success: true, Network: x402.NetworkBaseSepolia, Transaction: "0xafter", Payer: "0x789"}, nil
+		},
+	}
+}
+
+func newStateStore(t *testing.T) *FilePaymentStateStore {
+	t.Helper()
+	store, err := NewFilePaymentStateStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFilePaymentStateStore() error = %v", err)
+	}
+	return store
+}
+
+func recoveredTask(t *testing.T, tasks *memoryTaskStore, taskID a2a.TaskID) *a2a.Task {
+	t.Helper()
+	task, _, err := tasks.Get(context.Background(), taskID)
+	if err != nil {
+		t.Fatalf("task store Get() error = %v", err)
+	}
+	return task
+}
+
+func errorCodeOf(task *a2a.Task) string {
+	if task.Status.Message == nil {
+		return ""
+	}
+	code, _ := task.Status.Message.Meta()[x402.MetadataKeyError].(string)
+	return code
+}
+
+func TestRecover_SettlesVerifiedPayment(t *testing.T) {
+	ctx := context.Background()
+	stateStore := newStateStore(t)
+	task := interruptPayment(t, "task-recover-verified", false, WithPaymentStateStore(stateStore))
+	tasks := &memoryTaskStore{}
+	if _, err := tasks.Save(ctx, task, nil, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	facilitator := &countingServer{}
+	var businessRequest business.Request
+	after := newTestOrchestrator(
+		t,
+		facilitator.server(),
+		&mockBusinessService{executeFunc: func(ctx context.Context, request business.Request) (*business.Result, error) {
+			businessRequest = request
+			return &business.Result{Message: "rendered"}, nil
+		}},
+		recoveryNetworks,
+		WithPaymentStateStore(stateStore),
+		WithRecovery(RecoveryConfig{Tasks: tasks}),
+	)
+	if err := after.Recover(ctx); err != nil {
+		t.Fatalf("Recover() error = %v", err)
+	}
+
+	recovered := recoveredTask(t, tasks, task.ID)
+	if recovered.Status.State != a2a.TaskStateCompleted {
+		t.Fatalf("task state = %s, want completed", recovered.Status.State)
+	}
+	receipts, err := x402state.ExtractPaymentReceipts(recovered)
+	if err != nil || len(receipts) != 1 || receipts[0].Transaction != "0xafter" {
+		t.Errorf("receipts = %+v, %v, want the settlement after restart", receipts, err)
+	}
+	if got := facilitator.verified.Load(); got != 1 {
+		t.Errorf("verified %d times after restart, want 1", got)
+	}
+	if got := facilitator.settled.Load(); got != 1 {
+		t.Errorf("settled %d times after restart, want 1", got)
+	}
+	if !businessRequest.PaymentVerified || businessRequest.Prompt != "render" {
+		t.Errorf("business request = %+v, want the paid call with the original prompt", businessRequest)
+	}
+	if _, found, _ := stateStore.LoadState(ctx, task.ID); found {
+		t.Error("payment record should be deleted once the task completes")
+	}
+}
+
+func TestRecover_FailsPaymentNoLongerValid(t *testing.T) {
+	ctx := context.Background()
+	stateStore := newStateStore(t)
+	task := interruptPayment(t, "task-recover-invalid", false, WithPaymentStateStore(stateStore))
+	tasks := &memoryTaskStore{}
+	if _, err := tasks.Save(ctx, task, nil, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	facilitator := &countingServer{invalid: true}
+	after := newTestOrchestrator(t, facilitator.server(), &mockBusinessService{}, recoveryNetworks,
+		WithPaymentStateStore(stateStore), WithRecovery(RecoveryConfig{Tasks: tasks}))
+	if err := after.Recover(ctx); err != nil {
+		t.Fatalf("Recover() error = %v", err)
+	}
+
+	recovered := recoveredTask(t, tasks, task.ID)
+	if recovered.Status.State != a2a.TaskStateFailed {
+		t.Fatalf("task state = %s, want failed", recovered.Status.State)
+	}
+	if code := errorCodeOf(recovered); code != x402.ErrorCodeInvalidSignature {
+		t.Errorf("error code = %q, want %q", code, x402.ErrorCodeInvalidSignature)
+	}
+	if got := facilitator.settled.Load(); got != 0 {
+		t.Errorf("settled %d times, want a payment that no longer verifies left alone", got)
+	}
+}
+
+func TestRecover_ReconcilesInterruptedSettlement(t *testing.T) {
+	tests := []struct {
+		name      string
+		recorded  bool
+		wantState a2a.TaskState
+		wantCode  string
+	}{
+		{name: "receipt recorded", recorded: true, wantState: a2a.TaskStateCompleted},
+		{name: "no receipt", wantState: a2a.TaskStateFailed, wantCode: x402.ErrorCodeSettleTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			stateStore := newStateStore(t)
+			receipts := NewMemoryReceiptStore()
+			task := interruptPayment(t, "task-recover-settling", true, WithPaymentStateStore(stateStore), WithReceiptStore(receipts))
+			record, found, err := stateStore.LoadState(ctx, task.ID)
+			if err != nil || !found || record.SettlingSince.IsZero() {
+				t.Fatalf("persisted record = %+v, found = %v, error = %v, want the settlement start", record, found, err)
+			}
+			if tt.recorded {
+				err := receipts.Append(ctx, &ReceiptRecord{
+					TaskID:      task.ID,
+					Payer:       "0x789",
+					Network:     x402.NetworkBaseSepolia,
+					Amount:      "1000000",
+					Transaction: "0xbefore",
+					SettledAt:   time.Now(),
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			tasks := &memoryTaskStore{}
+			if _, err := tasks.Save(ctx, task, nil, 0); err != nil {
+				t.Fatal(err)
+			}
+
+			facilitator := &countingServer{}
+			after := newTestOrchestrator(t, facilitator.server(), &mockBusinessService{}, recoveryNetworks,
+				WithPaymentStateStore(stateStore), WithReceiptStore(receipts),
+				WithRecovery(RecoveryConfig{Tasks: tasks}))
+			if err := after.Recover(ctx); err != nil {
+				t.Fatalf("Recover() error = %v", err)
+			}
+
+			recovered := recoveredTask(t, tasks, task.ID)
+			if recovered.Status.State != tt.wantState {
+				t.Fatalf("task state = %s, want %s", recovered.Status.State, tt.wantState)
+			}
+			if code := errorCodeOf(recovered); code != tt.wantCode {
+				t.Errorf("error code = %q, want %q", code, tt.wantCode)
+			}
+			if tt.recorded {
+				taskReceipts, err := x402state.ExtractPaymentReceipts(recovered)
+				if err != nil || len(taskReceipts) != 1 || taskReceipts[0].Transaction != "0xbefore" {
+					t.Errorf("receipts = %+v, %v, want the recorded settlement", taskReceipts, err)
+				}
+			} else if recovered.Status.Message.Metadata[x402.MetadataKeyIndeterminate] != true {
+				t.Error("task is not marked indeterminate")
+			}
+			if got := facilitator.settled.Load(); got != 0 {
+				t.Errorf("settled %d times after restart, want the interrupted settlement never repeated", got)
+			}
+		})
+	}
+}
+
+func TestBusinessOrchestrator_Execute_ReconcilesInterruptedSettlement(t *testing.T) {
+	ctx := context.Background()
+	stateStore := newStateStore(t)
+	task := interruptPayment(t, "task-lazy-settling", true, WithPaymentStateStore(stateStore))
+
+	facilitator := &countingServer{}
+	after := newTestOrchestrator(t, facilitator.server(), &mockBusinessService{}, recoveryNetworks,
+		WithPaymentStateStore(stateStore))
+	err := after.executeInPlace(ctx, &a2asrv.RequestContext{
+		Message:    a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "status?"}),
+		StoredTask: task,
+		TaskID:     task.ID,
+		ContextID:  task.ContextID,
+	}, &mockEventQueue{})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if task.Status.State != a2a.TaskStateFailed || errorCodeOf(task) != x402.ErrorCodeSettleTimeout {
+		t.Errorf("task = %s with %q, want failed with %q", task.Status.State, errorCodeOf(task), x402.ErrorCodeSettleTimeout)
+	}
+	if got := facilitator.settled.Load(); got != 0 {
+		t.Errorf("settled %d times, want the interrupted settlement never repeated", got)
+	}
+}
+
+func TestWithRecovery_RequiresTaskStoreAndLister(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr string
+	}{
+		{
+			name:    "no task store",
+			opts:    []Option{WithPaymentStateStore(NewMemoryPaymentStateStore()), WithRecovery(RecoveryConfig{})},
+			wantErr: "task store",
+		},
+		{
+			name:    "no payment state store",
+			opts:    []Option{WithRecovery(RecoveryConfig{Tasks: &memoryTaskStore{}})},
+			wantErr: "PaymentStateLister",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewBusinessOrchestrator(context.Background(), &mockBusinessService{}, recoveryNetworks,
+				append([]Option{WithPaymentServer(&MockResourceServer{})}, tt.opts...)...)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("NewBusinessOrchestrator() error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
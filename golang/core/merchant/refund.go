@@ -0,0 +1,489 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/token"
+	"github.com/gagliardetto/solana-go/rpc"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	svmutils "github.com/x402-foundation/x402/go/mechanisms/svm"
+)
+
+// RefundRequest is a transfer back to the payer of a settled task. Amount is
+// in the asset's base units.
+type RefundRequest struct {
+	TaskID  a2a.TaskID
+	Payer   string
+	Network string
+	Asset   string
+	Amount  string
+	Reason  string
+}
+
+// RefundExecutor sends refunds. Implementations must be safe for concurrent
+// use.
+type RefundExecutor interface {
+	// Refund transfers request.Amount to request.Payer and returns the
+	// transaction reference, or "" when there is none to record.
+	Refund(ctx context.Context, request *RefundRequest) (string, error)
+}
+
+// RefundExecutorFunc adapts a function to the RefundExecutor interface.
+type RefundExecutorFunc func(ctx context.Context, request *RefundRequest) (string, error)
+
+func (f RefundExecutorFunc) Refund(ctx context.Context, request *RefundRequest) (string, error) {
+	return f(ctx, request)
+}
+
+// ManualRefunds moves no funds. It records refunds an operator sends by other
+// means, such as from a custodial wallet.
+type ManualRefunds struct{}
+
+func (ManualRefunds) Refund(ctx context.Context, request *RefundRequest) (string, error) {
+	return "", nil
+}
+
+// RefundConfig configures Merchant.IssueRefund.
+type RefundConfig struct {
+	// Executor sends each refund. It is required.
+	Executor RefundExecutor
+	// Tasks, when set, is the server's task store, where each refund is
+	// appended to the task's metadata.
+	Tasks a2asrv.TaskStore
+	// NotifyClient calls back the push-notification configs the client
+	// registered for the task with the updated task. It requires Tasks and
+	// WithPushNotifier.
+	NotifyClient bool
+}
+
+// WithRefunds lets the merchant refund settled payments with IssueRefund.
+// Refunds are recorded in the receipt store, which is required, and are
+// checked against it so that a task is never refunded more than it paid.
+func WithRefunds(config RefundConfig) Option {
+	return func(o *BusinessOrchestrator) {
+		o.refunds = &config
+	}
+}
+
+// validateRefunds rejects a refund configuration that could not record or
+// deliver its refunds.
+func (o *BusinessOrchestrator) validateRefunds() error {
+	if o.refunds == nil {
+		return nil
+	}
+	switch {
+	case o.refunds.Executor == nil:
+		return errors.New("refunds require a refund executor")
+	case o.receipts == nil:
+		return errors.New("refunds require a receipt store")
+	case o.refunds.NotifyClient && (o.refunds.Tasks == nil || o.push == nil):
+		return errors.New("refund notifications require a task store and a push notifier")
+	}
+	return nil
+}
+
+// refundablePayment is what a task paid and has had refunded so far, in one
+// asset on one network.
+type refundablePayment struct {
+	payer    string
+	network  string
+	asset    string
+	paid     *big.Int
+	refunded *big.Int
+}
+
+// IssueRefund returns amount, in the asset's base units, to the payer of the
+// task's settled payment, or whatever is left of it when amount is empty. A
+// task may be refunded in parts, but never beyond what it paid. The refund
+// is appended to the receipt store and, when configured, to the task's
+// metadata, from where the client is notified.
+//
+// When the refund was sent but could not be recorded in the receipt store,
+// IssueRefund returns the receipt together with the error, and the refund
+// has to be recorded by hand before the task is refunded again.
+func (o *BusinessOrchestrator) IssueRefund(ctx context.Context, taskID a2a.TaskID, amount string, reason string) (*state.RefundReceipt, error) {
+	if o.refunds == nil || o.refunds.Executor == nil {
+		return nil, errors.New("merchant does not issue refunds")
+	}
+	if o.receipts == nil {
+		return nil, errors.New("refunds require a receipt store")
+	}
+	unlock, err := o.taskLocks.lock(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	payment, err := o.refundablePayment(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	remaining := new(big.Int).Sub(payment.paid, payment.refunded)
+	if remaining.Sign() <= 0 {
+		return nil, fmt.Errorf("task %s is already fully refunded", taskID)
+	}
+	value := remaining
+	if amount = strings.TrimSpace(amount); amount != "" {
+		parsed, ok := new(big.Int).SetString(amount, 10)
+		if !ok || parsed.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid refund amount %q", amount)
+		}
+		if parsed.Cmp(remaining) > 0 {
+			return nil, fmt.Errorf("refund of %s exceeds the %s left to refund on task %s", parsed, remaining, taskID)
+		}
+		value = parsed
+	}
+
+	request := &RefundRequest{
+		TaskID:  taskID,
+		Payer:   payment.payer,
+		Network: payment.network,
+		Asset:   payment.asset,
+		Amount:  value.String(),
+		Reason:  reason,
+	}
+	transaction, err := o.refunds.Executor.Refund(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refund task %s: %w", taskID, err)
+	}
+	refund := &state.RefundReceipt{
+		TaskID:      taskID,
+		Payer:       request.Payer,
+		Network:     request.Network,
+		Asset:       request.Asset,
+		Amount:      request.Amount,
+		Reason:      reason,
+		Transaction: transaction,
+		IssuedAt:    o.now().UTC(),
+	}
+	// The funds have moved, so the refund is recorded even if the caller has
+	// given up.
+	ctx = context.WithoutCancel(ctx)
+	o.logger.InfoContext(ctx, "x402 payment refunded",
+		"task_id", taskID,
+		"network", refund.Network,
+		"amount", refund.Amount,
+		"transaction", refund.Transaction,
+	)
+	if err := o.recordRefund(ctx, refund); err != nil {
+		return refund, err
+	}
+	o.notifyRefund(ctx, refund)
+	return refund, nil
+}
+
+// refundablePayment sums the task's receipts in the asset of its first
+// payment.
+func (o *BusinessOrchestrator) refundablePayment(ctx context.Context, taskID a2a.TaskID) (*refundablePayment, error) {
+	records, err := o.receipts.GetByTask(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read receipts of task %s: %w", taskID, err)
+	}
+	var payment *refundablePayment
+	for _, record := range records {
+		if payment == nil && !record.Refund {
+			payment = &refundablePayment{
+				payer:    record.Payer,
+				network:  record.Network,
+				asset:    record.Asset,
+				paid:     new(big.Int),
+				refunded: new(big.Int),
+			}
+		}
+	}
+	if payment == nil {
+		return nil, fmt.Errorf("task %s has no settled payment to refund", taskID)
+	}
+	if payment.payer == "" {
+		return nil, fmt.Errorf("payment of task %s has no payer to refund", taskID)
+	}
+	for _, record := range records {
+		if record.Network != payment.network || !strings.EqualFold(record.Asset, payment.asset) {
+			continue
+		}
+		value, ok := new(big.Int).SetString(record.Amount, 10)
+		if !ok || value.Sign() < 0 {
+			return nil, fmt.Errorf("receipt %s for task %s has invalid amount %q", record.Transaction, taskID, record.Amount)
+		}
+		if record.Refund {
+			payment.refunded.Add(payment.refunded, value)
+		} else {
+			payment.paid.Add(payment.paid, value)
+		}
+	}
+	return payment, nil
+}
+
+// recordRefund appends the refund to the receipt store and to the task in
+// the configured task store. Only the receipt store guards against refunding
+// twice, so only a failure to append there is returned.
+func (o *BusinessOrchestrator) recordRefund(ctx context.Context, refund *state.RefundReceipt) error {
+	record := &ReceiptRecord{
+		TaskID:      refund.TaskID,
+		Payer:       refund.Payer,
+		Network:     refund.Network,
+		Asset:       refund.Asset,
+		Amount:      refund.Amount,
+		Transaction: refund.Transaction,
+		Refund:      true,
+		Reason:      refund.Reason,
+		SettledAt:   refund.IssuedAt,
+	}
+	if err := o.receipts.Append(ctx, record); err != nil {
+		o.logger.ErrorContext(ctx, "x402 refund not recorded",
+			"task_id", refund.TaskID,
+			"transaction", refund.Transaction,
+			"error", err,
+		)
+		return fmt.Errorf("refund of task %s sent but not recorded: %w", refund.TaskID, err)
+	}
+	if o.refunds.Tasks == nil {
+		return nil
+	}
+	if err := appendTaskRefund(ctx, o.refunds.Tasks, refund); err != nil {
+		o.logger.WarnContext(ctx, "x402 refund not recorded on task",
+			"task_id", refund.TaskID,
+			"error", err,
+		)
+	}
+	return nil
+}
+
+func appendTaskRefund(ctx context.Context, tasks a2asrv.TaskStore, refund *state.RefundReceipt) error {
+	task, version, err := tasks.Get(ctx, refund.TaskID)
+	if err != nil {
+		return fmt.Errorf("failed to load task: %w", err)
+	}
+	message := snapshotMessage(task.Status.Message)
+	if message == nil {
+		message = a2a.NewMessage(a2a.MessageRoleAgent)
+	}
+	if err := state.AppendRefund(message, refund); err != nil {
+		return err
+	}
+	task.Status.Message = message
+	event := a2a.NewStatusUpdateEvent(task, task.Status.State, message)
+	if _, err := tasks.Save(ctx, task, event, version); err != nil {
+		return fmt.Errorf("failed to save task: %w", err)
+	}
+	return nil
+}
+
+// notifyRefund calls back the client's push configs with the refunded task.
+func (o *BusinessOrchestrator) notifyRefund(ctx context.Context, refund *state.RefundReceipt) {
+	if !o.refunds.NotifyClient || o.refunds.Tasks == nil || o.push == nil {
+		return
+	}
+	task, _, err := o.refunds.Tasks.Get(ctx, refund.TaskID)
+	if err != nil {
+		o.logger.WarnContext(ctx, "x402 refund notification not sent",
+			"task_id", refund.TaskID,
+			"error", err,
+		)
+		return
+	}
+	configs, err := o.push.notifier.Store.List(ctx, refund.TaskID)
+	if err != nil {
+		o.logger.WarnContext(ctx, "x402 refund notification not sent",
+			"task_id", refund.TaskID,
+			"error", err,
+		)
+		return
+	}
+	for _, config := range configs {
+		_ = o.push.SendPush(ctx, config, task)
+	}
+}
+
+// erc20TransferSelector is the selector of transfer(address,uint256).
+var erc20TransferSelector = crypto.Keccak256([]byte("transfer(address,uint256)"))[:4]
+
+// OnChainRefunds sends refunds from the merchant's own wallet: an ERC-20
+// transfer on EVM networks and an SPL Token transfer between associated token
+// accounts on Solana. The wallet pays the fees and must hold the refunded
+// asset.
+type OnChainRefunds struct {
+	// RPCURLs are JSON-RPC endpoints per CAIP-2 network. They are required
+	// for EVM networks; Solana networks default to the public endpoints.
+	RPCURLs map[string]string
+	// EVMPrivateKey is the hex key refunds on EVM networks are sent from.
+	EVMPrivateKey string
+	// SolanaPrivateKey is the base58 key refunds on Solana are sent from.
+	SolanaPrivateKey string
+
+	// mu keeps two EVM refunds from taking the same nonce.
+	mu sync.Mutex
+}
+
+func (r *OnChainRefunds) Refund(ctx context.Context, request *RefundRequest) (string, error) {
+	network := x402pkg.NormalizeNetwork(request.Network)
+	switch {
+	case x402pkg.IsEVMNetwork(network):
+		return r.refundEVM(ctx, network, request)
+	case x402pkg.IsSolanaNetwork(network):
+		return r.refundSolana(ctx, network, request)
+	}
+	return "", fmt.Errorf("refunds on %s are not supported", request.Network)
+}
+
+func (r *OnChainRefunds) rpcURL(network string) string {
+	for name, url := range r.RPCURLs {
+		if x402pkg.NormalizeNetwork(name) == network {
+			return url
+		}
+	}
+	return ""
+}
+
+func (r *OnChainRefunds) refundEVM(ctx context.Context, network string, request *RefundRequest) (string, error) {
+	url := r.rpcURL(network)
+	if url == "" {
+		return "", fmt.Errorf("no EVM RPC endpoint configured for %s", network)
+	}
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(r.EVMPrivateKey, "0x"))
+	if err != nil {
+		return "", fmt.Errorf("invalid EVM refund key: %w", err)
+	}
+	if !common.IsHexAddress(request.Payer) || !common.IsHexAddress(request.Asset) {
+		return "", fmt.Errorf("invalid payer %q or asset %q", request.Payer, request.Asset)
+	}
+	amount, ok := new(big.Int).SetString(request.Amount, 10)
+	if !ok {
+		return "", fmt.Errorf("invalid refund amount %q", request.Amount)
+	}
+	data := append([]byte{}, erc20TransferSelector...)
+	data = append(data, common.LeftPadBytes(common.HexToAddress(request.Payer).Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(amount.Bytes(), 32)...)
+
+	client, err := ethclient.DialContext(ctx, url)
+	if err != nil {
+		return "", fmt.Errorf("failed to dial RPC endpoint for %s: %w", network, err)
+	}
+	defer client.Close()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	asset := common.HexToAddress(request.Asset)
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to read chain ID of %s: %w", network, err)
+	}
+	nonce, err := client.PendingNonceAt(ctx, from)
+	if err != nil {
+		return "", fmt.Errorf("failed to read nonce of %s: %w", from, err)
+	}
+	gasPrice, err := client.SuggestGasPrice(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to read gas price on %s: %w", network, err)
+	}
+	gas, err := client.EstimateGas(ctx, ethereum.CallMsg{From: from, To: &asset, Data: data})
+	if err != nil {
+		return "", fmt.Errorf("failed to estimate refund gas: %w", err)
+	}
+	tx, err := ethtypes.SignTx(
+		ethtypes.NewTransaction(nonce, asset, new(big.Int), gas, gasPrice, data),
+		ethtypes.LatestSignerForChainID(chainID),
+		key,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign refund: %w", err)
+	}
+	if err := client.SendTransaction(ctx, tx); err != nil {
+		return "", fmt.Errorf("failed to send refund: %w", err)
+	}
+	return tx.Hash().Hex(), nil
+}
+
+func (r *OnChainRefunds) refundSolana(ctx context.Context, network string, request *RefundRequest) (string, error) {
+	url := r.rpcURL(network)
+	if url == "" {
+		config, err := svmutils.GetNetworkConfig(network)
+		if err != nil {
+			return "", err
+		}
+		url = config.RPCURL
+	}
+	key, err := solana.PrivateKeyFromBase58(r.SolanaPrivateKey)
+	if err != nil {
+		return "", fmt.Errorf("invalid Solana refund key: %w", err)
+	}
+	payer, err := solana.PublicKeyFromBase58(request.Payer)
+	if err != nil {
+		return "", fmt.Errorf("invalid payer %q: %w", request.Payer, err)
+	}
+	mint, err := solana.PublicKeyFromBase58(request.Asset)
+	if err != nil {
+		return "", fmt.Errorf("invalid asset %q: %w", request.Asset, err)
+	}
+	amount, err := strconv.ParseUint(request.Amount, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid refund amount %q: %w", request.Amount, err)
+	}
+	owner := key.PublicKey()
+	source, _, err := solana.FindAssociatedTokenAddress(owner, mint)
+	if err != nil {
+		return "", fmt.Errorf("failed to derive refund source account: %w", err)
+	}
+	destination, _, err := solana.FindAssociatedTokenAddress(payer, mint)
+	if err != nil {
+		return "", fmt.Errorf("failed to derive payer token account: %w", err)
+	}
+
+	client := rpc.New(url)
+	blockhash, err := client.GetLatestBlockhash(ctx, rpc.CommitmentFinalized)
+	if err != nil {
+		return "", fmt.Errorf("failed to read latest blockhash on %s: %w", network, err)
+	}
+	tx, err := solana.NewTransaction(
+		[]solana.Instruction{token.NewTransferInstruction(amount, source, destination, owner, nil).Build()},
+		blockhash.Value.Blockhash,
+		solana.TransactionPayer(owner),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to build refund: %w", err)
+	}
+	_, err = tx.Sign(func(signer solana.PublicKey) *solana.PrivateKey {
+		if signer.Equals(owner) {
+			return &key
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to sign refund: %w", err)
+	}
+	signature, err := client.SendTransaction(ctx, tx)
+	if err != nil {
+		return "", fmt.Errorf("failed to send refund: %w", err)
+	}
+	return signature.String(), nil
+}
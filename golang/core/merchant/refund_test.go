@@ -0,0 +1,319 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+)
+
+const refundTaskID a2a.TaskID = "task-refund"
+
+// fakeRefunds records every refund request and answers with a numbered
+// transaction, or with err when set.
+type fakeRefunds struct {
+	mu       sync.Mutex
+	requests []*RefundRequest
+	err      error
+}
+
+func (f *fakeRefunds) Refund(ctx context.Context, request *RefundRequest) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return "", f.err
+	}
+	f.requests = append(f.requests, request)
+	return fmt.Sprintf("0xrefund%d", len(f.requests)), nil
+}
+
+// refundOrchestrator issues refunds through executor against a receipt store
+// holding a 1000 unit payment for refundTaskID.
+func refundOrchestrator(t *testing.T, executor RefundExecutor, config RefundConfig) (*BusinessOrchestrator, *MemoryReceiptStore) {
+	t.Helper()
+	store := NewMemoryReceiptStore()
+	err := store.Append(context.Background(), &ReceiptRecord{
+		TaskID:      refundTaskID,
+		Payer:       "0xPayer",
+		Network:     x402.NetworkBaseSepolia,
+		Asset:       "0xAsset",
+		Amount:      "1000",
+		Transaction: "0xpayment",
+		SettledAt:   subscriptionNow,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Executor = executor
+	orchestrator := newTestOrchestrator(
+		t,
+		&MockResourceServer{},
+		&mockBusinessService{},
+		[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+		WithReceiptStore(store),
+		WithRefunds(config),
+		WithClock(func() time.Time { return subscriptionNow }),
+	)
+	return orchestrator, store
+}
+
+func refundRecords(t *testing.T, store *MemoryReceiptStore) []*ReceiptRecord {
+	t.Helper()
+	records, err := store.GetByTask(context.Background(), refundTaskID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var refunds []*ReceiptRecord
+	for _, record := range records {
+		if record.Refund {
+			refunds = append(refunds, record)
+		}
+	}
+	return refunds
+}
+
+func TestIssueRefund_Full(t *testing.T) {
+	executor := &fakeRefunds{}
+	orchestrator, store := refundOrchestrator(t, executor, RefundConfig{})
+
+	refund, err := orchestrator.IssueRefund(context.Background(), refundTaskID, "", "service unavailable")
+	if err != nil {
+		t.Fatalf("IssueRefund() error = %v", err)
+	}
+	want := &x402state.RefundReceipt{
+		TaskID:      refundTaskID,
+		Payer:       "0xPayer",
+		Network:     x402.NetworkBaseSepolia,
+		Asset:       "0xAsset",
+		Amount:      "1000",
+		Reason:      "service unavailable",
+		Transaction: "0xrefund1",
+		IssuedAt:    subscriptionNow.UTC(),
+	}
+	if *refund != *want {
+		t.Errorf("IssueRefund() = %+v, want %+v", refund, want)
+	}
+	if len(executor.requests) != 1 || executor.requests[0].Payer != "0xPayer" || executor.requests[0].Amount != "1000" {
+		t.Errorf("executor requests = %+v, want one of 1000 to 0xPayer", executor.requests)
+	}
+	records := refundRecords(t, store)
+	if len(records) != 1 || records[0].Amount != "1000" || records[0].Reason != "service unavailable" || records[0].Transaction != "0xrefund1" {
+		t.Errorf("refund records = %+v, want the refund of 1000", records)
+	}
+}
+
+func TestIssueRefund_Partial(t *testing.T) {
+	executor := &fakeRefunds{}
+	orchestrator, store := refundOrchestrator(t, executor, RefundConfig{})
+	ctx := context.Background()
+
+	if _, err := orchestrator.IssueRefund(ctx, refundTaskID, "400", "partial delivery"); err != nil {
+		t.Fatalf("IssueRefund(400) error = %v", err)
+	}
+	if _, err := orchestrator.IssueRefund(ctx, refundTaskID, "700", ""); err == nil || !strings.Contains(err.Error(), "exceeds the 600 left") {
+		t.Fatalf("IssueRefund(700) error = %v, want the 600 left", err)
+	}
+	refund, err := orchestrator.IssueRefund(ctx, refundTaskID, "", "")
+	if err != nil {
+		t.Fatalf("IssueRefund(rest) error = %v", err)
+	}
+	if refund.Amount != "600" {
+		t.Errorf("remaining refund = %s, want 600", refund.Amount)
+	}
+	if len(executor.requests) != 2 {
+		t.Errorf("executor called %d times, want 2", len(executor.requests))
+	}
+	if records := refundRecords(t, store); len(records) != 2 {
+		t.Errorf("refund records = %+v, want 2", records)
+	}
+}
+
+func TestIssueRefund_RejectsDuplicate(t *testing.T) {
+	executor := &fakeRefunds{}
+	orchestrator, store := refundOrchestrator(t, executor, RefundConfig{})
+	ctx := context.Background()
+
+	if _, err := orchestrator.IssueRefund(ctx, refundTaskID, "1000", ""); err != nil {
+		t.Fatalf("IssueRefund() error = %v", err)
+	}
+	for _, amount := range []string{"1000", "1", ""} {
+		if _, err := orchestrator.IssueRefund(ctx, refundTaskID, amount, ""); err == nil || !strings.Contains(err.Error(), "already fully refunded") {
+			t.Errorf("IssueRefund(%q) again error = %v, want already fully refunded", amount, err)
+		}
+	}
+	if len(executor.requests) != 1 {
+		t.Errorf("executor called %d times, want 1", len(executor.requests))
+	}
+	if records := refundRecords(t, store); len(records) != 1 {
+		t.Errorf("refund records = %+v, want 1", records)
+	}
+}
+
+func TestIssueRefund_Concurrent(t *testing.T) {
+	executor := &fakeRefunds{}
+	orchestrator, _ := refundOrchestrator(t, executor, RefundConfig{})
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = orchestrator.IssueRefund(context.Background(), refundTaskID, "", "")
+		}()
+	}
+	wg.Wait()
+	if len(executor.requests) != 1 {
+		t.Errorf("executor called %d times, want 1", len(executor.requests))
+	}
+}
+
+func TestIssueRefund_RejectsInvalidRequests(t *testing.T) {
+	tests := []struct {
+		name    string
+		taskID  a2a.TaskID
+		amount  string
+		wantErr string
+	}{
+		{name: "unpaid task", taskID: "task-unpaid", wantErr: "no settled payment"},
+		{name: "zero", taskID: refundTaskID, amount: "0", wantErr: "invalid refund amount"},
+		{name: "negative", taskID: refundTaskID, amount: "-5", wantErr: "invalid refund amount"},
+		{name: "not a number", taskID: refundTaskID, amount: "1.5", wantErr: "invalid refund amount"},
+		{name: "more than paid", taskID: refundTaskID, amount: "1001", wantErr: "exceeds"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := &fakeRefunds{}
+			orchestrator, _ := refundOrchestrator(t, executor, RefundConfig{})
+			_, err := orchestrator.IssueRefund(context.Background(), tt.taskID, tt.amount, "")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("IssueRefund() error = %v, want one containing %q", err, tt.wantErr)
+			}
+			if len(executor.requests) != 0 {
+				t.Errorf("executor called for a rejected refund: %+v", executor.requests)
+			}
+		})
+	}
+}
+
+func TestIssueRefund_ExecutorFailureRecordsNothing(t *testing.T) {
+	executor := &fakeRefunds{err: errors.New("rpc down")}
+	orchestrator, store := refundOrchestrator(t, executor, RefundConfig{})
+
+	if _, err := orchestrator.IssueRefund(context.Background(), refundTaskID, "", ""); err == nil || !strings.Contains(err.Error(), "rpc down") {
+		t.Fatalf("IssueRefund() error = %v, want the executor's", err)
+	}
+	if records := refundRecords(t, store); len(records) != 0 {
+		t.Fatalf("refund records = %+v, want none", records)
+	}
+
+	executor.err = nil
+	if refund, err := orchestrator.IssueRefund(context.Background(), refundTaskID, "", ""); err != nil || refund.Amount != "1000" {
+		t.Fatalf("IssueRefund() after recovery = %+v, %v, want the full refund", refund, err)
+	}
+}
+
+func TestIssueRefund_RecordsOnTask(t *testing.T) {
+	tasks := &memoryTaskStore{}
+	message := a2a.NewMessage(a2a.MessageRoleAgent)
+	x402state.SetPaymentStatus(message, x402state.PaymentCompleted)
+	task := &a2a.Task{ID: refundTaskID, ContextID: "ctx-refund", Status: a2a.TaskStatus{State: a2a.TaskStateCompleted, Message: message}}
+	if _, err := tasks.Save(context.Background(), task, nil, 0); err != nil {
+		t.Fatal(err)
+	}
+	orchestrator, _ := refundOrchestrator(t, &fakeRefunds{}, RefundConfig{Tasks: tasks})
+
+	for _, amount := range []string{"250", "250"} {
+		if _, err := orchestrator.IssueRefund(context.Background(), refundTaskID, amount, "late"); err != nil {
+			t.Fatalf("IssueRefund(%s) error = %v", amount, err)
+		}
+	}
+	stored, _, err := tasks.Get(context.Background(), refundTaskID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refunds, err := x402state.ExtractRefunds(stored)
+	if err != nil {
+		t.Fatalf("ExtractRefunds() error = %v", err)
+	}
+	if len(refunds) != 2 || refunds[0].Transaction != "0xrefund1" || refunds[1].Transaction != "0xrefund2" || refunds[1].Amount != "250" {
+		t.Errorf("task refunds = %+v, want both refunds of 250", refunds)
+	}
+	if status, _ := x402state.ExtractPaymentStatus(stored); status != x402state.PaymentCompleted {
+		t.Errorf("payment status = %q, want it left %q", status, x402state.PaymentCompleted)
+	}
+}
+
+func TestWithRefunds_RequiresExecutorAndReceiptStore(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr string
+	}{
+		{
+			name:    "no executor",
+			opts:    []Option{WithReceiptStore(NewMemoryReceiptStore()), WithRefunds(RefundConfig{})},
+			wantErr: "refund executor",
+		},
+		{
+			name:    "no receipt store",
+			opts:    []Option{WithRefunds(RefundConfig{Executor: ManualRefunds{}})},
+			wantErr: "receipt store",
+		},
+		{
+			name: "notify without push",
+			opts: []Option{
+				WithReceiptStore(NewMemoryReceiptStore()),
+				WithRefunds(RefundConfig{Executor: ManualRefunds{}, Tasks: &memoryTaskStore{}, NotifyClient: true}),
+			},
+			wantErr: "push notifier",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewBusinessOrchestrator(context.Background(), &mockBusinessService{},
+				[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+				append([]Option{WithPaymentServer(&MockResourceServer{})}, tt.opts...)...)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("NewBusinessOrchestrator() error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAnalytics_LeavesOutRefunds(t *testing.T) {
+	orchestrator, store := refundOrchestrator(t, ManualRefunds{}, RefundConfig{})
+	if _, err := orchestrator.IssueRefund(context.Background(), refundTaskID, "300", ""); err != nil {
+		t.Fatal(err)
+	}
+	totals, err := NewAnalytics(store, nil).TotalsByAsset(context.Background(), TimeRange{From: subscriptionNow.Add(-time.Hour), To: subscriptionNow.Add(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(totals) != 1 || totals[0].Amount != "1000" || totals[0].Receipts != 1 {
+		t.Errorf("TotalsByAsset() = %+v, want the 1000 payment alone", totals)
+	}
+}
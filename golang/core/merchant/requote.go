@@ -0,0 +1,141 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/a2aproject/a2a-go/a2asrv/eventqueue"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+)
+
+// DefaultMaxRequotes bounds how many times an expired quote is reissued on
+// the same task before the submission fails instead.
+const DefaultMaxRequotes = 2
+
+// WithMaxRequotes sets how many times a task's quote may be reissued after
+// the client paid against an expired one. Zero disables re-quoting.
+func WithMaxRequotes(requotes int) Option {
+	return func(o *BusinessOrchestrator) {
+		o.maxRequotes = max(requotes, 0)
+	}
+}
+
+// isQuoteExpired reports whether a verification failure means the client's
+// authorization window lapsed, either caught locally or reported by the
+// facilitator as an expired validBefore or deadline.
+func isQuoteExpired(err error) bool {
+	var windowErr *authorizationWindowError
+	if errors.As(err, &windowErr) {
+		return windowErr.expired
+	}
+	var timeoutErr *facilitatorTimeoutError
+	if errors.As(err, &timeoutErr) {
+		return false
+	}
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "valid_before") || strings.Contains(message, "expired")
+}
+
+// requote replaces an expired quote with a fresh one on the same task so the
+// client can pay again instead of starting over. The first round is quoted
+// by the business service again, picking up current prices; later rounds
+// reissue the stored requirements, which carry no absolute expiry themselves.
+// It reports false when the task has used up its re-quotes or no fresh quote
+// could be built, and the caller fails the payment as before.
+func (o *BusinessOrchestrator) requote(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	eventQueue eventqueue.Queue,
+	paymentState *state.PaymentState,
+	cause error,
+) (bool, error) {
+	requotes := state.ExtractRequoteCount(task)
+	if requotes >= o.maxRequotes {
+		return false, nil
+	}
+
+	round := state.ExtractPaymentRound(task)
+	prompt := o.originalPrompt(ctx, task)
+	skillID := state.ExtractSkillID(task)
+	metadata := requestMetadata(task, requestContext.Message)
+	next := &state.PaymentState{Status: state.PaymentRequired, Requirements: paymentState.Requirements}
+	var discounts []DiscountInfo
+	if round == 1 {
+		_, err := o.businessService.Execute(ctx, business.Request{
+			Prompt:    prompt,
+			SkillID:   skillID,
+			TaskID:    task.ID,
+			ContextID: task.ContextID,
+			Message:   originalMessage(task, requestContext.Message),
+			Metadata:  metadata,
+			Round:     round,
+		})
+		var paymentRequired *business.PaymentRequiredError
+		if !errors.As(err, &paymentRequired) {
+			o.logger.WarnContext(ctx, "x402 re-quote skipped: service did not quote",
+				"task_id", task.ID,
+				"context_id", task.ContextID,
+				"error", err,
+			)
+			return false, nil
+		}
+		paymentRequired, discounts = o.applyDiscounts(ctx, requestContext, task, paymentRequired)
+		if next, err = o.buildPaymentRequirements(ctx, task.ID, paymentRequired, discounts); err != nil {
+			o.logger.WarnContext(ctx, "x402 re-quote failed",
+				"task_id", task.ID,
+				"context_id", task.ContextID,
+				"error", err,
+			)
+			return false, nil
+		}
+	}
+	if next.Requirements == nil {
+		return false, nil
+	}
+
+	receipts, err := state.ExtractPaymentReceipts(task)
+	if err != nil {
+		return true, fmt.Errorf("failed to read payment receipts: %w", err)
+	}
+	message := a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{
+		Text: fmt.Sprintf("The quote expired before payment (%v). Please pay against the refreshed requirements.", cause),
+	})
+	recordFailedAttempt(task, message)
+	task.Status.Message = message
+	if err := state.SetPaymentReceipts(task.Status.Message, receipts); err != nil {
+		return true, fmt.Errorf("failed to record payment receipts: %w", err)
+	}
+	if round > 1 {
+		state.SetPaymentRound(task.Status.Message, round)
+	}
+	state.SetRequoteCount(task.Status.Message, requotes+1)
+	state.SetPaymentError(task.Status.Message, x402pkg.ErrorCodeQuoteExpiredRequote)
+	o.logger.InfoContext(ctx, "x402 quote expired; re-quoted",
+		"task_id", task.ID,
+		"context_id", task.ContextID,
+		"requotes", requotes+1,
+	)
+
+	return true, o.transitionToPaymentRequired(ctx, requestContext, task, eventQueue, next, prompt, skillID, metadata, discounts)
+}
@@ -0,0 +1,181 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+func TestIsQuoteExpired(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "lapsed window", err: &authorizationWindowError{message: "expired", expired: true}, want: true},
+		{name: "window not yet open", err: &authorizationWindowError{message: "not valid until"}, want: false},
+		{name: "facilitator valid_before", err: errors.New("payment verification failed: invalid_exact_evm_payload_authorization_valid_before, "), want: true},
+		{name: "facilitator permit2 deadline", err: errors.New("permit2_deadline_expired"), want: true},
+		{name: "facilitator timeout", err: &facilitatorTimeoutError{operation: "verify", err: errors.New("expired")}, want: false},
+		{name: "bad signature", err: errors.New("invalid_exact_evm_payload_signature"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isQuoteExpired(tt.err); got != tt.want {
+				t.Errorf("isQuoteExpired() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBusinessOrchestrator_Execute_RequotesExpiredQuote(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	authorization := func(validBefore time.Time) map[string]interface{} {
+		return map[string]interface{}{
+			"signature": "0xabc",
+			"authorization": map[string]interface{}{
+				"from":        "0x789",
+				"to":          "0x123",
+				"value":       "100",
+				"validAfter":  strconv.FormatInt(now.Add(-time.Hour).Unix(), 10),
+				"validBefore": strconv.FormatInt(validBefore.Unix(), 10),
+				"nonce":       "0xdef",
+			},
+		}
+	}
+	expired := authorization(now.Add(-time.Minute))
+	valid := authorization(now.Add(time.Minute))
+	rejectedByFacilitator := map[string]interface{}{"signature": "0xstale"}
+
+	tests := []struct {
+		name        string
+		maxRequotes int
+		submissions []map[string]interface{}
+		wantState   a2a.TaskState
+		wantCode    string
+		wantQuotes  int
+		wantVerify  int
+	}{
+		{
+			name:        "local expiry is re-quoted and the fresh quote is paid",
+			maxRequotes: 2,
+			submissions: []map[string]interface{}{expired, valid},
+			wantState:   a2a.TaskStateCompleted,
+			wantQuotes:  2,
+			wantVerify:  1,
+		},
+		{
+			name:        "facilitator expiry is re-quoted",
+			maxRequotes: 2,
+			submissions: []map[string]interface{}{rejectedByFacilitator},
+			wantState:   a2a.TaskStateInputRequired,
+			wantCode:    x402.ErrorCodeQuoteExpiredRequote,
+			wantQuotes:  2,
+			wantVerify:  1,
+		},
+		{
+			name:        "cap reached fails the payment",
+			maxRequotes: 1,
+			submissions: []map[string]interface{}{expired, expired},
+			wantState:   a2a.TaskStateFailed,
+			wantCode:    x402.ErrorCodeExpiredPayment,
+			wantQuotes:  2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quotes, verifies := 0, 0
+			orchestrator := newTestOrchestrator(
+				t,
+				&MockResourceServer{
+					VerifyPaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.VerifyResponse, error) {
+						verifies++
+						if payload.Payload["signature"] == "0xstale" {
+							return &x402core.VerifyResponse{IsValid: false, InvalidReason: "invalid_exact_evm_payload_authorization_valid_before"}, nil
+						}
+						return &x402core.VerifyResponse{IsValid: true, Payer: "0x789"}, nil
+					},
+				},
+				&mockBusinessService{executeFunc: func(ctx context.Context, request business.Request) (*business.Result, error) {
+					if request.PaymentVerified {
+						return &business.Result{Message: "done"}, nil
+					}
+					quotes++
+					return nil, business.NewPaymentRequiredError("pay", business.ServiceRequirements{
+						Price:             "1.00",
+						Resource:          fmt.Sprintf("/quote/%d", quotes),
+						Scheme:            "exact",
+						MaxTimeoutSeconds: 60,
+					})
+				}},
+				[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+				WithClock(func() time.Time { return now }),
+				WithMaxRequotes(tt.maxRequotes),
+			)
+
+			task := quoteRequest(t, orchestrator, "task-requote", a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "buy"}))
+
+			for i, payload := range tt.submissions {
+				submission := paymentSubmission(t, task, func(submitted *x402types.PaymentPayload) {
+					submitted.Payload = payload
+				})
+				if err := submitPayment(orchestrator, task, submission, &mockEventQueue{}); err != nil {
+					t.Fatalf("submission %d: Execute() error = %v", i, err)
+				}
+			}
+
+			if task.Status.State != tt.wantState {
+				t.Fatalf("task state = %v, want %v: %s", task.Status.State, tt.wantState, x402state.ExtractMessageText(task.Status.Message))
+			}
+			if tt.wantCode != "" {
+				if got := task.Status.Message.Metadata[x402.MetadataKeyError]; got != tt.wantCode {
+					t.Errorf("error code = %v, want %s", got, tt.wantCode)
+				}
+			}
+			if quotes != tt.wantQuotes {
+				t.Errorf("quotes = %d, want %d", quotes, tt.wantQuotes)
+			}
+			if verifies != tt.wantVerify {
+				t.Errorf("verify calls = %d, want %d", verifies, tt.wantVerify)
+			}
+			if tt.wantState == a2a.TaskStateInputRequired {
+				requirements, _ := x402state.ExtractPaymentRequirements(task)
+				if requirements.Resource == nil || requirements.Resource.URL != "/quote/2" {
+					t.Errorf("re-quoted resource = %+v, want the fresh quote", requirements.Resource)
+				}
+				if got := x402state.ExtractRequoteCount(task); got != 1 {
+					t.Errorf("requote count = %d, want 1", got)
+				}
+				if prompt := x402state.ExtractOriginalPrompt(task); prompt != "buy" {
+					t.Errorf("original prompt = %q, want %q", prompt, "buy")
+				}
+			}
+		})
+	}
+}
@@ -16,7 +16,11 @@ package merchant
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/google-agentic-commerce/a2a-x402/core/business"
 	"github.com/google-agentic-commerce/a2a-x402/core/types"
@@ -24,31 +28,95 @@ import (
 	x402 "github.com/x402-foundation/x402/go"
 	x402core "github.com/x402-foundation/x402/go"
 	x402http "github.com/x402-foundation/x402/go/http"
+	evmutils "github.com/x402-foundation/x402/go/mechanisms/evm"
 	evm "github.com/x402-foundation/x402/go/mechanisms/evm/exact/server"
+	evmupto "github.com/x402-foundation/x402/go/mechanisms/evm/upto/server"
 	svm "github.com/x402-foundation/x402/go/mechanisms/svm/exact/server"
 	x402types "github.com/x402-foundation/x402/go/types"
 )
 
-func NewResourceServer(ctx context.Context, facilitatorURL string) (*x402.X402ResourceServer, error) {
+// SchemeRegistration pairs a CAIP-2 network with a scheme server that builds
+// payment requirements for it.
+type SchemeRegistration struct {
+	Network string
+	Server  x402.SchemeNetworkServer
+}
+
+// DefaultSchemeServers returns the built-in registrations: exact and upto on
+// Base and Base Sepolia, and exact on the Solana clusters.
+func DefaultSchemeServers() []SchemeRegistration {
+	return []SchemeRegistration{
+		{Network: x402pkg.NetworkBase, Server: evm.NewExactEvmScheme()},
+		{Network: x402pkg.NetworkBaseSepolia, Server: evm.NewExactEvmScheme()},
+		{Network: x402pkg.NetworkBase, Server: evmupto.NewUptoEvmScheme()},
+		{Network: x402pkg.NetworkBaseSepolia, Server: evmupto.NewUptoEvmScheme()},
+		{Network: x402pkg.NetworkSolanaMainnet, Server: svm.NewExactSvmScheme()},
+		{Network: x402pkg.NetworkSolanaDevnet, Server: svm.NewExactSvmScheme()},
+		{Network: x402pkg.NetworkSolanaTestnet, Server: svm.NewExactSvmScheme()},
+	}
+}
+
+// registeredNetworks returns the distinct networks in schemes.
+func registeredNetworks(schemes []SchemeRegistration) []string {
+	var networks []string
+	for _, scheme := range schemes {
+		if !slices.Contains(networks, scheme.Network) {
+			networks = append(networks, scheme.Network)
+		}
+	}
+	return networks
+}
+
+// NewResourceServer creates an initialized x402 resource server that talks to
+// the facilitator over HTTP. When no schemes are given, DefaultSchemeServers
+// is used.
+func NewResourceServer(ctx context.Context, facilitatorURL string, facilitatorOptions FacilitatorOptions, schemes ...SchemeRegistration) (*x402.X402ResourceServer, error) {
+	facilitator, err := newHTTPFacilitatorClient(facilitatorURL, facilitatorOptions)
+	if err != nil {
+		return nil, err
+	}
+	return NewResourceServerWithFacilitator(ctx, facilitator, schemes...)
+}
+
+// newHTTPFacilitatorClient builds the HTTP client for the facilitator at
+// facilitatorURL, applying the authentication in facilitatorOptions.
+func newHTTPFacilitatorClient(facilitatorURL string, facilitatorOptions FacilitatorOptions) (*x402http.HTTPFacilitatorClient, error) {
 	if facilitatorURL == "" {
 		return nil, fmt.Errorf("facilitatorURL is required")
 	}
 
-	var opts []x402.ResourceServerOption
+	authProvider, err := facilitatorOptions.authProvider(facilitatorURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid facilitator options: %w", err)
+	}
 
 	facilitatorConfig := &x402http.FacilitatorConfig{
-		URL: facilitatorURL,
+		URL:          facilitatorURL,
+		HTTPClient:   facilitatorOptions.HTTPClient,
+		AuthProvider: authProvider,
 	}
-	facilitator := x402http.NewHTTPFacilitatorClient(facilitatorConfig)
+	return x402http.NewHTTPFacilitatorClient(facilitatorConfig), nil
+}
 
-	opts = append(opts,
-		x402.WithFacilitatorClient(facilitator),
-		x402.WithSchemeServer(x402.Network(x402pkg.NetworkBase), evm.NewExactEvmScheme()),
-		x402.WithSchemeServer(x402.Network(x402pkg.NetworkBaseSepolia), evm.NewExactEvmScheme()),
-		x402.WithSchemeServer(x402.Network(x402pkg.NetworkSolanaMainnet), svm.NewExactSvmScheme()),
-		x402.WithSchemeServer(x402.Network(x402pkg.NetworkSolanaDevnet), svm.NewExactSvmScheme()),
-		x402.WithSchemeServer(x402.Network(x402pkg.NetworkSolanaTestnet), svm.NewExactSvmScheme()),
-	)
+// NewResourceServerWithFacilitator creates an initialized x402 resource server
+// around an existing facilitator client, e.g. a self-hosted facilitator or an
+// instrumented wrapper. When no schemes are given, DefaultSchemeServers is
+// used.
+func NewResourceServerWithFacilitator(ctx context.Context, facilitator x402.FacilitatorClient, schemes ...SchemeRegistration) (*x402.X402ResourceServer, error) {
+	if facilitator == nil {
+		return nil, fmt.Errorf("facilitator client is required")
+	}
+	if len(schemes) == 0 {
+		schemes = DefaultSchemeServers()
+	}
+
+	opts := []x402.ResourceServerOption{x402.WithFacilitatorClient(facilitator)}
+	for _, scheme := range schemes {
+		if scheme.Server == nil {
+			return nil, fmt.Errorf("scheme server for %s is nil", scheme.Network)
+		}
+		opts = append(opts, x402.WithSchemeServer(x402.Network(scheme.Network), scheme.Server))
+	}
 
 	server := x402.Newx402ResourceServer(opts...)
 
@@ -59,9 +127,51 @@ func NewResourceServer(ctx context.Context, facilitatorURL string) (*x402.X402Re
 	return server, nil
 }
 
+// WithFacilitatorClient makes NewBusinessOrchestrator and NewMerchant build
+// the resource server around client instead of an HTTP client for the
+// facilitator URL, which may then be empty. Facilitator options that only
+// configure the HTTP client have no effect.
+func WithFacilitatorClient(client x402.FacilitatorClient) Option {
+	return func(o *BusinessOrchestrator) {
+		o.facilitatorClient = client
+	}
+}
+
+// WithResourceServer makes NewBusinessOrchestrator and NewMerchant use an
+// already initialized resource server as-is. The facilitator URL, facilitator
+// client and scheme server options are ignored.
+func WithResourceServer(server *x402.X402ResourceServer) Option {
+	return func(o *BusinessOrchestrator) {
+		o.resourceServer = server
+	}
+}
+
+// supportedNetworks lists the networks the resource server will quote on. A
+// pre-built server is asked directly since its registrations are not visible
+// through the options.
+func (o *BusinessOrchestrator) supportedNetworks(configs []types.NetworkConfig) []string {
+	if o.resourceServer == nil {
+		return registeredNetworks(o.schemeRegistrations())
+	}
+	var networks []string
+	for _, config := range configs {
+		network := x402pkg.NormalizeNetwork(config.NetworkName)
+		if o.resourceServer.HasRegisteredScheme(x402.Network(network), x402pkg.SchemeExact) ||
+			o.resourceServer.HasRegisteredScheme(x402.Network(network), x402pkg.SchemeUpto) {
+			networks = append(networks, network)
+		}
+	}
+	return networks
+}
+
 // resourceServerWrapper wraps *x402.X402ResourceServer to implement ResourceServer
 type resourceServerWrapper struct {
 	server *x402.X402ResourceServer
+	// facilitator and facilitatorURL are kept for health checks. They are
+	// unset when the resource server was supplied pre-built.
+	facilitator    x402.FacilitatorClient
+	facilitatorURL string
+	pingTimeout    time.Duration
 }
 
 func (w *resourceServerWrapper) BuildPaymentRequirementsFromConfig(ctx context.Context, config x402.ResourceConfig) ([]x402types.PaymentRequirements, error) {
@@ -86,11 +196,164 @@ func BuildPaymentRequirements(
 	networkConfig types.NetworkConfig,
 	params business.ServiceRequirements,
 ) ([]*x402types.PaymentRequirements, error) {
+	if params.DecimalPricing {
+		return buildDecimalRequirements(ctx, server, networkConfig, params)
+	}
+	if len(networkConfig.Assets) == 0 {
+		return buildRequirements(ctx, server, networkConfig, params, params.Price)
+	}
 
+	var result []*x402types.PaymentRequirements
+	for _, asset := range networkConfig.Assets {
+		price, err := assetPrice(asset, params.Price)
+		if err != nil {
+			return nil, fmt.Errorf("invalid price for asset %s: %w", asset.Address, err)
+		}
+		reqs, err := buildRequirements(ctx, server, networkConfig, params, price)
+		if err != nil {
+			return nil, fmt.Errorf("asset %s: %w", asset.Address, err)
+		}
+		result = append(result, reqs...)
+	}
+	return result, nil
+}
+
+// assetPrice converts a whole-token price into the SDK's asset amount form so
+// the requirement is denominated in the configured asset.
+func assetPrice(asset types.AssetConfig, servicePrice string) (map[string]interface{}, error) {
+	price := asset.Price
+	if price == "" {
+		price = servicePrice
+	}
+	amount, err := evmutils.ParseAmount(strings.TrimPrefix(strings.TrimSpace(price), "$"), asset.Decimals)
+	if err != nil {
+		return nil, err
+	}
+	return assetAmountPrice(asset, amount.String(), nil), nil
+}
+
+// buildDecimalRequirements quotes a whole-token price in every asset accepted
+// on the network, converted to atomic units by the asset's decimals. The
+// requirement's Extra keeps both forms under "decimalPrice".
+func buildDecimalRequirements(
+	ctx context.Context,
+	server ResourceServer,
+	networkConfig types.NetworkConfig,
+	params business.ServiceRequirements,
+) ([]*x402types.PaymentRequirements, error) {
+	assets, err := pricedAssets(networkConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve assets: %w", err)
+	}
+
+	var result []*x402types.PaymentRequirements
+	for _, asset := range assets {
+		price := asset.Price
+		if price == "" {
+			price = params.Price
+		}
+		var amount string
+		if len(networkConfig.Assets) > 0 {
+			amount, err = x402pkg.ParseAtomicAmount(price, asset.Decimals)
+		} else {
+			amount, err = x402pkg.ToAtomicAmount(price, networkConfig.NetworkName, asset.Address)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid price for asset %s: %w", asset.Address, err)
+		}
+		reqs, err := buildRequirements(ctx, server, networkConfig, params, assetAmountPrice(asset, amount, map[string]interface{}{
+			"decimalPrice": map[string]interface{}{
+				"price":    strings.TrimPrefix(strings.TrimSpace(price), "$"),
+				"amount":   amount,
+				"decimals": asset.Decimals,
+			},
+		}))
+		if err != nil {
+			return nil, fmt.Errorf("asset %s: %w", asset.Address, err)
+		}
+		result = append(result, reqs...)
+	}
+	return result, nil
+}
+
+func assetAmountPrice(asset types.AssetConfig, amount string, extra map[string]interface{}) map[string]interface{} {
+	if extra == nil {
+		extra = map[string]interface{}{}
+	}
+	if asset.Name != "" {
+		extra["name"] = asset.Name
+	}
+	if asset.Version != "" {
+		extra["version"] = asset.Version
+	}
+	return map[string]interface{}{
+		"amount": amount,
+		"asset":  asset.Address,
+		"extra":  extra,
+	}
+}
+
+// buildFiatRequirements quotes a fiat price in every asset accepted on the
+// network. A failed quote fails the whole build rather than offering a
+// mispriced requirement.
+func buildFiatRequirements(
+	ctx context.Context,
+	server ResourceServer,
+	pricing PricingProvider,
+	networkConfig types.NetworkConfig,
+	params business.ServiceRequirements,
+	currency string,
+	fiatAmount string,
+) ([]*x402types.PaymentRequirements, error) {
+	assets, err := pricedAssets(networkConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve assets: %w", err)
+	}
+
+	var result []*x402types.PaymentRequirements
+	for _, asset := range assets {
+		var price map[string]interface{}
+		if asset.Price != "" {
+			price, err = assetPrice(asset, "")
+			if err != nil {
+				return nil, fmt.Errorf("invalid price for asset %s: %w", asset.Address, err)
+			}
+		} else {
+			amount, err := pricing.Quote(ctx, fiatAmount, currency, networkConfig.NetworkName, asset)
+			if err != nil {
+				return nil, fmt.Errorf("failed to quote %s %s in asset %s: %w", currency, fiatAmount, asset.Address, err)
+			}
+			price = assetAmountPrice(asset, amount, map[string]interface{}{
+				"fiatQuote": map[string]interface{}{
+					"currency":   currency,
+					"fiatAmount": fiatAmount,
+					"amount":     amount,
+				},
+			})
+		}
+		reqs, err := buildRequirements(ctx, server, networkConfig, params, price)
+		if err != nil {
+			return nil, fmt.Errorf("asset %s: %w", asset.Address, err)
+		}
+		result = append(result, reqs...)
+	}
+	return result, nil
+}
+
+func buildRequirements(
+	ctx context.Context,
+	server ResourceServer,
+	networkConfig types.NetworkConfig,
+	params business.ServiceRequirements,
+	price x402.Price,
+) ([]*x402types.PaymentRequirements, error) {
+	if err := validateScheme(params.Scheme, networkConfig.NetworkName); err != nil {
+		return nil, err
+	}
 	config := x402.ResourceConfig{
 		Scheme:            params.Scheme,
 		PayTo:             networkConfig.PayToAddress,
-		Price:             params.Price,
+		Price:             price,
 		Network:           x402.Network(networkConfig.NetworkName),
 		MaxTimeoutSeconds: params.MaxTimeoutSeconds,
 	}
@@ -103,9 +366,42 @@ func BuildPaymentRequirements(
 		return nil, fmt.Errorf("no payment requirements returned")
 	}
 
+	schema, err := outputSchema(params.OutputSchema)
+	if err != nil {
+		return nil, err
+	}
+
 	result := make([]*x402types.PaymentRequirements, 0, len(reqs))
 	for _, req := range reqs {
+		if err := checkBuiltPayTo(req, networkConfig); err != nil {
+			return nil, err
+		}
+		if schema != nil {
+			extra := make(map[string]interface{}, len(req.Extra)+1)
+			for key, value := range req.Extra {
+				extra[key] = value
+			}
+			extra[x402pkg.ExtraKeyOutputSchema] = schema
+			req.Extra = extra
+		}
 		result = append(result, &req)
 	}
 	return result, nil
 }
+
+// outputSchema converts a declared output schema to the JSON object it is
+// sent as, so that what the client sees is what a JSON round trip yields.
+func outputSchema(schema any) (map[string]interface{}, error) {
+	if schema == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output schema: %w", err)
+	}
+	var object map[string]interface{}
+	if err := json.Unmarshal(data, &object); err != nil || object == nil {
+		return nil, fmt.Errorf("invalid output schema: must be a JSON object, got %s", data)
+	}
+	return object, nil
+}
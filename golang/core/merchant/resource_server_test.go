@@ -0,0 +1,522 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	"github.com/google-agentic-commerce/a2a-x402/core/testutil/facilitator"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// assetAwareResourceServer mirrors how the SDK treats asset amount prices and
+// matches payloads on the accepted asset.
+func assetAwareResourceServer(verified, settled *[]x402types.PaymentRequirements) *MockResourceServer {
+	return &MockResourceServer{
+		BuildPaymentRequirementsFromConfigFunc: func(ctx context.Context, config x402core.ResourceConfig) ([]x402types.PaymentRequirements, error) {
+			requirement := x402types.PaymentRequirements{Scheme: config.Scheme, Network: string(config.Network), PayTo: config.PayTo, Asset: "0xdefault", Amount: "1000000"}
+			if price, ok := config.Price.(map[string]interface{}); ok {
+				requirement.Asset, _ = price["asset"].(string)
+				requirement.Amount, _ = price["amount"].(string)
+				requirement.Extra, _ = price["extra"].(map[string]interface{})
+			}
+			return []x402types.PaymentRequirements{requirement}, nil
+		},
+		FindMatchingRequirementsFunc: func(accepts []x402types.PaymentRequirements, payload x402types.PaymentPayload) *x402types.PaymentRequirements {
+			for i := range accepts {
+				if accepts[i].Asset == payload.Accepted.Asset && accepts[i].Amount == payload.Accepted.Amount {
+					return &accepts[i]
+				}
+			}
+			return nil
+		},
+		VerifyPaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.VerifyResponse, error) {
+			*verified = append(*verified, requirements)
+			return &x402core.VerifyResponse{IsValid: true, Payer: "0x789"}, nil
+		},
+		SettlePaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.SettleResponse, error) {
+			*settled = append(*settled, requirements)
+			return &x402core.SettleResponse{Success: true, Network: x402core.Network(requirements.Network), Transaction: "0xtx"}, nil
+		},
+	}
+}
+
+func TestBuildPaymentRequirements_Assets(t *testing.T) {
+	params := business.ServiceRequirements{Price: "$1.50", Resource: "/r", Scheme: "exact"}
+	var verified, settled []x402types.PaymentRequirements
+	server := assetAwareResourceServer(&verified, &settled)
+
+	tests := []struct {
+		name       string
+		assets     []types.AssetConfig
+		wantAssets []string
+		wantAmount []string
+	}{
+		{
+			name:       "default asset",
+			wantAssets: []string{"0xdefault"},
+			wantAmount: []string{"1000000"},
+		},
+		{
+			name: "two assets with override",
+			assets: []types.AssetConfig{
+				{Address: "0xusdc", Decimals: 6, Name: "USD Coin", Version: "2"},
+				{Address: "0xdai", Decimals: 18, Price: "1.45"},
+			},
+			wantAssets: []string{"0xusdc", "0xdai"},
+			wantAmount: []string{"1500000", "1450000000000000000"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			networkConfig := types.NetworkConfig{NetworkName: x402.NetworkBase, PayToAddress: "0x123", Assets: tt.assets}
+			reqs, err := BuildPaymentRequirements(context.Background(), server, networkConfig, params)
+			if err != nil {
+				t.Fatalf("BuildPaymentRequirements() error = %v", err)
+			}
+			if len(reqs) != len(tt.wantAssets) {
+				t.Fatalf("requirements = %d, want %d", len(reqs), len(tt.wantAssets))
+			}
+			for i, req := range reqs {
+				if req.Asset != tt.wantAssets[i] || req.Amount != tt.wantAmount[i] || req.Network != x402.NetworkBase {
+					t.Errorf("requirement %d = %s %s on %s, want %s %s", i, req.Amount, req.Asset, req.Network, tt.wantAmount[i], tt.wantAssets[i])
+				}
+			}
+			if len(tt.assets) > 0 && reqs[0].Extra["name"] != "USD Coin" {
+				t.Errorf("asset extra = %#v, want token domain name", reqs[0].Extra)
+			}
+		})
+	}
+}
+
+func TestBuildPaymentRequirements_DecimalPricing(t *testing.T) {
+	var verified, settled []x402types.PaymentRequirements
+	server := assetAwareResourceServer(&verified, &settled)
+	baseUSDC, _ := x402.LookupAsset(x402.NetworkBase, "")
+
+	tests := []struct {
+		name       string
+		price      string
+		assets     []types.AssetConfig
+		wantAssets []string
+		wantAmount []string
+		wantErr    string
+	}{
+		{
+			name:       "default asset",
+			price:      "1.5",
+			wantAssets: []string{baseUSDC.Address},
+			wantAmount: []string{"1500000"},
+		},
+		{
+			name:  "configured assets",
+			price: "0.000001",
+			assets: []types.AssetConfig{
+				{Address: "0xusdc", Decimals: 6},
+				{Address: "0xdai", Decimals: 18, Price: "0.000000000000000002"},
+			},
+			wantAssets: []string{"0xusdc", "0xdai"},
+			wantAmount: []string{"1", "2"},
+		},
+		{
+			name:    "more precise than the asset",
+			price:   "0.0000005",
+			wantErr: "has 7 decimal places but the asset supports 6",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			networkConfig := types.NetworkConfig{NetworkName: x402.NetworkBase, PayToAddress: "0x123", Assets: tt.assets}
+			params := business.ServiceRequirements{Price: tt.price, Scheme: "exact", DecimalPricing: true}
+			reqs, err := BuildPaymentRequirements(context.Background(), server, networkConfig, params)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("BuildPaymentRequirements() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BuildPaymentRequirements() error = %v", err)
+			}
+			if len(reqs) != len(tt.wantAssets) {
+				t.Fatalf("requirements = %d, want %d", len(reqs), len(tt.wantAssets))
+			}
+			for i, req := range reqs {
+				if req.Asset != tt.wantAssets[i] || req.Amount != tt.wantAmount[i] {
+					t.Errorf("requirement %d = %s %s, want %s %s", i, req.Amount, req.Asset, tt.wantAmount[i], tt.wantAssets[i])
+				}
+				decimal, _ := req.Extra["decimalPrice"].(map[string]interface{})
+				if decimal["amount"] != tt.wantAmount[i] || decimal["price"] == "" || decimal["price"] == nil {
+					t.Errorf("requirement %d decimalPrice = %#v, want human and atomic amounts", i, decimal)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildPaymentRequirements_OutputSchema(t *testing.T) {
+	var verified, settled []x402types.PaymentRequirements
+	server := assetAwareResourceServer(&verified, &settled)
+	type caption struct {
+		Type string `json:"type"`
+	}
+
+	tests := []struct {
+		name    string
+		schema  any
+		want    map[string]interface{}
+		wantErr string
+	}{
+		{name: "no schema"},
+		{
+			name:   "struct schema",
+			schema: map[string]caption{"caption": {Type: "string"}},
+			want:   map[string]interface{}{"caption": map[string]interface{}{"type": "string"}},
+		},
+		{name: "not encodable", schema: map[string]interface{}{"f": func() {}}, wantErr: "invalid output schema"},
+		{name: "not an object", schema: []string{"caption"}, wantErr: "must be a JSON object"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			networkConfig := types.NetworkConfig{NetworkName: x402.NetworkBase, PayToAddress: "0x123"}
+			params := business.ServiceRequirements{Price: "1", Scheme: x402.SchemeExact, OutputSchema: tt.schema}
+			reqs, err := BuildPaymentRequirements(context.Background(), server, networkConfig, params)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("BuildPaymentRequirements() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BuildPaymentRequirements() error = %v", err)
+			}
+			got, _ := reqs[0].Extra[x402.ExtraKeyOutputSchema].(map[string]interface{})
+			if tt.want == nil {
+				if _, ok := reqs[0].Extra[x402.ExtraKeyOutputSchema]; ok {
+					t.Errorf("extra = %#v, want no output schema", reqs[0].Extra)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("output schema = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBusinessOrchestrator_Execute_PaysWithSecondAsset(t *testing.T) {
+	var verified, settled []x402types.PaymentRequirements
+	orchestrator := newTestOrchestrator(
+		t,
+		assetAwareResourceServer(&verified, &settled),
+		&mockBusinessService{},
+		[]types.NetworkConfig{{
+			NetworkName:  x402.NetworkBase,
+			PayToAddress: "0x123",
+			Assets: []types.AssetConfig{
+				{Address: "0xusdc", Decimals: 6},
+				{Address: "0xeurc", Decimals: 6, Price: "0.95"},
+			},
+		}},
+	)
+
+	task := quoteRequest(t, orchestrator, "task-assets", a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "buy"}))
+	requirements, err := x402state.ExtractPaymentRequirements(task)
+	if err != nil || len(requirements.Accepts) != 2 {
+		t.Fatalf("accepts = %#v, error = %v", requirements, err)
+	}
+
+	payQuotedTask(t, orchestrator, task, func(payload *x402types.PaymentPayload) {
+		payload.Accepted = requirements.Accepts[1]
+	})
+
+	if task.Status.State != a2a.TaskStateCompleted {
+		t.Errorf("task state = %v, want completed", task.Status.State)
+	}
+	if len(verified) != 1 || verified[0].Asset != "0xeurc" || verified[0].Amount != "950000" {
+		t.Errorf("verified requirements = %#v, want the 0xeurc option", verified)
+	}
+	if len(settled) != 1 || settled[0].Asset != "0xeurc" {
+		t.Errorf("settled requirements = %#v, want the 0xeurc option", settled)
+	}
+}
+
+const optimism = "eip155:10"
+
+// fakeScheme prices everything at 1000 units of a fixed asset.
+type fakeScheme struct{}
+
+func (fakeScheme) Scheme() string { return x402.SchemeExact }
+
+func (fakeScheme) ParsePrice(price x402core.Price, network x402core.Network) (x402core.AssetAmount, error) {
+	return x402core.AssetAmount{Asset: "0xfake", Amount: "1000"}, nil
+}
+
+func (fakeScheme) EnhancePaymentRequirements(ctx context.Context, requirements x402types.PaymentRequirements, supportedKind x402types.SupportedKind, extensions []string) (x402types.PaymentRequirements, error) {
+	return requirements, nil
+}
+
+func newSupportedFacilitator(t *testing.T, networks ...string) string {
+	t.Helper()
+	kinds := make([]string, 0, len(networks))
+	for _, network := range networks {
+		kinds = append(kinds, `{"x402Version":2,"scheme":"exact","network":"`+network+`"}`)
+	}
+	body := `{"kinds":[` + strings.Join(kinds, ",") + `],"extensions":[],"signers":{}}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestNewResourceServer_CustomSchemeServer(t *testing.T) {
+	facilitatorURL := newSupportedFacilitator(t, optimism)
+	server, err := NewResourceServer(context.Background(), facilitatorURL, FacilitatorOptions{},
+		SchemeRegistration{Network: optimism, Server: fakeScheme{}})
+	if err != nil {
+		t.Fatalf("NewResourceServer() error = %v", err)
+	}
+
+	reqs, err := BuildPaymentRequirements(context.Background(), &resourceServerWrapper{server: server},
+		types.NetworkConfig{NetworkName: optimism, PayToAddress: evmPayTo},
+		business.ServiceRequirements{Price: "1.00", Resource: "/test", Scheme: x402.SchemeExact})
+	if err != nil {
+		t.Fatalf("BuildPaymentRequirements() error = %v", err)
+	}
+	if len(reqs) != 1 || reqs[0].Network != optimism || reqs[0].Asset != "0xfake" || reqs[0].Amount != "1000" {
+		t.Fatalf("requirements = %+v", reqs)
+	}
+}
+
+func TestNewMerchant_SchemeServerOptions(t *testing.T) {
+	facilitatorURL := newSupportedFacilitator(t, optimism, x402.NetworkBaseSepolia)
+	tests := []struct {
+		name    string
+		configs []types.NetworkConfig
+		opts    []Option
+		wantErr string
+	}{
+		{
+			name:    "unregistered network fails at startup",
+			configs: []types.NetworkConfig{{NetworkName: optimism, PayToAddress: evmPayTo}},
+			wantErr: `unsupported network "eip155:10": no scheme server is registered`,
+		},
+		{
+			name:    "registered custom network",
+			configs: []types.NetworkConfig{{NetworkName: optimism, PayToAddress: evmPayTo}},
+			opts:    []Option{WithSchemeServer(optimism, fakeScheme{})},
+		},
+		{
+			name:    "defaults removed",
+			configs: []types.NetworkConfig{{NetworkName: "base-sepolia", PayToAddress: evmPayTo}},
+			opts:    []Option{WithoutDefaultNetworks(), WithSchemeServer(optimism, fakeScheme{})},
+			wantErr: `unsupported network "base-sepolia"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewMerchant(context.Background(), facilitatorURL, &mockBusinessService{}, tt.configs, tt.opts...)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("NewMerchant() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("NewMerchant() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// fakeFacilitator is an in-process facilitator that accepts every payment.
+type fakeFacilitator struct {
+	networks       []string
+	verified       []x402types.PaymentRequirements
+	settledPayload []x402types.PaymentPayload
+}
+
+func (f *fakeFacilitator) Verify(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402core.VerifyResponse, error) {
+	requirements, err := x402types.ToPaymentRequirements(requirementsBytes)
+	if err != nil {
+		return nil, err
+	}
+	f.verified = append(f.verified, *requirements)
+	return &x402core.VerifyResponse{IsValid: true, Payer: "0xpayer"}, nil
+}
+
+func (f *fakeFacilitator) Settle(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402core.SettleResponse, error) {
+	payload, err := x402types.ToPaymentPayload(payloadBytes)
+	if err != nil {
+		return nil, err
+	}
+	f.settledPayload = append(f.settledPayload, *payload)
+	return &x402core.SettleResponse{Success: true, Payer: "0xpayer", Transaction: "0xfaketx", Network: x402core.Network(payload.Accepted.Network)}, nil
+}
+
+func (f *fakeFacilitator) GetSupported(ctx context.Context) (x402core.SupportedResponse, error) {
+	var kinds []x402core.SupportedKind
+	for _, network := range f.networks {
+		kinds = append(kinds, x402core.SupportedKind{X402Version: x402.X402Version, Scheme: x402.SchemeExact, Network: network})
+	}
+	return x402core.SupportedResponse{Kinds: kinds, Signers: map[string][]string{}}, nil
+}
+
+func TestNewMerchant_InjectedFacilitator(t *testing.T) {
+	ctx := context.Background()
+	configs := []types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: evmPayTo}}
+	tests := []struct {
+		name        string
+		newMerchant func(facilitator *fakeFacilitator) (*Merchant, error)
+	}{
+		{
+			name: "facilitator client",
+			newMerchant: func(facilitator *fakeFacilitator) (*Merchant, error) {
+				return NewMerchant(ctx, "", &mockBusinessService{}, configs, WithFacilitatorClient(facilitator))
+			},
+		},
+		{
+			name: "pre-built resource server",
+			newMerchant: func(facilitator *fakeFacilitator) (*Merchant, error) {
+				server, err := NewResourceServerWithFacilitator(ctx, facilitator)
+				if err != nil {
+					return nil, err
+				}
+				return NewMerchant(ctx, "", &mockBusinessService{}, configs, WithResourceServer(server))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			facilitator := &fakeFacilitator{networks: []string{x402.NetworkBaseSepolia}}
+			m, err := tt.newMerchant(facilitator)
+			if err != nil {
+				t.Fatalf("NewMerchant() error = %v", err)
+			}
+			orchestrator := m.orchestrator
+			orchestrator.extensionChecker = newMockExtensionCheckerWithX402()
+
+			task := quoteRequest(t, orchestrator, "task-facilitator", a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "buy"}))
+			payQuotedTask(t, orchestrator, task)
+
+			if task.Status.State != a2a.TaskStateCompleted {
+				t.Fatalf("task state = %v, want %v: %s", task.Status.State, a2a.TaskStateCompleted, x402state.ExtractMessageText(task.Status.Message))
+			}
+			if len(facilitator.verified) != 1 || len(facilitator.settledPayload) != 1 {
+				t.Fatalf("facilitator verified %d and settled %d payments, want 1 each", len(facilitator.verified), len(facilitator.settledPayload))
+			}
+			if facilitator.verified[0].PayTo != evmPayTo {
+				t.Errorf("verified payTo = %q, want %q", facilitator.verified[0].PayTo, evmPayTo)
+			}
+			receipts, _ := x402state.ExtractPaymentReceipts(task)
+			if len(receipts) != 1 || receipts[0].Transaction != "0xfaketx" {
+				t.Errorf("receipts = %#v, want the fake facilitator's settlement", receipts)
+			}
+		})
+	}
+}
+
+func TestNewMerchant_HTTPFacilitator(t *testing.T) {
+	ctx := context.Background()
+	configs := []types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: evmPayTo}}
+	tests := []struct {
+		name          string
+		opts          facilitator.Options
+		merchantOpts  []Option
+		wantState     a2a.TaskState
+		wantErrorCode string
+		wantSettled   int
+	}{
+		{name: "settles", wantState: a2a.TaskStateCompleted, wantSettled: 1},
+		{
+			name:          "invalid payload",
+			opts:          facilitator.Options{InvalidReason: "invalid_exact_evm_payload_signature"},
+			wantState:     a2a.TaskStateFailed,
+			wantErrorCode: x402.ErrorCodeInvalidSignature,
+		},
+		{
+			name:          "settlement refused",
+			opts:          facilitator.Options{SettleErrorReason: "insufficient_funds"},
+			wantState:     a2a.TaskStateFailed,
+			wantErrorCode: x402.ErrorCodeInsufficientFunds,
+			wantSettled:   1,
+		},
+		{
+			name:          "verify server error",
+			opts:          facilitator.Options{VerifyStatus: http.StatusBadGateway},
+			wantState:     a2a.TaskStateFailed,
+			wantErrorCode: x402.ErrorCodeInvalidSignature,
+		},
+		{
+			name:          "verify too slow",
+			opts:          facilitator.Options{Latency: time.Second},
+			merchantOpts:  []Option{WithFacilitatorOptions(FacilitatorOptions{VerifyTimeout: 50 * time.Millisecond})},
+			wantState:     a2a.TaskStateFailed,
+			wantErrorCode: x402.ErrorCodeVerifyTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := facilitator.NewMockFacilitator(t, facilitator.Options{})
+			m, err := NewMerchant(ctx, mock.URL, &mockBusinessService{}, configs, tt.merchantOpts...)
+			if err != nil {
+				t.Fatalf("NewMerchant() error = %v", err)
+			}
+			mock.Update(func(o *facilitator.Options) { *o = tt.opts })
+			orchestrator := m.orchestrator
+			orchestrator.extensionChecker = newMockExtensionCheckerWithX402()
+
+			task := quoteRequest(t, orchestrator, "task-http-facilitator", a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "buy"}))
+			payQuotedTask(t, orchestrator, task)
+
+			if task.Status.State != tt.wantState {
+				t.Fatalf("task state = %v, want %v: %s", task.Status.State, tt.wantState, x402state.ExtractMessageText(task.Status.Message))
+			}
+			if got := task.Status.Message.Metadata[x402.MetadataKeyError]; tt.wantErrorCode != "" && got != tt.wantErrorCode {
+				t.Errorf("error code = %v, want %s", got, tt.wantErrorCode)
+			}
+			verified := mock.Verified()
+			if len(verified) != 1 || verified[0].Requirements.PayTo != evmPayTo {
+				t.Errorf("verified = %+v, want one request paying %s", verified, evmPayTo)
+			}
+			if got := len(mock.Settled()); got != tt.wantSettled {
+				t.Errorf("settle requests = %d, want %d", got, tt.wantSettled)
+			}
+			if tt.wantState == a2a.TaskStateCompleted {
+				receipts, _ := x402state.ExtractPaymentReceipts(task)
+				if len(receipts) != 1 || receipts[0].Transaction != facilitator.DefaultTransaction || receipts[0].Payer != facilitator.DefaultPayer {
+					t.Errorf("receipts = %#v, want the mock facilitator's settlement", receipts)
+				}
+			}
+		})
+	}
+}
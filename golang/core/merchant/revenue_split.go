@@ -0,0 +1,181 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// ReceiptExtraSplits is the receipt Extra key listing what each split
+// recipient is owed from the settled payment.
+const ReceiptExtraSplits = "splits"
+
+// splitBasisPoints is the whole payment in basis points.
+const splitBasisPoints = 10000
+
+// SplitShare is what one recipient is owed from a settled payment, in the
+// asset's atomic units.
+type SplitShare struct {
+	Address     string `json:"address"`
+	BasisPoints int    `json:"basisPoints"`
+	Amount      string `json:"amount"`
+}
+
+// SplitEntry is a settled payment whose proceeds the payTo address owes to
+// split recipients.
+type SplitEntry struct {
+	TaskID      string
+	ContextID   string
+	Network     string
+	Asset       string
+	PayTo       string
+	Transaction string
+	Amount      string
+	Shares      []SplitShare
+}
+
+// SplitLedger records owed splits for later distribution. It runs after
+// settlement, so an error is logged and never fails the task.
+type SplitLedger interface {
+	RecordSplit(ctx context.Context, entry SplitEntry) error
+}
+
+// SplitLedgerFunc adapts a function to the SplitLedger interface.
+type SplitLedgerFunc func(ctx context.Context, entry SplitEntry) error
+
+func (f SplitLedgerFunc) RecordSplit(ctx context.Context, entry SplitEntry) error {
+	return f(ctx, entry)
+}
+
+// WithSplitLedger records every split payment in ledger.
+func WithSplitLedger(ledger SplitLedger) Option {
+	return func(o *BusinessOrchestrator) {
+		o.splitLedger = ledger
+	}
+}
+
+// ReceiptSplits returns the split shares recorded on a receipt, or nil when
+// the payment was not split.
+func ReceiptSplits(receipt *x402core.SettleResponse) ([]SplitShare, error) {
+	if receipt == nil || receipt.Extra[ReceiptExtraSplits] == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(receipt.Extra[ReceiptExtraSplits])
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode receipt splits: %w", err)
+	}
+	var shares []SplitShare
+	if err := json.Unmarshal(data, &shares); err != nil {
+		return nil, fmt.Errorf("failed to decode receipt splits: %w", err)
+	}
+	return shares, nil
+}
+
+// recordSplits adds the owed shares to a successful receipt when its network
+// splits payments, and hands them to the split ledger.
+func (o *BusinessOrchestrator) recordSplits(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	response *x402core.SettleResponse,
+	requirement *x402types.PaymentRequirements,
+) *x402core.SettleResponse {
+	if response == nil || requirement == nil {
+		return response
+	}
+	splits := o.networkSplits(requirement.Network)
+	if len(splits) == 0 {
+		return response
+	}
+
+	shares := splitShares(response.Amount, splits)
+	receipt := *response
+	receipt.Extra = make(map[string]interface{}, len(response.Extra)+1)
+	for key, value := range response.Extra {
+		receipt.Extra[key] = value
+	}
+	receipt.Extra[ReceiptExtraSplits] = shares
+
+	if o.splitLedger != nil {
+		entry := SplitEntry{
+			Network:     requirement.Network,
+			Asset:       requirement.Asset,
+			PayTo:       requirement.PayTo,
+			Transaction: receipt.Transaction,
+			Amount:      receipt.Amount,
+			Shares:      shares,
+		}
+		if requestContext != nil {
+			entry.TaskID = string(requestContext.TaskID)
+			entry.ContextID = requestContext.ContextID
+		}
+		if err := o.splitLedger.RecordSplit(context.WithoutCancel(ctx), entry); err != nil {
+			o.logger.ErrorContext(ctx, "x402 split ledger record failed",
+				"task_id", entry.TaskID,
+				"context_id", entry.ContextID,
+				"transaction", entry.Transaction,
+				"error", err,
+			)
+		}
+	}
+	return &receipt
+}
+
+func (o *BusinessOrchestrator) networkSplits(network string) []types.SplitRecipient {
+	network = x402pkg.NormalizeNetwork(network)
+	for _, config := range o.networkConfigs {
+		if x402pkg.NormalizeNetwork(config.NetworkName) == network {
+			return config.Splits
+		}
+	}
+	return nil
+}
+
+// splitShares divides amount by basis points. Rounding leaves at most a few
+// atomic units over, which go to the first recipient so the shares always
+// add up to the amount. An amount that is not an integer leaves the shares'
+// amounts empty.
+func splitShares(amount string, splits []types.SplitRecipient) []SplitShare {
+	shares := make([]SplitShare, len(splits))
+	for i, split := range splits {
+		shares[i] = SplitShare{Address: split.Address, BasisPoints: split.BasisPoints}
+	}
+	total, ok := new(big.Int).SetString(amount, 10)
+	if !ok {
+		return shares
+	}
+
+	remaining := new(big.Int).Set(total)
+	whole := big.NewInt(splitBasisPoints)
+	for i, split := range splits {
+		share := new(big.Int).Mul(total, big.NewInt(int64(split.BasisPoints)))
+		share.Quo(share, whole)
+		remaining.Sub(remaining, share)
+		shares[i].Amount = share.String()
+	}
+	if remaining.Sign() != 0 {
+		first, _ := new(big.Int).SetString(shares[0].Amount, 10)
+		shares[0].Amount = first.Add(first, remaining).String()
+	}
+	return shares
+}
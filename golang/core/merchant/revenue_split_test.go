@@ -0,0 +1,116 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+const artistPayTo = "0x1111111111111111111111111111111111111111"
+
+func newSplitOrchestrator(t *testing.T, ledger SplitLedger) *BusinessOrchestrator {
+	return newTestOrchestrator(
+		t,
+		&MockResourceServer{
+			SettlePaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.SettleResponse, error) {
+				return &x402core.SettleResponse{Success: true, Transaction: "0xtx", Network: x402core.Network(requirements.Network), Amount: "1000001"}, nil
+			},
+		},
+		&mockBusinessService{},
+		[]types.NetworkConfig{{
+			NetworkName:  x402.NetworkBaseSepolia,
+			PayToAddress: evmPayTo,
+			Splits: []types.SplitRecipient{
+				{Address: evmPayTo, BasisPoints: 1000},
+				{Address: artistPayTo, BasisPoints: 9000},
+			},
+		}},
+		WithSplitLedger(ledger),
+	)
+}
+
+func TestBusinessOrchestrator_Execute_RecordsRevenueSplit(t *testing.T) {
+	var entries []SplitEntry
+	orchestrator := newSplitOrchestrator(t, SplitLedgerFunc(func(ctx context.Context, entry SplitEntry) error {
+		entries = append(entries, entry)
+		return nil
+	}))
+
+	task := payTask(t, orchestrator, "task-split")
+
+	wantShares := []SplitShare{
+		{Address: evmPayTo, BasisPoints: 1000, Amount: "100001"},
+		{Address: artistPayTo, BasisPoints: 9000, Amount: "900000"},
+	}
+	if len(entries) != 1 {
+		t.Fatalf("ledger entries = %d, want 1", len(entries))
+	}
+	entry := entries[0]
+	if entry.TaskID != "task-split" || entry.Transaction != "0xtx" || entry.Amount != "1000001" || entry.Network != x402.NetworkBaseSepolia {
+		t.Errorf("entry = %+v, want task-split tx 0xtx for 1000001 on %s", entry, x402.NetworkBaseSepolia)
+	}
+	if !slices.Equal(entry.Shares, wantShares) {
+		t.Errorf("entry.Shares = %+v, want %+v", entry.Shares, wantShares)
+	}
+
+	receipts, err := x402state.ExtractPaymentReceipts(task)
+	if err != nil || len(receipts) != 1 {
+		t.Fatalf("ExtractPaymentReceipts() = %+v, %v, want one receipt", receipts, err)
+	}
+	shares, err := ReceiptSplits(receipts[0])
+	if err != nil {
+		t.Fatalf("ReceiptSplits() error = %v", err)
+	}
+	if !slices.Equal(shares, wantShares) {
+		t.Errorf("receipt splits = %+v, want %+v", shares, wantShares)
+	}
+}
+
+func TestBusinessOrchestrator_Execute_SplitLedgerErrorDoesNotFailTask(t *testing.T) {
+	orchestrator := newSplitOrchestrator(t, SplitLedgerFunc(func(ctx context.Context, entry SplitEntry) error {
+		return errors.New("ledger offline")
+	}))
+
+	task := payTask(t, orchestrator, "task-split-ledger-down")
+
+	if status, _ := x402state.ExtractPaymentStatus(task); status != x402state.PaymentCompleted {
+		t.Errorf("payment status = %q, want %q", status, x402state.PaymentCompleted)
+	}
+}
+
+func TestSplitShares(t *testing.T) {
+	splits := []types.SplitRecipient{{Address: "a", BasisPoints: 3333}, {Address: "b", BasisPoints: 3333}, {Address: "c", BasisPoints: 3334}}
+
+	got := splitShares("100", splits)
+	want := []SplitShare{{"a", 3333, "34"}, {"b", 3333, "33"}, {"c", 3334, "33"}}
+	if !slices.Equal(got, want) {
+		t.Errorf("splitShares(100) = %+v, want %+v", got, want)
+	}
+
+	for _, share := range splitShares("1.5", splits) {
+		if share.Amount != "" {
+			t.Errorf("splitShares(1.5) share = %+v, want no amount", share)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+
+	x402 "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+const (
+	// SandboxPayer is the payer reported for every payment in sandbox mode.
+	SandboxPayer = "0x5a4db0c500000000000000000000000000000000"
+	// SandboxTransactionPrefix starts every transaction reference fabricated
+	// in sandbox mode, so no one mistakes it for a real one.
+	SandboxTransactionPrefix = "sandbox-"
+)
+
+// WithSandboxMode verifies and settles every structurally valid payment
+// in-process, without a facilitator or any money moving. Requirements are
+// still built by the registered scheme servers, so clients see realistic
+// quotes; receipts carry a transaction starting with SandboxTransactionPrefix
+// and a "sandbox" entry in their extra data. It is meant for developing a
+// BusinessService locally. With NewBusinessOrchestrator and NewMerchant it
+// takes precedence over every facilitator and payment server option, and a
+// warning is logged when the merchant is created.
+func WithSandboxMode() Option {
+	return func(o *BusinessOrchestrator) {
+		o.sandbox = true
+	}
+}
+
+// newSandboxServer builds a resource server whose facilitator is the
+// in-process sandboxFacilitator.
+func newSandboxServer(ctx context.Context, schemes []SchemeRegistration) (ResourceServer, error) {
+	server, err := NewResourceServerWithFacilitator(ctx, sandboxFacilitator{schemes: schemes}, schemes...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sandbox resource server: %w", err)
+	}
+	return &resourceServerWrapper{server: server}, nil
+}
+
+// warnSandbox logs that payments are not real. It goes to the default logger
+// when none was configured, so sandbox mode never runs silently.
+func (o *BusinessOrchestrator) warnSandbox(ctx context.Context) {
+	logger := o.logger
+	if logger.Handler() == slog.DiscardHandler {
+		logger = slog.Default()
+	}
+	logger.WarnContext(ctx, "x402 SANDBOX MODE: payments are accepted without verification and never settled; do not use in production")
+}
+
+// sandboxFacilitator supports every registered scheme, accepts any payload
+// that decodes and names the quoted scheme and network, and fabricates
+// settlements.
+type sandboxFacilitator struct {
+	schemes []SchemeRegistration
+}
+
+func (f sandboxFacilitator) GetSupported(ctx context.Context) (x402.SupportedResponse, error) {
+	kinds := make([]x402.SupportedKind, 0, len(f.schemes))
+	for _, scheme := range f.schemes {
+		kinds = append(kinds, x402.SupportedKind{X402Version: 2, Scheme: scheme.Server.Scheme(), Network: scheme.Network})
+	}
+	return x402.SupportedResponse{Kinds: kinds, Extensions: []string{}, Signers: map[string][]string{}}, nil
+}
+
+func (f sandboxFacilitator) Verify(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.VerifyResponse, error) {
+	if reason := sandboxInvalidReason(payloadBytes, requirementsBytes); reason != "" {
+		return &x402.VerifyResponse{IsValid: false, InvalidReason: "sandbox_invalid_payload", InvalidMessage: reason}, nil
+	}
+	return &x402.VerifyResponse{IsValid: true, Payer: SandboxPayer}, nil
+}
+
+func (f sandboxFacilitator) Settle(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error) {
+	requirements, err := x402types.ToPaymentRequirements(requirementsBytes)
+	if err != nil {
+		return nil, fmt.Errorf("sandbox: invalid requirements: %w", err)
+	}
+	if reason := sandboxInvalidReason(payloadBytes, requirementsBytes); reason != "" {
+		return &x402.SettleResponse{Success: false, ErrorReason: "sandbox_invalid_payload", ErrorMessage: reason, Network: x402.Network(requirements.Network)}, nil
+	}
+	nonce := make([]byte, 16)
+	_, _ = rand.Read(nonce)
+	return &x402.SettleResponse{
+		Success:     true,
+		Payer:       SandboxPayer,
+		Transaction: SandboxTransactionPrefix + hex.EncodeToString(nonce),
+		Network:     x402.Network(requirements.Network),
+		Amount:      requirements.Amount,
+		Extra:       map[string]interface{}{"sandbox": true},
+	}, nil
+}
+
+// sandboxInvalidReason describes why a payload is not structurally valid for
+// the requirements, or returns "" when it is.
+func sandboxInvalidReason(payloadBytes, requirementsBytes []byte) string {
+	payload, err := x402types.ToPaymentPayload(payloadBytes)
+	if err != nil {
+		return fmt.Sprintf("payload does not decode: %v", err)
+	}
+	requirements, err := x402types.ToPaymentRequirements(requirementsBytes)
+	if err != nil {
+		return fmt.Sprintf("requirements do not decode: %v", err)
+	}
+	if len(payload.Payload) == 0 {
+		return "payload carries no authorization"
+	}
+	if payload.Accepted.Scheme != requirements.Scheme || payload.Accepted.Network != requirements.Network {
+		return fmt.Sprintf("payload is for %s on %s, quote is %s on %s",
+			payload.Accepted.Scheme, payload.Accepted.Network, requirements.Scheme, requirements.Network)
+	}
+	return ""
+}
@@ -0,0 +1,123 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// refusingTransport fails and counts every outbound request.
+type refusingTransport struct {
+	requests atomic.Int32
+}
+
+func (r *refusingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.requests.Add(1)
+	return nil, errors.New("outbound HTTP in sandbox mode")
+}
+
+func TestWithSandboxMode(t *testing.T) {
+	transport := &refusingTransport{}
+	defaultTransport := http.DefaultTransport
+	http.DefaultTransport = transport
+	t.Cleanup(func() { http.DefaultTransport = defaultTransport })
+
+	var logs bytes.Buffer
+	ctx := context.Background()
+	configs := []types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: evmPayTo}}
+	m, err := NewMerchant(ctx, "https://facilitator.invalid", &mockBusinessService{}, configs,
+		WithSandboxMode(), WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+	if err != nil {
+		t.Fatalf("NewMerchant() error = %v", err)
+	}
+	if !strings.Contains(logs.String(), "SANDBOX MODE") {
+		t.Errorf("logs = %q, want a sandbox warning", logs.String())
+	}
+	orchestrator := m.orchestrator
+	orchestrator.extensionChecker = newMockExtensionCheckerWithX402()
+
+	task := quoteRequest(t, orchestrator, "task-sandbox", a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "buy"}))
+	requirements, err := x402state.ExtractPaymentRequirements(task)
+	if err != nil || requirements == nil || len(requirements.Accepts) == 0 {
+		t.Fatalf("ExtractPaymentRequirements() = %v, %v", requirements, err)
+	}
+	if got := requirements.Accepts[0]; got.PayTo != evmPayTo || got.Asset == "" || got.Amount == "" {
+		t.Errorf("quoted requirement = %+v, want a plausible quote", got)
+	}
+	payQuotedTask(t, orchestrator, task)
+
+	if task.Status.State != a2a.TaskStateCompleted {
+		t.Fatalf("task state = %v, want %v: %s", task.Status.State, a2a.TaskStateCompleted, x402state.ExtractMessageText(task.Status.Message))
+	}
+	receipts, _ := x402state.ExtractPaymentReceipts(task)
+	if len(receipts) != 1 || !strings.HasPrefix(receipts[0].Transaction, SandboxTransactionPrefix) || receipts[0].Payer != SandboxPayer {
+		t.Errorf("receipts = %#v, want a sandbox settlement", receipts)
+	}
+	if got := transport.requests.Load(); got != 0 {
+		t.Errorf("outbound HTTP requests = %d, want none", got)
+	}
+}
+
+func TestSandboxFacilitator_RejectsMalformedPayloads(t *testing.T) {
+	requirement := x402types.PaymentRequirements{Scheme: x402.SchemeExact, Network: x402.NetworkBaseSepolia, PayTo: evmPayTo, Asset: "0x456", Amount: "100"}
+	requirementBytes := mustJSON(t, requirement)
+	tests := []struct {
+		name    string
+		payload []byte
+	}{
+		{name: "not a payload", payload: []byte(`"nope"`)},
+		{name: "no authorization", payload: mustJSON(t, x402types.PaymentPayload{X402Version: x402.X402Version, Accepted: requirement})},
+		{
+			name: "other network",
+			payload: mustJSON(t, x402types.PaymentPayload{
+				X402Version: x402.X402Version,
+				Accepted:    x402types.PaymentRequirements{Scheme: x402.SchemeExact, Network: x402.NetworkBase},
+				Payload:     map[string]interface{}{"signature": "0xabc"},
+			}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := sandboxFacilitator{}.Verify(context.Background(), tt.payload, requirementBytes)
+			if err != nil || response.IsValid {
+				t.Errorf("Verify() = %+v, %v, want an invalid payload", response, err)
+			}
+		})
+	}
+}
+
+func mustJSON(t *testing.T, value interface{}) []byte {
+	t.Helper()
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
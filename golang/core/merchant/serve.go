@@ -0,0 +1,182 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+)
+
+// Defaults for Serve.
+const (
+	DefaultReadTimeout     = 30 * time.Second
+	DefaultWriteTimeout    = 2 * time.Minute
+	DefaultShutdownTimeout = 30 * time.Second
+)
+
+// ServeOption configures Serve.
+type ServeOption func(*serveConfig)
+
+type serveConfig struct {
+	readTimeout     time.Duration
+	writeTimeout    time.Duration
+	shutdownTimeout time.Duration
+	tlsConfig       *tls.Config
+	listener        net.Listener
+	routes          []route
+	handlerOptions  []HandlerOption
+}
+
+type route struct {
+	pattern string
+	handler http.Handler
+}
+
+// WithReadTimeout bounds reading a request, body included. Defaults to
+// DefaultReadTimeout.
+func WithReadTimeout(timeout time.Duration) ServeOption {
+	return func(c *serveConfig) {
+		c.readTimeout = timeout
+	}
+}
+
+// WithWriteTimeout bounds writing a response. A paid request is answered
+// only after verification, the service and settlement, so it must cover all
+// three. Defaults to DefaultWriteTimeout.
+func WithWriteTimeout(timeout time.Duration) ServeOption {
+	return func(c *serveConfig) {
+		c.writeTimeout = timeout
+	}
+}
+
+// WithShutdownTimeout bounds how long in-flight requests and settlements get
+// to finish once Serve is asked to stop. Defaults to DefaultShutdownTimeout.
+func WithShutdownTimeout(timeout time.Duration) ServeOption {
+	return func(c *serveConfig) {
+		c.shutdownTimeout = timeout
+	}
+}
+
+// WithTLSConfig serves HTTPS with config, which must carry the certificates.
+func WithTLSConfig(config *tls.Config) ServeOption {
+	return func(c *serveConfig) {
+		c.tlsConfig = config
+	}
+}
+
+// WithListener serves on listener instead of listening on the address given
+// to Serve, for example to learn the port chosen for ":0".
+func WithListener(listener net.Listener) ServeOption {
+	return func(c *serveConfig) {
+		c.listener = listener
+	}
+}
+
+// WithRoute serves handler at pattern, in http.ServeMux syntax, beside the
+// a2a routes; a health check, for instance.
+func WithRoute(pattern string, handler http.Handler) ServeOption {
+	return func(c *serveConfig) {
+		c.routes = append(c.routes, route{pattern: pattern, handler: handler})
+	}
+}
+
+// WithHTTPHandlerOptions passes options to the NewHTTPHandler that serves
+// the a2a routes.
+func WithHTTPHandlerOptions(opts ...HandlerOption) ServeOption {
+	return func(c *serveConfig) {
+		c.handlerOptions = append(c.handlerOptions, opts...)
+	}
+}
+
+// Serve serves m over plain net/http until ctx is done or the process gets
+// SIGINT or SIGTERM. It serves JSON-RPC at DefaultRPCPath and, when card is
+// not nil, the agent card at its well-known path. On the way out the merchant
+// stops taking new work, and Serve returns once in-flight requests and
+// settlements have drained or the shutdown timeout has passed.
+func Serve(ctx context.Context, addr string, m *Merchant, card *a2a.AgentCard, opts ...ServeOption) error {
+	config := serveConfig{
+		readTimeout:     DefaultReadTimeout,
+		writeTimeout:    DefaultWriteTimeout,
+		shutdownTimeout: DefaultShutdownTimeout,
+	}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	handlerOptions := config.handlerOptions
+	if card != nil {
+		handlerOptions = append(handlerOptions, WithHandlerAgentCard(card))
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/", NewHTTPHandler(m, handlerOptions...))
+	for _, route := range config.routes {
+		mux.Handle(route.pattern, route.handler)
+	}
+
+	listener := config.listener
+	if listener == nil {
+		var err error
+		if listener, err = net.Listen("tcp", addr); err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", addr, err)
+		}
+	}
+	server := &http.Server{
+		Handler:      mux,
+		ReadTimeout:  config.readTimeout,
+		WriteTimeout: config.writeTimeout,
+		TLSConfig:    config.tlsConfig,
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	serveErr := make(chan error, 1)
+	go func() {
+		if config.tlsConfig != nil {
+			serveErr <- server.ServeTLS(listener, "", "")
+			return
+		}
+		serveErr <- server.Serve(listener)
+	}()
+
+	var err error
+	select {
+	case err = <-serveErr:
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), config.shutdownTimeout)
+	defer cancel()
+	// The merchant refuses new work first, so requests still arriving while
+	// the listener closes are turned away rather than half-processed.
+	merchantErr := m.Shutdown(shutdownCtx)
+	if err != nil {
+		return errors.Join(err, merchantErr)
+	}
+	serverErr := server.Shutdown(shutdownCtx)
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		serverErr = errors.Join(serverErr, err)
+	}
+	return errors.Join(merchantErr, serverErr)
+}
@@ -0,0 +1,134 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/client"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+func TestServe_PaidFlowThenCleanExit(t *testing.T) {
+	networks := []types.NetworkConfig{{
+		NetworkName:  x402pkg.NetworkBaseSepolia,
+		PayToAddress: "0x209693Bc6afc0C5328bA36FaF03C514EF312287C",
+	}}
+	settled := make(chan struct{}, 1)
+	m := &Merchant{orchestrator: newTestOrchestrator(
+		t,
+		&MockResourceServer{
+			BuildPaymentRequirementsFromConfigFunc: func(ctx context.Context, config x402core.ResourceConfig) ([]x402types.PaymentRequirements, error) {
+				return []x402types.PaymentRequirements{{
+					Scheme:            x402pkg.SchemeExact,
+					Network:           string(config.Network),
+					Asset:             "0x036CbD53842c5426634e7929541eC2318f3dCF7e",
+					Amount:            "1000000",
+					PayTo:             config.PayTo,
+					MaxTimeoutSeconds: 60,
+					Extra:             map[string]interface{}{"name": "USDC", "version": "2"},
+				}}, nil
+			},
+			SettlePaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.SettleResponse, error) {
+				settled <- struct{}{}
+				return &x402core.SettleResponse{Success: true, Network: x402core.Network(requirements.Network), Transaction: "0xabc"}, nil
+			},
+		},
+		&mockBusinessService{},
+		networks,
+		WithExtensionChecker(DefaultExtensionChecker()),
+	)}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	url := "http://" + listener.Addr().String()
+	card, err := NewAgentCard(AgentCardConfig{
+		Name:           "Serve Test",
+		URL:            url + DefaultRPCPath,
+		Skills:         []a2a.AgentSkill{{ID: "buy", Name: "buy"}},
+		NetworkConfigs: networks,
+	})
+	if err != nil {
+		t.Fatalf("NewAgentCard() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	served := make(chan error, 1)
+	go func() {
+		served <- Serve(ctx, "", m, card,
+			WithListener(listener),
+			WithShutdownTimeout(5*time.Second),
+			WithRoute("GET /healthz", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			})),
+		)
+	}()
+
+	c, err := client.NewClient(url, []types.NetworkKeyPair{{
+		NetworkName: x402pkg.NetworkBaseSepolia,
+		PrivateKey:  "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318",
+	}}, client.WithPollInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer c.Close()
+	flowCtx, flowCancel := context.WithTimeout(ctx, 5*time.Second)
+	defer flowCancel()
+	task, err := c.WaitForCompletion(flowCtx, "buy")
+	if err != nil {
+		t.Fatalf("WaitForCompletion() error = %v", err)
+	}
+	if task.Status.State != a2a.TaskStateCompleted {
+		t.Fatalf("state = %s, want completed", task.Status.State)
+	}
+	select {
+	case <-settled:
+	default:
+		t.Error("payment was not settled")
+	}
+
+	response, err := http.Get(url + "/healthz")
+	if err != nil {
+		t.Fatalf("GET /healthz error = %v", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusNoContent {
+		t.Errorf("GET /healthz status = %d, want the extra route's 204", response.StatusCode)
+	}
+
+	cancel()
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Fatalf("Serve() error = %v, want a clean exit", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Serve() did not return after cancellation")
+	}
+	if _, err := http.Get(url + "/healthz"); err == nil {
+		t.Error("server still accepting connections after Serve returned")
+	}
+}
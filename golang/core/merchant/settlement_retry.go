@@ -0,0 +1,202 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/a2aproject/a2a-go/a2asrv/eventqueue"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// SettlementRetryPolicy controls how transient settlement failures are retried.
+// Only transport errors, facilitator 429/5xx responses and the listed
+// RetryableReasons are retried; deterministic rejections fail immediately.
+type SettlementRetryPolicy struct {
+	// MaxAttempts is the total number of SettlePayment calls, including the first.
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry; it doubles on each retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between two attempts.
+	MaxBackoff time.Duration
+	// Deadline bounds the time spent on all attempts. Zero means no bound
+	// beyond the request context.
+	Deadline time.Duration
+	// RetryableReasons are facilitator error reasons that are safe to retry.
+	RetryableReasons []string
+}
+
+// DefaultSettlementRetryPolicy returns the policy used when none is configured.
+func DefaultSettlementRetryPolicy() SettlementRetryPolicy {
+	return SettlementRetryPolicy{
+		MaxAttempts:      3,
+		InitialBackoff:   500 * time.Millisecond,
+		MaxBackoff:       5 * time.Second,
+		Deadline:         30 * time.Second,
+		RetryableReasons: []string{"facilitator_unavailable", "rpc_unavailable", "transaction_timeout"},
+	}
+}
+
+// WithSettlementRetry overrides the settlement retry policy. A policy with
+// MaxAttempts of 1 disables retries.
+func WithSettlementRetry(policy SettlementRetryPolicy) Option {
+	return func(o *BusinessOrchestrator) {
+		o.settlementRetry = policy
+	}
+}
+
+var facilitatorStatusPattern = regexp.MustCompile(`facilitator settle failed \((\d{3})\)`)
+
+func (p SettlementRetryPolicy) retryable(response *x402core.SettleResponse, err error) bool {
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false
+		}
+		var settleErr *x402core.SettleError
+		if errors.As(err, &settleErr) {
+			return slices.Contains(p.RetryableReasons, settleErr.ErrorReason)
+		}
+		var netErr net.Error
+		if errors.As(err, &netErr) {
+			return true
+		}
+		if match := facilitatorStatusPattern.FindStringSubmatch(err.Error()); match != nil {
+			status, _ := strconv.Atoi(match[1])
+			return status == 429 || status >= 500
+		}
+		return false
+	}
+	return response != nil && !response.Success && slices.Contains(p.RetryableReasons, response.ErrorReason)
+}
+
+func (p SettlementRetryPolicy) backoff(retry int) time.Duration {
+	delay := p.InitialBackoff
+	for i := 1; i < retry && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	if delay <= 0 {
+		return 0
+	}
+	// Full jitter over the upper half keeps retries from synchronizing.
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// settleWithRetry calls settlePayment until it succeeds, fails
+// deterministically, or the policy is exhausted. Between attempts the task
+// stays working with a message saying settlement is pending; a nil queue
+// retries silently.
+func (o *BusinessOrchestrator) settleWithRetry(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	eventQueue eventqueue.Queue,
+	paymentState *state.PaymentState,
+	matchedRequirement *x402types.PaymentRequirements,
+) (*x402core.SettleResponse, error) {
+	// Background settlements run after the task has moved on, so only the
+	// payment is captured here, not the task.
+	defer o.lifecycle.settlementStarted(requestContext.TaskID, &PaymentRecord{
+		Status:       state.PaymentVerified,
+		Requirements: paymentState.Requirements,
+		Payload:      paymentState.Payload,
+	})()
+
+	policy := o.settlementRetry
+	attempts := max(policy.MaxAttempts, 1)
+	if policy.Deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, policy.Deadline)
+		defer cancel()
+	}
+
+	started := time.Now()
+	for attempt := 1; ; attempt++ {
+		settleCtx, span := o.startSpan(ctx, SpanSettle, requestContext.TaskID)
+		setRequirementAttributes(span, *matchedRequirement)
+		response, err := o.settlePayment(settleCtx, requestContext.TaskID, paymentState, matchedRequirement)
+		if err != nil {
+			span.SetAttribute(AttributeErrorCode, settlementErrorCode(response, err))
+		}
+		span.End(err)
+		if err == nil || attempt >= attempts || !policy.retryable(response, err) {
+			o.recordSettlement(paymentState, response, err, started)
+			if err == nil {
+				response = withSettledAmount(response, matchedRequirement)
+				response = o.recordSplits(ctx, requestContext, response, matchedRequirement)
+			}
+			return response, err
+		}
+
+		if eventQueue != nil {
+			pending := a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{
+				Text: fmt.Sprintf("Settlement pending: retrying after transient error (attempt %d of %d)", attempt+1, attempts),
+			})
+			state.SetPaymentStatus(pending, state.PaymentVerified)
+			event := a2a.NewStatusUpdateEvent(requestContext, a2a.TaskStateWorking, pending)
+			if writeErr := eventQueue.Write(ctx, event); writeErr != nil {
+				return response, err
+			}
+		}
+
+		timer := time.NewTimer(policy.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			err = fmt.Errorf("%w (settlement retry deadline: %v)", err, ctx.Err())
+			o.recordSettlement(paymentState, response, err, started)
+			return response, err
+		case <-timer.C:
+		}
+	}
+}
+
+func (o *BusinessOrchestrator) recordSettlement(
+	paymentState *state.PaymentState,
+	response *x402core.SettleResponse,
+	err error,
+	started time.Time,
+) {
+	code := ""
+	if err != nil {
+		code = settlementErrorCode(response, err)
+	}
+	o.metrics.SettlementCompleted(payloadNetwork(paymentState), code, time.Since(started))
+}
+
+// withSettledAmount records the amount actually charged on the receipt when
+// the facilitator leaves it out, which matters for upto settlements below
+// the authorized maximum.
+func withSettledAmount(response *x402core.SettleResponse, requirement *x402types.PaymentRequirements) *x402core.SettleResponse {
+	if response == nil || response.Amount != "" || requirement == nil {
+		return response
+	}
+	receipt := *response
+	receipt.Amount = requirement.Amount
+	return &receipt
+}
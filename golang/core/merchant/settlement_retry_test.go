@@ -0,0 +1,184 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+func TestBusinessOrchestrator_SettlementRetry(t *testing.T) {
+	unavailable := errors.New("facilitator settle failed (502): bad gateway")
+	success := &x402core.SettleResponse{Success: true, Network: x402.NetworkBaseSepolia, Transaction: "0xtx"}
+
+	tests := []struct {
+		name         string
+		results      []error
+		wantCalls    int
+		wantPending  int
+		wantState    a2a.TaskState
+		wantReceipts bool
+	}{
+		{
+			name:         "eventual success",
+			results:      []error{unavailable, unavailable, nil},
+			wantCalls:    3,
+			wantPending:  2,
+			wantState:    a2a.TaskStateCompleted,
+			wantReceipts: true,
+		},
+		{
+			name:        "exhausted",
+			results:     []error{unavailable, unavailable, unavailable, nil},
+			wantCalls:   3,
+			wantPending: 2,
+			wantState:   a2a.TaskStateFailed,
+		},
+		{
+			name:        "non-retryable rejection",
+			results:     []error{x402core.NewSettleError("invalid_signature", "0xpayer", x402.NetworkBaseSepolia, "", "facilitator returned 400"), nil},
+			wantCalls:   1,
+			wantPending: 0,
+			wantState:   a2a.TaskStateFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			orchestrator := newTestOrchestrator(
+				t,
+				&MockResourceServer{
+					SettlePaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.SettleResponse, error) {
+						err := tt.results[calls]
+						calls++
+						if err != nil {
+							return nil, err
+						}
+						return success, nil
+					},
+				},
+				&mockBusinessService{executeFunc: func(ctx context.Context, request business.Request) (*business.Result, error) {
+					return &business.Result{Message: "result"}, nil
+				}},
+				[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+				WithSettlementRetry(SettlementRetryPolicy{
+					MaxAttempts:    3,
+					InitialBackoff: time.Millisecond,
+					MaxBackoff:     2 * time.Millisecond,
+					Deadline:       time.Second,
+				}),
+			)
+
+			requirements := x402types.PaymentRequirements{Scheme: "exact", Network: x402.NetworkBaseSepolia, PayTo: "0x123", Asset: "0x456"}
+			task := &a2a.Task{
+				ID:        "task-retry",
+				ContextID: "context-retry",
+				Status:    a2a.TaskStatus{State: a2a.TaskStateWorking, Message: a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{Text: ""})},
+			}
+			x402state.SetOriginalPrompt(task.Status.Message, "test prompt")
+			paymentState := &x402state.PaymentState{
+				Status:       x402state.PaymentVerified,
+				Payload:      &x402types.PaymentPayload{X402Version: x402.X402Version, Accepted: requirements},
+				Requirements: &x402types.PaymentRequired{X402Version: x402.X402Version, Accepts: []x402types.PaymentRequirements{requirements}},
+			}
+			requestContext := &a2asrv.RequestContext{
+				Message:    a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "paid"}),
+				StoredTask: task,
+				TaskID:     task.ID,
+				ContextID:  task.ContextID,
+			}
+			queue := &mockEventQueue{}
+
+			resultState, err := orchestrator.handlePaymentVerified(context.Background(), requestContext, task, queue, paymentState)
+			if err != nil {
+				t.Fatalf("handlePaymentVerified() error = %v", err)
+			}
+			if resultState.Status == x402state.PaymentCompleted {
+				if err := orchestrator.transitionToCompleted(context.Background(), requestContext, task, queue, resultState); err != nil {
+					t.Fatalf("transitionToCompleted() error = %v", err)
+				}
+			}
+
+			if calls != tt.wantCalls {
+				t.Errorf("SettlePayment calls = %d, want %d", calls, tt.wantCalls)
+			}
+			pending := 0
+			for _, event := range queue.events {
+				update, ok := event.(*a2a.TaskStatusUpdateEvent)
+				if ok && update.Status.State == a2a.TaskStateWorking && strings.HasPrefix(x402state.ExtractMessageText(update.Status.Message), "Settlement pending") {
+					if update.Final {
+						t.Error("settlement pending update must not be final")
+					}
+					pending++
+				}
+			}
+			if pending != tt.wantPending {
+				t.Errorf("pending updates = %d, want %d", pending, tt.wantPending)
+			}
+			if task.Status.State != tt.wantState {
+				t.Errorf("task state = %v, want %v", task.Status.State, tt.wantState)
+			}
+			if tt.wantReceipts && len(resultState.Receipts) != 1 {
+				t.Errorf("receipts = %#v, want one", resultState.Receipts)
+			}
+		})
+	}
+}
+
+func TestSettlementRetryPolicy_Retryable(t *testing.T) {
+	policy := DefaultSettlementRetryPolicy()
+	tests := []struct {
+		name     string
+		response *x402core.SettleResponse
+		err      error
+		want     bool
+	}{
+		{name: "gateway error", err: errors.New("facilitator settle failed (503): unavailable"), want: true},
+		{name: "rate limited", err: errors.New("facilitator settle failed (429): slow down"), want: true},
+		{name: "bad request", err: errors.New("facilitator settle failed (400): bad payload"), want: false},
+		{name: "network error", err: &timeoutError{}, want: true},
+		{name: "retryable reason", err: x402core.NewSettleError("facilitator_unavailable", "", "", "", ""), want: true},
+		{name: "invalid signature", err: x402core.NewSettleError("invalid_signature", "", "", "", ""), want: false},
+		{name: "context canceled", err: context.Canceled, want: false},
+		{name: "unsuccessful response", response: &x402core.SettleResponse{ErrorReason: "insufficient_funds"}, want: false},
+		{name: "retryable response", response: &x402core.SettleResponse{ErrorReason: "rpc_unavailable"}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.retryable(tt.response, tt.err); got != tt.want {
+				t.Errorf("retryable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type timeoutError struct{}
+
+func (*timeoutError) Error() string   { return "i/o timeout" }
+func (*timeoutError) Timeout() bool   { return true }
+func (*timeoutError) Temporary() bool { return true }
@@ -0,0 +1,185 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+)
+
+// ErrShuttingDown is returned by Execute once Shutdown has begun. The task is
+// left as it was, so the client can send the message again once the merchant
+// is back or to another instance.
+var ErrShuttingDown = errors.New("merchant is shutting down")
+
+// lifecycle tracks the executions and settlements in flight so Shutdown can
+// wait for them.
+type lifecycle struct {
+	mu        sync.Mutex
+	closed    bool
+	executing sync.WaitGroup
+	settling  map[a2a.TaskID]*PaymentRecord
+}
+
+// enter registers an execution, reporting false once Shutdown has begun.
+func (l *lifecycle) enter() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return false
+	}
+	l.executing.Add(1)
+	return true
+}
+
+func (l *lifecycle) exit() {
+	l.executing.Done()
+}
+
+// close refuses new executions and waits for the running ones.
+func (l *lifecycle) close(ctx context.Context) error {
+	l.mu.Lock()
+	l.closed = true
+	l.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		l.executing.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("in-flight executions not finished: %w", ctx.Err())
+	}
+}
+
+// settlementStarted records a settlement in flight for taskID until the
+// returned function is called.
+func (l *lifecycle) settlementStarted(taskID a2a.TaskID, record *PaymentRecord) func() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.settling == nil {
+		l.settling = make(map[a2a.TaskID]*PaymentRecord)
+	}
+	l.settling[taskID] = record
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.settling, taskID)
+	}
+}
+
+func (l *lifecycle) inFlightSettlements() map[a2a.TaskID]*PaymentRecord {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	settling := make(map[a2a.TaskID]*PaymentRecord, len(l.settling))
+	for taskID, record := range l.settling {
+		settling[taskID] = record
+	}
+	return settling
+}
+
+// Shutdown stops the quote janitor, stops accepting executions and waits for
+// the running ones, then stops accepting deferred executions and background
+// settlements and waits for pending ones to finish, settles the batch
+// settlement queue, stops the windows of held deliveries, and drains the
+// webhook and push-notification outboxes. Held deliveries stay in the store
+// for the next start.
+//
+// When ctx expires first, every settlement still waiting on the facilitator
+// is saved to the payment state store marked indeterminate: it may or may
+// not land on chain, and the task is reconciled when it is next executed.
+func (o *BusinessOrchestrator) Shutdown(ctx context.Context) error {
+	err := o.drain(ctx)
+	if err != nil {
+		o.persistInFlightSettlements(context.WithoutCancel(ctx))
+	}
+	return err
+}
+
+func (o *BusinessOrchestrator) drain(ctx context.Context) error {
+	if o.quoteJanitor != nil {
+		if err := o.quoteJanitor.shutdown(ctx); err != nil {
+			return err
+		}
+	}
+	if err := o.lifecycle.close(ctx); err != nil {
+		return err
+	}
+	if o.deferredExecution != nil {
+		if err := o.deferredExecution.shutdown(ctx); err != nil {
+			return err
+		}
+	}
+	if o.asyncSettlement != nil {
+		if err := o.asyncSettlement.shutdown(ctx); err != nil {
+			return err
+		}
+	}
+	if o.batchSettlement != nil {
+		if err := o.batchSettlement.shutdown(ctx); err != nil {
+			return err
+		}
+	}
+	if o.escrow != nil {
+		if err := o.escrow.shutdown(ctx); err != nil {
+			return err
+		}
+	}
+	if o.webhooks != nil {
+		if err := o.webhooks.shutdown(ctx); err != nil {
+			return err
+		}
+	}
+	if o.push != nil {
+		return o.push.shutdown(ctx)
+	}
+	return nil
+}
+
+func (o *BusinessOrchestrator) persistInFlightSettlements(ctx context.Context) {
+	for taskID, record := range o.lifecycle.inFlightSettlements() {
+		if o.stateStore == nil {
+			o.logger.ErrorContext(ctx, "x402 settlement outcome unknown at shutdown; no payment state store to record it",
+				"task_id", taskID,
+			)
+			continue
+		}
+		// The verified payment's own record, when the task still has one,
+		// also keeps the prompt.
+		indeterminate := *record
+		if saved, found, err := o.stateStore.LoadState(ctx, taskID); err == nil && found && saved.Status == state.PaymentVerified {
+			indeterminate = *saved
+		}
+		indeterminate.Indeterminate = true
+		if err := o.stateStore.SaveState(ctx, taskID, &indeterminate); err != nil {
+			o.logger.ErrorContext(ctx, "x402 indeterminate settlement not recorded",
+				"task_id", taskID,
+				"error", err,
+			)
+			continue
+		}
+		o.logger.WarnContext(ctx, "x402 settlement outcome unknown at shutdown; recorded for reconciliation",
+			"task_id", taskID,
+		)
+	}
+}
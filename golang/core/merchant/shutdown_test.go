@@ -0,0 +1,141 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// slowSettlement starts a payment whose settlement blocks until release is
+// closed, and returns once the facilitator has been called. done reports the
+// error from the paying Execute.
+func slowSettlement(t *testing.T, opts ...Option) (orchestrator *BusinessOrchestrator, task *a2a.Task, release chan struct{}, done chan error) {
+	t.Helper()
+	release = make(chan struct{})
+	started := make(chan struct{})
+	orchestrator = newTestOrchestrator(
+		t,
+		&MockResourceServer{
+			SettlePaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.SettleResponse, error) {
+				close(started)
+				<-release
+				return &x402core.SettleResponse{Success: true, Network: x402.NetworkBaseSepolia, Transaction: "0xtx"}, nil
+			},
+		},
+		&mockBusinessService{},
+		[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+		opts...,
+	)
+	task = quoteTask(t, orchestrator)
+	submission := paymentSubmission(t, task)
+
+	done = make(chan error, 1)
+	go func() {
+		done <- submitPayment(orchestrator, task, submission, &mockEventQueue{})
+	}()
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("settlement did not start")
+	}
+	return orchestrator, task, release, done
+}
+
+func TestBusinessOrchestrator_Shutdown_RecordsIndeterminateSettlement(t *testing.T) {
+	store := NewMemoryPaymentStateStore()
+	orchestrator, task, release, done := slowSettlement(t, WithPaymentStateStore(store))
+	defer func() {
+		close(release)
+		<-done
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := orchestrator.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	record, found, err := store.LoadState(context.Background(), task.ID)
+	if err != nil || !found {
+		t.Fatalf("LoadState() found = %v, error = %v, want the in-flight settlement", found, err)
+	}
+	if !record.Indeterminate || record.Status != x402state.PaymentVerified || record.Payload == nil || record.Requirements == nil {
+		t.Errorf("record = %+v, want an indeterminate verified payment with its payload", record)
+	}
+
+	// A restarted merchant sees the payment as indeterminate.
+	restored := &a2a.Task{ID: task.ID, ContextID: task.ContextID, Status: a2a.TaskStatus{State: a2a.TaskStateWorking}}
+	if err := newTestOrchestrator(t, &MockResourceServer{}, &mockBusinessService{}, nil,
+		WithPaymentStateStore(store)).restorePaymentState(context.Background(), restored); err != nil {
+		t.Fatalf("restorePaymentState() error = %v", err)
+	}
+	if restored.Status.Message.Metadata[x402.MetadataKeyIndeterminate] != true {
+		t.Error("restored task is not marked indeterminate")
+	}
+}
+
+func TestBusinessOrchestrator_Shutdown_WaitsForInFlightExecution(t *testing.T) {
+	store := NewMemoryPaymentStateStore()
+	orchestrator, task, release, done := slowSettlement(t, WithPaymentStateStore(store))
+
+	shutdownErr := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		shutdownErr <- orchestrator.Shutdown(ctx)
+	}()
+
+	// New executions are refused while the payment finishes.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		err := orchestrator.executeInPlace(context.Background(), &a2asrv.RequestContext{
+			Message:   a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "buy"}),
+			TaskID:    "task-late",
+			ContextID: "context-late",
+		}, &mockEventQueue{})
+		if errors.Is(err, ErrShuttingDown) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Execute() still accepted after Shutdown began")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+
+	if err := <-shutdownErr; err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("paid Execute() error = %v", err)
+	}
+	if task.Status.State != a2a.TaskStateCompleted {
+		t.Errorf("state = %s, want %s", task.Status.State, a2a.TaskStateCompleted)
+	}
+	if _, found, _ := store.LoadState(context.Background(), task.ID); found {
+		t.Error("settled payment left a record in the store")
+	}
+}
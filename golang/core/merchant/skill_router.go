@@ -0,0 +1,59 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/a2aproject/a2a-go/a2a"
+)
+
+// SkillMetadataKey is the message metadata key clients use to name the agent
+// card skill they are invoking.
+const SkillMetadataKey = "skillId"
+
+// SkillRouter decides which agent card skill a new request invokes. An empty
+// result means no specific skill, leaving the business service to apply its
+// default pricing.
+type SkillRouter interface {
+	ResolveSkill(ctx context.Context, message *a2a.Message) (string, error)
+}
+
+// SkillRouterFunc adapts a function to the SkillRouter interface.
+type SkillRouterFunc func(ctx context.Context, message *a2a.Message) (string, error)
+
+func (f SkillRouterFunc) ResolveSkill(ctx context.Context, message *a2a.Message) (string, error) {
+	return f(ctx, message)
+}
+
+// MetadataSkillRouter reads the skill from SkillMetadataKey in the message
+// metadata.
+type MetadataSkillRouter struct{}
+
+func (MetadataSkillRouter) ResolveSkill(ctx context.Context, message *a2a.Message) (string, error) {
+	if message == nil {
+		return "", nil
+	}
+	value, ok := message.Meta()[SkillMetadataKey]
+	if !ok {
+		return "", nil
+	}
+	skillID, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("%s must be a string, got %T", SkillMetadataKey, value)
+	}
+	return skillID, nil
+}
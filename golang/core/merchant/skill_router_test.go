@@ -0,0 +1,124 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402pkg "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+func TestBusinessOrchestrator_SkillPricing(t *testing.T) {
+	prices := map[string]string{
+		"generate-image": "1.00",
+		"upscale-image":  "0.25",
+	}
+
+	tests := []struct {
+		name      string
+		skillID   string
+		wantPrice string
+	}{
+		{name: "generate image", skillID: "generate-image", wantPrice: "1.00"},
+		{name: "upscale image", skillID: "upscale-image", wantPrice: "0.25"},
+		{name: "default skill", wantPrice: "1.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pricedPrices []string
+			var paidSkill string
+			orchestrator := newTestOrchestrator(
+				t,
+				&MockResourceServer{
+					BuildPaymentRequirementsFromConfigFunc: func(ctx context.Context, config x402pkg.ResourceConfig) ([]x402types.PaymentRequirements, error) {
+						pricedPrices = append(pricedPrices, config.Price.(string))
+						return []x402types.PaymentRequirements{{Scheme: "exact", Network: string(config.Network), PayTo: config.PayTo, Asset: "0x456"}}, nil
+					},
+				},
+				&mockBusinessService{executeFunc: func(ctx context.Context, request business.Request) (*business.Result, error) {
+					if request.PaymentVerified {
+						paidSkill = request.SkillID
+						return &business.Result{Message: "done"}, nil
+					}
+					skillID := request.SkillID
+					if skillID == "" {
+						skillID = "generate-image"
+					}
+					return nil, business.NewPaymentRequiredError("pay", business.ServiceRequirements{
+						Price:    prices[skillID],
+						Resource: "/" + skillID,
+						Scheme:   "exact",
+					})
+				}},
+				[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+			)
+
+			message := a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "a cat"})
+			if tt.skillID != "" {
+				message.Metadata = map[string]any{SkillMetadataKey: tt.skillID}
+			}
+			requestContext := &a2asrv.RequestContext{Message: message, TaskID: "task-skill", ContextID: "context-skill"}
+			if err := orchestrator.executeInPlace(context.Background(), requestContext, &mockEventQueue{}); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			task := requestContext.StoredTask
+
+			if len(pricedPrices) != 1 || pricedPrices[0] != tt.wantPrice {
+				t.Errorf("priced at %v, want %s", pricedPrices, tt.wantPrice)
+			}
+			if got := x402state.ExtractSkillID(task); got != tt.skillID {
+				t.Errorf("recorded skill = %q, want %q", got, tt.skillID)
+			}
+
+			submission := paymentSubmission(t, task)
+			if err := submitPayment(orchestrator, task, submission, &mockEventQueue{}); err != nil {
+				t.Fatalf("paid Execute() error = %v", err)
+			}
+			if paidSkill != tt.skillID {
+				t.Errorf("paid execution skill = %q, want %q", paidSkill, tt.skillID)
+			}
+		})
+	}
+}
+
+func TestBusinessOrchestrator_CustomSkillRouter(t *testing.T) {
+	var gotSkill string
+	orchestrator := newTestOrchestrator(
+		t,
+		&MockResourceServer{},
+		&mockBusinessService{executeFunc: func(ctx context.Context, request business.Request) (*business.Result, error) {
+			gotSkill = request.SkillID
+			return &business.Result{Message: "free"}, nil
+		}},
+		[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+		WithSkillRouter(SkillRouterFunc(func(ctx context.Context, message *a2a.Message) (string, error) {
+			return "upscale-image", nil
+		})),
+	)
+
+	quoteRequest(t, orchestrator, "task-router", a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "upscale"}))
+	if gotSkill != "upscale-image" {
+		t.Errorf("skill = %q, want upscale-image", gotSkill)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sqlitereceipts stores merchant receipts in SQLite.
+//
+// The package only speaks database/sql, so the application chooses and
+// registers the driver, for example:
+//
+//	import _ "modernc.org/sqlite"
+//
+//	db, err := sql.Open("sqlite", "receipts.db")
+//	store, err := sqlitereceipts.Open(ctx, db)
+//	m, err := merchant.NewMerchant(ctx, url, service, networks, merchant.WithReceiptStore(store))
+package sqlitereceipts
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/merchant"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS x402_receipts (
+	id              INTEGER PRIMARY KEY AUTOINCREMENT,
+	task_id         TEXT    NOT NULL,
+	context_id      TEXT    NOT NULL,
+	payer           TEXT    NOT NULL,
+	network         TEXT    NOT NULL,
+	asset           TEXT    NOT NULL,
+	amount          TEXT    NOT NULL,
+	transaction_ref TEXT    NOT NULL,
+	settled_at      INTEGER NOT NULL,
+	recorded_at     INTEGER NOT NULL,
+	pay_to          TEXT    NOT NULL,
+	refund          INTEGER NOT NULL,
+	reason          TEXT    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS x402_receipts_task ON x402_receipts (task_id);
+CREATE INDEX IF NOT EXISTS x402_receipts_payer ON x402_receipts (payer COLLATE NOCASE);
+CREATE INDEX IF NOT EXISTS x402_receipts_settled ON x402_receipts (settled_at);
+`
+
+const columns = `task_id, context_id, payer, network, asset, amount, transaction_ref, settled_at, recorded_at, pay_to, refund, reason`
+
+// Store is a merchant.ReceiptStore backed by a SQLite database. Timestamps are
+// kept as Unix nanoseconds.
+type Store struct {
+	db *sql.DB
+}
+
+var _ merchant.ReceiptStore = (*Store)(nil)
+
+// Open creates the receipts table in db if it does not exist yet.
+func Open(ctx context.Context, db *sql.DB) (*Store, error) {
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		return nil, fmt.Errorf("failed to create receipts table: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Append(ctx context.Context, record *merchant.ReceiptRecord) error {
+	if record == nil {
+		return fmt.Errorf("receipt record is required")
+	}
+	recordedAt := record.RecordedAt
+	if recordedAt.IsZero() {
+		recordedAt = time.Now()
+	}
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO x402_receipts (`+columns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		string(record.TaskID), record.ContextID, record.Payer, record.Network, record.Asset,
+		record.Amount, record.Transaction, record.SettledAt.UnixNano(), recordedAt.UnixNano(),
+		record.PayTo, record.Refund, record.Reason,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to append receipt: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) ListByPayer(ctx context.Context, payer string) ([]*merchant.ReceiptRecord, error) {
+	return s.query(ctx, `payer = ? COLLATE NOCASE`, payer)
+}
+
+func (s *Store) ListByTimeRange(ctx context.Context, from, to time.Time) ([]*merchant.ReceiptRecord, error) {
+	return s.query(ctx, `settled_at >= ? AND settled_at < ?`, from.UnixNano(), to.UnixNano())
+}
+
+func (s *Store) GetByTask(ctx context.Context, taskID a2a.TaskID) ([]*merchant.ReceiptRecord, error) {
+	return s.query(ctx, `task_id = ?`, string(taskID))
+}
+
+func (s *Store) query(ctx context.Context, where string, args ...any) ([]*merchant.ReceiptRecord, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+columns+` FROM x402_receipts WHERE `+where+` ORDER BY settled_at, task_id, transaction_ref, id`,
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query receipts: %w", err)
+	}
+	defer rows.Close()
+
+	var records []*merchant.ReceiptRecord
+	for rows.Next() {
+		var record merchant.ReceiptRecord
+		var taskID string
+		var settledAt, recordedAt int64
+		if err := rows.Scan(&taskID, &record.ContextID, &record.Payer, &record.Network, &record.Asset,
+			&record.Amount, &record.Transaction, &settledAt, &recordedAt, &record.PayTo,
+			&record.Refund, &record.Reason); err != nil {
+			return nil, fmt.Errorf("failed to read receipt: %w", err)
+		}
+		record.TaskID = a2a.TaskID(taskID)
+		record.SettledAt = time.Unix(0, settledAt).UTC()
+		record.RecordedAt = time.Unix(0, recordedAt).UTC()
+		records = append(records, &record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read receipts: %w", err)
+	}
+	return records, nil
+}
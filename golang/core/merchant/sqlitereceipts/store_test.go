@@ -0,0 +1,194 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlitereceipts
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/merchant"
+	_ "modernc.org/sqlite"
+)
+
+var settled = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func openStore(t *testing.T) (*Store, *sql.DB) {
+	t.Helper()
+	dsn := filepath.Join(t.TempDir(), "receipts.db") + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	store, err := Open(context.Background(), db)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	return store, db
+}
+
+func record(taskID a2a.TaskID, payer string, at time.Time) *merchant.ReceiptRecord {
+	return &merchant.ReceiptRecord{
+		TaskID:      taskID,
+		ContextID:   "context-" + string(taskID),
+		Payer:       payer,
+		Network:     "eip155:84532",
+		Asset:       "0xasset",
+		PayTo:       "0xmerchant",
+		Amount:      "1000",
+		Transaction: "0x" + string(taskID),
+		SettledAt:   at,
+		RecordedAt:  at.Add(time.Second),
+	}
+}
+
+func transactions(records []*merchant.ReceiptRecord) []string {
+	var refs []string
+	for _, r := range records {
+		refs = append(refs, r.Transaction)
+	}
+	return refs
+}
+
+func TestStore_Queries(t *testing.T) {
+	ctx := context.Background()
+	store, _ := openStore(t)
+
+	refund := record("task-a", "0xAlice", settled.Add(2*time.Hour))
+	refund.Transaction = "0xrefund"
+	refund.Refund = true
+	refund.Reason = "not delivered"
+	refund.Amount = "400"
+	for _, r := range []*merchant.ReceiptRecord{
+		record("task-b", "0xbob", settled.Add(time.Hour)),
+		record("task-a", "0xAlice", settled),
+		refund,
+		record("task-c", "0xalice", settled.Add(3*time.Hour)),
+	} {
+		if err := store.Append(ctx, r); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	byTask, err := store.GetByTask(ctx, "task-a")
+	if err != nil {
+		t.Fatalf("GetByTask() error = %v", err)
+	}
+	if got := fmt.Sprint(transactions(byTask)); got != "[0xtask-a 0xrefund]" {
+		t.Errorf("GetByTask() = %s, want the payment then its refund", got)
+	}
+	if got := *byTask[1]; got != *refund {
+		t.Errorf("refund read back as %+v, want %+v", got, *refund)
+	}
+
+	byPayer, err := store.ListByPayer(ctx, "0xALICE")
+	if err != nil {
+		t.Fatalf("ListByPayer() error = %v", err)
+	}
+	if got := fmt.Sprint(transactions(byPayer)); got != "[0xtask-a 0xrefund 0xtask-c]" {
+		t.Errorf("ListByPayer() = %s, want every record of the payer in any case", got)
+	}
+
+	inRange, err := store.ListByTimeRange(ctx, settled.Add(time.Hour), settled.Add(3*time.Hour))
+	if err != nil {
+		t.Fatalf("ListByTimeRange() error = %v", err)
+	}
+	if got := fmt.Sprint(transactions(inRange)); got != "[0xtask-b 0xrefund]" {
+		t.Errorf("ListByTimeRange() = %s, want records in [from, to)", got)
+	}
+
+	if none, err := store.GetByTask(ctx, "task-missing"); err != nil || len(none) != 0 {
+		t.Errorf("GetByTask(missing) = %v, %v, want none", none, err)
+	}
+}
+
+func TestStore_AppendFillsRecordedAt(t *testing.T) {
+	ctx := context.Background()
+	store, _ := openStore(t)
+	r := record("task-a", "0xalice", settled)
+	r.RecordedAt = time.Time{}
+
+	before := time.Now()
+	if err := store.Append(ctx, r); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	records, err := store.GetByTask(ctx, "task-a")
+	if err != nil || len(records) != 1 {
+		t.Fatalf("GetByTask() = %v, %v, want one record", records, err)
+	}
+	if records[0].RecordedAt.Before(before) {
+		t.Errorf("RecordedAt = %s, want the time of the append", records[0].RecordedAt)
+	}
+	if err := store.Append(ctx, nil); err == nil {
+		t.Error("Append(nil) error = nil, want an error")
+	}
+}
+
+func TestStore_ConcurrentAppends(t *testing.T) {
+	ctx := context.Background()
+	store, _ := openStore(t)
+
+	const writers, perWriter = 8, 25
+	var wg sync.WaitGroup
+	for w := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range perWriter {
+				r := record(a2a.TaskID(fmt.Sprintf("task-%d-%d", w, i)), "0xalice", settled.Add(time.Duration(i)*time.Second))
+				if err := store.Append(ctx, r); err != nil {
+					t.Errorf("Append() error = %v", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	records, err := store.ListByPayer(ctx, "0xalice")
+	if err != nil {
+		t.Fatalf("ListByPayer() error = %v", err)
+	}
+	if len(records) != writers*perWriter {
+		t.Fatalf("records = %d, want %d", len(records), writers*perWriter)
+	}
+	for i := 1; i < len(records); i++ {
+		if records[i].SettledAt.Before(records[i-1].SettledAt) {
+			t.Fatalf("records out of settlement order at %d", i)
+		}
+	}
+}
+
+func TestOpen_KeepsExistingRecords(t *testing.T) {
+	ctx := context.Background()
+	store, db := openStore(t)
+	if err := store.Append(ctx, record("task-a", "0xalice", settled)); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+
+	reopened, err := Open(ctx, db)
+	if err != nil {
+		t.Fatalf("second Open() error = %v", err)
+	}
+	if records, err := reopened.GetByTask(ctx, "task-a"); err != nil || len(records) != 1 {
+		t.Errorf("GetByTask() after reopening = %v, %v, want the earlier record", records, err)
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/a2aproject/a2a-go/a2asrv/eventqueue"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+)
+
+// maxStateMachineSteps bounds a single Execute. The longest legitimate run is
+// required -> submitted -> verified -> completed.
+const maxStateMachineSteps = 10
+
+// stepFunc handles one payment status and returns the next state, or done
+// once the execution has finished.
+type stepFunc func(paymentState *state.PaymentState) (next *state.PaymentState, done bool, err error)
+
+// runStateMachine drives step until the task is terminal or a step reports
+// done. A step that leaves both the payment status and the task state
+// unchanged, or a run past maxStateMachineSteps, fails the task instead of
+// spinning forever.
+func (o *BusinessOrchestrator) runStateMachine(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	eventQueue eventqueue.Queue,
+	paymentState *state.PaymentState,
+	step stepFunc,
+) error {
+	type position struct {
+		status    state.PaymentStatus
+		taskState a2a.TaskState
+	}
+	var previous position
+	for iteration := 0; ; iteration++ {
+		if task.Status.State.Terminal() {
+			return nil
+		}
+		if paymentState == nil {
+			return o.failStuck(ctx, requestContext, task, eventQueue, nil,
+				fmt.Errorf("payment state handler returned no state after %s", previous.status))
+		}
+
+		current := position{status: paymentState.Status, taskState: task.Status.State}
+		switch {
+		case iteration >= maxStateMachineSteps:
+			return o.failStuck(ctx, requestContext, task, eventQueue, paymentState,
+				fmt.Errorf("payment state machine exceeded %d steps at %s/%s", maxStateMachineSteps, current.status, current.taskState))
+		case iteration > 0 && current == previous:
+			return o.failStuck(ctx, requestContext, task, eventQueue, paymentState,
+				fmt.Errorf("payment state machine made no progress from %s/%s", current.status, current.taskState))
+		}
+		previous = current
+
+		next, done, err := step(paymentState)
+		if done || err != nil {
+			return err
+		}
+		paymentState = next
+	}
+}
+
+func (o *BusinessOrchestrator) failStuck(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	eventQueue eventqueue.Queue,
+	paymentState *state.PaymentState,
+	err error,
+) error {
+	o.logger.ErrorContext(ctx, "x402 orchestrator stuck",
+		"task_id", task.ID,
+		"context_id", task.ContextID,
+		"task_state", task.Status.State,
+		"error", err,
+	)
+	if paymentState == nil {
+		paymentState = &state.PaymentState{}
+	}
+	_, failErr := o.failPayment(ctx, requestContext, task, eventQueue, paymentState, err, x402pkg.ErrorCodeOrchestratorStuck, nil)
+	return failErr
+}
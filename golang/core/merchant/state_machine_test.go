@@ -0,0 +1,111 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+)
+
+func TestBusinessOrchestrator_runStateMachine(t *testing.T) {
+	tests := []struct {
+		name      string
+		step      func(paymentState *x402state.PaymentState) (*x402state.PaymentState, bool)
+		wantCalls int
+		wantStuck bool
+	}{
+		{
+			name: "never advances",
+			step: func(paymentState *x402state.PaymentState) (*x402state.PaymentState, bool) {
+				return paymentState, false
+			},
+			wantCalls: 1,
+			wantStuck: true,
+		},
+		{
+			name: "oscillates forever",
+			step: func(paymentState *x402state.PaymentState) (*x402state.PaymentState, bool) {
+				if paymentState.Status == x402state.PaymentSubmitted {
+					return &x402state.PaymentState{Status: x402state.PaymentVerified}, false
+				}
+				return &x402state.PaymentState{Status: x402state.PaymentSubmitted}, false
+			},
+			wantCalls: maxStateMachineSteps,
+			wantStuck: true,
+		},
+		{
+			name: "returns no state",
+			step: func(paymentState *x402state.PaymentState) (*x402state.PaymentState, bool) {
+				return nil, false
+			},
+			wantCalls: 1,
+			wantStuck: true,
+		},
+		{
+			name: "legitimate progression",
+			step: func(paymentState *x402state.PaymentState) (*x402state.PaymentState, bool) {
+				switch paymentState.Status {
+				case x402state.PaymentRequired:
+					return &x402state.PaymentState{Status: x402state.PaymentSubmitted}, false
+				case x402state.PaymentSubmitted:
+					return &x402state.PaymentState{Status: x402state.PaymentVerified}, false
+				default:
+					return nil, true
+				}
+			},
+			wantCalls: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orchestrator := newTestOrchestrator(t, &MockResourceServer{}, &mockBusinessService{}, nil)
+			task := &a2a.Task{ID: "task-loop", ContextID: "context-loop", Status: a2a.TaskStatus{State: a2a.TaskStateWorking}}
+			requestContext := &a2asrv.RequestContext{StoredTask: task, TaskID: task.ID, ContextID: task.ContextID}
+
+			calls := 0
+			err := orchestrator.runStateMachine(context.Background(), requestContext, task, &mockEventQueue{},
+				&x402state.PaymentState{Status: x402state.PaymentRequired},
+				func(paymentState *x402state.PaymentState) (*x402state.PaymentState, bool, error) {
+					calls++
+					next, done := tt.step(paymentState)
+					return next, done, nil
+				})
+			if err != nil {
+				t.Fatalf("runStateMachine() error = %v", err)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("step calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if !tt.wantStuck {
+				if task.Status.State != a2a.TaskStateWorking {
+					t.Errorf("task state = %v, want it untouched", task.Status.State)
+				}
+				return
+			}
+			if task.Status.State != a2a.TaskStateFailed {
+				t.Fatalf("task state = %v, want %v", task.Status.State, a2a.TaskStateFailed)
+			}
+			if got := task.Status.Message.Metadata[x402.MetadataKeyError]; got != x402.ErrorCodeOrchestratorStuck {
+				t.Errorf("error code = %v, want %s", got, x402.ErrorCodeOrchestratorStuck)
+			}
+		})
+	}
+}
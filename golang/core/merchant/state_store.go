@@ -0,0 +1,583 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// PaymentRecord is the durable copy of a task's in-flight payment state.
+type PaymentRecord struct {
+	Status         state.PaymentStatus        `json:"status"`
+	Requirements   *x402types.PaymentRequired `json:"requirements,omitempty"`
+	Payload        *x402types.PaymentPayload  `json:"payload,omitempty"`
+	OriginalPrompt string                     `json:"originalPrompt,omitempty"`
+	SkillID        string                     `json:"skillId,omitempty"`
+	// RequestMetadata is the client metadata from the message that started
+	// the task, without the x402 keys.
+	RequestMetadata map[string]interface{} `json:"requestMetadata,omitempty"`
+	// Indeterminate marks a payment whose settlement was still waiting on the
+	// facilitator when the merchant shut down; it may already be on chain.
+	Indeterminate bool `json:"indeterminate,omitempty"`
+	// SettlingSince is when settlement of the verified payment began. A record
+	// that still carries it after a restart belongs to a settlement whose
+	// outcome the merchant never saw.
+	SettlingSince time.Time `json:"settlingSince,omitzero"`
+}
+
+// PaymentStateStore persists payment state outside the task so it survives a
+// merchant restart.
+//
+// The orchestrator saves a record before announcing payment-required,
+// payment-verified or delivery-pending-ack, and again before settling a
+// verified payment, and deletes it once the task reaches a terminal state.
+// SaveState must be durable when it returns; a record that outlives its task
+// is harmless because it is only consulted for tasks without payment metadata.
+// Implementations must be safe for concurrent use.
+type PaymentStateStore interface {
+	SaveState(ctx context.Context, taskID a2a.TaskID, record *PaymentRecord) error
+	LoadState(ctx context.Context, taskID a2a.TaskID) (*PaymentRecord, bool, error)
+	Delete(ctx context.Context, taskID a2a.TaskID) error
+}
+
+// PaymentStateLister lists every persisted record so Recover can find the
+// payments an earlier process left unfinished. Both stores in this package
+// implement it.
+type PaymentStateLister interface {
+	PaymentStates(ctx context.Context) (map[a2a.TaskID]*PaymentRecord, error)
+}
+
+// PendingSettlement is a verified payment waiting in the batch settlement
+// queue. Its task has already completed.
+type PendingSettlement struct {
+	TaskID      a2a.TaskID                     `json:"taskId"`
+	ContextID   string                         `json:"contextId"`
+	Payer       string                         `json:"payer,omitempty"`
+	Payload     *x402types.PaymentPayload      `json:"payload"`
+	Requirement *x402types.PaymentRequirements `json:"requirement"`
+	QueuedAt    time.Time                      `json:"queuedAt"`
+}
+
+// PendingSettlementStore holds the batch settlement queue so payments queued
+// before a crash are settled after the restart. A PaymentStateStore used with
+// WithBatchSettlement should implement it; both stores in this package do.
+// Implementations must be safe for concurrent use.
+type PendingSettlementStore interface {
+	SavePendingSettlement(ctx context.Context, pending *PendingSettlement) error
+	// PendingSettlements returns every queued payment, oldest first.
+	PendingSettlements(ctx context.Context) ([]*PendingSettlement, error)
+	DeletePendingSettlement(ctx context.Context, taskID a2a.TaskID) error
+}
+
+// PendingDelivery is a delivered task whose verified payment is held until the
+// client acknowledges the delivery or its window closes.
+type PendingDelivery struct {
+	TaskID      a2a.TaskID                     `json:"taskId"`
+	ContextID   string                         `json:"contextId"`
+	Payer       string                         `json:"payer,omitempty"`
+	Payload     *x402types.PaymentPayload      `json:"payload"`
+	Requirement *x402types.PaymentRequirements `json:"requirement"`
+	Deadline    time.Time                      `json:"deadline"`
+}
+
+// PendingDeliveryStore holds deliveries awaiting acknowledgement so their
+// windows still close after a restart. A PaymentStateStore used with
+// WithEscrowPolicy should implement it; both stores in this package do.
+// Implementations must be safe for concurrent use.
+type PendingDeliveryStore interface {
+	SavePendingDelivery(ctx context.Context, pending *PendingDelivery) error
+	// PendingDeliveries returns every held delivery, earliest deadline first.
+	PendingDeliveries(ctx context.Context) ([]*PendingDelivery, error)
+	DeletePendingDelivery(ctx context.Context, taskID a2a.TaskID) error
+}
+
+// MemoryPaymentStateStore keeps records in memory. It survives orchestrator
+// re-creation within a process but not a process restart.
+type MemoryPaymentStateStore struct {
+	mu      sync.RWMutex
+	records map[a2a.TaskID]PaymentRecord
+	pending map[a2a.TaskID]PendingSettlement
+	held    map[a2a.TaskID]PendingDelivery
+}
+
+func NewMemoryPaymentStateStore() *MemoryPaymentStateStore {
+	return &MemoryPaymentStateStore{
+		records: make(map[a2a.TaskID]PaymentRecord),
+		pending: make(map[a2a.TaskID]PendingSettlement),
+		held:    make(map[a2a.TaskID]PendingDelivery),
+	}
+}
+
+func (s *MemoryPaymentStateStore) SaveState(ctx context.Context, taskID a2a.TaskID, record *PaymentRecord) error {
+	if record == nil {
+		return fmt.Errorf("payment record is required")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[taskID] = *record
+	return nil
+}
+
+func (s *MemoryPaymentStateStore) LoadState(ctx context.Context, taskID a2a.TaskID) (*PaymentRecord, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	record, ok := s.records[taskID]
+	if !ok {
+		return nil, false, nil
+	}
+	return &record, true, nil
+}
+
+func (s *MemoryPaymentStateStore) Delete(ctx context.Context, taskID a2a.TaskID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, taskID)
+	return nil
+}
+
+func (s *MemoryPaymentStateStore) PaymentStates(ctx context.Context) (map[a2a.TaskID]*PaymentRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	records := make(map[a2a.TaskID]*PaymentRecord, len(s.records))
+	for taskID, record := range s.records {
+		records[taskID] = &record
+	}
+	return records, nil
+}
+
+func (s *MemoryPaymentStateStore) SavePendingSettlement(ctx context.Context, pending *PendingSettlement) error {
+	if pending == nil {
+		return fmt.Errorf("pending settlement is required")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending[pending.TaskID] = *pending
+	return nil
+}
+
+func (s *MemoryPaymentStateStore) PendingSettlements(ctx context.Context) ([]*PendingSettlement, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	pending := make([]*PendingSettlement, 0, len(s.pending))
+	for _, settlement := range s.pending {
+		pending = append(pending, &settlement)
+	}
+	sortPendingSettlements(pending)
+	return pending, nil
+}
+
+func (s *MemoryPaymentStateStore) DeletePendingSettlement(ctx context.Context, taskID a2a.TaskID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.pending, taskID)
+	return nil
+}
+
+func (s *MemoryPaymentStateStore) SavePendingDelivery(ctx context.Context, pending *PendingDelivery) error {
+	if pending == nil {
+		return fmt.Errorf("pending delivery is required")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.held[pending.TaskID] = *pending
+	return nil
+}
+
+func (s *MemoryPaymentStateStore) PendingDeliveries(ctx context.Context) ([]*PendingDelivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	held := make([]*PendingDelivery, 0, len(s.held))
+	for _, delivery := range s.held {
+		held = append(held, &delivery)
+	}
+	sortPendingDeliveries(held)
+	return held, nil
+}
+
+func (s *MemoryPaymentStateStore) DeletePendingDelivery(ctx context.Context, taskID a2a.TaskID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.held, taskID)
+	return nil
+}
+
+func sortPendingDeliveries(held []*PendingDelivery) {
+	slices.SortFunc(held, func(a, b *PendingDelivery) int {
+		if c := a.Deadline.Compare(b.Deadline); c != 0 {
+			return c
+		}
+		return strings.Compare(string(a.TaskID), string(b.TaskID))
+	})
+}
+
+func sortPendingSettlements(pending []*PendingSettlement) {
+	slices.SortFunc(pending, func(a, b *PendingSettlement) int {
+		if c := a.QueuedAt.Compare(b.QueuedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(string(a.TaskID), string(b.TaskID))
+	})
+}
+
+// FilePaymentStateStore keeps one JSON file per task in a directory. Files are
+// replaced atomically, so a crash leaves either the old or the new record.
+// Pending batch settlements live in a "pending" subdirectory and deliveries
+// awaiting acknowledgement in an "escrow" one.
+type FilePaymentStateStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+func NewFilePaymentStateStore(dir string) (*FilePaymentStateStore, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("payment state directory is required")
+	}
+	for _, sub := range []string{pendingSettlementDir, pendingDeliveryDir} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o700); err != nil {
+			return nil, fmt.Errorf("failed to create payment state directory: %w", err)
+		}
+	}
+	return &FilePaymentStateStore{dir: dir}, nil
+}
+
+func (s *FilePaymentStateStore) SaveState(ctx context.Context, taskID a2a.TaskID, record *PaymentRecord) error {
+	if record == nil {
+		return fmt.Errorf("payment record is required")
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode payment record: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.writeFile(s.dir, s.path(taskID), data); err != nil {
+		return fmt.Errorf("failed to write payment record: %w", err)
+	}
+	return nil
+}
+
+// writeFile replaces path with data atomically.
+func (s *FilePaymentStateStore) writeFile(dir, path string, data []byte) error {
+	tmp, err := os.CreateTemp(dir, "payment-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *FilePaymentStateStore) LoadState(ctx context.Context, taskID a2a.TaskID) (*PaymentRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, err := os.ReadFile(s.path(taskID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read payment record: %w", err)
+	}
+	var record PaymentRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, false, fmt.Errorf("failed to decode payment record: %w", err)
+	}
+	return &record, true, nil
+}
+
+func (s *FilePaymentStateStore) Delete(ctx context.Context, taskID a2a.TaskID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Remove(s.path(taskID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete payment record: %w", err)
+	}
+	return nil
+}
+
+func (s *FilePaymentStateStore) PaymentStates(ctx context.Context) (map[a2a.TaskID]*PaymentRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payment records: %w", err)
+	}
+	records := make(map[a2a.TaskID]*PaymentRecord)
+	for _, entry := range entries {
+		name, isRecord := strings.CutSuffix(entry.Name(), ".json")
+		if entry.IsDir() || !isRecord {
+			continue
+		}
+		taskID, err := url.PathUnescape(name)
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read payment record: %w", err)
+		}
+		var record PaymentRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			return nil, fmt.Errorf("failed to decode payment record %s: %w", entry.Name(), err)
+		}
+		records[a2a.TaskID(taskID)] = &record
+	}
+	return records, nil
+}
+
+func (s *FilePaymentStateStore) path(taskID a2a.TaskID) string {
+	return filepath.Join(s.dir, url.PathEscape(string(taskID))+".json")
+}
+
+const pendingSettlementDir = "pending"
+
+func (s *FilePaymentStateStore) SavePendingSettlement(ctx context.Context, pending *PendingSettlement) error {
+	if pending == nil {
+		return fmt.Errorf("pending settlement is required")
+	}
+	data, err := json.Marshal(pending)
+	if err != nil {
+		return fmt.Errorf("failed to encode pending settlement: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	dir := filepath.Join(s.dir, pendingSettlementDir)
+	if err := s.writeFile(dir, s.pendingPath(pending.TaskID), data); err != nil {
+		return fmt.Errorf("failed to write pending settlement: %w", err)
+	}
+	return nil
+}
+
+func (s *FilePaymentStateStore) PendingSettlements(ctx context.Context) ([]*PendingSettlement, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	dir := filepath.Join(s.dir, pendingSettlementDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending settlements: %w", err)
+	}
+	var pending []*PendingSettlement
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read pending settlement: %w", err)
+		}
+		var settlement PendingSettlement
+		if err := json.Unmarshal(data, &settlement); err != nil {
+			return nil, fmt.Errorf("failed to decode pending settlement %s: %w", entry.Name(), err)
+		}
+		pending = append(pending, &settlement)
+	}
+	sortPendingSettlements(pending)
+	return pending, nil
+}
+
+func (s *FilePaymentStateStore) DeletePendingSettlement(ctx context.Context, taskID a2a.TaskID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Remove(s.pendingPath(taskID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete pending settlement: %w", err)
+	}
+	return nil
+}
+
+func (s *FilePaymentStateStore) pendingPath(taskID a2a.TaskID) string {
+	return filepath.Join(s.dir, pendingSettlementDir, url.PathEscape(string(taskID))+".json")
+}
+
+const pendingDeliveryDir = "escrow"
+
+func (s *FilePaymentStateStore) SavePendingDelivery(ctx context.Context, pending *PendingDelivery) error {
+	if pending == nil {
+		return fmt.Errorf("pending delivery is required")
+	}
+	data, err := json.Marshal(pending)
+	if err != nil {
+		return fmt.Errorf("failed to encode pending delivery: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	dir := filepath.Join(s.dir, pendingDeliveryDir)
+	if err := s.writeFile(dir, s.pendingDeliveryPath(pending.TaskID), data); err != nil {
+		return fmt.Errorf("failed to write pending delivery: %w", err)
+	}
+	return nil
+}
+
+func (s *FilePaymentStateStore) PendingDeliveries(ctx context.Context) ([]*PendingDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	dir := filepath.Join(s.dir, pendingDeliveryDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending deliveries: %w", err)
+	}
+	var held []*PendingDelivery
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read pending delivery: %w", err)
+		}
+		var delivery PendingDelivery
+		if err := json.Unmarshal(data, &delivery); err != nil {
+			return nil, fmt.Errorf("failed to decode pending delivery %s: %w", entry.Name(), err)
+		}
+		held = append(held, &delivery)
+	}
+	sortPendingDeliveries(held)
+	return held, nil
+}
+
+func (s *FilePaymentStateStore) DeletePendingDelivery(ctx context.Context, taskID a2a.TaskID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Remove(s.pendingDeliveryPath(taskID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete pending delivery: %w", err)
+	}
+	return nil
+}
+
+func (s *FilePaymentStateStore) pendingDeliveryPath(taskID a2a.TaskID) string {
+	return filepath.Join(s.dir, pendingDeliveryDir, url.PathEscape(string(taskID))+".json")
+}
+
+func (o *BusinessOrchestrator) savePaymentState(ctx context.Context, task *a2a.Task, paymentState *state.PaymentState) error {
+	if o.stateStore == nil {
+		return nil
+	}
+	if err := o.stateStore.SaveState(ctx, task.ID, paymentRecord(task, paymentState)); err != nil {
+		return fmt.Errorf("failed to persist payment state: %w", err)
+	}
+	return nil
+}
+
+// markSettling records that settlement of the task's verified payment is
+// about to start, so that a merchant restarted before it finishes reconciles
+// the payment rather than settling it again.
+func (o *BusinessOrchestrator) markSettling(ctx context.Context, task *a2a.Task, paymentState *state.PaymentState) error {
+	if o.stateStore == nil {
+		return nil
+	}
+	record := paymentRecord(task, paymentState)
+	record.Status = state.PaymentVerified
+	record.SettlingSince = o.now()
+	if err := o.stateStore.SaveState(ctx, task.ID, record); err != nil {
+		return fmt.Errorf("failed to persist settlement start: %w", err)
+	}
+	return nil
+}
+
+func paymentRecord(task *a2a.Task, paymentState *state.PaymentState) *PaymentRecord {
+	return &PaymentRecord{
+		Status:          paymentState.Status,
+		Requirements:    paymentState.Requirements,
+		Payload:         paymentState.Payload,
+		OriginalPrompt:  state.ExtractOriginalPrompt(task),
+		SkillID:         state.ExtractSkillID(task),
+		RequestMetadata: state.ExtractRequestMetadata(task),
+	}
+}
+
+func (o *BusinessOrchestrator) deletePaymentState(ctx context.Context, task *a2a.Task) error {
+	if o.stateStore == nil {
+		return nil
+	}
+	if err := o.stateStore.Delete(ctx, task.ID); err != nil {
+		return fmt.Errorf("failed to delete persisted payment state: %w", err)
+	}
+	return nil
+}
+
+// restorePaymentState copies a persisted record back onto a task that has lost
+// its payment metadata, e.g. after a merchant restart.
+func (o *BusinessOrchestrator) restorePaymentState(ctx context.Context, task *a2a.Task) error {
+	if o.stateStore == nil {
+		return nil
+	}
+	status, err := state.ExtractPaymentStatus(task)
+	if err != nil || status != "" {
+		return err
+	}
+	record, found, err := o.stateStore.LoadState(ctx, task.ID)
+	if err != nil || !found {
+		return err
+	}
+
+	switch record.Status {
+	case state.PaymentRequired:
+		task.Status.State = a2a.TaskStateInputRequired
+		if err := state.RecordPaymentRequired(task, record.Requirements, "Payment required"); err != nil {
+			return err
+		}
+	case state.PaymentVerified:
+		task.Status.State = a2a.TaskStateWorking
+		if err := state.RecordPaymentVerified(task, &state.PaymentState{
+			Status:       state.PaymentVerified,
+			Requirements: record.Requirements,
+			Payload:      record.Payload,
+		}, "Payment verified"); err != nil {
+			return err
+		}
+	case state.PaymentDeliveryPendingAck:
+		task.Status.State = a2a.TaskStateInputRequired
+		if err := state.RecordPaymentVerified(task, &state.PaymentState{
+			Status:       state.PaymentDeliveryPendingAck,
+			Requirements: record.Requirements,
+			Payload:      record.Payload,
+		}, "Delivered; acknowledge to release payment"); err != nil {
+			return err
+		}
+	default:
+		return nil
+	}
+	state.SetOriginalPrompt(task.Status.Message, record.OriginalPrompt)
+	state.SetSkillID(task.Status.Message, record.SkillID)
+	state.SetRequestMetadata(task.Status.Message, record.RequestMetadata)
+	if record.Indeterminate {
+		state.SetPaymentIndeterminate(task.Status.Message)
+	}
+	return nil
+}
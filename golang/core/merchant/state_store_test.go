@@ -0,0 +1,243 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+func TestFilePaymentStateStore_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFilePaymentStateStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFilePaymentStateStore() error = %v", err)
+	}
+
+	taskID := a2a.TaskID("task/with spaces")
+	if _, found, err := store.LoadState(ctx, taskID); err != nil || found {
+		t.Fatalf("LoadState() before save found = %v, error = %v", found, err)
+	}
+
+	record := &PaymentRecord{
+		Status: x402state.PaymentRequired,
+		Requirements: &x402types.PaymentRequired{
+			X402Version: x402.X402Version,
+			Accepts:     []x402types.PaymentRequirements{{Scheme: "exact", Network: x402.NetworkBaseSepolia, Amount: "100"}},
+		},
+		OriginalPrompt: "buy a thing",
+	}
+	if err := store.SaveState(ctx, taskID, record); err != nil {
+		t.Fatalf("SaveState() error = %v", err)
+	}
+
+	loaded, found, err := store.LoadState(ctx, taskID)
+	if err != nil || !found {
+		t.Fatalf("LoadState() found = %v, error = %v", found, err)
+	}
+	if loaded.Status != record.Status || loaded.OriginalPrompt != record.OriginalPrompt {
+		t.Errorf("loaded record = %#v, want %#v", loaded, record)
+	}
+	if loaded.Requirements == nil || len(loaded.Requirements.Accepts) != 1 || loaded.Requirements.Accepts[0].Amount != "100" {
+		t.Errorf("loaded requirements = %#v", loaded.Requirements)
+	}
+
+	if err := store.Delete(ctx, taskID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, found, _ := store.LoadState(ctx, taskID); found {
+		t.Error("record still present after Delete()")
+	}
+	if err := store.Delete(ctx, taskID); err != nil {
+		t.Errorf("Delete() of missing record error = %v", err)
+	}
+}
+
+func TestFilePaymentStateStore_PendingSettlements(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := NewFilePaymentStateStore(dir)
+	if err != nil {
+		t.Fatalf("NewFilePaymentStateStore() error = %v", err)
+	}
+
+	queued := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i, taskID := range []a2a.TaskID{"task/later", "task-earlier"} {
+		pending := &PendingSettlement{
+			TaskID:      taskID,
+			ContextID:   "context-1",
+			Payer:       "0xpayer",
+			Payload:     &x402types.PaymentPayload{X402Version: x402.X402Version},
+			Requirement: &x402types.PaymentRequirements{Scheme: "exact", Network: x402.NetworkBaseSepolia, Amount: "100"},
+			QueuedAt:    queued.Add(-time.Duration(i) * time.Minute),
+		}
+		if err := store.SavePendingSettlement(ctx, pending); err != nil {
+			t.Fatalf("SavePendingSettlement() error = %v", err)
+		}
+	}
+
+	// A second store over the same directory sees the queue, as after a restart.
+	reopened, err := NewFilePaymentStateStore(dir)
+	if err != nil {
+		t.Fatalf("NewFilePaymentStateStore() error = %v", err)
+	}
+	pending, err := reopened.PendingSettlements(ctx)
+	if err != nil {
+		t.Fatalf("PendingSettlements() error = %v", err)
+	}
+	if len(pending) != 2 || pending[0].TaskID != "task-earlier" || pending[1].TaskID != "task/later" {
+		t.Fatalf("pending = %+v, want task-earlier then task/later", pending)
+	}
+	if pending[1].Requirement.Amount != "100" || pending[1].Payer != "0xpayer" || !pending[1].QueuedAt.Equal(queued) {
+		t.Errorf("pending[1] = %+v, want the saved settlement", pending[1])
+	}
+
+	if err := reopened.DeletePendingSettlement(ctx, "task/later"); err != nil {
+		t.Fatalf("DeletePendingSettlement() error = %v", err)
+	}
+	if pending, _ := reopened.PendingSettlements(ctx); len(pending) != 1 {
+		t.Errorf("pending after delete = %d, want 1", len(pending))
+	}
+	if _, found, _ := reopened.LoadState(ctx, "task-earlier"); found {
+		t.Error("pending settlement read back as a payment record")
+	}
+}
+
+func TestFilePaymentStateStore_PendingDeliveries(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := NewFilePaymentStateStore(dir)
+	if err != nil {
+		t.Fatalf("NewFilePaymentStateStore() error = %v", err)
+	}
+
+	deadline := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i, taskID := range []a2a.TaskID{"task/later", "task-earlier"} {
+		held := &PendingDelivery{
+			TaskID:      taskID,
+			ContextID:   "context-1",
+			Payer:       "0xpayer",
+			Payload:     &x402types.PaymentPayload{X402Version: x402.X402Version},
+			Requirement: &x402types.PaymentRequirements{Scheme: "exact", Network: x402.NetworkBaseSepolia, Amount: "100"},
+			Deadline:    deadline.Add(-time.Duration(i) * time.Hour),
+		}
+		if err := store.SavePendingDelivery(ctx, held); err != nil {
+			t.Fatalf("SavePendingDelivery() error = %v", err)
+		}
+	}
+
+	reopened, err := NewFilePaymentStateStore(dir)
+	if err != nil {
+		t.Fatalf("NewFilePaymentStateStore() error = %v", err)
+	}
+	held, err := reopened.PendingDeliveries(ctx)
+	if err != nil {
+		t.Fatalf("PendingDeliveries() error = %v", err)
+	}
+	if len(held) != 2 || held[0].TaskID != "task-earlier" || held[1].TaskID != "task/later" {
+		t.Fatalf("held = %+v, want task-earlier then task/later", held)
+	}
+	if held[1].Requirement.Amount != "100" || !held[1].Deadline.Equal(deadline) {
+		t.Errorf("held[1] = %+v, want the saved delivery", held[1])
+	}
+	if pending, _ := reopened.PendingSettlements(ctx); len(pending) != 0 {
+		t.Errorf("held deliveries read back as pending settlements: %+v", pending)
+	}
+
+	if err := reopened.DeletePendingDelivery(ctx, "task/later"); err != nil {
+		t.Fatalf("DeletePendingDelivery() error = %v", err)
+	}
+	if held, _ := reopened.PendingDeliveries(ctx); len(held) != 1 {
+		t.Errorf("held after delete = %d, want 1", len(held))
+	}
+}
+
+func TestBusinessOrchestrator_ResumesFromPaymentStateStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFilePaymentStateStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFilePaymentStateStore() error = %v", err)
+	}
+	networkConfigs := []types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}}
+
+	before := newTestOrchestrator(
+		t,
+		&MockResourceServer{},
+		&mockBusinessService{},
+		networkConfigs,
+		WithPaymentStateStore(store),
+	)
+	task := quoteRequest(t, before, "task-restart", a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "original prompt"}))
+	record, found, err := store.LoadState(ctx, task.ID)
+	if err != nil || !found || record.Status != x402state.PaymentRequired {
+		t.Fatalf("persisted record = %#v, found = %v, error = %v", record, found, err)
+	}
+
+	// The restarted merchant has lost the payment metadata on the stored task.
+	task.Status.Message = a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{Text: "Payment required"})
+
+	var settled bool
+	var businessRequest business.Request
+	after := newTestOrchestrator(
+		t,
+		&MockResourceServer{
+			SettlePaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.SettleResponse, error) {
+				settled = true
+				return &x402core.SettleResponse{Success: true, Network: x402.NetworkBaseSepolia, Transaction: "0xtx"}, nil
+			},
+		},
+		&mockBusinessService{executeFunc: func(ctx context.Context, request business.Request) (*business.Result, error) {
+			businessRequest = request
+			return &business.Result{Message: "paid result"}, nil
+		}},
+		networkConfigs,
+		WithPaymentStateStore(store),
+	)
+	payload := &x402types.PaymentPayload{
+		X402Version: x402.X402Version,
+		Accepted:    record.Requirements.Accepts[0],
+		Payload:     map[string]interface{}{"signature": "0xabc"},
+	}
+	submission, err := x402state.EncodePaymentSubmission(task.ID, payload)
+	if err != nil {
+		t.Fatalf("EncodePaymentSubmission() error = %v", err)
+	}
+	submission.ContextID = task.ContextID
+	if err := submitPayment(after, task, submission, &mockEventQueue{}); err != nil {
+		t.Fatalf("resumed Execute() error = %v", err)
+	}
+
+	if task.Status.State != a2a.TaskStateCompleted {
+		t.Errorf("task state = %v, want %v", task.Status.State, a2a.TaskStateCompleted)
+	}
+	if !settled {
+		t.Error("payment was not settled after restart")
+	}
+	if !businessRequest.PaymentVerified || businessRequest.Prompt != "original prompt" {
+		t.Errorf("business request = %#v, want verified call with original prompt", businessRequest)
+	}
+	if _, found, _ := store.LoadState(ctx, task.ID); found {
+		t.Error("payment record should be deleted once the task completes")
+	}
+}
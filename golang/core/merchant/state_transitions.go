@@ -15,13 +15,20 @@
 package merchant
 
 import (
+	"bytes"
 	"context"
+	"encoding/gob"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
 
 	"github.com/a2aproject/a2a-go/a2a"
 	"github.com/a2aproject/a2a-go/a2asrv"
 	"github.com/a2aproject/a2a-go/a2asrv/eventqueue"
 	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
 	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
 	x402core "github.com/x402-foundation/x402/go"
 )
@@ -31,14 +38,18 @@ func (o *BusinessOrchestrator) createTask(
 	requestContext *a2asrv.RequestContext,
 	eventQueue eventqueue.Queue,
 ) (*a2a.Task, error) {
+	task := a2a.NewSubmittedTask(requestContext, requestContext.Message)
+	// a2asrv fails a new task whose execution errors only once the request
+	// context names one. It gets a task of its own so the one the execution
+	// updates stays private.
 	requestContext.StoredTask = a2a.NewSubmittedTask(requestContext, requestContext.Message)
 
 	event := a2a.NewStatusUpdateEvent(requestContext, a2a.TaskStateSubmitted, nil)
-	if err := eventQueue.Write(ctx, event); err != nil {
+	if err := o.writeEvent(ctx, task, eventQueue, event); err != nil {
 		return nil, fmt.Errorf("failed to write task creation event: %w", err)
 	}
 
-	return requestContext.StoredTask, nil
+	return task, nil
 }
 
 func (o *BusinessOrchestrator) transitionToPaymentRequired(
@@ -47,22 +58,81 @@ func (o *BusinessOrchestrator) transitionToPaymentRequired(
 	task *a2a.Task,
 	queue eventqueue.Queue,
 	paymentState *state.PaymentState,
+	originalPrompt string,
+	skillID string,
+	requestMetadata map[string]interface{},
+	discounts []DiscountInfo,
 ) error {
 	task.Status.State = a2a.TaskStateInputRequired
 
+	// Only the first quote carries a preview; later rounds follow a
+	// delivered result.
+	var preview []a2a.Part
+	if state.ExtractPaymentRound(task) == 1 {
+		preview = o.previewParts(ctx, task, originalPrompt)
+	}
+	if len(preview) > 0 {
+		if task.Status.Message == nil {
+			task.Status.Message = a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{Text: "Payment required"})
+		}
+		task.Status.Message.Parts = append(task.Status.Message.Parts, preview...)
+	}
+
+	bindRequirements(task.ID, paymentState.Requirements)
 	if err := state.RecordPaymentRequired(task, paymentState.Requirements, "Payment required"); err != nil {
 		return fmt.Errorf("failed to record payment required: %w", err)
 	}
+	state.SetQuotedAt(task.Status.Message, o.now())
+	if o.payTo != nil {
+		state.SetPayToAddresses(task.Status.Message, payToAddresses(paymentState.Requirements))
+	}
 
-	originalPrompt := state.ExtractMessageText(requestContext.Message)
-	if originalPrompt != "" {
-		state.SetOriginalPrompt(task.Status.Message, originalPrompt)
+	o.retainPrompt(task, originalPrompt)
+	state.SetSkillID(task.Status.Message, skillID)
+	state.SetRequestMetadata(task.Status.Message, requestMetadata)
+	setDiscountMetadata(task.Status.Message, discounts)
+	applyMessageTemplate(task, o.messageTemplates.PaymentRequired, nil)
+
+	if err := o.savePaymentState(ctx, task, paymentState); err != nil {
+		return err
 	}
 
-	event := a2a.NewStatusUpdateEvent(requestContext, a2a.TaskStateInputRequired, task.Status.Message)
-	event.Final = true
+	event := statusEvent(requestContext, task)
 
-	return queue.Write(ctx, event)
+	if err := o.writeEvent(ctx, task, queue, event); err != nil {
+		return err
+	}
+	o.logQuoteIssued(ctx, task, paymentState.Requirements)
+	o.hooks.quoteIssued(ctx, task, paymentState.Requirements)
+	if paymentState.Requirements != nil {
+		seen := make(map[string]bool)
+		for _, accepted := range paymentState.Requirements.Accepts {
+			if !seen[accepted.Network] {
+				seen[accepted.Network] = true
+				o.metrics.QuoteIssued(accepted.Network)
+			}
+		}
+	}
+	return nil
+}
+
+// previewParts asks a PreviewBusinessService for teaser parts to show with the
+// quote. Failures are logged and the quote goes out without a preview.
+func (o *BusinessOrchestrator) previewParts(ctx context.Context, task *a2a.Task, prompt string) []a2a.Part {
+	previewer, ok := o.businessService.(business.PreviewBusinessService)
+	if !ok {
+		return nil
+	}
+	parts, err := previewer.Preview(ctx, prompt)
+	if err != nil {
+		o.logger.WarnContext(ctx, "x402 preview failed",
+			"task_id", task.ID,
+			"context_id", task.ContextID,
+			"error", err,
+		)
+		return nil
+	}
+	return parts
 }
 
 func (o *BusinessOrchestrator) transitionToWorking(
@@ -72,9 +142,8 @@ func (o *BusinessOrchestrator) transitionToWorking(
 	queue eventqueue.Queue,
 ) error {
 	task.Status.State = a2a.TaskStateWorking
-	event := a2a.NewStatusUpdateEvent(requestContext, a2a.TaskStateWorking, task.Status.Message)
-	event.Final = false
-	return queue.Write(ctx, event)
+	event := statusEvent(requestContext, task)
+	return o.writeEvent(ctx, task, queue, event)
 }
 
 func (o *BusinessOrchestrator) transitionToCompleted(
@@ -84,25 +153,41 @@ func (o *BusinessOrchestrator) transitionToCompleted(
 	queue eventqueue.Queue,
 	result *state.PaymentState,
 ) error {
-	if err := writeArtifacts(ctx, task, queue, result.Artifacts); err != nil {
+	if err := o.writeArtifacts(ctx, task, queue, businessArtifacts(result.Message, result.Parts, result.Artifacts)); err != nil {
 		return err
 	}
 
-	responseText := result.Message
-	if responseText == "" {
-		responseText = "Task completed"
+	responseText := resultSummary(result.Summary, result.Message, "Task completed")
+	// Earlier rounds of a multi-payment task left their receipts on the
+	// status message; RecordPaymentCompleted appends this round's.
+	allReceipts, err := state.ExtractPaymentReceipts(task)
+	if err != nil {
+		return fmt.Errorf("failed to read payment receipts: %w", err)
+	}
+	allReceipts = append(allReceipts, result.Receipts...)
+	if o.transactionText {
+		if paid := transactionLines(allReceipts); paid != "" {
+			responseText += "\n\n" + paid
+		}
 	}
 
+	payloadHash := state.ExtractPaymentPayloadHash(task)
 	if err := state.RecordPaymentCompleted(task, result.Receipts, responseText); err != nil {
 		return fmt.Errorf("failed to record payment completed: %w", err)
 	}
+	state.SetPaymentTransactions(task.Status.Message, allReceipts)
+	o.signReceipts(ctx, task, payloadHash, result.Receipts)
 
 	task.Status.State = a2a.TaskStateCompleted
+	applyMessageTemplate(task, o.messageTemplates.Completed, nil)
 
-	event := a2a.NewStatusUpdateEvent(requestContext, a2a.TaskStateCompleted, task.Status.Message)
-	event.Final = true
+	event := statusEvent(requestContext, task)
 
-	return queue.Write(ctx, event)
+	if err := o.writeTerminalEvent(ctx, task, queue, event); err != nil {
+		return err
+	}
+	o.notifyWebhook(ctx, WebhookPaymentSettled, task, result.Receipts, "", nil)
+	return nil
 }
 
 func (o *BusinessOrchestrator) transitionToBusinessCompleted(
@@ -111,24 +196,25 @@ func (o *BusinessOrchestrator) transitionToBusinessCompleted(
 	task *a2a.Task,
 	queue eventqueue.Queue,
 	result *business.Result,
+	annotate func(*a2a.Message),
 ) error {
 	if result == nil {
 		return fmt.Errorf("business result is required")
 	}
-	if err := writeArtifacts(ctx, task, queue, result.Artifacts); err != nil {
+	if err := o.writeArtifacts(ctx, task, queue, businessArtifacts(result.Message, result.Parts, result.Artifacts)); err != nil {
 		return err
 	}
 
-	responseText := result.Message
-	if responseText == "" {
-		responseText = "Task completed"
-	}
+	responseText := resultSummary(result.Summary, result.Message, "Task completed")
 	task.Status.Message = a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{Text: responseText})
+	if annotate != nil {
+		annotate(task.Status.Message)
+	}
 	task.Status.State = a2a.TaskStateCompleted
+	applyMessageTemplate(task, o.messageTemplates.Completed, nil)
 
-	event := a2a.NewStatusUpdateEvent(requestContext, a2a.TaskStateCompleted, task.Status.Message)
-	event.Final = true
-	return queue.Write(ctx, event)
+	event := statusEvent(requestContext, task)
+	return o.writeTerminalEvent(ctx, task, queue, event)
 }
 
 func (o *BusinessOrchestrator) transitionToTaskFailed(
@@ -137,13 +223,21 @@ func (o *BusinessOrchestrator) transitionToTaskFailed(
 	task *a2a.Task,
 	queue eventqueue.Queue,
 	err error,
+	errorCode string,
 ) error {
 	task.Status.State = a2a.TaskStateFailed
 	task.Status.Message = a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{Text: err.Error()})
+	state.SetPaymentError(task.Status.Message, errorCode)
+	applyMessageTemplate(task, o.messageTemplates.Failed, err)
+	o.logFailed(ctx, task, errorCode, err)
+	o.hooks.failed(ctx, task, errorCode, err)
 
-	event := a2a.NewStatusUpdateEvent(requestContext, a2a.TaskStateFailed, task.Status.Message)
-	event.Final = true
-	return queue.Write(ctx, event)
+	event := statusEvent(requestContext, task)
+	if writeErr := o.writeTerminalEvent(ctx, task, queue, event); writeErr != nil {
+		return writeErr
+	}
+	o.notifyWebhook(ctx, WebhookTaskFailed, task, nil, errorCode, err)
+	return nil
 }
 
 func (o *BusinessOrchestrator) transitionToFailed(
@@ -154,17 +248,148 @@ func (o *BusinessOrchestrator) transitionToFailed(
 	err error,
 	errorCode string,
 	receipt *x402core.SettleResponse,
+	compensation string,
 ) error {
 	task.Status.State = a2a.TaskStateFailed
 
 	if recordErr := state.RecordPaymentFailed(task, errorCode, err.Error(), receipt); recordErr != nil {
 		return fmt.Errorf("failed to record payment failure: %w", recordErr)
 	}
+	if compensation != "" {
+		state.SetCompensation(task.Status.Message, compensation)
+	}
+	applyMessageTemplate(task, o.messageTemplates.Failed, err)
+	o.logFailed(ctx, task, errorCode, err)
+	o.hooks.failed(ctx, task, errorCode, err)
+
+	event := statusEvent(requestContext, task)
+
+	if writeErr := o.writeTerminalEvent(ctx, task, queue, event); writeErr != nil {
+		return writeErr
+	}
+	var receipts []*x402core.SettleResponse
+	if receipt != nil {
+		receipts = append(receipts, receipt)
+	}
+	o.notifyWebhook(ctx, WebhookTaskFailed, task, receipts, errorCode, err)
+	return nil
+}
+
+func (o *BusinessOrchestrator) transitionToPaymentRejected(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	queue eventqueue.Queue,
+	reason string,
+) error {
+	task.Status.State = a2a.TaskStateCanceled
+	state.RecordPaymentRejected(task, reason)
+	applyMessageTemplate(task, o.messageTemplates.Rejected, nil)
+	o.logRejected(ctx, task)
+
+	event := statusEvent(requestContext, task)
+
+	return o.writeTerminalEvent(ctx, task, queue, event)
+}
+
+func (o *BusinessOrchestrator) transitionToCanceled(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	queue eventqueue.Queue,
+) error {
+	status, _ := state.ExtractPaymentStatus(task)
+	receipts, err := state.ExtractPaymentReceipts(task)
+	if err != nil {
+		return fmt.Errorf("failed to read payment receipts: %w", err)
+	}
+	var settled []*x402core.SettleResponse
+	for _, receipt := range receipts {
+		if receipt.Success {
+			settled = append(settled, receipt)
+		}
+	}
+
+	task.Status.State = a2a.TaskStateCanceled
+	if err := state.RecordPaymentCanceled(task, status, settled, "Task canceled"); err != nil {
+		return fmt.Errorf("failed to record payment cancellation: %w", err)
+	}
+	applyMessageTemplate(task, o.messageTemplates.Canceled, nil)
+	o.logCanceled(ctx, task, len(settled) > 0)
+
+	event := statusEvent(requestContext, task)
+	return o.writeTerminalEvent(ctx, task, queue, event)
+}
+
+// transitionToAuthorizationVoided cancels a task whose verified payment was
+// abandoned before settlement. The authorization is never settled.
+func (o *BusinessOrchestrator) transitionToAuthorizationVoided(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	queue eventqueue.Queue,
+	paymentState *state.PaymentState,
+) error {
+	task.Status.State = a2a.TaskStateCanceled
+	if err := state.RecordPaymentCanceled(task, state.PaymentVerified, nil, "Task canceled before settlement; payment authorization voided"); err != nil {
+		return fmt.Errorf("failed to record payment cancellation: %w", err)
+	}
+	state.SetPaymentError(task.Status.Message, x402.ErrorCodeAuthorizationVoided)
+	applyMessageTemplate(task, o.messageTemplates.Canceled, nil)
+	o.logCanceled(ctx, task, false)
+	o.hooks.authorizationVoided(ctx, task, paymentState.Payload)
+
+	return o.writeTerminalEvent(ctx, task, queue, statusEvent(requestContext, task))
+}
+
+// transitionToDeliveryDisputed cancels a held delivery the client disputed.
+// The authorization is never settled.
+func (o *BusinessOrchestrator) transitionToDeliveryDisputed(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	queue eventqueue.Queue,
+	paymentState *state.PaymentState,
+	compensation string,
+) error {
+	task.Status.State = a2a.TaskStateCanceled
+	if err := state.RecordPaymentCanceled(task, state.PaymentVerified, nil, "Delivery disputed; payment authorization voided"); err != nil {
+		return fmt.Errorf("failed to record payment cancellation: %w", err)
+	}
+	state.SetPaymentError(task.Status.Message, x402.ErrorCodeAuthorizationVoided)
+	if compensation != "" {
+		state.SetCompensation(task.Status.Message, compensation)
+	}
+	applyMessageTemplate(task, o.messageTemplates.Canceled, nil)
+	o.logCanceled(ctx, task, false)
+	o.hooks.authorizationVoided(ctx, task, paymentState.Payload)
+
+	return o.writeTerminalEvent(ctx, task, queue, statusEvent(requestContext, task))
+}
 
-	event := a2a.NewStatusUpdateEvent(requestContext, a2a.TaskStateFailed, task.Status.Message)
-	event.Final = true
+// transitionToPayerRejected refuses a verified payment from a payer the
+// merchant will not serve. Nothing has been settled at this point.
+func (o *BusinessOrchestrator) transitionToPayerRejected(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	queue eventqueue.Queue,
+	reason string,
+) error {
+	task.Status.State = a2a.TaskStateRejected
+	state.RecordPaymentRejected(task, reason)
+	state.SetPaymentError(task.Status.Message, x402.ErrorCodePayerNotAllowed)
+	applyMessageTemplate(task, o.messageTemplates.Rejected, nil)
+	err := errors.New(reason)
+	o.logFailed(ctx, task, x402.ErrorCodePayerNotAllowed, err)
+	o.hooks.failed(ctx, task, x402.ErrorCodePayerNotAllowed, err)
 
-	return queue.Write(ctx, event)
+	event := statusEvent(requestContext, task)
+	if writeErr := o.writeTerminalEvent(ctx, task, queue, event); writeErr != nil {
+		return writeErr
+	}
+	o.notifyWebhook(ctx, WebhookTaskFailed, task, nil, x402.ErrorCodePayerNotAllowed, err)
+	return nil
 }
 
 func (o *BusinessOrchestrator) transitionToPaymentVerified(
@@ -178,19 +403,216 @@ func (o *BusinessOrchestrator) transitionToPaymentVerified(
 	if err := state.RecordPaymentVerified(task, paymentState, "Payment verified"); err != nil {
 		return fmt.Errorf("failed to record payment verified: %w", err)
 	}
+	state.ClearPaymentError(task.Status.Message)
+	applyMessageTemplate(task, o.messageTemplates.PaymentVerified, nil)
+	if err := o.savePaymentState(ctx, task, paymentState); err != nil {
+		return err
+	}
+
+	event := statusEvent(requestContext, task)
+
+	if err := o.writeEvent(ctx, task, queue, event); err != nil {
+		return err
+	}
+	o.logPaymentVerified(ctx, task, paymentState)
+	o.hooks.paymentVerified(ctx, task, paymentState.Payer)
+	return nil
+}
+
+// transitionToExecuting tells the client the service is running so a slow
+// execution is not mistaken for a hung task. The payment metadata recorded at
+// verification is carried over to the new status message.
+func (o *BusinessOrchestrator) transitionToExecuting(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	queue eventqueue.Queue,
+) error {
+	message := a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{Text: "Payment verified — executing service"})
+	if task.Status.Message != nil {
+		message.Metadata = maps.Clone(task.Status.Message.Metadata)
+	}
+	task.Status.State = a2a.TaskStateWorking
+	task.Status.Message = message
+	applyMessageTemplate(task, o.messageTemplates.Executing, nil)
+
+	return o.writeEvent(ctx, task, queue, statusEvent(requestContext, task))
+}
+
+// statusEvent reports the task's current status. Every transition writes
+// exactly one of these, so a subscriber replaying the queue sees the same
+// sequence of states the task went through. The message is copied because
+// later transitions update the task's status message in place. Terminal
+// states and input-required end the execution's event sequence; anything
+// else leaves it open for the events that follow.
+func statusEvent(requestContext *a2asrv.RequestContext, task *a2a.Task) *a2a.TaskStatusUpdateEvent {
+	event := a2a.NewStatusUpdateEvent(requestContext, task.Status.State, snapshotMessage(task.Status.Message))
+	event.Final = task.Status.State.Terminal() || task.Status.State == a2a.TaskStateInputRequired
+	return event
+}
+
+// deepCopy copies v with a gob round trip, the way a2asrv copies tasks
+// between its goroutines, so nothing in the copy is shared with v.
+func deepCopy[T any](v *T) (*T, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	var copied T
+	if err := gob.NewDecoder(&buf).Decode(&copied); err != nil {
+		return nil, err
+	}
+	return &copied, nil
+}
+
+// snapshotMessage deep-copies message for an event. Transitions update the
+// task's status message and the metadata nested in it, such as the receipts
+// list, after the event is queued.
+func snapshotMessage(message *a2a.Message) *a2a.Message {
+	if message == nil {
+		return nil
+	}
+	snapshot := *message
+	snapshot.Extensions = slices.Clone(message.Extensions)
+	snapshot.ReferenceTasks = slices.Clone(message.ReferenceTasks)
+	snapshot.Metadata = snapshotMetadata(message.Metadata)
+	if message.Parts != nil {
+		snapshot.Parts = make(a2a.ContentParts, len(message.Parts))
+		for i, part := range message.Parts {
+			snapshot.Parts[i] = snapshotPart(part)
+		}
+	}
+	return &snapshot
+}
+
+func snapshotPart(part a2a.Part) a2a.Part {
+	switch p := part.(type) {
+	case a2a.TextPart:
+		p.Metadata = snapshotMetadata(p.Metadata)
+		return p
+	case a2a.DataPart:
+		p.Data = snapshotMetadata(p.Data)
+		p.Metadata = snapshotMetadata(p.Metadata)
+		return p
+	case a2a.FilePart:
+		p.Metadata = snapshotMetadata(p.Metadata)
+		return p
+	}
+	return part
+}
+
+func snapshotMetadata(metadata map[string]any) map[string]any {
+	if metadata == nil {
+		return nil
+	}
+	snapshot := make(map[string]any, len(metadata))
+	for key, value := range metadata {
+		snapshot[key] = snapshotValue(value)
+	}
+	return snapshot
+}
+
+// snapshotValue copies the maps and slices that metadata is built from.
+// Other values are not updated in place and are shared.
+func snapshotValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		return snapshotMetadata(v)
+	case []any:
+		if v == nil {
+			return v
+		}
+		snapshot := make([]any, len(v))
+		for i, item := range v {
+			snapshot[i] = snapshotValue(item)
+		}
+		return snapshot
+	}
+	return value
+}
+
+// writeTerminalEvent writes the final event and only then drops the persisted
+// payment state, so a crash in between leaves a stale record rather than a
+// task that cannot be resumed.
+func (o *BusinessOrchestrator) writeTerminalEvent(
+	ctx context.Context,
+	task *a2a.Task,
+	queue eventqueue.Queue,
+	event a2a.Event,
+) error {
+	o.recordFailure(ctx, task)
+	if err := o.writeEvent(ctx, task, queue, event); err != nil {
+		return err
+	}
+	o.prompts.forget(task.ID)
+	return o.deletePaymentState(ctx, task)
+}
+
+// transactionLines renders one "Paid — tx ... on network" line per settled
+// receipt that carries a transaction reference.
+func transactionLines(receipts []*x402core.SettleResponse) string {
+	var lines []string
+	for _, receipt := range receipts {
+		if receipt == nil || !receipt.Success || receipt.Transaction == "" {
+			continue
+		}
+		line := "Paid — tx " + shortTransaction(receipt.Transaction)
+		if receipt.Network != "" {
+			line += " on " + string(receipt.Network)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
 
-	event := a2a.NewStatusUpdateEvent(requestContext, task.Status.State, task.Status.Message)
-	event.Final = false
+// shortTransaction abbreviates long hashes and signatures for display.
+func shortTransaction(tx string) string {
+	if len(tx) <= 16 {
+		return tx
+	}
+	return tx[:10] + "…" + tx[len(tx)-6:]
+}
 
-	return queue.Write(ctx, event)
+// resultSummary picks the text for a result's status message. The output
+// itself travels in artifacts, so the status message stays small.
+func resultSummary(summary, message, fallback string) string {
+	if summary != "" {
+		return summary
+	}
+	if message != "" {
+		return message
+	}
+	return fallback
 }
 
-func writeArtifacts(
+// resultArtifactName names the artifact that carries a business response.
+const resultArtifactName = "result"
+
+// businessArtifacts returns the artifacts to stream for a business result:
+// the service's own artifacts followed by a "result" artifact carrying the
+// response text and parts.
+func businessArtifacts(message string, parts []a2a.Part, artifacts []*a2a.Artifact) []*a2a.Artifact {
+	var output []a2a.Part
+	if message != "" {
+		output = append(output, a2a.TextPart{Text: message})
+	}
+	output = append(output, parts...)
+	if len(output) == 0 {
+		return artifacts
+	}
+	result := &a2a.Artifact{ID: a2a.NewArtifactID(), Name: resultArtifactName, Parts: output}
+	return append(slices.Clone(artifacts), result)
+}
+
+// writeArtifacts streams artifacts in order. Artifacts sharing an ID are sent
+// as chunks of one artifact, with LastChunk set on the final one.
+func (o *BusinessOrchestrator) writeArtifacts(
 	ctx context.Context,
 	task *a2a.Task,
 	queue eventqueue.Queue,
 	artifacts []*a2a.Artifact,
 ) error {
+	remaining := make(map[a2a.ArtifactID]int)
 	for _, artifact := range artifacts {
 		if artifact == nil {
 			continue
@@ -198,13 +620,23 @@ func writeArtifacts(
 		if artifact.ID == "" {
 			artifact.ID = a2a.NewArtifactID()
 		}
+		remaining[artifact.ID]++
+	}
+	sent := make(map[a2a.ArtifactID]bool)
+	for _, artifact := range artifacts {
+		if artifact == nil {
+			continue
+		}
+		remaining[artifact.ID]--
 		event := &a2a.TaskArtifactUpdateEvent{
 			TaskID:    task.ID,
 			ContextID: task.ContextID,
 			Artifact:  artifact,
-			LastChunk: true,
+			Append:    sent[artifact.ID],
+			LastChunk: remaining[artifact.ID] == 0,
 		}
-		if err := queue.Write(ctx, event); err != nil {
+		sent[artifact.ID] = true
+		if err := o.writeEvent(ctx, task, queue, event); err != nil {
 			return fmt.Errorf("failed to write artifact event: %w", err)
 		}
 	}
@@ -0,0 +1,257 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// snapshotEventQueue serializes each event as it is written so the test can
+// tell whether the orchestrator changed an event after handing it over.
+type snapshotEventQueue struct {
+	mockEventQueue
+	written [][]byte
+}
+
+func (q *snapshotEventQueue) Write(ctx context.Context, event a2a.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	q.written = append(q.written, data)
+	return q.mockEventQueue.Write(ctx, event)
+}
+
+func describeEvent(event any) string {
+	switch e := event.(type) {
+	case *a2a.TaskStatusUpdateEvent:
+		status, _ := x402state.ExtractPaymentStatus(&a2a.Task{Status: e.Status})
+		return fmt.Sprintf("status:%s:%s:final=%t", e.Status.State, status, e.Final)
+	case *a2a.TaskArtifactUpdateEvent:
+		return "artifact:" + e.Artifact.Name
+	default:
+		return fmt.Sprintf("%T", event)
+	}
+}
+
+func TestBusinessOrchestrator_Execute_StreamsReplayableEventSequence(t *testing.T) {
+	ctx := context.Background()
+	orchestrator := newTestOrchestrator(
+		t,
+		&MockResourceServer{
+			SettlePaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.SettleResponse, error) {
+				return &x402core.SettleResponse{Success: true, Network: x402.NetworkBaseSepolia, Transaction: "0xtx"}, nil
+			},
+		},
+		&mockBusinessService{executeFunc: func(ctx context.Context, request business.Request) (*business.Result, error) {
+			if !request.PaymentVerified {
+				return nil, business.NewPaymentRequiredError("pay", business.ServiceRequirements{
+					Price:             "1.00",
+					Resource:          "/report",
+					Scheme:            "exact",
+					MaxTimeoutSeconds: 60,
+				})
+			}
+			return &business.Result{
+				Message:   "report ready",
+				Artifacts: []*a2a.Artifact{{Name: "report", Parts: a2a.ContentParts{a2a.TextPart{Text: "data"}}}},
+			}, nil
+		}},
+		[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+	)
+
+	requestContext := &a2asrv.RequestContext{
+		Message:   a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "report"}),
+		TaskID:    "task-stream",
+		ContextID: "context-stream",
+	}
+	quoteQueue := &snapshotEventQueue{}
+	if err := orchestrator.executeInPlace(ctx, requestContext, quoteQueue); err != nil {
+		t.Fatalf("initial Execute() error = %v", err)
+	}
+	task := requestContext.StoredTask
+	submission := paymentSubmission(t, task)
+	paidQueue := &snapshotEventQueue{}
+	if err := submitPayment(orchestrator, task, submission, paidQueue); err != nil {
+		t.Fatalf("paid Execute() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		queue *snapshotEventQueue
+		want  []string
+	}{
+		{
+			name:  "quote",
+			queue: quoteQueue,
+			want: []string{
+				"status:submitted::final=false",
+				"status:working::final=false",
+				"status:input-required:payment-required:final=true",
+			},
+		},
+		{
+			name:  "paid",
+			queue: paidQueue,
+			want: []string{
+				"status:working:payment-verified:final=false",
+				"status:working:payment-verified:final=false",
+				"artifact:report",
+				"artifact:" + resultArtifactName,
+				"status:completed:payment-completed:final=true",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for i, event := range tt.queue.events {
+				got = append(got, describeEvent(event))
+				data, err := json.Marshal(event)
+				if err != nil {
+					t.Fatalf("Marshal() error = %v", err)
+				}
+				if string(data) != string(tt.queue.written[i]) {
+					t.Errorf("event %d changed after it was written:\nwritten %s\nnow     %s", i, tt.queue.written[i], data)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("events =\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+
+	result := paidQueue.events[3].(*a2a.TaskArtifactUpdateEvent).Artifact
+	if text := result.Parts[0].(a2a.TextPart).Text; text != "report ready" {
+		t.Errorf("result artifact text = %q, want %q", text, "report ready")
+	}
+}
+
+func TestStatusEvent_SnapshotsNestedMessage(t *testing.T) {
+	task := &a2a.Task{
+		ID:        "task-snapshot",
+		ContextID: "context-snapshot",
+		Status: a2a.TaskStatus{
+			State: a2a.TaskStateWorking,
+			Message: a2a.NewMessage(a2a.MessageRoleAgent,
+				a2a.TextPart{Text: "Payment settled", Metadata: map[string]any{"note": "settled"}},
+				a2a.DataPart{Data: map[string]any{"image": "rendered"}},
+			),
+		},
+	}
+	err := x402state.SetPaymentReceipts(task.Status.Message, []*x402core.SettleResponse{
+		{Success: true, Transaction: "0xsettled", Network: x402.NetworkBaseSepolia},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := statusEvent(&a2asrv.RequestContext{TaskID: task.ID, ContextID: task.ContextID}, task)
+	want, err := json.Marshal(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A subscriber reads the queued event while the next transition updates
+	// the live status message; run with -race to catch shared state.
+	read := make(chan []byte)
+	go func() {
+		data, _ := json.Marshal(event)
+		read <- data
+	}()
+	message := task.Status.Message
+	receipt := message.Metadata[x402.MetadataKeyReceipts].([]any)[0].(map[string]any)
+	receipt["transaction"] = "0xchanged"
+	message.Parts[0].(a2a.TextPart).Metadata["note"] = "changed"
+	message.Parts[1].(a2a.DataPart).Data["image"] = "changed"
+
+	if got := <-read; string(got) != string(want) {
+		t.Errorf("event read during the next transition = %s, want %s", got, want)
+	}
+	if got, _ := json.Marshal(event); string(got) != string(want) {
+		t.Errorf("event after the next transition = %s, want %s", got, want)
+	}
+}
+
+func TestBusinessOrchestrator_writeArtifacts_Chunks(t *testing.T) {
+	o := newTestOrchestrator(t, &MockResourceServer{}, &mockBusinessService{}, nil)
+	task := &a2a.Task{ID: "task-chunks", ContextID: "context-chunks"}
+	queue := &mockEventQueue{}
+
+	err := o.writeArtifacts(context.Background(), task, queue, []*a2a.Artifact{
+		{ID: "video", Name: "video", Parts: a2a.ContentParts{a2a.TextPart{Text: "part 1"}}},
+		{ID: "log", Name: "log", Parts: a2a.ContentParts{a2a.TextPart{Text: "done"}}},
+		{ID: "video", Name: "video", Parts: a2a.ContentParts{a2a.TextPart{Text: "part 2"}}},
+	})
+	if err != nil {
+		t.Fatalf("writeArtifacts() error = %v", err)
+	}
+
+	var got []string
+	for _, event := range queue.events {
+		update := event.(*a2a.TaskArtifactUpdateEvent)
+		got = append(got, fmt.Sprintf("%s:append=%v:last=%v", update.Artifact.ID, update.Append, update.LastChunk))
+	}
+	want := []string{"video:append=false:last=false", "log:append=false:last=true", "video:append=true:last=true"}
+	if !slices.Equal(got, want) {
+		t.Errorf("artifact events = %v, want %v", got, want)
+	}
+}
+
+func TestBusinessOrchestrator_Execute_CompletionUsesSummary(t *testing.T) {
+	orchestrator := newTestOrchestrator(
+		t,
+		&MockResourceServer{},
+		&mockBusinessService{executeFunc: func(ctx context.Context, request business.Request) (*business.Result, error) {
+			return &business.Result{Message: "the full three-page report", Summary: "Report ready"}, nil
+		}},
+		[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+	)
+	requestContext := &a2asrv.RequestContext{
+		Message:   a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "report"}),
+		TaskID:    "task-summary",
+		ContextID: "context-summary",
+	}
+	queue := &mockEventQueue{}
+	if err := orchestrator.executeInPlace(context.Background(), requestContext, queue); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	task := requestContext.StoredTask
+	if text := x402state.ExtractMessageText(task.Status.Message); text != "Report ready" {
+		t.Errorf("status text = %q, want the summary", text)
+	}
+	for _, event := range queue.events {
+		if update, ok := event.(*a2a.TaskArtifactUpdateEvent); ok && update.Artifact.Name == resultArtifactName {
+			if text := update.Artifact.Parts[0].(a2a.TextPart).Text; text != "the full three-page report" {
+				t.Errorf("result artifact text = %q, want the full message", text)
+			}
+			return
+		}
+	}
+	t.Error("no result artifact was written")
+}
@@ -0,0 +1,262 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/a2aproject/a2a-go/a2asrv/eventqueue"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+)
+
+// SubscriptionClaimMaxAge bounds how old a signed subscription claim may be,
+// so a captured claim cannot be replayed indefinitely.
+const SubscriptionClaimMaxAge = 5 * time.Minute
+
+// Subscription is a standing authorization: requests from Payer are paid for
+// until ValidUntil.
+type Subscription struct {
+	ID         string
+	Payer      string
+	ValidUntil time.Time
+}
+
+// SubscriptionPolicy finds the subscription covering a returning payer. It is
+// consulted for every new request that carries a valid signed
+// state.SubscriptionClaim in its message metadata: one made for this merchant
+// and the request's context, issued within SubscriptionClaimMaxAge, with a
+// nonce the merchant has not seen. A task the message references proves
+// nothing about who sent it and is not consulted. A covered request runs
+// straight away and is never quoted; a nil subscription, an expired one, or
+// an error falls back to the normal quote.
+type SubscriptionPolicy interface {
+	Subscription(ctx context.Context, payer string, skillID string) (*Subscription, error)
+}
+
+// SubscriptionPolicyFunc adapts a function to the SubscriptionPolicy
+// interface.
+type SubscriptionPolicyFunc func(ctx context.Context, payer string, skillID string) (*Subscription, error)
+
+func (f SubscriptionPolicyFunc) Subscription(ctx context.Context, payer string, skillID string) (*Subscription, error) {
+	return f(ctx, payer, skillID)
+}
+
+// WithSubscriptionPolicy lets returning payers skip quoting while a
+// subscription covers them. Claim nonces are remembered by this orchestrator
+// only, so merchants running several instances should route a context to
+// the same one.
+func WithSubscriptionPolicy(policy SubscriptionPolicy) Option {
+	return func(o *BusinessOrchestrator) {
+		o.subscriptionPolicy = policy
+	}
+}
+
+// WithSubscriptionAudience sets the audiences subscription claims may name.
+// By default a claim must name the payTo address of one of the configured
+// networks.
+func WithSubscriptionAudience(audiences ...string) Option {
+	return func(o *BusinessOrchestrator) {
+		o.subscriptionAudiences = audiences
+	}
+}
+
+// claimNonces remembers the nonces of accepted claims until the claims are
+// too old to be accepted anyway.
+type claimNonces struct {
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+// use records the payer's nonce and reports false if it was already used.
+func (n *claimNonces) use(payer, nonce string, expires, now time.Time) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for key, expiry := range n.seen {
+		if !now.Before(expiry) {
+			delete(n.seen, key)
+		}
+	}
+	key := strings.ToLower(payer) + "/" + nonce
+	if _, ok := n.seen[key]; ok {
+		return false
+	}
+	if n.seen == nil {
+		n.seen = make(map[string]time.Time)
+	}
+	n.seen[key] = expires
+	return true
+}
+
+// coveringSubscription returns the subscription that pre-pays a new request,
+// or nil when the request must be quoted.
+func (o *BusinessOrchestrator) coveringSubscription(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	message *a2a.Message,
+	skillID string,
+) *Subscription {
+	if o.subscriptionPolicy == nil {
+		return nil
+	}
+	payer := o.requestPayer(ctx, requestContext, task, message)
+	if payer == "" {
+		return nil
+	}
+	subscription, err := o.subscriptionPolicy.Subscription(ctx, payer, skillID)
+	if err != nil {
+		o.logger.WarnContext(ctx, "x402 subscription lookup failed: quoting instead",
+			"task_id", task.ID,
+			"context_id", task.ContextID,
+			"payer", payer,
+			"error", err,
+		)
+		return nil
+	}
+	if subscription == nil || !o.now().Before(subscription.ValidUntil) {
+		return nil
+	}
+	if subscription.Payer == "" {
+		subscription.Payer = payer
+	}
+	return subscription
+}
+
+// requestPayer identifies who is asking from the message's subscription
+// claim, or returns "" when it carries no valid one.
+func (o *BusinessOrchestrator) requestPayer(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	message *a2a.Message,
+) string {
+	claim, err := state.ExtractSubscriptionClaim(message)
+	if err == nil && claim != nil {
+		err = o.checkSubscriptionClaim(requestContext, claim)
+	}
+	if err != nil {
+		o.logger.WarnContext(ctx, "x402 subscription claim rejected",
+			"task_id", task.ID,
+			"context_id", task.ContextID,
+			"error", err,
+		)
+		return ""
+	}
+	if claim == nil {
+		return ""
+	}
+	return claim.Payer
+}
+
+// checkSubscriptionClaim accepts a claim signed by its payer for this
+// merchant and the request's context, and uses up its nonce.
+func (o *BusinessOrchestrator) checkSubscriptionClaim(requestContext *a2asrv.RequestContext, claim *state.SubscriptionClaim) error {
+	if err := state.VerifySubscriptionClaim(claim); err != nil {
+		return err
+	}
+	if !o.subscriptionAudience(claim.Audience) {
+		return fmt.Errorf("subscription claim is for %q, not this merchant", claim.Audience)
+	}
+	if claim.ContextID == "" || claim.ContextID != requestContext.ContextID {
+		return fmt.Errorf("subscription claim is for context %q, not %q", claim.ContextID, requestContext.ContextID)
+	}
+	if claim.Nonce == "" {
+		return fmt.Errorf("subscription claim nonce is required")
+	}
+	issued := time.Unix(claim.Timestamp, 0)
+	now := o.now()
+	if !issued.After(now.Add(-SubscriptionClaimMaxAge)) || issued.After(now.Add(o.clockSkew)) {
+		return fmt.Errorf("subscription claim issued at %s is outside the accepted window", issued.UTC().Format(time.RFC3339))
+	}
+	if !o.claimNonces.use(claim.Payer, claim.Nonce, issued.Add(SubscriptionClaimMaxAge), now) {
+		return fmt.Errorf("subscription claim nonce %s was already used", claim.Nonce)
+	}
+	return nil
+}
+
+// subscriptionAudience reports whether a claim naming audience is meant for
+// this merchant.
+func (o *BusinessOrchestrator) subscriptionAudience(audience string) bool {
+	if audience == "" {
+		return false
+	}
+	if o.subscriptionAudiences != nil {
+		for _, accepted := range o.subscriptionAudiences {
+			if strings.EqualFold(accepted, audience) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, config := range o.networkConfigs {
+		if strings.EqualFold(config.PayToAddress, audience) {
+			return true
+		}
+	}
+	return false
+}
+
+// executeSubscribed runs a request a subscription has paid for and completes
+// the task with a reference to the subscription instead of a receipt.
+func (o *BusinessOrchestrator) executeSubscribed(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	eventQueue eventqueue.Queue,
+	message *a2a.Message,
+	prompt string,
+	skillID string,
+	subscription *Subscription,
+) error {
+	o.logger.InfoContext(ctx, "x402 request covered by subscription",
+		"task_id", task.ID,
+		"context_id", task.ContextID,
+		"subscription", subscription.ID,
+		"payer", subscription.Payer,
+	)
+	if err := o.transitionToWorking(ctx, requestContext, task, eventQueue); err != nil {
+		return err
+	}
+	result, err := o.executePaidRequest(ctx, requestContext, eventQueue, business.Request{
+		Prompt:          prompt,
+		PaymentVerified: true,
+		Payer:           subscription.Payer,
+		Subscription:    subscription.ID,
+		SkillID:         skillID,
+		TaskID:          task.ID,
+		ContextID:       task.ContextID,
+		Message:         message,
+		Metadata:        state.RequestMetadata(message),
+		Round:           1,
+	})
+	if err != nil {
+		return o.transitionToTaskFailed(ctx, requestContext, task, eventQueue, err, businessErrorCode(err))
+	}
+	return o.transitionToBusinessCompleted(ctx, requestContext, task, eventQueue, result, func(message *a2a.Message) {
+		state.SetPaymentStatus(message, state.PaymentNotRequired)
+		state.SetSubscription(message, &state.SubscriptionReference{
+			ID:         subscription.ID,
+			Payer:      subscription.Payer,
+			ValidUntil: subscription.ValidUntil.Unix(),
+		})
+	})
+}
@@ -0,0 +1,279 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+)
+
+var subscriptionNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// subscriber is a payer with a key to sign subscription claims.
+type subscriber struct {
+	key     string
+	address string
+}
+
+func newSubscriber(t *testing.T) subscriber {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	return subscriber{key: hex.EncodeToString(crypto.FromECDSA(key)), address: crypto.PubkeyToAddress(key.PublicKey).Hex()}
+}
+
+// claim signs a claim for payer with the subscriber's key, made for the test
+// merchant and context. edit, when given, changes the claim before signing.
+func (s subscriber) claim(t *testing.T, payer string, issued time.Time, edit ...func(*x402state.SubscriptionClaim)) *a2a.Message {
+	t.Helper()
+	claim, err := x402state.NewSubscriptionClaim(payer, "0x123", "context-subscription", issued)
+	if err != nil {
+		t.Fatalf("NewSubscriptionClaim() error = %v", err)
+	}
+	for _, change := range edit {
+		change(claim)
+	}
+	if err := x402state.SignSubscriptionClaim(claim, s.key); err != nil {
+		t.Fatalf("SignSubscriptionClaim() error = %v", err)
+	}
+	message := a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "render"})
+	if err := x402state.SetSubscriptionClaim(message, claim); err != nil {
+		t.Fatalf("SetSubscriptionClaim() error = %v", err)
+	}
+	return message
+}
+
+// subscriptionHarness records business requests and policy lookups.
+type subscriptionHarness struct {
+	requests []business.Request
+	lookups  []string
+}
+
+func (h *subscriptionHarness) orchestrator(t *testing.T, subscriptions map[string]*Subscription) *BusinessOrchestrator {
+	service := &mockBusinessService{}
+	recorder := &mockBusinessService{executeFunc: func(ctx context.Context, request business.Request) (*business.Result, error) {
+		h.requests = append(h.requests, request)
+		return service.Execute(ctx, request)
+	}}
+	orchestrator := newTestOrchestrator(
+		t,
+		&MockResourceServer{},
+		recorder,
+		[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+		WithSubscriptionPolicy(SubscriptionPolicyFunc(func(ctx context.Context, payer string, skillID string) (*Subscription, error) {
+			h.lookups = append(h.lookups, payer)
+			return subscriptions[payer], nil
+		})),
+	)
+	orchestrator.now = func() time.Time { return subscriptionNow }
+	return orchestrator
+}
+
+func executeNewTask(t *testing.T, orchestrator *BusinessOrchestrator, requestContext *a2asrv.RequestContext) *a2a.Task {
+	t.Helper()
+	if requestContext.TaskID == "" {
+		requestContext.TaskID = "task-subscription"
+	}
+	if requestContext.ContextID == "" {
+		requestContext.ContextID = "context-subscription"
+	}
+	if err := orchestrator.executeInPlace(context.Background(), requestContext, &mockEventQueue{}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	return requestContext.StoredTask
+}
+
+func assertQuoted(t *testing.T, task *a2a.Task) {
+	t.Helper()
+	if task.Status.State != a2a.TaskStateInputRequired {
+		t.Errorf("task state = %s, want input-required", task.Status.State)
+	}
+	if status, _ := x402state.ExtractPaymentStatus(task); status != x402state.PaymentRequired {
+		t.Errorf("payment status = %q, want %q", status, x402state.PaymentRequired)
+	}
+	if reference, _ := x402state.ExtractSubscription(task); reference != nil {
+		t.Errorf("subscription = %+v, want none on a quoted task", reference)
+	}
+}
+
+func TestBusinessOrchestrator_Execute_SubscriptionCoversClaimedPayer(t *testing.T) {
+	payer := newSubscriber(t)
+	harness := &subscriptionHarness{}
+	validUntil := subscriptionNow.Add(24 * time.Hour)
+	orchestrator := harness.orchestrator(t, map[string]*Subscription{
+		payer.address: {ID: "sub-seat-1", ValidUntil: validUntil},
+	})
+
+	task := executeNewTask(t, orchestrator, &a2asrv.RequestContext{Message: payer.claim(t, payer.address, subscriptionNow)})
+
+	if task.Status.State != a2a.TaskStateCompleted {
+		t.Fatalf("task state = %s, want completed without a quote", task.Status.State)
+	}
+	if status, _ := x402state.ExtractPaymentStatus(task); status != x402state.PaymentNotRequired {
+		t.Errorf("payment status = %q, want %q", status, x402state.PaymentNotRequired)
+	}
+	reference, err := x402state.ExtractSubscription(task)
+	if err != nil || reference == nil {
+		t.Fatalf("ExtractSubscription() = %v, %v, want a reference", reference, err)
+	}
+	if reference.ID != "sub-seat-1" || reference.Payer != payer.address || reference.ValidUntil != validUntil.Unix() {
+		t.Errorf("subscription = %+v, want sub-seat-1 for %s until %d", reference, payer.address, validUntil.Unix())
+	}
+	if receipts, _ := x402state.ExtractPaymentReceipts(task); len(receipts) != 0 {
+		t.Errorf("receipts = %+v, want none", receipts)
+	}
+	if len(harness.requests) != 1 {
+		t.Fatalf("business calls = %d, want one paid call", len(harness.requests))
+	}
+	request := harness.requests[0]
+	if !request.PaymentVerified || request.Subscription != "sub-seat-1" || request.Payer != payer.address {
+		t.Errorf("business request = %+v, want a verified call for the subscription", request)
+	}
+}
+
+func TestBusinessOrchestrator_Execute_RelatedTaskDoesNotIdentifyPayer(t *testing.T) {
+	harness := &subscriptionHarness{}
+	orchestrator := harness.orchestrator(t, map[string]*Subscription{
+		"0xreturning": {ID: "sub-seat-2", ValidUntil: subscriptionNow.Add(time.Hour)},
+	})
+
+	paid := &a2a.Task{ID: "task-paid", ContextID: "context-subscription", Status: a2a.TaskStatus{State: a2a.TaskStateCompleted}}
+	paid.Status.Message = a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{Text: "done"})
+	if err := x402state.SetPaymentReceipts(paid.Status.Message, []*x402core.SettleResponse{{Success: true, Payer: "0xreturning", Transaction: "0xtx"}}); err != nil {
+		t.Fatalf("SetPaymentReceipts() error = %v", err)
+	}
+
+	// Anyone can name another payer's task and set its context ID.
+	task := executeNewTask(t, orchestrator, &a2asrv.RequestContext{
+		Message:      a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "render"}),
+		RelatedTasks: []*a2a.Task{paid},
+	})
+
+	assertQuoted(t, task)
+	if len(harness.lookups) != 0 {
+		t.Errorf("policy lookups = %v, want none without a claim", harness.lookups)
+	}
+}
+
+func TestBusinessOrchestrator_Execute_ReplayedClaimIsQuoted(t *testing.T) {
+	payer := newSubscriber(t)
+	harness := &subscriptionHarness{}
+	orchestrator := harness.orchestrator(t, map[string]*Subscription{
+		payer.address: {ID: "sub-seat-1", ValidUntil: subscriptionNow.Add(time.Hour)},
+	})
+	claim := payer.claim(t, payer.address, subscriptionNow)
+
+	first := executeNewTask(t, orchestrator, &a2asrv.RequestContext{Message: claim, TaskID: "task-first"})
+	if first.Status.State != a2a.TaskStateCompleted {
+		t.Fatalf("first task state = %s, want completed", first.Status.State)
+	}
+	replayed := executeNewTask(t, orchestrator, &a2asrv.RequestContext{Message: claim, TaskID: "task-replayed"})
+	assertQuoted(t, replayed)
+	for _, request := range harness.requests[1:] {
+		if request.PaymentVerified {
+			t.Errorf("business request = %+v, want the replay quoted rather than served", request)
+		}
+	}
+}
+
+func TestBusinessOrchestrator_Execute_ExpiredSubscriptionIsQuoted(t *testing.T) {
+	payer := newSubscriber(t)
+	harness := &subscriptionHarness{}
+	orchestrator := harness.orchestrator(t, map[string]*Subscription{
+		payer.address: {ID: "sub-lapsed", ValidUntil: subscriptionNow.Add(-time.Minute)},
+	})
+
+	task := executeNewTask(t, orchestrator, &a2asrv.RequestContext{Message: payer.claim(t, payer.address, subscriptionNow)})
+
+	assertQuoted(t, task)
+	if len(harness.lookups) != 1 {
+		t.Errorf("policy lookups = %v, want one", harness.lookups)
+	}
+}
+
+func TestBusinessOrchestrator_Execute_UnknownPayerIsQuoted(t *testing.T) {
+	payer := newSubscriber(t)
+	stranger := newSubscriber(t)
+
+	tests := []struct {
+		name        string
+		message     *a2a.Message
+		wantLookups int
+	}{
+		{
+			name:        "no subscription for payer",
+			message:     stranger.claim(t, stranger.address, subscriptionNow),
+			wantLookups: 1,
+		},
+		{
+			name:    "claim signed by someone else",
+			message: stranger.claim(t, payer.address, subscriptionNow),
+		},
+		{
+			name: "claim for another merchant",
+			message: payer.claim(t, payer.address, subscriptionNow, func(claim *x402state.SubscriptionClaim) {
+				claim.Audience = "0xother"
+			}),
+		},
+		{
+			name: "claim for another context",
+			message: payer.claim(t, payer.address, subscriptionNow, func(claim *x402state.SubscriptionClaim) {
+				claim.ContextID = "context-other"
+			}),
+		},
+		{
+			name: "claim without a nonce",
+			message: payer.claim(t, payer.address, subscriptionNow, func(claim *x402state.SubscriptionClaim) {
+				claim.Nonce = ""
+			}),
+		},
+		{
+			name:    "stale claim",
+			message: payer.claim(t, payer.address, subscriptionNow.Add(-time.Hour)),
+		},
+		{
+			name:    "no payer",
+			message: a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "render"}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			harness := &subscriptionHarness{}
+			orchestrator := harness.orchestrator(t, map[string]*Subscription{
+				payer.address: {ID: "sub-seat-1", ValidUntil: subscriptionNow.Add(time.Hour)},
+			})
+
+			task := executeNewTask(t, orchestrator, &a2asrv.RequestContext{Message: tt.message})
+
+			assertQuoted(t, task)
+			if len(harness.lookups) != tt.wantLookups {
+				t.Errorf("policy lookups = %v, want %d", harness.lookups, tt.wantLookups)
+			}
+		})
+	}
+}
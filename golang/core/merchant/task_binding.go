@@ -0,0 +1,83 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"fmt"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// WithRequireTaskBinding rejects payloads whose accepted block does not carry
+// the task binding back. Every quote binds its requirements to the task it
+// was issued for, and a payload bound to another task is always rejected with
+// TASK_BINDING_MISMATCH; this option decides what happens to payloads from
+// clients that rebuild the accepted block without the binding. They are
+// accepted by default.
+//
+// The binding catches a payload replayed onto another task as it was
+// submitted. It complements nonce replay tracking rather than replacing it.
+func WithRequireTaskBinding(required bool) Option {
+	return func(o *BusinessOrchestrator) {
+		o.requireTaskBinding = required
+	}
+}
+
+// taskBindingError reports a payload made against a quote for another task,
+// or one without a binding when bindings are required.
+type taskBindingError struct {
+	got  string
+	want a2a.TaskID
+}
+
+func (e *taskBindingError) Error() string {
+	if e.got == "" {
+		return fmt.Sprintf("payload is not bound to task %s", e.want)
+	}
+	return fmt.Sprintf("payload is bound to task %s, not %s", e.got, e.want)
+}
+
+// bindRequirements records taskID in the Extra of every requirement, copying
+// each Extra map so that requirements shared with other quotes are left
+// alone.
+func bindRequirements(taskID a2a.TaskID, requirements *x402types.PaymentRequired) {
+	if taskID == "" || requirements == nil {
+		return
+	}
+	for i := range requirements.Accepts {
+		req := &requirements.Accepts[i]
+		extra := make(map[string]interface{}, len(req.Extra)+1)
+		for key, value := range req.Extra {
+			extra[key] = value
+		}
+		extra[x402pkg.ExtraKeyTaskBinding] = string(taskID)
+		req.Extra = extra
+	}
+}
+
+// checkTaskBinding confirms that the payload's accepted block names taskID.
+// An unbound payload passes unless required is set.
+func checkTaskBinding(accepted x402types.PaymentRequirements, taskID a2a.TaskID, required bool) error {
+	bound, _ := accepted.Extra[x402pkg.ExtraKeyTaskBinding].(string)
+	switch {
+	case bound == "" && !required:
+		return nil
+	case bound != string(taskID):
+		return &taskBindingError{got: bound, want: taskID}
+	}
+	return nil
+}
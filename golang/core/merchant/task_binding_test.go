@@ -0,0 +1,105 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// payWithBinding submits a payment for the task's first quoted option with the
+// accepted block's task binding replaced by binding, or removed when binding
+// is empty.
+func payWithBinding(t *testing.T, orchestrator *BusinessOrchestrator, task *a2a.Task, binding string) {
+	t.Helper()
+	payQuotedTask(t, orchestrator, task, func(payload *x402types.PaymentPayload) {
+		payload.Accepted.Extra = map[string]interface{}{}
+		if binding != "" {
+			payload.Accepted.Extra[x402.ExtraKeyTaskBinding] = binding
+		}
+	})
+}
+
+func TestBusinessOrchestrator_QuoteBindsRequirementsToTask(t *testing.T) {
+	orchestrator := newNetworkMatchingOrchestrator(t)
+	task := quoteTask(t, orchestrator)
+
+	requirements, err := x402state.ExtractPaymentRequirements(task)
+	if err != nil || requirements == nil {
+		t.Fatalf("ExtractPaymentRequirements() = %v, %v", requirements, err)
+	}
+	for _, accepted := range requirements.Accepts {
+		if got := accepted.Extra[x402.ExtraKeyTaskBinding]; got != string(task.ID) {
+			t.Errorf("binding on %s = %v, want %s", accepted.Network, got, task.ID)
+		}
+	}
+
+	payOnNetwork(t, orchestrator, task, x402.NetworkBaseSepolia)
+	if task.Status.State != a2a.TaskStateCompleted {
+		t.Errorf("state = %s, want %s", task.Status.State, a2a.TaskStateCompleted)
+	}
+}
+
+func TestBusinessOrchestrator_TaskBinding(t *testing.T) {
+	tests := []struct {
+		name      string
+		binding   string
+		opts      []Option
+		wantState a2a.TaskState
+		wantCode  string
+	}{
+		{name: "bound to this task", binding: "task-option", wantState: a2a.TaskStateCompleted},
+		{name: "bound to another task", binding: "task-other", wantState: a2a.TaskStateFailed, wantCode: x402.ErrorCodeTaskBindingMismatch},
+		{name: "unbound legacy payload", wantState: a2a.TaskStateCompleted},
+		{
+			name:      "unbound payload when required",
+			opts:      []Option{WithRequireTaskBinding(true)},
+			wantState: a2a.TaskStateFailed,
+			wantCode:  x402.ErrorCodeTaskBindingMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orchestrator := newNetworkMatchingOrchestrator(t, tt.opts...)
+			verifyCalled := false
+			orchestrator.merchant.(*MockResourceServer).VerifyPaymentFunc = func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.VerifyResponse, error) {
+				verifyCalled = true
+				return &x402core.VerifyResponse{IsValid: true, Payer: "0xpayer"}, nil
+			}
+			task := quoteTask(t, orchestrator)
+
+			payWithBinding(t, orchestrator, task, tt.binding)
+			if task.Status.State != tt.wantState {
+				t.Fatalf("state = %s, want %s", task.Status.State, tt.wantState)
+			}
+			if tt.wantCode == "" {
+				return
+			}
+			if got := task.Status.Message.Metadata[x402.MetadataKeyError]; got != tt.wantCode {
+				t.Errorf("error code = %v, want %s", got, tt.wantCode)
+			}
+			if verifyCalled {
+				t.Error("facilitator verify called for a payload without this task's binding")
+			}
+		})
+	}
+}
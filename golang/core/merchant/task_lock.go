@@ -0,0 +1,107 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"sync"
+
+	"github.com/a2aproject/a2a-go/a2a"
+)
+
+// taskLocks serializes Execute and Cancel calls for the same task so two
+// concurrent submissions cannot both verify a payment or race on the task's
+// metadata. Different tasks proceed in parallel. It only covers a single
+// process; deployments with several merchant instances rely on the task and
+// payment state stores to coordinate.
+type taskLocks struct {
+	mu    sync.Mutex
+	locks map[a2a.TaskID]*taskLock
+}
+
+type taskLock struct {
+	held    chan struct{}
+	waiters int
+	// canceled is set by requestCancel and applies to the current holder.
+	canceled bool
+}
+
+// lock blocks until the caller holds the task's lock or ctx is done. The
+// returned function releases it; the entry is removed once nobody holds or
+// waits for it, so finished tasks do not accumulate.
+func (l *taskLocks) lock(ctx context.Context, taskID a2a.TaskID) (func(), error) {
+	if taskID == "" {
+		return func() {}, nil
+	}
+
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[a2a.TaskID]*taskLock)
+	}
+	entry, ok := l.locks[taskID]
+	if !ok {
+		entry = &taskLock{held: make(chan struct{}, 1)}
+		l.locks[taskID] = entry
+	}
+	entry.waiters++
+	l.mu.Unlock()
+
+	select {
+	case entry.held <- struct{}{}:
+		l.mu.Lock()
+		entry.canceled = false
+		l.mu.Unlock()
+		return func() {
+			<-entry.held
+			l.release(taskID, entry)
+		}, nil
+	case <-ctx.Done():
+		l.release(taskID, entry)
+		return nil, ctx.Err()
+	}
+}
+
+// requestCancel flags the execution currently holding the task's lock so it
+// stops before settling. It does not wait for the lock, which is the point:
+// Cancel would otherwise queue behind the execution it is trying to stop.
+func (l *taskLocks) requestCancel(taskID a2a.TaskID) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if entry, ok := l.locks[taskID]; ok {
+		entry.canceled = true
+	}
+}
+
+func (l *taskLocks) cancelRequested(taskID a2a.TaskID) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry, ok := l.locks[taskID]
+	return ok && entry.canceled
+}
+
+func (l *taskLocks) release(taskID a2a.TaskID, entry *taskLock) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry.waiters--
+	if entry.waiters == 0 {
+		delete(l.locks, taskID)
+	}
+}
+
+func (l *taskLocks) len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.locks)
+}
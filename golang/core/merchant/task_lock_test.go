@@ -0,0 +1,101 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// Run with -race: without per-task serialization the goroutines below write
+// the same task and metadata maps concurrently.
+func TestBusinessOrchestrator_ConcurrentSubmissionsAreSerialized(t *testing.T) {
+	var verifies, settles atomic.Int32
+	orchestrator := newTestOrchestrator(
+		t,
+		&MockResourceServer{
+			VerifyPaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.VerifyResponse, error) {
+				verifies.Add(1)
+				time.Sleep(time.Millisecond)
+				return &x402core.VerifyResponse{IsValid: true, Payer: "0x789"}, nil
+			},
+			SettlePaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.SettleResponse, error) {
+				settles.Add(1)
+				return &x402core.SettleResponse{Success: true, Network: x402.NetworkBaseSepolia, Transaction: "0xtx"}, nil
+			},
+		},
+		&mockBusinessService{},
+		[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+	)
+
+	task := quoteRequest(t, orchestrator, "task-concurrent", a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "buy"}))
+
+	var wg sync.WaitGroup
+	for range 8 {
+		submission := paymentSubmission(t, task)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := submitPayment(orchestrator, task, submission, &mockEventQueue{})
+			if err != nil {
+				t.Errorf("paid Execute() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if verifies.Load() != 1 || settles.Load() != 1 {
+		t.Errorf("verify calls = %d, settle calls = %d, want exactly one of each", verifies.Load(), settles.Load())
+	}
+	if task.Status.State != a2a.TaskStateCompleted {
+		t.Errorf("task state = %v, want %v", task.Status.State, a2a.TaskStateCompleted)
+	}
+	if n := orchestrator.taskLocks.len(); n != 0 {
+		t.Errorf("lock table has %d entries after all executions finished", n)
+	}
+}
+
+func TestTaskLocks_ContextCanceledWhileWaiting(t *testing.T) {
+	var locks taskLocks
+	unlock, err := locks.lock(context.Background(), "task-1")
+	if err != nil {
+		t.Fatalf("lock() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := locks.lock(ctx, "task-1"); err == nil {
+		t.Fatal("lock() on a held task with a canceled context succeeded")
+	}
+	otherUnlock, err := locks.lock(context.Background(), "task-2")
+	if err != nil {
+		t.Fatalf("lock() on a different task error = %v", err)
+	}
+	otherUnlock()
+
+	unlock()
+	if n := locks.len(); n != 0 {
+		t.Errorf("lock table has %d entries, want 0", n)
+	}
+}
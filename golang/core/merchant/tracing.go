@@ -0,0 +1,101 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// Tracer starts a span around each step of the payment flow. Spans nest under
+// the span already in ctx, so a tracer that reads the incoming request's
+// trace links the payment to the server that received it. Implementations
+// must be safe for concurrent use; the oteltrace package adapts an
+// OpenTelemetry tracer without adding that dependency here.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is one traced step. Attribute values are plain strings and never
+// include payload signatures.
+type Span interface {
+	SetAttribute(key, value string)
+	// End finishes the span, marking it failed when err is non-nil.
+	End(err error)
+}
+
+// Names of the spans the orchestrator starts. Every other span is a
+// descendant of SpanExecute.
+const (
+	SpanExecute         = "x402.execute"
+	SpanExtensionCheck  = "x402.extension_check"
+	SpanQuote           = "x402.quote"
+	SpanVerify          = "x402.verify"
+	SpanBusinessExecute = "x402.business_execute"
+	SpanSettle          = "x402.settle"
+	SpanEventWrite      = "x402.event_write"
+)
+
+// Attribute keys set on spans.
+const (
+	AttributeTaskID    = "x402.task_id"
+	AttributeNetwork   = "x402.network"
+	AttributeAmount    = "x402.amount"
+	AttributeErrorCode = "x402.error_code"
+)
+
+// WithTracer traces every Execute call. Without it no spans are started.
+func WithTracer(tracer Tracer) Option {
+	return func(o *BusinessOrchestrator) {
+		o.tracer = tracer
+	}
+}
+
+type nopTracer struct{}
+
+func (nopTracer) Start(ctx context.Context, _ string) (context.Context, Span) { return ctx, nopSpan{} }
+
+type nopSpan struct{}
+
+func (nopSpan) SetAttribute(string, string) {}
+func (nopSpan) End(error)                   {}
+
+// startSpan starts a span named name, tagged with taskID when it is known.
+func (o *BusinessOrchestrator) startSpan(ctx context.Context, name string, taskID a2a.TaskID) (context.Context, Span) {
+	ctx, span := o.tracer.Start(ctx, name)
+	if taskID != "" {
+		span.SetAttribute(AttributeTaskID, string(taskID))
+	}
+	return ctx, span
+}
+
+// setRequirementAttributes records the network and amount being paid.
+func setRequirementAttributes(span Span, requirement x402types.PaymentRequirements) {
+	span.SetAttribute(AttributeNetwork, requirement.Network)
+	span.SetAttribute(AttributeAmount, requirement.Amount)
+}
+
+// setSpanErrorCode records the x402 error code the task ended with, if any.
+func setSpanErrorCode(span Span, task *a2a.Task) {
+	if task == nil || task.Status.Message == nil {
+		return
+	}
+	if code, _ := task.Status.Message.Meta()[x402pkg.MetadataKeyError].(string); code != "" {
+		span.SetAttribute(AttributeErrorCode, code)
+	}
+}
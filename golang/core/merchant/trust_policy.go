@@ -0,0 +1,242 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/a2aproject/a2a-go/a2asrv/eventqueue"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+)
+
+// TrustCredentialHeader carries a pre-authorized credential on the HTTP
+// request. Clients without access to headers can attach it to the message
+// with state.SetTrustCredential instead.
+const TrustCredentialHeader = "X-X402-Credential"
+
+// TrustedClient is a caller exempted from payment until ExpiresAt. A zero
+// ExpiresAt never expires.
+type TrustedClient struct {
+	Subject   string
+	ExpiresAt time.Time
+}
+
+// TrustPolicy identifies callers, such as internal services or prepaid
+// enterprise customers, whose requests run without payment. It is consulted
+// for every new request; a nil client, an expired one, or an error falls back
+// to the normal quote.
+type TrustPolicy interface {
+	TrustedClient(ctx context.Context, requestContext *a2asrv.RequestContext) (*TrustedClient, error)
+}
+
+// TrustPolicyFunc adapts a function to the TrustPolicy interface.
+type TrustPolicyFunc func(ctx context.Context, requestContext *a2asrv.RequestContext) (*TrustedClient, error)
+
+func (f TrustPolicyFunc) TrustedClient(ctx context.Context, requestContext *a2asrv.RequestContext) (*TrustedClient, error) {
+	return f(ctx, requestContext)
+}
+
+// CredentialVerifier checks a pre-authorized credential and returns the client
+// it was issued to.
+type CredentialVerifier interface {
+	VerifyCredential(ctx context.Context, credential string) (*TrustedClient, error)
+}
+
+// CredentialTrustPolicy trusts callers that present a credential accepted by
+// Verifier, in the TrustCredentialHeader header or in the message metadata.
+type CredentialTrustPolicy struct {
+	Verifier CredentialVerifier
+}
+
+func (p CredentialTrustPolicy) TrustedClient(ctx context.Context, requestContext *a2asrv.RequestContext) (*TrustedClient, error) {
+	credential := state.ExtractTrustCredential(requestContext.Message)
+	if callContext, ok := a2asrv.CallContextFrom(ctx); ok {
+		if values, _ := callContext.RequestMeta().Get(TrustCredentialHeader); len(values) > 0 && values[0] != "" {
+			credential = values[0]
+		}
+	}
+	if credential == "" {
+		return nil, nil
+	}
+	return p.Verifier.VerifyCredential(ctx, credential)
+}
+
+// WithTrustPolicy lets trusted callers skip quoting, verification and
+// settlement entirely.
+func WithTrustPolicy(policy TrustPolicy) Option {
+	return func(o *BusinessOrchestrator) {
+		o.trustPolicy = policy
+	}
+}
+
+// HMACKeySet verifies credentials signed with HMAC-SHA256 under one of its
+// keys, indexed by key ID. A credential has the form
+// "<key ID>.<claims>.<signature>", where claims is the base64url JSON object
+// {"sub": subject, "exp": Unix expiry} and signature is the base64url MAC of
+// everything before it.
+type HMACKeySet map[string][]byte
+
+type hmacClaims struct {
+	Subject   string `json:"sub"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+}
+
+// IssueCredential signs a credential for subject under the key keyID. A zero
+// expiresAt issues a credential that never expires.
+func (s HMACKeySet) IssueCredential(keyID, subject string, expiresAt time.Time) (string, error) {
+	key, ok := s[keyID]
+	if !ok {
+		return "", fmt.Errorf("unknown key %q", keyID)
+	}
+	if strings.Contains(keyID, ".") {
+		return "", fmt.Errorf("key ID %q must not contain a dot", keyID)
+	}
+	if subject == "" {
+		return "", errors.New("credential subject is required")
+	}
+	claims := hmacClaims{Subject: subject}
+	if !expiresAt.IsZero() {
+		claims.ExpiresAt = expiresAt.Unix()
+	}
+	encoded, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode credential claims: %w", err)
+	}
+	signed := keyID + "." + base64.RawURLEncoding.EncodeToString(encoded)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(hmacSum(key, signed)), nil
+}
+
+// VerifyCredential checks the credential's signature. The expiry is returned
+// for the orchestrator to enforce against its own clock.
+func (s HMACKeySet) VerifyCredential(ctx context.Context, credential string) (*TrustedClient, error) {
+	parts := strings.Split(credential, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed credential")
+	}
+	keyID, encoded, signature := parts[0], parts[1], parts[2]
+	signed := keyID + "." + encoded
+	key, ok := s[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", keyID)
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, hmacSum(key, signed)) {
+		return nil, errors.New("invalid credential signature")
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("malformed credential claims: %w", err)
+	}
+	var claims hmacClaims
+	if err := json.Unmarshal(decoded, &claims); err != nil {
+		return nil, fmt.Errorf("malformed credential claims: %w", err)
+	}
+	client := &TrustedClient{Subject: claims.Subject}
+	if claims.ExpiresAt != 0 {
+		client.ExpiresAt = time.Unix(claims.ExpiresAt, 0)
+	}
+	return client, nil
+}
+
+func hmacSum(key []byte, message string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(message))
+	return mac.Sum(nil)
+}
+
+// trustedClient returns the client exempting a new request from payment, or
+// nil when the request must be quoted.
+func (o *BusinessOrchestrator) trustedClient(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+) *TrustedClient {
+	if o.trustPolicy == nil {
+		return nil
+	}
+	client, err := o.trustPolicy.TrustedClient(ctx, requestContext)
+	if err != nil {
+		o.logger.WarnContext(ctx, "x402 trust credential rejected: quoting instead",
+			"task_id", task.ID,
+			"context_id", task.ContextID,
+			"error", err,
+		)
+		return nil
+	}
+	if client == nil || client.Subject == "" {
+		return nil
+	}
+	if !client.ExpiresAt.IsZero() && !o.now().Before(client.ExpiresAt) {
+		o.logger.WarnContext(ctx, "x402 trust credential expired: quoting instead",
+			"task_id", task.ID,
+			"context_id", task.ContextID,
+			"subject", client.Subject,
+			"expired_at", client.ExpiresAt,
+		)
+		return nil
+	}
+	return client
+}
+
+// executeTrusted runs a request for a trusted client and completes the task
+// with the client's subject instead of a receipt.
+func (o *BusinessOrchestrator) executeTrusted(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	eventQueue eventqueue.Queue,
+	message *a2a.Message,
+	prompt string,
+	skillID string,
+	client *TrustedClient,
+) error {
+	o.logger.InfoContext(ctx, "x402 request exempted for trusted client",
+		"task_id", task.ID,
+		"context_id", task.ContextID,
+		"subject", client.Subject,
+	)
+	if err := o.transitionToWorking(ctx, requestContext, task, eventQueue); err != nil {
+		return err
+	}
+	result, err := o.executePaidRequest(ctx, requestContext, eventQueue, business.Request{
+		Prompt:          prompt,
+		PaymentVerified: true,
+		Trusted:         client.Subject,
+		SkillID:         skillID,
+		TaskID:          task.ID,
+		ContextID:       task.ContextID,
+		Message:         message,
+		Metadata:        state.RequestMetadata(message),
+		Round:           1,
+	})
+	if err != nil {
+		return o.transitionToTaskFailed(ctx, requestContext, task, eventQueue, err, businessErrorCode(err))
+	}
+	return o.transitionToBusinessCompleted(ctx, requestContext, task, eventQueue, result, func(message *a2a.Message) {
+		state.SetPaymentStatus(message, state.PaymentNotRequired)
+		state.SetTrustedSubject(message, client.Subject)
+	})
+}
@@ -0,0 +1,117 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+)
+
+func TestBusinessOrchestrator_Execute_TrustPolicy(t *testing.T) {
+	keys := HMACKeySet{"k1": []byte("internal-secret")}
+	issue := func(subject string, expiresAt time.Time) string {
+		credential, err := keys.IssueCredential("k1", subject, expiresAt)
+		if err != nil {
+			t.Fatalf("IssueCredential() error = %v", err)
+		}
+		return credential
+	}
+	forged, err := HMACKeySet{"k1": []byte("other-secret")}.IssueCredential("k1", "billing-service", time.Time{})
+	if err != nil {
+		t.Fatalf("IssueCredential() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		header      string
+		metadata    string
+		wantSubject string
+	}{
+		{name: "valid header", header: issue("billing-service", subscriptionNow.Add(time.Hour)), wantSubject: "billing-service"},
+		{name: "valid metadata", metadata: issue("acme-corp", time.Time{}), wantSubject: "acme-corp"},
+		{name: "expired", header: issue("billing-service", subscriptionNow.Add(-time.Second))},
+		{name: "forged", header: forged},
+		{name: "malformed", metadata: "not-a-credential"},
+		{name: "absent"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []business.Request
+			service := &mockBusinessService{}
+			orchestrator := newTestOrchestrator(
+				t,
+				&MockResourceServer{},
+				&mockBusinessService{executeFunc: func(ctx context.Context, request business.Request) (*business.Result, error) {
+					requests = append(requests, request)
+					return service.Execute(ctx, request)
+				}},
+				[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+				WithTrustPolicy(CredentialTrustPolicy{Verifier: keys}),
+				WithClock(func() time.Time { return subscriptionNow }),
+			)
+
+			message := a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "render"})
+			if tt.metadata != "" {
+				x402state.SetTrustCredential(message, tt.metadata)
+			}
+			ctx := context.Background()
+			if tt.header != "" {
+				ctx, _ = a2asrv.WithCallContext(ctx, a2asrv.NewRequestMeta(map[string][]string{
+					TrustCredentialHeader: {tt.header},
+				}))
+			}
+			requestContext := &a2asrv.RequestContext{Message: message, TaskID: "task-trust", ContextID: "context-trust"}
+			if err := orchestrator.executeInPlace(ctx, requestContext, &mockEventQueue{}); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			task := requestContext.StoredTask
+
+			if tt.wantSubject == "" {
+				assertQuoted(t, task)
+				if subject := x402state.ExtractTrustedSubject(task); subject != "" {
+					t.Errorf("trusted subject = %q, want none on a quoted task", subject)
+				}
+				return
+			}
+			if task.Status.State != a2a.TaskStateCompleted {
+				t.Fatalf("task state = %s, want completed", task.Status.State)
+			}
+			if subject := x402state.ExtractTrustedSubject(task); subject != tt.wantSubject {
+				t.Errorf("trusted subject = %q, want %q", subject, tt.wantSubject)
+			}
+			if status, _ := x402state.ExtractPaymentStatus(task); status != x402state.PaymentNotRequired {
+				t.Errorf("payment status = %q, want %q", status, x402state.PaymentNotRequired)
+			}
+			if receipts, _ := x402state.ExtractPaymentReceipts(task); len(receipts) != 0 {
+				t.Errorf("receipts = %v, want none", receipts)
+			}
+			if len(requests) != 1 || !requests[0].PaymentVerified || requests[0].Trusted != tt.wantSubject {
+				t.Errorf("business requests = %+v, want one trusted request", requests)
+			}
+			if _, ok := requests[0].Metadata[x402.MetadataKeyTrustCredential]; ok {
+				t.Error("business request metadata carries the credential")
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/a2aproject/a2a-go/a2asrv/eventqueue"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// DefaultMaxOptionRetries bounds how many payments on an unquoted network or
+// option a task rejects before the submission fails instead.
+const DefaultMaxOptionRetries = 2
+
+// WithMaxOptionRetries sets how many times a task may reject a payment for a
+// network or option it never quoted while keeping the quote open. Zero fails
+// the first such payment.
+func WithMaxOptionRetries(retries int) Option {
+	return func(o *BusinessOrchestrator) {
+		o.maxOptionRetries = max(retries, 0)
+	}
+}
+
+// unsupportedOptionError reports a payload that matches none of the quoted
+// requirements, typically one for a network the merchant did not offer.
+type unsupportedOptionError struct {
+	accepted x402types.PaymentRequirements
+}
+
+func (e *unsupportedOptionError) Error() string {
+	return fmt.Sprintf("no matching payment requirement found for payload (accepted: scheme=%s, network=%s, amount=%s, asset=%s, payTo=%s)",
+		e.accepted.Scheme,
+		e.accepted.Network,
+		e.accepted.Amount,
+		e.accepted.Asset,
+		e.accepted.PayTo)
+}
+
+// rejectUnsupportedOption answers a payment on an unquoted option with
+// payment-rejected while keeping the task in input-required with its original
+// requirements, so the client can pay again with an offered option. It reports
+// false once the task has used up its retries and the caller fails the
+// payment as before.
+func (o *BusinessOrchestrator) rejectUnsupportedOption(
+	ctx context.Context,
+	requestContext *a2asrv.RequestContext,
+	task *a2a.Task,
+	eventQueue eventqueue.Queue,
+	cause error,
+) (bool, error) {
+	retries := state.ExtractOptionRetries(task)
+	if retries >= o.maxOptionRetries || task.Status.Message == nil {
+		return false, nil
+	}
+
+	// The quote's metadata carries the requirements, prompt, skill, round and
+	// receipts, all of which the next submission needs.
+	message := a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{
+		Text: fmt.Sprintf("Payment rejected (%v). Please pay with one of the offered options.", cause),
+	})
+	message.Metadata = maps.Clone(task.Status.Message.Metadata)
+	state.SetPaymentStatus(message, state.PaymentRejected)
+	state.SetPaymentError(message, x402pkg.ErrorCodeUnsupportedOption)
+	state.SetOptionRetries(message, retries+1)
+	recordFailedAttempt(task, message)
+	task.Status.Message = message
+	task.Status.State = a2a.TaskStateInputRequired
+	o.logger.InfoContext(ctx, "x402 payment option rejected; quote kept open",
+		"task_id", task.ID,
+		"context_id", task.ContextID,
+		"retries", retries+1,
+	)
+
+	return true, o.writeEvent(ctx, task, eventQueue, statusEvent(requestContext, task))
+}
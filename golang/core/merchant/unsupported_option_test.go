@@ -0,0 +1,122 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// newNetworkMatchingOrchestrator quotes on Base Sepolia and matches payloads
+// by network, like the x402 resource server.
+func newNetworkMatchingOrchestrator(t *testing.T, opts ...Option) *BusinessOrchestrator {
+	return newTestOrchestrator(
+		t,
+		&MockResourceServer{
+			FindMatchingRequirementsFunc: func(accepts []x402types.PaymentRequirements, payload x402types.PaymentPayload) *x402types.PaymentRequirements {
+				for i := range accepts {
+					if accepts[i].Network == payload.Accepted.Network {
+						return &accepts[i]
+					}
+				}
+				return nil
+			},
+		},
+		&mockBusinessService{},
+		[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+		opts...,
+	)
+}
+
+// payOnNetwork submits a payment for the task's first quoted option, moved to
+// network.
+func payOnNetwork(t *testing.T, orchestrator *BusinessOrchestrator, task *a2a.Task, network string) {
+	t.Helper()
+	payQuotedTask(t, orchestrator, task, func(payload *x402types.PaymentPayload) {
+		payload.Accepted.Network = network
+	})
+}
+
+func TestBusinessOrchestrator_Execute_UnsupportedNetworkKeepsQuoteOpen(t *testing.T) {
+	orchestrator := newNetworkMatchingOrchestrator(t)
+	task := quoteTask(t, orchestrator)
+	quoted, err := x402state.ExtractPaymentRequirements(task)
+	if err != nil {
+		t.Fatalf("ExtractPaymentRequirements() error = %v", err)
+	}
+
+	payOnNetwork(t, orchestrator, task, "eip155:1")
+	if task.Status.State != a2a.TaskStateInputRequired {
+		t.Fatalf("state after wrong network = %s, want %s", task.Status.State, a2a.TaskStateInputRequired)
+	}
+	if status, _ := x402state.ExtractPaymentStatus(task); status != x402state.PaymentRejected {
+		t.Errorf("payment status = %s, want %s", status, x402state.PaymentRejected)
+	}
+	if got := task.Status.Message.Metadata[x402.MetadataKeyError]; got != x402.ErrorCodeUnsupportedOption {
+		t.Errorf("error code = %v, want %s", got, x402.ErrorCodeUnsupportedOption)
+	}
+	if got := x402state.ExtractOptionRetries(task); got != 1 {
+		t.Errorf("option retries = %d, want 1", got)
+	}
+	requirements, err := x402state.ExtractPaymentRequirements(task)
+	if err != nil || requirements == nil || len(requirements.Accepts) != len(quoted.Accepts) || requirements.Accepts[0].Network != quoted.Accepts[0].Network {
+		t.Fatalf("requirements after rejection = %+v, %v, want the original quote", requirements, err)
+	}
+
+	// A message that is not a payment leaves the quote open.
+	if err := orchestrator.executeInPlace(context.Background(), &a2asrv.RequestContext{
+		Message:    a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "which networks?"}),
+		StoredTask: task,
+		TaskID:     task.ID,
+		ContextID:  task.ContextID,
+	}, &mockEventQueue{}); err != nil {
+		t.Fatalf("follow-up Execute() error = %v", err)
+	}
+	if task.Status.State != a2a.TaskStateInputRequired {
+		t.Fatalf("state after follow-up = %s, want %s", task.Status.State, a2a.TaskStateInputRequired)
+	}
+
+	payOnNetwork(t, orchestrator, task, x402.NetworkBaseSepolia)
+	if task.Status.State != a2a.TaskStateCompleted {
+		t.Fatalf("state after supported network = %s, want %s", task.Status.State, a2a.TaskStateCompleted)
+	}
+	if got, ok := task.Status.Message.Metadata[x402.MetadataKeyError]; ok {
+		t.Errorf("completed task error code = %v, want none", got)
+	}
+}
+
+func TestBusinessOrchestrator_Execute_UnsupportedNetworkRetriesExhausted(t *testing.T) {
+	orchestrator := newNetworkMatchingOrchestrator(t, WithMaxOptionRetries(1))
+	task := quoteTask(t, orchestrator)
+
+	payOnNetwork(t, orchestrator, task, "eip155:1")
+	if task.Status.State != a2a.TaskStateInputRequired {
+		t.Fatalf("state after first wrong network = %s, want %s", task.Status.State, a2a.TaskStateInputRequired)
+	}
+	payOnNetwork(t, orchestrator, task, "eip155:1")
+	if task.Status.State != a2a.TaskStateFailed {
+		t.Fatalf("state after retries exhausted = %s, want %s", task.Status.State, a2a.TaskStateFailed)
+	}
+	if got := task.Status.Message.Metadata[x402.MetadataKeyError]; got != x402.ErrorCodeUnsupportedOption {
+		t.Errorf("error code = %v, want %s", got, x402.ErrorCodeUnsupportedOption)
+	}
+}
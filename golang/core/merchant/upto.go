@@ -0,0 +1,73 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+// validateScheme rejects schemes this merchant cannot settle. An empty scheme
+// is left to the SDK default.
+func validateScheme(scheme, network string) error {
+	switch scheme {
+	case "", x402pkg.SchemeExact:
+		return nil
+	case x402pkg.SchemeUpto:
+		if !strings.HasPrefix(network, "eip155:") {
+			return fmt.Errorf("scheme %q is only supported on EVM networks, not %s", scheme, network)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported payment scheme %q", scheme)
+	}
+}
+
+// settlementRequirement returns the requirement to settle for a business
+// result. Under the upto scheme the service may report a lower amount than
+// the authorized maximum; the SDK settles whatever Amount the requirement
+// carries, so the copy is reduced accordingly.
+func settlementRequirement(
+	matched *x402types.PaymentRequirements,
+	result *business.Result,
+) (*x402types.PaymentRequirements, error) {
+	if result == nil || result.SettleAmount == "" || result.SettleAmount == matched.Amount {
+		return matched, nil
+	}
+	if matched.Scheme != x402pkg.SchemeUpto {
+		return nil, fmt.Errorf("settle amount %s differs from the quoted %s under the %q scheme", result.SettleAmount, matched.Amount, matched.Scheme)
+	}
+
+	actual, ok := new(big.Int).SetString(result.SettleAmount, 10)
+	if !ok || actual.Sign() < 0 {
+		return nil, fmt.Errorf("invalid settle amount %q", result.SettleAmount)
+	}
+	maximum, ok := new(big.Int).SetString(matched.Amount, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid authorized amount %q", matched.Amount)
+	}
+	if actual.Cmp(maximum) > 0 {
+		return nil, fmt.Errorf("settle amount %s exceeds the authorized maximum %s", actual, maximum)
+	}
+
+	settled := *matched
+	settled.Amount = actual.String()
+	return &settled, nil
+}
@@ -0,0 +1,145 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402state "github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+func TestBusinessOrchestrator_UptoSettlement(t *testing.T) {
+	tests := []struct {
+		name         string
+		settleAmount string
+		wantState    a2a.TaskState
+		wantSettled  string
+		wantCode     string
+	}{
+		{
+			name:         "settles the metered amount",
+			settleAmount: "250000",
+			wantState:    a2a.TaskStateCompleted,
+			wantSettled:  "250000",
+		},
+		{
+			name:        "settles the full authorization by default",
+			wantState:   a2a.TaskStateCompleted,
+			wantSettled: "1000000",
+		},
+		{
+			name:         "rejects an amount above the maximum",
+			settleAmount: "1000001",
+			wantState:    a2a.TaskStateFailed,
+			wantCode:     x402.ErrorCodeInvalidAmount,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var settled []string
+			orchestrator := newTestOrchestrator(
+				t,
+				&MockResourceServer{
+					BuildPaymentRequirementsFromConfigFunc: func(ctx context.Context, config x402core.ResourceConfig) ([]x402types.PaymentRequirements, error) {
+						return []x402types.PaymentRequirements{{
+							Scheme:  config.Scheme,
+							Network: string(config.Network),
+							PayTo:   config.PayTo,
+							Asset:   "0x456",
+							Amount:  "1000000",
+						}}, nil
+					},
+					SettlePaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.SettleResponse, error) {
+						settled = append(settled, requirements.Amount)
+						return &x402core.SettleResponse{Success: true, Network: x402.NetworkBaseSepolia, Transaction: "0xtx"}, nil
+					},
+				},
+				&mockBusinessService{executeFunc: func(ctx context.Context, request business.Request) (*business.Result, error) {
+					if request.PaymentVerified {
+						return &business.Result{Message: "metered", SettleAmount: tt.settleAmount}, nil
+					}
+					return nil, business.NewPaymentRequiredError("metered", business.ServiceRequirements{
+						Price:    "1.00",
+						Resource: "/generate",
+						Scheme:   x402.SchemeUpto,
+					})
+				}},
+				[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+			)
+
+			task := quoteRequest(t, orchestrator, "task-upto", a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "generate"}))
+			requirements, err := x402state.ExtractPaymentRequirements(task)
+			if err != nil {
+				t.Fatalf("ExtractPaymentRequirements() error = %v", err)
+			}
+			if got := requirements.Accepts[0].Scheme; got != x402.SchemeUpto {
+				t.Fatalf("quoted scheme = %q, want %q", got, x402.SchemeUpto)
+			}
+			payQuotedTask(t, orchestrator, task)
+
+			if task.Status.State != tt.wantState {
+				t.Fatalf("task state = %v, want %v", task.Status.State, tt.wantState)
+			}
+			if tt.wantCode != "" {
+				if len(settled) != 0 {
+					t.Errorf("settled %v, want no settlement", settled)
+				}
+				if got := task.Status.Message.Metadata[x402.MetadataKeyError]; got != tt.wantCode {
+					t.Errorf("error code = %v, want %s", got, tt.wantCode)
+				}
+				return
+			}
+			if len(settled) != 1 || settled[0] != tt.wantSettled {
+				t.Fatalf("settled amounts = %v, want [%s]", settled, tt.wantSettled)
+			}
+			receipts, err := x402state.ExtractPaymentReceipts(task)
+			if err != nil || len(receipts) != 1 {
+				t.Fatalf("ExtractPaymentReceipts() = %v, %v", receipts, err)
+			}
+			if receipts[0].Amount != tt.wantSettled {
+				t.Errorf("receipt amount = %q, want %q", receipts[0].Amount, tt.wantSettled)
+			}
+		})
+	}
+}
+
+func TestValidateScheme(t *testing.T) {
+	tests := []struct {
+		scheme  string
+		network string
+		wantErr bool
+	}{
+		{scheme: "", network: x402.NetworkBase},
+		{scheme: x402.SchemeExact, network: x402.NetworkSolanaDevnet},
+		{scheme: x402.SchemeUpto, network: x402.NetworkBaseSepolia},
+		{scheme: x402.SchemeUpto, network: x402.NetworkSolanaDevnet, wantErr: true},
+		{scheme: "stream", network: x402.NetworkBase, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		err := validateScheme(tt.scheme, tt.network)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("validateScheme(%q, %q) error = %v, wantErr %v", tt.scheme, tt.network, err, tt.wantErr)
+		}
+	}
+}
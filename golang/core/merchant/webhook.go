@@ -0,0 +1,272 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	x402core "github.com/x402-foundation/x402/go"
+)
+
+// WebhookSignatureHeader carries "sha256=" followed by the hex HMAC-SHA256 of
+// the request body under the shared secret.
+const WebhookSignatureHeader = "X-X402-Signature"
+
+// Webhook event types.
+const (
+	WebhookPaymentSettled = "payment.settled"
+	WebhookTaskFailed     = "task.failed"
+	// WebhookPaymentFailed reports a background settlement that failed after
+	// its task had already completed.
+	WebhookPaymentFailed = "payment.failed"
+)
+
+// WebhookNotifier posts payment outcomes to a merchant backend, such as a
+// fulfillment service, as signed JSON documents.
+type WebhookNotifier struct {
+	URL    string
+	Secret []byte
+	// Client sends the requests. Defaults to a client with a 10s timeout.
+	Client *http.Client
+	// MaxAttempts is the total number of deliveries per event, including the
+	// first. Defaults to 5.
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry; it doubles on each
+	// retry up to one minute. Defaults to 1s.
+	InitialBackoff time.Duration
+	// QueueSize bounds the in-memory outbox. When it is full new events are
+	// dropped and logged. Defaults to 256.
+	QueueSize int
+}
+
+// WebhookEvent is the document posted to the webhook URL. ID is stable across
+// retries so receivers can discard duplicates.
+type WebhookEvent struct {
+	ID        string                     `json:"id"`
+	Type      string                     `json:"type"`
+	TaskID    a2a.TaskID                 `json:"taskId"`
+	ContextID string                     `json:"contextId"`
+	State     a2a.TaskState              `json:"state"`
+	Payer     string                     `json:"payer,omitempty"`
+	Receipts  []*x402core.SettleResponse `json:"receipts,omitempty"`
+	ErrorCode string                     `json:"errorCode,omitempty"`
+	Error     string                     `json:"error,omitempty"`
+	Timestamp time.Time                  `json:"timestamp"`
+}
+
+// WithWebhookNotifier posts a WebhookEvent after each successful settlement
+// and each terminal failure, once the corresponding task event has been
+// written. Deliveries run from an in-memory outbox, so a webhook outage never
+// delays a task; events still queued are lost on restart. Call Shutdown to
+// drain the outbox; deliveries still retrying when its context ends are
+// abandoned.
+func WithWebhookNotifier(notifier WebhookNotifier) Option {
+	return func(o *BusinessOrchestrator) {
+		if notifier.Client == nil {
+			notifier.Client = &http.Client{Timeout: 10 * time.Second}
+		}
+		if notifier.MaxAttempts <= 0 {
+			notifier.MaxAttempts = 5
+		}
+		if notifier.InitialBackoff <= 0 {
+			notifier.InitialBackoff = time.Second
+		}
+		if notifier.QueueSize <= 0 {
+			notifier.QueueSize = 256
+		}
+		o.webhooks = &webhookOutbox{
+			notifier: notifier,
+			events:   make(chan *WebhookEvent, notifier.QueueSize),
+			stop:     make(chan struct{}),
+			done:     make(chan struct{}),
+		}
+	}
+}
+
+// SignWebhook returns the WebhookSignatureHeader value for body.
+func SignWebhook(body []byte, secret []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature reports whether signature is the
+// WebhookSignatureHeader value for body under secret.
+func VerifyWebhookSignature(body []byte, signature string, secret []byte) bool {
+	return hmac.Equal([]byte(signature), []byte(SignWebhook(body, secret)))
+}
+
+type webhookOutbox struct {
+	notifier WebhookNotifier
+	events   chan *WebhookEvent
+	done     chan struct{}
+
+	// stop is closed when Shutdown gives up waiting, ending any retry
+	// backoff so the delivery goroutine exits.
+	stopOnce sync.Once
+	stop     chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+func (w *webhookOutbox) start(o *BusinessOrchestrator) {
+	go func() {
+		defer close(w.done)
+		for event := range w.events {
+			if err := w.deliver(event); err != nil {
+				o.logger.Error("x402 webhook undelivered",
+					"task_id", event.TaskID,
+					"webhook_id", event.ID,
+					"type", event.Type,
+					"error", err,
+				)
+			}
+		}
+	}()
+}
+
+// enqueue reports false when the outbox is full or shut down.
+func (w *webhookOutbox) enqueue(event *WebhookEvent) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return false
+	}
+	select {
+	case w.events <- event:
+		return true
+	default:
+		return false
+	}
+}
+
+func (w *webhookOutbox) shutdown(ctx context.Context) error {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.events)
+	}
+	w.mu.Unlock()
+
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		w.stopOnce.Do(func() { close(w.stop) })
+		return fmt.Errorf("pending webhooks not delivered: %w", ctx.Err())
+	}
+}
+
+// deliver posts event until the receiver accepts it, rejects it outright
+// with a 4xx other than 429, the attempts run out, or the outbox is stopped.
+func (w *webhookOutbox) deliver(event *WebhookEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook: %w", err)
+	}
+	signature := SignWebhook(body, w.notifier.Secret)
+
+	backoff := w.notifier.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		retry, err := w.post(body, signature)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= w.notifier.MaxAttempts {
+			return fmt.Errorf("webhook delivery failed after %d attempts: %w", attempt, err)
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-w.stop:
+			timer.Stop()
+			return fmt.Errorf("webhook delivery abandoned at shutdown after %d attempts: %w", attempt, err)
+		}
+		backoff = min(backoff*2, time.Minute)
+	}
+}
+
+func (w *webhookOutbox) post(body []byte, signature string) (bool, error) {
+	request, err := http.NewRequest(http.MethodPost, w.notifier.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(WebhookSignatureHeader, signature)
+
+	response, err := w.notifier.Client.Do(request)
+	if err != nil {
+		return true, err
+	}
+	response.Body.Close()
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return false, nil
+	}
+	retry := response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= 500
+	return retry, fmt.Errorf("webhook receiver returned %s", response.Status)
+}
+
+// notifyWebhook queues a webhook for task. Callers invoke it only once the
+// corresponding task event has been written.
+func (o *BusinessOrchestrator) notifyWebhook(
+	ctx context.Context,
+	eventType string,
+	task *a2a.Task,
+	receipts []*x402core.SettleResponse,
+	errorCode string,
+	cause error,
+) {
+	if o.webhooks == nil {
+		return
+	}
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	event := &WebhookEvent{
+		ID:        hex.EncodeToString(id),
+		Type:      eventType,
+		TaskID:    task.ID,
+		ContextID: task.ContextID,
+		State:     task.Status.State,
+		Receipts:  receipts,
+		ErrorCode: errorCode,
+		Timestamp: o.now().UTC(),
+	}
+	for _, receipt := range receipts {
+		if receipt != nil && receipt.Payer != "" {
+			event.Payer = receipt.Payer
+		}
+	}
+	if cause != nil {
+		event.Error = cause.Error()
+	}
+	if !o.webhooks.enqueue(event) {
+		o.logger.WarnContext(ctx, "x402 webhook dropped: outbox full or shut down",
+			"task_id", task.ID,
+			"type", eventType,
+		)
+	}
+}
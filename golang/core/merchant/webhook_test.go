@@ -0,0 +1,270 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merchant
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+var webhookSecret = []byte("whsec_test")
+
+// webhookReceiver verifies signatures and records the events it accepts.
+// Each request is answered with the next status in statuses, then 200.
+type webhookReceiver struct {
+	t        *testing.T
+	statuses []int
+	terminal *atomic.Bool
+
+	mu       sync.Mutex
+	requests int
+	events   []WebhookEvent
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		r.t.Errorf("read webhook body: %v", err)
+	}
+	if !VerifyWebhookSignature(body, req.Header.Get(WebhookSignatureHeader), webhookSecret) {
+		r.t.Errorf("webhook signature %q does not match body", req.Header.Get(WebhookSignatureHeader))
+	}
+	if r.terminal != nil && !r.terminal.Load() {
+		r.t.Error("webhook delivered before the terminal task event was written")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests++
+	if len(r.statuses) > 0 {
+		status := r.statuses[0]
+		r.statuses = r.statuses[1:]
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+	}
+	var event WebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		r.t.Errorf("decode webhook: %v", err)
+	}
+	r.events = append(r.events, event)
+}
+
+// terminalFlagQueue records when a terminal status update is written.
+type terminalFlagQueue struct {
+	mockEventQueue
+	terminal *atomic.Bool
+}
+
+func (q *terminalFlagQueue) Write(ctx context.Context, event a2a.Event) error {
+	if update, ok := event.(*a2a.TaskStatusUpdateEvent); ok && update.Status.State.Terminal() {
+		q.terminal.Store(true)
+	}
+	return q.mockEventQueue.Write(ctx, event)
+}
+
+func newWebhookOrchestrator(t *testing.T, server *MockResourceServer, service business.BusinessService, url string, opts ...Option) *BusinessOrchestrator {
+	opts = append([]Option{WithWebhookNotifier(WebhookNotifier{
+		URL:            url,
+		Secret:         webhookSecret,
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+	})}, opts...)
+	return newTestOrchestrator(
+		t,
+		server,
+		service,
+		[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+		opts...,
+	)
+}
+
+// runPaidTask quotes a task and pays it, writing the paid execution's events
+// to queue.
+func runPaidTask(t *testing.T, orchestrator *BusinessOrchestrator, queue *terminalFlagQueue) {
+	t.Helper()
+	task := quoteRequest(t, orchestrator, "task-webhook", a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "buy"}))
+	submission := paymentSubmission(t, task)
+	if err := submitPayment(orchestrator, task, submission, queue); err != nil {
+		t.Fatalf("paid Execute() error = %v", err)
+	}
+}
+
+func shutdown(t *testing.T, orchestrator *BusinessOrchestrator) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := orchestrator.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+}
+
+func TestBusinessOrchestrator_Execute_PostsSettlementWebhook(t *testing.T) {
+	var terminal atomic.Bool
+	receiver := &webhookReceiver{t: t, terminal: &terminal}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	orchestrator := newWebhookOrchestrator(t, &MockResourceServer{
+		SettlePaymentFunc: func(ctx context.Context, payload x402types.PaymentPayload, requirements x402types.PaymentRequirements) (*x402core.SettleResponse, error) {
+			return &x402core.SettleResponse{Success: true, Transaction: "0xtx", Network: x402.NetworkBaseSepolia, Payer: "0xpayer"}, nil
+		},
+	}, &mockBusinessService{}, server.URL)
+	runPaidTask(t, orchestrator, &terminalFlagQueue{terminal: &terminal})
+	shutdown(t, orchestrator)
+
+	if len(receiver.events) != 1 {
+		t.Fatalf("webhooks = %+v, want one", receiver.events)
+	}
+	event := receiver.events[0]
+	if event.Type != WebhookPaymentSettled || event.TaskID != "task-webhook" || event.State != a2a.TaskStateCompleted {
+		t.Errorf("webhook = %+v, want payment.settled for completed task-webhook", event)
+	}
+	if event.Payer != "0xpayer" || len(event.Receipts) != 1 || event.Receipts[0].Transaction != "0xtx" {
+		t.Errorf("webhook payer/receipts = %q/%+v, want 0xpayer with tx 0xtx", event.Payer, event.Receipts)
+	}
+	if event.ID == "" || event.Timestamp.IsZero() {
+		t.Errorf("webhook id/timestamp = %q/%v, want both set", event.ID, event.Timestamp)
+	}
+}
+
+func TestBusinessOrchestrator_Execute_PostsFailureWebhook(t *testing.T) {
+	var terminal atomic.Bool
+	receiver := &webhookReceiver{t: t, terminal: &terminal}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	service := &mockBusinessService{executeFunc: func(ctx context.Context, request business.Request) (*business.Result, error) {
+		if request.PaymentVerified {
+			return nil, errors.New("render failed")
+		}
+		return (&mockBusinessService{}).Execute(ctx, request)
+	}}
+	orchestrator := newWebhookOrchestrator(t, &MockResourceServer{}, service, server.URL)
+	runPaidTask(t, orchestrator, &terminalFlagQueue{terminal: &terminal})
+	shutdown(t, orchestrator)
+
+	if len(receiver.events) != 1 {
+		t.Fatalf("webhooks = %+v, want one", receiver.events)
+	}
+	event := receiver.events[0]
+	if event.Type != WebhookTaskFailed || event.State != a2a.TaskStateFailed {
+		t.Errorf("webhook type/state = %q/%q, want %q/%q", event.Type, event.State, WebhookTaskFailed, a2a.TaskStateFailed)
+	}
+	if event.ErrorCode != x402.ErrorCodeBusinessExecutionFailed || event.Error == "" {
+		t.Errorf("webhook error = %q/%q, want %q with a message", event.ErrorCode, event.Error, x402.ErrorCodeBusinessExecutionFailed)
+	}
+}
+
+func TestWebhookOutbox_Retries(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantRequests int
+		wantEvents   int
+	}{
+		{name: "delivered after transient failures", statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}, wantRequests: 3, wantEvents: 1},
+		{name: "gives up after max attempts", statuses: []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway}, wantRequests: 3},
+		{name: "client error is not retried", statuses: []int{http.StatusBadRequest}, wantRequests: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver := &webhookReceiver{t: t, statuses: tt.statuses}
+			server := httptest.NewServer(receiver)
+			defer server.Close()
+
+			orchestrator := newWebhookOrchestrator(t, &MockResourceServer{}, &mockBusinessService{}, server.URL)
+			orchestrator.notifyWebhook(context.Background(), WebhookPaymentSettled, &a2a.Task{ID: "task-retry"}, nil, "", nil)
+			shutdown(t, orchestrator)
+
+			if receiver.requests != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", receiver.requests, tt.wantRequests)
+			}
+			if len(receiver.events) != tt.wantEvents {
+				t.Errorf("delivered = %d, want %d", len(receiver.events), tt.wantEvents)
+			}
+		})
+	}
+}
+
+func TestBusinessOrchestrator_Execute_WebhookOutageDoesNotBlockCompletion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	orchestrator := newTestOrchestrator(
+		t,
+		&MockResourceServer{},
+		&mockBusinessService{},
+		[]types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: "0x123"}},
+		WithWebhookNotifier(WebhookNotifier{URL: server.URL, Secret: webhookSecret, InitialBackoff: time.Hour}),
+	)
+	var terminal atomic.Bool
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		runPaidTask(t, orchestrator, &terminalFlagQueue{terminal: &terminal})
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("task completion blocked on webhook delivery")
+	}
+	if !terminal.Load() {
+		t.Error("task did not complete")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := orchestrator.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() error = %v, want deadline exceeded while the webhook is retrying", err)
+	}
+	select {
+	case <-orchestrator.webhooks.done:
+	case <-time.After(5 * time.Second):
+		t.Error("webhook delivery still waiting out its backoff after Shutdown gave up")
+	}
+}
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	body := []byte(`{"id":"1"}`)
+	signature := SignWebhook(body, webhookSecret)
+	if !VerifyWebhookSignature(body, signature, webhookSecret) {
+		t.Error("VerifyWebhookSignature() = false for a valid signature")
+	}
+	if VerifyWebhookSignature([]byte(`{"id":"2"}`), signature, webhookSecret) {
+		t.Error("VerifyWebhookSignature() = true for a tampered body")
+	}
+	if VerifyWebhookSignature(body, signature, []byte("other")) {
+		t.Error("VerifyWebhookSignature() = true for the wrong secret")
+	}
+}
@@ -0,0 +1,263 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package facilitator provides an in-process x402 facilitator speaking the
+// HTTP contract used by the SDK's facilitator client, so merchants can be
+// tested through the real resource server without a network dependency.
+package facilitator
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+const (
+	// DefaultPayer is the payer reported for verified and settled payments.
+	DefaultPayer = "0x857b06519E91e3A54538791bDbb0E22373e36b66"
+	// DefaultTransaction is the transaction hash reported for settlements.
+	DefaultTransaction = "0x00000000000000000000000000000000000000000000000000000000000f4c11"
+)
+
+// Options programs a MockFacilitator. Zero values select a facilitator that
+// supports exact payments on Base Sepolia, accepts every payload and settles
+// it with DefaultTransaction.
+type Options struct {
+	// Kinds lists the supported payment kinds. Defaults to exact on Base
+	// Sepolia.
+	Kinds []x402core.SupportedKind
+	// Payer is reported for every payment. Defaults to DefaultPayer.
+	Payer string
+
+	// InvalidReason, when set, makes verification answer that the payload is
+	// invalid for this reason, e.g. "insufficient_funds".
+	InvalidReason  string
+	InvalidMessage string
+	// SettleErrorReason, when set, makes settlement answer that it failed
+	// for this reason.
+	SettleErrorReason  string
+	SettleErrorMessage string
+	// Transaction is reported for successful settlements. Defaults to
+	// DefaultTransaction.
+	Transaction string
+
+	// SupportedStatus, VerifyStatus and SettleStatus, when set to anything
+	// but 200, make the endpoint answer with that status and no result, as
+	// a failing facilitator would.
+	SupportedStatus int
+	VerifyStatus    int
+	SettleStatus    int
+	// Latency delays every answer, e.g. to exercise facilitator timeouts.
+	// A request whose client gives up is dropped without an answer.
+	Latency time.Duration
+}
+
+// Request is a verify or settle request received by the facilitator.
+type Request struct {
+	Payload      x402types.PaymentPayload
+	Requirements x402types.PaymentRequirements
+}
+
+// MockFacilitator is a facilitator served over HTTP at URL. Its responses can
+// be reprogrammed while it runs with Update.
+type MockFacilitator struct {
+	// URL is the base URL to hand to the merchant as its facilitator.
+	URL string
+
+	mu             sync.Mutex
+	opts           Options
+	supportedCalls int
+	verified       []Request
+	settled        []Request
+}
+
+// NewMockFacilitator starts a facilitator answering as opts describes. It is
+// stopped when the test ends.
+func NewMockFacilitator(t testing.TB, opts Options) *MockFacilitator {
+	t.Helper()
+	f := &MockFacilitator{opts: opts}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /supported", f.handleSupported)
+	mux.HandleFunc("POST /verify", f.handleVerify)
+	mux.HandleFunc("POST /settle", f.handleSettle)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	f.URL = server.URL
+	return f
+}
+
+// Update changes how the facilitator answers later requests.
+func (f *MockFacilitator) Update(update func(*Options)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	update(&f.opts)
+}
+
+// SupportedCalls returns how many times the supported kinds were requested.
+func (f *MockFacilitator) SupportedCalls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.supportedCalls
+}
+
+// Verified returns the verify requests received so far.
+func (f *MockFacilitator) Verified() []Request {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.verified)
+}
+
+// Settled returns the settle requests received so far.
+func (f *MockFacilitator) Settled() []Request {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.settled)
+}
+
+func (f *MockFacilitator) handleSupported(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.supportedCalls++
+	opts := f.opts
+	f.mu.Unlock()
+	if !wait(r, opts.Latency) || failed(w, opts.SupportedStatus) {
+		return
+	}
+
+	kinds := opts.Kinds
+	if len(kinds) == 0 {
+		kinds = []x402core.SupportedKind{{X402Version: x402.X402Version, Scheme: x402.SchemeExact, Network: x402.NetworkBaseSepolia}}
+	}
+	writeJSON(w, x402core.SupportedResponse{Kinds: kinds, Extensions: []string{}, Signers: map[string][]string{}})
+}
+
+func (f *MockFacilitator) handleVerify(w http.ResponseWriter, r *http.Request) {
+	request, ok := decodeRequest(w, r)
+	if !ok {
+		return
+	}
+	f.mu.Lock()
+	f.verified = append(f.verified, request)
+	opts := f.opts
+	f.mu.Unlock()
+	if !wait(r, opts.Latency) || failed(w, opts.VerifyStatus) {
+		return
+	}
+
+	writeJSON(w, x402core.VerifyResponse{
+		IsValid:        opts.InvalidReason == "",
+		InvalidReason:  opts.InvalidReason,
+		InvalidMessage: opts.InvalidMessage,
+		Payer:          opts.payer(),
+	})
+}
+
+func (f *MockFacilitator) handleSettle(w http.ResponseWriter, r *http.Request) {
+	request, ok := decodeRequest(w, r)
+	if !ok {
+		return
+	}
+	f.mu.Lock()
+	f.settled = append(f.settled, request)
+	opts := f.opts
+	f.mu.Unlock()
+	if !wait(r, opts.Latency) || failed(w, opts.SettleStatus) {
+		return
+	}
+
+	response := x402core.SettleResponse{
+		Success: true,
+		Payer:   opts.payer(),
+		Network: x402core.Network(request.Requirements.Network),
+		Amount:  request.Requirements.Amount,
+	}
+	if opts.SettleErrorReason != "" {
+		response.Success = false
+		response.ErrorReason = opts.SettleErrorReason
+		response.ErrorMessage = opts.SettleErrorMessage
+	} else {
+		response.Transaction = opts.Transaction
+		if response.Transaction == "" {
+			response.Transaction = DefaultTransaction
+		}
+	}
+	writeJSON(w, response)
+}
+
+func (o Options) payer() string {
+	if o.Payer == "" {
+		return DefaultPayer
+	}
+	return o.Payer
+}
+
+// decodeRequest reads the body shared by verify and settle, answering 400
+// when it cannot be decoded.
+func decodeRequest(w http.ResponseWriter, r *http.Request) (Request, bool) {
+	var body struct {
+		PaymentPayload      json.RawMessage `json:"paymentPayload"`
+		PaymentRequirements json.RawMessage `json:"paymentRequirements"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return Request{}, false
+	}
+	payload, err := x402types.ToPaymentPayload(body.PaymentPayload)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return Request{}, false
+	}
+	requirements, err := x402types.ToPaymentRequirements(body.PaymentRequirements)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return Request{}, false
+	}
+	return Request{Payload: *payload, Requirements: *requirements}, true
+}
+
+// wait sleeps for latency and reports whether the client is still waiting.
+func wait(r *http.Request, latency time.Duration) bool {
+	if latency <= 0 {
+		return true
+	}
+	timer := time.NewTimer(latency)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-r.Context().Done():
+		return false
+	}
+}
+
+// failed answers with status when it is set to a failure.
+func failed(w http.ResponseWriter, status int) bool {
+	if status == 0 || status == http.StatusOK {
+		return false
+	}
+	http.Error(w, "mock facilitator failure", status)
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(value)
+}
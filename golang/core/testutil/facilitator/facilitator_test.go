@@ -0,0 +1,82 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package facilitator_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google-agentic-commerce/a2a-x402/core/testutil/facilitator"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402http "github.com/x402-foundation/x402/go/http"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+func encode(t *testing.T) (payload, requirements []byte) {
+	t.Helper()
+	accepted := x402types.PaymentRequirements{
+		Scheme:  x402.SchemeExact,
+		Network: x402.NetworkBaseSepolia,
+		Asset:   "0x036CbD53842c5426634e7929541eC2318f3dCF7e",
+		PayTo:   "0x209693Bc6afc0C5328bA36FaF03C514EF312287C",
+		Amount:  "10000",
+	}
+	payload, err := json.Marshal(x402types.PaymentPayload{X402Version: x402.X402Version, Accepted: accepted, Payload: map[string]interface{}{"signature": "0xabc"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	requirements, err = json.Marshal(accepted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return payload, requirements
+}
+
+func TestMockFacilitatorAnswersSDKClient(t *testing.T) {
+	ctx := context.Background()
+	mock := facilitator.NewMockFacilitator(t, facilitator.Options{})
+	client := x402http.NewHTTPFacilitatorClient(&x402http.FacilitatorConfig{URL: mock.URL})
+	payload, requirements := encode(t)
+
+	supported, err := client.GetSupported(ctx)
+	if err != nil || len(supported.Kinds) != 1 || supported.Kinds[0].Network != x402.NetworkBaseSepolia {
+		t.Fatalf("GetSupported() = %+v, %v", supported, err)
+	}
+	verify, err := client.Verify(ctx, payload, requirements)
+	if err != nil || !verify.IsValid || verify.Payer != facilitator.DefaultPayer {
+		t.Fatalf("Verify() = %+v, %v", verify, err)
+	}
+	settle, err := client.Settle(ctx, payload, requirements)
+	if err != nil || !settle.Success || settle.Transaction != facilitator.DefaultTransaction {
+		t.Fatalf("Settle() = %+v, %v", settle, err)
+	}
+	if got := mock.Settled(); len(got) != 1 || got[0].Requirements.Amount != "10000" {
+		t.Errorf("settled = %+v, want the submitted requirements", got)
+	}
+
+	mock.Update(func(o *facilitator.Options) {
+		o.InvalidReason = "insufficient_funds"
+		o.SettleStatus = http.StatusInternalServerError
+	})
+	if verify, err := client.Verify(ctx, payload, requirements); err != nil || verify.IsValid || verify.InvalidReason != "insufficient_funds" {
+		t.Errorf("Verify() = %+v, %v, want an invalid payload", verify, err)
+	}
+	if _, err := client.Settle(ctx, payload, requirements); err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("Settle() error = %v, want the server error", err)
+	}
+}
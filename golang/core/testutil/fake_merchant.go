@@ -0,0 +1,291 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package testutil provides in-process fakes for testing code built on the
+// x402 A2A client and merchant packages.
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	"github.com/google-agentic-commerce/a2a-x402/core/merchant"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402core "github.com/x402-foundation/x402/go"
+	x402evm "github.com/x402-foundation/x402/go/mechanisms/evm"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+const (
+	// FakeAsset is the Base Sepolia USDC address quoted by the fake merchant.
+	FakeAsset = "0x036CbD53842c5426634e7929541eC2318f3dCF7e"
+	// FakePayTo is the default address the fake merchant asks to be paid.
+	FakePayTo = "0x209693Bc6afc0C5328bA36FaF03C514EF312287C"
+	// FakeTransaction is the transaction hash reported for successful settlements.
+	FakeTransaction = "0x0000000000000000000000000000000000000000000000000000000000000402"
+)
+
+// FakeMerchantOptions configures NewFakeMerchant. Zero values select a merchant
+// that charges FakePrice on Base Sepolia and completes every paid request.
+type FakeMerchantOptions struct {
+	// BusinessService replaces the default fixed-price service.
+	BusinessService business.BusinessService
+	// Price is the price quoted by the default service, e.g. "$0.01".
+	Price string
+	// NetworkConfigs defaults to Base Sepolia paying FakePayTo.
+	NetworkConfigs []types.NetworkConfig
+
+	// QuoteError fails building payment requirements.
+	QuoteError error
+	// VerifyError fails verification as if the facilitator were unreachable.
+	VerifyError error
+	// InvalidReason rejects the payment during verification.
+	InvalidReason string
+	// SettleError fails settlement as if the facilitator were unreachable.
+	SettleError error
+	// SettleFailureReason reports an unsuccessful settlement.
+	SettleFailureReason string
+	// ExecuteError fails the paid execution of the default service.
+	ExecuteError error
+
+	// MerchantOptions are applied to the orchestrator, e.g. to install
+	// metrics or a tracer.
+	MerchantOptions []merchant.Option
+}
+
+// FakePrice is the price quoted when FakeMerchantOptions.Price is empty.
+const FakePrice = "$0.01"
+
+// FakeMerchant is an in-process merchant running the real orchestrator against
+// a scripted resource server.
+type FakeMerchant struct {
+	URL    string
+	Server *httptest.Server
+
+	resourceServer *fakeResourceServer
+}
+
+// NewFakeMerchant starts a fake merchant that is shut down when the test ends.
+func NewFakeMerchant(t testing.TB, opts FakeMerchantOptions) *FakeMerchant {
+	t.Helper()
+
+	if opts.Price == "" {
+		opts.Price = FakePrice
+	}
+	if len(opts.NetworkConfigs) == 0 {
+		opts.NetworkConfigs = []types.NetworkConfig{{NetworkName: x402.NetworkBaseSepolia, PayToAddress: FakePayTo}}
+	}
+	service := opts.BusinessService
+	if service == nil {
+		service = &fakeService{price: opts.Price, executeErr: opts.ExecuteError}
+	}
+
+	resourceServer := &fakeResourceServer{opts: opts}
+	orchestrator, err := merchant.NewBusinessOrchestrator(context.Background(), service, opts.NetworkConfigs,
+		append([]merchant.Option{merchant.WithPaymentServer(resourceServer)}, opts.MerchantOptions...)...)
+	if err != nil {
+		t.Fatalf("NewBusinessOrchestrator() error = %v", err)
+	}
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	card := &a2a.AgentCard{
+		Name:               "Fake Merchant",
+		Description:        "In-process x402 merchant for tests",
+		URL:                server.URL + "/rpc",
+		PreferredTransport: a2a.TransportProtocolJSONRPC,
+		DefaultInputModes:  []string{"text"},
+		DefaultOutputModes: []string{"text"},
+		Capabilities: a2a.AgentCapabilities{
+			Extensions: []a2a.AgentExtension{{URI: x402.X402ExtensionURI, Required: true}},
+		},
+		ProtocolVersion: "0.2",
+		Version:         "1.0.0",
+	}
+	mux.Handle(a2asrv.WellKnownAgentCardPath, a2asrv.NewStaticAgentCardHandler(card))
+	mux.Handle("/rpc", withRequestMeta(a2asrv.NewJSONRPCHandler(a2asrv.NewHandler(orchestrator))))
+
+	return &FakeMerchant{
+		URL:            server.URL,
+		Server:         server,
+		resourceServer: resourceServer,
+	}
+}
+
+// VerifyCalls returns how many payments the merchant has verified.
+func (m *FakeMerchant) VerifyCalls() int {
+	m.resourceServer.mu.Lock()
+	defer m.resourceServer.mu.Unlock()
+	return m.resourceServer.verifyCalls
+}
+
+// SettleCalls returns how many payments the merchant has settled.
+func (m *FakeMerchant) SettleCalls() int {
+	m.resourceServer.mu.Lock()
+	defer m.resourceServer.mu.Unlock()
+	return m.resourceServer.settleCalls
+}
+
+func withRequestMeta(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, _ := a2asrv.WithCallContext(r.Context(), a2asrv.NewRequestMeta(r.Header))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+type fakeService struct {
+	price      string
+	executeErr error
+}
+
+func (s *fakeService) Execute(ctx context.Context, request business.Request) (*business.Result, error) {
+	if !request.PaymentVerified {
+		return nil, business.NewPaymentRequiredError("Payment is required", business.ServiceRequirements{
+			Price:             s.price,
+			Resource:          "/fake",
+			Description:       "Fake paid resource",
+			MimeType:          "text/plain",
+			Scheme:            "exact",
+			MaxTimeoutSeconds: 60,
+		})
+	}
+	if s.executeErr != nil {
+		return nil, s.executeErr
+	}
+	return &business.Result{Message: "Fake result for: " + request.Prompt}, nil
+}
+
+type fakeResourceServer struct {
+	opts FakeMerchantOptions
+
+	mu          sync.Mutex
+	verifyCalls int
+	settleCalls int
+}
+
+func (s *fakeResourceServer) BuildPaymentRequirementsFromConfig(
+	ctx context.Context,
+	config x402core.ResourceConfig,
+) ([]x402types.PaymentRequirements, error) {
+	if s.opts.QuoteError != nil {
+		return nil, s.opts.QuoteError
+	}
+	if assetAmount, ok := config.Price.(map[string]interface{}); ok {
+		asset, _ := assetAmount["asset"].(string)
+		amount, _ := assetAmount["amount"].(string)
+		extra, _ := assetAmount["extra"].(map[string]interface{})
+		return []x402types.PaymentRequirements{{
+			Scheme:            config.Scheme,
+			Network:           string(config.Network),
+			Asset:             asset,
+			Amount:            amount,
+			PayTo:             config.PayTo,
+			MaxTimeoutSeconds: config.MaxTimeoutSeconds,
+			Extra:             extra,
+		}}, nil
+	}
+	price := strings.TrimPrefix(fmt.Sprint(config.Price), "$")
+	amount, err := x402evm.ParseAmount(price, 6)
+	if err != nil {
+		return nil, fmt.Errorf("invalid price %v: %w", config.Price, err)
+	}
+	return []x402types.PaymentRequirements{{
+		Scheme:            config.Scheme,
+		Network:           string(config.Network),
+		Asset:             FakeAsset,
+		Amount:            amount.String(),
+		PayTo:             config.PayTo,
+		MaxTimeoutSeconds: config.MaxTimeoutSeconds,
+		Extra:             map[string]interface{}{"name": "USDC", "version": "2"},
+	}}, nil
+}
+
+func (s *fakeResourceServer) FindMatchingRequirements(
+	accepts []x402types.PaymentRequirements,
+	payload x402types.PaymentPayload,
+) *x402types.PaymentRequirements {
+	for i := range accepts {
+		if accepts[i].Scheme == payload.Accepted.Scheme &&
+			accepts[i].Network == payload.Accepted.Network &&
+			accepts[i].Asset == payload.Accepted.Asset &&
+			accepts[i].Amount == payload.Accepted.Amount {
+			return &accepts[i]
+		}
+	}
+	return nil
+}
+
+func (s *fakeResourceServer) VerifyPayment(
+	ctx context.Context,
+	payload x402types.PaymentPayload,
+	requirements x402types.PaymentRequirements,
+) (*x402core.VerifyResponse, error) {
+	s.mu.Lock()
+	s.verifyCalls++
+	s.mu.Unlock()
+
+	if s.opts.VerifyError != nil {
+		return nil, s.opts.VerifyError
+	}
+	if s.opts.InvalidReason != "" {
+		return &x402core.VerifyResponse{IsValid: false, InvalidReason: s.opts.InvalidReason}, nil
+	}
+	return &x402core.VerifyResponse{IsValid: true, Payer: payerOf(payload)}, nil
+}
+
+func (s *fakeResourceServer) SettlePayment(
+	ctx context.Context,
+	payload x402types.PaymentPayload,
+	requirements x402types.PaymentRequirements,
+) (*x402core.SettleResponse, error) {
+	s.mu.Lock()
+	s.settleCalls++
+	s.mu.Unlock()
+
+	if s.opts.SettleError != nil {
+		return nil, s.opts.SettleError
+	}
+	if s.opts.SettleFailureReason != "" {
+		return &x402core.SettleResponse{
+			Success:     false,
+			ErrorReason: s.opts.SettleFailureReason,
+			Network:     x402core.Network(requirements.Network),
+			Payer:       payerOf(payload),
+		}, nil
+	}
+	return &x402core.SettleResponse{
+		Success:     true,
+		Payer:       payerOf(payload),
+		Transaction: FakeTransaction,
+		Network:     x402core.Network(requirements.Network),
+		Amount:      requirements.Amount,
+	}, nil
+}
+
+func payerOf(payload x402types.PaymentPayload) string {
+	authorization, _ := payload.Payload["authorization"].(map[string]interface{})
+	from, _ := authorization["from"].(string)
+	return from
+}
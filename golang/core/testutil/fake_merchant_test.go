@@ -0,0 +1,136 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/business"
+	"github.com/google-agentic-commerce/a2a-x402/core/client"
+	"github.com/google-agentic-commerce/a2a-x402/core/testutil"
+	"github.com/google-agentic-commerce/a2a-x402/core/types"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402/state"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+const testPrivateKey = "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+
+func newTestClient(t *testing.T, merchant *testutil.FakeMerchant) *client.Client {
+	t.Helper()
+	c, err := client.NewClient(
+		merchant.URL,
+		[]types.NetworkKeyPair{{NetworkName: x402.NetworkBaseSepolia, PrivateKey: testPrivateKey}},
+		client.WithPollInterval(10*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+func TestFakeMerchantCompletesPaidFlow(t *testing.T) {
+	merchant := testutil.NewFakeMerchant(t, testutil.FakeMerchantOptions{})
+	c := newTestClient(t, merchant)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	task, err := c.WaitForCompletion(ctx, "hello")
+	if err != nil {
+		t.Fatalf("WaitForCompletion() error = %v", err)
+	}
+	if task.Status.State != a2a.TaskStateCompleted {
+		t.Fatalf("task state = %s, want completed", task.Status.State)
+	}
+	receipts, err := state.ExtractPaymentReceipts(task)
+	if err != nil || len(receipts) != 1 || receipts[0].Transaction != testutil.FakeTransaction {
+		t.Fatalf("receipts = %#v, error = %v", receipts, err)
+	}
+	if merchant.VerifyCalls() != 1 || merchant.SettleCalls() != 1 {
+		t.Fatalf("verify calls = %d, settle calls = %d", merchant.VerifyCalls(), merchant.SettleCalls())
+	}
+}
+
+func TestFakeMerchantInjectsVerificationFailure(t *testing.T) {
+	merchant := testutil.NewFakeMerchant(t, testutil.FakeMerchantOptions{InvalidReason: "insufficient_funds"})
+	c := newTestClient(t, merchant)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	task, err := c.WaitForCompletion(ctx, "hello")
+	if err != nil {
+		t.Fatalf("WaitForCompletion() error = %v", err)
+	}
+	status, _ := state.ExtractPaymentStatusFromTask(task)
+	if task.Status.State != a2a.TaskStateFailed || status != state.PaymentFailed {
+		t.Fatalf("task state = %s, payment status = %s", task.Status.State, status)
+	}
+	if merchant.SettleCalls() != 0 {
+		t.Fatalf("settle calls = %d, want none after failed verification", merchant.SettleCalls())
+	}
+}
+
+// schemaService quotes with an output schema and returns a matching result.
+type schemaService struct {
+	schema map[string]interface{}
+}
+
+func (s schemaService) Execute(ctx context.Context, request business.Request) (*business.Result, error) {
+	if !request.PaymentVerified {
+		return nil, business.NewPaymentRequiredError("Payment is required", business.ServiceRequirements{
+			Price:        testutil.FakePrice,
+			Resource:     "/fake",
+			Scheme:       x402.SchemeExact,
+			OutputSchema: s.schema,
+		})
+	}
+	return &business.Result{Message: `{"caption":"a sunset"}`}, nil
+}
+
+func TestFakeMerchantQuotesOutputSchema(t *testing.T) {
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"caption": map[string]interface{}{"type": "string"}},
+		"required":   []interface{}{"caption"},
+	}
+	merchant := testutil.NewFakeMerchant(t, testutil.FakeMerchantOptions{BusinessService: schemaService{schema: schema}})
+	var quoted map[string]interface{}
+	c, err := client.NewClient(
+		merchant.URL,
+		[]types.NetworkKeyPair{{NetworkName: x402.NetworkBaseSepolia, PrivateKey: testPrivateKey}},
+		client.WithPollInterval(10*time.Millisecond),
+		client.WithHooks(client.Hooks{OnQuote: func(ctx context.Context, taskID a2a.TaskID, requirements *x402types.PaymentRequired) {
+			quoted = client.OutputSchema(requirements)
+		}}),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := c.WaitForCompletion(ctx, "hello"); err != nil {
+		t.Fatalf("WaitForCompletion() error = %v", err)
+	}
+	if !reflect.DeepEqual(quoted, schema) {
+		t.Errorf("quoted output schema = %#v, want %#v", quoted, schema)
+	}
+}
@@ -3,9 +3,75 @@ package types
 type NetworkConfig struct {
 	NetworkName  string
 	PayToAddress string
+	// Assets lists the tokens accepted on this network. When empty, the
+	// network's default asset is offered.
+	Assets []AssetConfig
+	// AllowedAssets, when set, lists the only asset addresses a quote on this
+	// network may name, whatever the SDK or facilitator defaults pick.
+	AllowedAssets []string
+	// Splits divides every payment on this network between recipients.
+	// Payments still settle to PayToAddress, which owes each recipient its
+	// share. When set, BasisPoints must sum to 10000.
+	Splits []SplitRecipient
+	// ResourcePayTo routes payments for particular resources to their own
+	// address, keyed by the ServiceRequirements.Resource the service quotes.
+	// Resources without an entry are paid to PayToAddress.
+	ResourcePayTo map[string]string
+}
+
+// SplitRecipient is one party's share of a split payment, in basis points
+// (hundredths of a percent).
+type SplitRecipient struct {
+	Address     string
+	BasisPoints int
+}
+
+// AssetConfig describes one token accepted on a network.
+type AssetConfig struct {
+	Address  string
+	Decimals int
+	// Price overrides the service price for this asset, in whole tokens
+	// (e.g. "0.99"). Empty uses the price from the service requirements.
+	Price string
+	// Name and Version are the token's EIP-712 domain, needed to sign
+	// EIP-3009 authorizations for tokens the SDK does not know.
+	Name    string
+	Version string
 }
 
 type NetworkKeyPair struct {
 	NetworkName string
 	PrivateKey  string
 }
+
+// Capabilities is what a merchant publishes at x402.CapabilitiesPath: the
+// networks, schemes and assets it quotes in, so integrators can tell whether
+// they can pay before starting a task.
+type Capabilities struct {
+	X402Version  int    `json:"x402Version"`
+	ExtensionURI string `json:"extensionUri"`
+	// Facilitator is the facilitator URL, when the merchant settles through
+	// one over HTTP.
+	Facilitator string `json:"facilitator,omitempty"`
+	// Sandbox reports that payments are accepted without moving money.
+	Sandbox  bool                `json:"sandbox,omitempty"`
+	Networks []NetworkCapability `json:"networks"`
+}
+
+// NetworkCapability lists what a merchant accepts on one network.
+type NetworkCapability struct {
+	Network string            `json:"network"`
+	PayTo   string            `json:"payTo"`
+	Schemes []string          `json:"schemes"`
+	Assets  []AssetCapability `json:"assets"`
+}
+
+// AssetCapability is one token a merchant quotes in.
+type AssetCapability struct {
+	Address  string `json:"address"`
+	Decimals int    `json:"decimals"`
+	Name     string `json:"name,omitempty"`
+	// Price is the asset's fixed price in whole tokens, when it overrides the
+	// service price.
+	Price string `json:"price,omitempty"`
+}
@@ -0,0 +1,173 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x402
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	evm "github.com/x402-foundation/x402/go/mechanisms/evm"
+	svm "github.com/x402-foundation/x402/go/mechanisms/svm"
+)
+
+// AssetInfo describes a token accepted on a network. Symbol is the ticker
+// shown to users, such as "USDC", and is empty when unknown.
+type AssetInfo struct {
+	Network  string
+	Address  string
+	Decimals int
+	Symbol   string
+}
+
+// evmDefaultSymbols are the symbols of the EVM networks' default assets,
+// whose configs carry only the token's EIP-712 name.
+var evmDefaultSymbols = map[string]string{
+	NetworkBase:        "USDC",
+	NetworkBaseSepolia: "USDC",
+}
+
+var assetRegistry = struct {
+	sync.RWMutex
+	assets map[string]AssetInfo
+}{assets: make(map[string]AssetInfo)}
+
+// RegisterAsset makes an asset known to LookupAsset and ToAtomicAmount. A
+// later registration of the same address on the same network replaces it.
+func RegisterAsset(info AssetInfo) error {
+	info.Network = NormalizeNetwork(info.Network)
+	info.Address = strings.TrimSpace(info.Address)
+	if info.Network == "" || info.Address == "" {
+		return fmt.Errorf("asset network and address are required")
+	}
+	if info.Decimals < 0 {
+		return fmt.Errorf("asset %s decimals must not be negative, got %d", info.Address, info.Decimals)
+	}
+	assetRegistry.Lock()
+	defer assetRegistry.Unlock()
+	assetRegistry.assets[assetKey(info.Network, info.Address)] = info
+	return nil
+}
+
+// LookupAsset returns a registered asset, or the network's default stablecoin
+// when asset is empty or its address. Tokens that are neither are unknown,
+// since guessing their decimals would misprice them.
+func LookupAsset(network, asset string) (AssetInfo, bool) {
+	network = NormalizeNetwork(network)
+	asset = strings.TrimSpace(asset)
+	if asset != "" {
+		assetRegistry.RLock()
+		info, ok := assetRegistry.assets[assetKey(network, asset)]
+		assetRegistry.RUnlock()
+		if ok {
+			return info, true
+		}
+	}
+
+	info, ok := defaultAsset(network)
+	if !ok || (asset != "" && assetKey(network, asset) != assetKey(network, info.Address)) {
+		return AssetInfo{}, false
+	}
+	return info, true
+}
+
+func defaultAsset(network string) (AssetInfo, bool) {
+	switch {
+	case IsEVMNetwork(network):
+		config, err := evm.GetNetworkConfig(network)
+		if err != nil || config.DefaultAsset.Address == "" {
+			return AssetInfo{}, false
+		}
+		return AssetInfo{
+			Network:  network,
+			Address:  config.DefaultAsset.Address,
+			Decimals: config.DefaultAsset.Decimals,
+			Symbol:   evmDefaultSymbols[network],
+		}, true
+	case IsSolanaNetwork(network):
+		config, err := svm.GetNetworkConfig(network)
+		if err != nil || config.DefaultAsset.Address == "" {
+			return AssetInfo{}, false
+		}
+		return AssetInfo{
+			Network:  network,
+			Address:  config.DefaultAsset.Address,
+			Decimals: config.DefaultAsset.Decimals,
+			Symbol:   config.DefaultAsset.Symbol,
+		}, true
+	}
+	return AssetInfo{}, false
+}
+
+// assetKey compares EVM addresses case-insensitively; Solana mints are
+// case-sensitive.
+func assetKey(network, address string) string {
+	if IsEVMNetwork(network) {
+		address = strings.ToLower(address)
+	}
+	return network + "|" + address
+}
+
+// ToAtomicAmount converts a whole-token price such as "1.5" into the asset's
+// atomic units, "1500000" for a 6-decimal stablecoin. asset is an address,
+// or empty for the network's default stablecoin. Prices are never rounded: a
+// price more precise than the asset's decimals is an error.
+func ToAtomicAmount(price string, network, asset string) (string, error) {
+	info, ok := LookupAsset(network, asset)
+	if !ok {
+		if asset == "" {
+			return "", fmt.Errorf("no default asset known for network %s", network)
+		}
+		return "", fmt.Errorf("unknown asset %s on network %s: register its decimals with RegisterAsset", asset, network)
+	}
+	return ParseAtomicAmount(price, info.Decimals)
+}
+
+// ParseAtomicAmount converts a whole-token price into atomic units of an asset
+// with the given decimals. A leading "$" is ignored and trailing zeros past
+// the asset's precision are accepted; any other extra precision, negative
+// amounts and exponents are rejected.
+func ParseAtomicAmount(price string, decimals int) (string, error) {
+	if decimals < 0 {
+		return "", fmt.Errorf("decimals must not be negative, got %d", decimals)
+	}
+	value := strings.TrimPrefix(strings.TrimSpace(price), "$")
+	whole, fraction, hasPoint := strings.Cut(value, ".")
+	if whole == "" && fraction == "" || !isDigits(whole) || !isDigits(fraction) || hasPoint && fraction == "" {
+		return "", fmt.Errorf("invalid price %q: want a non-negative decimal such as \"1.50\"", price)
+	}
+	if trimmed := strings.TrimRight(fraction, "0"); len(trimmed) > decimals {
+		return "", fmt.Errorf("price %q has %d decimal places but the asset supports %d", price, len(trimmed), decimals)
+	}
+	if len(fraction) > decimals {
+		fraction = fraction[:decimals]
+	}
+	digits := whole + fraction + strings.Repeat("0", decimals-len(fraction))
+	amount, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return "", fmt.Errorf("invalid price %q", price)
+	}
+	return amount.String(), nil
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,132 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x402
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseAtomicAmount(t *testing.T) {
+	tests := []struct {
+		price    string
+		decimals int
+		want     string
+		wantErr  string
+	}{
+		{price: "1.5", decimals: 6, want: "1500000"},
+		{price: "$1.50", decimals: 6, want: "1500000"},
+		{price: " 0.01 ", decimals: 6, want: "10000"},
+		{price: "0.000001", decimals: 6, want: "1"},
+		{price: "1", decimals: 6, want: "1000000"},
+		{price: ".5", decimals: 6, want: "500000"},
+		{price: "0", decimals: 6, want: "0"},
+		{price: "007.25", decimals: 2, want: "725"},
+		{price: "12", decimals: 0, want: "12"},
+		{price: "12.000", decimals: 0, want: "12"},
+		{price: "1.500000000", decimals: 6, want: "1500000"},
+		{price: "0.000001000", decimals: 6, want: "1"},
+		{price: "1", decimals: 18, want: "1000000000000000000"},
+		{price: "0.000000000000000001", decimals: 18, want: "1"},
+		{price: "1.000000000000000001", decimals: 18, want: "1000000000000000001"},
+		{price: "123456789.123456789123456789", decimals: 18, want: "123456789123456789123456789"},
+		{price: "99999999999999999999.999999999999999999", decimals: 18, want: "99999999999999999999999999999999999999"},
+		// 0.1 + 0.2 style values stay exact; no float rounding is involved.
+		{price: "0.3", decimals: 18, want: "300000000000000000"},
+		{price: "0.0000001", decimals: 6, wantErr: "has 7 decimal places but the asset supports 6"},
+		{price: "1.1234565", decimals: 6, wantErr: "has 7 decimal places"},
+		{price: "0.9999999", decimals: 6, wantErr: "has 7 decimal places"},
+		{price: "0.0000000000000000001", decimals: 18, wantErr: "has 19 decimal places but the asset supports 18"},
+		{price: "1.5", decimals: 0, wantErr: "has 1 decimal places but the asset supports 0"},
+		{price: "", decimals: 6, wantErr: "invalid price"},
+		{price: "$", decimals: 6, wantErr: "invalid price"},
+		{price: ".", decimals: 6, wantErr: "invalid price"},
+		{price: "1.", decimals: 6, wantErr: "invalid price"},
+		{price: "-1", decimals: 6, wantErr: "invalid price"},
+		{price: "+1", decimals: 6, wantErr: "invalid price"},
+		{price: "1e6", decimals: 6, wantErr: "invalid price"},
+		{price: "1,5", decimals: 6, wantErr: "invalid price"},
+		{price: "1.2.3", decimals: 6, wantErr: "invalid price"},
+		{price: "USD 1", decimals: 6, wantErr: "invalid price"},
+		{price: "1", decimals: -1, wantErr: "decimals must not be negative"},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseAtomicAmount(tt.price, tt.decimals)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseAtomicAmount(%q, %d) = %q, %v, want error containing %q", tt.price, tt.decimals, got, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseAtomicAmount(%q, %d) = %q, %v, want %q", tt.price, tt.decimals, got, err, tt.want)
+		}
+	}
+}
+
+func TestToAtomicAmount(t *testing.T) {
+	baseUSDC, ok := LookupAsset(NetworkBase, "")
+	if !ok || baseUSDC.Decimals != 6 || baseUSDC.Symbol != "USDC" {
+		t.Fatalf("LookupAsset(base) = %+v, %v, want the 6-decimal default stablecoin", baseUSDC, ok)
+	}
+	const weth = "0x4200000000000000000000000000000000000006"
+	if err := RegisterAsset(AssetInfo{Network: "base", Address: weth, Decimals: 18}); err != nil {
+		t.Fatalf("RegisterAsset() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		price   string
+		network string
+		asset   string
+		want    string
+		wantErr string
+	}{
+		{name: "default asset", price: "1.5", network: NetworkBase, want: "1500000"},
+		{name: "default asset by address", price: "2", network: NetworkBase, asset: strings.ToLower(baseUSDC.Address), want: "2000000"},
+		{name: "legacy network name", price: "0.25", network: "base", want: "250000"},
+		{name: "solana default asset", price: "0.5", network: NetworkSolanaDevnet, want: "500000"},
+		{name: "registered 18-decimal asset", price: "0.000000000000000001", network: NetworkBase, asset: weth, want: "1"},
+		{name: "registered asset is per network", price: "1", network: NetworkBaseSepolia, asset: weth, wantErr: "unknown asset"},
+		{name: "too precise for default asset", price: "0.0000001", network: NetworkBase, wantErr: "has 7 decimal places"},
+		{name: "unknown asset", price: "1", network: NetworkBase, asset: "0x1111111111111111111111111111111111111111", wantErr: "unknown asset"},
+		{name: "unknown network", price: "1", network: "eip155:999999", wantErr: "no default asset"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToAtomicAmount(tt.price, tt.network, tt.asset)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ToAtomicAmount() = %q, %v, want error containing %q", got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("ToAtomicAmount() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterAsset_Validation(t *testing.T) {
+	if err := RegisterAsset(AssetInfo{Network: NetworkBase}); err == nil {
+		t.Error("RegisterAsset() without address error = nil")
+	}
+	if err := RegisterAsset(AssetInfo{Network: NetworkBase, Address: "0xabc", Decimals: -1}); err == nil {
+		t.Error("RegisterAsset() with negative decimals error = nil")
+	}
+}
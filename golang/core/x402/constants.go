@@ -21,8 +21,43 @@ import (
 const (
 	X402ExtensionURI = "https://github.com/google-agentic-commerce/a2a-x402/blob/main/spec/v0.2"
 	X402Version      = 2
+	// A2AProtocolVersion is the A2A protocol version implemented by the
+	// a2a-go release this module builds against.
+	A2AProtocolVersion = "0.3.0"
 )
 
+const (
+	SchemeExact = "exact"
+	// SchemeUpto authorizes a maximum and settles the amount actually used.
+	SchemeUpto = "upto"
+)
+
+// ExtraKeyTaskBinding names the requirement Extra entry that binds a quote to
+// the task it was issued for. Clients echo it back in the payload's accepted
+// block.
+const ExtraKeyTaskBinding = "taskBinding"
+
+// ExtraKeyOutputSchema names the requirement Extra entry describing the shape
+// of the result being paid for.
+const ExtraKeyOutputSchema = "outputSchema"
+
+// ExtraKeyTip names the receipt Extra entry recording an accepted
+// overpayment: the quoted amount, the amount paid and the difference.
+const ExtraKeyTip = "tip"
+
+// ExtraKeyMetering names the receipt Extra entry that explains a metered
+// settlement: the amount measured, the ceiling authorized, the amount settled
+// and what the measurement was based on.
+const ExtraKeyMetering = "metering"
+
+// ExtensionParamSkillPricing names the x402 extension param on an agent card
+// that lists indicative prices per skill, keyed by skill ID.
+const ExtensionParamSkillPricing = "skillPricing"
+
+// CapabilitiesPath is where a merchant publishes the networks and assets it
+// accepts.
+const CapabilitiesPath = "/.well-known/x402.json"
+
 const (
 	NetworkBase          = "eip155:8453"
 	NetworkBaseSepolia   = "eip155:84532"
@@ -31,21 +66,131 @@ const (
 	NetworkSolanaTestnet = svm.SolanaTestnetCAIP2
 )
 
+// MetadataKeyPrefix starts every metadata key the x402 extension defines.
+const MetadataKeyPrefix = "x402."
+
 const (
-	MetadataKeyStatus         = "x402.payment.status"
-	MetadataKeyRequired       = "x402.payment.required"
-	MetadataKeyPayload        = "x402.payment.payload"
-	MetadataKeyReceipts       = "x402.payment.receipts"
-	MetadataKeyError          = "x402.payment.error"
-	MetadataKeyOriginalPrompt = "x402.payment.original_prompt"
+	MetadataKeyStatus            = "x402.payment.status"
+	MetadataKeyRequired          = "x402.payment.required"
+	MetadataKeyIssued            = "x402.payment.required.issued"
+	MetadataKeyQuotedAt          = "x402.payment.required.quoted_at"
+	MetadataKeyPayTo             = "x402.payment.required.pay_to"
+	MetadataKeyPayload           = "x402.payment.payload"
+	MetadataKeyReceipts          = "x402.payment.receipts"
+	MetadataKeyTransactions      = "x402.payment.receipts.tx"
+	MetadataKeySignedReceipts    = "x402.payment.receipts.signed"
+	MetadataKeyRefunds           = "x402.payment.refunds"
+	MetadataKeyError             = "x402.payment.error"
+	MetadataKeyOriginalPrompt    = "x402.payment.original_prompt"
+	MetadataKeyPromptDigest      = "x402.payment.original_prompt.sha256"
+	MetadataKeyPromptLength      = "x402.payment.original_prompt.length"
+	MetadataKeyPayloadHash       = "x402.payment.payload_hash"
+	MetadataKeySkillID           = "x402.payment.skill_id"
+	MetadataKeyRequestMetadata   = "x402.payment.request_metadata"
+	MetadataKeyRound             = "x402.payment.round"
+	MetadataKeyRequotes          = "x402.payment.requotes"
+	MetadataKeyOptionRetries     = "x402.payment.option_retries"
+	MetadataKeyPayloadRetries    = "x402.payment.payload_retries"
+	MetadataKeyFailedAttempts    = "x402.payment.failed_attempts"
+	MetadataKeyVoided            = "x402.payment.voided"
+	MetadataKeyIndeterminate     = "x402.payment.indeterminate"
+	MetadataKeyDiscounts         = "x402.payment.discounts"
+	MetadataKeyCompensation      = "x402.payment.compensation"
+	MetadataKeySubscription      = "x402.payment.subscription"
+	MetadataKeySubscriptionClaim = "x402.subscription.claim"
+	MetadataKeyTrustCredential   = "x402.trust.credential"
+	MetadataKeyTrustedSubject    = "x402.payment.trusted_subject"
+	MetadataKeyAckDeadline       = "x402.payment.ack_deadline"
+	MetadataKeyDeliveryAck       = "x402.delivery.ack"
+	MetadataKeyProgress          = "x402.progress"
 )
 
+// Error codes recorded under MetadataKeyError when a payment or task fails.
+// Retryable reports which of them a client can recover from by paying again.
 const (
+	// ErrorCodeInsufficientFunds means the payer could not cover the amount.
 	ErrorCodeInsufficientFunds = "INSUFFICIENT_FUNDS"
-	ErrorCodeInvalidSignature  = "INVALID_SIGNATURE"
-	ErrorCodeExpiredPayment    = "EXPIRED_PAYMENT"
-	ErrorCodeDuplicateNonce    = "DUPLICATE_NONCE"
-	ErrorCodeNetworkMismatch   = "NETWORK_MISMATCH"
-	ErrorCodeInvalidAmount     = "INVALID_AMOUNT"
-	ErrorCodeSettlementFailed  = "SETTLEMENT_FAILED"
+	// ErrorCodeInvalidSignature means the payload failed verification.
+	ErrorCodeInvalidSignature = "INVALID_SIGNATURE"
+	// ErrorCodeExpiredPayment means the authorization window had lapsed.
+	ErrorCodeExpiredPayment = "EXPIRED_PAYMENT"
+	// ErrorCodeDuplicateNonce means the authorization nonce was already used.
+	ErrorCodeDuplicateNonce = "DUPLICATE_NONCE"
+	// ErrorCodeNetworkMismatch means the payload targets an unoffered network.
+	ErrorCodeNetworkMismatch = "NETWORK_MISMATCH"
+	// ErrorCodeInvalidAmount means the amount to settle was not acceptable.
+	ErrorCodeInvalidAmount = "INVALID_AMOUNT"
+	// ErrorCodePayloadMismatch means the payload matches none of the quoted
+	// requirements.
+	ErrorCodePayloadMismatch = "PAYLOAD_REQUIREMENT_MISMATCH"
+	// ErrorCodeUnsupportedOption means the payload names a network or option
+	// that was not quoted; the client may pay again with an offered one.
+	ErrorCodeUnsupportedOption = "UNSUPPORTED_NETWORK_OR_OPTION"
+	// ErrorCodeInvalidPayload means the submitted payment metadata could not
+	// be decoded.
+	ErrorCodeInvalidPayload = "INVALID_PAYLOAD"
+	// ErrorCodeMalformedPayload means the submitted payment payload could not
+	// be decoded and the quote was kept open; the client may resend it.
+	ErrorCodeMalformedPayload = "MALFORMED_PAYMENT_PAYLOAD"
+	// ErrorCodeOrchestratorStuck means the merchant's state machine stopped
+	// making progress.
+	ErrorCodeOrchestratorStuck = "ORCHESTRATOR_STUCK"
+	// ErrorCodeSettlementFailed means the facilitator refused the settlement.
+	ErrorCodeSettlementFailed = "SETTLEMENT_FAILED"
+	// ErrorCodeVerifyTimeout means verification did not answer in time.
+	ErrorCodeVerifyTimeout = "VERIFY_TIMEOUT"
+	// ErrorCodeSettleTimeout means settlement did not answer in time; the
+	// payment may still land on chain.
+	ErrorCodeSettleTimeout = "SETTLE_TIMEOUT"
+	// ErrorCodePayerNotAllowed means merchant policy rejected the payer.
+	ErrorCodePayerNotAllowed = "PAYER_NOT_ALLOWED"
+	// ErrorCodeAuthorizationVoided means the task was canceled after
+	// verification and the authorization was never settled.
+	ErrorCodeAuthorizationVoided = "AUTHORIZATION_VOIDED"
+	// ErrorCodeQuoteExpiredRequote means the quote expired and a fresh one
+	// was issued on the same task.
+	ErrorCodeQuoteExpiredRequote = "QUOTE_EXPIRED_REQUOTE"
+	// ErrorCodeQuoteExpired means the quote went unpaid past its lifetime
+	// and the task was canceled; the client may start a new request.
+	ErrorCodeQuoteExpired = "QUOTE_EXPIRED"
+	// ErrorCodeBusinessExecutionFailed means the merchant's service failed
+	// to produce a result.
+	ErrorCodeBusinessExecutionFailed = "BUSINESS_EXECUTION_FAILED"
+	// ErrorCodeBusinessTimeout means the merchant's service did not finish
+	// within the payment window.
+	ErrorCodeBusinessTimeout = "BUSINESS_TIMEOUT"
+	// ErrorCodeMerchantBusy means the merchant had no capacity to take the
+	// job; nothing was settled and the client may try again later.
+	ErrorCodeMerchantBusy = "MERCHANT_BUSY"
+	// ErrorCodeCapacityExceeded means the paid work waited too long for an
+	// execution slot; nothing was settled and the client may try again
+	// later.
+	ErrorCodeCapacityExceeded = "CAPACITY_EXCEEDED"
+	// ErrorCodeExtensionRequired means the request did not activate the x402
+	// extension.
+	ErrorCodeExtensionRequired = "EXTENSION_REQUIRED"
+	// ErrorCodeInvalidRequest means the request could not be routed to a
+	// skill.
+	ErrorCodeInvalidRequest = "INVALID_REQUEST"
+	// ErrorCodeTooManyPaymentAttempts means the task refused further payment
+	// submissions after too many failed ones.
+	ErrorCodeTooManyPaymentAttempts = "TOO_MANY_PAYMENT_ATTEMPTS"
+	// ErrorCodeTamperedRequirements means the payment was matched to a
+	// requirement the merchant never quoted.
+	ErrorCodeTamperedRequirements = "TAMPERED_REQUIREMENTS"
+	// ErrorCodeTaskBindingMismatch means the payment was made against a
+	// quote issued for another task.
+	ErrorCodeTaskBindingMismatch = "TASK_BINDING_MISMATCH"
+	// ErrorCodeConfirmationTimeout means the settlement did not reach the
+	// required block confirmations in time; it may still confirm later.
+	ErrorCodeConfirmationTimeout = "CONFIRMATION_TIMEOUT"
+	// ErrorCodeAmountMismatch means the payload paid a different amount than
+	// the exact quote and the merchant's overpayment policy refused it.
+	ErrorCodeAmountMismatch = "AMOUNT_MISMATCH"
+	// ErrorCodeMeteredAmountExceeded means the merchant measured more usage
+	// than the client authorized; nothing was settled.
+	ErrorCodeMeteredAmountExceeded = "METERED_AMOUNT_EXCEEDED"
+	// ErrorCodeInternal means the merchant failed for a reason unrelated to
+	// the payment.
+	ErrorCodeInternal = "INTERNAL_ERROR"
 )
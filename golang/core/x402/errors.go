@@ -0,0 +1,77 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x402
+
+import "sort"
+
+// errorCodes maps every registered error code to whether a client can recover
+// by submitting a fresh payment.
+var errorCodes = map[string]bool{
+	ErrorCodeInsufficientFunds:       false,
+	ErrorCodeInvalidSignature:        false,
+	ErrorCodeExpiredPayment:          true,
+	ErrorCodeDuplicateNonce:          true,
+	ErrorCodeNetworkMismatch:         false,
+	ErrorCodeInvalidAmount:           false,
+	ErrorCodePayloadMismatch:         false,
+	ErrorCodeTamperedRequirements:    false,
+	ErrorCodeTaskBindingMismatch:     false,
+	ErrorCodeUnsupportedOption:       true,
+	ErrorCodeInvalidPayload:          false,
+	ErrorCodeMalformedPayload:        true,
+	ErrorCodeOrchestratorStuck:       true,
+	ErrorCodeSettlementFailed:        true,
+	ErrorCodeVerifyTimeout:           true,
+	ErrorCodeSettleTimeout:           false,
+	ErrorCodeConfirmationTimeout:     false,
+	ErrorCodePayerNotAllowed:         false,
+	ErrorCodeAuthorizationVoided:     false,
+	ErrorCodeQuoteExpiredRequote:     true,
+	ErrorCodeQuoteExpired:            true,
+	ErrorCodeBusinessExecutionFailed: false,
+	ErrorCodeBusinessTimeout:         false,
+	ErrorCodeMerchantBusy:            true,
+	ErrorCodeCapacityExceeded:        true,
+	ErrorCodeExtensionRequired:       false,
+	ErrorCodeInvalidRequest:          false,
+	ErrorCodeTooManyPaymentAttempts:  false,
+	ErrorCodeMeteredAmountExceeded:   false,
+	ErrorCodeAmountMismatch:          false,
+	ErrorCodeInternal:                true,
+}
+
+// ErrorCodes returns every registered error code in sorted order.
+func ErrorCodes() []string {
+	codes := make([]string, 0, len(errorCodes))
+	for code := range errorCodes {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// IsErrorCode reports whether code is a registered error code.
+func IsErrorCode(code string) bool {
+	_, ok := errorCodes[code]
+	return ok
+}
+
+// Retryable reports whether a failure with code may succeed if the client pays
+// again against a fresh quote. A settlement timeout is not retryable because
+// the original payment may still land, and neither is a business failure,
+// which can follow a completed settlement.
+func Retryable(code string) bool {
+	return errorCodes[code]
+}
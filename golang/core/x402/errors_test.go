@@ -0,0 +1,78 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x402
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestErrorCodesAreRegistered(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), "constants.go", nil, 0)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	declared := 0
+	ast.Inspect(file, func(node ast.Node) bool {
+		spec, ok := node.(*ast.ValueSpec)
+		if !ok {
+			return true
+		}
+		for i, name := range spec.Names {
+			if !strings.HasPrefix(name.Name, "ErrorCode") {
+				continue
+			}
+			literal, ok := spec.Values[i].(*ast.BasicLit)
+			if !ok {
+				t.Fatalf("%s is not a string literal", name.Name)
+			}
+			code, _ := strconv.Unquote(literal.Value)
+			declared++
+			if !IsErrorCode(code) {
+				t.Errorf("%s = %q is not registered", name.Name, code)
+			}
+		}
+		return true
+	})
+	if declared != len(ErrorCodes()) {
+		t.Errorf("declared %d error codes, registered %d", declared, len(ErrorCodes()))
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{ErrorCodeExpiredPayment, true},
+		{ErrorCodeVerifyTimeout, true},
+		{ErrorCodeQuoteExpiredRequote, true},
+		{ErrorCodeSettleTimeout, false},
+		{ErrorCodeInsufficientFunds, false},
+		{ErrorCodePayerNotAllowed, false},
+		{ErrorCodeBusinessExecutionFailed, false},
+		{"", false},
+		{"UNKNOWN", false},
+	}
+	for _, tt := range tests {
+		if got := Retryable(tt.code); got != tt.want {
+			t.Errorf("Retryable(%q) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
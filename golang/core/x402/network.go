@@ -0,0 +1,53 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x402
+
+import (
+	"strings"
+
+	svm "github.com/x402-foundation/x402/go/mechanisms/svm"
+)
+
+// networkAliases maps legacy (x402 v1) network names to CAIP-2 identifiers.
+var networkAliases = map[string]string{
+	"base":         NetworkBase,
+	"base-sepolia": NetworkBaseSepolia,
+}
+
+func init() {
+	for name, caip2 := range svm.V1ToV2NetworkMap {
+		networkAliases[name] = caip2
+	}
+}
+
+// NormalizeNetwork returns the CAIP-2 identifier for a legacy network name
+// such as "base-sepolia". Other values are returned trimmed but unchanged.
+func NormalizeNetwork(network string) string {
+	network = strings.TrimSpace(network)
+	if caip2, ok := networkAliases[strings.ToLower(network)]; ok {
+		return caip2
+	}
+	return network
+}
+
+// IsEVMNetwork reports whether network is a CAIP-2 eip155 chain.
+func IsEVMNetwork(network string) bool {
+	return strings.HasPrefix(network, "eip155:")
+}
+
+// IsSolanaNetwork reports whether network is a CAIP-2 Solana cluster.
+func IsSolanaNetwork(network string) bool {
+	return strings.HasPrefix(network, "solana:")
+}
@@ -0,0 +1,37 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x402
+
+import "testing"
+
+func TestNormalizeNetwork(t *testing.T) {
+	tests := []struct {
+		network string
+		want    string
+	}{
+		{network: "base", want: NetworkBase},
+		{network: "Base-Sepolia", want: NetworkBaseSepolia},
+		{network: " eip155:84532 ", want: NetworkBaseSepolia},
+		{network: "solana-devnet", want: NetworkSolanaDevnet},
+		{network: "eip155:10", want: "eip155:10"},
+		{network: "base-seplia", want: "base-seplia"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeNetwork(tt.network); got != tt.want {
+			t.Errorf("NormalizeNetwork(%q) = %q, want %q", tt.network, got, tt.want)
+		}
+	}
+}
@@ -45,3 +45,35 @@ func EncodePaymentSubmission(
 
 	return message, nil
 }
+
+func EncodePaymentRejection(taskID a2a.TaskID, reason string) *a2a.Message {
+	if reason == "" {
+		reason = "Payment requirements rejected"
+	}
+	message := a2a.NewMessageForTask(
+		a2a.MessageRoleUser,
+		a2a.TaskInfo{TaskID: taskID},
+		a2a.TextPart{Text: reason},
+	)
+	message.Metadata = map[string]interface{}{
+		x402.MetadataKeyStatus: PaymentRejected.String(),
+	}
+	return message
+}
+
+// EncodeDeliveryAck answers a delivery the merchant holds for acknowledgement.
+// DeliveryAccepted releases the payment; DeliveryDisputed voids it.
+func EncodeDeliveryAck(taskID a2a.TaskID, ack string, text string) *a2a.Message {
+	if text == "" {
+		text = "Delivery " + ack
+	}
+	message := a2a.NewMessageForTask(
+		a2a.MessageRoleUser,
+		a2a.TaskInfo{TaskID: taskID},
+		a2a.TextPart{Text: text},
+	)
+	message.Metadata = map[string]interface{}{
+		x402.MetadataKeyDeliveryAck: ack,
+	}
+	return message
+}
@@ -0,0 +1,60 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+)
+
+// SetAckDeadline records when a delivery held for acknowledgement settles
+// without one.
+func SetAckDeadline(msg *a2a.Message, deadline time.Time) {
+	if msg.Metadata == nil {
+		msg.Metadata = make(map[string]interface{})
+	}
+	msg.Metadata[x402.MetadataKeyAckDeadline] = deadline.Unix()
+}
+
+// ExtractAckDeadline returns when the task's held delivery settles without an
+// acknowledgement, and false when none is held.
+func ExtractAckDeadline(task *a2a.Task) (time.Time, bool) {
+	if task == nil || task.Status.Message == nil {
+		return time.Time{}, false
+	}
+	switch deadline := task.Status.Message.Meta()[x402.MetadataKeyAckDeadline].(type) {
+	case int64:
+		return time.Unix(deadline, 0), true
+	case float64:
+		return time.Unix(int64(deadline), 0), true
+	}
+	return time.Time{}, false
+}
+
+// ExtractDeliveryAck returns the client's answer to a held delivery,
+// DeliveryAccepted or DeliveryDisputed, or empty when the message carries
+// neither.
+func ExtractDeliveryAck(msg *a2a.Message) string {
+	if msg == nil {
+		return ""
+	}
+	switch ack, _ := msg.Meta()[x402.MetadataKeyDeliveryAck].(string); ack {
+	case DeliveryAccepted, DeliveryDisputed:
+		return ack
+	}
+	return ""
+}
@@ -15,7 +15,9 @@
 package state
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/a2aproject/a2a-go/a2a"
 	"github.com/google-agentic-commerce/a2a-x402/core/utils"
@@ -35,7 +37,9 @@ func ExtractPaymentState(task *a2a.Task, message *a2a.Message) (*PaymentState, e
 	if err != nil {
 		return nil, fmt.Errorf("failed to extract message payment status: %w", err)
 	}
-	if messageStatus == PaymentSubmitted {
+	// A client may decline only an open quote; once a payment is under way
+	// the stored status stands.
+	if messageStatus == PaymentSubmitted || (messageStatus == PaymentRejected && status == PaymentRequired) {
 		status = messageStatus
 	}
 	paymentState.Status = status
@@ -220,6 +224,125 @@ func ExtractOriginalPrompt(task *a2a.Task) string {
 	return ""
 }
 
+func ExtractPaymentPayloadHash(task *a2a.Task) string {
+	if task == nil || task.Status.Message == nil {
+		return ""
+	}
+	if hash, ok := task.Status.Message.Meta()[x402.MetadataKeyPayloadHash].(string); ok {
+		return hash
+	}
+	return ""
+}
+
+// ExtractOriginalPromptDigest returns the salted prompt digest and prompt
+// length recorded when the prompt text is not retained.
+func ExtractOriginalPromptDigest(task *a2a.Task) (string, int) {
+	if task == nil || task.Status.Message == nil {
+		return "", 0
+	}
+	digest, _ := task.Status.Message.Meta()[x402.MetadataKeyPromptDigest].(string)
+	return digest, extractCount(task, x402.MetadataKeyPromptLength)
+}
+
+// ExtractPaymentRound returns the task's current payment round, 1 when none
+// is recorded.
+func ExtractPaymentRound(task *a2a.Task) int {
+	return max(extractCount(task, x402.MetadataKeyRound), 1)
+}
+
+// ExtractRequoteCount returns how many times the task's quote has been
+// reissued after expiring.
+func ExtractRequoteCount(task *a2a.Task) int {
+	return max(extractCount(task, x402.MetadataKeyRequotes), 0)
+}
+
+// ExtractOptionRetries returns how many payments on an unquoted network or
+// option the task has rejected while still awaiting payment.
+func ExtractOptionRetries(task *a2a.Task) int {
+	return max(extractCount(task, x402.MetadataKeyOptionRetries), 0)
+}
+
+// ExtractCompensation returns the outcome of compensation for a payment that
+// failed to settle after delivery, empty when none ran.
+func ExtractCompensation(task *a2a.Task) string {
+	if task == nil || task.Status.Message == nil {
+		return ""
+	}
+	outcome, _ := task.Status.Message.Meta()[x402.MetadataKeyCompensation].(string)
+	return outcome
+}
+
+// ExtractPayloadRetries returns how many undecodable payment payloads the
+// task has answered by keeping its quote open.
+func ExtractPayloadRetries(task *a2a.Task) int {
+	return max(extractCount(task, x402.MetadataKeyPayloadRetries), 0)
+}
+
+// ExtractFailedAttempts returns how many payment submissions the task has
+// turned away while keeping its quote open.
+func ExtractFailedAttempts(task *a2a.Task) int {
+	return max(extractCount(task, x402.MetadataKeyFailedAttempts), 0)
+}
+
+// extractCount reads an integer from the task's status metadata. Stored tasks
+// decoded from JSON carry numbers as floats.
+func extractCount(task *a2a.Task, key string) int {
+	if task == nil || task.Status.Message == nil {
+		return 0
+	}
+	switch count := task.Status.Message.Meta()[key].(type) {
+	case int:
+		return count
+	case float64:
+		return int(count)
+	case json.Number:
+		if n, err := count.Int64(); err == nil {
+			return int(n)
+		}
+	}
+	return 0
+}
+
+func ExtractSkillID(task *a2a.Task) string {
+	if task == nil || task.Status.Message == nil {
+		return ""
+	}
+	if skillID, ok := task.Status.Message.Meta()[x402.MetadataKeySkillID].(string); ok {
+		return skillID
+	}
+	return ""
+}
+
+// ExtractRequestMetadata returns the request metadata recorded with
+// SetRequestMetadata, or nil when there is none.
+func ExtractRequestMetadata(task *a2a.Task) map[string]interface{} {
+	if task == nil || task.Status.Message == nil {
+		return nil
+	}
+	metadata, _ := task.Status.Message.Meta()[x402.MetadataKeyRequestMetadata].(map[string]interface{})
+	return metadata
+}
+
+// RequestMetadata returns the metadata of message without the x402 protocol
+// keys, leaving only what the client attached for the service. It returns nil
+// when nothing is left.
+func RequestMetadata(message *a2a.Message) map[string]interface{} {
+	if message == nil {
+		return nil
+	}
+	var metadata map[string]interface{}
+	for key, value := range message.Metadata {
+		if strings.HasPrefix(key, x402.MetadataKeyPrefix) {
+			continue
+		}
+		if metadata == nil {
+			metadata = make(map[string]interface{})
+		}
+		metadata[key] = value
+	}
+	return metadata
+}
+
 func ExtractMessageText(message *a2a.Message) string {
 	if message == nil {
 		return ""
@@ -236,3 +359,22 @@ func ExtractMessageText(message *a2a.Message) string {
 
 	return ""
 }
+
+// ExtractIssuedRequirements returns the requirement hashes recorded when the
+// task's quote was issued, or nil for a quote recorded without them.
+func ExtractIssuedRequirements(task *a2a.Task) []string {
+	if task == nil || task.Status.Message == nil {
+		return nil
+	}
+	hashes, ok := task.Status.Message.Meta()[x402.MetadataKeyIssued].([]interface{})
+	if !ok {
+		return nil
+	}
+	issued := make([]string, 0, len(hashes))
+	for _, hash := range hashes {
+		if s, ok := hash.(string); ok {
+			issued = append(issued, s)
+		}
+	}
+	return issued
+}
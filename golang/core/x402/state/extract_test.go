@@ -16,6 +16,7 @@ package state
 
 import (
 	"testing"
+	"time"
 
 	"github.com/a2aproject/a2a-go/a2a"
 	"github.com/google-agentic-commerce/a2a-x402/core/x402"
@@ -562,3 +563,111 @@ func TestExtractPaymentReceipts(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractPaymentRound(t *testing.T) {
+	withRound := func(round interface{}) *a2a.Task {
+		message := a2a.NewMessage(a2a.MessageRoleAgent)
+		message.Metadata = map[string]interface{}{x402.MetadataKeyRound: round}
+		return &a2a.Task{Status: a2a.TaskStatus{Message: message}}
+	}
+	set := a2a.NewMessage(a2a.MessageRoleAgent)
+	SetPaymentRound(set, 3)
+
+	tests := []struct {
+		name string
+		task *a2a.Task
+		want int
+	}{
+		{name: "nil task", task: nil, want: 1},
+		{name: "no round recorded", task: &a2a.Task{Status: a2a.TaskStatus{Message: a2a.NewMessage(a2a.MessageRoleAgent)}}, want: 1},
+		{name: "set round", task: &a2a.Task{Status: a2a.TaskStatus{Message: set}}, want: 3},
+		{name: "decoded from JSON", task: withRound(float64(2)), want: 2},
+		{name: "invalid", task: withRound("two"), want: 1},
+		{name: "zero", task: withRound(0), want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractPaymentRound(tt.task); got != tt.want {
+				t.Errorf("ExtractPaymentRound() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractAckDeadline(t *testing.T) {
+	deadline := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	withDeadline := func(value interface{}) *a2a.Task {
+		message := a2a.NewMessage(a2a.MessageRoleAgent)
+		message.Metadata = map[string]interface{}{x402.MetadataKeyAckDeadline: value}
+		return &a2a.Task{Status: a2a.TaskStatus{Message: message}}
+	}
+	set := a2a.NewMessage(a2a.MessageRoleAgent)
+	SetAckDeadline(set, deadline)
+
+	tests := []struct {
+		name   string
+		task   *a2a.Task
+		wantOK bool
+	}{
+		{name: "nil task", task: nil},
+		{name: "no deadline", task: &a2a.Task{Status: a2a.TaskStatus{Message: a2a.NewMessage(a2a.MessageRoleAgent)}}},
+		{name: "set deadline", task: &a2a.Task{Status: a2a.TaskStatus{Message: set}}, wantOK: true},
+		{name: "decoded from JSON", task: withDeadline(float64(deadline.Unix())), wantOK: true},
+		{name: "invalid", task: withDeadline("tomorrow")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ExtractAckDeadline(tt.task)
+			if ok != tt.wantOK || (ok && !got.Equal(deadline)) {
+				t.Errorf("ExtractAckDeadline() = %v, %v, want %v, %v", got, ok, deadline, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestExtractDeliveryAck(t *testing.T) {
+	for _, ack := range []string{DeliveryAccepted, DeliveryDisputed} {
+		if got := ExtractDeliveryAck(EncodeDeliveryAck("task-1", ack, "")); got != ack {
+			t.Errorf("ExtractDeliveryAck(%q) = %q", ack, got)
+		}
+	}
+	unknown := a2a.NewMessage(a2a.MessageRoleUser)
+	unknown.Metadata = map[string]interface{}{x402.MetadataKeyDeliveryAck: "maybe"}
+	if got := ExtractDeliveryAck(unknown); got != "" {
+		t.Errorf("ExtractDeliveryAck(unknown) = %q, want empty", got)
+	}
+	if got := ExtractDeliveryAck(nil); got != "" {
+		t.Errorf("ExtractDeliveryAck(nil) = %q, want empty", got)
+	}
+}
+
+func TestExtractPaymentState_RejectOnlyOverridesOpenQuote(t *testing.T) {
+	tests := []struct {
+		name   string
+		stored PaymentStatus
+		want   PaymentStatus
+	}{
+		{name: "payment required", stored: PaymentRequired, want: PaymentRejected},
+		{name: "payment verified", stored: PaymentVerified, want: PaymentVerified},
+		{name: "delivery pending ack", stored: PaymentDeliveryPendingAck, want: PaymentDeliveryPendingAck},
+		{name: "payment completed", stored: PaymentCompleted, want: PaymentCompleted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &a2a.Task{
+				ID:     "task-1",
+				Status: a2a.TaskStatus{State: a2a.TaskStateWorking, Message: a2a.NewMessage(a2a.MessageRoleAgent)},
+			}
+			SetPaymentStatus(task.Status.Message, tt.stored)
+
+			got, err := ExtractPaymentState(task, EncodePaymentRejection(task.ID, ""))
+			if err != nil {
+				t.Fatalf("ExtractPaymentState() error = %v", err)
+			}
+			if got.Status != tt.want {
+				t.Errorf("status = %q, want %q", got.Status, tt.want)
+			}
+		})
+	}
+}
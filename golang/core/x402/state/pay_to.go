@@ -0,0 +1,55 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+)
+
+// SetPayToAddresses records the address each network's quote pays, keyed by
+// network, so funds arriving at a per-task address can be matched to the task.
+func SetPayToAddresses(msg *a2a.Message, addresses map[string]string) {
+	if len(addresses) == 0 {
+		return
+	}
+	if msg.Metadata == nil {
+		msg.Metadata = make(map[string]interface{})
+	}
+	recorded := make(map[string]interface{}, len(addresses))
+	for network, address := range addresses {
+		recorded[network] = address
+	}
+	msg.Metadata[x402.MetadataKeyPayTo] = recorded
+}
+
+// ExtractPayToAddresses returns the pay-to addresses recorded with the task's
+// quote, keyed by network, or nil when none were.
+func ExtractPayToAddresses(task *a2a.Task) map[string]string {
+	if task == nil || task.Status.Message == nil {
+		return nil
+	}
+	recorded, ok := task.Status.Message.Meta()[x402.MetadataKeyPayTo].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	addresses := make(map[string]string, len(recorded))
+	for network, address := range recorded {
+		if address, ok := address.(string); ok {
+			addresses[network] = address
+		}
+	}
+	return addresses
+}
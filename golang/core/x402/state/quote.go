@@ -0,0 +1,45 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+)
+
+// SetQuotedAt records when the quote on the message was issued.
+func SetQuotedAt(msg *a2a.Message, quotedAt time.Time) {
+	if msg.Metadata == nil {
+		msg.Metadata = make(map[string]interface{})
+	}
+	msg.Metadata[x402.MetadataKeyQuotedAt] = quotedAt.Unix()
+}
+
+// ExtractQuotedAt returns when the task's open quote was issued, and false
+// for a quote recorded without the time.
+func ExtractQuotedAt(task *a2a.Task) (time.Time, bool) {
+	if task == nil || task.Status.Message == nil {
+		return time.Time{}, false
+	}
+	switch quotedAt := task.Status.Message.Meta()[x402.MetadataKeyQuotedAt].(type) {
+	case int64:
+		return time.Unix(quotedAt, 0), true
+	case float64:
+		return time.Unix(int64(quotedAt), 0), true
+	}
+	return time.Time{}, false
+}
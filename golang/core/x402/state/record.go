@@ -31,6 +31,7 @@ func RecordPaymentRequired(task *a2a.Task, requirements *x402types.PaymentRequir
 		task.Status.Message = a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{Text: defaultText})
 	}
 	SetPaymentStatus(task.Status.Message, PaymentRequired)
+	SetIssuedRequirements(task.Status.Message, requirements)
 	return SetPaymentRequirements(task.Status.Message, requirements)
 }
 
@@ -45,6 +46,11 @@ func RecordPaymentVerified(task *a2a.Task, paymentState *PaymentState, defaultTe
 	if err := SetPaymentPayload(task.Status.Message, paymentState.Payload); err != nil {
 		return err
 	}
+	hash, err := PaymentPayloadHash(paymentState.Payload)
+	if err != nil {
+		return err
+	}
+	SetPaymentPayloadHash(task.Status.Message, hash)
 	return SetPaymentRequirements(task.Status.Message, paymentState.Requirements)
 }
 
@@ -74,6 +80,9 @@ func RecordPaymentCompleted(task *a2a.Task, receipts []*x402core.SettleResponse,
 	return nil
 }
 
+// RecordPaymentFailed records a failed payment. A settlement that timed out
+// or was not confirmed in time is marked indeterminate: the transaction may
+// still land on chain.
 func RecordPaymentFailed(task *a2a.Task, errorCode string, defaultText string, receipt *x402core.SettleResponse) error {
 	if receipt == nil {
 		return fmt.Errorf("failed payment receipt is required")
@@ -96,9 +105,40 @@ func RecordPaymentFailed(task *a2a.Task, errorCode string, defaultText string, r
 	}
 	SetPaymentStatus(task.Status.Message, PaymentFailed)
 	SetPaymentError(task.Status.Message, errorCode)
+	if errorCode == x402.ErrorCodeSettleTimeout || errorCode == x402.ErrorCodeConfirmationTimeout {
+		SetPaymentIndeterminate(task.Status.Message)
+	}
 	if err := SetPaymentReceipts(task.Status.Message, []*x402core.SettleResponse{receipt}); err != nil {
 		return err
 	}
 	delete(task.Status.Message.Metadata, x402.MetadataKeyPayload)
 	return nil
 }
+
+// RecordPaymentCanceled records the payment outcome of a canceled task.
+// Receipts from a settlement that already happened are kept so the client
+// knows funds moved; a verified authorization that was never settled is
+// marked voided.
+func RecordPaymentCanceled(task *a2a.Task, previous PaymentStatus, receipts []*x402core.SettleResponse, defaultText string) error {
+	if defaultText == "" {
+		defaultText = "Task canceled"
+	}
+	task.Status.Message = a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{Text: defaultText})
+	if len(receipts) > 0 {
+		SetPaymentStatus(task.Status.Message, PaymentCompleted)
+		return SetPaymentReceipts(task.Status.Message, receipts)
+	}
+	SetPaymentStatus(task.Status.Message, PaymentRejected)
+	if previous == PaymentVerified || previous == PaymentDeliveryPendingAck {
+		SetPaymentVoided(task.Status.Message)
+	}
+	return nil
+}
+
+func RecordPaymentRejected(task *a2a.Task, defaultText string) {
+	if defaultText == "" {
+		defaultText = "Payment rejected"
+	}
+	task.Status.Message = a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{Text: defaultText})
+	SetPaymentStatus(task.Status.Message, PaymentRejected)
+}
@@ -79,3 +79,43 @@ func TestRecordPaymentFailedRequiresReceipt(t *testing.T) {
 		t.Fatal("RecordPaymentFailed() error = nil, want missing receipt error")
 	}
 }
+
+func TestRecordPaymentFailedMarksSettleTimeoutIndeterminate(t *testing.T) {
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{code: x402pkg.ErrorCodeSettleTimeout, want: true},
+		{code: x402pkg.ErrorCodeConfirmationTimeout, want: true},
+		{code: x402pkg.ErrorCodeVerifyTimeout, want: false},
+		{code: x402pkg.ErrorCodeSettlementFailed, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			task := &a2a.Task{Status: a2a.TaskStatus{Message: a2a.NewMessage(a2a.MessageRoleAgent)}}
+			if err := RecordPaymentFailed(task, tt.code, "failed", &x402core.SettleResponse{}); err != nil {
+				t.Fatalf("RecordPaymentFailed() error = %v", err)
+			}
+			if got := task.Status.Message.Metadata[x402pkg.MetadataKeyIndeterminate] == true; got != tt.want {
+				t.Errorf("indeterminate = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordPaymentRequiredPreservesParts(t *testing.T) {
+	preview := a2a.FilePart{File: a2a.FileURI{URI: "https://example.com/preview.png", FileMeta: a2a.FileMeta{MimeType: "image/png"}}}
+	task := &a2a.Task{Status: a2a.TaskStatus{
+		Message: a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{Text: "Payment required"}, preview),
+	}}
+
+	if err := RecordPaymentRequired(task, &x402types.PaymentRequired{X402Version: x402pkg.X402Version}, ""); err != nil {
+		t.Fatalf("RecordPaymentRequired() error = %v", err)
+	}
+	if len(task.Status.Message.Parts) != 2 {
+		t.Fatalf("parts = %#v, want text and preview", task.Status.Message.Parts)
+	}
+	if _, ok := task.Status.Message.Metadata[x402pkg.MetadataKeyRequired]; !ok {
+		t.Error("payment requirements were not recorded")
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/utils"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+)
+
+// RefundReceipt records an amount a merchant returned to the payer of a
+// settled task. Amount is in the asset's base units.
+type RefundReceipt struct {
+	TaskID  a2a.TaskID `json:"taskId"`
+	Payer   string     `json:"payer"`
+	Network string     `json:"network"`
+	Asset   string     `json:"asset"`
+	Amount  string     `json:"amount"`
+	Reason  string     `json:"reason,omitempty"`
+	// Transaction is the refund transfer, or empty for a refund made outside
+	// the merchant.
+	Transaction string    `json:"transaction,omitempty"`
+	IssuedAt    time.Time `json:"issuedAt"`
+}
+
+// AppendRefund adds refund to the refunds recorded on msg, after any it
+// already carries.
+func AppendRefund(msg *a2a.Message, refund *RefundReceipt) error {
+	refundMap, err := utils.ToMap(refund)
+	if err != nil {
+		return fmt.Errorf("failed to convert refund to map: %w", err)
+	}
+	if msg.Metadata == nil {
+		msg.Metadata = make(map[string]interface{})
+	}
+	existing, _ := msg.Metadata[x402.MetadataKeyRefunds].([]interface{})
+	refunds := make([]interface{}, 0, len(existing)+1)
+	refunds = append(refunds, existing...)
+	msg.Metadata[x402.MetadataKeyRefunds] = append(refunds, refundMap)
+	return nil
+}
+
+// ExtractRefunds returns the refunds recorded on the task in the order they
+// were issued, or none.
+func ExtractRefunds(task *a2a.Task) ([]*RefundReceipt, error) {
+	if task == nil || task.Status.Message == nil {
+		return nil, nil
+	}
+	recorded, _ := task.Status.Message.Meta()[x402.MetadataKeyRefunds].([]interface{})
+	refunds := make([]*RefundReceipt, 0, len(recorded))
+	for _, data := range recorded {
+		refundMap, ok := data.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("refund data is not a map")
+		}
+		var refund RefundReceipt
+		if err := utils.FromMap(refundMap, &refund); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal refund: %w", err)
+		}
+		refunds = append(refunds, &refund)
+	}
+	return refunds, nil
+}
@@ -15,7 +15,13 @@
 package state
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/a2aproject/a2a-go/a2a"
 	"github.com/google-agentic-commerce/a2a-x402/core/utils"
@@ -88,6 +94,29 @@ func SetPaymentReceipts(msg *a2a.Message, receipts []*x402core.SettleResponse) e
 	return nil
 }
 
+// SetPaymentTransactions summarizes the transaction reference and network of
+// each successful receipt so clients need not decode the full receipts.
+// Receipts without a transaction are skipped.
+func SetPaymentTransactions(msg *a2a.Message, receipts []*x402core.SettleResponse) {
+	var transactions []interface{}
+	for _, receipt := range receipts {
+		if receipt == nil || !receipt.Success || receipt.Transaction == "" {
+			continue
+		}
+		transactions = append(transactions, map[string]interface{}{
+			"transaction": receipt.Transaction,
+			"network":     string(receipt.Network),
+		})
+	}
+	if len(transactions) == 0 {
+		return
+	}
+	if msg.Metadata == nil {
+		msg.Metadata = make(map[string]interface{})
+	}
+	msg.Metadata[x402.MetadataKeyTransactions] = transactions
+}
+
 func SetPaymentError(msg *a2a.Message, errorCode string) {
 	if errorCode == "" {
 		return
@@ -98,6 +127,12 @@ func SetPaymentError(msg *a2a.Message, errorCode string) {
 	msg.Metadata[x402.MetadataKeyError] = errorCode
 }
 
+// ClearPaymentError removes an error code recorded by an earlier attempt, such
+// as a rejected option or an expired quote, once a payment goes through.
+func ClearPaymentError(msg *a2a.Message) {
+	delete(msg.Metadata, x402.MetadataKeyError)
+}
+
 func SetOriginalPrompt(msg *a2a.Message, prompt string) {
 	if prompt == "" {
 		return
@@ -108,10 +143,180 @@ func SetOriginalPrompt(msg *a2a.Message, prompt string) {
 	msg.Metadata[x402.MetadataKeyOriginalPrompt] = prompt
 }
 
+// SetOriginalPromptDigest records a salted digest and the character length of
+// prompt in place of its text.
+func SetOriginalPromptDigest(msg *a2a.Message, prompt string, salt []byte) {
+	if prompt == "" {
+		return
+	}
+	if msg.Metadata == nil {
+		msg.Metadata = make(map[string]interface{})
+	}
+	msg.Metadata[x402.MetadataKeyPromptDigest] = PromptDigest(prompt, salt)
+	msg.Metadata[x402.MetadataKeyPromptLength] = utf8.RuneCountInString(prompt)
+}
+
+// PromptDigest returns the hex salt and the hex SHA-256 of salt followed by
+// prompt, separated by a colon, so a holder of the prompt can check it later.
+func PromptDigest(prompt string, salt []byte) string {
+	sum := sha256.Sum256(append(slices.Clone(salt), prompt...))
+	return hex.EncodeToString(salt) + ":" + hex.EncodeToString(sum[:])
+}
+
+// SetPaymentPayloadHash records the hash of the verified payload so that a
+// retried submission of the same payload can be recognized.
+func SetPaymentPayloadHash(msg *a2a.Message, hash string) {
+	if hash == "" {
+		return
+	}
+	if msg.Metadata == nil {
+		msg.Metadata = make(map[string]interface{})
+	}
+	msg.Metadata[x402.MetadataKeyPayloadHash] = hash
+}
+
+// PaymentPayloadHash returns a hex SHA-256 over the canonical JSON encoding of
+// the payload.
+func PaymentPayloadHash(payload *x402types.PaymentPayload) (string, error) {
+	if payload == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode payment payload: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// SetIssuedRequirements records the hash of every requirement in a quote as
+// it is issued, so that a payment can later be held to what was quoted even if
+// the stored requirements change.
+func SetIssuedRequirements(msg *a2a.Message, requirements *x402types.PaymentRequired) {
+	if requirements == nil {
+		return
+	}
+	if msg.Metadata == nil {
+		msg.Metadata = make(map[string]interface{})
+	}
+	hashes := make([]interface{}, 0, len(requirements.Accepts))
+	for _, requirement := range requirements.Accepts {
+		hashes = append(hashes, RequirementHash(requirement))
+	}
+	msg.Metadata[x402.MetadataKeyIssued] = hashes
+}
+
+// RequirementHash returns a hex SHA-256 over the fields a payment must agree
+// with: scheme, network, asset, payTo and amount. Addresses are folded to
+// lower case on EVM networks, where they compare case-insensitively.
+func RequirementHash(requirement x402types.PaymentRequirements) string {
+	asset, payTo := requirement.Asset, requirement.PayTo
+	if x402.IsEVMNetwork(requirement.Network) {
+		asset, payTo = strings.ToLower(asset), strings.ToLower(payTo)
+	}
+	data, _ := json.Marshal([]string{requirement.Scheme, requirement.Network, asset, payTo, requirement.Amount})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func SetSkillID(msg *a2a.Message, skillID string) {
+	if skillID == "" {
+		return
+	}
+	if msg.Metadata == nil {
+		msg.Metadata = make(map[string]interface{})
+	}
+	msg.Metadata[x402.MetadataKeySkillID] = skillID
+}
+
+// SetRequestMetadata keeps the client's own metadata from the message that
+// started the task, so a later execution sees it even when the task history
+// is gone. Empty metadata is not recorded.
+func SetRequestMetadata(msg *a2a.Message, metadata map[string]interface{}) {
+	if len(metadata) == 0 {
+		return
+	}
+	if msg.Metadata == nil {
+		msg.Metadata = make(map[string]interface{})
+	}
+	msg.Metadata[x402.MetadataKeyRequestMetadata] = metadata
+}
+
+// SetPaymentRound records which payment round of a multi-payment task the
+// message belongs to.
+func SetPaymentRound(msg *a2a.Message, round int) {
+	if msg.Metadata == nil {
+		msg.Metadata = make(map[string]interface{})
+	}
+	msg.Metadata[x402.MetadataKeyRound] = round
+}
+
+// SetRequoteCount records how many times the task's quote has been reissued
+// after expiring.
+func SetRequoteCount(msg *a2a.Message, count int) {
+	if msg.Metadata == nil {
+		msg.Metadata = make(map[string]interface{})
+	}
+	msg.Metadata[x402.MetadataKeyRequotes] = count
+}
+
+// SetOptionRetries records how many payments on an unquoted network or option
+// the task has rejected.
+func SetOptionRetries(msg *a2a.Message, count int) {
+	if msg.Metadata == nil {
+		msg.Metadata = make(map[string]interface{})
+	}
+	msg.Metadata[x402.MetadataKeyOptionRetries] = count
+}
+
+// SetPayloadRetries records how many undecodable payment payloads the task
+// has answered by keeping its quote open.
+func SetPayloadRetries(msg *a2a.Message, count int) {
+	if msg.Metadata == nil {
+		msg.Metadata = make(map[string]interface{})
+	}
+	msg.Metadata[x402.MetadataKeyPayloadRetries] = count
+}
+
+// SetFailedAttempts records how many payment submissions the task has turned
+// away while keeping its quote open.
+func SetFailedAttempts(msg *a2a.Message, count int) {
+	if msg.Metadata == nil {
+		msg.Metadata = make(map[string]interface{})
+	}
+	msg.Metadata[x402.MetadataKeyFailedAttempts] = count
+}
+
+// SetCompensation records that compensation ran for a payment that failed to
+// settle after delivery, and its outcome.
+func SetCompensation(msg *a2a.Message, outcome string) {
+	if msg.Metadata == nil {
+		msg.Metadata = make(map[string]interface{})
+	}
+	msg.Metadata[x402.MetadataKeyCompensation] = outcome
+}
+
+func SetPaymentVoided(msg *a2a.Message) {
+	if msg.Metadata == nil {
+		msg.Metadata = make(map[string]interface{})
+	}
+	msg.Metadata[x402.MetadataKeyVoided] = true
+}
+
+// SetPaymentIndeterminate marks a payment whose settlement outcome is unknown.
+func SetPaymentIndeterminate(msg *a2a.Message) {
+	if msg.Metadata == nil {
+		msg.Metadata = make(map[string]interface{})
+	}
+	msg.Metadata[x402.MetadataKeyIndeterminate] = true
+}
+
 func ClearPaymentMetadata(msg *a2a.Message) {
 	if msg.Metadata == nil {
 		return
 	}
 	delete(msg.Metadata, x402.MetadataKeyPayload)
 	delete(msg.Metadata, x402.MetadataKeyRequired)
+	delete(msg.Metadata, x402.MetadataKeyIssued)
+	delete(msg.Metadata, x402.MetadataKeyQuotedAt)
 }
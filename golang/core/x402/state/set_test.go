@@ -0,0 +1,134 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402core "github.com/x402-foundation/x402/go"
+	x402types "github.com/x402-foundation/x402/go/types"
+)
+
+func TestSetPaymentTransactions(t *testing.T) {
+	tests := []struct {
+		name     string
+		receipts []*x402core.SettleResponse
+		want     interface{}
+	}{
+		{
+			name: "successful receipts",
+			receipts: []*x402core.SettleResponse{
+				{Success: true, Transaction: "0xabc", Network: x402pkg.NetworkBaseSepolia},
+				{Success: true, Transaction: "5sig", Network: x402pkg.NetworkSolanaDevnet},
+			},
+			want: []interface{}{
+				map[string]interface{}{"transaction": "0xabc", "network": x402pkg.NetworkBaseSepolia},
+				map[string]interface{}{"transaction": "5sig", "network": x402pkg.NetworkSolanaDevnet},
+			},
+		},
+		{
+			name: "receipts without a transaction are skipped",
+			receipts: []*x402core.SettleResponse{
+				{Success: true, Network: x402pkg.NetworkBaseSepolia},
+				nil,
+				{Success: false, Transaction: "0xdead"},
+				{Success: true, Transaction: "0xabc"},
+			},
+			want: []interface{}{
+				map[string]interface{}{"transaction": "0xabc", "network": ""},
+			},
+		},
+		{
+			name:     "nothing to summarize",
+			receipts: []*x402core.SettleResponse{{Success: true}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := a2a.NewMessage(a2a.MessageRoleAgent)
+			SetPaymentTransactions(msg, tt.receipts)
+			got, ok := msg.Metadata[x402pkg.MetadataKeyTransactions]
+			if tt.want == nil {
+				if ok {
+					t.Fatalf("metadata = %v, want no transaction summary", got)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("transactions = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetOriginalPromptDigest(t *testing.T) {
+	msg := a2a.NewMessage(a2a.MessageRoleAgent)
+	SetOriginalPromptDigest(msg, "héllo", []byte{0x01, 0x02})
+	task := &a2a.Task{Status: a2a.TaskStatus{Message: msg}}
+
+	digest, length := ExtractOriginalPromptDigest(task)
+	if digest != PromptDigest("héllo", []byte{0x01, 0x02}) || !strings.HasPrefix(digest, "0102:") {
+		t.Errorf("digest = %q", digest)
+	}
+	if length != 5 {
+		t.Errorf("length = %d, want 5", length)
+	}
+	if PromptDigest("héllo", []byte{0x03}) == digest {
+		t.Error("digest does not depend on the salt")
+	}
+	if ExtractOriginalPrompt(task) != "" {
+		t.Error("prompt text was stored")
+	}
+}
+
+func TestSetIssuedRequirements(t *testing.T) {
+	quoted := x402types.PaymentRequirements{Scheme: "exact", Network: x402pkg.NetworkBaseSepolia, Asset: "0xAbC", PayTo: "0xDeF", Amount: "100"}
+	msg := a2a.NewMessage(a2a.MessageRoleAgent)
+	SetIssuedRequirements(msg, &x402types.PaymentRequired{Accepts: []x402types.PaymentRequirements{quoted}})
+	task := &a2a.Task{Status: a2a.TaskStatus{Message: msg}}
+
+	issued := ExtractIssuedRequirements(task)
+	if len(issued) != 1 || issued[0] != RequirementHash(quoted) {
+		t.Fatalf("issued = %v, want the quoted requirement's hash", issued)
+	}
+
+	folded := quoted
+	folded.Asset, folded.PayTo = "0xabc", "0xdef"
+	if RequirementHash(folded) != issued[0] {
+		t.Error("EVM address case changed the hash")
+	}
+	for name, change := range map[string]func(*x402types.PaymentRequirements){
+		"amount":  func(r *x402types.PaymentRequirements) { r.Amount = "99" },
+		"payTo":   func(r *x402types.PaymentRequirements) { r.PayTo = "0x999" },
+		"asset":   func(r *x402types.PaymentRequirements) { r.Asset = "0x999" },
+		"scheme":  func(r *x402types.PaymentRequirements) { r.Scheme = "upto" },
+		"network": func(r *x402types.PaymentRequirements) { r.Network = "eip155:1" },
+	} {
+		changed := quoted
+		change(&changed)
+		if RequirementHash(changed) == issued[0] {
+			t.Errorf("changing %s kept the hash", name)
+		}
+	}
+
+	if got := ExtractIssuedRequirements(&a2a.Task{Status: a2a.TaskStatus{Message: a2a.NewMessage(a2a.MessageRoleAgent)}}); got != nil {
+		t.Errorf("issued without hashes = %v, want nil", got)
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google-agentic-commerce/a2a-x402/core/utils"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402core "github.com/x402-foundation/x402/go"
+)
+
+// SignedReceipt is the merchant's attestation that a settlement belongs to a
+// task. The signature covers the canonical encoding of every field except
+// Signer and Signature.
+type SignedReceipt struct {
+	TaskID      string                   `json:"taskId"`
+	PayloadHash string                   `json:"payloadHash,omitempty"`
+	Receipt     *x402core.SettleResponse `json:"receipt"`
+	Timestamp   int64                    `json:"timestamp"`
+	Signer      string                   `json:"signer"`
+	Signature   string                   `json:"signature"`
+}
+
+// ReceiptDigest returns the Keccak-256 hash of the canonical encoding of the
+// signed fields.
+func ReceiptDigest(receipt *SignedReceipt) ([]byte, error) {
+	canonical, err := CanonicalJSON(map[string]interface{}{
+		"taskId":      receipt.TaskID,
+		"payloadHash": receipt.PayloadHash,
+		"receipt":     receipt.Receipt,
+		"timestamp":   receipt.Timestamp,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Keccak256(canonical), nil
+}
+
+// CanonicalJSON encodes v with object keys sorted at every level and no
+// insignificant whitespace, so equal values always produce the same bytes.
+func CanonicalJSON(v interface{}) ([]byte, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+	// encoding/json writes map keys in sorted order.
+	return json.Marshal(generic)
+}
+
+// VerifySignedReceipt checks that receipt was signed by expectedSigner, an
+// EVM address. An empty expectedSigner accepts the signer recorded in the
+// receipt, which only proves integrity, not origin.
+func VerifySignedReceipt(receipt *SignedReceipt, expectedSigner string) error {
+	if receipt == nil {
+		return fmt.Errorf("signed receipt is required")
+	}
+	signature, err := hex.DecodeString(strings.TrimPrefix(receipt.Signature, "0x"))
+	if err != nil || len(signature) != crypto.SignatureLength {
+		return fmt.Errorf("invalid receipt signature encoding")
+	}
+	// Accept both raw (0/1) and Ethereum-style (27/28) recovery ids.
+	if signature[crypto.RecoveryIDOffset] >= 27 {
+		signature[crypto.RecoveryIDOffset] -= 27
+	}
+
+	digest, err := ReceiptDigest(receipt)
+	if err != nil {
+		return fmt.Errorf("failed to encode receipt: %w", err)
+	}
+	publicKey, err := crypto.SigToPub(digest, signature)
+	if err != nil {
+		return fmt.Errorf("failed to recover receipt signer: %w", err)
+	}
+	recovered := crypto.PubkeyToAddress(*publicKey).Hex()
+
+	if !strings.EqualFold(recovered, receipt.Signer) {
+		return fmt.Errorf("receipt signed by %s, not the recorded signer %s", recovered, receipt.Signer)
+	}
+	if expectedSigner != "" && !strings.EqualFold(recovered, expectedSigner) {
+		return fmt.Errorf("receipt signed by %s, want %s", recovered, expectedSigner)
+	}
+	return nil
+}
+
+// SetSignedReceipts stores merchant signatures next to the receipts.
+func SetSignedReceipts(msg *a2a.Message, receipts []*SignedReceipt) error {
+	if len(receipts) == 0 {
+		return nil
+	}
+	signed, err := utils.ToSlice(receipts)
+	if err != nil {
+		return fmt.Errorf("failed to convert signed receipts: %w", err)
+	}
+	if msg.Metadata == nil {
+		msg.Metadata = make(map[string]interface{})
+	}
+	msg.Metadata[x402.MetadataKeySignedReceipts] = signed
+	return nil
+}
+
+// ExtractSignedReceipts returns the merchant-signed receipts recorded on the
+// task, if any.
+func ExtractSignedReceipts(task *a2a.Task) ([]*SignedReceipt, error) {
+	if task == nil || task.Status.Message == nil {
+		return nil, nil
+	}
+	data, ok := task.Status.Message.Meta()[x402.MetadataKeySignedReceipts].([]interface{})
+	if !ok {
+		return nil, nil
+	}
+	receipts := make([]*SignedReceipt, 0, len(data))
+	for _, item := range data {
+		itemMap, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("signed receipt data is not a map")
+		}
+		var receipt SignedReceipt
+		if err := utils.FromMap(itemMap, &receipt); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal signed receipt: %w", err)
+		}
+		receipts = append(receipts, &receipt)
+	}
+	return receipts, nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/ethereum/go-ethereum/crypto"
+	x402pkg "github.com/google-agentic-commerce/a2a-x402/core/x402"
+	x402core "github.com/x402-foundation/x402/go"
+)
+
+func TestCanonicalJSON(t *testing.T) {
+	a, err := CanonicalJSON(map[string]interface{}{"b": 1, "a": map[string]interface{}{"d": true, "c": "x"}})
+	if err != nil {
+		t.Fatalf("CanonicalJSON() error = %v", err)
+	}
+	b, err := CanonicalJSON(struct {
+		B int `json:"b"`
+		A struct {
+			D bool   `json:"d"`
+			C string `json:"c"`
+		} `json:"a"`
+	}{B: 1, A: struct {
+		D bool   `json:"d"`
+		C string `json:"c"`
+	}{D: true, C: "x"}})
+	if err != nil {
+		t.Fatalf("CanonicalJSON() error = %v", err)
+	}
+	const want = `{"a":{"c":"x","d":true},"b":1}`
+	if string(a) != want || string(b) != want {
+		t.Errorf("CanonicalJSON() = %s and %s, want %s", a, b, want)
+	}
+}
+
+func TestSignedReceiptRoundTrip(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.PubkeyToAddress(key.PublicKey).Hex()
+	sign := func(receipt *SignedReceipt) {
+		t.Helper()
+		receipt.Signer = signer
+		digest, err := ReceiptDigest(receipt)
+		if err != nil {
+			t.Fatalf("ReceiptDigest() error = %v", err)
+		}
+		signature, err := crypto.Sign(digest, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		receipt.Signature = "0x" + hex.EncodeToString(signature)
+	}
+	newReceipt := func() *SignedReceipt {
+		receipt := &SignedReceipt{
+			TaskID:      "task-1",
+			PayloadHash: "abc123",
+			Receipt:     &x402core.SettleResponse{Success: true, Transaction: "0xtx", Network: x402pkg.NetworkBaseSepolia, Payer: "0xpayer", Amount: "1000"},
+			Timestamp:   1760000000,
+		}
+		sign(receipt)
+		return receipt
+	}
+
+	tests := []struct {
+		name     string
+		mutate   func(*SignedReceipt)
+		expected string
+		wantErr  bool
+	}{
+		{name: "valid", expected: signer},
+		{name: "valid without expected signer"},
+		{name: "expected signer is case-insensitive", expected: "0x" + hex.EncodeToString(crypto.PubkeyToAddress(key.PublicKey).Bytes())},
+		{name: "tampered amount", mutate: func(r *SignedReceipt) { r.Receipt.Amount = "1" }, wantErr: true},
+		{name: "tampered task", mutate: func(r *SignedReceipt) { r.TaskID = "task-2" }, wantErr: true},
+		{name: "other merchant", expected: "0x0000000000000000000000000000000000000001", wantErr: true},
+		{name: "bad signature", mutate: func(r *SignedReceipt) { r.Signature = "0x1234" }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := a2a.NewMessage(a2a.MessageRoleAgent)
+			if err := SetSignedReceipts(msg, []*SignedReceipt{newReceipt()}); err != nil {
+				t.Fatalf("SetSignedReceipts() error = %v", err)
+			}
+			receipts, err := ExtractSignedReceipts(&a2a.Task{Status: a2a.TaskStatus{Message: msg}})
+			if err != nil || len(receipts) != 1 {
+				t.Fatalf("ExtractSignedReceipts() = %v, %v", receipts, err)
+			}
+			if tt.mutate != nil {
+				tt.mutate(receipts[0])
+			}
+			err = VerifySignedReceipt(receipts[0], tt.expected)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VerifySignedReceipt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
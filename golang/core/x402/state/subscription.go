@@ -0,0 +1,192 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google-agentic-commerce/a2a-x402/core/utils"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+)
+
+// subscriptionClaimType separates claim digests from other signed payloads.
+const subscriptionClaimType = "x402.subscription.claim"
+
+// SubscriptionClaim is a payer's signed statement that it is making the
+// request, so a merchant can honor a subscription the payer bought earlier.
+// The signature covers every field but Signature. Audience names the merchant
+// the claim is for, by its payTo address unless the merchant publishes
+// another, and ContextID the conversation it is made in; a merchant accepts
+// each Nonce once.
+type SubscriptionClaim struct {
+	Payer     string `json:"payer"`
+	Audience  string `json:"audience"`
+	ContextID string `json:"contextId"`
+	Nonce     string `json:"nonce"`
+	Timestamp int64  `json:"timestamp"`
+	Signature string `json:"signature"`
+}
+
+// NewSubscriptionClaim returns an unsigned claim by payer for a request to
+// audience in contextID, issued now with a random nonce.
+func NewSubscriptionClaim(payer, audience, contextID string, now time.Time) (*SubscriptionClaim, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate claim nonce: %w", err)
+	}
+	return &SubscriptionClaim{
+		Payer:     payer,
+		Audience:  audience,
+		ContextID: contextID,
+		Nonce:     hex.EncodeToString(nonce),
+		Timestamp: now.Unix(),
+	}, nil
+}
+
+// SubscriptionClaimDigest returns the Keccak-256 hash of the canonical
+// encoding of the signed fields.
+func SubscriptionClaimDigest(claim *SubscriptionClaim) ([]byte, error) {
+	canonical, err := CanonicalJSON(map[string]interface{}{
+		"type":      subscriptionClaimType,
+		"payer":     claim.Payer,
+		"audience":  claim.Audience,
+		"contextId": claim.ContextID,
+		"nonce":     claim.Nonce,
+		"timestamp": claim.Timestamp,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Keccak256(canonical), nil
+}
+
+// SignSubscriptionClaim fills in the claim's signature with an EVM key given
+// in hex. The key must belong to claim.Payer.
+func SignSubscriptionClaim(claim *SubscriptionClaim, privateKeyHex string) error {
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(privateKeyHex, "0x"))
+	if err != nil {
+		return fmt.Errorf("invalid claim signing key: %w", err)
+	}
+	digest, err := SubscriptionClaimDigest(claim)
+	if err != nil {
+		return fmt.Errorf("failed to encode subscription claim: %w", err)
+	}
+	signature, err := crypto.Sign(digest, key)
+	if err != nil {
+		return fmt.Errorf("failed to sign subscription claim: %w", err)
+	}
+	claim.Signature = "0x" + hex.EncodeToString(signature)
+	return nil
+}
+
+// VerifySubscriptionClaim checks that the claim was signed by its payer.
+func VerifySubscriptionClaim(claim *SubscriptionClaim) error {
+	if claim == nil || claim.Payer == "" {
+		return fmt.Errorf("subscription claim payer is required")
+	}
+	signature, err := hex.DecodeString(strings.TrimPrefix(claim.Signature, "0x"))
+	if err != nil || len(signature) != crypto.SignatureLength {
+		return fmt.Errorf("invalid subscription claim signature encoding")
+	}
+	// Accept both raw (0/1) and Ethereum-style (27/28) recovery ids.
+	if signature[crypto.RecoveryIDOffset] >= 27 {
+		signature[crypto.RecoveryIDOffset] -= 27
+	}
+
+	digest, err := SubscriptionClaimDigest(claim)
+	if err != nil {
+		return fmt.Errorf("failed to encode subscription claim: %w", err)
+	}
+	publicKey, err := crypto.SigToPub(digest, signature)
+	if err != nil {
+		return fmt.Errorf("failed to recover subscription claim signer: %w", err)
+	}
+	if recovered := crypto.PubkeyToAddress(*publicKey).Hex(); !strings.EqualFold(recovered, claim.Payer) {
+		return fmt.Errorf("subscription claim signed by %s, not payer %s", recovered, claim.Payer)
+	}
+	return nil
+}
+
+// SetSubscriptionClaim attaches a signed claim to a client message.
+func SetSubscriptionClaim(msg *a2a.Message, claim *SubscriptionClaim) error {
+	claimMap, err := utils.ToMap(claim)
+	if err != nil {
+		return fmt.Errorf("failed to convert subscription claim: %w", err)
+	}
+	if msg.Metadata == nil {
+		msg.Metadata = make(map[string]interface{})
+	}
+	msg.Metadata[x402.MetadataKeySubscriptionClaim] = claimMap
+	return nil
+}
+
+// ExtractSubscriptionClaim returns the claim attached to msg, or nil when
+// there is none. The signature is not checked.
+func ExtractSubscriptionClaim(msg *a2a.Message) (*SubscriptionClaim, error) {
+	if msg == nil {
+		return nil, nil
+	}
+	claimMap, ok := msg.Meta()[x402.MetadataKeySubscriptionClaim].(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+	var claim SubscriptionClaim
+	if err := utils.FromMap(claimMap, &claim); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal subscription claim: %w", err)
+	}
+	return &claim, nil
+}
+
+// SubscriptionReference records the subscription that paid for a task in
+// place of a receipt.
+type SubscriptionReference struct {
+	ID         string `json:"id"`
+	Payer      string `json:"payer"`
+	ValidUntil int64  `json:"validUntil"`
+}
+
+func SetSubscription(msg *a2a.Message, reference *SubscriptionReference) {
+	if msg.Metadata == nil {
+		msg.Metadata = make(map[string]interface{})
+	}
+	msg.Metadata[x402.MetadataKeySubscription] = map[string]interface{}{
+		"id":         reference.ID,
+		"payer":      reference.Payer,
+		"validUntil": reference.ValidUntil,
+	}
+}
+
+// ExtractSubscription returns the subscription that paid for the task, or
+// nil when it was not covered by one.
+func ExtractSubscription(task *a2a.Task) (*SubscriptionReference, error) {
+	if task == nil || task.Status.Message == nil {
+		return nil, nil
+	}
+	referenceMap, ok := task.Status.Message.Meta()[x402.MetadataKeySubscription].(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+	var reference SubscriptionReference
+	if err := utils.FromMap(referenceMap, &reference); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal subscription reference: %w", err)
+	}
+	return &reference, nil
+}
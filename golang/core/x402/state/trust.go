@@ -0,0 +1,58 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/google-agentic-commerce/a2a-x402/core/x402"
+)
+
+// SetTrustCredential attaches a pre-authorized credential to a client message
+// for merchants that exempt trusted clients from payment.
+func SetTrustCredential(msg *a2a.Message, credential string) {
+	if msg.Metadata == nil {
+		msg.Metadata = make(map[string]interface{})
+	}
+	msg.Metadata[x402.MetadataKeyTrustCredential] = credential
+}
+
+// ExtractTrustCredential returns the credential attached to msg, or "" when
+// there is none.
+func ExtractTrustCredential(msg *a2a.Message) string {
+	if msg == nil {
+		return ""
+	}
+	credential, _ := msg.Meta()[x402.MetadataKeyTrustCredential].(string)
+	return credential
+}
+
+// SetTrustedSubject records the subject of the credential that exempted a
+// task from payment, in place of a receipt.
+func SetTrustedSubject(msg *a2a.Message, subject string) {
+	if msg.Metadata == nil {
+		msg.Metadata = make(map[string]interface{})
+	}
+	msg.Metadata[x402.MetadataKeyTrustedSubject] = subject
+}
+
+// ExtractTrustedSubject returns the subject that the task was exempted from
+// payment for, or "" when it was not.
+func ExtractTrustedSubject(task *a2a.Task) string {
+	if task == nil || task.Status.Message == nil {
+		return ""
+	}
+	subject, _ := task.Status.Message.Meta()[x402.MetadataKeyTrustedSubject].(string)
+	return subject
+}
@@ -29,12 +29,32 @@ const (
 	PaymentRejected  PaymentStatus = "payment-rejected"
 	PaymentCompleted PaymentStatus = "payment-completed"
 	PaymentFailed    PaymentStatus = "payment-failed"
+	// PaymentNotRequired marks a task the merchant served for free.
+	PaymentNotRequired PaymentStatus = "payment-not-required"
+	// PaymentDeliveryPendingAck marks a delivered task whose verified payment
+	// is held until the client acknowledges the delivery.
+	PaymentDeliveryPendingAck PaymentStatus = "delivery-pending-ack"
+)
+
+// Outcomes recorded under x402.MetadataKeyCompensation when a merchant
+// compensated for work delivered against a payment that failed to settle.
+const (
+	CompensationSucceeded = "succeeded"
+	CompensationFailed    = "failed"
+)
+
+// Answers a client sends under x402.MetadataKeyDeliveryAck to a delivery held
+// for acknowledgement.
+const (
+	DeliveryAccepted = "accepted"
+	DeliveryDisputed = "disputed"
 )
 
 func (ps PaymentStatus) IsValid() bool {
 	switch ps {
 	case PaymentRequired, PaymentSubmitted, PaymentVerified,
-		PaymentRejected, PaymentCompleted, PaymentFailed:
+		PaymentRejected, PaymentCompleted, PaymentFailed, PaymentNotRequired,
+		PaymentDeliveryPendingAck:
 		return true
 	default:
 		return false
@@ -48,8 +68,11 @@ func (ps PaymentStatus) String() string {
 type PaymentState struct {
 	Status       PaymentStatus
 	Message      string
+	Summary      string
 	Requirements *x402types.PaymentRequired
 	Payload      *x402types.PaymentPayload
+	Payer        string
 	Receipts     []*x402core.SettleResponse
+	Parts        []a2a.Part
 	Artifacts    []*a2a.Artifact
 }
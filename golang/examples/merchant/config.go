@@ -19,11 +19,15 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/google-agentic-commerce/a2a-x402/core/merchant"
 	"github.com/google-agentic-commerce/a2a-x402/core/types"
 )
 
 type ServerConfig struct {
 	NetworkConfigs []types.NetworkConfig `json:"networkConfigs"`
+	// Facilitator holds facilitator credentials, e.g.
+	// {"APIKeyID": "env:CDP_API_KEY_ID", "APIKeySecret": "env:CDP_API_KEY_SECRET"}.
+	Facilitator merchant.FacilitatorOptions `json:"facilitator"`
 }
 
 func LoadServerConfig(configPath string) (*ServerConfig, error) {
@@ -18,6 +18,11 @@ import (
 	"context"
 	"flag"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/google-agentic-commerce/a2a-x402/core/merchant"
 )
 
 func init() {
@@ -28,6 +33,7 @@ func main() {
 	port := flag.String("port", ":8080", "Server port (e.g., :8080)")
 	facilitatorURL := flag.String("facilitator", "https://www.x402.org/facilitator", "Facilitator URL for payment verification (testnet: https://www.x402.org/facilitator, mainnet: https://api.cdp.coinbase.com/platform/v2/x402)")
 	configPath := flag.String("config", "server_config.json", "Path to server config file")
+	sandbox := flag.Bool("sandbox", false, "Accept payments without a facilitator for local development; nothing is settled")
 	flag.Parse()
 
 	serverConfig, err := LoadServerConfig(*configPath)
@@ -37,12 +43,19 @@ func main() {
 
 	imageService := NewImageService()
 
-	serverHandler, err := NewServerHandler(context.Background(), *facilitatorURL, serverConfig.NetworkConfigs, imageService)
+	var opts []merchant.Option
+	if *sandbox {
+		opts = append(opts, merchant.WithSandboxMode())
+	}
+
+	serverHandler, err := NewServerHandler(context.Background(), *facilitatorURL, serverConfig.Facilitator, serverConfig.NetworkConfigs, imageService, opts...)
 	if err != nil {
 		log.Fatalf("Failed to create server handler: %v", err)
 	}
 
-	if err := serverHandler.StartServer(*port); err != nil {
-		log.Fatalf("Server failed to start: %v", err)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := serverHandler.StartServer(ctx, *port); err != nil {
+		log.Fatalf("Server stopped with error: %v", err)
 	}
 }
@@ -17,87 +17,72 @@ package main
 import (
 	"context"
 	"fmt"
+	"log"
 	"net/http"
+	"time"
 
 	"github.com/a2aproject/a2a-go/a2a"
-	"github.com/a2aproject/a2a-go/a2asrv"
-	"github.com/gin-gonic/gin"
 	"github.com/google-agentic-commerce/a2a-x402/core/business"
 	"github.com/google-agentic-commerce/a2a-x402/core/merchant"
 	"github.com/google-agentic-commerce/a2a-x402/core/types"
-	"github.com/google-agentic-commerce/a2a-x402/core/x402"
 )
 
+// shutdownTimeout bounds how long in-flight payments get to finish once the
+// server is asked to stop.
+const shutdownTimeout = 30 * time.Second
+
 type ServerHandler struct {
 	agentCard *a2a.AgentCard
-	handler   a2asrv.RequestHandler
+	merchant  *merchant.Merchant
 }
 
-func NewServerHandler(ctx context.Context, facilitatorURL string, networkConfigs []types.NetworkConfig, businessService business.BusinessService) (*ServerHandler, error) {
+func NewServerHandler(ctx context.Context, facilitatorURL string, facilitatorOptions merchant.FacilitatorOptions, networkConfigs []types.NetworkConfig, businessService business.BusinessService, opts ...merchant.Option) (*ServerHandler, error) {
 
-	merchantInstance, err := merchant.NewMerchant(ctx, facilitatorURL, businessService, networkConfigs)
+	opts = append([]merchant.Option{merchant.WithFacilitatorOptions(facilitatorOptions)}, opts...)
+	merchantInstance, err := merchant.NewMerchant(ctx, facilitatorURL, businessService, networkConfigs, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create merchant: %w", err)
 	}
 
-	agentCard := &a2a.AgentCard{
-		Name:               "AI Image Generator",
-		Description:        "An AI agent that generates images with payment support",
-		URL:                "http://localhost:8080/rpc",
-		PreferredTransport: a2a.TransportProtocolJSONRPC,
-		DefaultInputModes:  []string{"text"},
-		DefaultOutputModes: []string{"text", "image/png"},
-		Capabilities: a2a.AgentCapabilities{
-			Extensions: []a2a.AgentExtension{
-				{
-					URI:      x402.X402ExtensionURI,
-					Required: true,
-				},
-			},
-		},
-		ProtocolVersion: "0.2",
-		Version:         "1.0.0",
+	agentCard, err := merchant.NewAgentCard(merchant.AgentCardConfig{
+		Name:        "AI Image Generator",
+		Description: "An AI agent that generates images with payment support",
+		URL:         "http://localhost:8080/rpc",
 		Skills: []a2a.AgentSkill{
 			{
+				ID:          "generate-image",
 				Name:        "generate-image",
 				Description: "Generate an AI image based on a text prompt",
 			},
 		},
+		NetworkConfigs: networkConfigs,
+		OutputModes:    []string{"text", "image/png"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build agent card: %w", err)
 	}
 
 	return &ServerHandler{
 		agentCard: agentCard,
-		handler:   a2asrv.NewHandler(merchantInstance.Orchestrator()),
+		merchant:  merchantInstance,
 	}, nil
 }
 
-func (sh *ServerHandler) StartServer(port string) error {
-	gin.SetMode(gin.ReleaseMode)
-
-	router := gin.Default()
-
-	agentCardHandler := a2asrv.NewStaticAgentCardHandler(sh.agentCard)
-	router.GET(a2asrv.WellKnownAgentCardPath, gin.WrapH(agentCardHandler))
-
-	rpcHandler := a2asrv.NewJSONRPCHandler(sh.handler)
-	wrappedHandler := extractHeadersMiddleware(rpcHandler)
-	router.POST("/rpc", gin.WrapH(wrappedHandler))
-	router.GET("/rpc", gin.WrapH(wrappedHandler))
-
-	return router.Run(port)
+// StartServer serves until ctx is done, then stops taking requests and lets
+// in-flight payments finish before returning.
+func (sh *ServerHandler) StartServer(ctx context.Context, port string) error {
+	log.Printf("Serving on %s; in-flight payments get up to %s on shutdown", port, shutdownTimeout)
+	return merchant.Serve(ctx, port, sh.merchant, sh.agentCard,
+		merchant.WithShutdownTimeout(shutdownTimeout),
+		merchant.WithRoute("GET /healthz", http.HandlerFunc(sh.healthz)),
+		merchant.WithHTTPHandlerOptions(merchant.WithHandlerCapabilities()),
+	)
 }
 
-func extractHeadersMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		headers := make(map[string][]string)
-		for k, v := range r.Header {
-			headers[k] = v
-		}
-
-		requestMeta := a2asrv.NewRequestMeta(headers)
-		ctx, _ := a2asrv.WithCallContext(r.Context(), requestMeta)
-		r = r.WithContext(ctx)
-
-		next.ServeHTTP(w, r)
-	})
+func (sh *ServerHandler) healthz(w http.ResponseWriter, r *http.Request) {
+	if err := sh.merchant.PingFacilitator(r.Context()); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	fmt.Fprint(w, "ok")
 }